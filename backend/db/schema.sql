@@ -12,9 +12,15 @@ CREATE TABLE IF NOT EXISTS users (
     email VARCHAR(255) UNIQUE NOT NULL,
     password_hash VARCHAR(255) NOT NULL,
     role VARCHAR(50) NOT NULL DEFAULT 'user', -- e.g., 'user', 'admin'
+    display_name VARCHAR(100) NOT NULL DEFAULT '', -- shown instead of the email, empty until first login
+    avatar_url TEXT NOT NULL DEFAULT '',
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
+-- users created before profiles were introduced
+ALTER TABLE users ADD COLUMN IF NOT EXISTS display_name VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_url TEXT NOT NULL DEFAULT '';
+
 -- =================================================================
 -- Table: tokens
 -- Stores refresh tokens for persistent user sessions.
@@ -44,9 +50,29 @@ CREATE TABLE IF NOT EXISTS movies (
     uploaded_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     processing_started_at TIMESTAMP WITH TIME ZONE,
-    processing_ended_at TIMESTAMP WITH TIME ZONE
+    processing_ended_at TIMESTAMP WITH TIME ZONE,
+    fast_preview BOOLEAN NOT NULL DEFAULT FALSE, -- only the preview quality is published so far
+    hls_encryption_key BYTEA, -- AES-128 key of the HLS segments sealed with VIDEO_KEY_SECRET, NULL when they are not encrypted
+    failure_reason TEXT NOT NULL DEFAULT '', -- why processing failed, shown to the uploader
+    failed_qualities TEXT[] NOT NULL DEFAULT '{}', -- renditions that failed to transcode while the others were published
+    retranscoding_since TIMESTAMP WITH TIME ZONE -- when an instance claimed the failed qualities for retranscoding, NULL when none did
 );
 
+-- movies created before fast preview uploads were introduced
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS fast_preview BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- movies created before segment encryption was introduced
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS hls_encryption_key BYTEA;
+
+-- movies created before failure reasons were recorded
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS failure_reason TEXT NOT NULL DEFAULT '';
+
+-- movies created before partially transcoded movies were tracked
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS failed_qualities TEXT[] NOT NULL DEFAULT '{}';
+
+-- movies created before retranscodes were claimed across instances
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS retranscoding_since TIMESTAMP WITH TIME ZONE;
+
 -- =================================================================
 -- Table: rooms
 -- Represents a watch party room created by a host.
@@ -57,9 +83,19 @@ CREATE TABLE IF NOT EXISTS rooms (
     host_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     name VARCHAR(255) NOT NULL DEFAULT '',
     description TEXT,
+    settings JSONB NOT NULL DEFAULT '{}', -- see model.RoomSettings, missing keys use defaults
+    last_position DOUBLE PRECISION NOT NULL DEFAULT 0, -- playback position in seconds, persisted from the live state to resume after it expired
+    last_position_at TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
+-- rooms created before settings were introduced
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}';
+
+-- rooms created before playback positions were persisted
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS last_position DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS last_position_at TIMESTAMP WITH TIME ZONE;
+
 -- =================================================================
 -- Table: room_access
 -- Manages user access permissions for specific rooms.
@@ -69,10 +105,13 @@ CREATE TABLE IF NOT EXISTS room_access (
     room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
     access_type VARCHAR(50) NOT NULL DEFAULT 'granted', -- e.g., 'granted', 'guest'
     status VARCHAR(20) NOT NULL DEFAULT 'granted', -- e.g., 'granted', 'pending'
+    role VARCHAR(20) NOT NULL DEFAULT 'member', -- 'member' or 'co_host'
     granted_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     PRIMARY KEY (user_id, room_id)
 );
 
+ALTER TABLE room_access ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'member';
+
 -- =================================================================
 -- Table: room_invitations
 -- Stores email-based invitations for users to join rooms.
@@ -148,6 +187,85 @@ CREATE TABLE IF NOT EXISTS guest_sessions (
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
+-- =================================================================
+-- Table: room_audit_log
+-- Records changes to a room that outlive a single session (e.g. host changes).
+-- =================================================================
+CREATE TABLE IF NOT EXISTS room_audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL, -- NULL when the change was made by the system
+    action VARCHAR(50) NOT NULL, -- 'host_transferred', 'host_auto_handoff'
+    details JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- =================================================================
+-- Table: reports
+-- Abuse reports filed by room members, with the chat that preceded them.
+-- =================================================================
+CREATE TABLE IF NOT EXISTS reports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_user_id UUID NOT NULL, -- user or guest session ID, so no foreign key
+    target_username VARCHAR(255),
+    reason TEXT NOT NULL,
+    chat_snapshot JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL DEFAULT 'open', -- 'open', 'reviewed', 'dismissed'
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- =================================================================
+-- Table: movie_daily_views
+-- Per-day view counters, flushed periodically from Redis.
+-- =================================================================
+CREATE TABLE IF NOT EXISTS movie_daily_views (
+    movie_id UUID NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    views BIGINT NOT NULL DEFAULT 0,
+    unique_viewers BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (movie_id, day)
+);
+
+-- =================================================================
+-- Table: movie_analytics
+-- All-time distinct viewers, which cannot be summed from daily rows.
+-- =================================================================
+CREATE TABLE IF NOT EXISTS movie_analytics (
+    movie_id UUID PRIMARY KEY REFERENCES movies(id) ON DELETE CASCADE,
+    unique_viewers BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- =================================================================
+-- Table: movie_subtitles
+-- WebVTT tracks added after upload, one per language.
+-- =================================================================
+CREATE TABLE IF NOT EXISTS movie_subtitles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    movie_id UUID NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+    language VARCHAR(35) NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    file_path TEXT NOT NULL,
+    uploaded_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (movie_id, language)
+);
+
+-- =================================================================
+-- Table: movie_access
+-- Direct grants to stream a movie, next to the access rooms give.
+-- =================================================================
+CREATE TABLE IF NOT EXISTS movie_access (
+    movie_id UUID NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    granted_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    granted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE, -- NULL never expires
+    PRIMARY KEY (movie_id, user_id)
+);
+
 -- =================================================================
 -- Indexes for Performance
 -- =================================================================
@@ -156,9 +274,12 @@ CREATE INDEX IF NOT EXISTS idx_tokens_value ON tokens(value);
 CREATE INDEX IF NOT EXISTS idx_tokens_user_id ON tokens(user_id);
 CREATE INDEX IF NOT EXISTS idx_movies_uploaded_by ON movies(uploaded_by);
 CREATE INDEX IF NOT EXISTS idx_movies_status ON movies(status);
+CREATE INDEX IF NOT EXISTS idx_movies_original_file_path ON movies(original_file_path);
 CREATE INDEX IF NOT EXISTS idx_rooms_movie_id ON rooms(movie_id);
 CREATE INDEX IF NOT EXISTS idx_rooms_host_id ON rooms(host_id);
+CREATE INDEX IF NOT EXISTS idx_rooms_host_movie ON rooms(host_id, movie_id, created_at);
 CREATE INDEX IF NOT EXISTS idx_room_access_room_id ON room_access(room_id);
+CREATE INDEX IF NOT EXISTS idx_movie_access_user_id ON movie_access(user_id);
 CREATE INDEX IF NOT EXISTS idx_room_invitations_room_id ON room_invitations(room_id);
 CREATE INDEX IF NOT EXISTS idx_room_invitations_token ON room_invitations(token);
 CREATE INDEX IF NOT EXISTS idx_room_invitations_email ON room_invitations(email);
@@ -175,6 +296,8 @@ CREATE INDEX IF NOT EXISTS idx_guest_requests_status ON guest_access_requests(st
 CREATE INDEX IF NOT EXISTS idx_guest_sessions_room ON guest_sessions(room_id);
 CREATE INDEX IF NOT EXISTS idx_guest_sessions_token ON guest_sessions(session_token);
 CREATE INDEX IF NOT EXISTS idx_guest_sessions_expires ON guest_sessions(expires_at);
+CREATE INDEX IF NOT EXISTS idx_room_audit_log_room_id ON room_audit_log(room_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_reports_status ON reports(status, created_at);
 
 -- =================================================================
 -- Helper Functions
@@ -21,7 +21,11 @@ require (
 	golang.org/x/crypto v0.39.0
 )
 
-require github.com/alicebob/miniredis/v2 v2.35.0
+require (
+	github.com/alicebob/miniredis/v2 v2.35.0
+	github.com/andybalholm/brotli v1.1.1
+	google.golang.org/api v0.237.0
+)
 
 require (
 	cel.dev/expr v0.23.1 // indirect
@@ -96,7 +100,6 @@ require (
 	golang.org/x/sys v0.33.0 // indirect
 	golang.org/x/text v0.26.0 // indirect
 	golang.org/x/time v0.12.0 // indirect
-	google.golang.org/api v0.237.0 // indirect
 	google.golang.org/genproto v0.0.0-20250505200425-f936aa4a68b2 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20250603155806-513f23925822 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250603155806-513f23925822 // indirect
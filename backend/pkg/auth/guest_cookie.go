@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GuestCookieName is the cookie carrying a guest session to the streaming routes
+const GuestCookieName = "wp_guest_session"
+
+// GuestCookieSigner signs guest session cookies so forged or altered cookies are rejected before any lookup
+type GuestCookieSigner struct {
+	secretKey []byte
+	domain    string
+}
+
+// NewGuestCookieSigner creates a signer for guest session cookies, an empty domain scopes them to the API host
+func NewGuestCookieSigner(secretKey, domain string) *GuestCookieSigner {
+	return &GuestCookieSigner{secretKey: []byte(secretKey), domain: domain}
+}
+
+// Cookie returns an httpOnly cookie holding the signed guest token, scoped to the API and expiring with the session.
+// it is marked SameSite=None and Secure so players on another origin send it back, which browsers only do over HTTPS
+// (or on localhost)
+func (s *GuestCookieSigner) Cookie(guestToken string, expiresAt time.Time) *http.Cookie {
+	encodedToken := base64.RawURLEncoding.EncodeToString([]byte(guestToken))
+	signature := base64.RawURLEncoding.EncodeToString(s.sign(encodedToken))
+
+	return &http.Cookie{
+		Name:     GuestCookieName,
+		Value:    encodedToken + "." + signature,
+		Path:     "/api/v1/",
+		Domain:   s.domain,
+		Expires:  expiresAt,
+		MaxAge:   max(1, int(time.Until(expiresAt).Seconds())),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	}
+}
+
+// Verify returns the guest token of a cookie value, ok is false when the signature does not match
+func (s *GuestCookieSigner) Verify(value string) (string, bool) {
+	encodedToken, encodedSignature, found := strings.Cut(value, ".")
+	if !found {
+		return "", false
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, s.sign(encodedToken)) {
+		return "", false
+	}
+
+	guestToken, err := base64.RawURLEncoding.DecodeString(encodedToken)
+	if err != nil || len(guestToken) == 0 {
+		return "", false
+	}
+	return string(guestToken), true
+}
+
+// sign computes the cookie signature, domain-separated from other uses of the secret
+func (s *GuestCookieSigner) sign(message string) []byte {
+	mac := hmac.New(sha256.New, s.secretKey)
+	mac.Write([]byte("guest-cookie:" + message))
+	return mac.Sum(nil)
+}
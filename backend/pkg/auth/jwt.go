@@ -1,8 +1,10 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"time"
+	"watch-party/pkg/logger"
 	"watch-party/pkg/model"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -12,6 +14,19 @@ import (
 var (
 	ErrInvalidToken = errors.New("invalid token")
 	ErrTokenExpired = errors.New("token expired")
+	ErrTokenRevoked = errors.New("token revoked")
+
+	// ErrUnauthenticated means the request carries no valid credentials, answered with 401
+	ErrUnauthenticated = errors.New("authentication required")
+	// ErrForbidden means the credentials are valid but don't grant access, answered with 403
+	ErrForbidden = errors.New("access denied")
+)
+
+const (
+	// AccessTokenTTL is how long an access token is valid
+	AccessTokenTTL = 24 * time.Hour
+	// RefreshTokenTTL is how long a refresh token is valid, and so the longest any token lives
+	RefreshTokenTTL = 7 * 24 * time.Hour
 )
 
 // JWTClaims represents the JWT claims structure
@@ -19,31 +34,38 @@ type JWTClaims struct {
 	UserID uuid.UUID `json:"user_id"`
 	Email  string    `json:"email"`
 	Role   string    `json:"role"`
+	// IssuedAtMillis is when the token was issued in unix milliseconds, iat only keeps the second
+	IssuedAtMillis int64 `json:"iat_ms,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // JWTManager handles JWT token operations
 type JWTManager struct {
 	secretKey string
+	revoker   *TokenRevoker
 }
 
-// NewJWTManager creates a new JWT manager
-func NewJWTManager(secretKey string) *JWTManager {
+// NewJWTManager creates a new JWT manager, revoker may be nil to skip revocation checks
+func NewJWTManager(secretKey string, revoker *TokenRevoker) *JWTManager {
 	return &JWTManager{
 		secretKey: secretKey,
+		revoker:   revoker,
 	}
 }
 
 // GenerateAccessToken generates a new access token
 func (j *JWTManager) GenerateAccessToken(user *model.User) (string, error) {
+	now := time.Now()
 	claims := JWTClaims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
+		UserID:         user.ID,
+		Email:          user.Email,
+		Role:           user.Role,
+		IssuedAtMillis: now.UnixMilli(),
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24)), // 24 hours
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "watch-party",
 			Subject:   user.ID.String(),
 		},
@@ -55,14 +77,17 @@ func (j *JWTManager) GenerateAccessToken(user *model.User) (string, error) {
 
 // GenerateRefreshToken generates a new refresh token
 func (j *JWTManager) GenerateRefreshToken(user *model.User) (string, error) {
+	now := time.Now()
 	claims := JWTClaims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
+		UserID:         user.ID,
+		Email:          user.Email,
+		Role:           user.Role,
+		IssuedAtMillis: now.UnixMilli(),
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24 * 7)), // 7 days
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(RefreshTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "watch-party",
 			Subject:   user.ID.String(),
 		},
@@ -72,8 +97,52 @@ func (j *JWTManager) GenerateRefreshToken(user *model.User) (string, error) {
 	return token.SignedString([]byte(j.secretKey))
 }
 
-// ValidateToken validates and parses a JWT token
+// ValidateToken validates and parses a JWT token, rejecting revoked tokens
 func (j *JWTManager) ValidateToken(tokenString string) (*JWTClaims, error) {
+	claims, err := j.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if j.revoker != nil {
+		revoked, err := j.revoker.IsRevoked(context.Background(), claims)
+		if err != nil {
+			// a Redis outage must not log everyone out
+			logger.Error(err, "failed to check token revocation")
+		} else if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+
+	return claims, nil
+}
+
+// RevokeToken rejects a token from now on until it expires
+func (j *JWTManager) RevokeToken(ctx context.Context, tokenString string) error {
+	if j.revoker == nil {
+		return nil
+	}
+
+	claims, err := j.parseToken(tokenString)
+	if err != nil {
+		// expired or forged tokens are rejected anyway
+		return nil
+	}
+
+	return j.revoker.RevokeToken(ctx, claims)
+}
+
+// RevokeUserTokens rejects every token issued to a user so far
+func (j *JWTManager) RevokeUserTokens(ctx context.Context, userID uuid.UUID, reason string) error {
+	if j.revoker == nil {
+		return errors.New("token revocation is not available")
+	}
+
+	return j.revoker.RevokeUserTokens(ctx, userID, reason)
+}
+
+// parseToken verifies the signature and expiry of a JWT token
+func (j *JWTManager) parseToken(tokenString string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		_, ok := token.Method.(*jwt.SigningMethodHMAC)
 		if !ok {
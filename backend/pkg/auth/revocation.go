@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+)
+
+// TokenRevoker keeps a Redis-backed list of tokens that must no longer be accepted
+type TokenRevoker struct {
+	redis *redis.Client
+}
+
+// NewTokenRevoker creates a token revoker, nil when Redis is not available
+func NewTokenRevoker(redisClient *redis.Client) *TokenRevoker {
+	if redisClient == nil {
+		return nil
+	}
+	return &TokenRevoker{
+		redis: redisClient,
+	}
+}
+
+// RevokeToken blacklists a single token until it would have expired anyway
+func (r *TokenRevoker) RevokeToken(ctx context.Context, claims *JWTClaims) error {
+	// tokens issued before revocation support carry no ID and can only be revoked per user
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if ttl <= 0 {
+		return nil
+	}
+
+	return r.redis.Set(ctx, redis.RevokedTokenKey(claims.ID), claims.UserID.String(), ttl)
+}
+
+// RevokeUserTokens revokes every token issued to a user up to now and announces it to connected services
+func (r *TokenRevoker) RevokeUserTokens(ctx context.Context, userID uuid.UUID, reason string) error {
+	now := time.Now()
+
+	// the marker is only needed until the longest-lived token issued before it has expired
+	err := r.redis.Set(ctx, redis.UserTokensRevokedKey(userID), now.UnixMilli(), RefreshTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	err = r.redis.Publish(ctx, redis.TokenRevocationChannel, &model.TokensRevokedMessage{
+		UserID:    userID,
+		RevokedAt: now,
+		Reason:    reason,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to announce token revocation: %w", err)
+	}
+
+	return nil
+}
+
+// IsRevoked reports whether a token was revoked on its own or together with all tokens of its user
+func (r *TokenRevoker) IsRevoked(ctx context.Context, claims *JWTClaims) (bool, error) {
+	if claims.ID != "" {
+		revoked, err := r.redis.Exists(ctx, redis.RevokedTokenKey(claims.ID))
+		if err != nil {
+			return false, err
+		}
+		if revoked > 0 {
+			return true, nil
+		}
+	}
+
+	value, err := r.redis.GetString(ctx, redis.UserTokensRevokedKey(claims.UserID))
+	if err != nil {
+		return false, err
+	}
+	if value == "" {
+		return false, nil
+	}
+
+	revokedAt, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid revocation marker for user %s: %w", claims.UserID, err)
+	}
+	// markers from before millisecond precision hold seconds, they revoke their whole second as they used to
+	if revokedAt < minMillisMarker {
+		revokedAt = revokedAt*1000 + 999
+	}
+
+	issuedAt, ok := issuedAtMillis(claims)
+	return !ok || issuedAt <= revokedAt, nil
+}
+
+// minMillisMarker tells revocation markers in milliseconds from older ones in seconds, any time since 2001 in
+// milliseconds is above it and any time before the year 33658 in seconds below it
+const minMillisMarker = 1_000_000_000_000
+
+// issuedAtMillis returns when a token was issued in unix milliseconds. tokens issued before iat_ms was added only
+// carry the second, they count as issued at its start so one from the same second as a revocation stays revoked
+func issuedAtMillis(claims *JWTClaims) (int64, bool) {
+	if claims.IssuedAtMillis > 0 {
+		return claims.IssuedAtMillis, true
+	}
+	if claims.IssuedAt == nil {
+		return 0, false
+	}
+	return claims.IssuedAt.Unix() * 1000, true
+}
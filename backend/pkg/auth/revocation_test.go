@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRevoker(t *testing.T) (*TokenRevoker, *miniredis.Miniredis) {
+	logger.InitLogger(&config.Config{})
+	server := miniredis.RunT(t)
+
+	client, err := redis.NewClient(&config.Config{Redis: config.RedisConfig{Host: server.Host(), Port: server.Port()}})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	return NewTokenRevoker(client), server
+}
+
+func TestTokenIssuedRightAfterForcedLogoutIsAccepted(t *testing.T) {
+	revoker, server := newTestRevoker(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	require.NoError(t, revoker.RevokeUserTokens(ctx, userID, model.TokenRevokedReasonForceLogout))
+	marker, err := server.Get(redis.UserTokensRevokedKey(userID))
+	require.NoError(t, err)
+	revokedAt, err := strconv.ParseInt(marker, 10, 64)
+	require.NoError(t, err)
+
+	// the user logs in again within the same second, a millisecond later
+	relogin := time.UnixMilli(revokedAt + 1)
+	claims := &JWTClaims{
+		UserID:           userID,
+		IssuedAtMillis:   relogin.UnixMilli(),
+		RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(relogin)},
+	}
+	revoked, err := revoker.IsRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	claims.IssuedAtMillis = revokedAt - 1
+	revoked, err = revoker.IsRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.True(t, revoked, "a token issued before the revocation")
+}
+
+func TestRevocationOfTokensWithSecondPrecision(t *testing.T) {
+	revoker, server := newTestRevoker(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	revokedAt := time.Now().Truncate(time.Second).Add(500 * time.Millisecond)
+
+	tests := []struct {
+		name     string
+		marker   string
+		issuedAt time.Time
+		millis   bool
+		revoked  bool
+	}{
+		{"legacy token from the same second", strconv.FormatInt(revokedAt.UnixMilli(), 10), revokedAt.Add(time.Millisecond), false, true},
+		{"legacy token from the next second", strconv.FormatInt(revokedAt.UnixMilli(), 10), revokedAt.Add(time.Second), false, false},
+		{"legacy marker, token from the same second", strconv.FormatInt(revokedAt.Unix(), 10), revokedAt.Add(time.Millisecond), true, true},
+		{"legacy marker, token from the next second", strconv.FormatInt(revokedAt.Unix(), 10), revokedAt.Add(time.Second), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, server.Set(redis.UserTokensRevokedKey(userID), tt.marker))
+
+			claims := &JWTClaims{
+				UserID:           userID,
+				RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(tt.issuedAt)},
+			}
+			if tt.millis {
+				claims.IssuedAtMillis = tt.issuedAt.UnixMilli()
+			}
+
+			revoked, err := revoker.IsRevoked(ctx, claims)
+			require.NoError(t, err)
+			assert.Equal(t, tt.revoked, revoked)
+		})
+	}
+}
@@ -3,10 +3,12 @@ package config
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -75,14 +77,24 @@ func getGCPProjectID() string {
 }
 
 type Config struct {
-	Port      string         `json:"port"`
-	JWTSecret string         `json:"jwt_secret"`
-	Database  DatabaseConfig `json:"database"`
-	Log       LogConfig      `json:"log"`
-	Storage   StorageConfig  `json:"storage"`
-	Email     EmailConfig    `json:"email"`
-	Redis     RedisConfig    `json:"redis"`
-	CORS      CORSConfig     `json:"cors"`
+	Port        string            `json:"port"`
+	JWTSecret   string            `json:"jwt_secret"`
+	Database    DatabaseConfig    `json:"database"`
+	Log         LogConfig         `json:"log"`
+	Storage     StorageConfig     `json:"storage"`
+	Email       EmailConfig       `json:"email"`
+	Redis       RedisConfig       `json:"redis"`
+	CORS        CORSConfig        `json:"cors"`
+	Compression CompressionConfig `json:"compression"`
+	HostHandoff HostHandoffConfig `json:"host_handoff"`
+	Room        RoomConfig        `json:"room"`
+	Profile     ProfileConfig     `json:"profile"`
+	Chat        ChatConfig        `json:"chat"`
+	Sync        SyncConfig        `json:"sync"`
+	Streaming   StreamingConfig   `json:"streaming"`
+	Idempotency IdempotencyConfig `json:"idempotency"`
+	TLS         TLSConfig         `json:"tls"`
+	Features    FeatureConfig     `json:"features"`
 }
 
 type DatabaseConfig struct {
@@ -95,22 +107,72 @@ type DatabaseConfig struct {
 	MaxOpenConns    int      `json:"max_open_conns" mapstructure:"db_max_open_conns"`
 	MaxIdleConns    int      `json:"max_idle_conns" mapstructure:"db_max_idle_conns"`
 	ConnMaxLifetime Duration `json:"conn_max_lifetime" mapstructure:"db_conn_max_lifetime"`
-	SSLMode         string   `json:"ssl_mode" mapstructure:"db_ssl_mode"` // e.g., "disable", "require", "verify-ca", "verify-full"
+	SSLMode         string   `json:"ssl_mode" mapstructure:"db_ssl_mode"`           // e.g., "disable", "require", "verify-ca", "verify-full"
+	QueryTimeout    Duration `json:"query_timeout" mapstructure:"db_query_timeout"` // longest a single statement may run before the server cancels it, 0 uses the default
+}
+
+// DefaultDBQueryTimeout is how long a single statement may run, also used when a stored config leaves it unset
+const DefaultDBQueryTimeout = 10 * time.Second
+
+// StatementTimeout returns how long a single statement may run
+func (c DatabaseConfig) StatementTimeout() time.Duration {
+	if c.QueryTimeout <= 0 {
+		return DefaultDBQueryTimeout
+	}
+	return c.QueryTimeout.ToDuration()
 }
 
 type LogConfig struct {
-	Level  string `json:"level" mapstructure:"log_level"`
-	Format string `json:"format" mapstructure:"log_format"` // "console" or "json"
+	Level      string `json:"level" mapstructure:"log_level"`
+	Format     string `json:"format" mapstructure:"log_format"`           // "console" or "json"
+	SampleRate int    `json:"sample_rate" mapstructure:"log_sample_rate"` // high-volume info logs such as sync actions are written 1 in this many times, 0 or 1 writes all
 }
 
 type StorageConfig struct {
-	Provider            string      `json:"provider" mapstructure:"storage_provider"`
-	GCSBucket           string      `json:"gcs_bucket" mapstructure:"storage_gcs_bucket"`
-	GCSCredentialsPath  string      `json:"gcs_credentials_path" mapstructure:"storage_gcs_credentials_path"`
-	GCSServiceAccountID string      `json:"gcs_service_account_id" mapstructure:"storage_gcs_service_account_id"`
-	GCSPrivateKey       string      `json:"gcs_private_key" mapstructure:"storage_gcs_private_key"`
-	MinIO               MinIOConfig `json:"minio" mapstructure:"minio"`
-	VideoProcessing     VideoConfig `json:"video_processing" mapstructure:"video_processing"`
+	Provider             string      `json:"provider" mapstructure:"storage_provider"`
+	GCSBucket            string      `json:"gcs_bucket" mapstructure:"storage_gcs_bucket"`
+	GCSCredentialsPath   string      `json:"gcs_credentials_path" mapstructure:"storage_gcs_credentials_path"`
+	GCSServiceAccountID  string      `json:"gcs_service_account_id" mapstructure:"storage_gcs_service_account_id"`
+	GCSPrivateKey        string      `json:"gcs_private_key" mapstructure:"storage_gcs_private_key"`
+	MinIO                MinIOConfig `json:"minio" mapstructure:"minio"`
+	VideoProcessing      VideoConfig `json:"video_processing" mapstructure:"video_processing"`
+	MaxUploadBytes       int64       `json:"max_upload_bytes" mapstructure:"max_upload_bytes"`                 // largest original file accepted, 0 uses the default
+	UploadReaperInterval Duration    `json:"upload_reaper_interval" mapstructure:"upload_reaper_interval"`     // how often abandoned uploads are swept, 0 disables
+	UploadNotifications  bool        `json:"upload_notifications" mapstructure:"storage_upload_notifications"` // start processing when storage reports a new upload
+	NotificationToken    string      `json:"-" mapstructure:"storage_notification_token"`                      // shared secret for pushed storage notifications
+	OperationTimeout     Duration    `json:"operation_timeout" mapstructure:"storage_operation_timeout"`       // deadline of metadata, signing, listing and delete calls, 0 uses the default
+	TransferTimeout      Duration    `json:"transfer_timeout" mapstructure:"storage_transfer_timeout"`         // deadline of whole-file uploads and downloads, 0 uses the default
+}
+
+// DefaultStorageOperationTimeout bounds storage calls that do not move file contents, also used when a stored
+// config leaves it unset
+const DefaultStorageOperationTimeout = 30 * time.Second
+
+// DefaultStorageTransferTimeout bounds uploads and downloads of whole files such as transcode inputs, also used
+// when a stored config leaves it unset
+const DefaultStorageTransferTimeout = 30 * time.Minute
+
+// StorageTimeouts returns the deadlines of storage calls that do and do not move file contents
+func (c StorageConfig) StorageTimeouts() (operation, transfer time.Duration) {
+	operation, transfer = c.OperationTimeout.ToDuration(), c.TransferTimeout.ToDuration()
+	if operation <= 0 {
+		operation = DefaultStorageOperationTimeout
+	}
+	if transfer <= 0 {
+		transfer = DefaultStorageTransferTimeout
+	}
+	return operation, transfer
+}
+
+// DefaultMaxUploadBytes is the largest original file accepted for upload, also used when a stored config leaves it unset
+const DefaultMaxUploadBytes = 5 * 1024 * 1024 * 1024 // 5GB
+
+// UploadSizeLimit returns the largest original file accepted for upload
+func (c StorageConfig) UploadSizeLimit() int64 {
+	if c.MaxUploadBytes <= 0 {
+		return DefaultMaxUploadBytes
+	}
+	return c.MaxUploadBytes
 }
 
 type MinIOConfig struct {
@@ -123,12 +185,167 @@ type MinIOConfig struct {
 }
 
 type VideoConfig struct {
-	TempDir     string `json:"temp_dir" mapstructure:"temp_dir"`
-	HLSBaseURL  string `json:"hls_base_url" mapstructure:"hls_base_url"`
-	FFmpegPath  string `json:"ffmpeg_path" mapstructure:"ffmpeg_path"`
-	FFprobePath string `json:"ffprobe_path" mapstructure:"ffprobe_path"`
+	TempDir               string              `json:"temp_dir" mapstructure:"temp_dir"`
+	HLSBaseURL            string              `json:"hls_base_url" mapstructure:"hls_base_url"`
+	FFmpegPath            string              `json:"ffmpeg_path" mapstructure:"ffmpeg_path"`
+	FFprobePath           string              `json:"ffprobe_path" mapstructure:"ffprobe_path"`
+	Poster                ImageArtifactConfig `json:"poster" mapstructure:"poster"`                                     // full-size still shown before playback starts
+	Preview               ImageArtifactConfig `json:"preview" mapstructure:"preview"`                                   // small still shown in movie listings
+	FastPreviewFullLadder bool                `json:"fast_preview_full_ladder" mapstructure:"fast_preview_full_ladder"` // transcode the rest of the ladder after a fast preview is published
+	DownloadConcurrency   int                 `json:"download_concurrency" mapstructure:"download_concurrency"`         // ranges of a source fetched at the same time, 1 downloads serially
+	DownloadChunkSizeMB   int                 `json:"download_chunk_size_mb" mapstructure:"download_chunk_size_mb"`     // size of each range fetched from storage
+	EncryptSegments       bool                `json:"encrypt_segments" mapstructure:"encrypt_segments"`                 // encrypt HLS segments with AES-128, keys are served by the API
+	KeyBaseURL            string              `json:"key_base_url" mapstructure:"key_base_url"`                         // public URL of the API video routes written to encrypted playlists, required with encryption
+	KeySecret             string              `json:"key_secret" mapstructure:"key_secret"`                             // seals segment keys stored in the database, required with encryption
+	AllowedExtensions     []string            `json:"allowed_extensions" mapstructure:"allowed_extensions"`             // source file extensions accepted for upload, empty uses the default
+	MaxDuration           Duration            `json:"max_duration" mapstructure:"max_duration"`                         // longest source accepted for transcoding, 0 uses the default
+	StuckAfter            Duration            `json:"stuck_after" mapstructure:"stuck_after"`                           // how long a movie may stay in transcoding before pipeline health reports it stuck, 0 uses the default
+}
+
+// DefaultVideoMaxDuration is the longest source transcoded when no limit is configured, generous enough for any
+// feature film while keeping a runaway file from holding the transcoder for a day
+const DefaultVideoMaxDuration = 6 * time.Hour
+
+// StuckTranscodeFactor scales the longest accepted source into the default time a transcode may run before it is
+// reported stuck, transcoding the whole ladder takes longer than playing the source back
+const StuckTranscodeFactor = 2
+
+// StuckTranscodeThreshold returns how long a movie may stay in transcoding before it is reported stuck, by default
+// twice the longest accepted source
+func (c VideoConfig) StuckTranscodeThreshold() time.Duration {
+	if c.StuckAfter <= 0 {
+		return StuckTranscodeFactor * c.MaxSourceDuration()
+	}
+	return c.StuckAfter.ToDuration()
 }
 
+// MaxSourceDuration returns the longest source accepted for transcoding
+func (c VideoConfig) MaxSourceDuration() time.Duration {
+	if c.MaxDuration <= 0 {
+		return DefaultVideoMaxDuration
+	}
+	return c.MaxDuration.ToDuration()
+}
+
+// VideoFormats maps every source file extension uploads can be enabled for to the content type it is stored with
+var VideoFormats = map[string]string{
+	".mp4":  "video/mp4",
+	".m4v":  "video/mp4",
+	".mov":  "video/quicktime",
+	".mkv":  "video/x-matroska",
+	".webm": "video/webm",
+	".avi":  "video/x-msvideo",
+	".ts":   "video/mp2t",
+	".flv":  "video/x-flv",
+	".mpg":  "video/mpeg",
+	".mpeg": "video/mpeg",
+	".wmv":  "video/x-ms-wmv",
+	".3gp":  "video/3gpp",
+}
+
+// DefaultVideoAllowedExtensions are the source formats accepted when no allowlist is configured
+var DefaultVideoAllowedExtensions = []string{".mp4", ".avi", ".mkv", ".mov", ".webm", ".m4v"}
+
+// InputExtensions returns the accepted source file extensions, lowercased with their leading dot
+func (c VideoConfig) InputExtensions() []string {
+	if len(c.AllowedExtensions) == 0 {
+		return DefaultVideoAllowedExtensions
+	}
+
+	extensions := make([]string, 0, len(c.AllowedExtensions))
+	for _, ext := range c.AllowedExtensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext != "" && !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		extensions = append(extensions, ext)
+	}
+	return extensions
+}
+
+// AllowsExtension reports whether a source file with this extension is accepted
+func (c VideoConfig) AllowsExtension(ext string) bool {
+	return slices.Contains(c.InputExtensions(), strings.ToLower(ext))
+}
+
+// ValidateAllowedExtensions checks that every accepted extension is a known video format, checked at startup so a
+// typo does not silently reject every upload
+func (c VideoConfig) ValidateAllowedExtensions() error {
+	for _, ext := range c.InputExtensions() {
+		if _, known := VideoFormats[ext]; !known {
+			return fmt.Errorf("unsupported video extension %q in allowed extensions", ext)
+		}
+	}
+	return nil
+}
+
+// VideoMimeType returns the content type a source file with this extension is stored with
+func VideoMimeType(ext string) string {
+	if mimeType, known := VideoFormats[strings.ToLower(ext)]; known {
+		return mimeType
+	}
+	return "application/octet-stream"
+}
+
+// MinSegmentKeySecretLength is the shortest secret accepted to seal segment keys
+const MinSegmentKeySecretLength = 32
+
+// SegmentKeyBaseURL returns the base of the key URIs written to encrypted playlists, empty when segments are not encrypted
+func (c VideoConfig) SegmentKeyBaseURL() string {
+	if !c.EncryptSegments {
+		return ""
+	}
+	return strings.TrimRight(strings.TrimSpace(c.KeyBaseURL), "/")
+}
+
+// ValidateSegmentEncryption checks that encryption has a key URL players can reach and a secret to seal keys with,
+// checked at startup so playlists never point at a guessed host and keys are never stored in the clear
+func (c VideoConfig) ValidateSegmentEncryption() error {
+	if !c.EncryptSegments {
+		return nil
+	}
+	if c.SegmentKeyBaseURL() == "" {
+		return fmt.Errorf("segment encryption needs a key base URL")
+	}
+	if len(c.KeySecret) < MinSegmentKeySecretLength {
+		return fmt.Errorf("segment encryption needs a key secret of at least %d characters", MinSegmentKeySecretLength)
+	}
+	return nil
+}
+
+// frame selection strategies for generated images
+const (
+	FrameSelectionOffset         = "offset"         // the frame at the configured offset
+	FrameSelectionRepresentative = "representative" // the most representative frame of the shot starting at the offset
+)
+
+// ImageArtifactConfig controls an image generated from each movie after transcoding
+type ImageArtifactConfig struct {
+	Enabled        bool     `json:"enabled" mapstructure:"enabled"`
+	Width          int      `json:"width" mapstructure:"width"`                     // output width in pixels, the height keeps the aspect ratio
+	Quality        int      `json:"quality" mapstructure:"quality"`                 // JPEG quality from 1 (smallest) to 100 (best)
+	FrameSelection string   `json:"frame_selection" mapstructure:"frame_selection"` // offset or representative, empty means offset
+	Offset         Duration `json:"offset" mapstructure:"offset"`                   // how far into the movie the frame is taken
+}
+
+// default image artifacts, posters favor quality and previews favor size
+var (
+	DefaultPosterConfig = ImageArtifactConfig{
+		Enabled:        true,
+		Width:          1280,
+		Quality:        85,
+		FrameSelection: FrameSelectionRepresentative,
+		Offset:         Duration(10 * time.Second),
+	}
+	DefaultPreviewConfig = ImageArtifactConfig{
+		Enabled:        true,
+		Width:          320,
+		Quality:        70,
+		FrameSelection: FrameSelectionOffset,
+		Offset:         Duration(10 * time.Second),
+	}
+)
+
 type EmailConfig struct {
 	Provider  string              `json:"provider" mapstructure:"email_provider"`
 	SMTP      SMTPConfig          `json:"smtp" mapstructure:"smtp"`
@@ -151,8 +368,9 @@ type SendGridConfig struct {
 }
 
 type EmailTemplateConfig struct {
-	BaseURL string `json:"base_url" mapstructure:"base_url"`
-	AppName string `json:"app_name" mapstructure:"app_name"`
+	BaseURL         string   `json:"base_url" mapstructure:"base_url"`
+	AppName         string   `json:"app_name" mapstructure:"app_name"`
+	AllowedBaseURLs []string `json:"allowed_base_urls" mapstructure:"allowed_base_urls"` // origins rooms may use for their emailed links instead of BaseURL
 }
 
 type RedisConfig struct {
@@ -163,9 +381,233 @@ type RedisConfig struct {
 }
 
 type CORSConfig struct {
-	AllowedOrigins []string `json:"allowed_origins" mapstructure:"cors_allowed_origins"`
-	AllowedMethods []string `json:"allowed_methods" mapstructure:"cors_allowed_methods"`
-	AllowedHeaders []string `json:"allowed_headers" mapstructure:"cors_allowed_headers"`
+	AllowedOrigins           []string `json:"allowed_origins" mapstructure:"cors_allowed_origins"`
+	AllowedMethods           []string `json:"allowed_methods" mapstructure:"cors_allowed_methods"`
+	AllowedHeaders           []string `json:"allowed_headers" mapstructure:"cors_allowed_headers"`
+	AllowAllWebSocketOrigins bool     `json:"allow_all_websocket_origins" mapstructure:"cors_allow_all_websocket_origins"` // accept WebSocket upgrades from any page, for development only
+}
+
+// AllowsWebSocketOrigin reports whether a page served from origin may open a WebSocket. browsers always send the
+// Origin of a cross-site upgrade, requests without one come from non-browser clients that can't be hijacked
+func (c CORSConfig) AllowsWebSocketOrigin(origin string) bool {
+	if origin == "" || c.AllowAllWebSocketOrigins {
+		return true
+	}
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+type CompressionConfig struct {
+	Enabled       bool `json:"enabled" mapstructure:"compression_enabled"`
+	BrotliEnabled bool `json:"brotli_enabled" mapstructure:"compression_brotli_enabled"`
+	MinSize       int  `json:"min_size" mapstructure:"compression_min_size"` // responses smaller than this (bytes) are sent as-is
+	Level         int  `json:"level" mapstructure:"compression_level"`       // -1 uses the encoder default
+}
+
+type HostHandoffConfig struct {
+	AutoHandoffEnabled bool     `json:"auto_handoff_enabled" mapstructure:"host_auto_handoff_enabled"`
+	GracePeriod        Duration `json:"grace_period" mapstructure:"host_handoff_grace_period"` // how long a disconnected host can take to come back
+}
+
+type RoomConfig struct {
+	DefaultControlMode string `json:"default_control_mode" mapstructure:"room_default_control_mode"` // control mode of new rooms whose host doesn't pick one, free or host_only
+	ReuseOpenRooms     bool   `json:"reuse_open_rooms" mapstructure:"room_reuse_open_rooms"`         // creating a room for a movie the host already has one for returns that room instead
+}
+
+type ProfileConfig struct {
+	AvatarAllowedHosts []string `json:"avatar_allowed_hosts" mapstructure:"profile_avatar_allowed_hosts"` // hosts external avatar URLs may point at, with their subdomains. empty allows any host
+}
+
+// chat history defaults, also used when a stored config leaves the values unset
+const (
+	DefaultChatHistoryMaxMessages = 50
+	DefaultChatHistoryTTL         = 24 * time.Hour
+)
+
+type ChatConfig struct {
+	HistoryMaxMessages int      `json:"history_max_messages" mapstructure:"chat_history_max_messages"` // recent messages kept per room, 0 uses the default
+	HistoryTTL         Duration `json:"history_ttl" mapstructure:"chat_history_ttl"`                   // how long an idle room's history is kept, 0 uses the default
+}
+
+// DefaultSyncMaxMessageBytes bounds a single client WebSocket message, also used when a stored config leaves it unset
+const DefaultSyncMaxMessageBytes = 64 * 1024
+
+// DefaultSyncReconcileInterval is how often an instance checks its rooms' participants against its connections,
+// also used when a stored config leaves it unset
+const DefaultSyncReconcileInterval = time.Minute
+
+// DefaultSyncParticipantStaleAfter is how long a participant nobody holds a connection for stays listed, also used
+// when a stored config leaves it unset
+const DefaultSyncParticipantStaleAfter = time.Minute
+
+// DefaultSyncPositionPersistInterval is how often service-api persists the playback position of rooms being watched
+const DefaultSyncPositionPersistInterval = 30 * time.Second
+
+// DefaultSyncMaxSendFailures is how many writes in a row may fail before a connection is dropped, also used when a
+// stored config leaves it unset
+const DefaultSyncMaxSendFailures = 3
+
+// DefaultSyncTolerance is how far a participant may drift from the room position before the server resyncs them
+const DefaultSyncTolerance = 500 * time.Millisecond
+
+// DefaultSyncResumeGracePeriod is how long a dropped client can reconnect with its resume token and skip the join
+const DefaultSyncResumeGracePeriod = 10 * time.Second
+
+// DefaultSyncIdleTimeout is how long a connection may stay silent before it is closed, also used when a stored
+// config leaves it unset. the server pings at half of it, so a client only times out once it stops answering
+const DefaultSyncIdleTimeout = time.Minute
+
+// reaction batching defaults, also used when a stored config leaves the values unset
+const (
+	DefaultSyncReactionBatchWindow          = 500 * time.Millisecond
+	DefaultSyncReactionBatchMinParticipants = 20
+)
+
+type SyncConfig struct {
+	PendingStateTTL              Duration `json:"pending_state_ttl" mapstructure:"sync_pending_state_ttl"`                             // how long a joiner waits for a live state snapshot, 0 uses the default
+	PendingStateSweepInterval    Duration `json:"pending_state_sweep_interval" mapstructure:"sync_pending_state_sweep_interval"`       // how often unanswered state requests are expired, 0 uses the default
+	MaxPendingStateRequests      int      `json:"max_pending_state_requests" mapstructure:"sync_max_pending_state_requests"`           // beyond this joiners get the stored state, 0 uses the default
+	MinPlayBuffer                Duration `json:"min_play_buffer" mapstructure:"sync_min_play_buffer"`                                 // buffered-ahead every participant needs before play is honored, 0 disables the gate
+	SyncTolerance                Duration `json:"sync_tolerance" mapstructure:"sync_tolerance"`                                        // drift from the room position a heartbeat may report before that participant is resynced, 0 disables correction
+	MaxRoomsPerUser              int      `json:"max_rooms_per_user" mapstructure:"sync_max_rooms_per_user"`                           // rooms a registered user can be connected to at once, 0 means unlimited
+	MaxMessageBytes              int      `json:"max_message_bytes" mapstructure:"sync_max_message_bytes"`                             // largest message a client may send, larger ones close the connection, 0 uses the default
+	ReconcileInterval            Duration `json:"reconcile_interval" mapstructure:"sync_reconcile_interval"`                           // how often participants without a live connection are removed, 0 uses the default
+	ParticipantStaleAfter        Duration `json:"participant_stale_after" mapstructure:"sync_participant_stale_after"`                 // how long since last seen a participant without a live connection is removed, 0 uses the default
+	PositionPersistInterval      Duration `json:"position_persist_interval" mapstructure:"sync_position_persist_interval"`             // how often watched rooms' positions are saved to resume them after their state expired, 0 disables
+	MaxSendFailures              int      `json:"max_send_failures" mapstructure:"sync_max_send_failures"`                             // consecutive failed writes after which a connection is dropped, 0 uses the default
+	ResumeGracePeriod            Duration `json:"resume_grace_period" mapstructure:"sync_resume_grace_period"`                         // how long a dropped client stays listed and may resume its session, 0 disables resuming
+	IdleTimeout                  Duration `json:"idle_timeout" mapstructure:"sync_idle_timeout"`                                       // how long without any message or pong before a connection is closed, 0 uses the default
+	ReactionBatchWindow          Duration `json:"reaction_batch_window" mapstructure:"sync_reaction_batch_window"`                     // how long reactions are collected into one batch in a large room, 0 uses the default
+	ReactionBatchMinParticipants int      `json:"reaction_batch_min_participants" mapstructure:"sync_reaction_batch_min_participants"` // participants from which a room's reactions are batched, smaller rooms get each right away, 0 uses the default
+}
+
+// feature flags of sync behaviors a deployment opts into, each is off unless the config turns it on
+const (
+	FeatureSyncDriftCorrection = "sync_drift_correction" // resync a participant whose heartbeat drifted beyond the sync tolerance
+	FeatureSyncSegmentPosition = "sync_segment_position" // tell clients which segment a play or seek lands in
+	FeatureSyncReactionBatch   = "sync_reaction_batch"   // count the reactions of large rooms per emoji instead of sending each
+)
+
+// KnownFeatures lists every feature flag the services consult
+var KnownFeatures = []string{
+	FeatureSyncDriftCorrection,
+	FeatureSyncSegmentPosition,
+	FeatureSyncReactionBatch,
+}
+
+type FeatureConfig struct {
+	Flags map[string]bool `json:"flags" mapstructure:"feature_flags"` // flag name to whether it is on, a flag left out is off
+}
+
+// Enabled reports whether a feature flag is turned on
+func (c FeatureConfig) Enabled(flag string) bool {
+	return c.Flags[flag]
+}
+
+// Snapshot returns every known flag with whether it is on, along with any configured flag no service consults
+func (c FeatureConfig) Snapshot() map[string]bool {
+	flags := make(map[string]bool, len(KnownFeatures)+len(c.Flags))
+	for _, flag := range KnownFeatures {
+		flags[flag] = false
+	}
+	for flag, enabled := range c.Flags {
+		flags[flag] = enabled
+	}
+	return flags
+}
+
+// streaming modes, the service-api README describes the tradeoffs
+const (
+	StreamingModeProxy    = "proxy"    // the API serves playlists and segments, storage is never exposed
+	StreamingModeRedirect = "redirect" // the API serves playlists and redirects segments to signed storage URLs
+	StreamingModeDirect   = "direct"   // clients get signed storage URLs and fetch everything from storage
+)
+
+// DefaultIdempotencyKeyTTL is how long a response is replayed for its Idempotency-Key, also used when a stored config leaves it unset
+const DefaultIdempotencyKeyTTL = 24 * time.Hour
+
+type IdempotencyConfig struct {
+	KeyTTL Duration `json:"key_ttl" mapstructure:"idempotency_key_ttl"` // how long a retry with the same key gets the original response, 0 uses the default
+}
+
+// DefaultMaxBatchURLs is how many files one batch URL request may ask for, also used when a stored config leaves it
+// unset. every file is signed within one storage operation timeout, so larger batches risk failing as a whole
+const DefaultMaxBatchURLs = 100
+
+// DefaultPlaylistCacheTTL is how long players and CDNs may reuse a playlist before revalidating it, also used when
+// a stored config leaves it unset. playlists change when a movie is reprocessed, unlike segments
+const DefaultPlaylistCacheTTL = 30 * time.Second
+
+// HLS playlist content types, both are registered for m3u8 and players differ in which one they expect
+const (
+	PlaylistContentTypeApple  = "application/vnd.apple.mpegurl"
+	PlaylistContentTypeLegacy = "application/x-mpegURL"
+)
+
+// DefaultPlaylistContentType is the content type playlists are stored and served with unless configured otherwise
+const DefaultPlaylistContentType = PlaylistContentTypeApple
+
+type StreamingConfig struct {
+	Mode                string   `json:"mode" mapstructure:"streaming_mode"`                                   // proxy, redirect or direct, empty means direct
+	GuestCookie         bool     `json:"guest_cookie" mapstructure:"streaming_guest_cookie"`                   // hand guests a signed cookie so tokens stay out of URLs
+	GuestCookieDomain   string   `json:"guest_cookie_domain" mapstructure:"streaming_guest_cookie_domain"`     // domain of the guest cookie, empty scopes it to the API host
+	MaxBatchURLs        int      `json:"max_batch_urls" mapstructure:"streaming_max_batch_urls"`               // files one batch URL request may ask for, 0 uses the default
+	PlaylistCacheTTL    Duration `json:"playlist_cache_ttl" mapstructure:"streaming_playlist_cache_ttl"`       // max-age of playlists, 0 uses the default
+	PlaylistContentType string   `json:"playlist_content_type" mapstructure:"streaming_playlist_content_type"` // content type of playlists, empty uses the default
+	MovieCacheTTL       Duration `json:"movie_cache_ttl" mapstructure:"streaming_movie_cache_ttl"`             // how long the streaming routes reuse a movie read from the database, 0 uses the default
+}
+
+// DefaultMovieCacheTTL is how long the streaming routes reuse a movie read from the database, also used when a
+// stored config leaves it unset. writes on the same instance drop the movie right away, other instances catch up
+// within this time
+const DefaultMovieCacheTTL = 5 * time.Second
+
+// MovieCacheMaxAge returns how long the streaming routes may reuse a movie read from the database
+func (c StreamingConfig) MovieCacheMaxAge() time.Duration {
+	if c.MovieCacheTTL <= 0 {
+		return DefaultMovieCacheTTL
+	}
+	return c.MovieCacheTTL.ToDuration()
+}
+
+// EffectivePlaylistContentType returns the configured playlist content type, unknown values fall back to the default
+func (c StreamingConfig) EffectivePlaylistContentType() string {
+	switch c.PlaylistContentType {
+	case PlaylistContentTypeApple, PlaylistContentTypeLegacy:
+		return c.PlaylistContentType
+	default:
+		return DefaultPlaylistContentType
+	}
+}
+
+// EffectiveMode returns the configured streaming mode, unknown values fall back to direct
+func (c StreamingConfig) EffectiveMode() string {
+	switch c.Mode {
+	case StreamingModeProxy, StreamingModeRedirect:
+		return c.Mode
+	default:
+		return StreamingModeDirect
+	}
+}
+
+// BatchURLLimit returns how many files one batch URL request may ask for
+func (c StreamingConfig) BatchURLLimit() int {
+	if c.MaxBatchURLs <= 0 {
+		return DefaultMaxBatchURLs
+	}
+	return c.MaxBatchURLs
+}
+
+// PlaylistMaxAge returns how long a served or signed playlist may be cached
+func (c StreamingConfig) PlaylistMaxAge() time.Duration {
+	if c.PlaylistCacheTTL <= 0 {
+		return DefaultPlaylistCacheTTL
+	}
+	return c.PlaylistCacheTTL.ToDuration()
 }
 
 func init() {
@@ -193,6 +635,8 @@ func NewConfig() *Config {
 			log.Fatalf("failed to load configuration from Google Secret Manager for %s environment: %v", environment, err)
 		}
 
+		applyTLSFromEnvironment(config)
+
 		log.Printf("Configuration loaded from Google Secret Manager for %s environment", environment)
 		return config
 	}
@@ -221,9 +665,11 @@ func loadFromEnvironment() *Config {
 			MaxIdleConns:    parseInt("DB_MAX_IDLE_CONNS"),
 			ConnMaxLifetime: Duration(parseDuration("DB_CONN_MAX_LIFETIME")),
 			SSLMode:         getOptionalSecret("DB_SSL_MODE", "disable"), // Default to "disable" if not set
+			QueryTimeout:    Duration(parseOptionalDuration("DB_QUERY_TIMEOUT", DefaultDBQueryTimeout)),
 		},
 		Log: LogConfig{
-			Level: getOptionalSecret("LOG_LEVEL", "info"),
+			Level:      getOptionalSecret("LOG_LEVEL", "info"),
+			SampleRate: parseOptionalInt("LOG_SAMPLE_RATE", 1),
 		},
 		Storage: StorageConfig{
 			Provider:            getOptionalSecret("STORAGE_PROVIDER", "minio"),
@@ -239,11 +685,28 @@ func loadFromEnvironment() *Config {
 				PublicEndpoint: getOptionalSecret("MINIO_PUBLIC_ENDPOINT", ""),
 			},
 			VideoProcessing: VideoConfig{
-				TempDir:     getOptionalSecret("VIDEO_PROCESSING_TEMP_DIR", "/tmp/watch-party-processing"),
-				HLSBaseURL:  getOptionalSecret("VIDEO_HLS_BASE_URL", "http://localhost:8080/api/v1/files"),
-				FFmpegPath:  getOptionalSecret("FFMPEG_PATH", "ffmpeg"),
-				FFprobePath: getOptionalSecret("FFPROBE_PATH", "ffprobe"),
+				TempDir:               getOptionalSecret("VIDEO_PROCESSING_TEMP_DIR", "/tmp/watch-party-processing"),
+				HLSBaseURL:            getOptionalSecret("VIDEO_HLS_BASE_URL", "http://localhost:8080/api/v1/files"),
+				FFmpegPath:            getOptionalSecret("FFMPEG_PATH", "ffmpeg"),
+				FFprobePath:           getOptionalSecret("FFPROBE_PATH", "ffprobe"),
+				Poster:                loadImageArtifactConfig("VIDEO_POSTER", DefaultPosterConfig),
+				Preview:               loadImageArtifactConfig("VIDEO_PREVIEW", DefaultPreviewConfig),
+				FastPreviewFullLadder: parseOptionalBool("VIDEO_FAST_PREVIEW_FULL_LADDER", true),
+				DownloadConcurrency:   parseOptionalInt("VIDEO_DOWNLOAD_CONCURRENCY", 4),
+				DownloadChunkSizeMB:   parseOptionalInt("VIDEO_DOWNLOAD_CHUNK_SIZE_MB", 16),
+				EncryptSegments:       parseOptionalBool("VIDEO_ENCRYPT_SEGMENTS", false),
+				KeyBaseURL:            getOptionalSecret("VIDEO_KEY_BASE_URL", ""),
+				KeySecret:             getOptionalSecret("VIDEO_KEY_SECRET", ""),
+				AllowedExtensions:     parseOptionalStringSlice("VIDEO_ALLOWED_EXTENSIONS", ""),
+				MaxDuration:           Duration(parseOptionalDuration("VIDEO_MAX_DURATION", DefaultVideoMaxDuration)),
+				StuckAfter:            Duration(parseOptionalDuration("VIDEO_STUCK_AFTER", 0)),
 			},
+			MaxUploadBytes:       int64(parseOptionalInt("MAX_UPLOAD_BYTES", DefaultMaxUploadBytes)),
+			UploadReaperInterval: Duration(parseOptionalDuration("UPLOAD_REAPER_INTERVAL", 10*time.Minute)),
+			UploadNotifications:  parseOptionalBool("STORAGE_UPLOAD_NOTIFICATIONS", false),
+			NotificationToken:    getOptionalSecret("STORAGE_NOTIFICATION_TOKEN", ""),
+			OperationTimeout:     Duration(parseOptionalDuration("STORAGE_OPERATION_TIMEOUT", DefaultStorageOperationTimeout)),
+			TransferTimeout:      Duration(parseOptionalDuration("STORAGE_TRANSFER_TIMEOUT", DefaultStorageTransferTimeout)),
 		},
 		Email: EmailConfig{
 			Provider: getOptionalSecret("EMAIL_PROVIDER", "smtp"),
@@ -260,8 +723,9 @@ func loadFromEnvironment() *Config {
 				FromName:  getOptionalSecret("EMAIL_SENDGRID_FROM_NAME", ""),
 			},
 			Templates: EmailTemplateConfig{
-				BaseURL: getOptionalSecret("EMAIL_TEMPLATE_BASE_URL", "http://localhost:3000"),
-				AppName: getOptionalSecret("EMAIL_TEMPLATE_APP_NAME", "WatchParty"),
+				BaseURL:         getOptionalSecret("EMAIL_TEMPLATE_BASE_URL", "http://localhost:3000"),
+				AppName:         getOptionalSecret("EMAIL_TEMPLATE_APP_NAME", "WatchParty"),
+				AllowedBaseURLs: parseOptionalStringSlice("EMAIL_TEMPLATE_ALLOWED_BASE_URLS", ""),
 			},
 		},
 		Redis: RedisConfig{
@@ -271,9 +735,68 @@ func loadFromEnvironment() *Config {
 			DB:       parseOptionalInt("REDIS_DB", 0),
 		},
 		CORS: CORSConfig{
-			AllowedOrigins: parseOptionalStringSlice("CORS_ALLOWED_ORIGINS", "http://localhost:3000,http://localhost:5173,http://localhost:5174"),
-			AllowedMethods: parseOptionalStringSlice("CORS_ALLOWED_METHODS", "GET,POST,PUT,DELETE,OPTIONS"),
-			AllowedHeaders: parseOptionalStringSlice("CORS_ALLOWED_HEADERS", "Content-Type,Authorization,x-guest-token,User-Agent,Sec-Ch-Ua,Sec-Ch-Ua-Mobile,Sec-Ch-Ua-Platform,Accept,Accept-Language,Accept-Encoding,Cache-Control,Connection,Host,Origin,Referer,Sec-Fetch-Dest,Sec-Fetch-Mode,Sec-Fetch-Site,X-Requested-With"),
+			AllowedOrigins:           parseOptionalStringSlice("CORS_ALLOWED_ORIGINS", "http://localhost:3000,http://localhost:5173,http://localhost:5174"),
+			AllowedMethods:           parseOptionalStringSlice("CORS_ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE,OPTIONS"),
+			AllowedHeaders:           parseOptionalStringSlice("CORS_ALLOWED_HEADERS", "Content-Type,Authorization,x-guest-token,User-Agent,Sec-Ch-Ua,Sec-Ch-Ua-Mobile,Sec-Ch-Ua-Platform,Accept,Accept-Language,Accept-Encoding,Cache-Control,Connection,Host,Origin,Referer,Sec-Fetch-Dest,Sec-Fetch-Mode,Sec-Fetch-Site,X-Requested-With,Idempotency-Key"),
+			AllowAllWebSocketOrigins: parseOptionalBool("CORS_ALLOW_ALL_WEBSOCKET_ORIGINS", false),
+		},
+		Compression: CompressionConfig{
+			Enabled:       parseOptionalBool("COMPRESSION_ENABLED", true),
+			BrotliEnabled: parseOptionalBool("COMPRESSION_BROTLI_ENABLED", true),
+			MinSize:       parseOptionalInt("COMPRESSION_MIN_SIZE", 1024),
+			Level:         parseOptionalInt("COMPRESSION_LEVEL", -1),
+		},
+		HostHandoff: HostHandoffConfig{
+			AutoHandoffEnabled: parseOptionalBool("HOST_AUTO_HANDOFF_ENABLED", false),
+			GracePeriod:        Duration(parseOptionalDuration("HOST_HANDOFF_GRACE_PERIOD", 30*time.Second)),
+		},
+		Room: RoomConfig{
+			DefaultControlMode: getOptionalSecret("ROOM_DEFAULT_CONTROL_MODE", "free"),
+			ReuseOpenRooms:     parseOptionalBool("ROOM_REUSE_OPEN_ROOMS", false),
+		},
+		Profile: ProfileConfig{
+			AvatarAllowedHosts: parseOptionalStringSlice("PROFILE_AVATAR_ALLOWED_HOSTS", ""),
+		},
+		Chat: ChatConfig{
+			HistoryMaxMessages: parseOptionalInt("CHAT_HISTORY_MAX_MESSAGES", DefaultChatHistoryMaxMessages),
+			HistoryTTL:         Duration(parseOptionalDuration("CHAT_HISTORY_TTL", DefaultChatHistoryTTL)),
+		},
+		Sync: SyncConfig{
+			PendingStateTTL:              Duration(parseOptionalDuration("SYNC_PENDING_STATE_TTL", 10*time.Second)),
+			PendingStateSweepInterval:    Duration(parseOptionalDuration("SYNC_PENDING_STATE_SWEEP_INTERVAL", 5*time.Second)),
+			MaxPendingStateRequests:      parseOptionalInt("SYNC_MAX_PENDING_STATE_REQUESTS", 10000),
+			MinPlayBuffer:                Duration(parseOptionalDuration("SYNC_MIN_PLAY_BUFFER", 0)),
+			SyncTolerance:                Duration(parseOptionalDuration("SYNC_TOLERANCE", DefaultSyncTolerance)),
+			MaxRoomsPerUser:              parseOptionalInt("SYNC_MAX_ROOMS_PER_USER", 0),
+			MaxMessageBytes:              parseOptionalInt("SYNC_MAX_MESSAGE_BYTES", DefaultSyncMaxMessageBytes),
+			ReconcileInterval:            Duration(parseOptionalDuration("SYNC_RECONCILE_INTERVAL", DefaultSyncReconcileInterval)),
+			ParticipantStaleAfter:        Duration(parseOptionalDuration("SYNC_PARTICIPANT_STALE_AFTER", DefaultSyncParticipantStaleAfter)),
+			PositionPersistInterval:      Duration(parseOptionalDuration("SYNC_POSITION_PERSIST_INTERVAL", DefaultSyncPositionPersistInterval)),
+			MaxSendFailures:              parseOptionalInt("SYNC_MAX_SEND_FAILURES", DefaultSyncMaxSendFailures),
+			ResumeGracePeriod:            Duration(parseOptionalDuration("SYNC_RESUME_GRACE_PERIOD", DefaultSyncResumeGracePeriod)),
+			IdleTimeout:                  Duration(parseOptionalDuration("SYNC_IDLE_TIMEOUT", DefaultSyncIdleTimeout)),
+			ReactionBatchWindow:          Duration(parseOptionalDuration("SYNC_REACTION_BATCH_WINDOW", DefaultSyncReactionBatchWindow)),
+			ReactionBatchMinParticipants: parseOptionalInt("SYNC_REACTION_BATCH_MIN_PARTICIPANTS", DefaultSyncReactionBatchMinParticipants),
+		},
+		Streaming: StreamingConfig{
+			Mode:                getOptionalSecret("STREAMING_MODE", StreamingModeDirect),
+			GuestCookie:         parseOptionalBool("STREAMING_GUEST_COOKIE", false),
+			GuestCookieDomain:   getOptionalSecret("STREAMING_GUEST_COOKIE_DOMAIN", ""),
+			MaxBatchURLs:        parseOptionalInt("STREAMING_MAX_BATCH_URLS", DefaultMaxBatchURLs),
+			PlaylistCacheTTL:    Duration(parseOptionalDuration("STREAMING_PLAYLIST_CACHE_TTL", DefaultPlaylistCacheTTL)),
+			PlaylistContentType: getOptionalSecret("STREAMING_PLAYLIST_CONTENT_TYPE", DefaultPlaylistContentType),
+			MovieCacheTTL:       Duration(parseOptionalDuration("STREAMING_MOVIE_CACHE_TTL", DefaultMovieCacheTTL)),
+		},
+		Idempotency: IdempotencyConfig{
+			KeyTTL: Duration(parseOptionalDuration("IDEMPOTENCY_KEY_TTL", DefaultIdempotencyKeyTTL)),
+		},
+		TLS: TLSConfig{
+			Enabled:  parseOptionalBool("SSL_ENABLED", false),
+			CertFile: getOptionalSecret("SSL_CERT_PATH", ""),
+			KeyFile:  getOptionalSecret("SSL_KEY_PATH", ""),
+		},
+		Features: FeatureConfig{
+			Flags: parseOptionalFlags("FEATURE_FLAGS"),
 		},
 	}
 }
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSConfigAllowsWebSocketOrigin(t *testing.T) {
+	cors := CORSConfig{AllowedOrigins: []string{"https://watch.example.com", "http://localhost:5173"}}
+
+	assert.True(t, cors.AllowsWebSocketOrigin("https://watch.example.com"))
+	assert.True(t, cors.AllowsWebSocketOrigin("HTTPS://Watch.Example.com"))
+	assert.True(t, cors.AllowsWebSocketOrigin(""), "non-browser clients send no origin")
+	assert.False(t, cors.AllowsWebSocketOrigin("https://evil.example"))
+	assert.False(t, cors.AllowsWebSocketOrigin("https://watch.example.com.evil.example"))
+
+	cors.AllowAllWebSocketOrigins = true
+	assert.True(t, cors.AllowsWebSocketOrigin("https://evil.example"))
+
+	wildcard := CORSConfig{AllowedOrigins: []string{"*"}}
+	assert.True(t, wildcard.AllowsWebSocketOrigin("https://evil.example"))
+}
+
+func TestEffectivePlaylistContentType(t *testing.T) {
+	assert.Equal(t, DefaultPlaylistContentType, StreamingConfig{}.EffectivePlaylistContentType())
+	assert.Equal(t, PlaylistContentTypeLegacy, StreamingConfig{PlaylistContentType: PlaylistContentTypeLegacy}.EffectivePlaylistContentType())
+	assert.Equal(t, DefaultPlaylistContentType, StreamingConfig{PlaylistContentType: "text/plain"}.EffectivePlaylistContentType())
+}
+
+func TestVideoConfigAllowedExtensions(t *testing.T) {
+	defaults := VideoConfig{}
+	assert.True(t, defaults.AllowsExtension(".MKV"))
+	assert.False(t, defaults.AllowsExtension(".ts"), "transport streams are off by default")
+	assert.NoError(t, defaults.ValidateAllowedExtensions())
+
+	custom := VideoConfig{AllowedExtensions: []string{"mp4", " .TS "}}
+	assert.Equal(t, []string{".mp4", ".ts"}, custom.InputExtensions())
+	assert.True(t, custom.AllowsExtension(".ts"))
+	assert.False(t, custom.AllowsExtension(".mkv"))
+	assert.NoError(t, custom.ValidateAllowedExtensions())
+
+	typo := VideoConfig{AllowedExtensions: []string{".mp4", ".mp5"}}
+	assert.Error(t, typo.ValidateAllowedExtensions())
+
+	assert.Equal(t, "video/x-flv", VideoMimeType(".FLV"))
+	assert.Equal(t, "application/octet-stream", VideoMimeType(".txt"))
+}
+
+func TestStuckTranscodeThreshold(t *testing.T) {
+	assert.Equal(t, 12*time.Hour, VideoConfig{}.StuckTranscodeThreshold(), "the default outlasts the longest accepted source")
+	assert.Equal(t, 4*time.Hour, VideoConfig{MaxDuration: Duration(2 * time.Hour)}.StuckTranscodeThreshold())
+	assert.Equal(t, time.Hour, VideoConfig{StuckAfter: Duration(time.Hour)}.StuckTranscodeThreshold())
+}
+
+func TestValidateSegmentEncryption(t *testing.T) {
+	assert.NoError(t, VideoConfig{}.ValidateSegmentEncryption(), "nothing is needed while encryption is off")
+
+	secret := strings.Repeat("s", MinSegmentKeySecretLength)
+	assert.Error(t, VideoConfig{EncryptSegments: true, KeySecret: secret}.ValidateSegmentEncryption(), "no key base URL")
+	assert.Error(t, VideoConfig{EncryptSegments: true, KeyBaseURL: "https://api.example.com/api/v1/videos", KeySecret: "short"}.ValidateSegmentEncryption())
+
+	valid := VideoConfig{EncryptSegments: true, KeyBaseURL: "https://api.example.com/api/v1/videos/", KeySecret: secret}
+	assert.NoError(t, valid.ValidateSegmentEncryption())
+	assert.Equal(t, "https://api.example.com/api/v1/videos", valid.SegmentKeyBaseURL())
+}
+
+func TestParseFeatureFlags(t *testing.T) {
+	flags := parseFeatureFlags(" sync_drift_correction, sync_segment_position=false ,,future_flag=true,broken=maybe")
+	assert.Equal(t, map[string]bool{
+		FeatureSyncDriftCorrection: true,
+		FeatureSyncSegmentPosition: false,
+		"future_flag":              true,
+		"broken":                   false,
+	}, flags)
+	assert.Empty(t, parseFeatureFlags(""))
+
+	features := FeatureConfig{Flags: map[string]bool{FeatureSyncDriftCorrection: true}}
+	assert.True(t, features.Enabled(FeatureSyncDriftCorrection))
+	assert.False(t, features.Enabled(FeatureSyncSegmentPosition), "flags are off unless turned on")
+	assert.Equal(t, map[string]bool{
+		FeatureSyncDriftCorrection: true,
+		FeatureSyncSegmentPosition: false,
+		FeatureSyncReactionBatch:   false,
+	}, features.Snapshot())
+}
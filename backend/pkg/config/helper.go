@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -59,6 +60,17 @@ func parseDuration(key string) time.Duration {
 	return val
 }
 
+// parseOptionalDuration is a helper func to parse an optional duration from a secret with default value.
+func parseOptionalDuration(key string, defaultValue time.Duration) time.Duration {
+	val := getOptionalSecret(key, defaultValue.String())
+	parsed, err := time.ParseDuration(val)
+	if err != nil {
+		log.Printf("WARNING: Invalid duration value for secret %q, using default %s: %v", key, defaultValue, err)
+		return defaultValue
+	}
+	return parsed
+}
+
 // parseBool is a helper func to parse a boolean from a secret.
 func parseBool(key string) bool {
 	val := getOptionalSecret(key, "false")
@@ -70,6 +82,17 @@ func parseBool(key string) bool {
 	return parsed
 }
 
+// parseOptionalBool is a helper func to parse an optional boolean from a secret with default value.
+func parseOptionalBool(key string, defaultValue bool) bool {
+	val := getOptionalSecret(key, strconv.FormatBool(defaultValue))
+	parsed, err := strconv.ParseBool(val)
+	if err != nil {
+		log.Printf("WARNING: Invalid boolean value for secret %q, using default %v: %v", key, defaultValue, err)
+		return defaultValue
+	}
+	return parsed
+}
+
 // parseOptionalInt is a helper func to parse an optional integer from a secret with default value.
 func parseOptionalInt(key string, defaultValue int) int {
 	val := getOptionalSecret(key, strconv.Itoa(defaultValue))
@@ -98,3 +121,47 @@ func parseOptionalStringSlice(key, defaultValue string) []string {
 	}
 	return result
 }
+
+// parseOptionalFlags parses a comma-separated list of feature flags, see parseFeatureFlags
+func parseOptionalFlags(key string) map[string]bool {
+	return parseFeatureFlags(getOptionalSecret(key, ""))
+}
+
+// parseFeatureFlags parses flags given as "name" or "name=bool", a bare name turns the flag on. a flag no service
+// consults is kept but warned about, it is most likely a typo
+func parseFeatureFlags(value string) map[string]bool {
+	flags := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		name, rawValue, hasValue := strings.Cut(strings.TrimSpace(part), "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		enabled := true
+		if hasValue {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(rawValue))
+			if err != nil {
+				log.Printf("WARNING: Invalid value for feature flag %q, leaving it off: %v", name, err)
+				parsed = false
+			}
+			enabled = parsed
+		}
+		if !slices.Contains(KnownFeatures, name) {
+			log.Printf("WARNING: Unknown feature flag %q", name)
+		}
+		flags[name] = enabled
+	}
+	return flags
+}
+
+// loadImageArtifactConfig reads the <prefix>_ENABLED, _WIDTH, _QUALITY, _FRAME_SELECTION and _OFFSET settings of an image artifact
+func loadImageArtifactConfig(prefix string, defaults ImageArtifactConfig) ImageArtifactConfig {
+	return ImageArtifactConfig{
+		Enabled:        parseOptionalBool(prefix+"_ENABLED", defaults.Enabled),
+		Width:          parseOptionalInt(prefix+"_WIDTH", defaults.Width),
+		Quality:        parseOptionalInt(prefix+"_QUALITY", defaults.Quality),
+		FrameSelection: getOptionalSecret(prefix+"_FRAME_SELECTION", defaults.FrameSelection),
+		Offset:         Duration(parseOptionalDuration(prefix+"_OFFSET", defaults.Offset.ToDuration())),
+	}
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+)
+
+// TLSConfig controls TLS termination by the API and sync servers themselves
+type TLSConfig struct {
+	Enabled  bool   `json:"enabled" mapstructure:"ssl_enabled"`
+	CertFile string `json:"cert_file" mapstructure:"ssl_cert_path"`
+	KeyFile  string `json:"key_file" mapstructure:"ssl_key_path"`
+}
+
+// Load reads the certificate and key at startup, returning nil when TLS is disabled
+func (t TLSConfig) Load() (*tls.Config, error) {
+	if !t.Enabled {
+		return nil, nil
+	}
+
+	if t.CertFile == "" || t.KeyFile == "" {
+		return nil, fmt.Errorf("TLS is enabled but SSL_CERT_PATH or SSL_KEY_PATH is not set")
+	}
+
+	cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// applyTLSFromEnvironment lets deployments that mount certificates enable TLS without touching the stored config
+func applyTLSFromEnvironment(cfg *Config) {
+	if os.Getenv("SSL_ENABLED") == "" {
+		return
+	}
+
+	cfg.TLS = TLSConfig{
+		Enabled:  parseOptionalBool("SSL_ENABLED", false),
+		CertFile: getOptionalSecret("SSL_CERT_PATH", ""),
+		KeyFile:  getOptionalSecret("SSL_KEY_PATH", ""),
+	}
+}
@@ -41,16 +41,19 @@ func newPgDB(
 	return db, nil
 }
 
+// getDSN builds the connection string. statement_timeout is sent as a run-time parameter so the server cancels
+// any statement that outlives it, including ones issued without a context deadline
 func getDSN(
 	cfg config.DatabaseConfig,
 ) string {
 	return fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s statement_timeout=%d",
 		cfg.Host,
 		cfg.Port,
 		cfg.Username,
 		cfg.Password,
 		cfg.Name,
 		cfg.SSLMode,
+		cfg.StatementTimeout().Milliseconds(),
 	)
 }
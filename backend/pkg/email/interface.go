@@ -16,6 +16,11 @@ type Provider interface {
 	ValidateProvider(ctx context.Context) error
 }
 
+// TemplateSender sends templated emails, implemented by providers and by the queue that delivers through them
+type TemplateSender interface {
+	SendTemplateEmail(ctx context.Context, to []string, templateName string, data interface{}) error
+}
+
 // EmailBody represents the email content
 type EmailBody struct {
 	HTML string // HTML content
@@ -30,6 +35,12 @@ type TemplateData struct {
 	AppURL        string
 }
 
+// DedupeScoper is implemented by template data whose emails are deduplicated per scope as well as per recipient
+// and template, so inviting someone to two rooms sends both invitations
+type DedupeScoper interface {
+	DedupeScope() string
+}
+
 // InvitationTemplateData represents data for room invitation emails
 type InvitationTemplateData struct {
 	TemplateData
@@ -39,3 +50,8 @@ type InvitationTemplateData struct {
 	InviteURL   string
 	ExpiresAt   string
 }
+
+// DedupeScope scopes invitation dedupe to the room
+func (d InvitationTemplateData) DedupeScope() string {
+	return d.RoomID
+}
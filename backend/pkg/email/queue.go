@@ -0,0 +1,310 @@
+package email
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	// queuePollInterval is how often the worker looks for emails whose attempt is due
+	queuePollInterval = 2 * time.Second
+	// queueBatchSize bounds the emails one poll sends
+	queueBatchSize = 20
+	// sendLease is how long a claimed email is hidden from other workers, a worker that dies mid-send
+	// leaves it to be retried after the lease
+	sendLease = 2 * time.Minute
+	// maxSendAttempts is how often an email is tried before it is marked failed
+	maxSendAttempts = 5
+	// retryBaseDelay is the wait after the first failed attempt, doubled after each further one
+	retryBaseDelay = 30 * time.Second
+	// maxRetryDelay caps the wait between attempts
+	maxRetryDelay = 30 * time.Minute
+	// emailJobTTL keeps delivery records long enough to look into failures
+	emailJobTTL = 7 * 24 * time.Hour
+	// dedupeWindow is how long the same email to the same recipients is not queued again, so a retried request
+	// or a double click doesn't send it twice
+	dedupeWindow = 10 * time.Minute
+)
+
+// Queue sends templated emails through a Redis-backed queue, so request handlers never wait for the provider
+// and transient provider failures are retried. without Redis emails are sent right away.
+type Queue struct {
+	provider Provider
+	redis    *redis.Client // optional, nil sends synchronously
+}
+
+// NewQueue creates an email queue delivering through provider
+func NewQueue(provider Provider, redisClient *redis.Client) *Queue {
+	return &Queue{
+		provider: provider,
+		redis:    redisClient,
+	}
+}
+
+// SendTemplateEmail queues a templated email for delivery. an email to the same recipients with the same template
+// and dedupe scope queued within dedupeWindow is dropped as a duplicate
+func (q *Queue) SendTemplateEmail(ctx context.Context, to []string, templateName string, data interface{}) error {
+	if q.redis == nil {
+		return q.provider.SendTemplateEmail(ctx, to, templateName, data)
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode email data: %w", err)
+	}
+
+	now := time.Now()
+	jobID := uuid.New()
+
+	dedupeKey := redis.EmailDedupeKey(dedupeDigest(to, templateName, data))
+	fresh, err := q.redis.SetNX(ctx, dedupeKey, jobID.String(), dedupeWindow)
+	if err != nil {
+		return fmt.Errorf("failed to check for duplicate email: %w", err)
+	}
+	if !fresh {
+		logger.Infof("skipping duplicate %s email queued within %s", templateName, dedupeWindow)
+		return nil
+	}
+
+	err = q.enqueue(ctx, jobID, to, templateName, encoded, now)
+	if err != nil {
+		// let a retry of the request queue the email after all
+		if delErr := q.redis.Delete(ctx, dedupeKey); delErr != nil {
+			logger.Errorf(delErr, "failed to release dedupe key of email job %s", jobID)
+		}
+		return err
+	}
+	return nil
+}
+
+// dedupeDigest identifies an email by its recipients, in any order and case, its template and its dedupe scope
+func dedupeDigest(to []string, templateName string, data interface{}) string {
+	recipients := make([]string, len(to))
+	for i, address := range to {
+		recipients[i] = strings.ToLower(strings.TrimSpace(address))
+	}
+	sort.Strings(recipients)
+
+	scope := ""
+	if scoper, ok := data.(DedupeScoper); ok {
+		scope = scoper.DedupeScope()
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(recipients, ",") + "\n" + templateName + "\n" + scope))
+	return hex.EncodeToString(sum[:])
+}
+
+// enqueue stores a job and schedules its first attempt at now
+func (q *Queue) enqueue(ctx context.Context, jobID uuid.UUID, to []string, templateName string, encoded []byte, now time.Time) error {
+	job := &model.EmailJob{
+		ID:            jobID,
+		To:            to,
+		Template:      templateName,
+		Data:          encoded,
+		Status:        model.EmailStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	err := q.redis.Set(ctx, redis.EmailJobKey(job.ID.String()), job, emailJobTTL)
+	if err != nil {
+		return fmt.Errorf("failed to store email job: %w", err)
+	}
+
+	err = q.redis.ZAdd(ctx, redis.EmailQueueKey, goredis.Z{Score: float64(now.UnixMilli()), Member: job.ID.String()})
+	if err != nil {
+		return fmt.Errorf("failed to queue email: %w", err)
+	}
+
+	return nil
+}
+
+// Run delivers queued emails until ctx is done
+func (q *Queue) Run(ctx context.Context) {
+	if q.redis == nil {
+		logger.Info("email queue disabled without Redis, emails are sent right away")
+		return
+	}
+
+	ticker := time.NewTicker(queuePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := q.ProcessDue(ctx, time.Now())
+			if err != nil {
+				logger.Error(err, "failed to process email queue")
+			}
+		}
+	}
+}
+
+// ProcessDue attempts the queued emails that are due at now and returns how many were sent
+func (q *Queue) ProcessDue(ctx context.Context, now time.Time) (int, error) {
+	due := float64(now.UnixMilli())
+	jobIDs, err := q.redis.ZRangeByScore(ctx, redis.EmailQueueKey, &goredis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: queueBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, jobID := range jobIDs {
+		// the lease keeps other instances from sending the same email
+		leased, err := q.redis.LeaseDueMember(ctx, redis.EmailQueueKey, jobID, due, float64(now.Add(sendLease).UnixMilli()))
+		if err != nil {
+			return sent, err
+		}
+		if !leased {
+			continue
+		}
+
+		if q.deliver(ctx, jobID, now) {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// deliver makes one attempt at a queued email, rescheduling or failing it when the provider errors
+func (q *Queue) deliver(ctx context.Context, jobID string, now time.Time) bool {
+	var job model.EmailJob
+	err := q.redis.Get(ctx, redis.EmailJobKey(jobID), &job)
+	if err != nil {
+		exists, existsErr := q.redis.Exists(ctx, redis.EmailJobKey(jobID))
+		if existsErr == nil && exists == 0 {
+			// the record expired, nothing is left to send
+			logger.Warnf("dropping email job %s, its record is gone", jobID)
+			q.dequeue(ctx, jobID)
+		} else {
+			logger.Errorf(err, "failed to load email job %s, retrying after its lease", jobID)
+		}
+		return false
+	}
+
+	data, err := decodeTemplateData(job.Template, job.Data)
+	if err == nil {
+		err = q.provider.SendTemplateEmail(ctx, job.To, job.Template, data)
+	}
+
+	job.Attempts++
+	job.UpdatedAt = now
+
+	switch {
+	case err == nil:
+		job.Status = model.EmailStatusSent
+		job.LastError = ""
+		q.dequeue(ctx, jobID)
+	case job.Attempts >= maxSendAttempts:
+		job.Status = model.EmailStatusFailed
+		job.LastError = err.Error()
+		q.dequeue(ctx, jobID)
+		zErr := q.redis.ZAdd(ctx, redis.EmailFailedKey, goredis.Z{Score: float64(now.UnixMilli()), Member: jobID})
+		if zErr != nil {
+			logger.Errorf(zErr, "failed to record failed email job %s", jobID)
+		}
+		logger.Errorf(err, "giving up on email job %s (%s) after %d attempts", jobID, job.Template, job.Attempts)
+	default:
+		job.LastError = err.Error()
+		job.NextAttemptAt = now.Add(retryDelay(job.Attempts))
+		zErr := q.redis.ZAdd(ctx, redis.EmailQueueKey, goredis.Z{Score: float64(job.NextAttemptAt.UnixMilli()), Member: jobID})
+		if zErr != nil {
+			logger.Errorf(zErr, "failed to reschedule email job %s", jobID)
+		}
+		logger.Warnf("email job %s attempt %d failed, retrying at %s: %v", jobID, job.Attempts, job.NextAttemptAt.Format(time.RFC3339), err)
+	}
+
+	err = q.redis.Set(ctx, redis.EmailJobKey(jobID), &job, emailJobTTL)
+	if err != nil {
+		logger.Errorf(err, "failed to record status of email job %s", jobID)
+	}
+
+	return job.Status == model.EmailStatusSent
+}
+
+// dequeue removes a job from the schedule
+func (q *Queue) dequeue(ctx context.Context, jobID string) {
+	err := q.redis.ZRem(ctx, redis.EmailQueueKey, jobID)
+	if err != nil {
+		logger.Errorf(err, "failed to dequeue email job %s", jobID)
+	}
+}
+
+// retryDelay returns the wait before the next attempt after the given number of failed attempts
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// Snapshot lists up to limit pending emails, soonest first, and the limit most recently failed ones
+func (q *Queue) Snapshot(ctx context.Context, limit int) (*model.EmailQueueResponse, error) {
+	response := &model.EmailQueueResponse{
+		Pending: []model.EmailJob{},
+		Failed:  []model.EmailJob{},
+	}
+	if q.redis == nil {
+		return response, nil
+	}
+
+	pendingIDs, err := q.redis.ZRangeByScore(ctx, redis.EmailQueueKey, &goredis.ZRangeBy{
+		Min:   "-inf",
+		Max:   "+inf",
+		Count: int64(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	response.Pending = q.loadJobs(ctx, redis.EmailQueueKey, pendingIDs)
+
+	failedIDs, err := q.redis.ZRevRange(ctx, redis.EmailFailedKey, 0, int64(limit)-1)
+	if err != nil {
+		return nil, err
+	}
+	response.Failed = q.loadJobs(ctx, redis.EmailFailedKey, failedIDs)
+
+	return response, nil
+}
+
+// loadJobs reads the records of job IDs listed in a sorted set, forgetting IDs whose record expired
+func (q *Queue) loadJobs(ctx context.Context, setKey string, jobIDs []string) []model.EmailJob {
+	jobs := make([]model.EmailJob, 0, len(jobIDs))
+	for _, jobID := range jobIDs {
+		var job model.EmailJob
+		err := q.redis.Get(ctx, redis.EmailJobKey(jobID), &job)
+		if err != nil {
+			exists, existsErr := q.redis.Exists(ctx, redis.EmailJobKey(jobID))
+			if existsErr == nil && exists == 0 {
+				if zErr := q.redis.ZRem(ctx, setKey, jobID); zErr != nil {
+					logger.Errorf(zErr, "failed to forget expired email job %s", jobID)
+				}
+			}
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
@@ -0,0 +1,130 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyProvider fails its next failures sends and records the emails that went through
+type flakyProvider struct {
+	NoOpProvider
+	failures int
+	sent     []InvitationTemplateData
+}
+
+func (p *flakyProvider) SendTemplateEmail(ctx context.Context, to []string, templateName string, data interface{}) error {
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("smtp unavailable")
+	}
+	p.sent = append(p.sent, data.(InvitationTemplateData))
+	return nil
+}
+
+func newTestQueue(t *testing.T, provider Provider) *Queue {
+	logger.InitLogger(&config.Config{})
+	server := miniredis.RunT(t)
+
+	client, err := redis.NewClient(&config.Config{Redis: config.RedisConfig{Host: server.Host(), Port: server.Port()}})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	return NewQueue(provider, client)
+}
+
+func TestQueue_RetriesWithBackoff(t *testing.T) {
+	provider := &flakyProvider{failures: 2}
+	queue := newTestQueue(t, provider)
+	ctx := context.Background()
+
+	data := InvitationTemplateData{MovieTitle: "Heat", InviteURL: "http://localhost:3000/rooms/join/1"}
+	require.NoError(t, queue.SendTemplateEmail(ctx, []string{"guest@example.com"}, TemplateRoomInvitation, data))
+	assert.Empty(t, provider.sent, "queueing does not send")
+
+	now := time.Now()
+	sent, err := queue.ProcessDue(ctx, now)
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+
+	// not due again until the backoff passed
+	sent, err = queue.ProcessDue(ctx, now.Add(retryBaseDelay-time.Second))
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+
+	snapshot, err := queue.Snapshot(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Pending, 1)
+	assert.Equal(t, 1, snapshot.Pending[0].Attempts)
+	assert.Equal(t, "smtp unavailable", snapshot.Pending[0].LastError)
+
+	now = now.Add(retryBaseDelay)
+	_, err = queue.ProcessDue(ctx, now)
+	require.NoError(t, err)
+
+	sent, err = queue.ProcessDue(ctx, now.Add(2*retryBaseDelay))
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.Len(t, provider.sent, 1)
+	assert.Equal(t, data, provider.sent[0])
+
+	snapshot, err = queue.Snapshot(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, snapshot.Pending)
+	assert.Empty(t, snapshot.Failed)
+}
+
+func TestQueue_FailsAfterMaxAttempts(t *testing.T) {
+	provider := &flakyProvider{failures: maxSendAttempts}
+	queue := newTestQueue(t, provider)
+	ctx := context.Background()
+
+	require.NoError(t, queue.SendTemplateEmail(ctx, []string{"guest@example.com"}, TemplateRoomInvitation, InvitationTemplateData{}))
+
+	now := time.Now()
+	for i := 0; i < maxSendAttempts; i++ {
+		_, err := queue.ProcessDue(ctx, now)
+		require.NoError(t, err)
+		now = now.Add(maxRetryDelay)
+	}
+
+	snapshot, err := queue.Snapshot(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, snapshot.Pending)
+	require.Len(t, snapshot.Failed, 1)
+	assert.Equal(t, model.EmailStatusFailed, snapshot.Failed[0].Status)
+	assert.Equal(t, maxSendAttempts, snapshot.Failed[0].Attempts)
+}
+
+func TestQueue_DedupesWithinWindow(t *testing.T) {
+	queue := newTestQueue(t, &flakyProvider{})
+	ctx := context.Background()
+
+	invite := InvitationTemplateData{RoomID: "room-1", MovieTitle: "Heat"}
+	require.NoError(t, queue.SendTemplateEmail(ctx, []string{"guest@example.com"}, TemplateRoomInvitation, invite))
+	// a retried request, with the address typed differently
+	require.NoError(t, queue.SendTemplateEmail(ctx, []string{" Guest@Example.com"}, TemplateRoomInvitation, invite))
+	// an invitation to another room is not a duplicate
+	otherRoom := InvitationTemplateData{RoomID: "room-2", MovieTitle: "Ronin"}
+	require.NoError(t, queue.SendTemplateEmail(ctx, []string{"guest@example.com"}, TemplateRoomInvitation, otherRoom))
+
+	snapshot, err := queue.Snapshot(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, snapshot.Pending, 2)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, retryBaseDelay, retryDelay(1))
+	assert.Equal(t, 2*retryBaseDelay, retryDelay(2))
+	assert.Equal(t, 8*retryBaseDelay, retryDelay(4))
+	assert.Equal(t, maxRetryDelay, retryDelay(20))
+}
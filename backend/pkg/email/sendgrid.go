@@ -7,9 +7,13 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"time"
 	"watch-party/pkg/config"
 )
 
+// sendGridTimeout bounds one SendGrid API call, so a stalled API cannot hold the goroutine sending the email
+const sendGridTimeout = 15 * time.Second
+
 // SendGridProvider implements Provider for SendGrid
 type SendGridProvider struct {
 	config config.SendGridConfig
@@ -20,7 +24,7 @@ type SendGridProvider struct {
 func NewSendGridProvider(cfg config.SendGridConfig) (*SendGridProvider, error) {
 	return &SendGridProvider{
 		config: cfg,
-		client: &http.Client{},
+		client: &http.Client{Timeout: sendGridTimeout},
 	}, nil
 }
 
@@ -2,6 +2,7 @@ package email
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"strings"
@@ -22,6 +23,21 @@ func renderTemplate(templateName string, data interface{}) (EmailBody, error) {
 	}
 }
 
+// decodeTemplateData restores the typed data of a template from its JSON form, as stored by the queue
+func decodeTemplateData(templateName string, data json.RawMessage) (interface{}, error) {
+	switch templateName {
+	case TemplateRoomInvitation:
+		var inviteData InvitationTemplateData
+		err := json.Unmarshal(data, &inviteData)
+		if err != nil {
+			return nil, fmt.Errorf("invalid room invitation data: %w", err)
+		}
+		return inviteData, nil
+	default:
+		return nil, fmt.Errorf("unknown template: %s", templateName)
+	}
+}
+
 // getTemplateSubject returns the subject for a given template
 func getTemplateSubject(templateName string, data interface{}) string {
 	switch templateName {
@@ -0,0 +1,54 @@
+package events
+
+import (
+	"context"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/storage"
+)
+
+// maxNotificationRetryDelay caps the wait between reconnects to the storage notification stream
+const maxNotificationRetryDelay = time.Minute
+
+// ListenForUploads feeds objects created under the uploads prefix to the handler until ctx is done,
+// reconnecting with backoff whenever the notification stream drops
+func ListenForUploads(ctx context.Context, handler Handler, notifier storage.UploadNotifier) {
+	objects := make(chan storage.ObjectEvent, 16)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case object := <-objects:
+				err := handler.HandleObjectCreated(ctx, &object)
+				if err != nil {
+					logger.Errorf(err, "failed to handle storage notification for %s", object.Path)
+				}
+			}
+		}
+	}()
+
+	retryDelay := time.Second
+	for {
+		logger.Infof("listening for storage notifications under %s", storage.UploadsPrefix)
+		err := notifier.ListenForObjects(ctx, storage.UploadsPrefix, objects)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logger.Errorf(err, "storage notification listener stopped, retrying in %s", retryDelay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryDelay):
+		}
+
+		retryDelay *= 2
+		if retryDelay > maxNotificationRetryDelay {
+			retryDelay = maxNotificationRetryDelay
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package events
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+)
+
+// inFlightKey identifies a running transcode, a movie can be retranscoding while its first transcode is not
+// recorded as finished yet
+type inFlightKey struct {
+	movieID uuid.UUID
+	kind    string
+}
+
+// trackTranscode records a transcode as running until the returned func is called
+func (h *eventHandler) trackTranscode(movieID uuid.UUID, kind string, startedAt time.Time) func() {
+	key := inFlightKey{movieID: movieID, kind: kind}
+	h.inFlight.Store(key, startedAt)
+	return func() { h.inFlight.Delete(key) }
+}
+
+// InFlightTranscodes returns the transcodes this instance is running, longest running first
+func (h *eventHandler) InFlightTranscodes() []model.InFlightTranscode {
+	now := time.Now()
+	transcodes := make([]model.InFlightTranscode, 0)
+	h.inFlight.Range(func(k, v any) bool {
+		key := k.(inFlightKey)
+		startedAt := v.(time.Time)
+		transcodes = append(transcodes, model.InFlightTranscode{
+			MovieID:        key.movieID,
+			Kind:           key.kind,
+			StartedAt:      startedAt,
+			ElapsedSeconds: now.Sub(startedAt).Seconds(),
+		})
+		return true
+	})
+
+	sort.Slice(transcodes, func(i, j int) bool {
+		return transcodes[i].StartedAt.Before(transcodes[j].StartedAt)
+	})
+	return transcodes
+}
+
+// TempDirUsage walks the transcode scratch directory. entries left behind while nothing is in flight are
+// transcodes that did not clean up
+func (h *eventHandler) TempDirUsage() model.TempDirUsage {
+	usage := model.TempDirUsage{Path: h.tempDir}
+
+	entries, err := os.ReadDir(h.tempDir)
+	if err != nil {
+		// the directory is created by the first transcode
+		if !os.IsNotExist(err) {
+			usage.Error = err.Error()
+		}
+		return usage
+	}
+	usage.Entries = len(entries)
+
+	// files can vanish while a transcode cleans up, they are skipped rather than failing the walk
+	_ = filepath.WalkDir(h.tempDir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		usage.UsedBytes += info.Size()
+		return nil
+	})
+
+	return usage
+}
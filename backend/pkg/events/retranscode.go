@@ -0,0 +1,124 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/video"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrMovieNotFound is returned when the movie to retranscode does not exist
+	ErrMovieNotFound = errors.New("movie not found")
+	// ErrNoFailedQualities is returned for movies that are not available with some qualities missing
+	ErrNoFailedQualities = errors.New("movie has no failed qualities to retranscode")
+	// ErrRetranscodeInProgress is returned while the failed qualities of the movie are already being retranscoded
+	ErrRetranscodeInProgress = errors.New("failed qualities are already being retranscoded")
+)
+
+// retranscodeClaimTTL is how long a retranscode claim holds, a claim older than this was left by an instance that
+// stopped mid-retranscode
+const retranscodeClaimTTL = 24 * time.Hour
+
+// recordFailedQualities stores which qualities of a published movie failed, players fall back to the ones that exist
+func (h *eventHandler) recordFailedQualities(movieID uuid.UUID, failed []string) {
+	if len(failed) > 0 {
+		logger.Warnf("movie %s was published without qualities %v", movieID, failed)
+	}
+
+	err := h.movieRepo.SetFailedQualities(movieID, failed)
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("failed to record failed qualities of movie %s", movieID))
+	}
+}
+
+// RetranscodeFailedQualities starts transcoding the qualities an available movie was published without, next to
+// the ones it has. the movie stays watchable meanwhile. it returns the qualities being retranscoded
+func (h *eventHandler) RetranscodeFailedQualities(ctx context.Context, movieID uuid.UUID) ([]string, error) {
+	movie, err := h.movieRepo.GetByID(movieID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movie: %w", err)
+	}
+	if movie == nil {
+		return nil, ErrMovieNotFound
+	}
+	// a fast preview still completing its ladder is not missing qualities yet
+	if movie.Status != model.StatusAvailable || movie.FastPreview || !movie.IsPartial() {
+		return nil, ErrNoFailedQualities
+	}
+
+	// the added qualities must be encrypted with the key the published ones use
+	key, err := h.movieRepo.GetEncryptionKey(movieID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get segment key: %w", err)
+	}
+	var encryption *video.SegmentEncryption
+	if key != nil {
+		if h.keyBaseURL == "" {
+			return nil, fmt.Errorf("movie %s is encrypted but no segment key URL is configured", movieID)
+		}
+		encryption = &video.SegmentEncryption{Key: key, KeyURI: fmt.Sprintf("%s/%s/key", h.keyBaseURL, movieID)}
+	}
+
+	// the claim is kept in the database so two API instances cannot retranscode the same movie
+	claimed, err := h.movieRepo.ClaimForRetranscode(movieID, time.Now().Add(-retranscodeClaimTTL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim movie for retranscoding: %w", err)
+	}
+	if !claimed {
+		return nil, ErrRetranscodeInProgress
+	}
+
+	go h.retranscodeAsync(context.Background(), movie, encryption)
+
+	return movie.FailedQualities, nil
+}
+
+// retranscodeAsync transcodes the failed qualities of a movie from its original file and republishes the master
+// playlist with them. qualities failing again stay recorded as failed
+func (h *eventHandler) retranscodeAsync(ctx context.Context, movie *model.Movie, encryption *video.SegmentEncryption) {
+	defer func() {
+		if err := h.movieRepo.ReleaseRetranscode(movie.ID); err != nil {
+			logger.Error(err, fmt.Sprintf("failed to release retranscode claim of movie %s", movie.ID))
+		}
+	}()
+
+	startTime := time.Now()
+	defer h.trackTranscode(movie.ID, model.TranscodeKindRetranscode, startTime)()
+	logger.Infof("retranscoding qualities %v of movie %s", movie.FailedQualities, movie.ID)
+
+	movieTempDir := filepath.Join(h.tempDir, movie.ID.String()+"-retranscode")
+	defer func() {
+		if err := os.RemoveAll(movieTempDir); err != nil {
+			logger.Error(err, fmt.Sprintf("failed to cleanup temp directory for movie %s", movie.ID))
+		}
+	}()
+
+	inputFile := filepath.Join(movieTempDir, "input"+filepath.Ext(movie.OriginalFilePath))
+	err := h.downloadFileForProcessing(ctx, movie.OriginalFilePath, inputFile)
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("failed to download original of movie %s for retranscoding", movie.ID))
+		return
+	}
+
+	storagePrefix := fmt.Sprintf("hls/%s", movie.ID.String())
+	hlsOutput, err := h.videoProcessor.ExtendHLS(ctx, inputFile, filepath.Join(movieTempDir, "hls"), storagePrefix,
+		video.DefaultQualities, movie.Qualities(), encryption)
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("failed to retranscode qualities of movie %s", movie.ID))
+		return
+	}
+
+	h.recordFailedQualities(movie.ID, hlsOutput.FailedQualities)
+
+	logger.Infof("retranscoded movie %s in %v, added %d qualities",
+		movie.ID, time.Since(startTime), len(hlsOutput.QualityPlaylistURLs))
+}
@@ -2,10 +2,13 @@ package events
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 	"watch-party/pkg/logger"
 	"watch-party/pkg/model"
@@ -18,6 +21,10 @@ import (
 // Handler handles storage events like file uploads
 type Handler interface {
 	HandleUploadComplete(ctx context.Context, event *UploadEvent) error
+	HandleObjectCreated(ctx context.Context, object *storage.ObjectEvent) error
+	RetranscodeFailedQualities(ctx context.Context, movieID uuid.UUID) ([]string, error)
+	InFlightTranscodes() []model.InFlightTranscode
+	TempDirUsage() model.TempDirUsage
 }
 
 // UploadEvent represents a file upload completion event
@@ -31,9 +38,18 @@ type UploadEvent struct {
 // Repository defines the interface for updating movie records
 type Repository interface {
 	GetByID(id uuid.UUID) (*model.Movie, error)
+	GetByOriginalFilePath(path string) (*model.Movie, error)
 	UpdateStatus(id uuid.UUID, status model.MovieStatus) error
+	ClaimForTranscoding(id uuid.UUID) (bool, error)
+	MarkFailed(id uuid.UUID, reason string) error
 	UpdateProcessingTimes(id uuid.UUID, startedAt, endedAt *time.Time) error
 	UpdateHLSInfo(id uuid.UUID, hlsPlaylistURL, transcodedPath string) error
+	SetFastPreview(id uuid.UUID, fastPreview bool) error
+	SetFailedQualities(id uuid.UUID, qualities []string) error
+	ClaimForRetranscode(id uuid.UUID, staleBefore time.Time) (bool, error)
+	ReleaseRetranscode(id uuid.UUID) error
+	SetEncryptionKey(id uuid.UUID, key []byte) error
+	GetEncryptionKey(id uuid.UUID) ([]byte, error)
 	Update(movie *model.Movie) error
 }
 
@@ -42,8 +58,15 @@ type eventHandler struct {
 	movieRepo       Repository
 	storageProvider storage.Provider
 	videoProcessor  video.Processor
-	hlsBaseURL      string // Base URL for accessing HLS files (deprecated - not needed anymore)
-	tempDir         string // Directory for temporary processing files
+	hlsBaseURL      string                          // Base URL for accessing HLS files (deprecated - not needed anymore)
+	tempDir         string                          // Directory for temporary processing files
+	fullLadder      bool                            // complete the quality ladder after a fast preview is published
+	downloadOpts    storage.ParallelDownloadOptions // how sources are fetched from storage before transcoding
+	maxUploadBytes  int64                           // largest original file accepted
+	keyBaseURL      string                          // base of the segment key URIs, empty leaves segments unencrypted
+	inputFormats    []string                        // source file extensions accepted for transcoding
+	maxDuration     time.Duration                   // longest source accepted for transcoding
+	inFlight        sync.Map                        // transcodes running on this instance, by movie and kind
 }
 
 // NewHandler creates a new event handler
@@ -53,6 +76,12 @@ func NewHandler(
 	videoProcessor video.Processor,
 	hlsBaseURL string,
 	tempDir string,
+	fullLadder bool,
+	downloadOpts storage.ParallelDownloadOptions,
+	maxUploadBytes int64,
+	keyBaseURL string,
+	inputFormats []string,
+	maxDuration time.Duration,
 ) Handler {
 	return &eventHandler{
 		movieRepo:       movieRepo,
@@ -60,6 +89,12 @@ func NewHandler(
 		videoProcessor:  videoProcessor,
 		hlsBaseURL:      hlsBaseURL,
 		tempDir:         tempDir,
+		fullLadder:      fullLadder,
+		downloadOpts:    downloadOpts,
+		maxUploadBytes:  maxUploadBytes,
+		keyBaseURL:      keyBaseURL,
+		inputFormats:    inputFormats,
+		maxDuration:     maxDuration,
 	}
 }
 
@@ -78,6 +113,18 @@ func (h *eventHandler) HandleUploadComplete(ctx context.Context, event *UploadEv
 		return fmt.Errorf("movie not found: %s", event.MovieID)
 	}
 
+	// the client and a storage notification may both report the same upload, only the caller that moves the
+	// movie to transcoding starts it
+	claimed, err := h.movieRepo.ClaimForTranscoding(event.MovieID)
+	if err != nil {
+		return fmt.Errorf("failed to claim movie: %w", err)
+	}
+	if !claimed {
+		logger.Infof("upload for movie %s already handled (status %s), skipping", event.MovieID, movie.Status)
+		return nil
+	}
+	movie.Status = model.StatusTranscoding
+
 	// update movie with original file path
 	movie.OriginalFilePath = event.FilePath
 	movie.FileSize = event.FileSize
@@ -88,6 +135,10 @@ func (h *eventHandler) HandleUploadComplete(ctx context.Context, event *UploadEv
 	err = h.movieRepo.Update(movie)
 	if err != nil {
 		logger.Error(err, "failed to update movie with file info")
+		// release the claim so a retry of the upload completion can start the movie
+		if updateErr := h.movieRepo.UpdateStatus(event.MovieID, model.StatusFailed); updateErr != nil {
+			logger.Error(updateErr, "failed to update movie status to failed")
+		}
 		return fmt.Errorf("failed to update movie: %w", err)
 	}
 
@@ -110,6 +161,35 @@ func (h *eventHandler) HandleUploadComplete(ctx context.Context, event *UploadEv
 	return nil
 }
 
+// HandleObjectCreated starts processing when storage reports an upload, without waiting for the client
+func (h *eventHandler) HandleObjectCreated(ctx context.Context, object *storage.ObjectEvent) error {
+	if !strings.HasPrefix(object.Path, storage.UploadsPrefix) {
+		return nil
+	}
+
+	movie, err := h.movieRepo.GetByOriginalFilePath(object.Path)
+	if err != nil {
+		return fmt.Errorf("failed to get movie for %s: %w", object.Path, err)
+	}
+
+	// not an upload initiated through the API
+	if movie == nil {
+		logger.Warnf("no movie found for uploaded object %s", object.Path)
+		return nil
+	}
+
+	if movie.Status != model.StatusProcessing {
+		return nil
+	}
+
+	return h.HandleUploadComplete(ctx, &UploadEvent{
+		MovieID:  movie.ID,
+		FilePath: object.Path,
+		FileSize: object.Size,
+		MimeType: object.ContentType,
+	})
+}
+
 // validateUploadedFile validates the uploaded file
 func (h *eventHandler) validateUploadedFile(ctx context.Context, filePath string) error {
 	// check if file exists
@@ -118,16 +198,15 @@ func (h *eventHandler) validateUploadedFile(ctx context.Context, filePath string
 		return fmt.Errorf("failed to get file info: %w", err)
 	}
 
-	// basic file size check (max 5GB)
-	const maxFileSize = 5 * 1024 * 1024 * 1024 // 5GB
-	if fileInfo.Size > maxFileSize {
-		return fmt.Errorf("file size too large: %d bytes (max: %d bytes)", fileInfo.Size, maxFileSize)
+	// basic file size check, the signed upload already enforces it on storage that supports upload policies
+	if fileInfo.Size > h.maxUploadBytes {
+		return fmt.Errorf("file size too large: %d bytes (max: %d bytes)", fileInfo.Size, h.maxUploadBytes)
 	}
 
 	// validate file format using storage provider (if the file is accessible)
 	// for now, we'll rely on extension-based validation
 	ext := filepath.Ext(filePath)
-	if !isValidVideoExtension(ext) {
+	if !slices.Contains(h.inputFormats, strings.ToLower(ext)) {
 		return fmt.Errorf("unsupported video format: %s", ext)
 	}
 
@@ -138,6 +217,7 @@ func (h *eventHandler) validateUploadedFile(ctx context.Context, filePath string
 func (h *eventHandler) processVideoAsync(ctx context.Context, movie *model.Movie) {
 	movieID := movie.ID
 	startTime := time.Now()
+	defer h.trackTranscode(movieID, model.TranscodeKindUpload, startTime)()
 
 	logger.Infof("starting video transcoding for movie %s", movieID)
 
@@ -172,16 +252,73 @@ func (h *eventHandler) processVideoAsync(ctx context.Context, movie *model.Movie
 		return
 	}
 
+	// the extension check passes renamed files, only the contents tell whether this is a video
+	err = h.videoProcessor.ValidateVideoFile(ctx, inputFile)
+	if err != nil {
+		if errors.Is(err, video.ErrNotVideo) {
+			h.handleInvalidVideo(movieID, err)
+		} else {
+			h.handleTranscodingError(movieID, fmt.Errorf("failed to validate file: %w", err))
+		}
+		return
+	}
+
+	// a very long source would hold the transcoder for hours, refuse it before encoding starts
+	info, err := h.videoProcessor.GetVideoInfo(ctx, inputFile)
+	if err != nil {
+		h.handleTranscodingError(movieID, fmt.Errorf("failed to probe file: %w", err))
+		return
+	}
+	duration := time.Duration(info.Duration * float64(time.Second))
+	if h.maxDuration > 0 && duration > h.maxDuration {
+		h.handleTooLong(movieID, duration)
+		return
+	}
+
 	// storage prefix for HLS files
 	storagePrefix := fmt.Sprintf("hls/%s", movieID.String())
 
+	// a fast preview publishes a single quality first so the movie becomes watchable sooner
+	qualities := video.DefaultQualities
+	if movie.FastPreview {
+		qualities = []video.Quality{video.PreviewQuality}
+	}
+
+	var encryption *video.SegmentEncryption
+	if h.keyBaseURL != "" {
+		encryption, err = video.NewSegmentEncryption(fmt.Sprintf("%s/%s/key", h.keyBaseURL, movieID))
+		if err != nil {
+			h.handleTranscodingError(movieID, err)
+			return
+		}
+	}
+
+	// the key is stored before any segment is uploaded, segments whose key was lost could never be played.
+	// a movie transcoded unencrypted drops an old one
+	var key []byte
+	if encryption != nil {
+		key = encryption.Key
+	}
+	err = h.movieRepo.SetEncryptionKey(movieID, key)
+	if err != nil {
+		h.handleTranscodingError(movieID, fmt.Errorf("failed to store segment key: %w", err))
+		return
+	}
+
 	// transcode to HLS (this now handles uploading to storage automatically)
-	hlsOutput, err := h.videoProcessor.TranscodeToHLS(ctx, inputFile, outputDir, storagePrefix, video.DefaultQualities)
+	hlsOutput, err := h.videoProcessor.TranscodeToHLS(ctx, inputFile, outputDir, storagePrefix, qualities, encryption)
 	if err != nil {
 		h.handleTranscodingError(movieID, fmt.Errorf("transcoding failed: %w", err))
 		return
 	}
 
+	// posters and previews are cosmetic, a failure must not hold back an otherwise playable movie
+	images, err := h.videoProcessor.GenerateImages(ctx, inputFile, filepath.Join(movieTempDir, "images"), storagePrefix)
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("failed to generate images for movie %s", movieID))
+	}
+	logger.Infof("generated %d images for movie %s", len(images), movieID)
+
 	// update movie record with completion info
 	endTime := time.Now()
 	err = h.movieRepo.UpdateProcessingTimes(movieID, &startTime, &endTime)
@@ -197,6 +334,8 @@ func (h *eventHandler) processVideoAsync(ctx context.Context, movie *model.Movie
 		return
 	}
 
+	h.recordFailedQualities(movieID, hlsOutput.FailedQualities)
+
 	err = h.movieRepo.UpdateStatus(movieID, model.StatusAvailable)
 	if err != nil {
 		logger.Error(err, "failed to update movie status to available")
@@ -205,6 +344,35 @@ func (h *eventHandler) processVideoAsync(ctx context.Context, movie *model.Movie
 
 	logger.Infof("video transcoding completed successfully for movie %s in %v, generated %d segments across %d qualities",
 		movieID, endTime.Sub(startTime), hlsOutput.TotalSegments, len(hlsOutput.QualityPlaylistURLs))
+
+	if movie.FastPreview && h.fullLadder {
+		h.completeQualityLadder(ctx, movieID, inputFile, filepath.Join(movieTempDir, "hls-ladder"), storagePrefix, encryption)
+	}
+}
+
+// completeQualityLadder transcodes the qualities a fast preview skipped while the movie stays watchable.
+// on failure the movie keeps playing in the preview quality. the added qualities are encrypted like the preview.
+func (h *eventHandler) completeQualityLadder(ctx context.Context, movieID uuid.UUID, inputFile, outputDir, storagePrefix string, encryption *video.SegmentEncryption) {
+	startTime := time.Now()
+	logger.Infof("completing quality ladder for fast preview movie %s", movieID)
+
+	hlsOutput, err := h.videoProcessor.ExtendHLS(ctx, inputFile, outputDir, storagePrefix,
+		video.DefaultQualities, []string{video.PreviewQuality.Name}, encryption)
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("failed to complete quality ladder for movie %s, keeping the preview quality", movieID))
+		return
+	}
+
+	err = h.movieRepo.SetFastPreview(movieID, false)
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("failed to mark quality ladder of movie %s complete", movieID))
+		return
+	}
+
+	h.recordFailedQualities(movieID, hlsOutput.FailedQualities)
+
+	logger.Infof("quality ladder of movie %s completed in %v, added %d qualities",
+		movieID, time.Since(startTime), len(hlsOutput.QualityPlaylistURLs))
 }
 
 // downloadFileForProcessing downloads a file from storage to local temp directory
@@ -214,8 +382,8 @@ func (h *eventHandler) downloadFileForProcessing(ctx context.Context, storagePat
 		return fmt.Errorf("failed to create directory for local file: %w", err)
 	}
 
-	// Use the storage provider's Download method
-	err := h.storageProvider.Download(ctx, storagePath, localPath)
+	// large sources are fetched in parallel ranges when the provider supports it
+	err := storage.DownloadParallel(ctx, h.storageProvider, storagePath, localPath, h.downloadOpts)
 	if err != nil {
 		return fmt.Errorf("failed to download file from storage: %w", err)
 	}
@@ -239,15 +407,31 @@ func (h *eventHandler) handleTranscodingError(movieID uuid.UUID, err error) {
 	}
 }
 
-// isValidVideoExtension checks if the file extension is supported
-func isValidVideoExtension(ext string) bool {
-	supportedFormats := map[string]bool{
-		".mp4":  true,
-		".avi":  true,
-		".mkv":  true,
-		".mov":  true,
-		".webm": true,
-		".m4v":  true,
-	}
-	return supportedFormats[strings.ToLower(ext)]
+// invalidVideoReason is what the uploader of a file that isn't a video is told
+const invalidVideoReason = "not a valid video: the uploaded file has no video stream that can be played"
+
+// handleInvalidVideo fails a movie whose upload isn't a video, with a reason its uploader can act on
+func (h *eventHandler) handleInvalidVideo(movieID uuid.UUID, err error) {
+	logger.Warnf("upload of movie %s is not a valid video: %v", movieID, err)
+	h.rejectUpload(movieID, invalidVideoReason)
+}
+
+// handleTooLong fails a movie whose source runs longer than transcoding accepts
+func (h *eventHandler) handleTooLong(movieID uuid.UUID, duration time.Duration) {
+	logger.Warnf("upload of movie %s runs %s, longer than the limit of %s", movieID, duration.Round(time.Second), h.maxDuration)
+	h.rejectUpload(movieID, fmt.Sprintf("video too long: it runs %s, the limit is %s", duration.Round(time.Second), h.maxDuration))
+}
+
+// rejectUpload fails a movie before it is transcoded, reason is shown to its uploader
+func (h *eventHandler) rejectUpload(movieID uuid.UUID, reason string) {
+	endTime := time.Now()
+	updateErr := h.movieRepo.UpdateProcessingTimes(movieID, nil, &endTime)
+	if updateErr != nil {
+		logger.Error(updateErr, "failed to update processing end time after rejected upload")
+	}
+
+	updateErr = h.movieRepo.MarkFailed(movieID, reason)
+	if updateErr != nil {
+		logger.Error(updateErr, "failed to mark rejected upload as failed")
+	}
 }
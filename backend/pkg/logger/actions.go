@@ -31,6 +31,19 @@ func Infof(template string, args ...interface{}) {
 	)
 }
 
+// InfoSampledf logs an info message given a template and arguments, only 1 in the configured sample rate of these
+// calls is written. meant for logs written for every message of a busy room
+func InfoSampledf(template string, args ...interface{}) {
+	log.sampled.Info().Str(lineOfCode, utils.GetFileAndLoC(1)).Msg(
+		fmt.Sprintf(template, args...),
+	)
+}
+
+// InfoFields logs an info message with structured fields that log queries can filter and aggregate on
+func InfoFields(message string, fields map[string]interface{}) {
+	log.engine.Info().Str(lineOfCode, utils.GetFileAndLoC(1)).Fields(fields).Msg(message)
+}
+
 // Warn logs a warning message
 func Warn(message string) {
 	log.engine.Warn().Str(lineOfCode, utils.GetFileAndLoC(1)).Msg(message)
@@ -11,7 +11,8 @@ import (
 var log *logger
 
 type logger struct {
-	engine *zl.Logger
+	engine  *zl.Logger
+	sampled *zl.Logger // writes 1 in the configured sample rate of its logs
 }
 
 type options struct {
@@ -27,8 +28,14 @@ func InitLogger(cfg *config.Config) {
 	setupCloudLoggingSeverity()
 	engine := newGCPLogger(opts) // TODO: support other cloud prviders
 
+	sampled := engine
+	if cfg.Log.SampleRate > 1 {
+		sampled = engine.Sample(&zl.BasicSampler{N: uint32(cfg.Log.SampleRate)})
+	}
+
 	log = &logger{
-		engine: &engine,
+		engine:  &engine,
+		sampled: &sampled,
 	}
 }
 
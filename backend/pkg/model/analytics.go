@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AnalyticsDayFormat is the layout of the day keys used by movie analytics
+const AnalyticsDayFormat = "2006-01-02"
+
+// MovieAnalytics summarizes how often a movie has been watched
+type MovieAnalytics struct {
+	MovieID       uuid.UUID         `json:"movie_id"`
+	TotalViews    int64             `json:"total_views"`
+	UniqueViewers int64             `json:"unique_viewers"` // approximate, counted with HyperLogLog
+	Daily         []MovieDailyViews `json:"daily"`          // most recent day first
+	GeneratedAt   time.Time         `json:"generated_at"`
+}
+
+// MovieDailyViews holds the view counters of a movie for a single day
+type MovieDailyViews struct {
+	Day           string `json:"day"` // YYYY-MM-DD, UTC
+	Views         int64  `json:"views"`
+	UniqueViewers int64  `json:"unique_viewers"`
+}
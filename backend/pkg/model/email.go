@@ -0,0 +1,35 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// email delivery statuses
+const (
+	EmailStatusPending = "pending" // waiting for its first or next attempt
+	EmailStatusSent    = "sent"
+	EmailStatusFailed  = "failed" // ran out of attempts
+)
+
+// EmailJob is an email waiting in, or delivered through, the outbound queue
+type EmailJob struct {
+	ID            uuid.UUID       `json:"id"`
+	To            []string        `json:"to"`
+	Template      string          `json:"template"`
+	Data          json.RawMessage `json:"data"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"last_error,omitempty"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// EmailQueueResponse lists the emails still waiting for delivery and the ones that failed
+type EmailQueueResponse struct {
+	Pending []EmailJob `json:"pending"`
+	Failed  []EmailJob `json:"failed"`
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -31,6 +32,87 @@ type Movie struct {
 	CreatedAt           time.Time   `json:"created_at" db:"created_at"`
 	ProcessingStartedAt *time.Time  `json:"processing_started_at" db:"processing_started_at"` // When transcoding started
 	ProcessingEndedAt   *time.Time  `json:"processing_ended_at" db:"processing_ended_at"`     // When transcoding completed
+	FastPreview         bool        `json:"fast_preview" db:"fast_preview"`                   // only the preview quality is published, the rest of the ladder may follow
+	FailureReason       string      `json:"failure_reason,omitempty" db:"failure_reason"`     // why processing failed, when it is known
+	FailedQualities     []string    `json:"failed_qualities,omitempty" db:"failed_qualities"` // renditions that failed to transcode while the others were published
+}
+
+// Qualities returns the renditions the movie's HLS output has once it is available
+func (m *Movie) Qualities() []string {
+	if m.FastPreview {
+		return []string{PreviewQuality}
+	}
+	if len(m.FailedQualities) == 0 {
+		return AvailableQualities
+	}
+
+	qualities := make([]string, 0, len(AvailableQualities))
+	for _, quality := range AvailableQualities {
+		if !slices.Contains(m.FailedQualities, quality) {
+			qualities = append(qualities, quality)
+		}
+	}
+	return qualities
+}
+
+// IsPartial reports whether the movie was published without some of its renditions
+func (m *Movie) IsPartial() bool {
+	return len(m.FailedQualities) > 0
+}
+
+// HLS quality names produced by the transcoder
+const (
+	Quality360p  = "360p"
+	Quality720p  = "720p"
+	Quality1080p = "1080p"
+)
+
+// PreviewQuality is the rendition a fast preview upload is published with before the rest of the ladder
+const PreviewQuality = Quality720p
+
+// AvailableQualities lists the renditions every transcoded movie is published with
+var AvailableQualities = []string{Quality360p, Quality720p, Quality1080p}
+
+// IsAvailableQuality reports whether name is one of the published renditions
+func IsAvailableQuality(name string) bool {
+	for _, quality := range AvailableQualities {
+		if quality == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NearestQuality returns requested when the movie was published with it, otherwise the published quality closest
+// to it, preferring the lower one on a tie. this keeps players that ask for a quality the movie lost or never had
+// playing instead of failing
+func (m *Movie) NearestQuality(requested string) string {
+	rank := qualityRank(requested)
+	nearest, nearestDistance := requested, -1
+	for _, quality := range m.Qualities() {
+		if quality == requested {
+			return requested
+		}
+		distance := qualityRank(quality) - rank
+		if distance < 0 {
+			distance = -distance
+		}
+		if nearestDistance < 0 || distance < nearestDistance ||
+			(distance == nearestDistance && qualityRank(quality) < qualityRank(nearest)) {
+			nearest, nearestDistance = quality, distance
+		}
+	}
+	return nearest
+}
+
+// qualityRank orders qualities from lowest to highest resolution
+func qualityRank(name string) int {
+	for i, quality := range AvailableQualities {
+		if quality == name {
+			return i
+		}
+	}
+	return -1
 }
 
 // Storage provider constants
@@ -43,25 +125,77 @@ const (
 type UploadMovieRequest struct {
 	Title       string `form:"title" binding:"required"`
 	Description string `form:"description"`
-	FileName    string `form:"filename" binding:"required"` // Required for signed URL generation
-	FileSize    int64  `form:"filesize" binding:"required"` // Required for validation
-	MimeType    string `form:"mimetype"`                    // Optional, will be inferred if not provided
+	FileName    string `form:"filename" binding:"required"`      // Required for signed URL generation
+	FileSize    int64  `form:"filesize" binding:"required"`      // Required for validation
+	MimeType    string `form:"mimetype"`                         // Optional, will be inferred if not provided
+	FastPreview bool   `form:"fast_preview" json:"fast_preview"` // publish a single quality first so the movie is watchable sooner
 }
 
 // MovieListResponse represents a paginated list of movies
-type MovieListResponse struct {
-	Movies     []Movie `json:"movies"`
-	TotalCount int     `json:"total_count"`
-	Page       int     `json:"page"`
-	PageSize   int     `json:"page_size"`
-}
+type MovieListResponse = Page[Movie]
 
 // MovieUploadResponse represents the response after successful movie upload initiation
 type MovieUploadResponse struct {
-	MovieID   uuid.UUID `json:"movie_id"`
-	SignedURL string    `json:"signed_url"`
-	FilePath  string    `json:"file_path"`
-	Message   string    `json:"message"`
+	MovieID       uuid.UUID         `json:"movie_id"`
+	SignedURL     string            `json:"signed_url"`
+	UploadMethod  string            `json:"upload_method"`            // PUT the file, or POST it as multipart form with upload_fields
+	UploadHeaders map[string]string `json:"upload_headers,omitempty"` // headers the upload must send as they are
+	UploadFields  map[string]string `json:"upload_fields,omitempty"`  // form fields sent before the file on a POST upload
+	FilePath      string            `json:"file_path"`
+	Message       string            `json:"message"`
+}
+
+// ValidateUploadRequest represents a dry-run check of an upload before it is initiated
+type ValidateUploadRequest struct {
+	FileName string `json:"filename" binding:"required"`
+	FileSize int64  `json:"filesize"`
+	MimeType string `json:"mimetype"`
+}
+
+// ValidateUploadResponse reports whether an upload would be accepted and why not
+type ValidateUploadResponse struct {
+	Accepted    bool     `json:"accepted"`
+	Reasons     []string `json:"reasons"`
+	MimeType    string   `json:"mime_type"`     // type the file would be stored with
+	MaxFileSize int64    `json:"max_file_size"` // largest accepted file in bytes
+}
+
+// BulkDeleteMoviesRequest selects the movies to delete, by ID or every movie of an uploader
+type BulkDeleteMoviesRequest struct {
+	MovieIDs   []uuid.UUID `json:"movie_ids"`
+	UploaderID *uuid.UUID  `json:"uploader_id,omitempty"`
+	Force      bool        `json:"force"` // also delete movies that rooms still use, which deletes those rooms
+}
+
+// BulkDeleteResult is the outcome of deleting one movie of a bulk delete
+type BulkDeleteResult struct {
+	MovieID uuid.UUID `json:"movie_id"`
+	Deleted bool      `json:"deleted"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// BulkDeleteMoviesResponse reports the outcome of a bulk delete per movie
+type BulkDeleteMoviesResponse struct {
+	Results []BulkDeleteResult `json:"results"`
+	Deleted int                `json:"deleted"`
+	Failed  int                `json:"failed"`
+}
+
+// StorageInspectRequest asks what storage holds at a path, for debugging playback
+type StorageInspectRequest struct {
+	Path string `json:"path" binding:"required"` // object path inside the bucket, e.g. hls/<movie>/master.m3u8
+}
+
+// StorageInspectResponse reports an object's metadata and a fresh URL to fetch it with
+type StorageInspectResponse struct {
+	Path         string     `json:"path"`
+	Exists       bool       `json:"exists"`
+	Error        string     `json:"error,omitempty"` // why no signed URL could be generated
+	Size         int64      `json:"size,omitempty"`
+	ContentType  string     `json:"content_type,omitempty"`
+	LastModified string     `json:"last_modified,omitempty"`
+	SignedURL    string     `json:"signed_url,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"` // when SignedURL stops working
 }
 
 // MovieStatusResponse represents the status of a movie processing
@@ -73,4 +207,22 @@ type MovieStatusResponse struct {
 	ProcessingStartedAt *time.Time  `json:"processing_started_at,omitempty"`
 	ProcessingEndedAt   *time.Time  `json:"processing_ended_at,omitempty"`
 	ErrorMessage        string      `json:"error_message,omitempty"`
+	Qualities           []string    `json:"qualities,omitempty"`        // renditions players can pick, once available
+	Partial             bool        `json:"partial"`                    // some renditions failed to transcode
+	FailedQualities     []string    `json:"failed_qualities,omitempty"` // the renditions that failed, admins can retranscode them
+}
+
+// MovieRoom is a room that plays a movie, listed so admins see what deleting the movie would end
+type MovieRoom struct {
+	ID          uuid.UUID   `json:"id"`
+	Name        string      `json:"name"`
+	Host        UserProfile `json:"host"`
+	MemberCount int         `json:"member_count"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// MovieRoomsResponse lists the rooms that play a movie
+type MovieRoomsResponse struct {
+	MovieID uuid.UUID   `json:"movie_id"`
+	Rooms   []MovieRoom `json:"rooms"`
 }
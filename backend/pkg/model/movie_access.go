@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MovieAccessGrant lets a user stream a movie without being in a room that plays it
+type MovieAccessGrant struct {
+	MovieID     uuid.UUID  `json:"movie_id" db:"movie_id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	Email       string     `json:"email" db:"email"`
+	DisplayName string     `json:"display_name" db:"display_name"`
+	GrantedBy   uuid.UUID  `json:"granted_by" db:"granted_by"`
+	GrantedAt   time.Time  `json:"granted_at" db:"granted_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" db:"expires_at"` // nil never expires
+}
+
+// GrantMovieAccessRequest names the user to grant access to, by ID or email
+type GrantMovieAccessRequest struct {
+	UserID    *uuid.UUID `json:"user_id"`
+	Email     string     `json:"email" binding:"omitempty,email"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// MovieAccessListResponse lists the direct access grants of a movie
+type MovieAccessListResponse struct {
+	MovieID uuid.UUID          `json:"movie_id"`
+	Grants  []MovieAccessGrant `json:"grants"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMovieNearestQuality(t *testing.T) {
+	full := &Movie{}
+	preview := &Movie{FastPreview: true}
+	partial := &Movie{FailedQualities: []string{Quality1080p}}
+
+	tests := []struct {
+		name      string
+		movie     *Movie
+		requested string
+		want      string
+	}{
+		{name: "published quality is kept", movie: full, requested: Quality1080p, want: Quality1080p},
+		{name: "missing higher quality falls back", movie: preview, requested: Quality1080p, want: Quality720p},
+		{name: "missing lower quality falls back", movie: preview, requested: Quality360p, want: Quality720p},
+		{name: "preview quality is kept", movie: preview, requested: Quality720p, want: Quality720p},
+		{name: "failed quality falls back", movie: partial, requested: Quality1080p, want: Quality720p},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.movie.NearestQuality(tt.requested))
+		})
+	}
+}
+
+func TestMovieQualities(t *testing.T) {
+	assert.Equal(t, AvailableQualities, (&Movie{}).Qualities())
+	assert.Equal(t, []string{Quality720p}, (&Movie{FastPreview: true}).Qualities())
+
+	partial := &Movie{FailedQualities: []string{Quality360p, Quality1080p}}
+	assert.Equal(t, []string{Quality720p}, partial.Qualities())
+	assert.True(t, partial.IsPartial())
+	assert.False(t, (&Movie{}).IsPartial())
+}
@@ -0,0 +1,58 @@
+package model
+
+import "strconv"
+
+// page size limits shared by all list endpoints
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Page is the envelope returned by every list endpoint
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int    `json:"total"`
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+	NextCursor string `json:"next_cursor,omitempty"` // pass back as ?cursor= for the next page, empty on the last page
+}
+
+// NormalizePagination falls back to the first page and the default page size for out of range values
+func NormalizePagination(page, pageSize int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > MaxPageSize {
+		pageSize = DefaultPageSize
+	}
+	return page, pageSize
+}
+
+// NewPage wraps one page of items out of total matching items
+func NewPage[T any](items []T, total, page, pageSize int) *Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	result := &Page[T]{
+		Items:    items,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if page*pageSize < total {
+		result.NextCursor = strconv.Itoa(page + 1)
+	}
+
+	return result
+}
+
+// PaginateSlice returns one page of a list that was loaded in full
+func PaginateSlice[T any](all []T, page, pageSize int) *Page[T] {
+	page, pageSize = NormalizePagination(page, pageSize)
+
+	start := min((page-1)*pageSize, len(all))
+	end := min(start+pageSize, len(all))
+
+	return NewPage(all[start:end], len(all), page, pageSize)
+}
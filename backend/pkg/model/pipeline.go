@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of transcode running in the background
+const (
+	TranscodeKindUpload      = "upload"      // first transcode of an uploaded source
+	TranscodeKindRetranscode = "retranscode" // failed qualities of a published movie transcoded again
+)
+
+// PipelineStats are the upload and transcode counters kept in the database
+type PipelineStats struct {
+	StatusCounts            map[MovieStatus]int `json:"status_counts"`
+	StuckTranscoding        int                 `json:"stuck_transcoding"`         // movies in transcoding since before the stuck threshold
+	AverageTranscodeSeconds float64             `json:"average_transcode_seconds"` // over the transcodes finished in the sample window, 0 when none did
+	TranscodesSampled       int                 `json:"transcodes_sampled"`        // transcodes the average is taken over
+}
+
+// InFlightTranscode is a transcode this API instance is running
+type InFlightTranscode struct {
+	MovieID        uuid.UUID `json:"movie_id"`
+	Kind           string    `json:"kind"`
+	StartedAt      time.Time `json:"started_at"`
+	ElapsedSeconds float64   `json:"elapsed_seconds"`
+}
+
+// TempDirUsage is how much the transcode scratch directory holds
+type TempDirUsage struct {
+	Path      string `json:"path"`
+	UsedBytes int64  `json:"used_bytes"`
+	Entries   int    `json:"entries"` // top-level entries, one per transcode that has not cleaned up
+	Error     string `json:"error,omitempty"`
+}
+
+// PipelineHealth is the state of the upload and transcode pipeline as admins see it
+type PipelineHealth struct {
+	PipelineStats
+	StuckAfterSeconds float64             `json:"stuck_after_seconds"`
+	SampleWindowDays  int                 `json:"sample_window_days"`
+	InFlight          []InFlightTranscode `json:"in_flight"` // transcodes of this instance only
+	TempDir           TempDirUsage        `json:"temp_dir"`
+	GeneratedAt       time.Time           `json:"generated_at"`
+}
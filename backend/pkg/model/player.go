@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PlayerBootstrapResponse is everything a player needs to open a room, gathered behind one access check
+type PlayerBootstrapResponse struct {
+	Room         PlayerRoomInfo    `json:"room"`
+	Movie        PlayerMovieInfo   `json:"movie"`
+	Stream       *PlayerStreamInfo `json:"stream,omitempty"` // nil until the movie is available
+	State        *RoomState        `json:"state,omitempty"`  // nil until someone has played the room
+	Participants []ParticipantInfo `json:"participants"`
+	GeneratedAt  time.Time         `json:"generated_at"`
+}
+
+// PlayerRoomInfo describes the room a player is opened in
+type PlayerRoomInfo struct {
+	ID          uuid.UUID    `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	HostID      uuid.UUID    `json:"host_id"`
+	Settings    RoomSettings `json:"settings"`
+}
+
+// PlayerMovieInfo describes the room's movie and whether it can be streamed yet
+type PlayerMovieInfo struct {
+	ID                uuid.UUID   `json:"id"`
+	Title             string      `json:"title"`
+	Description       string      `json:"description"`
+	Status            MovieStatus `json:"status"`
+	Available         bool        `json:"available"`
+	Retryable         bool        `json:"retryable"`                     // a movie that isn't available may still become available
+	RetryAfterSeconds int         `json:"retry_after_seconds,omitempty"` // when to ask again while the movie is being prepared
+	EstimatedReadyAt  *time.Time  `json:"estimated_ready_at,omitempty"`
+}
+
+// PlayerStreamInfo tells the player where to load the movie from
+type PlayerStreamInfo struct {
+	HLSURL        string    `json:"hls_url"`
+	StreamingMode string    `json:"streaming_mode"`
+	Qualities     []string  `json:"qualities"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebSocket protocol versions. a client asks for the newest version it understands with the protocolVersion query
+// parameter when it connects, the server answers in the highest version both sides know and stamps it on every
+// envelope it sends. the policy:
+//   - adding a message type or an optional field is not a new version, clients ignore what they do not know
+//   - renaming or removing a field, or changing a payload's shape or meaning, is a new version
+//   - the server keeps speaking every older version until no supported client asks for it, clients that send no
+//     version predate versioning and get version 1
+const (
+	// ProtocolV1 sends sync events as the loosely typed map the first web client was written against
+	ProtocolV1 = 1
+	// ProtocolV2 sends sync events as SyncEvent, which also carries the extra data such as the segment position
+	ProtocolV2 = 2
+
+	// CurrentProtocolVersion is the newest version this server speaks
+	CurrentProtocolVersion = ProtocolV2
+)
+
+// NegotiateProtocolVersion returns the version to speak with a client that asked for requested, version 1 when it
+// asked for none or something unreadable and the newest version this server knows when it asked for a later one
+func NegotiateProtocolVersion(requested string) int {
+	version, err := strconv.Atoi(strings.TrimSpace(requested))
+	if err != nil || version < ProtocolV1 {
+		return ProtocolV1
+	}
+	return min(version, CurrentProtocolVersion)
+}
+
+// SyncEvent is a playback or chat event of another participant as protocol version 2 delivers it
+type SyncEvent struct {
+	Action      SyncAction `json:"action"`
+	CurrentTime float64    `json:"current_time"`
+	Timestamp   time.Time  `json:"timestamp"`
+	ServerTime  int64      `json:"server_time"` // server clock in unix milliseconds when sent, for drift correction
+	UserID      uuid.UUID  `json:"user_id"`
+	Username    string     `json:"username"`
+	Data        SyncData   `json:"data"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	assert.Equal(t, ProtocolV1, NegotiateProtocolVersion(""), "clients predating versioning speak version 1")
+	assert.Equal(t, ProtocolV1, NegotiateProtocolVersion("one"))
+	assert.Equal(t, ProtocolV1, NegotiateProtocolVersion("0"))
+	assert.Equal(t, ProtocolV1, NegotiateProtocolVersion("1"))
+	assert.Equal(t, ProtocolV2, NegotiateProtocolVersion(" 2 "))
+	assert.Equal(t, CurrentProtocolVersion, NegotiateProtocolVersion("99"), "a newer client gets the newest version the server knows")
+}
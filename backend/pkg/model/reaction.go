@@ -0,0 +1,68 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// MaxReactionRunes bounds a reaction, enough for emoji joined into one glyph such as a family or a flag
+const MaxReactionRunes = 8
+
+// reaction limits, a user may send ReactionBurst reactions per ReactionWindow in a room
+const (
+	ReactionBurst  = 5
+	ReactionWindow = time.Second
+)
+
+// emoji skin tone modifiers are modifier symbols (Sk) like ^ or `, but belong to the emoji they follow
+const (
+	firstSkinToneModifier = 0x1F3FB
+	lastSkinToneModifier  = 0x1F3FF
+)
+
+// ReactionMessage is one viewer's emoji reaction, shown briefly over everyone's player
+type ReactionMessage struct {
+	RoomID    uuid.UUID `json:"room_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	Emoji     string    `json:"emoji"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReactionBatchMessage is the reactions one instance received in a large room over a short window, counted per
+// emoji instead of sent one by one
+type ReactionBatchMessage struct {
+	RoomID    uuid.UUID      `json:"room_id"`
+	Counts    map[string]int `json:"counts"`
+	Total     int            `json:"total"`
+	StartedAt time.Time      `json:"started_at"`
+	EndedAt   time.Time      `json:"ended_at"`
+}
+
+// NormalizeReaction trims a reaction and checks it is emoji rather than text. letters, digits, spaces, punctuation,
+// math and currency symbols, modifier symbols and control characters are refused so reactions cannot be used as a
+// second chat
+func NormalizeReaction(emoji string) (string, bool) {
+	emoji = strings.TrimSpace(emoji)
+	if emoji == "" || utf8.RuneCountInString(emoji) > MaxReactionRunes {
+		return "", false
+	}
+	for _, r := range emoji {
+		if r == utf8.RuneError || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) || unicode.IsPunct(r) {
+			return "", false
+		}
+		if unicode.In(r, unicode.Sm, unicode.Sc, unicode.Cc) || (unicode.Is(unicode.Sk, r) && !isSkinToneModifier(r)) {
+			return "", false
+		}
+	}
+	return emoji, true
+}
+
+// isSkinToneModifier reports whether r is one of the emoji skin tone modifiers
+func isSkinToneModifier(r rune) bool {
+	return r >= firstSkinToneModifier && r <= lastSkinToneModifier
+}
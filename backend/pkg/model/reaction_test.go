@@ -0,0 +1,38 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeReaction(t *testing.T) {
+	tests := []struct {
+		name  string
+		emoji string
+		want  string
+		ok    bool
+	}{
+		{name: "single emoji", emoji: "🔥", want: "🔥", ok: true},
+		{name: "trimmed", emoji: " 😂 ", want: "😂", ok: true},
+		{name: "joined emoji", emoji: "👨‍👩‍👧", want: "👨‍👩‍👧", ok: true},
+		{name: "flag", emoji: "🇮🇩", want: "🇮🇩", ok: true},
+		{name: "empty", emoji: "  ", ok: false},
+		{name: "text", emoji: "lol", ok: false},
+		{name: "emoji with text", emoji: "🔥hot", ok: false},
+		{name: "too long", emoji: "🔥🔥🔥🔥🔥🔥🔥🔥🔥", ok: false},
+		{name: "skin tone", emoji: "👍🏽", want: "👍🏽", ok: true},
+		{name: "math symbol", emoji: "+", ok: false},
+		{name: "currency symbol", emoji: "$", ok: false},
+		{name: "modifier symbol", emoji: "^", ok: false},
+		{name: "control character", emoji: "🔥\x07", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NormalizeReaction(tt.emoji)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Report is an abuse report filed by a room member against another participant
+type Report struct {
+	ID             uuid.UUID    `json:"id" db:"id"`
+	RoomID         uuid.UUID    `json:"room_id" db:"room_id"`
+	ReporterID     uuid.UUID    `json:"reporter_id" db:"reporter_id"`
+	TargetUserID   uuid.UUID    `json:"target_user_id" db:"target_user_id"` // user or guest session ID, guests have no users row
+	Reason         string       `json:"reason" db:"reason"`
+	ChatSnapshot   ChatSnapshot `json:"chat_snapshot" db:"chat_snapshot"`
+	Status         string       `json:"status" db:"status"`
+	CreatedAt      time.Time    `json:"created_at" db:"created_at"`
+	ReporterEmail  string       `json:"reporter_email,omitempty"`
+	RoomName       string       `json:"room_name,omitempty"`
+	TargetUsername string       `json:"target_username,omitempty" db:"target_username"`
+}
+
+// ReportStatus constants
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusReviewed  = "reviewed"
+	ReportStatusDismissed = "dismissed"
+)
+
+// MaxReportReasonLength caps the free-text reason of a report
+const MaxReportReasonLength = 1000
+
+// ChatSnapshot is the recent chat of a room captured when a report is filed
+type ChatSnapshot []ChatLogEntry
+
+// Value implements driver.Valuer so the snapshot is stored as JSONB
+func (s ChatSnapshot) Value() (driver.Value, error) {
+	if s == nil {
+		s = ChatSnapshot{}
+	}
+	return json.Marshal(s)
+}
+
+// Scan implements sql.Scanner for JSONB snapshot columns
+func (s *ChatSnapshot) Scan(value interface{}) error {
+	if value == nil {
+		*s = ChatSnapshot{}
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ChatSnapshot", value)
+	}
+
+	return json.Unmarshal(data, s)
+}
+
+// CreateReportRequest represents the request to report a participant of a room
+type CreateReportRequest struct {
+	TargetUserID   uuid.UUID `json:"target_user_id" binding:"required"`
+	TargetUsername string    `json:"target_username,omitempty"`
+	Reason         string    `json:"reason" binding:"required"`
+}
+
+// CreateReportResponse represents the response after filing a report
+type CreateReportResponse struct {
+	Report  Report `json:"report"`
+	Message string `json:"message"`
+}
+
+// ReportListResponse represents a paginated list of reports for moderators
+type ReportListResponse = Page[Report]
@@ -2,6 +2,10 @@ package model
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,12 +13,169 @@ import (
 
 // Room represents a watch party room
 type Room struct {
-	ID          uuid.UUID `json:"id" db:"id"`
-	MovieID     uuid.UUID `json:"movie_id" db:"movie_id"`
-	HostID      uuid.UUID `json:"host_id" db:"host_id"`
-	Name        string    `json:"name" db:"name"`
-	Description string    `json:"description" db:"description"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	ID          uuid.UUID    `json:"id" db:"id"`
+	MovieID     uuid.UUID    `json:"movie_id" db:"movie_id"`
+	HostID      uuid.UUID    `json:"host_id" db:"host_id"`
+	Name        string       `json:"name" db:"name"`
+	Description string       `json:"description" db:"description"`
+	Settings    RoomSettings `json:"settings" db:"settings"`
+	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
+}
+
+// RoomSettings holds per-room behavior that the host can change while the room is live
+type RoomSettings struct {
+	ControlMode          string    `json:"control_mode"`
+	MaxParticipants      int       `json:"max_participants"`       // 0 means unlimited
+	AllowedPlaybackRates []float64 `json:"allowed_playback_rates"` // empty means any supported rate
+	ChatEnabled          bool      `json:"chat_enabled"`
+	ReactionsEnabled     bool      `json:"reactions_enabled"`
+	AllowGuestRequests   bool      `json:"allow_guest_requests"` // guests without an account may ask the host to let them in
+	WaitForBuffering     bool      `json:"wait_for_buffering"`
+	AutoHandoff          bool      `json:"auto_handoff"`
+	MinPlayBufferSeconds *float64  `json:"min_play_buffer_seconds,omitempty"` // nil uses the server default, 0 disables the play gate
+	SyncToleranceSeconds *float64  `json:"sync_tolerance_seconds,omitempty"`  // drift a participant may have before being resynced, nil uses the server default, 0 disables correction
+	LinkBaseURL          string    `json:"link_base_url,omitempty"`           // frontend origin of the room's emailed links, empty uses the configured one
+}
+
+// RoomControlMode constants
+const (
+	ControlModeFree     = "free"      // every participant can control playback
+	ControlModeHostOnly = "host_only" // only the host controls playback
+)
+
+// IsControlMode reports whether mode is one of the room control modes
+func IsControlMode(mode string) bool {
+	return mode == ControlModeFree || mode == ControlModeHostOnly
+}
+
+// playback rate bounds accepted in room settings
+const (
+	MinPlaybackRate = 0.25
+	MaxPlaybackRate = 4.0
+)
+
+// MaxPlayBufferSeconds bounds the buffered-ahead a host can require before play
+const MaxPlayBufferSeconds = 60.0
+
+// MaxSyncToleranceSeconds bounds the drift a host can let participants have before they are resynced
+const MaxSyncToleranceSeconds = 10.0
+
+// DefaultRoomSettings returns the settings applied to new rooms
+func DefaultRoomSettings() RoomSettings {
+	return RoomSettings{
+		ControlMode:          ControlModeFree,
+		MaxParticipants:      0,
+		AllowedPlaybackRates: []float64{0.5, 0.75, 1, 1.25, 1.5, 2},
+		ChatEnabled:          true,
+		ReactionsEnabled:     true,
+		AllowGuestRequests:   true,
+		WaitForBuffering:     false,
+		AutoHandoff:          true,
+	}
+}
+
+// Validate checks that the settings are within supported values
+func (rs RoomSettings) Validate() error {
+	if !IsControlMode(rs.ControlMode) {
+		return fmt.Errorf("invalid room settings: control_mode must be %q or %q", ControlModeFree, ControlModeHostOnly)
+	}
+
+	if rs.MaxParticipants < 0 {
+		return fmt.Errorf("invalid room settings: max_participants cannot be negative")
+	}
+
+	for _, rate := range rs.AllowedPlaybackRates {
+		if rate < MinPlaybackRate || rate > MaxPlaybackRate {
+			return fmt.Errorf("invalid room settings: playback rate %.2f must be between %.2f and %.2f", rate, MinPlaybackRate, MaxPlaybackRate)
+		}
+	}
+
+	if rs.MinPlayBufferSeconds != nil && (*rs.MinPlayBufferSeconds < 0 || *rs.MinPlayBufferSeconds > MaxPlayBufferSeconds) {
+		return fmt.Errorf("invalid room settings: min_play_buffer_seconds must be between 0 and %.0f", MaxPlayBufferSeconds)
+	}
+
+	if rs.SyncToleranceSeconds != nil && (*rs.SyncToleranceSeconds < 0 || *rs.SyncToleranceSeconds > MaxSyncToleranceSeconds) {
+		return fmt.Errorf("invalid room settings: sync_tolerance_seconds must be between 0 and %.0f", MaxSyncToleranceSeconds)
+	}
+
+	return nil
+}
+
+// Value implements driver.Valuer so settings are stored as JSONB
+func (rs RoomSettings) Value() (driver.Value, error) {
+	return json.Marshal(rs)
+}
+
+// Scan implements sql.Scanner, fields missing from the stored JSON keep their defaults
+func (rs *RoomSettings) Scan(value interface{}) error {
+	*rs = DefaultRoomSettings()
+
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported room settings type %T", value)
+	}
+
+	return json.Unmarshal(data, rs)
+}
+
+// UpdateRoomSettingsRequest represents a partial update of room settings, omitted fields are unchanged
+type UpdateRoomSettingsRequest struct {
+	ControlMode          *string    `json:"control_mode,omitempty"`
+	MaxParticipants      *int       `json:"max_participants,omitempty"`
+	AllowedPlaybackRates *[]float64 `json:"allowed_playback_rates,omitempty"`
+	ChatEnabled          *bool      `json:"chat_enabled,omitempty"`
+	ReactionsEnabled     *bool      `json:"reactions_enabled,omitempty"`
+	AllowGuestRequests   *bool      `json:"allow_guest_requests,omitempty"`
+	WaitForBuffering     *bool      `json:"wait_for_buffering,omitempty"`
+	AutoHandoff          *bool      `json:"auto_handoff,omitempty"`
+	MinPlayBufferSeconds *float64   `json:"min_play_buffer_seconds,omitempty"`
+	SyncToleranceSeconds *float64   `json:"sync_tolerance_seconds,omitempty"`
+	LinkBaseURL          *string    `json:"link_base_url,omitempty"` // empty string clears the override
+}
+
+// ApplyTo returns a copy of settings with the requested changes applied
+func (r *UpdateRoomSettingsRequest) ApplyTo(settings RoomSettings) RoomSettings {
+	if r.ControlMode != nil {
+		settings.ControlMode = *r.ControlMode
+	}
+	if r.MaxParticipants != nil {
+		settings.MaxParticipants = *r.MaxParticipants
+	}
+	if r.AllowedPlaybackRates != nil {
+		settings.AllowedPlaybackRates = *r.AllowedPlaybackRates
+	}
+	if r.ChatEnabled != nil {
+		settings.ChatEnabled = *r.ChatEnabled
+	}
+	if r.ReactionsEnabled != nil {
+		settings.ReactionsEnabled = *r.ReactionsEnabled
+	}
+	if r.AllowGuestRequests != nil {
+		settings.AllowGuestRequests = *r.AllowGuestRequests
+	}
+	if r.WaitForBuffering != nil {
+		settings.WaitForBuffering = *r.WaitForBuffering
+	}
+	if r.AutoHandoff != nil {
+		settings.AutoHandoff = *r.AutoHandoff
+	}
+	if r.MinPlayBufferSeconds != nil {
+		settings.MinPlayBufferSeconds = r.MinPlayBufferSeconds
+	}
+	if r.SyncToleranceSeconds != nil {
+		settings.SyncToleranceSeconds = r.SyncToleranceSeconds
+	}
+	if r.LinkBaseURL != nil {
+		settings.LinkBaseURL = *r.LinkBaseURL
+	}
+	return settings
 }
 
 // RoomAccess represents user access to a room
@@ -23,9 +184,17 @@ type RoomAccess struct {
 	RoomID     uuid.UUID `json:"room_id" db:"room_id"`
 	AccessType string    `json:"access_type" db:"access_type"` // "granted" or "guest"
 	Status     string    `json:"status" db:"status"`           // "granted", "requested", "denied"
+	Role       string    `json:"role" db:"role"`               // "member" or "co_host"
 	GrantedAt  time.Time `json:"granted_at" db:"granted_at"`
 }
 
+// room roles, the host is the room's host_id and never stored as a member role
+const (
+	RoomRoleHost   = "host"    // controls everything and is the only one who can change settings or delete the room
+	RoomRoleCoHost = "co_host" // controls playback and admits guests on behalf of the host
+	RoomRoleMember = "member"
+)
+
 // RoomAccessType constants
 const (
 	AccessTypeGranted = "granted"
@@ -46,18 +215,31 @@ const (
 	GuestStatusDenied   = "denied"   // Guest request was denied
 )
 
+// GuestRequestEvent is published when a guest access request is created or reviewed, the host's
+// pending list and the guest's waiting page both follow it
+type GuestRequestEvent struct {
+	RequestID uuid.UUID `json:"request_id"`
+	RoomID    uuid.UUID `json:"room_id"`
+	GuestName string    `json:"guest_name"`
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
 // CreateRoomRequest represents the request to create a new room
 type CreateRoomRequest struct {
 	MovieID     uuid.UUID `json:"movie_id" binding:"required"`
 	Name        string    `json:"name" binding:"required"`
 	Description string    `json:"description"`
+	ControlMode string    `json:"control_mode,omitempty"` // empty uses the deployment's default control mode
 }
 
 // CreateRoomResponse represents the response after creating a room
 type CreateRoomResponse struct {
-	Room        Room   `json:"room"`
-	InviteToken string `json:"invite_token,omitempty"`
-	Message     string `json:"message"`
+	Room        Room        `json:"room"`
+	InviteToken string      `json:"invite_token,omitempty"`
+	MovieStatus MovieStatus `json:"movie_status"`       // anything but available means the room cannot play yet
+	Existing    bool        `json:"existing,omitempty"` // the host already had a room for the movie and got it back instead of a new one
+	Message     string      `json:"message"`
 }
 
 // RoomWithDetails represents a room with additional details
@@ -68,6 +250,21 @@ type RoomWithDetails struct {
 	MemberCount int   `json:"member_count"`
 }
 
+// RoomSummary is the compact view of a room the home screen lists, stored room data joined with the live
+// state service-sync holds for it
+type RoomSummary struct {
+	ID               uuid.UUID `json:"id"`
+	Name             string    `json:"name"`
+	MovieID          uuid.UUID `json:"movie_id"`
+	MovieTitle       string    `json:"movie_title"`
+	PosterURL        string    `json:"poster_url,omitempty"` // empty when the movie has no poster or storage is not exposed to clients
+	PosterPath       string    `json:"-"`                    // storage path of the poster, signed into PosterURL
+	IsHost           bool      `json:"is_host"`
+	ParticipantCount int       `json:"participant_count"` // participants connected right now
+	IsPlaying        bool      `json:"is_playing"`
+	CurrentTime      float64   `json:"current_time"`
+}
+
 // InviteUserRequest represents the request to invite a user to a room
 type InviteUserRequest struct {
 	Email   string `json:"email" binding:"required,email"`
@@ -81,6 +278,106 @@ type InviteUserResponse struct {
 	Message     string    `json:"message"`
 }
 
+// MaxBatchInviteEmails bounds the emails one batch invite handles
+const MaxBatchInviteEmails = 50
+
+// BatchInviteRequest represents the request to invite several people to a room at once
+type BatchInviteRequest struct {
+	Emails  []string `json:"emails" binding:"required,min=1,dive,email"`
+	Message string   `json:"message,omitempty"`
+}
+
+// UnmarshalJSON trims the emails while decoding so an address pasted with spaces around it passes the email binding
+func (r *BatchInviteRequest) UnmarshalJSON(data []byte) error {
+	type plain BatchInviteRequest
+	err := json.Unmarshal(data, (*plain)(r))
+	if err != nil {
+		return err
+	}
+
+	for i, email := range r.Emails {
+		r.Emails[i] = strings.TrimSpace(email)
+	}
+	return nil
+}
+
+// batch invite outcomes of one email
+const (
+	InviteStatusInvited          = "invited"
+	InviteStatusAlreadyHasAccess = "already_has_access"
+	InviteStatusFailed           = "failed"
+)
+
+// BatchInviteResult is the outcome of inviting one email of a batch invite
+type BatchInviteResult struct {
+	Email  string `json:"email"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BatchInviteResponse reports the outcome of a batch invite per email
+type BatchInviteResponse struct {
+	Results          []BatchInviteResult `json:"results"`
+	Invited          int                 `json:"invited"`
+	AlreadyHasAccess int                 `json:"already_has_access"`
+	Failed           int                 `json:"failed"`
+}
+
+// TransferHostRequest represents the request to hand room host over to another member
+type TransferHostRequest struct {
+	NewHostID uuid.UUID `json:"new_host_id" binding:"required"`
+}
+
+// TransferHostResponse represents the response after transferring room host
+type TransferHostResponse struct {
+	Room           Room      `json:"room"`
+	PreviousHostID uuid.UUID `json:"previous_host_id"`
+	Message        string    `json:"message"`
+}
+
+// CoHostRequest names the member to make co-host
+type CoHostRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
+
+// RoomAuditEntry represents a recorded change to a room
+type RoomAuditEntry struct {
+	ID        uuid.UUID       `json:"id" db:"id"`
+	RoomID    uuid.UUID       `json:"room_id" db:"room_id"`
+	ActorID   *uuid.UUID      `json:"actor_id,omitempty" db:"actor_id"` // nil for changes made by the system
+	Action    string          `json:"action" db:"action"`
+	Details   json.RawMessage `json:"details,omitempty" db:"details"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+// RoomAuditAction constants
+const (
+	AuditActionHostTransferred = "host_transferred"
+	AuditActionHostAutoHandoff = "host_auto_handoff"
+	AuditActionCoHostGranted   = "co_host_granted"
+	AuditActionCoHostRevoked   = "co_host_revoked"
+)
+
+// RoomDiagnostics gathers the live sync data of a room for debugging
+type RoomDiagnostics struct {
+	RoomID          uuid.UUID         `json:"room_id"`
+	ConnectionCount int               `json:"connection_count"`
+	Instances       map[string]int    `json:"instances"` // sync instance ID to number of connections it holds
+	State           map[string]string `json:"state"`     // raw playback state hash
+	HostID          string            `json:"host_id,omitempty"`
+	Participants    []ParticipantInfo `json:"participants"`
+	LockHolder      string            `json:"lock_holder,omitempty"`
+	LockTTLMillis   int64             `json:"lock_ttl_ms,omitempty"`
+	RecentErrors    map[string]int    `json:"recent_errors"` // error code to count over the last hour
+	GeneratedAt     time.Time         `json:"generated_at"`
+}
+
+// RoomResyncResponse lists the sync instances a resync request was routed to
+type RoomResyncResponse struct {
+	RoomID    uuid.UUID `json:"room_id"`
+	Instances []string  `json:"instances"`
+}
+
 // JoinRoomRequest represents the request to join a room
 type JoinRoomRequest struct {
 	InviteToken string `json:"invite_token,omitempty"`
@@ -160,6 +457,17 @@ type GuestSession struct {
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 }
 
+// GuestSessionInfo is the response of validating a guest session token
+type GuestSessionInfo struct {
+	Valid      bool      `json:"valid"`
+	RoomID     uuid.UUID `json:"room_id"`
+	RoomName   string    `json:"room_name"`
+	MovieTitle string    `json:"movie_title"`
+	GuestName  string    `json:"guest_name"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	ExpiresIn  int64     `json:"expires_in"` // seconds until the session expires
+}
+
 // RoomGuestInfo represents basic room information for guests (public, no auth required)
 type RoomGuestInfo struct {
 	ID          uuid.UUID      `json:"id"`
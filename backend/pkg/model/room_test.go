@@ -0,0 +1,18 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchInviteRequestTrimsEmails(t *testing.T) {
+	var req BatchInviteRequest
+	err := json.Unmarshal([]byte(`{"emails": [" ana@example.com", "bo@example.com\n"], "message": "join us"}`), &req)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"ana@example.com", "bo@example.com"}, req.Emails)
+	assert.Equal(t, "join us", req.Message)
+}
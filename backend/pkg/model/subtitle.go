@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxSubtitleFileSize bounds an uploaded subtitle file, real tracks are well below this
+const MaxSubtitleFileSize = 2 * 1024 * 1024 // 2MB
+
+// MovieSubtitle is a WebVTT track added to a movie after upload, one per language
+type MovieSubtitle struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	MovieID    uuid.UUID `json:"movie_id" db:"movie_id"`
+	Language   string    `json:"language" db:"language"` // BCP 47 tag, e.g. "en" or "pt-BR"
+	Label      string    `json:"label" db:"label"`       // shown in the player's track menu
+	FilePath   string    `json:"file_path" db:"file_path"`
+	UploadedBy uuid.UUID `json:"uploaded_by" db:"uploaded_by"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// UploadSubtitleRequest carries a subtitle file and the track it describes
+type UploadSubtitleRequest struct {
+	Language string
+	Label    string
+	Filename string
+	Content  []byte
+}
+
+// SubtitleTrack is a subtitle as handed to players, with a signed URL to the WebVTT file
+type SubtitleTrack struct {
+	Language string `json:"language"`
+	Label    string `json:"label"`
+	URL      string `json:"url"`
+}
+
+// SubtitleListResponse lists the subtitle tracks of a movie
+type SubtitleListResponse struct {
+	MovieID   uuid.UUID       `json:"movie_id"`
+	Subtitles []SubtitleTrack `json:"subtitles"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
@@ -1,7 +1,11 @@
 package model
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
@@ -18,6 +22,31 @@ const (
 	ActionBuffering SyncAction = "buffering"
 	ActionReady     SyncAction = "ready"
 	ActionChat      SyncAction = "chat"
+
+	// ActionHostChanged is published by service-api when room host is reassigned
+	ActionHostChanged SyncAction = "host_changed"
+	// ActionSettingsChanged is published by service-api when room settings are updated
+	ActionSettingsChanged SyncAction = "settings_changed"
+	// ActionDefaultQualityChanged is published when the host sets the room's starting quality
+	ActionDefaultQualityChanged SyncAction = "default_quality_changed"
+	// ActionForceResync is published when the host snaps every participant to the stored state
+	ActionForceResync SyncAction = "force_resync"
+	// ActionDirectMessage routes a message to a single participant through the instance holding their connection
+	ActionDirectMessage SyncAction = "direct_message"
+	// ActionAnnotation carries a host annotation to every instance, it never changes the playback state
+	ActionAnnotation SyncAction = "annotation"
+	// room-level event published by service-api when the room's movie was deleted
+	ActionMovieUnavailable SyncAction = "movie_unavailable"
+	// ActionRoleChanged is published by service-api when the host grants or revokes co-host
+	ActionRoleChanged SyncAction = "role_changed"
+	// ActionSlowModeChanged is published when the host turns chat slow mode on or off
+	ActionSlowModeChanged SyncAction = "slow_mode_changed"
+	// ActionAnnouncement is published by service-api when an admin announces something to a room
+	ActionAnnouncement SyncAction = "announcement"
+	// ActionReaction carries one viewer's emoji reaction to every instance
+	ActionReaction SyncAction = "reaction"
+	// ActionReactionBatch carries the reactions an instance aggregated over a short window in a large room
+	ActionReactionBatch SyncAction = "reaction_batch"
 )
 
 // SyncMessage represents a synchronization message between clients
@@ -29,6 +58,7 @@ type SyncMessage struct {
 	Action    SyncAction `json:"action"`
 	Timestamp time.Time  `json:"timestamp"`
 	Data      SyncData   `json:"data"`
+	Seq       int64      `json:"seq,omitempty"` // per-room sequence number, set when published by service-sync
 }
 
 // SyncData contains the payload data for sync actions
@@ -43,13 +73,99 @@ type SyncData struct {
 
 // RoomState represents the current state of a room
 type RoomState struct {
-	RoomID       uuid.UUID `json:"room_id"`
-	IsPlaying    bool      `json:"is_playing"`
-	CurrentTime  float64   `json:"current_time"`
-	Duration     float64   `json:"duration"`
-	PlaybackRate float64   `json:"playback_rate"`
-	LastUpdated  time.Time `json:"last_updated"`
-	UpdatedBy    uuid.UUID `json:"updated_by"`
+	RoomID         uuid.UUID `json:"room_id"`
+	IsPlaying      bool      `json:"is_playing"`
+	CurrentTime    float64   `json:"current_time"`
+	Duration       float64   `json:"duration"`
+	PlaybackRate   float64   `json:"playback_rate"`
+	LastUpdated    time.Time `json:"last_updated"`
+	UpdatedBy      uuid.UUID `json:"updated_by"`
+	DefaultQuality string    `json:"default_quality"`         // rendition clients start at on join before ABR takes over, empty means auto
+	Authoritative  bool      `json:"authoritative,omitempty"` // set on force resync, clients hard-seek instead of smoothing
+}
+
+// ProjectedTime estimates the playback position at now, advancing it by the elapsed time while playing
+func (s *RoomState) ProjectedTime(now time.Time) float64 {
+	if !s.IsPlaying || s.LastUpdated.IsZero() {
+		return s.CurrentTime
+	}
+
+	rate := s.PlaybackRate
+	if rate <= 0 {
+		rate = 1.0
+	}
+
+	elapsed := now.Sub(s.LastUpdated).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	projected := s.CurrentTime + elapsed*rate
+	if s.Duration > 0 && projected > s.Duration {
+		projected = s.Duration
+	}
+	return projected
+}
+
+// ParseRoomState reads a room state from the Redis hash service-sync stores it in
+func ParseRoomState(roomID uuid.UUID, data map[string]string) (*RoomState, error) {
+	var err error
+	state := &RoomState{}
+
+	// Parse room_id
+	if roomIDStr, ok := data["room_id"]; ok {
+		if state.RoomID, err = uuid.Parse(roomIDStr); err != nil {
+			return nil, fmt.Errorf("invalid room_id: %w", err)
+		}
+	} else {
+		state.RoomID = roomID
+	}
+
+	// Parse is_playing
+	if isPlayingStr, ok := data["is_playing"]; ok {
+		if state.IsPlaying, err = strconv.ParseBool(isPlayingStr); err != nil {
+			return nil, fmt.Errorf("invalid is_playing: %w", err)
+		}
+	}
+
+	// Parse current_time
+	if currentTimeStr, ok := data["current_time"]; ok {
+		if state.CurrentTime, err = strconv.ParseFloat(currentTimeStr, 64); err != nil {
+			return nil, fmt.Errorf("invalid current_time: %w", err)
+		}
+	}
+
+	// Parse duration
+	if durationStr, ok := data["duration"]; ok {
+		if state.Duration, err = strconv.ParseFloat(durationStr, 64); err != nil {
+			return nil, fmt.Errorf("invalid duration: %w", err)
+		}
+	}
+
+	// Parse playback_rate
+	if playbackRateStr, ok := data["playback_rate"]; ok {
+		if state.PlaybackRate, err = strconv.ParseFloat(playbackRateStr, 64); err != nil {
+			return nil, fmt.Errorf("invalid playback_rate: %w", err)
+		}
+	}
+
+	// Parse last_updated
+	if lastUpdatedStr, ok := data["last_updated"]; ok {
+		if timestamp, err := strconv.ParseInt(lastUpdatedStr, 10, 64); err == nil {
+			state.LastUpdated = time.Unix(timestamp, 0)
+		}
+	}
+
+	// Parse updated_by
+	if updatedByStr, ok := data["updated_by"]; ok {
+		if state.UpdatedBy, err = uuid.Parse(updatedByStr); err != nil {
+			return nil, fmt.Errorf("invalid updated_by: %w", err)
+		}
+	}
+
+	state.DefaultQuality = data["default_quality"]
+
+	return state, nil
 }
 
 // ParticipantInfo represents information about a room participant
@@ -57,9 +173,19 @@ type ParticipantInfo struct {
 	UserID      uuid.UUID `json:"user_id"`
 	Username    string    `json:"username"`
 	IsHost      bool      `json:"is_host"`
+	Role        string    `json:"role"` // host, co_host or member
 	JoinedAt    time.Time `json:"joined_at"`
 	LastSeen    time.Time `json:"last_seen"`
 	IsBuffering bool      `json:"is_buffering"`
+	IsGuest     bool      `json:"is_guest"`
+	AvatarURL   string    `json:"avatar_url,omitempty"`
+}
+
+// BufferReport is the last buffer report of a participant's player, used by the play gate
+type BufferReport struct {
+	BufferedAhead float64   `json:"buffered_ahead"`
+	IsBuffering   bool      `json:"is_buffering"`
+	ReportedAt    time.Time `json:"reported_at"`
 }
 
 // RoomSession represents an active room session with participants
@@ -75,19 +201,38 @@ type RoomSession struct {
 type WebSocketMessage struct {
 	Type    WebSocketEventType `json:"type"`
 	Payload any                `json:"payload"`
+	// Seq is the room event this message delivers, or on state messages the latest event the state includes.
+	// clients that see a jump of more than one request a resync.
+	Seq int64 `json:"seq,omitempty"`
+	// Version is the protocol version the payload is shaped for, the one negotiated when the client connected
+	Version int `json:"version,omitempty"`
 }
 
 type WebSocketEventType string
 
 // WebSocket message types
 const (
-	MessageTypeSync         WebSocketEventType = "sync"
-	MessageTypeState        WebSocketEventType = "state"
-	MessageTypeParticipants WebSocketEventType = "participants"
-	MessageTypeError        WebSocketEventType = "error"
-	MessageTypeHeartbeat    WebSocketEventType = "heartbeat"
-	MessageTypeRequestState WebSocketEventType = "request_state"
-	MessageTypeProvideState WebSocketEventType = "provide_state"
+	MessageTypeSync             WebSocketEventType = "sync"
+	MessageTypeState            WebSocketEventType = "state"
+	MessageTypeParticipants     WebSocketEventType = "participants"
+	MessageTypeError            WebSocketEventType = "error"
+	MessageTypeHeartbeat        WebSocketEventType = "heartbeat"
+	MessageTypeRequestState     WebSocketEventType = "request_state"
+	MessageTypeProvideState     WebSocketEventType = "provide_state"
+	MessageTypeHostChanged      WebSocketEventType = "host_changed"
+	MessageTypeSettings         WebSocketEventType = "settings"
+	MessageTypeTimeSync         WebSocketEventType = "time_sync"
+	MessageTypeDefaultQuality   WebSocketEventType = "default_quality"
+	MessageTypeBufferReport     WebSocketEventType = "buffer_report"
+	MessageTypeAnnotation       WebSocketEventType = "annotation"
+	MessageTypeMovieUnavailable WebSocketEventType = "movie_unavailable"
+	MessageTypeSlowMode         WebSocketEventType = "slow_mode"
+	MessageTypeResumeToken      WebSocketEventType = "resume_token"
+	MessageTypeAnnouncement     WebSocketEventType = "announcement"
+	MessageTypeReaction         WebSocketEventType = "reaction"
+	MessageTypeReactionBatch    WebSocketEventType = "reaction_batch"
+	// sent to the sender of an event, who is left out of its broadcast, so its sequence stays gapless
+	MessageTypeEventSeq WebSocketEventType = "event_seq"
 )
 
 // ErrorMessage represents an error message
@@ -96,10 +241,211 @@ type ErrorMessage struct {
 	Message string `json:"message"`
 }
 
+// MovieUnavailableMessage tells participants the room's movie is gone and the session is over
+type MovieUnavailableMessage struct {
+	RoomID     uuid.UUID `json:"room_id"`
+	MovieID    uuid.UUID `json:"movie_id"`
+	Reason     string    `json:"reason"`
+	RoomStatus string    `json:"room_status"` // always RoomStatusEnded, the room was deleted with its movie
+}
+
+// reasons a movie becomes unavailable to a room
+const (
+	MovieUnavailableReasonDeleted = "deleted"
+)
+
+// RoomStatusEnded marks a session that cannot continue, clients leave the player
+const RoomStatusEnded = "ended"
+
+// DefaultQualityMessage notifies participants that the host changed the room's starting quality
+type DefaultQualityMessage struct {
+	DefaultQuality string    `json:"default_quality"` // empty means auto
+	SetBy          uuid.UUID `json:"set_by"`
+}
+
+// MaxChatSlowModeInterval bounds the gap slow mode can enforce between two chat messages of a user
+const MaxChatSlowModeInterval = 10 * time.Minute
+
+// SlowModeMessage notifies participants that the host changed the room's chat slow mode
+type SlowModeMessage struct {
+	IntervalSeconds int       `json:"interval_seconds"` // minimum gap between two messages of a user, 0 when off
+	SetBy           uuid.UUID `json:"set_by"`
+}
+
+// ResumeTokenMessage gives a client the token to reconnect with after a dropped connection. a reconnect within
+// the grace period keeps its place in the room instead of leaving and joining again
+type ResumeTokenMessage struct {
+	Token        string `json:"token"`
+	GraceSeconds int    `json:"grace_seconds"`
+}
+
+// CloseReason is why a sync connection ended
+type CloseReason string
+
+// reasons a sync connection ends
+const (
+	CloseReasonNormal          CloseReason = "normal"           // the client closed the connection
+	CloseReasonReadError       CloseReason = "read_error"       // reading from the connection failed
+	CloseReasonKicked          CloseReason = "kicked"           // the server removed the user, e.g. after their session was revoked
+	CloseReasonIdleTimeout     CloseReason = "idle_timeout"     // nothing was received within the read deadline
+	CloseReasonServerShutdown  CloseReason = "server_shutdown"  // the instance stopped while the connection was open
+	CloseReasonMaxParticipants CloseReason = "max_participants" // the user was refused because the room is full
+	CloseReasonRoomLimit       CloseReason = "room_limit"       // the user was refused because they already watch in as many rooms as allowed
+	CloseReasonSendFailures    CloseReason = "send_failures"    // writes to the connection kept failing
+	CloseReasonRoomEnded       CloseReason = "room_ended"       // the room cannot continue, e.g. its movie was deleted
+	CloseReasonReplaced        CloseReason = "replaced"         // the user connected to the room again, e.g. from a second tab
+	CloseReasonError           CloseReason = "error"            // the connection could not be set up
+)
+
+// ConnectionStats summarizes the WebSocket connections held by one sync instance
+type ConnectionStats struct {
+	InstanceID        string                `json:"instance_id"`
+	TotalConnections  int                   `json:"total_connections"`
+	Rooms             map[uuid.UUID]int     `json:"rooms"`              // connections per room
+	ClosedConnections map[CloseReason]int64 `json:"closed_connections"` // connections closed since the instance started, per reason
+}
+
+// ViewerCounts is the live viewer load across every sync instance
+type ViewerCounts struct {
+	TotalViewers int               `json:"total_viewers"`
+	Rooms        map[uuid.UUID]int `json:"rooms"`    // viewers per room with at least one connection
+	Instance     *ConnectionStats  `json:"instance"` // connections held by the instance that answered
+	GeneratedAt  time.Time         `json:"generated_at"`
+}
+
+// SyncInstanceInfo is the heartbeat a sync instance keeps alive in Redis while it is serving rooms
+type SyncInstanceInfo struct {
+	InstanceID       string    `json:"instance_id"`
+	StartedAt        time.Time `json:"started_at"`
+	LastHeartbeat    time.Time `json:"last_heartbeat"`
+	TotalConnections int       `json:"total_connections"`
+	Rooms            int       `json:"rooms"`
+}
+
+// InstanceControlMessage is a control action routed to the sync instance holding a room's connections
+type InstanceControlMessage struct {
+	ID       uuid.UUID              `json:"id"`
+	RoomID   uuid.UUID              `json:"room_id"`
+	Action   SyncAction             `json:"action"`
+	IssuedBy uuid.UUID              `json:"issued_by"`
+	IssuedAt time.Time              `json:"issued_at"`
+	Extra    map[string]interface{} `json:"extra,omitempty"`
+
+	// set for ActionDirectMessage
+	TargetUserID uuid.UUID         `json:"target_user_id,omitempty"`
+	Message      *WebSocketMessage `json:"message,omitempty"`
+}
+
+// ChatLogEntry is a chat message kept in a room's recent chat buffer
+type ChatLogEntry struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Message  string    `json:"message"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+// annotation limits
+const (
+	MaxAnnotationLength          = 280
+	DefaultAnnotationDisplayTime = 5 * time.Second
+	MaxAnnotationDisplayTime     = 30 * time.Second
+	AnnotationInterval           = 2 * time.Second // minimum gap between two annotations of the same user in a room
+)
+
+// AnnotationMessage is timed text the host overlays on everyone's player, e.g. "watch this part"
+type AnnotationMessage struct {
+	ID             uuid.UUID `json:"id"`
+	RoomID         uuid.UUID `json:"room_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	Username       string    `json:"username"`
+	Text           string    `json:"text"`
+	DisplaySeconds float64   `json:"display_seconds"`
+	VideoTime      *float64  `json:"video_time,omitempty"` // playback position the annotation refers to, if any
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// MaxAnnouncementLength bounds the text of an admin announcement
+const MaxAnnouncementLength = 500
+
+// AnnouncementSeverity tells clients how prominently to show an announcement
+type AnnouncementSeverity string
+
+// announcement severities
+const (
+	AnnouncementSeverityInfo    AnnouncementSeverity = "info"
+	AnnouncementSeverityWarning AnnouncementSeverity = "warning"
+)
+
+// AnnouncementRequest is an operator message for everyone in a room, e.g. "server restarting in 5 min"
+type AnnouncementRequest struct {
+	Message  string               `json:"message" binding:"required"`
+	Severity AnnouncementSeverity `json:"severity"` // defaults to info
+}
+
+// Normalize trims the message, fills in the default severity and checks both
+func (r *AnnouncementRequest) Normalize() error {
+	r.Message = strings.TrimSpace(r.Message)
+	if r.Message == "" {
+		return fmt.Errorf("message is required")
+	}
+	if utf8.RuneCountInString(r.Message) > MaxAnnouncementLength {
+		return fmt.Errorf("message must be at most %d characters", MaxAnnouncementLength)
+	}
+
+	switch r.Severity {
+	case "":
+		r.Severity = AnnouncementSeverityInfo
+	case AnnouncementSeverityInfo, AnnouncementSeverityWarning:
+	default:
+		return fmt.Errorf("severity must be %q or %q", AnnouncementSeverityInfo, AnnouncementSeverityWarning)
+	}
+	return nil
+}
+
+// AnnouncementMessage is an admin announcement as participants receive it
+type AnnouncementMessage struct {
+	ID       uuid.UUID            `json:"id"`
+	RoomID   uuid.UUID            `json:"room_id"`
+	Message  string               `json:"message"`
+	Severity AnnouncementSeverity `json:"severity"`
+	SentAt   time.Time            `json:"sent_at"`
+}
+
+// AnnouncementResponse lists the rooms an announcement was published to
+type AnnouncementResponse struct {
+	Rooms []uuid.UUID `json:"rooms"`
+}
+
+// HostChangeReason constants
+const (
+	HostChangeReasonTransfer    = "transfer"     // host handed over control manually
+	HostChangeReasonAutoHandoff = "auto_handoff" // host disconnected and did not return within the grace period
+)
+
+// HostChangedMessage notifies participants that the room host has changed
+type HostChangedMessage struct {
+	RoomID         uuid.UUID `json:"room_id"`
+	PreviousHostID uuid.UUID `json:"previous_host_id"`
+	NewHostID      uuid.UUID `json:"new_host_id"`
+	ChangedBy      uuid.UUID `json:"changed_by"`
+	ChangedAt      time.Time `json:"changed_at"`
+	Reason         string    `json:"reason"`
+}
+
 // HeartbeatMessage represents a heartbeat message
 type HeartbeatMessage struct {
-	Timestamp time.Time `json:"timestamp"`
-	UserID    uuid.UUID `json:"user_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	UserID     uuid.UUID `json:"user_id"`
+	ServerTime int64     `json:"server_time"` // unix milliseconds, the reference clock for drift correction
+}
+
+// TimeSyncMessage is the server reply to a client time_sync request, all times are unix milliseconds.
+// clients compute round trip = (t3 - t0) - (t2 - t1) and offset = ((t1 - t0) + (t2 - t3)) / 2,
+// where t0 is ClientTime, t1 ServerReceiveTime, t2 ServerSendTime and t3 the client receive time.
+type TimeSyncMessage struct {
+	ClientTime        int64 `json:"client_time"`
+	ServerReceiveTime int64 `json:"server_receive_time"`
+	ServerSendTime    int64 `json:"server_send_time"`
 }
 
 // UserLogEntry represents a user action log entry
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnouncementRequestNormalize(t *testing.T) {
+	req := AnnouncementRequest{Message: "  server restarting in 5 min "}
+	require.NoError(t, req.Normalize())
+	assert.Equal(t, "server restarting in 5 min", req.Message)
+	assert.Equal(t, AnnouncementSeverityInfo, req.Severity, "severity defaults to info")
+
+	req = AnnouncementRequest{Message: "maintenance", Severity: AnnouncementSeverityWarning}
+	require.NoError(t, req.Normalize())
+	assert.Equal(t, AnnouncementSeverityWarning, req.Severity)
+
+	req = AnnouncementRequest{Message: "maintenance", Severity: "critical"}
+	assert.Error(t, req.Normalize(), "unknown severity")
+
+	req = AnnouncementRequest{Message: "   "}
+	assert.Error(t, req.Normalize(), "blank message")
+
+	req = AnnouncementRequest{Message: strings.Repeat("a", MaxAnnouncementLength+1)}
+	assert.Error(t, req.Normalize(), "message too long")
+}
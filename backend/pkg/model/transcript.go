@@ -0,0 +1,75 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// transcript formats
+const (
+	TranscriptFormatJSON = "json"
+	TranscriptFormatText = "txt"
+)
+
+// kinds of transcript entries
+const (
+	TranscriptEntryChat       = "chat"
+	TranscriptEntryAnnotation = "annotation"
+)
+
+// TranscriptEntry is one line of a room transcript, a chat message or an annotation
+type TranscriptEntry struct {
+	Type      string    `json:"type"`
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	Text      string    `json:"text"`
+	VideoTime *float64  `json:"video_time,omitempty"` // playback position an annotation refers to
+	At        time.Time `json:"at"`
+}
+
+// RoomTranscript is the recent chat of a room, and for its host the annotations, oldest entry first. it only covers
+// what service-sync keeps in Redis, the last CHAT_HISTORY_MAX_MESSAGES (50 by default) messages and annotations
+type RoomTranscript struct {
+	RoomID              uuid.UUID         `json:"room_id"`
+	RoomName            string            `json:"room_name"`
+	IncludesAnnotations bool              `json:"includes_annotations"`
+	Entries             []TranscriptEntry `json:"entries"`
+	GeneratedAt         time.Time         `json:"generated_at"`
+}
+
+// Text renders the transcript as plain text, one timestamped line per entry
+func (t *RoomTranscript) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Transcript of %s (%s)\n", t.RoomName, t.GeneratedAt.UTC().Format(time.RFC3339))
+
+	for _, entry := range t.Entries {
+		at := entry.At.UTC().Format("2006-01-02 15:04:05")
+		switch entry.Type {
+		case TranscriptEntryAnnotation:
+			if entry.VideoTime != nil {
+				fmt.Fprintf(&b, "[%s] * %s annotated %s: %s\n", at, entry.Username, formatVideoTime(*entry.VideoTime), entry.Text)
+			} else {
+				fmt.Fprintf(&b, "[%s] * %s annotated: %s\n", at, entry.Username, entry.Text)
+			}
+		default:
+			fmt.Fprintf(&b, "[%s] %s: %s\n", at, entry.Username, entry.Text)
+		}
+	}
+
+	return b.String()
+}
+
+// formatVideoTime renders a playback position as h:mm:ss, or m:ss below an hour
+func formatVideoTime(seconds float64) string {
+	total := int(seconds)
+	if total < 0 {
+		total = 0
+	}
+	if total >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", total/3600, total%3600/60, total%60)
+	}
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoomTranscriptText(t *testing.T) {
+	at := time.Date(2026, 3, 14, 20, 5, 9, 0, time.UTC)
+	videoTime := 3725.4
+
+	transcript := RoomTranscript{
+		RoomID:      uuid.New(),
+		RoomName:    "Movie night",
+		GeneratedAt: at.Add(time.Hour),
+		Entries: []TranscriptEntry{
+			{Type: TranscriptEntryChat, Username: "alice", Text: "hi all", At: at},
+			{Type: TranscriptEntryAnnotation, Username: "bob", Text: "watch this", VideoTime: &videoTime, At: at.Add(time.Minute)},
+			{Type: TranscriptEntryAnnotation, Username: "bob", Text: "intermission", At: at.Add(2 * time.Minute)},
+		},
+	}
+
+	assert.Equal(t, "Transcript of Movie night (2026-03-14T21:05:09Z)\n"+
+		"[2026-03-14 20:05:09] alice: hi all\n"+
+		"[2026-03-14 20:06:09] * bob annotated 1:02:05: watch this\n"+
+		"[2026-03-14 20:07:09] * bob annotated: intermission\n", transcript.Text())
+}
@@ -1,6 +1,9 @@
 package model
 
 import (
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,9 +15,31 @@ type User struct {
 	Email        string    `json:"email" db:"email"`
 	PasswordHash string    `json:"-" db:"password_hash"` // Never include in JSON responses
 	Role         string    `json:"role" db:"role"`
+	DisplayName  string    `json:"display_name" db:"display_name"`
+	AvatarURL    string    `json:"avatar_url,omitempty" db:"avatar_url"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 }
 
+// Name returns what other users see for this user, never the full email address
+func (u *User) Name() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return DefaultDisplayName(u.Email)
+}
+
+// DefaultDisplayName derives a display name from the local part of an email address
+func DefaultDisplayName(email string) string {
+	name, _, _ := strings.Cut(email, "@")
+	if runes := []rune(name); len(runes) > MaxDisplayNameLength {
+		name = string(runes[:MaxDisplayNameLength])
+	}
+	if name == "" {
+		return "User"
+	}
+	return name
+}
+
 // UserRole constants
 const (
 	RoleUser  = "user"
@@ -42,18 +67,113 @@ type LoginResponse struct {
 
 // UserProfile represents user profile information
 type UserProfile struct {
-	ID        uuid.UUID `json:"id"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          uuid.UUID `json:"id"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 // ToProfile converts User to UserProfile (safe for public consumption)
 func (u *User) ToProfile() UserProfile {
 	return UserProfile{
-		ID:        u.ID,
-		Email:     u.Email,
-		Role:      u.Role,
-		CreatedAt: u.CreatedAt,
+		ID:          u.ID,
+		Email:       u.Email,
+		Role:        u.Role,
+		DisplayName: u.Name(),
+		AvatarURL:   u.AvatarURL,
+		CreatedAt:   u.CreatedAt,
+	}
+}
+
+// PublicProfile is what other participants may see of a user, cached in Redis for service-sync
+type PublicProfile struct {
+	UserID      uuid.UUID `json:"user_id"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url,omitempty"`
+}
+
+// ToPublicProfile converts User to the profile shown to other participants
+func (u *User) ToPublicProfile() PublicProfile {
+	return PublicProfile{
+		UserID:      u.ID,
+		DisplayName: u.Name(),
+		AvatarURL:   u.AvatarURL,
+	}
+}
+
+// profile field limits
+const (
+	MaxDisplayNameLength = 50
+	MaxAvatarURLLength   = 2048
+)
+
+// UpdateProfileRequest represents a partial update of the current user's profile, omitted fields are unchanged
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"display_name,omitempty"`
+	AvatarURL   *string `json:"avatar_url,omitempty"` // empty removes the avatar
+}
+
+// ApplyTo returns a copy of user with the requested changes applied and validated. an external avatar URL must
+// point at one of allowedAvatarHosts or their subdomains, an empty list allows any host
+func (r *UpdateProfileRequest) ApplyTo(user User, allowedAvatarHosts []string) (User, error) {
+	if r.DisplayName != nil {
+		name := strings.TrimSpace(*r.DisplayName)
+		if name == "" {
+			return user, fmt.Errorf("invalid profile: display_name cannot be empty")
+		}
+		if len([]rune(name)) > MaxDisplayNameLength {
+			return user, fmt.Errorf("invalid profile: display_name cannot be longer than %d characters", MaxDisplayNameLength)
+		}
+		user.DisplayName = name
+	}
+
+	if r.AvatarURL != nil {
+		avatarURL := strings.TrimSpace(*r.AvatarURL)
+		// resending the current avatar, such as an uploaded one served by the API, is not a change
+		if avatarURL != "" && avatarURL != user.AvatarURL {
+			if len(avatarURL) > MaxAvatarURLLength {
+				return user, fmt.Errorf("invalid profile: avatar_url cannot be longer than %d characters", MaxAvatarURLLength)
+			}
+			parsed, err := url.Parse(avatarURL)
+			if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+				return user, fmt.Errorf("invalid profile: avatar_url must be an http(s) URL")
+			}
+			if !AvatarHostAllowed(parsed.Hostname(), allowedAvatarHosts) {
+				return user, fmt.Errorf("invalid profile: avatar_url host %s is not allowed, upload the image instead", parsed.Hostname())
+			}
+		}
+		user.AvatarURL = avatarURL
+	}
+
+	return user, nil
+}
+
+// AvatarHostAllowed reports whether host is one of allowed or a subdomain of one, an empty list allows any host
+func AvatarHostAllowed(host string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
 	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, candidate := range allowed {
+		candidate = strings.ToLower(strings.TrimSuffix(candidate, "."))
+		if host == candidate || strings.HasSuffix(host, "."+candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// TokensRevokedMessage announces that every token issued to a user before RevokedAt is no longer valid
+type TokensRevokedMessage struct {
+	UserID    uuid.UUID `json:"user_id"`
+	RevokedAt time.Time `json:"revoked_at"`
+	Reason    string    `json:"reason"`
 }
+
+// token revocation reasons
+const (
+	TokenRevokedReasonLogout      = "logout"
+	TokenRevokedReasonForceLogout = "force_logout"
+)
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvatarHostAllowed(t *testing.T) {
+	allowed := []string{"gravatar.com", "CDN.example.com"}
+
+	tests := []struct {
+		name    string
+		host    string
+		allowed []string
+		want    bool
+	}{
+		{name: "empty list allows any host", host: "images.test", allowed: nil, want: true},
+		{name: "listed host", host: "gravatar.com", allowed: allowed, want: true},
+		{name: "subdomain of listed host", host: "secure.gravatar.com", allowed: allowed, want: true},
+		{name: "hosts compare case-insensitively", host: "cdn.EXAMPLE.com", allowed: allowed, want: true},
+		{name: "unlisted host", host: "evil.test", allowed: allowed, want: false},
+		{name: "suffix without dot is not a subdomain", host: "notgravatar.com", allowed: allowed, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, AvatarHostAllowed(tt.host, tt.allowed))
+		})
+	}
+}
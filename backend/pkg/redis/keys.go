@@ -0,0 +1,212 @@
+package redis
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// keys and channels shared between service-api and service-sync
+
+// RoomEventsChannel returns the pub/sub channel for a room's sync events
+func RoomEventsChannel(roomID uuid.UUID) string {
+	return fmt.Sprintf("room:%s:events", roomID.String())
+}
+
+// RoomEventSeqKey returns the counter numbering the events published on a room's events channel
+func RoomEventSeqKey(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:events:seq:%s", roomID.String())
+}
+
+// GuestRequestsChannel returns the pub/sub channel announcing guest access requests of a room and their review
+func GuestRequestsChannel(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:%s:guest-requests", roomID.String())
+}
+
+// RoomStateKey returns the hash holding a room's playback state
+func RoomStateKey(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:sync:%s", roomID.String())
+}
+
+// ActiveRoomsKey is the sorted set of rooms scored by the last change of their playback state
+func ActiveRoomsKey() string {
+	return "watch-party:rooms:active"
+}
+
+// RoomParticipantsKey returns the hash of participants present in a room, keyed by user ID
+func RoomParticipantsKey(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:participants:%s", roomID.String())
+}
+
+// RoomLockKey returns the key holding the user currently changing a room's state
+func RoomLockKey(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:lock:%s", roomID.String())
+}
+
+// RoomConnectionsKey returns the hash of live WebSocket connections per sync instance for a room
+func RoomConnectionsKey(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:connections:%s", roomID.String())
+}
+
+// RoomUserInstancesKey returns the hash mapping each connected user of a room to the sync instance holding their connection
+func RoomUserInstancesKey(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:user-instances:%s", roomID.String())
+}
+
+// RoomUsernamesKey returns the hash reserving display names in a room, keyed by lowercased name with the holder's user ID as value
+func RoomUsernamesKey(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:usernames:%s", roomID.String())
+}
+
+// RoomErrorsKey returns the hash counting recent errors sent to a room's clients, keyed by error code
+func RoomErrorsKey(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:errors:%s", roomID.String())
+}
+
+// RoomHostKey returns the key caching the current host of a room
+func RoomHostKey(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:host:%s", roomID.String())
+}
+
+// RoomCoHostsKey returns the set caching the user IDs of a room's co-hosts
+func RoomCoHostsKey(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:co-hosts:%s", roomID.String())
+}
+
+// RoomSettingsKey returns the key caching the settings of a room
+func RoomSettingsKey(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:settings:%s", roomID.String())
+}
+
+// RoomRecentChatKey returns the list holding the most recent chat messages of a room
+func RoomRecentChatKey(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:chat:%s", roomID.String())
+}
+
+// RoomAnnotationsKey returns the list holding the most recent annotations of a room
+func RoomAnnotationsKey(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:annotations:%s", roomID.String())
+}
+
+// ReactionRateKey returns the key counting a user's reactions in a room over the current rate window
+func ReactionRateKey(roomID, userID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:reaction-rate:%s:%s", roomID.String(), userID.String())
+}
+
+// AnnotationRateKey returns the key that exists while a user must wait before annotating a room again
+func AnnotationRateKey(roomID, userID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:annotation-rate:%s:%s", roomID.String(), userID.String())
+}
+
+// RoomSlowModeKey returns the key holding a room's chat slow mode interval in seconds, absent when slow mode is off
+func RoomSlowModeKey(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:slow-mode:%s", roomID.String())
+}
+
+// ResumeTokenKey returns the key that maps a dropped connection's resume token to its room and user until the
+// resume grace period passes
+func ResumeTokenKey(token string) string {
+	return fmt.Sprintf("watch-party:sync:resume:%s", token)
+}
+
+// ChatRateKey returns the key that exists while a user must wait before chatting in a slow mode room again
+func ChatRateKey(roomID, userID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:chat-rate:%s:%s", roomID.String(), userID.String())
+}
+
+// SyncInstanceKey returns the heartbeat key of a sync instance, which expires when the instance stops refreshing it
+func SyncInstanceKey(instanceID string) string {
+	return fmt.Sprintf("watch-party:sync:instance:%s", instanceID)
+}
+
+// SyncInstanceControlChannel returns the pub/sub channel a sync instance listens on for control actions
+func SyncInstanceControlChannel(instanceID string) string {
+	return fmt.Sprintf("watch-party:sync:instance:%s:control", instanceID)
+}
+
+// MovieViewSessionKey returns the key marking a viewer's current stream session of a movie, so refetches are not counted again
+func MovieViewSessionKey(movieID uuid.UUID, viewerID string) string {
+	return fmt.Sprintf("watch-party:movie:session:%s:%s", movieID.String(), viewerID)
+}
+
+// MovieDailyViewsKey returns the counter of stream sessions started for a movie on a day (YYYY-MM-DD)
+func MovieDailyViewsKey(movieID uuid.UUID, day string) string {
+	return fmt.Sprintf("watch-party:movie:views:%s:%s", movieID.String(), day)
+}
+
+// MovieDailyViewersKey returns the HyperLogLog of distinct viewers of a movie on a day (YYYY-MM-DD)
+func MovieDailyViewersKey(movieID uuid.UUID, day string) string {
+	return fmt.Sprintf("watch-party:movie:viewers:%s:%s", movieID.String(), day)
+}
+
+// MovieViewersKey returns the HyperLogLog of all distinct viewers a movie ever had
+func MovieViewersKey(movieID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:movie:viewers:%s", movieID.String())
+}
+
+// MovieRemovedKey returns the key marking a movie as recently deleted, so stream requests can tell removal from a bad ID
+func MovieRemovedKey(movieID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:movie:removed:%s", movieID.String())
+}
+
+// MovieSegmentsKey returns the hash holding the parsed segment timeline of each rendition of a movie, by quality
+func MovieSegmentsKey(movieID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:movie:segments:%s", movieID.String())
+}
+
+// RoomMovieKey returns the key holding the ID of the movie a room plays, cached by service-api for service-sync
+func RoomMovieKey(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:movie:%s", roomID.String())
+}
+
+// RoomMovieQualitiesKey returns the key holding the qualities the movie a room plays was published with, cached by
+// service-api for service-sync once the movie is available
+func RoomMovieQualitiesKey(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:movie:qualities:%s", roomID.String())
+}
+
+// MovieAnalyticsPendingKey is the set of "movieID:day" entries whose counters changed since the last flush
+const MovieAnalyticsPendingKey = "watch-party:movie:analytics:pending"
+
+// EmailQueueKey is the sorted set of queued email job IDs, scored by when their next attempt is due (unix ms)
+const EmailQueueKey = "watch-party:email:queue"
+
+// EmailFailedKey is the sorted set of email job IDs that ran out of attempts, scored by when they failed (unix ms)
+const EmailFailedKey = "watch-party:email:failed"
+
+// EmailJobKey returns the key holding an email job and its delivery status
+func EmailJobKey(jobID string) string {
+	return fmt.Sprintf("watch-party:email:job:%s", jobID)
+}
+
+// EmailDedupeKey returns the key that exists while an email with the given dedupe digest was queued recently
+func EmailDedupeKey(digest string) string {
+	return fmt.Sprintf("watch-party:email:dedupe:%s", digest)
+}
+
+// IdempotencyKey returns the key holding the outcome of a request made with an Idempotency-Key header,
+// scope separates callers and endpoints so the same client key cannot collide across them
+func IdempotencyKey(scope, key string) string {
+	return fmt.Sprintf("watch-party:idempotency:%s:%s", scope, key)
+}
+
+// RoomHostHandoffChannel is where service-sync reports automatic host handoffs for persistence
+const RoomHostHandoffChannel = "watch-party:room:host-handoffs"
+
+// RevokedTokenKey returns the key marking a single JWT (by its jti) as revoked until it would have expired
+func RevokedTokenKey(tokenID string) string {
+	return fmt.Sprintf("watch-party:auth:revoked:%s", tokenID)
+}
+
+// UserTokensRevokedKey returns the key holding the unix time in milliseconds up to which all tokens of a user are revoked
+func UserTokensRevokedKey(userID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:auth:revoked-user:%s", userID.String())
+}
+
+// TokenRevocationChannel is where revocations of all tokens of a user are announced, so open connections can be closed
+const TokenRevocationChannel = "watch-party:auth:revocations"
+
+// UserProfileKey returns the key caching the public profile of a user, so service-sync can show display names
+func UserProfileKey(userID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:user:profile:%s", userID.String())
+}
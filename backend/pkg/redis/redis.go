@@ -9,6 +9,7 @@ import (
 	"watch-party/pkg/config"
 	"watch-party/pkg/logger"
 
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -41,6 +42,11 @@ func NewClient(cfg *config.Config) (*Client, error) {
 	}, nil
 }
 
+// Ping checks that Redis answers
+func (c *Client) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
 // Close closes the Redis connection
 func (c *Client) Close() error {
 	return c.client.Close()
@@ -139,6 +145,27 @@ func (c *Client) SetRemove(ctx context.Context, key string, members ...interface
 	return nil
 }
 
+// ReplaceSet swaps the members of a set in one transaction so readers never see it half filled, an empty
+// members list deletes the set. a zero expiration keeps it without a TTL
+func (c *Client) ReplaceSet(ctx context.Context, key string, expiration time.Duration, members ...interface{}) error {
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		if len(members) == 0 {
+			return nil
+		}
+		pipe.SAdd(ctx, key, members...)
+		if expiration > 0 {
+			pipe.Expire(ctx, key, expiration)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replace set: %w", err)
+	}
+
+	return nil
+}
+
 // SetMembers gets all members of a set
 func (c *Client) SetMembers(ctx context.Context, key string) ([]string, error) {
 	result := c.client.SMembers(ctx, key)
@@ -173,6 +200,18 @@ func (c *Client) HSet(ctx context.Context, key string, values ...interface{}) er
 	return nil
 }
 
+// HSetExpire sets field-value pairs in a hash and refreshes its expiration in one MULTI
+func (c *Client) HSetExpire(ctx context.Context, key string, expiration time.Duration, values ...interface{}) error {
+	pipe := c.client.TxPipeline()
+	pipe.HSet(ctx, key, values...)
+	pipe.Expire(ctx, key, expiration)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to set hash fields: %w", err)
+	}
+	return nil
+}
+
 // HGet gets a field value from a hash
 func (c *Client) HGet(ctx context.Context, key, field string) (string, error) {
 	result := c.client.HGet(ctx, key, field)
@@ -194,6 +233,15 @@ func (c *Client) HGetAll(ctx context.Context, key string) (map[string]string, er
 	return result.Val(), nil
 }
 
+// HLen returns how many fields a hash holds, 0 when it does not exist
+func (c *Client) HLen(ctx context.Context, key string) (int64, error) {
+	result := c.client.HLen(ctx, key)
+	if result.Err() != nil {
+		return 0, fmt.Errorf("failed to count hash fields: %w", result.Err())
+	}
+	return result.Val(), nil
+}
+
 // HDel deletes fields from a hash
 func (c *Client) HDel(ctx context.Context, key string, fields ...string) error {
 	result := c.client.HDel(ctx, key, fields...)
@@ -203,6 +251,75 @@ func (c *Client) HDel(ctx context.Context, key string, fields ...string) error {
 	return nil
 }
 
+// HDelCount deletes hash fields and returns how many of them existed
+func (c *Client) HDelCount(ctx context.Context, key string, fields ...string) (int64, error) {
+	result := c.client.HDel(ctx, key, fields...)
+	if result.Err() != nil {
+		return 0, fmt.Errorf("failed to delete hash fields: %w", result.Err())
+	}
+	return result.Val(), nil
+}
+
+// ListPush prepends a JSON-encoded value to a list, trims it to maxLen entries and refreshes its expiration.
+// all three run in one MULTI so concurrent pushes cannot leave the list over its limit.
+func (c *Client) ListPush(ctx context.Context, key string, value interface{}, maxLen int64, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	pipe := c.client.TxPipeline()
+	pipe.LPush(ctx, key, data)
+	pipe.LTrim(ctx, key, 0, maxLen-1)
+	if expiration > 0 {
+		pipe.Expire(ctx, key, expiration)
+	}
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to push to list: %w", err)
+	}
+	return nil
+}
+
+// ListRange gets raw list entries between start and stop, newest first for lists built with ListPush
+func (c *Client) ListRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	result := c.client.LRange(ctx, key, start, stop)
+	if result.Err() != nil {
+		return nil, fmt.Errorf("failed to get list range: %w", result.Err())
+	}
+	return result.Val(), nil
+}
+
+// HIncrBy increments a hash field by the given amount
+func (c *Client) HIncrBy(ctx context.Context, key, field string, incr int64) (int64, error) {
+	result := c.client.HIncrBy(ctx, key, field, incr)
+	if result.Err() != nil {
+		return 0, fmt.Errorf("failed to increment hash field: %w", result.Err())
+	}
+	return result.Val(), nil
+}
+
+// GetString gets a plain string value that was not JSON-encoded, empty if the key does not exist
+func (c *Client) GetString(ctx context.Context, key string) (string, error) {
+	result := c.client.Get(ctx, key)
+	if result.Err() != nil {
+		if result.Err() == redis.Nil {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get key: %w", result.Err())
+	}
+	return result.Val(), nil
+}
+
+// TTL returns the remaining time to live of a key
+func (c *Client) TTL(ctx context.Context, key string) (time.Duration, error) {
+	result := c.client.TTL(ctx, key)
+	if result.Err() != nil {
+		return 0, fmt.Errorf("failed to get ttl: %w", result.Err())
+	}
+	return result.Val(), nil
+}
+
 // ZAdd adds members to a sorted set
 func (c *Client) ZAdd(ctx context.Context, key string, members ...redis.Z) error {
 	result := c.client.ZAdd(ctx, key, members...)
@@ -256,3 +373,165 @@ func (c *Client) Expire(ctx context.Context, key string, expiration time.Duratio
 	}
 	return nil
 }
+
+// Exists reports how many of the given keys exist
+func (c *Client) Exists(ctx context.Context, keys ...string) (int64, error) {
+	result := c.client.Exists(ctx, keys...)
+	if result.Err() != nil {
+		return 0, fmt.Errorf("failed to check keys: %w", result.Err())
+	}
+	return result.Val(), nil
+}
+
+// Incr increments the integer value of a key by one
+func (c *Client) Incr(ctx context.Context, key string) (int64, error) {
+	result := c.client.Incr(ctx, key)
+	if result.Err() != nil {
+		return 0, fmt.Errorf("failed to increment key: %w", result.Err())
+	}
+	return result.Val(), nil
+}
+
+// PFAdd adds elements to a HyperLogLog
+func (c *Client) PFAdd(ctx context.Context, key string, elements ...interface{}) error {
+	result := c.client.PFAdd(ctx, key, elements...)
+	if result.Err() != nil {
+		return fmt.Errorf("failed to add to hyperloglog: %w", result.Err())
+	}
+	return nil
+}
+
+// PFCount returns the approximate cardinality of a HyperLogLog
+func (c *Client) PFCount(ctx context.Context, key string) (int64, error) {
+	result := c.client.PFCount(ctx, key)
+	if result.Err() != nil {
+		return 0, fmt.Errorf("failed to count hyperloglog: %w", result.Err())
+	}
+	return result.Val(), nil
+}
+
+// HSetNX sets a hash field only if it does not exist yet
+func (c *Client) HSetNX(ctx context.Context, key, field string, value interface{}) (bool, error) {
+	result := c.client.HSetNX(ctx, key, field, value)
+	if result.Err() != nil {
+		return false, fmt.Errorf("failed to set hash field if not exists: %w", result.Err())
+	}
+	return result.Val(), nil
+}
+
+// seedHashScript writes a hash and its expiry only while the key does not exist, checked and written atomically
+var seedHashScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return 0
+end
+redis.call("HSET", KEYS[1], unpack(ARGV, 2))
+redis.call("EXPIRE", KEYS[1], ARGV[1])
+return 1
+`)
+
+// SeedHash writes the field-value pairs of a hash that does not exist yet, reporting whether it did
+func (c *Client) SeedHash(ctx context.Context, key string, expiration time.Duration, values ...string) (bool, error) {
+	args := make([]interface{}, 0, len(values)+1)
+	args = append(args, int64(expiration.Seconds()))
+	for _, value := range values {
+		args = append(args, value)
+	}
+
+	seeded, err := seedHashScript.Run(ctx, c.client, []string{key}, args...).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to seed hash: %w", err)
+	}
+	return seeded == 1, nil
+}
+
+// deleteIfValueScript deletes a key only while it holds the expected value, checked and deleted atomically
+var deleteIfValueScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// DeleteIfValue deletes a key only if it still holds the given value, reporting whether it did
+func (c *Client) DeleteIfValue(ctx context.Context, key, value string) (bool, error) {
+	deleted, err := deleteIfValueScript.Run(ctx, c.client, []string{key}, value).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete key if value matches: %w", err)
+	}
+	return deleted == 1, nil
+}
+
+// incrWindowScript counts a hit in a fixed window, the window starts with the first hit and ends when the key expires
+var incrWindowScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// IncrWindow increments the counter at key and returns the count within the current window, which starts with the
+// first increment and lasts window
+func (c *Client) IncrWindow(ctx context.Context, key string, window time.Duration) (int64, error) {
+	count, err := incrWindowScript.Run(ctx, c.client, []string{key}, window.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment windowed counter: %w", err)
+	}
+	return count, nil
+}
+
+// publishNumberedScript takes the next number of a counter and publishes a JSON object stamped with it as "seq",
+// numbered and published atomically so subscribers receive messages in the order of their numbers
+var publishNumberedScript = redis.NewScript(`
+local seq = redis.call("INCR", KEYS[1])
+redis.call("EXPIRE", KEYS[1], ARGV[1])
+redis.call("PUBLISH", ARGV[2], '{"seq":' .. seq .. ',' .. string.sub(ARGV[3], 2))
+return seq
+`)
+
+// PublishNumbered publishes message on channel with the next number of the counter at key added as its "seq"
+// field, and returns that number. message must marshal to a non-empty JSON object without a "seq" field
+func (c *Client) PublishNumbered(ctx context.Context, key string, expiration time.Duration, channel string, message interface{}) (int64, error) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal message: %w", err)
+	}
+	if len(data) < 2 || data[0] != '{' || string(data) == "{}" {
+		return 0, fmt.Errorf("failed to publish numbered message: %s is not a non-empty JSON object", data)
+	}
+
+	seq, err := publishNumberedScript.Run(ctx, c.client, []string{key}, int64(expiration.Seconds()), channel, data).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to publish numbered message: %w", err)
+	}
+	return seq, nil
+}
+
+// RoomEventSeqTTL matches the room state, a room idle for longer starts numbering its events again
+const RoomEventSeqTTL = 24 * time.Hour
+
+// PublishRoomEvent publishes event on the room's events channel numbered with the room's next sequence number, and
+// returns that number. every publisher of room events goes through here so clients can tell when they missed one
+func (c *Client) PublishRoomEvent(ctx context.Context, roomID uuid.UUID, event interface{}) (int64, error) {
+	return c.PublishNumbered(ctx, RoomEventSeqKey(roomID), RoomEventSeqTTL, RoomEventsChannel(roomID), event)
+}
+
+// leaseDueMemberScript moves a sorted set member whose score is due to a later score, checked and moved atomically
+var leaseDueMemberScript = redis.NewScript(`
+local score = redis.call("ZSCORE", KEYS[1], ARGV[1])
+if score and tonumber(score) <= tonumber(ARGV[2]) then
+	redis.call("ZADD", KEYS[1], ARGV[3], ARGV[1])
+	return 1
+end
+return 0
+`)
+
+// LeaseDueMember claims a member of a sorted set used as a schedule: if its score is at most due, it is
+// rescheduled at leaseUntil and true is returned. a claimer that dies leaves the member due again later.
+func (c *Client) LeaseDueMember(ctx context.Context, key, member string, due, leaseUntil float64) (bool, error) {
+	leased, err := leaseDueMemberScript.Run(ctx, c.client, []string{key}, member, due, leaseUntil).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to lease sorted set member: %w", err)
+	}
+	return leased == 1, nil
+}
@@ -0,0 +1,118 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedHash(t *testing.T) {
+	logger.InitLogger(&config.Config{})
+	server := miniredis.RunT(t)
+	client, err := NewClient(&config.Config{Redis: config.RedisConfig{Host: server.Host(), Port: server.Port()}})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	key := RoomStateKey(uuid.New())
+
+	seeded, err := client.SeedHash(ctx, key, time.Hour, "is_playing", "false", "current_time", "120.00")
+	require.NoError(t, err)
+	assert.True(t, seeded)
+	assert.Equal(t, "120.00", server.HGet(key, "current_time"))
+	assert.Equal(t, time.Hour, server.TTL(key))
+
+	seeded, err = client.SeedHash(ctx, key, time.Hour, "current_time", "0.00")
+	require.NoError(t, err)
+	assert.False(t, seeded, "an existing state is never replaced")
+	assert.Equal(t, "120.00", server.HGet(key, "current_time"))
+}
+
+func TestPublishNumbered(t *testing.T) {
+	logger.InitLogger(&config.Config{})
+	server := miniredis.RunT(t)
+	client, err := NewClient(&config.Config{Redis: config.RedisConfig{Host: server.Host(), Port: server.Port()}})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	channel := RoomEventsChannel(uuid.New())
+	pubsub := client.Subscribe(ctx, channel)
+	defer pubsub.Close()
+	_, err = pubsub.Receive(ctx)
+	require.NoError(t, err)
+
+	type event struct {
+		Seq    int64  `json:"seq,omitempty"`
+		Action string `json:"action"`
+	}
+	for _, action := range []string{"play", "pause"} {
+		_, err := client.PublishNumbered(ctx, "seq", time.Hour, channel, event{Action: action})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, time.Hour, server.TTL("seq"))
+
+	for i, action := range []string{"play", "pause"} {
+		msg, err := pubsub.ReceiveMessage(ctx)
+		require.NoError(t, err)
+		var received event
+		require.NoError(t, json.Unmarshal([]byte(msg.Payload), &received))
+		assert.Equal(t, event{Seq: int64(i + 1), Action: action}, received)
+	}
+
+	_, err = client.PublishNumbered(ctx, "seq", time.Hour, channel, struct{}{})
+	assert.Error(t, err, "only objects with fields can be stamped")
+}
+
+func TestIncrWindow(t *testing.T) {
+	logger.InitLogger(&config.Config{})
+	server := miniredis.RunT(t)
+	client, err := NewClient(&config.Config{Redis: config.RedisConfig{Host: server.Host(), Port: server.Port()}})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	key := ReactionRateKey(uuid.New(), uuid.New())
+
+	for want := int64(1); want <= 3; want++ {
+		count, err := client.IncrWindow(ctx, key, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, want, count)
+	}
+	assert.Equal(t, time.Second, server.TTL(key), "later hits do not extend the window")
+
+	server.FastForward(time.Second)
+	count, err := client.IncrWindow(ctx, key, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count, "a new window starts once the last one ended")
+}
+
+func TestReplaceSet(t *testing.T) {
+	logger.InitLogger(&config.Config{})
+	server := miniredis.RunT(t)
+	client, err := NewClient(&config.Config{Redis: config.RedisConfig{Host: server.Host(), Port: server.Port()}})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	key := RoomCoHostsKey(uuid.New())
+
+	require.NoError(t, client.SetAdd(ctx, key, "stale"))
+	require.NoError(t, client.ReplaceSet(ctx, key, time.Hour, "a", "b"))
+	members, err := server.Members(key)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b"}, members)
+	assert.Equal(t, time.Hour, server.TTL(key))
+
+	require.NoError(t, client.ReplaceSet(ctx, key, time.Hour))
+	assert.False(t, server.Exists(key), "an empty set is deleted")
+}
@@ -0,0 +1,101 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RoomInstances returns the live sync instances holding connections for a room, with their connection counts.
+// entries left behind by instances whose heartbeat expired are pruned from the registry.
+func (c *Client) RoomInstances(ctx context.Context, roomID uuid.UUID) (map[string]int, error) {
+	connectionsKey := RoomConnectionsKey(roomID)
+
+	entries, err := c.HGetAll(ctx, connectionsKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room connections: %w", err)
+	}
+
+	instances := make(map[string]int, len(entries))
+	var stale []string
+	for instanceID, value := range entries {
+		alive, err := c.Exists(ctx, SyncInstanceKey(instanceID))
+		if err != nil {
+			return nil, err
+		}
+		if alive == 0 {
+			stale = append(stale, instanceID)
+			continue
+		}
+
+		count, _ := strconv.Atoi(value)
+		instances[instanceID] = count
+	}
+
+	if len(stale) > 0 {
+		// a crashed instance cannot clean up after itself
+		if err := c.HDel(ctx, connectionsKey, stale...); err != nil {
+			return nil, err
+		}
+	}
+
+	return instances, nil
+}
+
+// PublishToRoomInstances sends a control message to every live sync instance serving a room
+// and returns the instances it was delivered to
+func (c *Client) PublishToRoomInstances(ctx context.Context, roomID uuid.UUID, message interface{}) ([]string, error) {
+	instances, err := c.RoomInstances(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivered := make([]string, 0, len(instances))
+	for instanceID := range instances {
+		err := c.Publish(ctx, SyncInstanceControlChannel(instanceID), message)
+		if err != nil {
+			return delivered, err
+		}
+		delivered = append(delivered, instanceID)
+	}
+
+	return delivered, nil
+}
+
+// RoomLiveSnapshot is what service-sync currently holds for a room
+type RoomLiveSnapshot struct {
+	ParticipantCount int
+	State            map[string]string // the room state hash, empty when nobody has played the room yet
+}
+
+// RoomLiveSnapshots reads the participant count and playback state of many rooms in one round trip,
+// in the order of roomIDs
+func (c *Client) RoomLiveSnapshots(ctx context.Context, roomIDs []uuid.UUID) ([]RoomLiveSnapshot, error) {
+	if len(roomIDs) == 0 {
+		return nil, nil
+	}
+
+	pipe := c.client.Pipeline()
+	counts := make([]*redis.IntCmd, len(roomIDs))
+	states := make([]*redis.MapStringStringCmd, len(roomIDs))
+	for i, roomID := range roomIDs {
+		counts[i] = pipe.HLen(ctx, RoomParticipantsKey(roomID))
+		states[i] = pipe.HGetAll(ctx, RoomStateKey(roomID))
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read live room state: %w", err)
+	}
+
+	snapshots := make([]RoomLiveSnapshot, len(roomIDs))
+	for i := range roomIDs {
+		snapshots[i] = RoomLiveSnapshot{
+			ParticipantCount: int(counts[i].Val()),
+			State:            states[i].Val(),
+		}
+	}
+	return snapshots, nil
+}
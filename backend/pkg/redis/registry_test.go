@@ -0,0 +1,36 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoomLiveSnapshots(t *testing.T) {
+	logger.InitLogger(&config.Config{})
+	server := miniredis.RunT(t)
+	client, err := NewClient(&config.Config{Redis: config.RedisConfig{Host: server.Host(), Port: server.Port()}})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	playing, idle := uuid.New(), uuid.New()
+	require.NoError(t, client.HSet(ctx, RoomParticipantsKey(playing), "a", "{}", "b", "{}"))
+	require.NoError(t, client.HSet(ctx, RoomStateKey(playing), "is_playing", "true", "current_time", "42"))
+
+	snapshots, err := client.RoomLiveSnapshots(ctx, []uuid.UUID{playing, idle})
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+
+	assert.Equal(t, 2, snapshots[0].ParticipantCount)
+	assert.Equal(t, "true", snapshots[0].State["is_playing"])
+	assert.Equal(t, 0, snapshots[1].ParticipantCount)
+	assert.Empty(t, snapshots[1].State)
+}
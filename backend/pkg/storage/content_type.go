@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"path/filepath"
+	"strings"
+
+	"watch-party/pkg/config"
+)
+
+// SegmentContentType is the content type of the MPEG-TS segments of HLS output
+const SegmentContentType = "video/mp2t"
+
+// ContentTypeFor returns the MIME type of a file from its extension, playlists get the configured playlist content
+// type (StreamingConfig.EffectivePlaylistContentType) so an object is stored with the content type the streaming
+// routes serve and sign it with. an empty playlist content type uses the default
+func ContentTypeFor(path, playlistContentType string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".m3u8":
+		if playlistContentType == "" {
+			return config.DefaultPlaylistContentType
+		}
+		return playlistContentType
+	case ".ts":
+		return SegmentContentType
+	case ".mp4":
+		return "video/mp4"
+	case ".webm":
+		return "video/webm"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	case ".vtt":
+		return "text/vtt"
+	default:
+		return "application/octet-stream"
+	}
+}
@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"testing"
+
+	"watch-party/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentTypeFor(t *testing.T) {
+	for _, playlistType := range []string{config.PlaylistContentTypeApple, config.PlaylistContentTypeLegacy} {
+		// what an object is uploaded with must be what the streaming routes serve and sign it with
+		assert.Equal(t, playlistType, ContentTypeFor("hls/movie/master.m3u8", playlistType))
+		assert.Equal(t, playlistType, ContentTypeFor("hls/movie/720p/PLAYLIST.M3U8", playlistType))
+	}
+	assert.Equal(t, config.DefaultPlaylistContentType, ContentTypeFor("hls/movie/master.m3u8", ""))
+
+	assert.Equal(t, SegmentContentType, ContentTypeFor("hls/movie/720p/segment_000.ts", ""))
+	assert.Equal(t, "text/vtt", ContentTypeFor("subtitles/movie/en.vtt", ""))
+	assert.Equal(t, "application/octet-stream", ContentTypeFor("uploads/movie", ""))
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+)
+
+const (
+	testBucket        = "watch-party"
+	testExistingPath  = "hls/movie/master.m3u8"
+	testForbiddenPath = "hls/movie/forbidden.m3u8"
+)
+
+// existsCases are checked against every provider
+var existsCases = []struct {
+	name    string
+	path    string
+	want    bool
+	wantErr bool
+}{
+	{name: "found", path: testExistingPath, want: true},
+	{name: "not found", path: "hls/movie/missing.m3u8", want: false},
+	{name: "storage error", path: testForbiddenPath, wantErr: true},
+}
+
+func TestMinIOProviderExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodHead, r.Method)
+
+		switch r.URL.Path {
+		case "/" + testBucket + "/" + testExistingPath:
+			w.Header().Set("Content-Length", "42")
+			w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+			w.Header().Set("ETag", `"etag"`)
+			w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+			w.WriteHeader(http.StatusOK)
+		case "/" + testBucket + "/" + testForbiddenPath:
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := minio.New(strings.TrimPrefix(server.URL, "http://"), &minio.Options{
+		Creds:  credentials.NewStaticV4("access", "secret", ""),
+		Region: "us-east-1", // skips the bucket location lookup
+	})
+	require.NoError(t, err)
+	provider := &minioProvider{client: client, bucket: testBucket}
+
+	for _, tt := range existsCases {
+		t.Run(tt.name, func(t *testing.T) {
+			exists, err := provider.Exists(context.Background(), tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, exists)
+		})
+	}
+}
+
+func TestGCSProviderExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/storage/v1/b/" + testBucket + "/o/" + testExistingPath:
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"bucket":      testBucket,
+				"name":        testExistingPath,
+				"size":        "42",
+				"contentType": "application/vnd.apple.mpegurl",
+			})
+		case "/storage/v1/b/" + testBucket + "/o/" + testForbiddenPath:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":{"code":403,"message":"forbidden"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"message":"not found"}}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := storage.NewClient(context.Background(),
+		option.WithEndpoint(server.URL+"/storage/v1/"),
+		option.WithoutAuthentication(),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+	provider := &GCSProvider{client: client, bucket: testBucket}
+
+	for _, tt := range existsCases {
+		t.Run(tt.name, func(t *testing.T) {
+			exists, err := provider.Exists(context.Background(), tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, exists)
+		})
+	}
+}
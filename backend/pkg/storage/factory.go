@@ -12,14 +12,14 @@ const (
 	StorageProviderMinIO = "minio"
 )
 
-// NewStorageProvider creates a storage provider based on configuration
-func NewStorageProvider(ctx context.Context, cfg *config.StorageConfig) (Provider, error) {
+// NewStorageProvider creates a storage provider based on configuration, playlists are uploaded with playlistContentType
+func NewStorageProvider(ctx context.Context, cfg *config.StorageConfig, playlistContentType string) (Provider, error) {
 	switch cfg.Provider {
 	case StorageProviderGCS:
 		if cfg.GCSBucket == "" {
 			return nil, fmt.Errorf("GCS bucket name is required")
 		}
-		return NewGCSProvider(ctx, cfg)
+		return NewGCSProvider(ctx, cfg, playlistContentType)
 
 	case StorageProviderMinIO:
 		if cfg.MinIO.Endpoint == "" {
@@ -35,6 +35,7 @@ func NewStorageProvider(ctx context.Context, cfg *config.StorageConfig) (Provide
 			cfg.MinIO.Bucket,
 			cfg.MinIO.UseSSL,
 			cfg.MinIO.PublicEndpoint,
+			playlistContentType,
 		)
 
 	}
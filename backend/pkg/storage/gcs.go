@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -14,6 +15,7 @@ import (
 	"watch-party/pkg/config"
 
 	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
 )
 
 // GCSProvider implements storage for Google Cloud Storage
@@ -22,10 +24,11 @@ type GCSProvider struct {
 	bucket           string
 	serviceAccountID string // service account email for signing URLs
 	privateKey       []byte // private key for signing URLs, if needed
+	playlistType     string // content type playlists are uploaded with
 }
 
 // NewGCSProvider creates a new GCS storage provider
-func NewGCSProvider(ctx context.Context, cfg *config.StorageConfig) (*GCSProvider, error) {
+func NewGCSProvider(ctx context.Context, cfg *config.StorageConfig, playlistContentType string) (*GCSProvider, error) {
 	client, err := storage.NewClient(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GCS client: %w", err)
@@ -41,6 +44,7 @@ func NewGCSProvider(ctx context.Context, cfg *config.StorageConfig) (*GCSProvide
 		bucket:           cfg.GCSBucket,
 		serviceAccountID: cfg.GCSServiceAccountID,
 		privateKey:       privateKeyPEM,
+		playlistType:     playlistContentType,
 	}, nil
 }
 
@@ -60,7 +64,7 @@ func (g *GCSProvider) Upload(ctx context.Context, file *multipart.FileHeader, fi
 	writer := obj.NewWriter(ctx)
 	writer.ContentType = file.Header.Get("Content-Type")
 	if writer.ContentType == "" {
-		writer.ContentType = getContentType(filename)
+		writer.ContentType = ContentTypeFor(filename, g.playlistType)
 	}
 
 	// copy the file to GCS
@@ -93,7 +97,7 @@ func (g *GCSProvider) UploadFromPath(ctx context.Context, localPath, storagePath
 
 	// Create a writer to the GCS object
 	writer := obj.NewWriter(ctx)
-	writer.ContentType = getContentType(localPath)
+	writer.ContentType = ContentTypeFor(localPath, g.playlistType)
 
 	// Copy the file to GCS
 	_, err = io.Copy(writer, file)
@@ -129,6 +133,9 @@ func (g *GCSProvider) GetSignedURL(ctx context.Context, path string) (string, er
 	return url, nil
 }
 
+// gcsContentLengthRangeHeader makes GCS reject an upload whose size is outside "min,max"
+const gcsContentLengthRangeHeader = "x-goog-content-length-range"
+
 // GenerateSignedUploadURL generates a signed URL for uploading to GCS
 func (g *GCSProvider) GenerateSignedUploadURL(ctx context.Context, filename string, opts *UploadOptions) (*SignedURL, error) {
 	if opts == nil {
@@ -154,9 +161,16 @@ func (g *GCSProvider) GenerateSignedUploadURL(ctx context.Context, filename stri
 		PrivateKey:     g.privateKey,
 	}
 
-	// Set content type if provided
+	// signed headers must be sent as they are, GCS rejects an upload with another content type or a size out of range
+	headers := make(map[string]string)
 	if opts.ContentType != "" {
 		opts2.ContentType = opts.ContentType
+		headers["Content-Type"] = opts.ContentType
+	}
+	if opts.MaxFileSize > 0 {
+		lengthRange := fmt.Sprintf("1,%d", opts.MaxFileSize)
+		opts2.Headers = append(opts2.Headers, gcsContentLengthRangeHeader+":"+lengthRange)
+		headers[gcsContentLengthRangeHeader] = lengthRange
 	}
 
 	url, err := bucket.SignedURL(filename, opts2)
@@ -164,11 +178,6 @@ func (g *GCSProvider) GenerateSignedUploadURL(ctx context.Context, filename stri
 		return nil, fmt.Errorf("failed to generate GCS signed URL: %w", err)
 	}
 
-	headers := make(map[string]string)
-	if opts.ContentType != "" {
-		headers["Content-Type"] = opts.ContentType
-	}
-
 	return &SignedURL{
 		URL:       url,
 		Method:    http.MethodPut,
@@ -194,6 +203,31 @@ func (g *GCSProvider) Delete(ctx context.Context, path string) error {
 	return nil
 }
 
+// DeletePrefix deletes every object under prefix and returns how many were deleted
+func (g *GCSProvider) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	if prefix == "" {
+		return 0, ErrEmptyPrefix
+	}
+
+	names, err := g.ListObjects(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	var errs []error
+	for _, name := range names {
+		err := g.client.Bucket(g.bucket).Object(name).Delete(ctx)
+		if err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+			errs = append(errs, fmt.Errorf("failed to delete %s: %w", name, err))
+			continue
+		}
+		deleted++
+	}
+
+	return deleted, errors.Join(errs...)
+}
+
 // ListObjects lists objects with a given prefix in GCS
 func (g *GCSProvider) ListObjects(ctx context.Context, prefix string) ([]string, error) {
 	var objects []string
@@ -204,7 +238,7 @@ func (g *GCSProvider) ListObjects(ctx context.Context, prefix string) ([]string,
 
 	for {
 		attrs, err := it.Next()
-		if err == storage.ErrObjectNotExist {
+		if errors.Is(err, iterator.Done) {
 			break
 		}
 		if err != nil {
@@ -237,6 +271,18 @@ func (g *GCSProvider) GetFileInfo(ctx context.Context, path string) (*FileInfo,
 	}, nil
 }
 
+// Exists reports whether an object is stored at path, a missing object is not an error
+func (g *GCSProvider) Exists(ctx context.Context, path string) (bool, error) {
+	_, err := g.client.Bucket(g.bucket).Object(path).Attrs(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get object attributes: %w", err)
+	}
+	return true, nil
+}
+
 // Download downloads a file from GCS to local filesystem
 func (g *GCSProvider) Download(ctx context.Context, storagePath, localPath string) error {
 	// Get object from GCS
@@ -263,28 +309,20 @@ func (g *GCSProvider) Download(ctx context.Context, storagePath, localPath strin
 	return nil
 }
 
+// DownloadRange reads part of an object from GCS
+func (g *GCSProvider) DownloadRange(ctx context.Context, storagePath string, offset, length int64) (io.ReadCloser, error) {
+	reader, err := g.client.Bucket(g.bucket).Object(storagePath).NewRangeReader(ctx, offset, length)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get range reader from GCS: %w", err)
+	}
+	return reader, nil
+}
+
 // Close closes the GCS client
 func (g *GCSProvider) Close() error {
 	return g.client.Close()
 }
 
-// getContentType returns the MIME type based on file name
-func getContentType(filename string) string {
-	if strings.HasSuffix(filename, ".m3u8") {
-		return "application/x-mpegURL"
-	}
-	if strings.HasSuffix(filename, ".ts") {
-		return "video/MP2T"
-	}
-	if strings.HasSuffix(filename, ".mp4") {
-		return "video/mp4"
-	}
-	if strings.HasSuffix(filename, ".webm") {
-		return "video/webm"
-	}
-	return "application/octet-stream"
-}
-
 // GenerateCDNSignedURL generates a CDN-friendly signed URL with custom options
 func (g *GCSProvider) GenerateCDNSignedURL(ctx context.Context, path string, opts *CDNSignedURLOptions) (string, error) {
 	// TODO: real CDN support
@@ -2,6 +2,8 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"io"
 	"mime/multipart"
 	"time"
 )
@@ -14,13 +16,40 @@ type Provider interface {
 	GetSignedURL(ctx context.Context, path string) (string, error)
 	Download(ctx context.Context, storagePath, localPath string) error
 	Delete(ctx context.Context, path string) error
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
 	GetFileInfo(ctx context.Context, path string) (*FileInfo, error)
+	Exists(ctx context.Context, path string) (bool, error)
 	GetPublicURL(ctx context.Context, path string) (string, error)
 	ListObjects(ctx context.Context, prefix string) ([]string, error)
 	GenerateSignedURLs(ctx context.Context, paths []string, opts *CDNSignedURLOptions) (map[string]string, error)
 	GenerateCDNSignedURL(ctx context.Context, path string, opts *CDNSignedURLOptions) (string, error)
 }
 
+// ErrEmptyPrefix is returned by DeletePrefix instead of emptying the whole bucket
+var ErrEmptyPrefix = errors.New("refusing to delete an empty prefix")
+
+// UploadsPrefix is the storage prefix original uploads are written under
+const UploadsPrefix = "uploads/"
+
+// ObjectEvent describes an object that was written to storage
+type ObjectEvent struct {
+	Path        string
+	Size        int64
+	ContentType string
+}
+
+// UploadNotifier is implemented by providers that can push object-created events
+type UploadNotifier interface {
+	// ListenForObjects sends an event for every object created under prefix until ctx is done or the stream fails
+	ListenForObjects(ctx context.Context, prefix string, events chan<- ObjectEvent) error
+}
+
+// RangeDownloader is implemented by providers that can read part of an object
+type RangeDownloader interface {
+	// DownloadRange reads length bytes of the object starting at offset
+	DownloadRange(ctx context.Context, storagePath string, offset, length int64) (io.ReadCloser, error)
+}
+
 // SignedURL represents a signed URL for upload
 type SignedURL struct {
 	URL        string            `json:"url"`
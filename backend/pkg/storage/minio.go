@@ -2,11 +2,13 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
+	"net/url"
 	"os"
-	"strings"
 	"time"
 	"watch-party/pkg/logger"
 
@@ -22,10 +24,11 @@ type minioProvider struct {
 	publicClient   *minio.Client // Client configured with public endpoint for signing URLs
 	publicEndpoint string        // Public endpoint for generating URLs accessible from browser
 	useSSL         bool
+	playlistType   string // content type playlists are uploaded with
 }
 
 // NewMinIOProvider creates a new MinIO storage provider
-func NewMinIOProvider(endpoint, accessKey, secretKey, bucket string, useSSL bool, publicEndpoint string) (Provider, error) {
+func NewMinIOProvider(endpoint, accessKey, secretKey, bucket string, useSSL bool, publicEndpoint, playlistContentType string) (Provider, error) {
 	logger.Info(fmt.Sprintf("Creating MinIO provider with endpoint: %s, publicEndpoint: %s, useSSL: %v", endpoint, publicEndpoint, useSSL))
 
 	// If publicEndpoint is empty, use the same as endpoint
@@ -68,6 +71,7 @@ func NewMinIOProvider(endpoint, accessKey, secretKey, bucket string, useSSL bool
 		publicClient:   publicClient,
 		publicEndpoint: publicEndpoint,
 		useSSL:         useSSL,
+		playlistType:   playlistContentType,
 	}
 
 	logger.Info(fmt.Sprintf("MinIO provider created, checking bucket: %s", bucket))
@@ -129,7 +133,8 @@ func (m *minioProvider) Upload(ctx context.Context, file *multipart.FileHeader,
 	return filename, nil
 }
 
-// GenerateSignedUploadURL generates a presigned URL for uploading
+// GenerateSignedUploadURL generates a presigned POST policy for uploading. unlike a presigned PUT, the policy
+// makes MinIO reject an upload whose content type or size differs from opts
 func (m *minioProvider) GenerateSignedUploadURL(ctx context.Context, filename string, opts *UploadOptions) (*SignedURL, error) {
 	if opts == nil {
 		opts = &UploadOptions{
@@ -141,22 +146,45 @@ func (m *minioProvider) GenerateSignedUploadURL(ctx context.Context, filename st
 	if opts.ExpiresIn == 0 {
 		opts.ExpiresIn = time.Hour
 	}
+	expiresAt := time.Now().Add(opts.ExpiresIn)
 
-	presignedURL, err := m.publicClient.PresignedPutObject(ctx, m.bucket, filename, opts.ExpiresIn)
+	policy := minio.NewPostPolicy()
+	err := policy.SetBucket(m.bucket)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate presigned URL: %w", err)
+		return nil, fmt.Errorf("failed to set upload policy bucket: %w", err)
+	}
+	err = policy.SetKey(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set upload policy key: %w", err)
+	}
+	err = policy.SetExpires(expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set upload policy expiry: %w", err)
 	}
-
-	headers := make(map[string]string)
 	if opts.ContentType != "" {
-		headers["Content-Type"] = opts.ContentType
+		err = policy.SetContentType(opts.ContentType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set upload policy content type: %w", err)
+		}
+	}
+	if opts.MaxFileSize > 0 {
+		err = policy.SetContentLengthRange(1, opts.MaxFileSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set upload policy size: %w", err)
+		}
+	}
+
+	presignedURL, formData, err := m.publicClient.PresignedPostPolicy(ctx, policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate presigned upload policy: %w", err)
 	}
 
 	return &SignedURL{
-		URL:       presignedURL.String(),
-		Method:    "PUT",
-		Headers:   headers,
-		ExpiresAt: time.Now().Add(opts.ExpiresIn),
+		URL:        presignedURL.String(),
+		Method:     http.MethodPost,
+		Headers:    map[string]string{},
+		FormFields: formData,
+		ExpiresAt:  expiresAt,
 	}, nil
 }
 
@@ -180,6 +208,31 @@ func (m *minioProvider) Delete(ctx context.Context, path string) error {
 	return nil
 }
 
+// DeletePrefix deletes every object under prefix in batches and returns how many were deleted
+func (m *minioProvider) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	if prefix == "" {
+		return 0, ErrEmptyPrefix
+	}
+
+	keys, err := m.ListObjects(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	objectsCh := make(chan minio.ObjectInfo, len(keys))
+	for _, key := range keys {
+		objectsCh <- minio.ObjectInfo{Key: key}
+	}
+	close(objectsCh)
+
+	var errs []error
+	for removeErr := range m.client.RemoveObjects(ctx, m.bucket, objectsCh, minio.RemoveObjectsOptions{}) {
+		errs = append(errs, fmt.Errorf("failed to delete %s: %w", removeErr.ObjectName, removeErr.Err))
+	}
+
+	return len(keys) - len(errs), errors.Join(errs...)
+}
+
 // GetFileInfo returns information about a file
 func (m *minioProvider) GetFileInfo(ctx context.Context, path string) (*FileInfo, error) {
 	stat, err := m.client.StatObject(ctx, m.bucket, path, minio.StatObjectOptions{})
@@ -202,6 +255,48 @@ func (m *minioProvider) GetFileInfo(ctx context.Context, path string) (*FileInfo
 	}, nil
 }
 
+// Exists reports whether an object is stored at path, a missing object is not an error
+func (m *minioProvider) Exists(ctx context.Context, path string) (bool, error) {
+	_, err := m.client.StatObject(ctx, m.bucket, path, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check object: %w", err)
+	}
+	return true, nil
+}
+
+// ListenForObjects streams MinIO bucket notifications for objects created under prefix
+func (m *minioProvider) ListenForObjects(ctx context.Context, prefix string, events chan<- ObjectEvent) error {
+	notifications := m.client.ListenBucketNotification(ctx, m.bucket, prefix, "", []string{"s3:ObjectCreated:*"})
+
+	for info := range notifications {
+		if info.Err != nil {
+			return fmt.Errorf("bucket notification stream failed: %w", info.Err)
+		}
+
+		for _, record := range info.Records {
+			// object keys arrive URL-encoded
+			key, err := url.QueryUnescape(record.S3.Object.Key)
+			if err != nil {
+				key = record.S3.Object.Key
+			}
+
+			select {
+			case events <- ObjectEvent{Path: key, Size: record.S3.Object.Size, ContentType: record.S3.Object.ContentType}:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	return fmt.Errorf("bucket notification stream closed")
+}
+
 // GetPublicURL returns a public URL for the file (for HLS playlists)
 func (m *minioProvider) GetPublicURL(ctx context.Context, path string) (string, error) {
 	// for MinIO, we'll use the direct endpoint URL
@@ -238,7 +333,7 @@ func (m *minioProvider) ListObjects(ctx context.Context, prefix string) ([]strin
 // UploadFile uploads a file from a local path (helper method for transcoded files)
 func (m *minioProvider) UploadFile(ctx context.Context, localPath, remotePath string, contentType string) error {
 	if contentType == "" {
-		contentType = getContentTypeFromPath(remotePath)
+		contentType = ContentTypeFor(remotePath, m.playlistType)
 	}
 
 	_, err := m.client.FPutObject(ctx, m.bucket, remotePath, localPath, minio.PutObjectOptions{
@@ -254,7 +349,7 @@ func (m *minioProvider) UploadFile(ctx context.Context, localPath, remotePath st
 // UploadReader uploads content from an io.Reader (helper method)
 func (m *minioProvider) UploadReader(ctx context.Context, reader io.Reader, remotePath string, size int64, contentType string) error {
 	if contentType == "" {
-		contentType = getContentTypeFromPath(remotePath)
+		contentType = ContentTypeFor(remotePath, m.playlistType)
 	}
 
 	_, err := m.client.PutObject(ctx, m.bucket, remotePath, reader, size, minio.PutObjectOptions{
@@ -292,6 +387,21 @@ func (m *minioProvider) Download(ctx context.Context, storagePath, localPath str
 	return nil
 }
 
+// DownloadRange reads part of an object from MinIO
+func (m *minioProvider) DownloadRange(ctx context.Context, storagePath string, offset, length int64) (io.ReadCloser, error) {
+	opts := minio.GetObjectOptions{}
+	err := opts.SetRange(offset, offset+length-1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid range: %w", err)
+	}
+
+	obj, err := m.client.GetObject(ctx, m.bucket, storagePath, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object from MinIO: %w", err)
+	}
+	return obj, nil
+}
+
 // UploadFromPath uploads a file from local filesystem to MinIO
 func (m *minioProvider) UploadFromPath(ctx context.Context, localPath, storagePath string) error {
 	// Open the local file
@@ -308,7 +418,7 @@ func (m *minioProvider) UploadFromPath(ctx context.Context, localPath, storagePa
 	}
 
 	// Determine content type based on file extension
-	contentType := getContentTypeFromPath(localPath)
+	contentType := ContentTypeFor(localPath, m.playlistType)
 
 	// Upload file to MinIO
 	_, err = m.client.PutObject(ctx, m.bucket, storagePath, file, fileInfo.Size(), minio.PutObjectOptions{
@@ -321,23 +431,6 @@ func (m *minioProvider) UploadFromPath(ctx context.Context, localPath, storagePa
 	return nil
 }
 
-// getContentTypeFromPath returns the MIME type based on file extension
-func getContentTypeFromPath(path string) string {
-	if strings.HasSuffix(path, ".m3u8") {
-		return "application/x-mpegURL"
-	}
-	if strings.HasSuffix(path, ".ts") {
-		return "video/MP2T"
-	}
-	if strings.HasSuffix(path, ".mp4") {
-		return "video/mp4"
-	}
-	if strings.HasSuffix(path, ".webm") {
-		return "video/webm"
-	}
-	return "application/octet-stream"
-}
-
 // GenerateCDNSignedURL generates a CDN-friendly signed URL with custom options
 func (m *minioProvider) GenerateCDNSignedURL(ctx context.Context, path string, opts *CDNSignedURLOptions) (string, error) {
 	if opts == nil {
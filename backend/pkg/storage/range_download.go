@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// ParallelDownloadOptions controls how large objects are fetched in ranges
+type ParallelDownloadOptions struct {
+	Concurrency int   // ranges fetched at the same time, 1 or less downloads serially
+	ChunkSize   int64 // bytes per range, objects no larger than one range are downloaded serially
+}
+
+// DownloadParallel downloads an object to a local file, fetching ranges of it concurrently when the provider
+// supports ranged reads. it falls back to the provider's serial Download otherwise.
+func DownloadParallel(ctx context.Context, provider Provider, storagePath, localPath string, opts ParallelDownloadOptions) error {
+	rangeDownloader, ok := provider.(RangeDownloader)
+	if !ok || opts.Concurrency <= 1 || opts.ChunkSize <= 0 {
+		return provider.Download(ctx, storagePath, localPath)
+	}
+
+	info, err := provider.GetFileInfo(ctx, storagePath)
+	if err != nil {
+		return fmt.Errorf("failed to get file info: %w", err)
+	}
+	if info.Size <= opts.ChunkSize {
+		return provider.Download(ctx, storagePath, localPath)
+	}
+
+	localFile, err := os.Create(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %w", err)
+	}
+	defer localFile.Close()
+
+	// sizing the file up front lets every range write at its own offset
+	err = localFile.Truncate(info.Size)
+	if err != nil {
+		return fmt.Errorf("failed to size local file: %w", err)
+	}
+
+	// the first failed range cancels the others
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	semaphore := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+
+	for offset := int64(0); offset < info.Size; offset += opts.ChunkSize {
+		length := min(opts.ChunkSize, info.Size-offset)
+
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(offset, length int64) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			err := downloadRange(ctx, rangeDownloader, storagePath, localFile, offset, length)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(offset, length)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	err = localFile.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write local file: %w", err)
+	}
+	return nil
+}
+
+// downloadRange copies one range of an object into the local file at the same offset
+func downloadRange(ctx context.Context, rangeDownloader RangeDownloader, storagePath string, localFile *os.File, offset, length int64) error {
+	reader, err := rangeDownloader.DownloadRange(ctx, storagePath, offset, length)
+	if err != nil {
+		return fmt.Errorf("failed to download range at %d: %w", offset, err)
+	}
+	defer reader.Close()
+
+	written, err := io.Copy(io.NewOffsetWriter(localFile, offset), io.LimitReader(reader, length))
+	if err != nil {
+		return fmt.Errorf("failed to copy range at %d: %w", offset, err)
+	}
+	if written != length {
+		return fmt.Errorf("range at %d ended after %d of %d bytes", offset, written, length)
+	}
+	return nil
+}
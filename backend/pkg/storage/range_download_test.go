@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryProvider serves one object from memory, the embedded Provider is nil and only covers the interface
+type memoryProvider struct {
+	Provider
+	data           []byte
+	serialCalls    atomic.Int32
+	rangeCalls     atomic.Int32
+	failRangeStart int64
+}
+
+func (p *memoryProvider) GetFileInfo(ctx context.Context, path string) (*FileInfo, error) {
+	return &FileInfo{Name: path, Size: int64(len(p.data))}, nil
+}
+
+func (p *memoryProvider) Download(ctx context.Context, storagePath, localPath string) error {
+	p.serialCalls.Add(1)
+	return os.WriteFile(localPath, p.data, 0644)
+}
+
+// rangeProvider is a memoryProvider that also supports ranged reads
+type rangeProvider struct {
+	*memoryProvider
+}
+
+func (p rangeProvider) DownloadRange(ctx context.Context, storagePath string, offset, length int64) (io.ReadCloser, error) {
+	p.rangeCalls.Add(1)
+	if p.failRangeStart > 0 && offset == p.failRangeStart {
+		return nil, errors.New("range failed")
+	}
+	return io.NopCloser(bytes.NewReader(p.data[offset : offset+length])), nil
+}
+
+func testObject(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+func TestDownloadParallel(t *testing.T) {
+	opts := ParallelDownloadOptions{Concurrency: 3, ChunkSize: 100}
+
+	t.Run("fetches ranges", func(t *testing.T) {
+		provider := rangeProvider{&memoryProvider{data: testObject(1050)}}
+		localPath := filepath.Join(t.TempDir(), "source.mp4")
+
+		err := DownloadParallel(context.Background(), provider, "uploads/source.mp4", localPath, opts)
+		require.NoError(t, err)
+
+		got, err := os.ReadFile(localPath)
+		require.NoError(t, err)
+		assert.Equal(t, provider.data, got)
+		assert.Equal(t, int32(11), provider.rangeCalls.Load())
+		assert.Zero(t, provider.serialCalls.Load())
+	})
+
+	t.Run("serial without range support", func(t *testing.T) {
+		provider := &memoryProvider{data: testObject(1050)}
+		localPath := filepath.Join(t.TempDir(), "source.mp4")
+
+		err := DownloadParallel(context.Background(), provider, "uploads/source.mp4", localPath, opts)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), provider.serialCalls.Load())
+	})
+
+	t.Run("serial for small objects", func(t *testing.T) {
+		provider := rangeProvider{&memoryProvider{data: testObject(80)}}
+		localPath := filepath.Join(t.TempDir(), "source.mp4")
+
+		err := DownloadParallel(context.Background(), provider, "uploads/source.mp4", localPath, opts)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), provider.serialCalls.Load())
+		assert.Zero(t, provider.rangeCalls.Load())
+	})
+
+	t.Run("failed range fails the download", func(t *testing.T) {
+		provider := rangeProvider{&memoryProvider{data: testObject(1050), failRangeStart: 500}}
+		localPath := filepath.Join(t.TempDir(), "source.mp4")
+
+		err := DownloadParallel(context.Background(), provider, "uploads/source.mp4", localPath, opts)
+		assert.ErrorContains(t, err, "range failed")
+	})
+}
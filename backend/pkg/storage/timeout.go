@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+	"time"
+)
+
+// timeoutProvider bounds every call of the wrapped provider with a deadline, so a stalled storage backend fails
+// the call instead of holding the request or worker that made it
+type timeoutProvider struct {
+	provider  Provider
+	operation time.Duration // calls that do not move file contents
+	transfer  time.Duration // whole-file uploads and downloads
+}
+
+// WithTimeouts wraps provider so calls that move file contents get the transfer deadline and every other call the
+// operation deadline. a caller's own earlier deadline still wins. ranged reads and upload notifications stay
+// available when provider supports them.
+func WithTimeouts(provider Provider, operation, transfer time.Duration) Provider {
+	t := &timeoutProvider{provider: provider, operation: operation, transfer: transfer}
+
+	rangeDownloader, ranged := provider.(RangeDownloader)
+	notifier, notifies := provider.(UploadNotifier)
+	switch {
+	case ranged && notifies:
+		return struct {
+			*timeoutProvider
+			RangeDownloader
+			UploadNotifier
+		}{t, timeoutRangeDownloader{rangeDownloader, transfer}, notifier}
+	case ranged:
+		return struct {
+			*timeoutProvider
+			RangeDownloader
+		}{t, timeoutRangeDownloader{rangeDownloader, transfer}}
+	case notifies:
+		// notifications are a long-lived stream, they end with the caller's context
+		return struct {
+			*timeoutProvider
+			UploadNotifier
+		}{t, notifier}
+	}
+	return t
+}
+
+func (t *timeoutProvider) Upload(ctx context.Context, file *multipart.FileHeader, filename string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.transfer)
+	defer cancel()
+	return t.provider.Upload(ctx, file, filename)
+}
+
+func (t *timeoutProvider) UploadFromPath(ctx context.Context, localPath, storagePath string) error {
+	ctx, cancel := context.WithTimeout(ctx, t.transfer)
+	defer cancel()
+	return t.provider.UploadFromPath(ctx, localPath, storagePath)
+}
+
+func (t *timeoutProvider) GenerateSignedUploadURL(ctx context.Context, filename string, opts *UploadOptions) (*SignedURL, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.operation)
+	defer cancel()
+	return t.provider.GenerateSignedUploadURL(ctx, filename, opts)
+}
+
+func (t *timeoutProvider) GetSignedURL(ctx context.Context, path string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.operation)
+	defer cancel()
+	return t.provider.GetSignedURL(ctx, path)
+}
+
+func (t *timeoutProvider) Download(ctx context.Context, storagePath, localPath string) error {
+	ctx, cancel := context.WithTimeout(ctx, t.transfer)
+	defer cancel()
+	return t.provider.Download(ctx, storagePath, localPath)
+}
+
+func (t *timeoutProvider) Delete(ctx context.Context, path string) error {
+	ctx, cancel := context.WithTimeout(ctx, t.operation)
+	defer cancel()
+	return t.provider.Delete(ctx, path)
+}
+
+// DeletePrefix gets the transfer deadline, a movie's renditions can be thousands of objects
+func (t *timeoutProvider) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.transfer)
+	defer cancel()
+	return t.provider.DeletePrefix(ctx, prefix)
+}
+
+func (t *timeoutProvider) GetFileInfo(ctx context.Context, path string) (*FileInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.operation)
+	defer cancel()
+	return t.provider.GetFileInfo(ctx, path)
+}
+
+func (t *timeoutProvider) Exists(ctx context.Context, path string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.operation)
+	defer cancel()
+	return t.provider.Exists(ctx, path)
+}
+
+func (t *timeoutProvider) GetPublicURL(ctx context.Context, path string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.operation)
+	defer cancel()
+	return t.provider.GetPublicURL(ctx, path)
+}
+
+func (t *timeoutProvider) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.operation)
+	defer cancel()
+	return t.provider.ListObjects(ctx, prefix)
+}
+
+func (t *timeoutProvider) GenerateSignedURLs(ctx context.Context, paths []string, opts *CDNSignedURLOptions) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.operation)
+	defer cancel()
+	return t.provider.GenerateSignedURLs(ctx, paths, opts)
+}
+
+func (t *timeoutProvider) GenerateCDNSignedURL(ctx context.Context, path string, opts *CDNSignedURLOptions) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.operation)
+	defer cancel()
+	return t.provider.GenerateCDNSignedURL(ctx, path, opts)
+}
+
+// timeoutRangeDownloader bounds a ranged read, from opening it until the returned reader is closed
+type timeoutRangeDownloader struct {
+	rangeDownloader RangeDownloader
+	transfer        time.Duration
+}
+
+func (t timeoutRangeDownloader) DownloadRange(ctx context.Context, storagePath string, offset, length int64) (io.ReadCloser, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.transfer)
+	reader, err := t.rangeDownloader.DownloadRange(ctx, storagePath, offset, length)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &cancelOnClose{ReadCloser: reader, cancel: cancel}, nil
+}
+
+// cancelOnClose releases a read's deadline once the reader is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
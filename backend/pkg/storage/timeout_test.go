@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deadlineProvider records the deadline each call was made with, the embedded Provider is nil and only covers
+// the interface
+type deadlineProvider struct {
+	Provider
+	deadline time.Time
+}
+
+func (p *deadlineProvider) Exists(ctx context.Context, path string) (bool, error) {
+	p.deadline, _ = ctx.Deadline()
+	return true, nil
+}
+
+func (p *deadlineProvider) Download(ctx context.Context, storagePath, localPath string) error {
+	p.deadline, _ = ctx.Deadline()
+	return nil
+}
+
+// rangeDeadlineProvider is a deadlineProvider that also supports ranged reads
+type rangeDeadlineProvider struct {
+	*deadlineProvider
+	ctx context.Context
+}
+
+func (p *rangeDeadlineProvider) DownloadRange(ctx context.Context, storagePath string, offset, length int64) (io.ReadCloser, error) {
+	p.ctx = ctx
+	return io.NopCloser(nil), nil
+}
+
+func TestWithTimeouts(t *testing.T) {
+	t.Run("applies the deadline of the call kind", func(t *testing.T) {
+		inner := &deadlineProvider{}
+		provider := WithTimeouts(inner, time.Second, time.Hour)
+
+		_, err := provider.Exists(context.Background(), "movies/a.m3u8")
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(time.Second), inner.deadline, 100*time.Millisecond)
+
+		require.NoError(t, provider.Download(context.Background(), "uploads/a.mp4", "/tmp/a.mp4"))
+		assert.WithinDuration(t, time.Now().Add(time.Hour), inner.deadline, 100*time.Millisecond)
+	})
+
+	t.Run("keeps an earlier caller deadline", func(t *testing.T) {
+		inner := &deadlineProvider{}
+		provider := WithTimeouts(inner, time.Hour, time.Hour)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err := provider.Exists(ctx, "movies/a.m3u8")
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(time.Second), inner.deadline, 100*time.Millisecond)
+	})
+
+	t.Run("keeps optional interfaces", func(t *testing.T) {
+		_, ranged := WithTimeouts(&deadlineProvider{}, time.Second, time.Second).(RangeDownloader)
+		assert.False(t, ranged)
+
+		inner := &rangeDeadlineProvider{deadlineProvider: &deadlineProvider{}}
+		rangeDownloader, ranged := WithTimeouts(inner, time.Second, time.Second).(RangeDownloader)
+		require.True(t, ranged)
+
+		reader, err := rangeDownloader.DownloadRange(context.Background(), "uploads/a.mp4", 0, 10)
+		require.NoError(t, err)
+		assert.NoError(t, inner.ctx.Err(), "the read is cancelled before it is closed")
+		require.NoError(t, reader.Close())
+		assert.ErrorIs(t, inner.ctx.Err(), context.Canceled)
+	})
+}
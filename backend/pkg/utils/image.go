@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+)
+
+// ResizeToFit scales img down so neither side exceeds maxDimension, keeping the aspect ratio.
+// images that already fit are returned unchanged. each output pixel averages the source pixels it covers,
+// which keeps downscaled photos smooth without an external imaging library.
+func ResizeToFit(img image.Image, maxDimension int) image.Image {
+	bounds := img.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	if maxDimension <= 0 || (srcWidth <= maxDimension && srcHeight <= maxDimension) {
+		return img
+	}
+
+	dstWidth, dstHeight := maxDimension, maxDimension
+	if srcWidth > srcHeight {
+		dstHeight = max(1, srcHeight*maxDimension/srcWidth)
+	} else {
+		dstWidth = max(1, srcWidth*maxDimension/srcHeight)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		y0 := bounds.Min.Y + y*srcHeight/dstHeight
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcHeight/dstHeight)
+
+		for x := 0; x < dstWidth; x++ {
+			x0 := bounds.Min.X + x*srcWidth/dstWidth
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcWidth/dstWidth)
+
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pixel := color.NRGBAModel.Convert(img.At(sx, sy)).(color.NRGBA)
+					r += uint64(pixel.R)
+					g += uint64(pixel.G)
+					b += uint64(pixel.B)
+					a += uint64(pixel.A)
+					count++
+				}
+			}
+
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r / count),
+				G: uint8(g / count),
+				B: uint8(b / count),
+				A: uint8(a / count),
+			})
+		}
+	}
+
+	return dst
+}
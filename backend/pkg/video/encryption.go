@@ -0,0 +1,102 @@
+package video
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SegmentKeySize is the size of an AES-128 HLS segment key in bytes
+const SegmentKeySize = 16
+
+// SegmentEncryption is the AES-128 key a movie's HLS segments are encrypted with and the URI players fetch it from.
+// the key is only handed to ffmpeg through a local file, it is never uploaded next to the segments
+type SegmentEncryption struct {
+	Key    []byte
+	KeyURI string
+}
+
+// NewSegmentEncryption generates a random segment key served at keyURI
+func NewSegmentEncryption(keyURI string) (*SegmentEncryption, error) {
+	key := make([]byte, SegmentKeySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate segment key: %w", err)
+	}
+
+	return &SegmentEncryption{Key: key, KeyURI: keyURI}, nil
+}
+
+// writeKeyInfo writes the key and an ffmpeg key info file referencing it to dir and returns the key info path.
+// without an IV line ffmpeg uses each segment's sequence number, which players derive the same way
+func (e *SegmentEncryption) writeKeyInfo(dir string) (string, error) {
+	if len(e.Key) != SegmentKeySize {
+		return "", fmt.Errorf("segment key must be %d bytes, got %d", SegmentKeySize, len(e.Key))
+	}
+
+	keyPath := filepath.Join(dir, "segment.key")
+	err := os.WriteFile(keyPath, e.Key, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to write segment key: %w", err)
+	}
+
+	keyInfoPath := filepath.Join(dir, "segment.keyinfo")
+	err = os.WriteFile(keyInfoPath, []byte(e.KeyURI+"\n"+keyPath+"\n"), 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to write segment key info: %w", err)
+	}
+
+	return keyInfoPath, nil
+}
+
+// SealSegmentKey encrypts a segment key with AES-GCM under a key derived from secret so the database only holds
+// keys that are useless without the server secret
+func SealSegmentKey(secret string, key []byte) ([]byte, error) {
+	gcm, err := segmentKeyCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, key, nil), nil
+}
+
+// OpenSegmentKey decrypts a segment key sealed by SealSegmentKey with the same secret
+func OpenSegmentKey(secret string, sealed []byte) ([]byte, error) {
+	gcm, err := segmentKeyCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("sealed segment key is too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	key, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open segment key: %w", err)
+	}
+	return key, nil
+}
+
+func segmentKeyCipher(secret string) (cipher.AEAD, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("no secret configured to seal segment keys")
+	}
+
+	derived := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(derived[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
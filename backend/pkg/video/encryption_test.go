@@ -0,0 +1,50 @@
+package video
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegmentEncryptionKeyInfo(t *testing.T) {
+	encryption, err := NewSegmentEncryption("http://localhost:8080/api/v1/videos/42/key")
+	require.NoError(t, err)
+	require.Len(t, encryption.Key, SegmentKeySize)
+
+	dir := t.TempDir()
+	keyInfoPath, err := encryption.writeKeyInfo(dir)
+	require.NoError(t, err)
+
+	keyInfo, err := os.ReadFile(keyInfoPath)
+	require.NoError(t, err)
+	keyPath := filepath.Join(dir, "segment.key")
+	assert.Equal(t, "http://localhost:8080/api/v1/videos/42/key\n"+keyPath+"\n", string(keyInfo))
+
+	key, err := os.ReadFile(keyPath)
+	require.NoError(t, err)
+	assert.Equal(t, encryption.Key, key)
+
+	_, err = (&SegmentEncryption{Key: []byte("short")}).writeKeyInfo(dir)
+	assert.Error(t, err)
+}
+
+func TestSealSegmentKey(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	secret := "a-secret-long-enough-to-seal-keys"
+
+	sealed, err := SealSegmentKey(secret, key)
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), string(key), "the key is not stored in the clear")
+
+	opened, err := OpenSegmentKey(secret, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, key, opened)
+
+	_, err = OpenSegmentKey("another-secret-long-enough-to-seal", sealed)
+	assert.Error(t, err)
+	_, err = SealSegmentKey("", key)
+	assert.Error(t, err)
+}
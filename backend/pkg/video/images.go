@@ -0,0 +1,113 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
+)
+
+// image artifact names, also the file names they are stored under next to the HLS output
+const (
+	ImagePoster  = "poster"
+	ImagePreview = "preview"
+)
+
+// representativeFrameWindow is how many frames the representative strategy compares
+const representativeFrameWindow = 100
+
+// GenerateImages extracts the enabled image artifacts from a video and uploads them under storagePrefix.
+// it returns the storage path of each generated artifact keyed by name.
+func (p *videoProcessor) GenerateImages(ctx context.Context, inputPath, outputDir, storagePrefix string) (map[string]string, error) {
+	artifacts := map[string]config.ImageArtifactConfig{
+		ImagePoster:  p.poster,
+		ImagePreview: p.preview,
+	}
+
+	err := os.MkdirAll(outputDir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	storagePaths := make(map[string]string, len(artifacts))
+	for name, artifact := range artifacts {
+		if !artifact.Enabled {
+			continue
+		}
+
+		localPath := filepath.Join(outputDir, name+".jpg")
+		err := p.extractFrame(ctx, inputPath, localPath, artifact)
+		if err != nil {
+			return storagePaths, fmt.Errorf("failed to generate %s: %w", name, err)
+		}
+
+		storagePath := storagePrefix + "/" + name + ".jpg"
+		err = p.storageProvider.UploadFromPath(ctx, localPath, storagePath)
+		if err != nil {
+			return storagePaths, fmt.Errorf("failed to upload %s: %w", name, err)
+		}
+		storagePaths[name] = storagePath
+	}
+
+	return storagePaths, nil
+}
+
+// extractFrame writes a single JPEG frame of the video selected by the artifact's strategy
+func (p *videoProcessor) extractFrame(ctx context.Context, inputPath, outputPath string, artifact config.ImageArtifactConfig) error {
+	err := p.runFrameExtraction(ctx, inputPath, outputPath, artifact, artifact.Offset.ToDuration().Seconds())
+	if err == nil {
+		return nil
+	}
+
+	// videos shorter than the offset have no frame there, fall back to the start
+	if artifact.Offset > 0 {
+		logger.Infof("no frame at %s of %s, using the first frame", artifact.Offset.ToDuration(), filepath.Base(inputPath))
+		return p.runFrameExtraction(ctx, inputPath, outputPath, artifact, 0)
+	}
+	return err
+}
+
+// runFrameExtraction runs ffmpeg to write one scaled frame starting at offsetSeconds
+func (p *videoProcessor) runFrameExtraction(ctx context.Context, inputPath, outputPath string, artifact config.ImageArtifactConfig, offsetSeconds float64) error {
+	// -2 keeps the aspect ratio with an even height, a width of 0 keeps the source width
+	filter := "scale=trunc(iw/2)*2:-2"
+	if artifact.Width > 0 {
+		filter = fmt.Sprintf("scale=%d:-2", artifact.Width)
+	}
+	if artifact.FrameSelection == config.FrameSelectionRepresentative {
+		filter = fmt.Sprintf("thumbnail=%d,%s", representativeFrameWindow, filter)
+	}
+
+	cmd := exec.CommandContext(ctx,
+		p.ffmpegPath,
+		"-y",
+		"-ss", strconv.FormatFloat(offsetSeconds, 'f', 3, 64),
+		"-i", inputPath,
+		"-vf", filter,
+		"-frames:v", "1",
+		"-q:v", strconv.Itoa(jpegQScale(artifact.Quality)),
+		outputPath,
+	)
+
+	cmdOutput, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ffmpeg failed: %w: %s", err, string(cmdOutput))
+	}
+
+	// ffmpeg succeeds without writing anything when the offset is past the end
+	info, err := os.Stat(outputPath)
+	if err != nil || info.Size() == 0 {
+		return fmt.Errorf("no frame at %.3fs", offsetSeconds)
+	}
+	return nil
+}
+
+// jpegQScale maps a 1-100 quality to ffmpeg's JPEG qscale, where 2 is the best and 31 the smallest
+func jpegQScale(quality int) int {
+	quality = min(max(quality, 1), 100)
+	return 31 - (quality-1)*29/99
+}
@@ -0,0 +1,86 @@
+package video
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// probeOutput is the part of `ffprobe -print_format json -show_format -show_streams` read into VideoInfo
+type probeOutput struct {
+	Streams []struct {
+		CodecType  string `json:"codec_type"`
+		CodecName  string `json:"codec_name"`
+		Width      int    `json:"width"`
+		Height     int    `json:"height"`
+		RFrameRate string `json:"r_frame_rate"`
+		Duration   string `json:"duration"`
+	} `json:"streams"`
+	Format struct {
+		Duration string `json:"duration"`
+		Size     string `json:"size"`
+		BitRate  string `json:"bit_rate"`
+	} `json:"format"`
+}
+
+// parseProbeOutput reads the container and first video and audio streams of an ffprobe JSON report. ffprobe
+// reports numbers as strings and leaves out what it cannot tell, those fields stay zero
+func parseProbeOutput(output []byte) (*VideoInfo, error) {
+	var probe probeOutput
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, fmt.Errorf("invalid ffprobe output: %w", err)
+	}
+
+	info := &VideoInfo{
+		Duration: parseProbeFloat(probe.Format.Duration),
+		Bitrate:  int64(parseProbeFloat(probe.Format.BitRate)),
+		FileSize: int64(parseProbeFloat(probe.Format.Size)),
+	}
+
+	for _, stream := range probe.Streams {
+		switch stream.CodecType {
+		case "video":
+			if info.VideoCodec != "" {
+				continue
+			}
+			info.VideoCodec = stream.CodecName
+			info.Width = stream.Width
+			info.Height = stream.Height
+			info.FrameRate = parseFrameRate(stream.RFrameRate)
+			// some containers only carry the duration on their streams
+			if info.Duration == 0 {
+				info.Duration = parseProbeFloat(stream.Duration)
+			}
+		case "audio":
+			if info.AudioCodec == "" {
+				info.AudioCodec = stream.CodecName
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// parseProbeFloat reads a number ffprobe printed as a string, 0 when it is missing or "N/A"
+func parseProbeFloat(value string) float64 {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed < 0 {
+		return 0
+	}
+	return parsed
+}
+
+// parseFrameRate reads a rational frame rate such as "30000/1001"
+func parseFrameRate(value string) float64 {
+	numerator, denominator, found := strings.Cut(value, "/")
+	if !found {
+		return parseProbeFloat(value)
+	}
+
+	den := parseProbeFloat(denominator)
+	if den == 0 {
+		return 0
+	}
+	return parseProbeFloat(numerator) / den
+}
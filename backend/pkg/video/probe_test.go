@@ -0,0 +1,46 @@
+package video
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProbeOutput(t *testing.T) {
+	output := `{
+		"streams": [
+			{"codec_type": "audio", "codec_name": "aac"},
+			{"codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080, "r_frame_rate": "30000/1001"},
+			{"codec_type": "video", "codec_name": "mjpeg", "width": 320, "height": 180, "r_frame_rate": "90000/1"}
+		],
+		"format": {"duration": "5400.250000", "size": "1073741824", "bit_rate": "1590728"}
+	}`
+
+	info, err := parseProbeOutput([]byte(output))
+	require.NoError(t, err)
+	assert.Equal(t, 5400.25, info.Duration)
+	assert.Equal(t, int64(1073741824), info.FileSize)
+	assert.Equal(t, int64(1590728), info.Bitrate)
+	assert.Equal(t, "h264", info.VideoCodec, "cover art streams after the first video stream are ignored")
+	assert.Equal(t, 1920, info.Width)
+	assert.Equal(t, 1080, info.Height)
+	assert.InDelta(t, 29.97, info.FrameRate, 0.01)
+	assert.Equal(t, "aac", info.AudioCodec)
+}
+
+func TestParseProbeOutputStreamDuration(t *testing.T) {
+	output := `{
+		"streams": [{"codec_type": "video", "codec_name": "vp9", "r_frame_rate": "0/0", "duration": "12.5"}],
+		"format": {"duration": "N/A"}
+	}`
+
+	info, err := parseProbeOutput([]byte(output))
+	require.NoError(t, err)
+	assert.Equal(t, 12.5, info.Duration)
+	assert.Zero(t, info.FrameRate)
+	assert.Empty(t, info.AudioCodec)
+
+	_, err = parseProbeOutput([]byte("not json"))
+	assert.Error(t, err)
+}
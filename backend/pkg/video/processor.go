@@ -2,6 +2,7 @@ package video
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,15 +11,22 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"watch-party/pkg/config"
 	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
 	"watch-party/pkg/storage"
 )
 
+// ErrNotVideo is returned for files without a readable video stream, whatever their extension says
+var ErrNotVideo = errors.New("file does not contain a valid video stream")
+
 // Processor handles video transcoding and HLS conversion
 type Processor interface {
-	TranscodeToHLS(ctx context.Context, inputPath, outputDir, storagePrefix string, qualities []Quality) (*HLSOutput, error)
+	TranscodeToHLS(ctx context.Context, inputPath, outputDir, storagePrefix string, qualities []Quality, encryption *SegmentEncryption) (*HLSOutput, error)
+	ExtendHLS(ctx context.Context, inputPath, outputDir, storagePrefix string, ladder []Quality, published []string, encryption *SegmentEncryption) (*HLSOutput, error)
 	GetVideoInfo(ctx context.Context, filePath string) (*VideoInfo, error)
 	ValidateVideoFile(ctx context.Context, filePath string) error
+	GenerateImages(ctx context.Context, inputPath, outputDir, storagePrefix string) (map[string]string, error)
 }
 
 // Quality represents a video quality level for HLS transcoding
@@ -47,6 +55,7 @@ type HLSOutput struct {
 	MasterPlaylistURL   string            // URL to master m3u8 file in storage
 	QualityPlaylistURLs map[string]string // Quality name -> playlist URL in storage
 	SegmentURLs         []string          // All .ts segment URLs in storage
+	FailedQualities     []string          // qualities of the ladder that failed to transcode, in ladder order
 	TotalSegments       int
 	ProcessingTime      time.Duration
 }
@@ -66,29 +75,69 @@ type videoProcessor struct {
 	tempDir         string
 	ffmpegPath      string
 	ffprobePath     string
+	poster          config.ImageArtifactConfig
+	preview         config.ImageArtifactConfig
 }
 
 // NewProcessor creates a new video processor
-func NewProcessor(storageProvider storage.Provider, tempDir string) Processor {
-	return &videoProcessor{
+func NewProcessor(storageProvider storage.Provider, cfg config.VideoConfig) Processor {
+	p := &videoProcessor{
 		storageProvider: storageProvider,
-		tempDir:         tempDir,
-		ffmpegPath:      "ffmpeg",  // assumes ffmpeg is in PATH
-		ffprobePath:     "ffprobe", // assumes ffprobe is in PATH
+		tempDir:         cfg.TempDir,
+		ffmpegPath:      cfg.FFmpegPath,
+		ffprobePath:     cfg.FFprobePath,
+		poster:          cfg.Poster,
+		preview:         cfg.Preview,
+	}
+	if p.ffmpegPath == "" {
+		p.ffmpegPath = "ffmpeg" // assumes ffmpeg is in PATH
 	}
+	if p.ffprobePath == "" {
+		p.ffprobePath = "ffprobe" // assumes ffprobe is in PATH
+	}
+	return p
 }
 
 // Default quality levels for HLS transcoding
 var DefaultQualities = []Quality{
-	{Name: "360p", Width: 640, Height: 360, Bitrate: "1000k", SegmentDur: 6},
-	{Name: "720p", Width: 1280, Height: 720, Bitrate: "2500k", SegmentDur: 6},
-	{Name: "1080p", Width: 1920, Height: 1080, Bitrate: "5000k", SegmentDur: 6},
+	{Name: model.Quality360p, Width: 640, Height: 360, Bitrate: "1000k", SegmentDur: 6},
+	{Name: model.Quality720p, Width: 1280, Height: 720, Bitrate: "2500k", SegmentDur: 6},
+	{Name: model.Quality1080p, Width: 1920, Height: 1080, Bitrate: "5000k", SegmentDur: 6},
+}
+
+// PreviewQuality is the single rendition a fast preview publishes first (model.PreviewQuality),
+// watchable on most screens and quick to encode
+var PreviewQuality = DefaultQualities[1]
+
+// TranscodeToHLS converts a video file to HLS format and uploads to storage.
+// segments are encrypted with AES-128 when encryption is not nil
+func (p *videoProcessor) TranscodeToHLS(ctx context.Context, inputPath, outputDir, storagePrefix string, qualities []Quality, encryption *SegmentEncryption) (*HLSOutput, error) {
+	return p.transcode(ctx, inputPath, outputDir, storagePrefix, qualities, nil, encryption)
+}
+
+// ExtendHLS transcodes the qualities of the ladder a movie's HLS output doesn't have yet, then republishes
+// the master playlist listing them next to the published ones. an encrypted movie must be extended with its key
+func (p *videoProcessor) ExtendHLS(ctx context.Context, inputPath, outputDir, storagePrefix string, ladder []Quality, published []string, encryption *SegmentEncryption) (*HLSOutput, error) {
+	return p.transcode(ctx, inputPath, outputDir, storagePrefix, ladder, published, encryption)
 }
 
-// TranscodeToHLS converts a video file to HLS format and uploads to storage
-func (p *videoProcessor) TranscodeToHLS(ctx context.Context, inputPath, outputDir, storagePrefix string, qualities []Quality) (*HLSOutput, error) {
+// transcode converts the qualities of the ladder that aren't published yet and uploads them with a master
+// playlist covering both, in ladder order
+func (p *videoProcessor) transcode(ctx context.Context, inputPath, outputDir, storagePrefix string, ladder []Quality, published []string, encryption *SegmentEncryption) (*HLSOutput, error) {
 	startTime := time.Now()
 
+	qualityPlaylistPaths := make(map[string]string) // for master playlist creation
+	for _, name := range published {
+		qualityPlaylistPaths[name] = name + "/playlist.m3u8"
+	}
+
+	qualities := make([]Quality, 0, len(ladder))
+	for _, quality := range ladder {
+		if _, ok := qualityPlaylistPaths[quality.Name]; !ok {
+			qualities = append(qualities, quality)
+		}
+	}
+
 	// ensure output directory exists
 	err := os.MkdirAll(outputDir, 0755)
 	if err != nil {
@@ -102,6 +151,18 @@ func (p *videoProcessor) TranscodeToHLS(ctx context.Context, inputPath, outputDi
 		}
 	}()
 
+	// every quality is encrypted with the same key, kept next to the output and removed with it
+	var keyInfoPath string
+	if encryption != nil {
+		keyInfoPath, err = encryption.writeKeyInfo(outputDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// a silent source gets no audio track, advertising one confuses strict players
+	hasAudio := p.hasAudio(ctx, inputPath)
+
 	// channel to collect results from goroutines
 	resultsChan := make(chan QualityResult, len(qualities))
 	var wg sync.WaitGroup
@@ -111,7 +172,7 @@ func (p *videoProcessor) TranscodeToHLS(ctx context.Context, inputPath, outputDi
 		wg.Add(1)
 		go func(q Quality) {
 			defer wg.Done()
-			result := p.processQuality(ctx, inputPath, outputDir, storagePrefix, q)
+			result := p.processQuality(ctx, inputPath, outputDir, storagePrefix, q, keyInfoPath, hasAudio)
 			resultsChan <- result
 		}(quality)
 	}
@@ -128,7 +189,6 @@ func (p *videoProcessor) TranscodeToHLS(ctx context.Context, inputPath, outputDi
 		SegmentURLs:         make([]string, 0),
 	}
 
-	qualityPlaylistPaths := make(map[string]string) // for master playlist creation
 	var processingErrors []error
 
 	for result := range resultsChan {
@@ -156,11 +216,17 @@ func (p *videoProcessor) TranscodeToHLS(ctx context.Context, inputPath, outputDi
 		if len(output.QualityPlaylistURLs) == 0 {
 			return nil, fmt.Errorf("all quality levels failed to process")
 		}
+
+		for _, quality := range qualities {
+			if _, ok := output.QualityPlaylistURLs[quality.Name]; !ok {
+				output.FailedQualities = append(output.FailedQualities, quality.Name)
+			}
+		}
 	}
 
 	// create and upload master playlist
 	masterPlaylistPath := filepath.Join(outputDir, "master.m3u8")
-	err = p.createMasterPlaylist(masterPlaylistPath, qualities, qualityPlaylistPaths)
+	err = p.createMasterPlaylist(masterPlaylistPath, ladder, qualityPlaylistPaths, hasAudio)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create master playlist: %w", err)
 	}
@@ -187,8 +253,20 @@ func (p *videoProcessor) TranscodeToHLS(ctx context.Context, inputPath, outputDi
 	return output, nil
 }
 
-// processQuality handles transcoding and uploading for a single quality level
-func (p *videoProcessor) processQuality(ctx context.Context, inputPath, outputDir, storagePrefix string, quality Quality) QualityResult {
+// hasAudio reports whether a source has an audio stream. when probing fails the source is assumed to have one,
+// as every source was before silent ones were detected
+func (p *videoProcessor) hasAudio(ctx context.Context, inputPath string) bool {
+	info, err := p.GetVideoInfo(ctx, inputPath)
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("failed to probe audio of %s, assuming it has audio", filepath.Base(inputPath)))
+		return true
+	}
+	return info.AudioCodec != ""
+}
+
+// processQuality handles transcoding and uploading for a single quality level, encrypting the segments
+// with the key of keyInfoPath unless it is empty
+func (p *videoProcessor) processQuality(ctx context.Context, inputPath, outputDir, storagePrefix string, quality Quality, keyInfoPath string, hasAudio bool) QualityResult {
 	result := QualityResult{Quality: quality}
 
 	qualityDir := filepath.Join(outputDir, quality.Name)
@@ -201,20 +279,7 @@ func (p *videoProcessor) processQuality(ctx context.Context, inputPath, outputDi
 	playlistPath := filepath.Join(qualityDir, "playlist.m3u8")
 	segmentPattern := filepath.Join(qualityDir, "segment_%03d.ts")
 
-	// build ffmpeg command for this quality
-	cmd := exec.CommandContext(ctx,
-		p.ffmpegPath,
-		"-i", inputPath,
-		"-c:v", "libx264",
-		"-c:a", "aac",
-		"-b:v", quality.Bitrate,
-		"-s", fmt.Sprintf("%dx%d", quality.Width, quality.Height),
-		"-hls_time", strconv.Itoa(quality.SegmentDur),
-		"-hls_playlist_type", "vod",
-		"-hls_segment_filename", segmentPattern,
-		"-f", "hls",
-		playlistPath,
-	)
+	cmd := exec.CommandContext(ctx, p.ffmpegPath, qualityArgs(inputPath, playlistPath, segmentPattern, quality, keyInfoPath, hasAudio)...)
 
 	logger.Infof("transcoding to %s: %s", quality.Name, cmd.String())
 
@@ -306,8 +371,40 @@ func (p *videoProcessor) processQuality(ctx context.Context, inputPath, outputDi
 	return result
 }
 
-// createMasterPlaylist creates the master HLS playlist
-func (p *videoProcessor) createMasterPlaylist(masterPath string, qualities []Quality, playlistPaths map[string]string) error {
+// qualityArgs builds the ffmpeg arguments that transcode a source into one HLS quality
+func qualityArgs(inputPath, playlistPath, segmentPattern string, quality Quality, keyInfoPath string, hasAudio bool) []string {
+	args := []string{
+		"-i", inputPath,
+		"-c:v", "libx264",
+	}
+	if hasAudio {
+		args = append(args, "-c:a", "aac")
+	} else {
+		args = append(args, "-an")
+	}
+	args = append(args,
+		"-b:v", quality.Bitrate,
+		"-s", fmt.Sprintf("%dx%d", quality.Width, quality.Height),
+		"-hls_time", strconv.Itoa(quality.SegmentDur),
+		"-hls_playlist_type", "vod",
+		"-hls_segment_filename", segmentPattern,
+	)
+	if keyInfoPath != "" {
+		// ffmpeg encrypts each segment and adds #EXT-X-KEY to the playlist
+		args = append(args, "-hls_key_info_file", keyInfoPath)
+	}
+	return append(args, "-f", "hls", playlistPath)
+}
+
+// createMasterPlaylist creates the master HLS playlist, advertising an audio codec only when the source has audio
+func (p *videoProcessor) createMasterPlaylist(masterPath string, qualities []Quality, playlistPaths map[string]string, hasAudio bool) error {
+	// avc1.42E01E = H.264 Baseline Profile Level 3.0
+	// mp4a.40.2 = AAC-LC (Low Complexity)
+	codecs := "avc1.42E01E"
+	if hasAudio {
+		codecs += ",mp4a.40.2"
+	}
+
 	var content strings.Builder
 	content.WriteString("#EXTM3U\n")
 	content.WriteString("#EXT-X-VERSION:3\n\n")
@@ -319,11 +416,8 @@ func (p *videoProcessor) createMasterPlaylist(masterPath string, qualities []Qua
 			bitrate, _ := strconv.Atoi(bitrateStr)
 			bitrateBps := bitrate * 1000
 
-			// include proper codec information for audio support
-			// avc1.42E01E = H.264 Baseline Profile Level 3.0
-			// mp4a.40.2 = AAC-LC (Low Complexity)
-			content.WriteString(fmt.Sprintf("#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%dx%d,CODECS=\"avc1.42E01E,mp4a.40.2\",NAME=\"%s\"\n",
-				bitrateBps, quality.Width, quality.Height, quality.Name))
+			content.WriteString(fmt.Sprintf("#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%dx%d,CODECS=\"%s\",NAME=\"%s\"\n",
+				bitrateBps, quality.Width, quality.Height, codecs, quality.Name))
 			content.WriteString(fmt.Sprintf("%s\n\n", relPath))
 		}
 	}
@@ -347,20 +441,7 @@ func (p *videoProcessor) GetVideoInfo(ctx context.Context, filePath string) (*Vi
 		return nil, fmt.Errorf("ffprobe failed: %w", err)
 	}
 
-	// parse ffprobe output (simplified - you might want to use a JSON parser)
-	info := &VideoInfo{}
-
-	// this is a simplified parser - in production you'd want proper JSON parsing
-	outputStr := string(output)
-	if strings.Contains(outputStr, `"codec_type": "video"`) {
-		// extract basic info - this is very simplified
-		info.Duration = 0      // would parse from "duration" field
-		info.Width = 1920      // would parse from "width" field
-		info.Height = 1080     // would parse from "height" field
-		info.Bitrate = 5000000 // would parse from "bit_rate" field
-	}
-
-	return info, nil
+	return parseProbeOutput(output)
 }
 
 // ValidateVideoFile validates if a file is a supported video format
@@ -376,11 +457,16 @@ func (p *videoProcessor) ValidateVideoFile(ctx context.Context, filePath string)
 
 	output, err := cmd.Output()
 	if err != nil {
+		// ffprobe exits with an error when it cannot read the file as media at all
+		var exitErr *exec.ExitError
+		if ctx.Err() == nil && errors.As(err, &exitErr) {
+			return fmt.Errorf("%w: %v", ErrNotVideo, err)
+		}
 		return fmt.Errorf("failed to validate video file: %w", err)
 	}
 
 	if strings.TrimSpace(string(output)) != "video" {
-		return fmt.Errorf("file does not contain a valid video stream")
+		return ErrNotVideo
 	}
 
 	return nil
@@ -0,0 +1,43 @@
+package video
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQualityArgsVideoOnly(t *testing.T) {
+	quality := DefaultQualities[0]
+
+	args := qualityArgs("input.mp4", "playlist.m3u8", "segment_%03d.ts", quality, "", false)
+	assert.Contains(t, args, "-an", "a silent source is encoded without an audio track")
+	assert.NotContains(t, args, "-c:a")
+	assert.Equal(t, "playlist.m3u8", args[len(args)-1])
+
+	args = qualityArgs("input.mp4", "playlist.m3u8", "segment_%03d.ts", quality, "key.info", true)
+	assert.Contains(t, args, "-c:a")
+	assert.NotContains(t, args, "-an")
+	assert.Contains(t, args, "-hls_key_info_file")
+}
+
+func TestCreateMasterPlaylistCodecs(t *testing.T) {
+	p := &videoProcessor{}
+	dir := t.TempDir()
+	paths := map[string]string{DefaultQualities[0].Name: DefaultQualities[0].Name + "/playlist.m3u8"}
+
+	videoOnly := filepath.Join(dir, "video-only.m3u8")
+	require.NoError(t, p.createMasterPlaylist(videoOnly, DefaultQualities, paths, false))
+	content, err := os.ReadFile(videoOnly)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `CODECS="avc1.42E01E",`)
+	assert.NotContains(t, string(content), "mp4a")
+
+	withAudio := filepath.Join(dir, "with-audio.m3u8")
+	require.NoError(t, p.createMasterPlaylist(withAudio, DefaultQualities, paths, true))
+	content, err = os.ReadFile(withAudio)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `CODECS="avc1.42E01E,mp4a.40.2"`)
+}
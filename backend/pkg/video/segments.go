@@ -0,0 +1,80 @@
+package video
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SegmentInfo represents a video segment with timing information
+type SegmentInfo struct {
+	Index     int     `json:"index"`
+	Filename  string  `json:"filename"`
+	Duration  float64 `json:"duration"`
+	StartTime float64 `json:"start_time"`
+}
+
+// SegmentTimeline is the parsed segment list of one rendition, as cached for seeks and sync broadcasts
+type SegmentTimeline struct {
+	Segments      []SegmentInfo `json:"segments"`
+	TotalDuration float64       `json:"total_duration"`
+}
+
+// ParseSegments reads the segments of a media playlist and where each starts. a segment without a usable
+// #EXTINF duration counts as zero seconds instead of inheriting the previous one
+func ParseSegments(content string) ([]SegmentInfo, float64) {
+	var segments []SegmentInfo
+	var currentDuration float64
+	var totalDuration float64
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+
+		// parse segment duration
+		if duration, ok := strings.CutPrefix(line, "#EXTINF:"); ok {
+			// extract duration from #EXTINF:duration,title
+			duration, _, _ = strings.Cut(duration, ",")
+			currentDuration = 0
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(duration), 64); err == nil && parsed > 0 && !math.IsInf(parsed, 0) {
+				currentDuration = parsed
+			}
+		} else if line != "" && !strings.HasPrefix(line, "#") {
+			// this is a segment filename
+			segments = append(segments, SegmentInfo{
+				Index:     len(segments),
+				Filename:  line,
+				Duration:  currentDuration,
+				StartTime: totalDuration,
+			})
+			totalDuration += currentDuration
+			currentDuration = 0
+		}
+	}
+
+	return segments, totalDuration
+}
+
+// segmentBoundaryTolerance absorbs the rounding of start times summed from segment durations, so a time at a
+// segment boundary picks the later segment rather than the end of the earlier one
+const segmentBoundaryTolerance = 1e-6
+
+// FindSegmentByTime finds the segment that contains the given time, a time exactly at a boundary belongs to the
+// segment starting there. it returns -1 when no segment does
+func FindSegmentByTime(segments []SegmentInfo, targetTime float64) (int, float64) {
+	for i, segment := range segments {
+		start := segment.StartTime - segmentBoundaryTolerance
+		if targetTime >= start && targetTime < start+segment.Duration {
+			return i, segment.StartTime
+		}
+	}
+
+	// if time is beyond the last segment, return the last segment
+	if len(segments) > 0 {
+		lastSegment := segments[len(segments)-1]
+		if targetTime >= lastSegment.StartTime-segmentBoundaryTolerance {
+			return len(segments) - 1, lastSegment.StartTime
+		}
+	}
+
+	return -1, 0
+}
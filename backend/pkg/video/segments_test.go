@@ -0,0 +1,94 @@
+package video
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSegments(t *testing.T) {
+	playlist := "#EXTM3U\r\n#EXT-X-TARGETDURATION:6\r\n#EXTINF:6.0,\r\nsegment_000.ts\r\n#EXTINF:4.5,title\r\nsegment_001.ts\r\nsegment_002.ts\r\n#EXTINF:bogus,\r\nsegment_003.ts\r\n#EXT-X-ENDLIST\r\n"
+
+	segments, total := ParseSegments(playlist)
+
+	assert.Equal(t, []SegmentInfo{
+		{Index: 0, Filename: "segment_000.ts", Duration: 6, StartTime: 0},
+		{Index: 1, Filename: "segment_001.ts", Duration: 4.5, StartTime: 6},
+		{Index: 2, Filename: "segment_002.ts", Duration: 0, StartTime: 10.5},
+		{Index: 3, Filename: "segment_003.ts", Duration: 0, StartTime: 10.5},
+	}, segments)
+	assert.Equal(t, 10.5, total)
+}
+
+func TestFindSegmentByTime(t *testing.T) {
+	segments, _ := ParseSegments("#EXTINF:6.0,\nsegment_000.ts\n#EXTINF:6.0,\nsegment_001.ts\n#EXTINF:4.5,\nsegment_002.ts\n")
+
+	// start times summed from these durations drift, the fourth starts at 0.30000000000000004
+	drifting, _ := ParseSegments(strings.Repeat("#EXTINF:0.1,\nsegment.ts\n", 5))
+
+	tests := []struct {
+		name      string
+		segments  []SegmentInfo
+		time      float64
+		wantIndex int
+		wantStart float64
+	}{
+		{"time at 0", segments, 0, 0, 0},
+		{"inside the first segment", segments, 3.2, 0, 0},
+		{"exactly at a boundary picks the later segment", segments, 6, 1, 6},
+		{"just before a boundary", segments, 11.999, 1, 6},
+		{"exactly at the last boundary", segments, 12, 2, 12},
+		{"exactly at the end", segments, 16.5, 2, 12},
+		{"past the end returns the last segment", segments, 100, 2, 12},
+		{"boundary of drifting start times", drifting, 0.3, 3, drifting[3].StartTime},
+		{"before the first segment", segments, -1, -1, 0},
+		{"empty segment list", nil, 0, -1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, start := FindSegmentByTime(tt.segments, tt.time)
+			assert.Equal(t, tt.wantIndex, index)
+			assert.Equal(t, tt.wantStart, start)
+		})
+	}
+}
+
+func FuzzParseSegments(f *testing.F) {
+	f.Add("#EXTM3U\n#EXTINF:6.0,\nsegment_000.ts\n#EXTINF:6.0,\nsegment_001.ts\n#EXT-X-ENDLIST\n")
+	f.Add("#EXTINF:4.5,title\r\nsegment_000.ts\r\n")
+	f.Add("#EXTINF:\nsegment_000.ts\n")
+	f.Add("#EXTINF:NaN,\nsegment_000.ts\n#EXTINF:-3,\nsegment_001.ts\n#EXTINF:Inf\nsegment_002.ts\n")
+	f.Add("#EXTINF:1e400,\nsegment_000.ts\n#EXTINF:6,,,\n\n\nsegment_001.ts")
+	f.Add(strings.Repeat("#EXTINF:2.0,\nsegment.ts\n", 1000))
+	f.Add("")
+
+	f.Fuzz(func(t *testing.T, content string) {
+		segments, total := ParseSegments(content)
+
+		var start float64
+		for i, segment := range segments {
+			if segment.Index != i {
+				t.Fatalf("segment %d has index %d", i, segment.Index)
+			}
+			if segment.Duration < 0 || math.IsNaN(segment.Duration) || math.IsInf(segment.Duration, 0) {
+				t.Fatalf("segment %d has duration %v", i, segment.Duration)
+			}
+			if segment.StartTime != start {
+				t.Fatalf("segment %d starts at %v, want %v", i, segment.StartTime, start)
+			}
+			start += segment.Duration
+		}
+		if total != start {
+			t.Fatalf("total duration %v, want %v", total, start)
+		}
+
+		// whatever the playlist, a seek resolves without panicking
+		index, _ := FindSegmentByTime(segments, total/2)
+		if len(segments) > 0 && index < 0 && total > 0 {
+			t.Fatalf("no segment found at %v of %v", total/2, total)
+		}
+	})
+}
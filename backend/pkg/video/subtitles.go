@@ -0,0 +1,73 @@
+package video
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidSubtitle is returned for subtitle files that are neither valid SRT nor WebVTT
+var ErrInvalidSubtitle = errors.New("invalid subtitle file")
+
+const webVTTHeader = "WEBVTT"
+
+// srtTimestamp matches an SRT timing line, which differs from WebVTT only in the decimal comma
+var srtTimestamp = regexp.MustCompile(`^(\d{1,2}:\d{2}:\d{2}),(\d{3})\s*-->\s*(\d{1,2}:\d{2}:\d{2}),(\d{3})(.*)$`)
+
+// ToWebVTT returns a subtitle file as WebVTT, converting SRT when the name or content says it is one
+func ToWebVTT(filename string, content []byte) ([]byte, error) {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(content) {
+		return nil, fmt.Errorf("%w: subtitles must be UTF-8 encoded", ErrInvalidSubtitle)
+	}
+
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	if strings.HasPrefix(text, webVTTHeader) {
+		if !strings.Contains(text, "-->") {
+			return nil, fmt.Errorf("%w: no cues found", ErrInvalidSubtitle)
+		}
+		return []byte(text), nil
+	}
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext == ".vtt" {
+		return nil, fmt.Errorf("%w: WebVTT files must start with WEBVTT", ErrInvalidSubtitle)
+	}
+
+	return convertSRT(text)
+}
+
+// convertSRT rewrites the timing lines of an SRT file, cue numbers are kept as WebVTT cue identifiers
+func convertSRT(text string) ([]byte, error) {
+	var out strings.Builder
+	out.WriteString(webVTTHeader + "\n\n")
+
+	cues := 0
+	for _, line := range strings.Split(text, "\n") {
+		if match := srtTimestamp.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			out.WriteString(vttTime(match[1], match[2]) + " --> " + vttTime(match[3], match[4]) + match[5] + "\n")
+			cues++
+			continue
+		}
+		out.WriteString(line + "\n")
+	}
+
+	if cues == 0 {
+		return nil, fmt.Errorf("%w: no cues found", ErrInvalidSubtitle)
+	}
+	return []byte(out.String()), nil
+}
+
+// vttTime formats an SRT time as WebVTT, which wants two-digit hours
+func vttTime(clock, millis string) string {
+	if len(clock) == len("0:00:00") {
+		clock = "0" + clock
+	}
+	return clock + "." + millis
+}
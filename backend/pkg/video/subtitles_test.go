@@ -0,0 +1,46 @@
+package video
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToWebVTT(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "srt with short hours and CRLF",
+			filename: "movie.srt",
+			content:  "\xef\xbb\xbf1\r\n0:00:01,500 --> 0:00:03,000\r\nHello\r\n\r\n2\r\n00:00:04,000 --> 00:00:05,250\r\nWorld\r\n",
+			want:     "WEBVTT\n\n1\n00:00:01.500 --> 00:00:03.000\nHello\n\n2\n00:00:04.000 --> 00:00:05.250\nWorld\n\n",
+		},
+		{
+			name:     "webvtt passes through",
+			filename: "movie.vtt",
+			content:  "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nHi\n",
+			want:     "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nHi\n",
+		},
+		{name: "vtt without header", filename: "movie.vtt", content: "00:00:01.000 --> 00:00:02.000\nHi\n", wantErr: true},
+		{name: "no cues", filename: "movie.srt", content: "just some text\n", wantErr: true},
+		{name: "not utf-8", filename: "movie.srt", content: "1\n00:00:01,000 --> 00:00:02,000\n\xff\xfe\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToWebVTT(tt.filename, []byte(tt.content))
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrInvalidSubtitle))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -8,11 +8,14 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"watch-party/pkg/auth"
 	"watch-party/pkg/config"
 	"watch-party/pkg/database"
 	"watch-party/pkg/email"
 	"watch-party/pkg/events"
 	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
 	"watch-party/pkg/storage"
 	"watch-party/pkg/video"
 	mdw "watch-party/service-api/internal/app/middleware"
@@ -34,60 +37,110 @@ type AppServer struct {
 	movieController       *ctl.MovieController
 	roomController        *ctl.RoomController
 	webhookController     *ctl.WebhookController
+	emailController       *ctl.EmailController
 	streamingController   *ctl.StreamingController
 	videoAccessController *ctl.VideoAccessController
 	roomService           *roomService.Service
+	movieService          movieService.Service
+	uploadHandler         events.Handler
+	storageProvider       storage.Provider
+	redisClient           *redis.Client
+	emailQueue            *email.Queue
+	jwtManager            *auth.JWTManager
+	guestCookies          *auth.GuestCookieSigner
 }
 
 // NewAppServer creates a new instance of AppServer with the provided configuration, middleware, and controller.
 func NewAppServer(cfg *config.Config) *AppServer {
+	// an unset default keeps rooms free for all
+	if cfg.Room.DefaultControlMode != "" && !model.IsControlMode(cfg.Room.DefaultControlMode) {
+		logger.Fatalf("invalid ROOM_DEFAULT_CONTROL_MODE %q, must be %q or %q",
+			cfg.Room.DefaultControlMode, model.ControlModeFree, model.ControlModeHostOnly)
+	}
+
+	// a typo in the format allowlist would reject every upload, refuse to start instead
+	err := cfg.Storage.VideoProcessing.ValidateAllowedExtensions()
+	if err != nil {
+		logger.Fatalf("invalid video configuration: %v", err)
+	}
+	err = cfg.Storage.VideoProcessing.ValidateSegmentEncryption()
+	if err != nil {
+		logger.Fatalf("invalid video configuration: %v", err)
+	}
+
 	// initialize database
 	db, err := database.NewPgDB(cfg)
 	if err != nil {
 		logger.Fatalf("failed to initialize database: %v", err)
 	}
 
-	// initialize storage provider
-	storageProvider, err := storage.NewStorageProvider(context.Background(), &cfg.Storage)
+	// initialize storage provider, every call gets a deadline so a stalled backend cannot pile up goroutines.
+	// playlists are uploaded with the content type the streaming routes serve and sign them with
+	operationTimeout, transferTimeout := cfg.Storage.StorageTimeouts()
+	storageProvider, err := storage.NewStorageProvider(context.Background(), &cfg.Storage, cfg.Streaming.EffectivePlaylistContentType())
 	if err != nil {
 		logger.Fatalf("failed to initialize storage provider: %v", err)
 	}
+	storageProvider = storage.WithTimeouts(storageProvider, operationTimeout, transferTimeout)
 
 	// initialize repositories
 	userRepository := userRepo.NewRepository(db)
 	authRepository := authRepo.NewRepository(db)
-	movieRepository := movieRepo.NewRepository(db)
+	movieRepository := movieRepo.NewCachedRepository(movieRepo.NewRepository(db, cfg.Storage.VideoProcessing.KeySecret), cfg.Streaming.MovieCacheMaxAge())
 	roomRepository := roomRepo.NewRepository(db)
 
+	// initialize Redis client, used to push room changes to service-sync
+	redisClient, err := redis.NewClient(cfg)
+	if err != nil {
+		logger.Error(err, "failed to initialize Redis client, real-time room updates are disabled")
+		redisClient = nil
+	}
+
 	// shared pkgs
 	emailService, err := email.NewEmailProvider(context.Background(), &cfg.Email)
 	if err != nil {
 		logger.Fatalf("failed to initialize email provider: %v", err)
 	}
 
+	// tokens are checked against the revocation list kept in Redis when it is available
+	jwtManager := auth.NewJWTManager(cfg.JWTSecret, auth.NewTokenRevoker(redisClient))
+
+	// guests only get a streaming cookie when enabled, otherwise they keep using the token parameter
+	var guestCookies *auth.GuestCookieSigner
+	if cfg.Streaming.GuestCookie {
+		guestCookies = auth.NewGuestCookieSigner(cfg.JWTSecret, cfg.Streaming.GuestCookieDomain)
+	}
+
 	// initialize services
-	userSvc := userService.NewUserService(userRepository)
-	authSvc := authService.NewAuthService(cfg, userSvc, authRepository)
-	movieSvc := movieService.NewMovieService(movieRepository, storageProvider)
-	roomSvc := roomService.NewService(roomRepository, userRepository, emailService, cfg)
+	userSvc := userService.NewUserService(userRepository, redisClient, storageProvider, cfg.Profile.AvatarAllowedHosts)
+	authSvc := authService.NewAuthService(jwtManager, userSvc, authRepository)
+	movieSvc := movieService.NewMovieService(movieRepository, storageProvider, redisClient, cfg)
+	emailQueue := email.NewQueue(emailService, redisClient)
+	roomSvc := roomService.NewService(roomRepository, userRepository, emailQueue, redisClient, cfg)
 
 	// initialize event handler dependencies
 	tempDir := cfg.Storage.VideoProcessing.TempDir
 	hlsBaseURL := cfg.Storage.VideoProcessing.HLSBaseURL
 
 	// create video processor
-	videoProcessor := video.NewProcessor(storageProvider, tempDir)
+	videoProcessor := video.NewProcessor(storageProvider, cfg.Storage.VideoProcessing)
 
 	// create upload event handler
-	uploadHandler := events.NewHandler(movieRepository, storageProvider, videoProcessor, hlsBaseURL, tempDir)
+	uploadHandler := events.NewHandler(movieRepository, storageProvider, videoProcessor, hlsBaseURL, tempDir,
+		cfg.Storage.VideoProcessing.FastPreviewFullLadder, storage.ParallelDownloadOptions{
+			Concurrency: cfg.Storage.VideoProcessing.DownloadConcurrency,
+			ChunkSize:   int64(cfg.Storage.VideoProcessing.DownloadChunkSizeMB) << 20,
+		}, cfg.Storage.UploadSizeLimit(), cfg.Storage.VideoProcessing.SegmentKeyBaseURL(),
+		cfg.Storage.VideoProcessing.InputExtensions(), cfg.Storage.VideoProcessing.MaxSourceDuration())
 
 	// initialize controllers
-	controller := ctl.NewController(authSvc)
-	movieController := ctl.NewMovieController(movieSvc)
-	roomController := ctl.NewRoomController(roomSvc)
-	webhookController := ctl.NewWebhookController(uploadHandler)
-	streamingController := ctl.NewStreamingController(storageProvider, movieSvc, roomSvc)
-	videoAccessController := ctl.NewVideoAccessController(storageProvider, movieSvc, roomSvc)
+	controller := ctl.NewController(authSvc, userSvc)
+	movieController := ctl.NewMovieController(movieSvc, uploadHandler)
+	roomController := ctl.NewRoomController(roomSvc, guestCookies, storageProvider, cfg.Streaming)
+	emailController := ctl.NewEmailController(emailQueue)
+	webhookController := ctl.NewWebhookController(uploadHandler, cfg.Storage.NotificationToken)
+	streamingController := ctl.NewStreamingController(storageProvider, movieSvc, cfg.Streaming)
+	videoAccessController := ctl.NewVideoAccessController(storageProvider, movieSvc, roomSvc, cfg.Streaming)
 
 	// initialize middleware
 	middleware := mdw.NewMiddleware()
@@ -99,9 +152,17 @@ func NewAppServer(cfg *config.Config) *AppServer {
 		movieController:       movieController,
 		roomController:        roomController,
 		webhookController:     webhookController,
+		emailController:       emailController,
 		streamingController:   streamingController,
 		videoAccessController: videoAccessController,
 		roomService:           roomSvc,
+		movieService:          movieSvc,
+		uploadHandler:         uploadHandler,
+		storageProvider:       storageProvider,
+		redisClient:           redisClient,
+		emailQueue:            emailQueue,
+		jwtManager:            jwtManager,
+		guestCookies:          guestCookies,
 	}
 }
 
@@ -111,15 +172,53 @@ func (a *AppServer) Serve() {
 		Handler: a.RegisterHandlers(),
 	}
 
+	tlsConfig, err := a.config.TLS.Load()
+	if err != nil {
+		logger.Fatalf("invalid TLS configuration: %v", err)
+	}
+	server.TLSConfig = tlsConfig
+
 	// serve the server
 	go func() {
-		err := server.ListenAndServe()
+		var err error
+		if tlsConfig != nil {
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
 		if err != nil && err != http.ErrServerClosed {
 			log.Fatalf("server failed to start: %v", err)
 		}
 	}()
 
-	logger.Infof("server started on port %s", a.config.Port)
+	logger.Infof("server started on port %s (TLS: %v)", a.config.Port, tlsConfig != nil)
+
+	// persist host handoffs decided by service-sync
+	listenCtx, stopListening := context.WithCancel(context.Background())
+	defer stopListening()
+	go a.roomService.ListenForHostHandoffs(listenCtx)
+
+	// save where watched rooms are so they resume there after their live state expired
+	go a.roomService.RunPositionPersister(listenCtx)
+
+	// mark movies whose upload never arrived as failed
+	go a.movieService.RunUploadReaper(listenCtx)
+
+	// persist view counters collected in Redis
+	go a.movieService.RunAnalyticsFlusher(listenCtx)
+
+	// deliver queued emails with retries
+	go a.emailQueue.Run(listenCtx)
+
+	// start processing uploads as soon as storage reports them
+	if a.config.Storage.UploadNotifications {
+		notifier, ok := a.storageProvider.(storage.UploadNotifier)
+		if ok {
+			go events.ListenForUploads(listenCtx, a.uploadHandler, notifier)
+		} else {
+			logger.Infof("storage provider %s pushes upload notifications to /api/v1/webhooks/storage-notification", a.config.Storage.Provider)
+		}
+	}
 
 	a.gracefulShutdown(server)
 
@@ -142,6 +241,11 @@ func (a *AppServer) gracefulShutdown(server *http.Server) {
 			logger.Info("server graceful shutdown")
 		}
 
+		// close Redis only once in-flight requests finished, they may still publish events or read caches
+		if a.redisClient != nil {
+			a.redisClient.Close()
+		}
+
 		logger.Flush()
 
 		stopCtx()
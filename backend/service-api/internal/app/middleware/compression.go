@@ -0,0 +1,258 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"watch-party/pkg/config"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	encodingGzip   = "gzip"
+	encodingBrotli = "br"
+)
+
+// compressibleContentTypes lists the text-based media types worth compressing.
+// video segments and images are already compressed and are never listed here.
+var compressibleContentTypes = []string{
+	"text/",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/vnd.apple.mpegurl",
+	"application/x-mpegurl",
+	"audio/mpegurl",
+}
+
+// Compression negotiates Accept-Encoding and compresses text responses larger than the configured threshold
+func Compression(cfg config.CompressionConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.Enabled || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"), cfg.BrotliEnabled)
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		// responses differ by Accept-Encoding even when we end up not compressing
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		cw := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       encoding,
+			minSize:        cfg.MinSize,
+			level:          cfg.Level,
+		}
+		c.Writer = cw
+
+		defer func() {
+			cw.close()
+			c.Writer = cw.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding picks the preferred supported encoding from an Accept-Encoding header
+func negotiateEncoding(acceptEncoding string, brotliEnabled bool) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	best := ""
+	bestQ := 0.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, q := parseEncodingPart(part)
+		if q <= 0 {
+			continue
+		}
+
+		switch name {
+		case encodingBrotli:
+			if !brotliEnabled {
+				continue
+			}
+		case encodingGzip:
+		default:
+			continue
+		}
+
+		// prefer brotli on a tie since it compresses text better
+		if q > bestQ || (q == bestQ && name == encodingBrotli) {
+			best = name
+			bestQ = q
+		}
+	}
+
+	return best
+}
+
+// parseEncodingPart parses a single "name;q=value" entry from Accept-Encoding
+func parseEncodingPart(part string) (string, float64) {
+	fields := strings.Split(part, ";")
+	name := strings.ToLower(strings.TrimSpace(fields[0]))
+	q := 1.0
+
+	for _, param := range fields[1:] {
+		param = strings.TrimSpace(param)
+		if !strings.HasPrefix(param, "q=") {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+		if err != nil {
+			return name, 0
+		}
+		q = parsed
+	}
+
+	return name, q
+}
+
+// isCompressibleContentType reports whether the response content type should be compressed
+func isCompressibleContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range compressibleContentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter buffers the start of a response until it knows whether compression is worthwhile
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+	level    int
+
+	buf     bytes.Buffer
+	decided bool
+	encoder io.WriteCloser
+}
+
+// Write buffers data until the threshold is reached, then streams it through the encoder
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buf.Write(data)
+		if w.buf.Len() < w.minSize {
+			return len(data), nil
+		}
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString implements gin.ResponseWriter
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow is called for bodiless responses, so nothing will be compressed
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		w.decided = true
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush forces a decision so streamed responses are not held back
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide()
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide chooses between compressed and plain output and writes out the buffered data
+func (w *compressWriter) decide() error {
+	w.decided = true
+
+	if w.shouldCompress() {
+		header := w.ResponseWriter.Header()
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		w.encoder = w.newEncoder()
+	}
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+
+	var err error
+	if w.encoder != nil {
+		_, err = w.encoder.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	return err
+}
+
+// shouldCompress checks status, size and content type of the pending response
+func (w *compressWriter) shouldCompress() bool {
+	if w.buf.Len() < w.minSize || w.buf.Len() == 0 {
+		return false
+	}
+
+	status := w.ResponseWriter.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified ||
+		(status >= http.StatusMultipleChoices && status < http.StatusBadRequest) {
+		return false
+	}
+
+	header := w.ResponseWriter.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	return isCompressibleContentType(header.Get("Content-Type"))
+}
+
+// newEncoder creates the encoder for the negotiated encoding
+func (w *compressWriter) newEncoder() io.WriteCloser {
+	if w.encoding == encodingBrotli {
+		level := w.level
+		if level < brotli.BestSpeed || level > brotli.BestCompression {
+			level = brotli.DefaultCompression
+		}
+		return brotli.NewWriterLevel(w.ResponseWriter, level)
+	}
+
+	encoder, err := gzip.NewWriterLevel(w.ResponseWriter, w.level)
+	if err != nil {
+		encoder, _ = gzip.NewWriterLevel(w.ResponseWriter, gzip.DefaultCompression)
+	}
+	return encoder
+}
+
+// close flushes any buffered data and finalizes the encoder
+func (w *compressWriter) close() {
+	if !w.decided {
+		w.decide()
+	}
+	if w.encoder != nil {
+		w.encoder.Close()
+	}
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"watch-party/pkg/config"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// compressionBody is large enough to pass the threshold the tests configure
+var compressionBody = strings.Repeat("#EXTINF:4.000,\nsegment.ts\n", 64)
+
+// newCompressedRouter serves GET and HEAD /file behind the compression middleware, answering with the given
+// status and content type
+func newCompressedRouter(cfg config.CompressionConfig, status int, contentType string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Compression(cfg))
+	serve := func(c *gin.Context) {
+		if status == http.StatusNotModified {
+			c.Status(status)
+			return
+		}
+		c.Data(status, contentType, []byte(compressionBody))
+	}
+	router.GET("/file", serve)
+	router.HEAD("/file", serve)
+	return router
+}
+
+// decodeBody reads the response body back through the decoder of its Content-Encoding
+func decodeBody(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var reader io.Reader = w.Body
+	switch w.Header().Get("Content-Encoding") {
+	case encodingGzip:
+		gz, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		reader = gz
+	case encodingBrotli:
+		reader = brotli.NewReader(w.Body)
+	}
+
+	decoded, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(decoded)
+}
+
+func TestCompression(t *testing.T) {
+	enabled := config.CompressionConfig{Enabled: true, BrotliEnabled: true, MinSize: 64, Level: -1}
+	gzipOnly := config.CompressionConfig{Enabled: true, MinSize: 64, Level: -1}
+
+	tests := []struct {
+		name           string
+		cfg            config.CompressionConfig
+		method         string
+		acceptEncoding string
+		status         int
+		contentType    string
+		wantEncoding   string
+		wantVary       bool
+	}{
+		{"gzip", enabled, http.MethodGet, "gzip", http.StatusOK, "application/vnd.apple.mpegurl", encodingGzip, true},
+		{"brotli preferred on a tie", enabled, http.MethodGet, "gzip, br", http.StatusOK, "application/json", encodingBrotli, true},
+		{"higher q wins", enabled, http.MethodGet, "br;q=0.5, gzip;q=0.9", http.StatusOK, "text/plain", encodingGzip, true},
+		{"brotli disabled", gzipOnly, http.MethodGet, "br, gzip;q=0.5", http.StatusOK, "text/plain", encodingGzip, true},
+		{"q=0 refuses an encoding", enabled, http.MethodGet, "br;q=0, gzip", http.StatusOK, "text/plain", encodingGzip, true},
+		{"case and spacing", enabled, http.MethodGet, " GZIP ; q=1", http.StatusOK, "text/plain", encodingGzip, true},
+		{"no Accept-Encoding", enabled, http.MethodGet, "", http.StatusOK, "text/plain", "", false},
+		{"only unsupported encodings", enabled, http.MethodGet, "deflate, zstd", http.StatusOK, "text/plain", "", false},
+		{"video segments are already compressed", enabled, http.MethodGet, "gzip", http.StatusOK, "video/mp2t", "", true},
+		{"images are already compressed", enabled, http.MethodGet, "gzip", http.StatusOK, "image/jpeg", "", true},
+		{"not modified", enabled, http.MethodGet, "gzip", http.StatusNotModified, "text/plain", "", true},
+		{"head", enabled, http.MethodHead, "gzip", http.StatusOK, "text/plain", "", false},
+		{"disabled", config.CompressionConfig{MinSize: 64}, http.MethodGet, "gzip", http.StatusOK, "text/plain", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newCompressedRouter(tt.cfg, tt.status, tt.contentType)
+
+			req := httptest.NewRequest(tt.method, "/file", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.wantEncoding, w.Header().Get("Content-Encoding"))
+			if tt.wantVary {
+				assert.Contains(t, w.Header().Values("Vary"), "Accept-Encoding")
+			} else {
+				assert.NotContains(t, w.Header().Values("Vary"), "Accept-Encoding")
+			}
+
+			// the recorder keeps what a HEAD handler writes, a real server drops it
+			if tt.status == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			} else {
+				assert.Equal(t, compressionBody, decodeBody(t, w))
+			}
+		})
+	}
+}
+
+func TestCompressionSkipsSmallResponses(t *testing.T) {
+	router := newCompressedRouter(config.CompressionConfig{Enabled: true, MinSize: len(compressionBody) + 1, Level: -1},
+		http.StatusOK, "application/json")
+
+	req := httptest.NewRequest(http.MethodGet, "/file", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, compressionBody, w.Body.String())
+}
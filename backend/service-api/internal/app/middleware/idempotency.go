@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/redis"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// IdempotencyHeader is the request header that makes a POST safe to retry
+	IdempotencyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response that was stored for an earlier request with the same key
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	// idempotencyLockTTL bounds how long a request in progress holds its key, so a crashed instance frees it
+	idempotencyLockTTL = time.Minute
+)
+
+// idempotencyRecord is what is stored for a key, first while its request runs and then its response
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"` // hash of the request body, a retry must send the same one
+	Completed   bool   `json:"completed"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Idempotency makes a POST endpoint safe to retry. a request with an Idempotency-Key header runs once per key,
+// caller and endpoint; a retry within ttl gets the stored response with Idempotent-Replayed: true.
+// only successful responses are stored, a failed request frees the key so its retry runs again. reusing a key
+// with a different body is rejected with 422, a retry while the first request still runs gets 409.
+// requests without the header, and every request while Redis is unavailable, run as usual.
+func Idempotency(redisClient *redis.Client, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" || redisClient == nil {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key cannot be longer than 255 characters"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		redisKey := redis.IdempotencyKey(idempotencyCaller(c), hashHex(c.Request.Method+" "+c.FullPath()+"\n"+key))
+		fingerprint := hashHex(string(body))
+
+		pending, err := json.Marshal(&idempotencyRecord{Fingerprint: fingerprint})
+		if err != nil {
+			c.Next()
+			return
+		}
+		claimed, err := redisClient.SetNX(ctx, redisKey, pending, idempotencyLockTTL)
+		if err != nil {
+			logger.Errorf(err, "failed to claim idempotency key, handling request without it")
+			c.Next()
+			return
+		}
+
+		if !claimed {
+			var record idempotencyRecord
+			err = redisClient.Get(ctx, redisKey, &record)
+			if err != nil {
+				logger.Errorf(err, "failed to read idempotency key, handling request without it")
+				c.Next()
+				return
+			}
+			replayIdempotentResponse(c, &record, fingerprint)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		c.Writer = recorder.ResponseWriter
+
+		// the request is finished either way, a canceled client context must not keep the key locked
+		ctx = context.WithoutCancel(ctx)
+
+		status := recorder.Status()
+		if status < http.StatusOK || status >= http.StatusMultipleChoices {
+			err = redisClient.Delete(ctx, redisKey)
+			if err != nil {
+				logger.Errorf(err, "failed to release idempotency key after status %d", status)
+			}
+			return
+		}
+
+		err = redisClient.Set(ctx, redisKey, &idempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Status:      status,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}, ttl)
+		if err != nil {
+			logger.Errorf(err, "failed to store idempotent response")
+		}
+	}
+}
+
+// replayIdempotentResponse answers a request whose key was seen before
+func replayIdempotentResponse(c *gin.Context, record *idempotencyRecord, fingerprint string) {
+	switch {
+	case record.Fingerprint != fingerprint:
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used with a different request"})
+	case !record.Completed:
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "a request with this Idempotency-Key is still in progress"})
+	default:
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Data(record.Status, record.ContentType, record.Body)
+		c.Abort()
+	}
+}
+
+// idempotencyCaller identifies who made the request, keys of different users never collide
+func idempotencyCaller(c *gin.Context) string {
+	if userID, ok := c.Get("user_id"); ok {
+		if id, ok := userID.(uuid.UUID); ok {
+			return id.String()
+		}
+	}
+	return "anonymous"
+}
+
+func hashHex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// responseRecorder keeps a copy of the response body while writing it through
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write implements gin.ResponseWriter
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString implements gin.ResponseWriter
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
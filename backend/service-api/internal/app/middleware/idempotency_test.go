@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/redis"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newIdempotentRouter serves POST /rooms behind the idempotency middleware, counting the creations
+func newIdempotentRouter(t *testing.T, status *int) (*gin.Engine, *int) {
+	logger.InitLogger(&config.Config{})
+	gin.SetMode(gin.TestMode)
+	server := miniredis.RunT(t)
+
+	client, err := redis.NewClient(&config.Config{Redis: config.RedisConfig{Host: server.Host(), Port: server.Port()}})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	created := 0
+	router := gin.New()
+	router.POST("/rooms", Idempotency(client, time.Hour), func(c *gin.Context) {
+		created++
+		c.JSON(*status, gin.H{"room": created})
+	})
+	return router, &created
+}
+
+func postRoom(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/rooms", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency(t *testing.T) {
+	t.Run("replays the first response", func(t *testing.T) {
+		status := http.StatusCreated
+		router, created := newIdempotentRouter(t, &status)
+
+		first := postRoom(router, "retry-1", `{"name":"movie night"}`)
+		second := postRoom(router, "retry-1", `{"name":"movie night"}`)
+
+		assert.Equal(t, 1, *created)
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "true", second.Header().Get(IdempotentReplayedHeader))
+		assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+	})
+
+	t.Run("rejects a reused key with a different body", func(t *testing.T) {
+		status := http.StatusCreated
+		router, created := newIdempotentRouter(t, &status)
+
+		postRoom(router, "retry-1", `{"name":"movie night"}`)
+		w := postRoom(router, "retry-1", `{"name":"another night"}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, 1, *created)
+	})
+
+	t.Run("failed requests free the key", func(t *testing.T) {
+		status := http.StatusInternalServerError
+		router, created := newIdempotentRouter(t, &status)
+
+		postRoom(router, "retry-1", `{}`)
+		status = http.StatusCreated
+		w := postRoom(router, "retry-1", `{}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, 2, *created)
+	})
+
+	t.Run("requests without a key always run", func(t *testing.T) {
+		status := http.StatusCreated
+		router, created := newIdempotentRouter(t, &status)
+
+		postRoom(router, "", `{}`)
+		postRoom(router, "", `{}`)
+
+		assert.Equal(t, 2, *created)
+	})
+}
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"watch-party/pkg/auth"
@@ -14,7 +16,7 @@ import (
 
 // StreamingAuthMiddleware creates middleware for streaming endpoints that validates
 // user access to rooms containing the requested movie
-func StreamingAuthMiddleware(jwtManager *auth.JWTManager, roomSvc *roomService.Service) gin.HandlerFunc {
+func StreamingAuthMiddleware(jwtManager *auth.JWTManager, roomSvc *roomService.Service, guestCookies *auth.GuestCookieSigner) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		movieIDStr := c.Param("movieId")
 		if movieIDStr == "" {
@@ -30,13 +32,20 @@ func StreamingAuthMiddleware(jwtManager *auth.JWTManager, roomSvc *roomService.S
 			return
 		}
 
-		// try to authenticate via JWT token first
+		// every method is tried, a user signed in elsewhere may be watching as a guest; only missing
+		// or invalid credentials end in 401, valid ones without access to this movie in 403
+		var denied error
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader != "" {
-			if authenticateWithJWT(c, jwtManager, roomSvc, movieID) {
+			err := authenticateWithJWT(c, jwtManager, roomSvc, movieID)
+			if err == nil {
 				c.Next()
 				return
 			}
+			if !errors.Is(err, auth.ErrUnauthenticated) {
+				denied = err
+			}
 		}
 
 		// try guest session token as fallback
@@ -44,12 +53,24 @@ func StreamingAuthMiddleware(jwtManager *auth.JWTManager, roomSvc *roomService.S
 		if guestToken == "" {
 			guestToken = c.GetHeader("X-Guest-Token")
 		}
+		if guestToken == "" && guestCookies != nil {
+			guestToken = guestTokenFromCookie(c, guestCookies)
+		}
 
 		if guestToken != "" {
-			if authenticateWithGuestToken(c, roomSvc, movieID, guestToken) {
+			err := authenticateWithGuestToken(c, roomSvc, movieID, guestToken)
+			if err == nil {
 				c.Next()
 				return
 			}
+			if !errors.Is(err, auth.ErrUnauthenticated) && denied == nil {
+				denied = err
+			}
+		}
+
+		if denied != nil {
+			abortStreamingAccess(c, denied)
+			return
 		}
 
 		logger.Warn("streaming access denied: no valid authentication provided")
@@ -58,32 +79,42 @@ func StreamingAuthMiddleware(jwtManager *auth.JWTManager, roomSvc *roomService.S
 	}
 }
 
+// abortStreamingAccess ends a streaming request whose credentials didn't grant access, or couldn't be checked
+func abortStreamingAccess(c *gin.Context, err error) {
+	if errors.Is(err, auth.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	} else {
+		logger.Error(err, "failed to check streaming access")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate access"})
+	}
+	c.Abort()
+}
+
 // authenticateWithJWT validates JWT token and checks room access
-func authenticateWithJWT(c *gin.Context, jwtManager *auth.JWTManager, roomSvc *roomService.Service, movieID uuid.UUID) bool {
+func authenticateWithJWT(c *gin.Context, jwtManager *auth.JWTManager, roomSvc *roomService.Service, movieID uuid.UUID) error {
 	authHeader := c.GetHeader("Authorization")
 	bearerToken := strings.Split(authHeader, " ")
 	if len(bearerToken) != 2 || bearerToken[0] != "Bearer" {
-		return false
+		return auth.ErrUnauthenticated
 	}
 
 	tokenString := bearerToken[1]
 	claims, err := jwtManager.ValidateToken(tokenString)
 	if err != nil {
 		logger.Error(err, "invalid JWT token in streaming request")
-		return false
+		return fmt.Errorf("%w: %v", auth.ErrUnauthenticated, err)
 	}
 
 	// check if user has access to any room containing this movie
 	hasAccess, err := roomSvc.CheckUserMovieAccess(context.Background(), claims.UserID, movieID)
 	if err != nil {
-		logger.Error(err, "failed to check user movie access")
-		return false
+		return fmt.Errorf("failed to check user movie access: %w", err)
 	}
 
 	if !hasAccess {
 		logger.Warnf("user %s denied streaming access to movie %s - not in any authorized room",
 			claims.UserID, movieID)
-		return false
+		return fmt.Errorf("%w: user does not have access to this movie", auth.ErrForbidden)
 	}
 
 	// store user info in context
@@ -93,28 +124,27 @@ func authenticateWithJWT(c *gin.Context, jwtManager *auth.JWTManager, roomSvc *r
 	c.Set("user_role", claims.Role)
 	c.Set("auth_type", "jwt")
 
-	return true
+	return nil
 }
 
 // authenticateWithGuestToken validates guest session and checks room access
-func authenticateWithGuestToken(c *gin.Context, roomSvc *roomService.Service, movieID uuid.UUID, token string) bool {
+func authenticateWithGuestToken(c *gin.Context, roomSvc *roomService.Service, movieID uuid.UUID, token string) error {
 	session, err := roomSvc.ValidateGuestSession(context.Background(), token)
 	if err != nil {
 		logger.Error(err, "invalid guest token in streaming request")
-		return false
+		return fmt.Errorf("%w: invalid or expired guest token", auth.ErrUnauthenticated)
 	}
 
 	// check if the guest's room contains this movie
 	hasAccess, err := roomSvc.CheckRoomContainsMovie(context.Background(), session.RoomID, movieID)
 	if err != nil {
-		logger.Error(err, "failed to check room movie access for guest")
-		return false
+		return fmt.Errorf("failed to check room movie access for guest: %w", err)
 	}
 
 	if !hasAccess {
 		logger.Warnf("guest denied streaming access to movie %s - movie not in authorized room %s",
 			movieID, session.RoomID)
-		return false
+		return fmt.Errorf("%w: guest does not have access to this movie", auth.ErrForbidden)
 	}
 
 	// store guest info in context
@@ -123,5 +153,20 @@ func authenticateWithGuestToken(c *gin.Context, roomSvc *roomService.Service, mo
 	c.Set("guest_name", session.GuestName)
 	c.Set("auth_type", "guest")
 
-	return true
+	return nil
+}
+
+// guestTokenFromCookie returns the guest token of a signed guest cookie, empty when there is none or it was tampered with
+func guestTokenFromCookie(c *gin.Context, guestCookies *auth.GuestCookieSigner) string {
+	value, err := c.Cookie(auth.GuestCookieName)
+	if err != nil {
+		return ""
+	}
+
+	guestToken, ok := guestCookies.Verify(value)
+	if !ok {
+		logger.Warn("rejected guest cookie with an invalid signature")
+		return ""
+	}
+	return guestToken
 }
@@ -2,6 +2,7 @@ package app
 
 import (
 	"watch-party/pkg/auth"
+	"watch-party/pkg/config"
 	"watch-party/pkg/logger"
 	"watch-party/pkg/model"
 	middleware "watch-party/service-api/internal/app/middleware"
@@ -37,6 +38,7 @@ func (a *AppServer) RegisterHandlers() *gin.Engine {
 	handler.Use(cors.New(corsConfig))
 	handler.Use(gin.Logger())
 	handler.Use(gin.Recovery())
+	handler.Use(middleware.Compression(a.config.Compression))
 
 	handler.OPTIONS("/*path", func(c *gin.Context) {
 		origin := c.GetHeader("Origin")
@@ -49,17 +51,24 @@ func (a *AppServer) RegisterHandlers() *gin.Engine {
 			}
 		}
 		c.Header("Access-Control-Allow-Methods", "GET,POST,PUT,DELETE,OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type,Authorization,User-Agent,Sec-Ch-Ua,Sec-Ch-Ua-Mobile,Sec-Ch-Ua-Platform")
+		c.Header("Access-Control-Allow-Headers", "Content-Type,Authorization,User-Agent,Sec-Ch-Ua,Sec-Ch-Ua-Mobile,Sec-Ch-Ua-Platform,Idempotency-Key")
 		c.Header("Access-Control-Allow-Credentials", "true")
 		c.Header("Access-Control-Max-Age", "43200")
 		c.Status(200)
 	})
 
 	// create JWT middleware
-	jwtManager := auth.NewJWTManager(a.config.JWTSecret)
+	jwtManager := a.jwtManager
 	authMiddleware := auth.AuthMiddleware(jwtManager)
 	adminMiddleware := auth.RequireRole(model.RoleAdmin)
 
+	// retried creations with the same Idempotency-Key get the original response
+	idempotencyTTL := a.config.Idempotency.KeyTTL.ToDuration()
+	if idempotencyTTL <= 0 {
+		idempotencyTTL = config.DefaultIdempotencyKeyTTL
+	}
+	idempotency := middleware.Idempotency(a.redisClient, idempotencyTTL)
+
 	// health check
 	handler.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "healthy"})
@@ -81,6 +90,7 @@ func (a *AppServer) RegisterHandlers() *gin.Engine {
 		users := api.Group("/users")
 		{
 			users.POST("/register", a.controller.RegisterUser)
+			users.GET("/:id/avatar", a.controller.GetAvatar)
 		}
 	}
 
@@ -90,14 +100,37 @@ func (a *AppServer) RegisterHandlers() *gin.Engine {
 	adminRoutes.Use(adminMiddleware)
 	{
 		// movies management - admin only
-		adminRoutes.POST("/movies", a.movieController.UploadMovie)
+		adminRoutes.POST("/movies", idempotency, a.movieController.UploadMovie)
+		adminRoutes.POST("/movies/validate", a.movieController.ValidateUpload)
 		adminRoutes.GET("/movies", a.movieController.GetMovies)
 		adminRoutes.GET("/movies/:id", a.movieController.GetMovie)
 		adminRoutes.GET("/movies/:id/status", a.movieController.GetMovieStatus)
+		adminRoutes.POST("/movies/:id/retranscode-failed", a.movieController.RetranscodeFailedQualities)
+		adminRoutes.GET("/movies/:id/rooms", a.movieController.GetMovieRooms)
 		adminRoutes.PUT("/movies/:id", a.movieController.UpdateMovie)
 		adminRoutes.DELETE("/movies/:id", a.movieController.DeleteMovie)
+		adminRoutes.DELETE("/movies", a.movieController.DeleteMoviesByUploader)
+		adminRoutes.POST("/movies/bulk-delete", a.movieController.BulkDeleteMovies)
 		adminRoutes.GET("/movies/:id/stream", a.movieController.GetMovieStreamURL)
 		adminRoutes.GET("/my-movies", a.movieController.GetMyMovies)
+		adminRoutes.GET("/pipeline-health", a.movieController.GetPipelineHealth)
+
+		// moderation - admin only
+		adminRoutes.GET("/reports", a.roomController.GetReports)
+		adminRoutes.GET("/rooms/:id/diagnostics", a.roomController.GetRoomDiagnostics)
+		adminRoutes.POST("/rooms/:id/resync", a.roomController.ResyncRoom)
+		adminRoutes.DELETE("/rooms/:id/chat", a.roomController.ClearChatHistory)
+		adminRoutes.POST("/rooms/:id/announce", a.roomController.AnnounceToRoom)
+		adminRoutes.POST("/announce", a.roomController.AnnounceToAllRooms)
+
+		// storage debugging - admin only
+		adminRoutes.POST("/storage/inspect", a.streamingController.InspectStorageObject)
+
+		// forced logout - admin only
+		adminRoutes.POST("/users/:id/revoke-tokens", a.controller.RevokeUserTokens)
+
+		// outbound email queue
+		adminRoutes.GET("/emails", a.emailController.GetEmailQueue)
 	}
 
 	// authenticated user routes
@@ -106,17 +139,41 @@ func (a *AppServer) RegisterHandlers() *gin.Engine {
 	{
 		// user profile endpoint
 		userRoutes.GET("/profile", a.controller.GetProfile)
+		userRoutes.PATCH("/me", a.controller.UpdateProfile)
+		userRoutes.POST("/me/avatar", a.controller.UploadAvatar)
+
+		// movie analytics - uploader or admin
+		userRoutes.GET("/movies/:id/analytics", a.movieController.GetMovieAnalytics)
+
+		// subtitle tracks added after upload - uploader or admin
+		userRoutes.POST("/movies/:id/subtitles", a.movieController.UploadSubtitle)
+		userRoutes.GET("/movies/:id/subtitles", a.movieController.ListSubtitles)
+
+		// direct movie access without a room - uploader or admin
+		userRoutes.GET("/movies/:id/access", a.movieController.ListMovieAccess)
+		userRoutes.POST("/movies/:id/access", a.movieController.GrantMovieAccess)
+		userRoutes.DELETE("/movies/:id/access/:userId", a.movieController.RevokeMovieAccess)
 
 		// room management - authenticated users
-		userRoutes.POST("/rooms", a.roomController.CreateRoom)
+		userRoutes.POST("/rooms", idempotency, a.roomController.CreateRoom)
 		userRoutes.GET("/rooms", a.roomController.GetRooms)
+		userRoutes.GET("/rooms/summary", a.roomController.GetRoomSummaries)
 		userRoutes.GET("/rooms/:id", a.roomController.GetRoom)
+		userRoutes.GET("/rooms/:id/player-bootstrap", a.videoAccessController.GetPlayerBootstrap)
 		userRoutes.POST("/rooms/:id/invite", a.roomController.InviteUser)
+		userRoutes.POST("/rooms/:id/invite-batch", a.roomController.BatchInviteUsers)
+		userRoutes.POST("/rooms/:id/transfer-host", a.roomController.TransferHost)
+		userRoutes.POST("/rooms/:id/co-hosts", a.roomController.GrantCoHost)
+		userRoutes.DELETE("/rooms/:id/co-hosts/:userId", a.roomController.RevokeCoHost)
+		userRoutes.GET("/rooms/:id/settings", a.roomController.GetRoomSettings)
+		userRoutes.PATCH("/rooms/:id/settings", a.roomController.UpdateRoomSettings)
+		userRoutes.POST("/rooms/:id/report", a.roomController.ReportParticipant)
+		userRoutes.GET("/rooms/:id/transcript", a.roomController.GetRoomTranscript)
 		userRoutes.POST("/rooms/join", a.roomController.JoinRoom)
 		userRoutes.GET("/rooms/join", a.roomController.JoinRoomByToken)
 		userRoutes.GET("/rooms/join/:room_id", a.roomController.JoinRoomByID)
 
-		// guest management - host only
+		// guest management - host, co-host or admin
 		userRoutes.GET("/rooms/:id/guest-requests", a.roomController.GetPendingGuestRequests)
 		userRoutes.POST("/rooms/:id/guest-requests/:requestId/approve", a.roomController.ApproveGuestRequest)
 
@@ -134,6 +191,7 @@ func (a *AppServer) RegisterHandlers() *gin.Engine {
 		publicRoutes.POST("/rooms/:id/request-access", a.roomController.RequestGuestAccess)
 		publicRoutes.GET("/guest/validate/:token", a.roomController.ValidateGuestSession)
 		publicRoutes.GET("/guest-requests/:requestId/status", a.roomController.CheckGuestRequestStatus)
+		publicRoutes.GET("/guest-requests/:requestId/events", a.roomController.StreamGuestRequestStatus)
 	}
 
 	// guest protected routes (require guest token authentication)
@@ -143,6 +201,7 @@ func (a *AppServer) RegisterHandlers() *gin.Engine {
 	{
 		// guest access to room info (requires guest token)
 		guestRoutes.GET("/rooms/:id", a.roomController.GetRoomForGuest)
+		guestRoutes.GET("/rooms/:id/player-bootstrap", a.videoAccessController.GetPlayerBootstrap)
 	}
 
 	// webhook routes (no authentication required for external services)
@@ -150,10 +209,13 @@ func (a *AppServer) RegisterHandlers() *gin.Engine {
 	{
 		// upload completion webhooks
 		webhookRoutes.POST("/upload-complete", a.webhookController.HandleUploadComplete)
+
+		// storage notifications pushed by GCS Pub/Sub
+		webhookRoutes.POST("/storage-notification", a.webhookController.HandleStorageNotification)
 	}
 
 	// CDN-friendly video access routes (returns signed URLs)
-	streamingAuth := middleware.StreamingAuthMiddleware(jwtManager, a.roomService)
+	streamingAuth := middleware.StreamingAuthMiddleware(jwtManager, a.roomService, a.guestCookies)
 	videoRoutes := api.Group("/videos")
 	videoRoutes.Use(streamingAuth) // support both JWT and guest token authentication
 	{
@@ -161,6 +223,19 @@ func (a *AppServer) RegisterHandlers() *gin.Engine {
 		videoRoutes.POST("/:movieId/urls", a.videoAccessController.GetVideoFileURLs)
 		videoRoutes.GET("/:movieId/direct", a.videoAccessController.GetDirectVideoURL)
 		videoRoutes.POST("/:movieId/seek", a.videoAccessController.GetSegmentByTime)
+		videoRoutes.GET("/:movieId/:quality/segments", a.videoAccessController.GetVariantSegments)
+		videoRoutes.GET("/:movieId/subtitles", a.videoAccessController.GetSubtitles)
+		videoRoutes.GET("/:movieId/key", a.videoAccessController.GetSegmentKey)
+	}
+
+	// HLS served through the API, segments are proxied or redirected depending on the streaming mode
+	streamRoutes := api.Group("/stream")
+	streamRoutes.Use(streamingAuth)
+	{
+		streamRoutes.GET("/:movieId/master.m3u8", a.streamingController.ProxyMasterPlaylist)
+		streamRoutes.GET("/:movieId/:quality/playlist.m3u8", a.streamingController.ProxyQualityPlaylist)
+		streamRoutes.GET("/:movieId/:quality/:segment", a.streamingController.ProxyVideoSegment)
+		streamRoutes.GET("/:movieId/subtitles/:file", a.streamingController.ProxySubtitle)
 	}
 
 	return handler
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"watch-party/pkg/auth"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// movieAccessChecker is the part of the room service that decides who may stream a movie
+type movieAccessChecker interface {
+	ValidateGuestSession(ctx context.Context, token string) (*model.GuestSession, error)
+	CheckUserMovieAccess(ctx context.Context, userID uuid.UUID, movieID uuid.UUID) (bool, error)
+	CheckRoomContainsMovie(ctx context.Context, roomID uuid.UUID, movieID uuid.UUID) (bool, error)
+}
+
+// checkGuestMovieAccess returns the session of a guest token whose room shows the movie.
+// a token that isn't a live session is auth.ErrUnauthenticated, a session for another movie auth.ErrForbidden.
+func checkGuestMovieAccess(ctx context.Context, rooms movieAccessChecker, guestToken string, movieID uuid.UUID, now time.Time) (*model.GuestSession, error) {
+	if len(guestToken) < 32 {
+		return nil, fmt.Errorf("%w: invalid guest token format", auth.ErrUnauthenticated)
+	}
+
+	guestSession, err := rooms.ValidateGuestSession(ctx, guestToken)
+	if err != nil {
+		logger.Error(err, "failed to validate guest session")
+		return nil, fmt.Errorf("%w: invalid or expired guest token", auth.ErrUnauthenticated)
+	}
+
+	if now.After(guestSession.ExpiresAt) {
+		return nil, fmt.Errorf("%w: guest session expired", auth.ErrUnauthenticated)
+	}
+
+	hasAccess, err := rooms.CheckRoomContainsMovie(ctx, guestSession.RoomID, movieID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate movie access: %w", err)
+	}
+	if !hasAccess {
+		return nil, fmt.Errorf("%w: guest does not have access to this movie", auth.ErrForbidden)
+	}
+
+	return guestSession, nil
+}
+
+// checkUserMovieAccess verifies a signed-in user may stream the movie, a nil user is auth.ErrUnauthenticated
+func checkUserMovieAccess(ctx context.Context, rooms movieAccessChecker, userID *uuid.UUID, movieID uuid.UUID) error {
+	if userID == nil {
+		return auth.ErrUnauthenticated
+	}
+
+	hasAccess, err := rooms.CheckUserMovieAccess(ctx, *userID, movieID)
+	if err != nil {
+		return fmt.Errorf("failed to validate movie access: %w", err)
+	}
+	if !hasAccess {
+		return fmt.Errorf("%w: user does not have access to this movie", auth.ErrForbidden)
+	}
+
+	return nil
+}
+
+// contextUserID returns the signed-in user the auth middleware stored, nil for guests and anonymous requests
+func contextUserID(c *gin.Context) *uuid.UUID {
+	if userIDValue, exists := c.Get("user_id"); exists {
+		if uid, ok := userIDValue.(uuid.UUID); ok {
+			return &uid
+		}
+	}
+	return nil
+}
+
+// accessErrorStatus maps an access check error to its status: 401 without valid credentials,
+// 403 when valid credentials don't grant access, 500 when the check itself failed
+func accessErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, auth.ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, auth.ErrForbidden):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondAccessError answers a failed access check with the status of the failure
+func respondAccessError(c *gin.Context, err error) {
+	status := accessErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		logger.Error(err, "failed to check movie access")
+		c.JSON(status, gin.H{"error": "failed to validate access"})
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	// the access checks log rejected credentials
+	logger.InitLogger(&config.Config{})
+	os.Exit(m.Run())
+}
+
+// fakeAccessChecker grants access according to its fields
+type fakeAccessChecker struct {
+	sessions   map[string]*model.GuestSession
+	userAccess map[uuid.UUID]bool
+	roomMovies map[uuid.UUID]uuid.UUID
+	checkErr   error
+}
+
+func (f *fakeAccessChecker) ValidateGuestSession(ctx context.Context, token string) (*model.GuestSession, error) {
+	session, ok := f.sessions[token]
+	if !ok {
+		return nil, errors.New("invalid or expired guest session")
+	}
+	return session, nil
+}
+
+func (f *fakeAccessChecker) CheckUserMovieAccess(ctx context.Context, userID uuid.UUID, movieID uuid.UUID) (bool, error) {
+	if f.checkErr != nil {
+		return false, f.checkErr
+	}
+	return f.userAccess[userID], nil
+}
+
+func (f *fakeAccessChecker) CheckRoomContainsMovie(ctx context.Context, roomID uuid.UUID, movieID uuid.UUID) (bool, error) {
+	if f.checkErr != nil {
+		return false, f.checkErr
+	}
+	return f.roomMovies[roomID] == movieID, nil
+}
+
+func TestCheckGuestMovieAccess(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	movieID := uuid.New()
+	roomID := uuid.New()
+	otherRoomID := uuid.New()
+
+	validToken := strings.Repeat("a", 64)
+	expiredToken := strings.Repeat("b", 64)
+	otherRoomToken := strings.Repeat("c", 64)
+
+	checker := &fakeAccessChecker{
+		sessions: map[string]*model.GuestSession{
+			validToken:     {RoomID: roomID, ExpiresAt: now.Add(time.Hour)},
+			expiredToken:   {RoomID: roomID, ExpiresAt: now.Add(-time.Minute)},
+			otherRoomToken: {RoomID: otherRoomID, ExpiresAt: now.Add(time.Hour)},
+		},
+		roomMovies: map[uuid.UUID]uuid.UUID{roomID: movieID, otherRoomID: uuid.New()},
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		checkErr   error
+		wantStatus int
+	}{
+		{name: "malformed token", token: "short", wantStatus: http.StatusUnauthorized},
+		{name: "unknown token", token: strings.Repeat("d", 64), wantStatus: http.StatusUnauthorized},
+		{name: "expired session", token: expiredToken, wantStatus: http.StatusUnauthorized},
+		{name: "room without the movie", token: otherRoomToken, wantStatus: http.StatusForbidden},
+		{name: "check fails", token: validToken, checkErr: errors.New("connection refused"), wantStatus: http.StatusInternalServerError},
+		{name: "granted", token: validToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker.checkErr = tt.checkErr
+
+			session, err := checkGuestMovieAccess(context.Background(), checker, tt.token, movieID, now)
+			if tt.wantStatus == 0 {
+				assert.NoError(t, err)
+				assert.Equal(t, roomID, session.RoomID)
+				return
+			}
+			assert.Equal(t, tt.wantStatus, accessErrorStatus(err))
+		})
+	}
+}
+
+func TestCheckUserMovieAccess(t *testing.T) {
+	movieID := uuid.New()
+	allowedUser := uuid.New()
+	otherUser := uuid.New()
+
+	checker := &fakeAccessChecker{
+		userAccess: map[uuid.UUID]bool{allowedUser: true},
+	}
+
+	tests := []struct {
+		name       string
+		userID     *uuid.UUID
+		checkErr   error
+		wantStatus int
+	}{
+		{name: "not signed in", userID: nil, wantStatus: http.StatusUnauthorized},
+		{name: "no room with the movie", userID: &otherUser, wantStatus: http.StatusForbidden},
+		{name: "check fails", userID: &allowedUser, checkErr: errors.New("connection refused"), wantStatus: http.StatusInternalServerError},
+		{name: "granted", userID: &allowedUser},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker.checkErr = tt.checkErr
+
+			err := checkUserMovieAccess(context.Background(), checker, tt.userID, movieID)
+			if tt.wantStatus == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.wantStatus, accessErrorStatus(err))
+		})
+	}
+}
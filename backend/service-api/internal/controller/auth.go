@@ -2,10 +2,13 @@ package controller
 
 import (
 	"net/http"
+	"strings"
+	"watch-party/pkg/auth"
 	"watch-party/pkg/logger"
 	"watch-party/pkg/model"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // Login handles user authentication
@@ -51,7 +54,14 @@ func (ctrl *controller) Logout(c *gin.Context) {
 		return
 	}
 
-	err = ctrl.authService.Logout(req.RefreshToken)
+	// the access token is optional, clients that send it have it revoked right away
+	accessToken := ""
+	bearerToken := strings.Split(c.GetHeader("Authorization"), " ")
+	if len(bearerToken) == 2 && bearerToken[0] == "Bearer" {
+		accessToken = bearerToken[1]
+	}
+
+	err = ctrl.authService.Logout(req.RefreshToken, accessToken)
 	if err != nil {
 		logger.Error(err, "failed to logout user")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
@@ -62,6 +72,32 @@ func (ctrl *controller) Logout(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "logged out successfully"})
 }
 
+// RevokeUserTokens logs a user out of every session (admin only)
+func (ctrl *controller) RevokeUserTokens(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	err = ctrl.authService.RevokeUserTokens(userID)
+	if err != nil {
+		logger.Error(err, "failed to revoke user tokens")
+		switch err.Error() {
+		case "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case "token revocation is not available":
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "token revocation is not available"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	logger.Infof("revoked all tokens of user %s", userID)
+	c.JSON(http.StatusOK, gin.H{"message": "all sessions of the user have been revoked"})
+}
+
 // GetProfile returns the current user's profile
 func (ctrl *controller) GetProfile(c *gin.Context) {
 	// get user from context (set by auth middleware)
@@ -71,12 +107,23 @@ func (ctrl *controller) GetProfile(c *gin.Context) {
 		return
 	}
 
-	user, ok := userValue.(*model.User)
+	claims, ok := userValue.(*auth.JWTClaims)
 	if !ok {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user context"})
 		return
 	}
 
+	user, err := ctrl.userService.GetUserByID(claims.UserID)
+	if err != nil {
+		logger.Error(err, "failed to get user profile")
+		if err.Error() == "user not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"user": user.ToProfile(),
 	})
@@ -2,6 +2,7 @@ package controller
 
 import (
 	authService "watch-party/service-api/internal/service/auth"
+	userService "watch-party/service-api/internal/service/user"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,17 +13,23 @@ type ControllerProvider interface {
 	RegisterUser(c *gin.Context)
 	Login(c *gin.Context)
 	Logout(c *gin.Context)
+	RevokeUserTokens(c *gin.Context)
 	GetProfile(c *gin.Context)
+	UpdateProfile(c *gin.Context)
+	UploadAvatar(c *gin.Context)
+	GetAvatar(c *gin.Context)
 }
 
 // controller implements the controller interface
 type controller struct {
 	authService authService.Service
+	userService userService.Service
 }
 
 // NewController creates a new controller instance
-func NewController(authService authService.Service) ControllerProvider {
+func NewController(authService authService.Service, userService userService.Service) ControllerProvider {
 	return &controller{
 		authService: authService,
+		userService: userService,
 	}
 }
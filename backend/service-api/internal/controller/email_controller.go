@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"watch-party/pkg/email"
+	"watch-party/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultEmailQueueLimit = 50
+	maxEmailQueueLimit     = 500
+)
+
+// EmailController exposes the outbound email queue to admins
+type EmailController struct {
+	queue *email.Queue
+}
+
+// NewEmailController creates a new email controller
+func NewEmailController(queue *email.Queue) *EmailController {
+	return &EmailController{
+		queue: queue,
+	}
+}
+
+// GetEmailQueue handles GET /api/v1/admin/emails - lists pending and failed outbound emails
+func (ec *EmailController) GetEmailQueue(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultEmailQueueLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultEmailQueueLimit
+	}
+	limit = min(limit, maxEmailQueueLimit)
+
+	response, err := ec.queue.Snapshot(c.Request.Context(), limit)
+	if err != nil {
+		logger.Error(err, "failed to read email queue")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read email queue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// guestRequestStreamMaxDuration bounds one status stream, a guest still waiting after it reconnects
+	guestRequestStreamMaxDuration = 5 * time.Minute
+	// guestRequestStreamHeartbeat is how often a waiting stream re-reads the request and sends a keepalive
+	guestRequestStreamHeartbeat = 15 * time.Second
+)
+
+// StreamGuestRequestStatus handles GET /api/v1/guest-requests/:requestId/events (public endpoint).
+// it sends the request status as a server-sent "status" event, then again once the request is approved or denied.
+// a stream still pending after guestRequestStreamMaxDuration ends with a "timeout" event.
+func (rc *RoomController) StreamGuestRequestStatus(c *gin.Context) {
+	requestID, err := uuid.Parse(c.Param("requestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID format"})
+		return
+	}
+	ctx := c.Request.Context()
+
+	// subscribe before reading the status so a review in between is not lost
+	changes, stop, err := rc.roomService.WatchGuestRequest(ctx, requestID)
+	switch {
+	case err == nil:
+		defer stop()
+	case err.Error() == "request not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
+		return
+	case err.Error() == "real-time updates unavailable":
+		// without Redis the request is only re-read on heartbeats
+	default:
+		logger.Errorf(err, "failed to watch guest request %s, re-reading it on heartbeats", requestID)
+	}
+
+	status, sessionToken, expiresAt, err := rc.roomService.CheckGuestRequestStatus(ctx, requestID)
+	if err != nil {
+		if err.Error() == "request not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent("status", guestRequestStatusBody(status, sessionToken, expiresAt))
+	c.Writer.Flush()
+	if status != model.GuestStatusPending {
+		return
+	}
+
+	deadline := time.NewTimer(guestRequestStreamMaxDuration)
+	defer deadline.Stop()
+	heartbeat := time.NewTicker(guestRequestStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// client disconnected
+			return
+		case <-deadline.C:
+			c.SSEvent("timeout", gin.H{"status": status})
+			c.Writer.Flush()
+			return
+		case _, ok := <-changes:
+			if !ok {
+				// the subscription ended, keep going on heartbeats
+				changes = nil
+				continue
+			}
+		case <-heartbeat.C:
+		}
+
+		status, sessionToken, expiresAt, err = rc.roomService.CheckGuestRequestStatus(ctx, requestID)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Errorf(err, "failed to check guest request %s", requestID)
+			}
+			return
+		}
+
+		if status == model.GuestStatusPending {
+			// a comment line keeps proxies from closing an idle stream
+			fmt.Fprint(c.Writer, ": keepalive\n\n")
+			c.Writer.Flush()
+			continue
+		}
+
+		c.SSEvent("status", guestRequestStatusBody(status, sessionToken, expiresAt))
+		c.Writer.Flush()
+		return
+	}
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	movieService "watch-party/service-api/internal/service/movie"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GrantMovieAccess handles POST /api/v1/movies/:id/access - uploader or admin
+func (mc *MovieController) GrantMovieAccess(c *gin.Context) {
+	userID := contextUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	movieID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
+		return
+	}
+
+	var req model.GrantMovieAccessRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload"})
+		return
+	}
+
+	isAdmin := c.GetString("user_role") == model.RoleAdmin
+	grant, err := mc.movieService.GrantMovieAccess(c.Request.Context(), movieID, *userID, isAdmin, &req)
+	if err != nil {
+		respondMovieAccessError(c, err, "failed to grant movie access")
+		return
+	}
+
+	c.JSON(http.StatusOK, grant)
+}
+
+// RevokeMovieAccess handles DELETE /api/v1/movies/:id/access/:userId - uploader or admin
+func (mc *MovieController) RevokeMovieAccess(c *gin.Context) {
+	userID := contextUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	movieID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
+		return
+	}
+
+	targetID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	isAdmin := c.GetString("user_role") == model.RoleAdmin
+	err = mc.movieService.RevokeMovieAccess(c.Request.Context(), movieID, targetID, *userID, isAdmin)
+	if err != nil {
+		respondMovieAccessError(c, err, "failed to revoke movie access")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "movie access revoked"})
+}
+
+// ListMovieAccess handles GET /api/v1/movies/:id/access - uploader or admin
+func (mc *MovieController) ListMovieAccess(c *gin.Context) {
+	userID := contextUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	movieID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
+		return
+	}
+
+	isAdmin := c.GetString("user_role") == model.RoleAdmin
+	grants, err := mc.movieService.ListMovieAccess(c.Request.Context(), movieID, *userID, isAdmin)
+	if err != nil {
+		respondMovieAccessError(c, err, "failed to list movie access")
+		return
+	}
+
+	c.JSON(http.StatusOK, grants)
+}
+
+// respondMovieAccessError maps the errors of the movie access endpoints to their status codes
+func respondMovieAccessError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, movieService.ErrMovieNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
+	case errors.Is(err, movieService.ErrUserNotFound), errors.Is(err, movieService.ErrGrantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, movieService.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+	case errors.Is(err, movieService.ErrInvalidAccessGrant):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		logger.Error(err, message)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
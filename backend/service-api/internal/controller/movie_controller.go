@@ -1,9 +1,11 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
+	"watch-party/pkg/events"
 	"watch-party/pkg/logger"
 	"watch-party/pkg/model"
 	movieService "watch-party/service-api/internal/service/movie"
@@ -14,13 +16,15 @@ import (
 
 // MovieController handles movie-related HTTP requests
 type MovieController struct {
-	movieService movieService.Service
+	movieService  movieService.Service
+	uploadHandler events.Handler
 }
 
 // NewMovieController creates a new movie controller
-func NewMovieController(movieService movieService.Service) *MovieController {
+func NewMovieController(movieService movieService.Service, uploadHandler events.Handler) *MovieController {
 	return &MovieController{
-		movieService: movieService,
+		movieService:  movieService,
+		uploadHandler: uploadHandler,
 	}
 }
 
@@ -53,11 +57,7 @@ func (mc *MovieController) UploadMovie(c *gin.Context) {
 	if err != nil {
 		logger.Error(err, "failed to initiate movie upload")
 
-		if err.Error() == "unsupported video format" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported video format"})
-			return
-		}
-		if strings.Contains(err.Error(), "file size too large") {
+		if errors.Is(err, movieService.ErrUnsupportedFormat) || errors.Is(err, movieService.ErrFileTooLarge) || strings.Contains(err.Error(), "unsupported mime type") {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
@@ -70,11 +70,23 @@ func (mc *MovieController) UploadMovie(c *gin.Context) {
 	c.JSON(http.StatusCreated, response)
 }
 
+// ValidateUpload handles a dry-run of upload validation without creating a movie - ADMIN ONLY
+func (mc *MovieController) ValidateUpload(c *gin.Context) {
+	var req model.ValidateUploadRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		logger.Error(err, "failed to bind validate upload request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, mc.movieService.ValidateUpload(c.Request.Context(), &req))
+}
+
 // GetMovies handles listing all movies - ADMIN ONLY
 func (mc *MovieController) GetMovies(c *gin.Context) {
 	// Parse pagination parameters
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize := parsePagination(c)
 
 	response, err := mc.movieService.GetMovies(c.Request.Context(), page, pageSize)
 	if err != nil {
@@ -172,6 +184,28 @@ func (mc *MovieController) DeleteMovie(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "movie deleted successfully"})
 }
 
+// GetMovieRooms handles listing the rooms that play a movie, so deleting it does not end them by surprise - ADMIN ONLY
+func (mc *MovieController) GetMovieRooms(c *gin.Context) {
+	movieID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
+		return
+	}
+
+	rooms, err := mc.movieService.GetMovieRooms(c.Request.Context(), movieID)
+	if err != nil {
+		if errors.Is(err, movieService.ErrMovieNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
+			return
+		}
+		logger.Error(err, "failed to get movie rooms")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get movie rooms"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rooms)
+}
+
 // GetMovieStreamURL handles getting a stream URL for a movie - ADMIN ONLY
 func (mc *MovieController) GetMovieStreamURL(c *gin.Context) {
 	// parse movie ID
@@ -214,8 +248,7 @@ func (mc *MovieController) GetMyMovies(c *gin.Context) {
 	}
 
 	// Parse pagination parameters
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize := parsePagination(c)
 
 	response, err := mc.movieService.GetMoviesByUploader(c.Request.Context(), userID, page, pageSize)
 	if err != nil {
@@ -251,3 +284,118 @@ func (mc *MovieController) GetMovieStatus(c *gin.Context) {
 
 	c.JSON(http.StatusOK, status)
 }
+
+// RetranscodeFailedQualities handles POST /admin/movies/:id/retranscode-failed - ADMIN ONLY.
+// the qualities a movie was published without are transcoded in the background while it stays watchable
+func (mc *MovieController) RetranscodeFailedQualities(c *gin.Context) {
+	movieID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
+		return
+	}
+
+	qualities, err := mc.uploadHandler.RetranscodeFailedQualities(c.Request.Context(), movieID)
+	if err != nil {
+		switch {
+		case errors.Is(err, events.ErrMovieNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
+		case errors.Is(err, events.ErrNoFailedQualities), errors.Is(err, events.ErrRetranscodeInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			logger.Error(err, "failed to start retranscoding failed qualities")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start retranscoding"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":   "retranscoding started",
+		"qualities": qualities,
+	})
+}
+
+// GetMovieAnalytics handles GET /api/v1/movies/:id/analytics - uploader or admin
+func (mc *MovieController) GetMovieAnalytics(c *gin.Context) {
+	// get user ID from context (set by auth middleware)
+	userIDValue, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, ok := userIDValue.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// get movie ID from URL parameter
+	movieIDStr := c.Param("id")
+	movieID, err := uuid.Parse(movieIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
+		return
+	}
+
+	isAdmin := c.GetString("user_role") == model.RoleAdmin
+
+	analytics, err := mc.movieService.GetMovieAnalytics(c.Request.Context(), movieID, userID, isAdmin)
+	if err != nil {
+		switch err {
+		case movieService.ErrMovieNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
+		case movieService.ErrAccessDenied:
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		default:
+			logger.Error(err, "failed to get movie analytics")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get movie analytics"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
+
+// BulkDeleteMovies handles POST /api/v1/admin/movies/bulk-delete - ADMIN ONLY
+func (mc *MovieController) BulkDeleteMovies(c *gin.Context) {
+	var req model.BulkDeleteMoviesRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload"})
+		return
+	}
+
+	mc.bulkDeleteMovies(c, &req)
+}
+
+// DeleteMoviesByUploader handles DELETE /api/v1/admin/movies?uploader=<id>&force=<bool> - ADMIN ONLY
+func (mc *MovieController) DeleteMoviesByUploader(c *gin.Context) {
+	uploaderID, err := uuid.Parse(c.Query("uploader"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "uploader query parameter must be a user ID"})
+		return
+	}
+
+	force, _ := strconv.ParseBool(c.Query("force"))
+	mc.bulkDeleteMovies(c, &model.BulkDeleteMoviesRequest{
+		UploaderID: &uploaderID,
+		Force:      force,
+	})
+}
+
+// bulkDeleteMovies runs a bulk delete and reports the outcome per movie
+func (mc *MovieController) bulkDeleteMovies(c *gin.Context, req *model.BulkDeleteMoviesRequest) {
+	response, err := mc.movieService.BulkDeleteMovies(c.Request.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, movieService.ErrNoMoviesSelected), errors.Is(err, movieService.ErrBulkDeleteTooLarge):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			logger.Error(err, "failed to bulk delete movies")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete movies"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+	"watch-party/pkg/model"
+	movieService "watch-party/service-api/internal/service/movie"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// transcodeBytesPerSecond is a rough rate of original bytes the transcoder works through, all renditions included
+	transcodeBytesPerSecond = 2 * 1024 * 1024
+	// minRetryAfter keeps clients from polling a movie that is still being prepared in a tight loop
+	minRetryAfter = 10 * time.Second
+	// maxRetryAfter keeps a poor estimate from parking clients for too long
+	maxRetryAfter = 5 * time.Minute
+)
+
+// movieRetryable reports whether a movie that isn't available may still become available
+func movieRetryable(status model.MovieStatus) bool {
+	return status == model.StatusProcessing || status == model.StatusTranscoding
+}
+
+// estimateMovieReady guesses when a transcoding movie becomes available from its size and when transcoding started,
+// returning false when there is nothing to base the guess on
+func estimateMovieReady(movie *model.Movie) (time.Time, bool) {
+	if movie.Status != model.StatusTranscoding || movie.ProcessingStartedAt == nil || movie.FileSize <= 0 {
+		return time.Time{}, false
+	}
+
+	expected := time.Duration(float64(movie.FileSize) / transcodeBytesPerSecond * float64(time.Second))
+	return movie.ProcessingStartedAt.Add(expected), true
+}
+
+// movieRetryAfter returns how long a client should wait before asking for a movie that is still being prepared
+func movieRetryAfter(movie *model.Movie, now time.Time) time.Duration {
+	readyAt, ok := estimateMovieReady(movie)
+	if !ok {
+		return minRetryAfter
+	}
+	return min(max(readyAt.Sub(now), minRetryAfter), maxRetryAfter)
+}
+
+// respondMovieNotReady tells the client why a movie can't be streamed yet and whether asking again can help
+func respondMovieNotReady(c *gin.Context, movie *model.Movie) {
+	retryable := movieRetryable(movie.Status)
+	response := gin.H{
+		"error":     "video not ready",
+		"status":    movie.Status,
+		"retryable": retryable,
+	}
+
+	if retryable {
+		retryAfter := movieRetryAfter(movie, time.Now())
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		response["retry_after_seconds"] = seconds
+		c.Header("Retry-After", strconv.Itoa(seconds))
+
+		if readyAt, ok := estimateMovieReady(movie); ok {
+			response["estimated_ready_at"] = readyAt.UTC()
+		}
+	}
+
+	c.JSON(http.StatusConflict, response)
+}
+
+// respondMovieNotFound answers a request for a missing movie, telling players apart a movie that was deleted
+// while they watched it from one that never existed
+func respondMovieNotFound(c *gin.Context, movies movieService.Service, movieID uuid.UUID) {
+	if movies.IsMovieRemoved(c.Request.Context(), movieID) {
+		c.JSON(http.StatusGone, gin.H{"error": "movie removed", "code": "movie_removed"})
+		return
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"watch-party/pkg/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMovieRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	startedAt := now.Add(-time.Minute)
+
+	tests := []struct {
+		name  string
+		movie *model.Movie
+		want  time.Duration
+	}{
+		{
+			name:  "upload still processing",
+			movie: &model.Movie{Status: model.StatusProcessing},
+			want:  minRetryAfter,
+		},
+		{
+			name:  "transcoding with estimate",
+			movie: &model.Movie{Status: model.StatusTranscoding, ProcessingStartedAt: &startedAt, FileSize: 3 * 60 * transcodeBytesPerSecond},
+			want:  2 * time.Minute,
+		},
+		{
+			name:  "transcoding past estimate",
+			movie: &model.Movie{Status: model.StatusTranscoding, ProcessingStartedAt: &startedAt, FileSize: transcodeBytesPerSecond},
+			want:  minRetryAfter,
+		},
+		{
+			name:  "transcoding large file",
+			movie: &model.Movie{Status: model.StatusTranscoding, ProcessingStartedAt: &startedAt, FileSize: 3600 * transcodeBytesPerSecond},
+			want:  maxRetryAfter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, movieRetryAfter(tt.movie, now))
+		})
+	}
+}
+
+func TestRespondMovieNotReady(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		status        model.MovieStatus
+		wantRetryable bool
+	}{
+		{name: "processing", status: model.StatusProcessing, wantRetryable: true},
+		{name: "transcoding", status: model.StatusTranscoding, wantRetryable: true},
+		{name: "failed", status: model.StatusFailed, wantRetryable: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+
+			respondMovieNotReady(c, &model.Movie{Status: tt.status})
+
+			assert.Equal(t, http.StatusConflict, recorder.Code)
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			assert.Equal(t, string(tt.status), body["status"])
+			assert.Equal(t, tt.wantRetryable, body["retryable"])
+
+			// only movies that can still become available tell clients when to come back
+			_, hasRetryAfter := body["retry_after_seconds"]
+			assert.Equal(t, tt.wantRetryable, hasRetryAfter)
+			assert.Equal(t, tt.wantRetryable, recorder.Header().Get("Retry-After") != "")
+		})
+	}
+}
+
+func TestPlayerMovieInfo(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	startedAt := now.Add(-time.Minute)
+
+	available := playerMovieInfo(&model.Movie{Status: model.StatusAvailable}, now)
+	assert.True(t, available.Available)
+	assert.False(t, available.Retryable)
+	assert.Zero(t, available.RetryAfterSeconds)
+
+	transcoding := playerMovieInfo(&model.Movie{
+		Status:              model.StatusTranscoding,
+		ProcessingStartedAt: &startedAt,
+		FileSize:            3 * 60 * transcodeBytesPerSecond,
+	}, now)
+	assert.False(t, transcoding.Available)
+	assert.True(t, transcoding.Retryable)
+	assert.Equal(t, 120, transcoding.RetryAfterSeconds)
+	require.NotNil(t, transcoding.EstimatedReadyAt)
+	assert.Equal(t, now.Add(2*time.Minute), *transcoding.EstimatedReadyAt)
+
+	failed := playerMovieInfo(&model.Movie{Status: model.StatusFailed}, now)
+	assert.False(t, failed.Available)
+	assert.False(t, failed.Retryable)
+	assert.Nil(t, failed.EstimatedReadyAt)
+}
@@ -0,0 +1,18 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePagination reads page and page_size query parameters, a cursor from a previous page takes precedence over page
+func parsePagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if cursor := c.Query("cursor"); cursor != "" {
+		page, _ = strconv.Atoi(cursor)
+	}
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	return page, pageSize
+}
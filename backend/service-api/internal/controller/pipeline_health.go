@@ -0,0 +1,25 @@
+package controller
+
+import (
+	"net/http"
+	"watch-party/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPipelineHealth handles GET /api/v1/admin/pipeline-health - ADMIN ONLY.
+// status counts, stuck transcodes and the average transcode time come from the database, so they cover every
+// instance. transcodes in flight and the scratch directory are those of the instance answering
+func (mc *MovieController) GetPipelineHealth(c *gin.Context) {
+	health, err := mc.movieService.GetPipelineHealth(c.Request.Context())
+	if err != nil {
+		logger.Error(err, "failed to get pipeline health")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get pipeline health"})
+		return
+	}
+
+	health.InFlight = mc.uploadHandler.InFlightTranscodes()
+	health.TempDir = mc.uploadHandler.TempDirUsage()
+
+	c.JSON(http.StatusOK, health)
+}
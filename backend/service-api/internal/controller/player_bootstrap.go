@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// playerURLExpiry is how long the stream URL of a player bootstrap stays valid, matching the HLS endpoint
+const playerURLExpiry = 2 * time.Hour
+
+// GetPlayerBootstrap handles GET /api/v1/rooms/:id/player-bootstrap for members and
+// GET /api/v1/guest/rooms/:id/player-bootstrap for guests. it returns the room, the movie's availability,
+// the master playlist URL, the live room state and participants in one response.
+func (vac *VideoAccessController) GetPlayerBootstrap(c *gin.Context) {
+	roomID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid room ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var room *model.RoomWithDetails
+	var guestSession *model.GuestSession
+	var viewerID string
+	if session, ok := c.Get("guestSession"); ok {
+		// the guest middleware already checked the token against this room
+		guestSession, _ = session.(*model.GuestSession)
+	}
+	if guestSession != nil {
+		viewerID = "guest:" + guestSession.ID.String()
+		room, err = vac.roomService.GetRoomDetails(ctx, roomID)
+	} else {
+		userID := contextUserID(c)
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+			return
+		}
+		viewerID = userID.String()
+		room, err = vac.roomService.GetRoom(ctx, *userID, roomID)
+	}
+	if err != nil {
+		switch {
+		case err.Error() == "access denied":
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		case err.Error() == "room not found", errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
+		default:
+			logger.Error(err, "failed to get room for player bootstrap")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get room"})
+		}
+		return
+	}
+
+	now := time.Now()
+	response := &model.PlayerBootstrapResponse{
+		Room: model.PlayerRoomInfo{
+			ID:          room.ID,
+			Name:        room.Name,
+			Description: room.Description,
+			HostID:      room.HostID,
+			Settings:    room.Settings,
+		},
+		Movie:        playerMovieInfo(&room.Movie, now),
+		Participants: []model.ParticipantInfo{},
+		GeneratedAt:  now,
+	}
+
+	if response.Movie.Available {
+		hlsURL, err := vac.masterPlaylistURL(c, room.MovieID)
+		if err != nil {
+			logger.Error(err, "failed to generate signed URL for player bootstrap")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate video access URL"})
+			return
+		}
+
+		mode := vac.streaming.EffectiveMode()
+		// guests reach this route with the guest middleware, so the stream URL doesn't carry their token yet
+		if guestSession != nil && mode != config.StreamingModeDirect {
+			hlsURL += "?token=" + url.QueryEscape(guestSession.SessionToken)
+		}
+
+		response.Stream = &model.PlayerStreamInfo{
+			HLSURL:        hlsURL,
+			StreamingMode: mode,
+			Qualities:     room.Movie.Qualities(),
+			ExpiresAt:     now.Add(playerURLExpiry),
+		}
+
+		// handing out the master playlist starts a stream session, as on the HLS endpoint
+		vac.movieService.RecordView(ctx, room.MovieID, viewerID)
+	}
+
+	// the live parts are best effort, the player also gets them once it joins over WebSocket
+	response.State, err = vac.roomService.GetLiveRoomState(ctx, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to get live state of room %s for player bootstrap", roomID)
+	}
+	participants, err := vac.roomService.GetLiveParticipants(ctx, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to get participants of room %s for player bootstrap", roomID)
+	} else {
+		response.Participants = participants
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, response)
+}
+
+// playerMovieInfo describes a movie's availability the way the streaming endpoints answer while it isn't ready
+func playerMovieInfo(movie *model.Movie, now time.Time) model.PlayerMovieInfo {
+	info := model.PlayerMovieInfo{
+		ID:          movie.ID,
+		Title:       movie.Title,
+		Description: movie.Description,
+		Status:      movie.Status,
+		Available:   movie.Status == model.StatusAvailable,
+	}
+	if info.Available {
+		return info
+	}
+
+	info.Retryable = movieRetryable(movie.Status)
+	if info.Retryable {
+		info.RetryAfterSeconds = int(math.Ceil(movieRetryAfter(movie, now).Seconds()))
+		if readyAt, ok := estimateMovieReady(movie); ok {
+			readyAt = readyAt.UTC()
+			info.EstimatedReadyAt = &readyAt
+		}
+	}
+	return info
+}
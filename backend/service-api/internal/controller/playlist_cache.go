@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"watch-party/pkg/model"
+)
+
+// segmentCacheControl is sent for HLS segments, they never change once written
+const segmentCacheControl = "public, max-age=86400"
+
+// playlistETag identifies a playlist as served to one viewer. the playlists of a movie only change when it is
+// processed again or its fast preview ladder is completed, and the served copy embeds the viewer's token
+func playlistETag(movie *model.Movie, file, authHash string) string {
+	version := movie.CreatedAt
+	if movie.ProcessingEndedAt != nil {
+		version = *movie.ProcessingEndedAt
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%s:%d:%t:%s", movie.ID, file, version.UnixNano(), movie.FastPreview, authHash)))
+	return `W/"` + hex.EncodeToString(sum[:12]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, using the weak comparison GET requests get
+func etagMatches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// hlsCacheControl returns the Cache-Control of an HLS file, short for playlists and long for segments
+func hlsCacheControl(file string, playlistMaxAge time.Duration) string {
+	if strings.HasSuffix(file, ".m3u8") {
+		return fmt.Sprintf("public, max-age=%d", int(playlistMaxAge.Seconds()))
+	}
+	return segmentCacheControl
+}
+
+// splitPlaylistFiles separates the playlists of a batch from its segments so each gets its own cache lifetime
+func splitPlaylistFiles(files []string) [2][]string {
+	var split [2][]string
+	for _, file := range files {
+		if strings.HasSuffix(file, ".m3u8") {
+			split[0] = append(split[0], file)
+		} else {
+			split[1] = append(split[1], file)
+		}
+	}
+	return split
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"testing"
+	"time"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlaylistETag(t *testing.T) {
+	processedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	movie := &model.Movie{ID: uuid.New(), CreatedAt: processedAt.Add(-time.Hour), ProcessingEndedAt: &processedAt}
+	etag := playlistETag(movie, "master.m3u8", "viewer")
+
+	assert.Equal(t, etag, playlistETag(movie, "master.m3u8", "viewer"))
+	assert.NotEqual(t, etag, playlistETag(movie, "720p/playlist.m3u8", "viewer"))
+	assert.NotEqual(t, etag, playlistETag(movie, "master.m3u8", "another viewer"))
+
+	reprocessedAt := processedAt.Add(time.Minute)
+	reprocessed := *movie
+	reprocessed.ProcessingEndedAt = &reprocessedAt
+	assert.NotEqual(t, etag, playlistETag(&reprocessed, "master.m3u8", "viewer"))
+
+	fastPreview := *movie
+	fastPreview.FastPreview = true
+	assert.NotEqual(t, etag, playlistETag(&fastPreview, "master.m3u8", "viewer"))
+}
+
+func TestEtagMatches(t *testing.T) {
+	etag := `W/"abc"`
+
+	assert.True(t, etagMatches(`W/"abc"`, etag))
+	assert.True(t, etagMatches(`"abc"`, etag))
+	assert.True(t, etagMatches(`"old", W/"abc"`, etag))
+	assert.True(t, etagMatches("*", etag))
+	assert.False(t, etagMatches("", etag))
+	assert.False(t, etagMatches(`W/"old"`, etag))
+}
+
+func TestHLSCacheControl(t *testing.T) {
+	assert.Equal(t, "public, max-age=30", hlsCacheControl("720p/playlist.m3u8", 30*time.Second))
+	assert.Equal(t, segmentCacheControl, hlsCacheControl("720p/segment_001.ts", 30*time.Second))
+}
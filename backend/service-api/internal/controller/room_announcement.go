@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"net/http"
+	"watch-party/pkg/auth"
+	"watch-party/pkg/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AnnounceToRoom handles POST /api/v1/admin/rooms/:id/announce - ADMIN ONLY
+func (rc *RoomController) AnnounceToRoom(c *gin.Context) {
+	claims, req, ok := announcementRequestContext(c)
+	if !ok {
+		return
+	}
+
+	roomID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	response, err := rc.roomService.Announce(c.Request.Context(), roomID, claims.UserID, req)
+	if err != nil {
+		respondAnnouncementError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
+
+// AnnounceToAllRooms handles POST /api/v1/admin/announce - ADMIN ONLY
+func (rc *RoomController) AnnounceToAllRooms(c *gin.Context) {
+	claims, req, ok := announcementRequestContext(c)
+	if !ok {
+		return
+	}
+
+	response, err := rc.roomService.AnnounceAll(c.Request.Context(), claims.UserID, req)
+	if err != nil {
+		respondAnnouncementError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
+
+// announcementRequestContext reads the caller and the announcement, writing the error response when either is invalid
+func announcementRequestContext(c *gin.Context) (*auth.JWTClaims, *model.AnnouncementRequest, bool) {
+	userClaims, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return nil, nil, false
+	}
+
+	claims, ok := userClaims.(*auth.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
+		return nil, nil, false
+	}
+
+	var req model.AnnouncementRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+
+	err = req.Normalize()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+
+	return claims, &req, true
+}
+
+func respondAnnouncementError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "room not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "redis not configured":
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"net/http"
+	"watch-party/pkg/auth"
+	"watch-party/pkg/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GrantCoHost handles POST /api/v1/rooms/:id/co-hosts (host or admin)
+func (rc *RoomController) GrantCoHost(c *gin.Context) {
+	claims, roomID, ok := coHostRequestContext(c)
+	if !ok {
+		return
+	}
+
+	var req model.CoHostRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	access, err := rc.roomService.SetCoHost(c.Request.Context(), claims.UserID, claims.Role, roomID, req.UserID, true)
+	if err != nil {
+		respondCoHostError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, access)
+}
+
+// RevokeCoHost handles DELETE /api/v1/rooms/:id/co-hosts/:userId (host or admin)
+func (rc *RoomController) RevokeCoHost(c *gin.Context) {
+	claims, roomID, ok := coHostRequestContext(c)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	access, err := rc.roomService.SetCoHost(c.Request.Context(), claims.UserID, claims.Role, roomID, userID, false)
+	if err != nil {
+		respondCoHostError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, access)
+}
+
+// coHostRequestContext reads the caller and room of a co-host request, answering the request when either is missing
+func coHostRequestContext(c *gin.Context) (*auth.JWTClaims, uuid.UUID, bool) {
+	userClaims, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return nil, uuid.Nil, false
+	}
+
+	claims, ok := userClaims.(*auth.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
+		return nil, uuid.Nil, false
+	}
+
+	roomID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return nil, uuid.Nil, false
+	}
+
+	return claims, roomID, true
+}
+
+// respondCoHostError maps the errors of the co-host endpoints to their status codes
+func respondCoHostError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "room not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "only room host can manage co-hosts":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "the room host cannot be a co-host", "co-host must be a member of the room":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -2,8 +2,13 @@ package controller
 
 import (
 	"net/http"
+	"strings"
+	"time"
 	"watch-party/pkg/auth"
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
 	"watch-party/pkg/model"
+	"watch-party/pkg/storage"
 	roomService "watch-party/service-api/internal/service/room"
 
 	"github.com/gin-gonic/gin"
@@ -12,13 +17,19 @@ import (
 
 // RoomController handles room-related HTTP requests
 type RoomController struct {
-	roomService *roomService.Service
+	roomService     *roomService.Service
+	guestCookies    *auth.GuestCookieSigner // nil unless guests get a streaming cookie
+	storageProvider storage.Provider        // signs poster URLs of room summaries
+	streaming       config.StreamingConfig
 }
 
 // NewRoomController creates a new room controller
-func NewRoomController(roomService *roomService.Service) *RoomController {
+func NewRoomController(roomService *roomService.Service, guestCookies *auth.GuestCookieSigner, storageProvider storage.Provider, streaming config.StreamingConfig) *RoomController {
 	return &RoomController{
-		roomService: roomService,
+		roomService:     roomService,
+		guestCookies:    guestCookies,
+		storageProvider: storageProvider,
+		streaming:       streaming,
 	}
 }
 
@@ -46,12 +57,30 @@ func (rc *RoomController) CreateRoom(c *gin.Context) {
 	}
 
 	// create room
-	response, err := rc.roomService.CreateRoom(c.Request.Context(), claims.UserID, &req)
+	response, err := rc.roomService.CreateRoom(c.Request.Context(), claims.UserID, claims.Role == model.RoleAdmin, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		switch err.Error() {
+		case "movie not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+		case "access to movie denied":
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to this movie"})
+		case "movie failed processing":
+			c.JSON(http.StatusConflict, gin.H{"error": "This movie failed processing and cannot be watched"})
+		default:
+			if strings.HasPrefix(err.Error(), "invalid room settings") {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
+	// an existing room handed back was not created by this request
+	if response.Existing {
+		c.JSON(http.StatusOK, response)
+		return
+	}
 	c.JSON(http.StatusCreated, response)
 }
 
@@ -162,6 +191,364 @@ func (rc *RoomController) InviteUser(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// BatchInviteUsers handles POST /api/v1/rooms/:id/invite-batch
+func (rc *RoomController) BatchInviteUsers(c *gin.Context) {
+	// get user ID from JWT token
+	userClaims, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	claims, ok := userClaims.(*auth.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
+		return
+	}
+
+	roomID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	var req model.BatchInviteRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := rc.roomService.BatchInviteUsers(c.Request.Context(), claims.UserID, roomID, &req)
+	if err != nil {
+		switch {
+		case err.Error() == "only room host can send invitations":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case strings.HasPrefix(err.Error(), "at most"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// TransferHost handles POST /api/v1/rooms/:id/transfer-host
+func (rc *RoomController) TransferHost(c *gin.Context) {
+	// get user ID from JWT token
+	userClaims, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	claims, ok := userClaims.(*auth.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
+		return
+	}
+
+	// parse room ID from URL
+	roomIDParam := c.Param("id")
+	roomID, err := uuid.Parse(roomIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	// parse request
+	var req model.TransferHostRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := rc.roomService.TransferHost(c.Request.Context(), claims.UserID, claims.Role, roomID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "room not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "only room host can transfer host":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case "user is already the room host", "new host must be a member of the room":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRoomSettings handles GET /api/v1/rooms/:id/settings (host only)
+func (rc *RoomController) GetRoomSettings(c *gin.Context) {
+	// get user ID from JWT token
+	userClaims, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	claims, ok := userClaims.(*auth.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
+		return
+	}
+
+	// parse room ID from URL
+	roomIDParam := c.Param("id")
+	roomID, err := uuid.Parse(roomIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	settings, err := rc.roomService.GetRoomSettings(c.Request.Context(), claims.UserID, roomID)
+	if err != nil {
+		switch err.Error() {
+		case "room not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "only room host can manage room settings":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+// UpdateRoomSettings handles PATCH /api/v1/rooms/:id/settings (host only)
+func (rc *RoomController) UpdateRoomSettings(c *gin.Context) {
+	// get user ID from JWT token
+	userClaims, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	claims, ok := userClaims.(*auth.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
+		return
+	}
+
+	// parse room ID from URL
+	roomIDParam := c.Param("id")
+	roomID, err := uuid.Parse(roomIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	// parse request
+	var req model.UpdateRoomSettingsRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := rc.roomService.UpdateRoomSettings(c.Request.Context(), claims.UserID, roomID, &req)
+	if err != nil {
+		switch {
+		case err.Error() == "room not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case err.Error() == "only room host can manage room settings":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case strings.HasPrefix(err.Error(), "invalid room settings"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"settings": settings,
+		"message":  "Room settings updated successfully",
+	})
+}
+
+// ReportParticipant handles POST /api/v1/rooms/:id/report
+func (rc *RoomController) ReportParticipant(c *gin.Context) {
+	// get user ID from JWT token
+	userClaims, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	claims, ok := userClaims.(*auth.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
+		return
+	}
+
+	// parse room ID from URL
+	roomIDParam := c.Param("id")
+	roomID, err := uuid.Parse(roomIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	// parse request
+	var req model.CreateReportRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := rc.roomService.ReportParticipant(c.Request.Context(), claims.UserID, roomID, &req)
+	if err != nil {
+		switch {
+		case err.Error() == "room not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case err.Error() == "access denied":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case err.Error() == "reason is required", err.Error() == "cannot report yourself",
+			strings.HasPrefix(err.Error(), "reason must be at most"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetReports handles GET /api/v1/admin/reports - ADMIN ONLY
+func (rc *RoomController) GetReports(c *gin.Context) {
+	// parse pagination parameters
+	page, pageSize := parsePagination(c)
+
+	response, err := rc.roomService.GetReports(c.Request.Context(), c.Query("status"), page, pageSize)
+	if err != nil {
+		if err.Error() == "invalid report status" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve reports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRoomDiagnostics handles GET /api/v1/admin/rooms/:id/diagnostics - ADMIN ONLY
+func (rc *RoomController) GetRoomDiagnostics(c *gin.Context) {
+	// parse room ID from URL
+	roomIDParam := c.Param("id")
+	roomID, err := uuid.Parse(roomIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	diagnostics, err := rc.roomService.GetRoomDiagnostics(c.Request.Context(), roomID)
+	if err != nil {
+		switch err.Error() {
+		case "room not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "redis not configured":
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, diagnostics)
+}
+
+// ResyncRoom handles POST /api/v1/admin/rooms/:id/resync - ADMIN ONLY
+func (rc *RoomController) ResyncRoom(c *gin.Context) {
+	// get user claims from JWT middleware
+	userClaims, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	claims, ok := userClaims.(*auth.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
+		return
+	}
+
+	// parse room ID from URL
+	roomIDParam := c.Param("id")
+	roomID, err := uuid.Parse(roomIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	response, err := rc.roomService.ResyncRoom(c.Request.Context(), roomID, claims.UserID)
+	if err != nil {
+		switch err.Error() {
+		case "room not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "room has no active connections":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case "redis not configured":
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
+
+// ClearChatHistory handles DELETE /api/v1/admin/rooms/:id/chat - ADMIN ONLY
+func (rc *RoomController) ClearChatHistory(c *gin.Context) {
+	// get user claims from JWT middleware
+	userClaims, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	claims, ok := userClaims.(*auth.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
+		return
+	}
+
+	// parse room ID from URL
+	roomIDParam := c.Param("id")
+	roomID, err := uuid.Parse(roomIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	err = rc.roomService.ClearChatHistory(c.Request.Context(), roomID, claims.UserID)
+	if err != nil {
+		switch err.Error() {
+		case "room not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "redis not configured":
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Chat history cleared"})
+}
+
 // JoinRoom handles POST /api/v1/rooms/join
 func (rc *RoomController) JoinRoom(c *gin.Context) {
 	// get user ID from JWT token
@@ -300,6 +687,10 @@ func (rc *RoomController) RequestGuestAccess(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
 			return
 		}
+		if err.Error() == "guest access requests are disabled for this room" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "The host does not accept guest access requests for this room"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit access request"})
 		return
 	}
@@ -307,7 +698,7 @@ func (rc *RoomController) RequestGuestAccess(c *gin.Context) {
 	c.JSON(http.StatusAccepted, response)
 }
 
-// GetPendingGuestRequests handles GET /api/v1/rooms/:id/guest-requests (admin only)
+// GetPendingGuestRequests handles GET /api/v1/rooms/:id/guest-requests (host, co-host or admin)
 func (rc *RoomController) GetPendingGuestRequests(c *gin.Context) {
 	// get user ID from JWT token
 	userClaims, exists := c.Get("user")
@@ -322,12 +713,6 @@ func (rc *RoomController) GetPendingGuestRequests(c *gin.Context) {
 		return
 	}
 
-	// check if user is admin
-	if claims.Role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
-		return
-	}
-
 	// parse room ID
 	roomIDStr := c.Param("id")
 	roomID, err := uuid.Parse(roomIDStr)
@@ -337,16 +722,21 @@ func (rc *RoomController) GetPendingGuestRequests(c *gin.Context) {
 	}
 
 	// get pending requests
-	requests, err := rc.roomService.GetPendingGuestRequests(c.Request.Context(), claims.UserID, roomID)
+	requests, err := rc.roomService.GetPendingGuestRequests(c.Request.Context(), claims.UserID, claims.Role == model.RoleAdmin, roomID)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "access denied") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only room host or co-host can view guest requests"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get guest requests"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"requests": requests})
+	page, pageSize := parsePagination(c)
+	c.JSON(http.StatusOK, model.PaginateSlice(requests, page, pageSize))
 }
 
-// ApproveGuestRequest handles POST /api/v1/rooms/:roomId/guest-requests/:requestId/approve (admin only)
+// ApproveGuestRequest handles POST /api/v1/rooms/:roomId/guest-requests/:requestId/approve (host, co-host or admin)
 func (rc *RoomController) ApproveGuestRequest(c *gin.Context) {
 	// get user ID from JWT token
 	userClaims, exists := c.Get("user")
@@ -361,12 +751,6 @@ func (rc *RoomController) ApproveGuestRequest(c *gin.Context) {
 		return
 	}
 
-	// check if user is admin
-	if claims.Role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
-		return
-	}
-
 	// parse room ID
 	roomIDStr := c.Param("id")
 	roomID, err := uuid.Parse(roomIDStr)
@@ -392,8 +776,12 @@ func (rc *RoomController) ApproveGuestRequest(c *gin.Context) {
 	}
 
 	// approve/deny request
-	response, err := rc.roomService.ApproveGuestRequest(c.Request.Context(), claims.UserID, roomID, requestID, req.Approved)
+	response, err := rc.roomService.ApproveGuestRequest(c.Request.Context(), claims.UserID, claims.Role == model.RoleAdmin, roomID, requestID, req.Approved)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "access denied") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only room host or co-host can approve guest requests"})
+			return
+		}
 		if err.Error() == "guest request has already been reviewed" {
 			c.JSON(http.StatusConflict, gin.H{"error": "Guest request has already been reviewed"})
 			return
@@ -413,18 +801,25 @@ func (rc *RoomController) ValidateGuestSession(c *gin.Context) {
 		return
 	}
 
-	session, err := rc.roomService.ValidateGuestSession(c.Request.Context(), token)
+	info, err := rc.roomService.GetGuestSessionInfo(c.Request.Context(), token, time.Now())
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired session"})
+		switch err.Error() {
+		case "guest session expired":
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session expired"})
+		case "invalid or expired guest session":
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired session"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate session"})
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"valid":      true,
-		"room_id":    session.RoomID,
-		"guest_name": session.GuestName,
-		"expires_at": session.ExpiresAt,
-	})
+	// the cookie lets players stream without the token in every playlist and segment URL
+	if rc.guestCookies != nil {
+		http.SetCookie(c.Writer, rc.guestCookies.Cookie(token, info.ExpiresAt))
+	}
+
+	c.JSON(http.StatusOK, info)
 }
 
 // GetRooms handles GET /api/v1/rooms (admin only)
@@ -449,7 +844,66 @@ func (rc *RoomController) GetRooms(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, rooms)
+	page, pageSize := parsePagination(c)
+	c.JSON(http.StatusOK, model.PaginateSlice(rooms, page, pageSize))
+}
+
+// GetRoomSummaries handles GET /api/v1/rooms/summary, the home screen list of the user's rooms with their live state
+func (rc *RoomController) GetRoomSummaries(c *gin.Context) {
+	userClaims, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	claims, ok := userClaims.(*auth.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
+		return
+	}
+
+	summaries, err := rc.roomService.GetUserRoomSummaries(c.Request.Context(), claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, pageSize := parsePagination(c)
+	result := model.PaginateSlice(summaries, page, pageSize)
+	rc.signPosters(c, result.Items)
+
+	c.JSON(http.StatusOK, result)
+}
+
+// signPosters fills in the poster URLs of summaries with one batch signing call. proxy mode promises storage
+// URLs never reach clients, so summaries go without posters there
+func (rc *RoomController) signPosters(c *gin.Context, summaries []*model.RoomSummary) {
+	if rc.streaming.EffectiveMode() == config.StreamingModeProxy {
+		return
+	}
+
+	var paths []string
+	for _, summary := range summaries {
+		if summary.PosterPath != "" {
+			paths = append(paths, summary.PosterPath)
+		}
+	}
+	if len(paths) == 0 {
+		return
+	}
+
+	signedURLs, err := rc.storageProvider.GenerateSignedURLs(c.Request.Context(), paths, &storage.CDNSignedURLOptions{
+		ExpiresIn:    time.Hour * 2,
+		CacheControl: "public, max-age=86400",
+	})
+	if err != nil {
+		// posters are cosmetic, the list is still useful without them
+		logger.Error(err, "failed to sign room summary posters")
+		return
+	}
+	for _, summary := range summaries {
+		summary.PosterURL = signedURLs[summary.PosterPath]
+	}
 }
 
 // CheckGuestRequestStatus handles GET /api/v1/guest-requests/:requestId/status (public endpoint)
@@ -477,6 +931,11 @@ func (rc *RoomController) CheckGuestRequestStatus(c *gin.Context) {
 		return
 	}
 
+	c.JSON(http.StatusOK, guestRequestStatusBody(status, sessionToken, expiresAt))
+}
+
+// guestRequestStatusBody is the status of a guest request as the guest sees it
+func guestRequestStatusBody(status, sessionToken string, expiresAt time.Time) gin.H {
 	response := gin.H{
 		"status": status,
 	}
@@ -487,7 +946,7 @@ func (rc *RoomController) CheckGuestRequestStatus(c *gin.Context) {
 		response["expires_at"] = expiresAt
 	}
 
-	c.JSON(http.StatusOK, response)
+	return response
 }
 
 // RequestRoomAccess handles POST /api/v1/rooms/:id/room-access (authenticated users)
@@ -569,15 +1028,16 @@ func (rc *RoomController) GetPendingRoomAccessRequests(c *gin.Context) {
 	// get pending requests
 	requests, err := rc.roomService.GetPendingRoomAccessRequests(c.Request.Context(), claims.UserID, roomID)
 	if err != nil {
-		if err.Error() == "access denied - only room host can view room access requests" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only room host can view room access requests"})
+		if err.Error() == "access denied - only room host or co-host can view room access requests" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only room host or co-host can view room access requests"})
 			return
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get room access requests"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"requests": requests})
+	page, pageSize := parsePagination(c)
+	c.JSON(http.StatusOK, model.PaginateSlice(requests, page, pageSize))
 }
 
 // ApproveRoomAccessRequest handles POST /api/v1/rooms/:roomId/room-access/:userId/approve (host only)
@@ -622,8 +1082,8 @@ func (rc *RoomController) ApproveRoomAccessRequest(c *gin.Context) {
 	// approve/deny request
 	response, err := rc.roomService.ApproveRoomAccessRequest(c.Request.Context(), claims.UserID, roomID, requestedUserID, req.Approved)
 	if err != nil {
-		if err.Error() == "access denied - only room host can approve room access requests" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only room host can approve room access requests"})
+		if err.Error() == "access denied - only room host or co-host can approve room access requests" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only room host or co-host can approve room access requests"})
 			return
 		}
 		if err.Error() == "room access request has already been reviewed" {
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"watch-party/pkg/auth"
+	"watch-party/pkg/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetRoomTranscript handles GET /api/v1/rooms/:id/transcript?format=json|txt&annotations=true (members, annotations
+// for the host, a co-host or an admin)
+func (rc *RoomController) GetRoomTranscript(c *gin.Context) {
+	userClaims, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	claims, ok := userClaims.(*auth.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
+		return
+	}
+
+	roomID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	format := c.DefaultQuery("format", model.TranscriptFormatJSON)
+	if format != model.TranscriptFormatJSON && format != model.TranscriptFormatText {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("format must be %q or %q", model.TranscriptFormatJSON, model.TranscriptFormatText)})
+		return
+	}
+
+	includeAnnotations, _ := strconv.ParseBool(c.Query("annotations"))
+
+	transcript, err := rc.roomService.GetRoomTranscript(c.Request.Context(), claims.UserID, claims.Role == model.RoleAdmin, roomID, includeAnnotations)
+	if err != nil {
+		switch {
+		case err.Error() == "room not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.HasPrefix(err.Error(), "access denied"):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if format == model.TranscriptFormatText {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"transcript-%s.txt\"", roomID))
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(transcript.Text()))
+		return
+	}
+
+	c.JSON(http.StatusOK, transcript)
+}
@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"watch-party/pkg/logger"
+	movieService "watch-party/service-api/internal/service/movie"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetSegmentKey handles GET /api/v1/videos/:movieId/key, the AES-128 key of an encrypted movie's segments.
+// the streaming middleware only lets viewers with access to the movie through
+func (vac *VideoAccessController) GetSegmentKey(c *gin.Context) {
+	movieID, err := uuid.Parse(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
+		return
+	}
+
+	key, err := vac.movieService.GetSegmentKey(c.Request.Context(), movieID)
+	if err != nil {
+		switch {
+		case errors.Is(err, movieService.ErrMovieNotFound):
+			respondMovieNotFound(c, vac.movieService, movieID)
+		case errors.Is(err, movieService.ErrNotEncrypted):
+			c.JSON(http.StatusNotFound, gin.H{"error": "movie segments are not encrypted"})
+		default:
+			logger.Error(err, "failed to get segment key")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get segment key"})
+		}
+		return
+	}
+
+	// the key must not end up in a shared cache, and is fetched once per playback
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "application/octet-stream", key)
+}
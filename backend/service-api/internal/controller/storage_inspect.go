@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// inspectURLExpiry keeps URLs handed out for debugging short-lived
+const inspectURLExpiry = 5 * time.Minute
+
+// InspectStorageObject handles POST /api/v1/admin/storage/inspect.
+// it reports whether an object exists with its metadata and signs a fresh URL, to tell expired URLs from wrong paths.
+func (sc *StreamingController) InspectStorageObject(c *gin.Context) {
+	var req model.StorageInspectRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload"})
+		return
+	}
+
+	path := strings.TrimPrefix(strings.TrimSpace(req.Path), "/")
+	if path == "" || strings.Contains(path, "..") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid storage path"})
+		return
+	}
+
+	response := model.StorageInspectResponse{Path: path}
+
+	exists, err := sc.storageProvider.Exists(c.Request.Context(), path)
+	if err != nil {
+		logger.Error(err, "failed to inspect storage object "+path)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to reach storage: " + err.Error()})
+		return
+	}
+	if !exists {
+		// a missing object is a valid answer, not a failed inspection
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	info, err := sc.storageProvider.GetFileInfo(c.Request.Context(), path)
+	if err != nil {
+		logger.Error(err, "failed to read storage object info "+path)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to read object info: " + err.Error()})
+		return
+	}
+
+	response.Exists = true
+	response.Size = info.Size
+	response.ContentType = info.ContentType
+	response.LastModified = info.LastModified
+
+	signedURL, err := sc.storageProvider.GenerateCDNSignedURL(c.Request.Context(), path, &storage.CDNSignedURLOptions{
+		ExpiresIn: inspectURLExpiry,
+	})
+	if err != nil {
+		logger.Error(err, "failed to sign inspected storage object "+path)
+		response.Error = "failed to generate signed URL: " + err.Error()
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	expiresAt := time.Now().Add(inspectURLExpiry)
+	response.SignedURL = signedURL
+	response.ExpiresAt = &expiresAt
+
+	c.JSON(http.StatusOK, response)
+}
@@ -1,34 +1,36 @@
 package controller
 
 import (
+	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 	"time"
+	"watch-party/pkg/config"
 	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
 	"watch-party/pkg/storage"
 	movieService "watch-party/service-api/internal/service/movie"
-	roomService "watch-party/service-api/internal/service/room"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-// StreamingController handles video streaming HTTP requests via signed URLs
+// StreamingController serves HLS playlists and segments through the API, see config.StreamingConfig for the modes
 type StreamingController struct {
 	storageProvider storage.Provider
 	movieService    movieService.Service
-	roomService     *roomService.Service
+	streaming       config.StreamingConfig
 }
 
 // NewStreamingController creates a new streaming controller
-func NewStreamingController(storageProvider storage.Provider, movieService movieService.Service, roomService *roomService.Service) *StreamingController {
+func NewStreamingController(storageProvider storage.Provider, movieService movieService.Service, streaming config.StreamingConfig) *StreamingController {
 	return &StreamingController{
 		storageProvider: storageProvider,
 		movieService:    movieService,
-		roomService:     roomService,
+		streaming:       streaming,
 	}
 }
 
@@ -45,54 +47,6 @@ func (sc *StreamingController) generateAuthHash(userID *uuid.UUID, guestToken st
 	return fmt.Sprintf("%x", hash)[:16] // first 16 chars for brevity
 }
 
-// validateAccess validates user or guest access and returns auth hash
-func (sc *StreamingController) validateAccess(c *gin.Context, movieID uuid.UUID) (string, error) {
-	// check for guest token first
-	guestToken := c.Query("guestToken")
-	var userID *uuid.UUID
-
-	if guestToken != "" {
-		// validate guest access
-		if len(guestToken) < 32 {
-			return "", fmt.Errorf("invalid guest token format")
-		}
-
-		guestSession, err := sc.roomService.ValidateGuestSession(c.Request.Context(), guestToken)
-		if err != nil {
-			return "", fmt.Errorf("invalid or expired guest token")
-		}
-
-		if time.Now().After(guestSession.ExpiresAt) {
-			return "", fmt.Errorf("guest session expired")
-		}
-
-		hasAccess, err := sc.roomService.CheckRoomContainsMovie(c.Request.Context(), guestSession.RoomID, movieID)
-		if err != nil || !hasAccess {
-			return "", fmt.Errorf("guest does not have access to this movie")
-		}
-
-		return sc.generateAuthHash(nil, guestToken, movieID), nil
-	} else {
-		// validate user access
-		if userIDValue, exists := c.Get("user_id"); exists {
-			if uid, ok := userIDValue.(uuid.UUID); ok {
-				userID = &uid
-			}
-		}
-
-		if userID == nil {
-			return "", fmt.Errorf("authentication required")
-		}
-
-		hasAccess, err := sc.roomService.CheckUserMovieAccess(c.Request.Context(), *userID, movieID)
-		if err != nil || !hasAccess {
-			return "", fmt.Errorf("user does not have access to this movie")
-		}
-
-		return sc.generateAuthHash(userID, "", movieID), nil
-	}
-}
-
 // generateAuthHashFromContext creates auth hash from middleware context
 func (sc *StreamingController) generateAuthHashFromContext(c *gin.Context, movieID uuid.UUID) string {
 	authType := c.GetString("auth_type")
@@ -104,499 +58,208 @@ func (sc *StreamingController) generateAuthHashFromContext(c *gin.Context, movie
 			}
 		}
 	} else if authType == "guest" {
-		guestToken := c.Query("guestToken")
-		if guestToken == "" {
-			guestToken = c.GetHeader("X-Guest-Token")
-		}
-		return sc.generateAuthHash(nil, guestToken, movieID)
+		return sc.generateAuthHash(nil, streamingGuestToken(c), movieID)
 	}
 
 	// fallback - shouldn't happen with proper middleware
 	return sc.generateAuthHash(nil, "", movieID)
 }
 
-// ProxyMasterPlaylist handles GET /api/v1/stream/{movieId}/playlist.m3u8
-func (sc *StreamingController) ProxyMasterPlaylist(c *gin.Context) {
-	movieIDStr := c.Param("movieId")
-	movieID, err := uuid.Parse(movieIDStr)
+// requireAvailableMovie returns the movie, or responds with an error and returns nil unless it can be streamed
+func (sc *StreamingController) requireAvailableMovie(c *gin.Context, movieID uuid.UUID) *model.Movie {
+	movie, err := sc.movieService.GetStreamingMovie(c.Request.Context(), movieID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
-		return
-	}
-
-	// generate auth hash for caching (auth already validated by middleware)
-	authHash := sc.generateAuthHashFromContext(c, movieID)
-
-	// verify movie exists and is available
-	movie, err := sc.movieService.GetMovie(c.Request.Context(), movieID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
-		return
+		respondMovieNotFound(c, sc.movieService, movieID)
+		return nil
 	}
 
 	if movie.Status != "available" {
-		c.JSON(http.StatusConflict, gin.H{
-			"error":  "video not ready",
-			"status": movie.Status,
-		})
-		return
-	}
-
-	// construct path for master playlist
-	masterPath := fmt.Sprintf("hls/%s/master.m3u8", movieID.String())
-
-	// get and rewrite master playlist to use proxy URLs
-	signedURL, err := sc.storageProvider.GenerateCDNSignedURL(c.Request.Context(), masterPath, &storage.CDNSignedURLOptions{
-		ExpiresIn:    time.Hour * 2,          // 2 hours expiration
-		CacheControl: "public, max-age=3600", // cache for 1 hour
-		ContentType:  "application/vnd.apple.mpegurl",
-	})
-	if err != nil {
-		logger.Error(err, "failed to generate signed URL for master playlist")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate playlist URL"})
-		return
-	}
-
-	// fetch the master playlist content
-	resp, err := http.Get(signedURL)
-	if err != nil {
-		logger.Error(err, "failed to fetch master playlist")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch playlist"})
-		return
-	}
-	defer resp.Body.Close()
-
-	// read playlist content
-	content, err := io.ReadAll(resp.Body)
-	if err != nil {
-		logger.Error(err, "failed to read master playlist")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read playlist"})
-		return
+		respondMovieNotReady(c, movie)
+		return nil
 	}
-
-	// rewrite playlist to use proxy URLs
-	playlistContent := string(content)
-	lines := strings.Split(playlistContent, "\n")
-
-	for i, line := range lines {
-		trimmedLine := strings.TrimSpace(line)
-		// rewrite variant playlist URLs to go through proxy
-		if trimmedLine != "" && !strings.HasPrefix(trimmedLine, "#") && strings.HasSuffix(trimmedLine, ".m3u8") {
-			// convert "1080p.m3u8" to "/api/v1/stream/movieID/1080p/playlist.m3u8"
-			quality := strings.TrimSuffix(trimmedLine, ".m3u8")
-			proxyURL := fmt.Sprintf("/api/v1/stream/%s/%s/playlist.m3u8", movieID.String(), quality)
-			if guestToken := c.Query("guestToken"); guestToken != "" {
-				proxyURL += fmt.Sprintf("?guestToken=%s", guestToken)
-			}
-			lines[i] = proxyURL
-		}
-	}
-
-	rewrittenContent := strings.Join(lines, "\n")
-
-	// set CDN-friendly cache headers with auth awareness
-	c.Header("Cache-Control", "public, max-age=3600")
-	c.Header("Vary", "Authorization")
-	c.Header("X-Auth-Hash", authHash)
-	c.Header("Content-Type", "application/vnd.apple.mpegurl")
-
-	// return rewritten playlist content
-	c.String(http.StatusOK, rewrittenContent)
+	return movie
 }
 
-// ProxyVideoSegment handles GET /api/v1/stream/{movieId}/{quality}/{segment}
-func (sc *StreamingController) ProxyVideoSegment(c *gin.Context) {
-	movieIDStr := c.Param("movieId")
-	quality := c.Param("quality")
-	segment := c.Param("segment")
-
-	movieID, err := uuid.Parse(movieIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
-		return
+// resolveQuality substitutes the nearest quality the movie was published with when the requested one is missing,
+// announcing the substitute in the X-Resolved-Quality header
+func resolveQuality(c *gin.Context, movie *model.Movie, requested string) string {
+	quality := movie.NearestQuality(requested)
+	if quality != requested {
+		c.Header("X-Resolved-Quality", quality)
 	}
+	return quality
+}
 
-	// generate auth hash for caching (auth already validated by middleware)
-	authHash := sc.generateAuthHashFromContext(c, movieID)
-
-	// validate parameters
-	if quality == "" || segment == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "quality and segment parameters required"})
+// servePlaylist serves a playlist from the movie's HLS output, keeping its relative URIs on the stream routes.
+// playlists are cached briefly and revalidated with an ETag that changes when the movie is reprocessed
+func (sc *StreamingController) servePlaylist(c *gin.Context, movie *model.Movie, file string) {
+	authHash := sc.generateAuthHashFromContext(c, movie.ID)
+	etag := playlistETag(movie, file, authHash)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		sc.setPlaylistHeaders(c, etag, authHash)
+		c.Status(http.StatusNotModified)
 		return
 	}
 
-	// verify movie exists and is available
-	movie, err := sc.movieService.GetMovie(c.Request.Context(), movieID)
+	content, err := readStorageObject(c.Request.Context(), sc.storageProvider, hlsStoragePath(movie.ID, file))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
-		return
-	}
-
-	if movie.Status != "available" {
-		c.JSON(http.StatusConflict, gin.H{
-			"error":  "video not ready",
-			"status": movie.Status,
-		})
+		if errors.Is(err, errStorageObjectNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "playlist not found"})
+			return
+		}
+		logger.Error(err, "failed to fetch playlist "+file)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch playlist"})
 		return
 	}
 
-	// construct path for video segment
-	segmentPath := fmt.Sprintf("hls/%s/%s/%s", movieID.String(), quality, segment)
+	sc.setPlaylistHeaders(c, etag, authHash)
+	c.Header("Content-Type", sc.streaming.EffectivePlaylistContentType())
 
-	// generate signed URL with long CDN cache for segments
-	signedURL, err := sc.storageProvider.GenerateCDNSignedURL(c.Request.Context(), segmentPath, &storage.CDNSignedURLOptions{
-		ExpiresIn:    time.Hour * 24,          // 24 hours expiration for segments
-		CacheControl: "public, max-age=86400", // cache segments for 24 hours
-		ContentType:  "video/mp2t",
-	})
-	if err != nil {
-		logger.Error(err, "failed to generate signed URL for video segment")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate segment URL"})
-		return
-	}
+	c.String(http.StatusOK, withGuestToken(string(content), streamingGuestToken(c)))
+}
 
-	// set aggressive CDN cache headers for segments
-	c.Header("Cache-Control", "public, max-age=86400") // 24 hours
+// setPlaylistHeaders sets the caching headers of a served playlist
+func (sc *StreamingController) setPlaylistHeaders(c *gin.Context, etag, authHash string) {
+	// playlists embed the guest token, so they must not be shared between viewers
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(sc.streaming.PlaylistMaxAge().Seconds())))
+	c.Header("ETag", etag)
 	c.Header("Vary", "Authorization")
 	c.Header("X-Auth-Hash", authHash)
-	c.Header("X-Content-Type", "video/mp2t")
-
-	// redirect to signed URL - CDN will cache this redirect with auth hash
-	c.Redirect(http.StatusFound, signedURL)
 }
 
-// ProxyVideoSegmentTimeWindow handles GET /api/v1/stream/{movieId}/{quality}/{segment} with time-window caching
-func (sc *StreamingController) ProxyVideoSegmentTimeWindow(c *gin.Context) {
-	movieIDStr := c.Param("movieId")
-	quality := c.Param("quality")
-	segment := c.Param("segment")
-
-	movieID, err := uuid.Parse(movieIDStr)
+// ProxyMasterPlaylist handles GET /api/v1/stream/{movieId}/master.m3u8
+func (sc *StreamingController) ProxyMasterPlaylist(c *gin.Context) {
+	movieID, err := uuid.Parse(c.Param("movieId"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
 		return
 	}
 
-	// validate access (no auth hash needed for time-window approach)
-	_, err = sc.validateAccess(c, movieID)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-		return
-	}
-
-	// validate parameters
-	if quality == "" || segment == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "quality and segment parameters required"})
-		return
-	}
-
-	// verify movie exists and is available
-	movie, err := sc.movieService.GetMovie(c.Request.Context(), movieID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
-		return
-	}
-
-	if movie.Status != "available" {
-		c.JSON(http.StatusConflict, gin.H{
-			"error":  "video not ready",
-			"status": movie.Status,
-		})
+	movie := sc.requireAvailableMovie(c, movieID)
+	if movie == nil {
 		return
 	}
 
-	// generate time window (rounded to 5-minute intervals)
-	now := time.Now()
-	timeWindow := now.Truncate(5 * time.Minute).Unix()
-
-	// construct path for video segment with time window
-	segmentPath := fmt.Sprintf("hls/%s/%s/%s", movieID.String(), quality, segment)
-
-	// generate signed URL that's valid for the current time window
-	signedURL, err := sc.storageProvider.GenerateCDNSignedURL(c.Request.Context(), segmentPath, &storage.CDNSignedURLOptions{
-		ExpiresIn:    time.Hour * 24,        // 24 hours expiration for segments
-		CacheControl: "public, max-age=300", // cache for 5 minutes (time window)
-		ContentType:  "video/mp2t",
-	})
-	if err != nil {
-		logger.Error(err, "failed to generate signed URL for video segment")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate segment URL"})
-		return
-	}
-
-	// set CDN cache headers with time window
-	c.Header("Cache-Control", "public, max-age=300") // 5 minutes
-	c.Header("X-Time-Window", fmt.Sprintf("%d", timeWindow))
-	c.Header("X-Content-Type", "video/mp2t")
-
-	// add time window to URL for CDN cache differentiation
-	if strings.Contains(signedURL, "?") {
-		signedURL += fmt.Sprintf("&tw=%d", timeWindow)
-	} else {
-		signedURL += fmt.Sprintf("?tw=%d", timeWindow)
-	}
-
-	// redirect to signed URL with time window
-	c.Redirect(http.StatusFound, signedURL)
+	sc.servePlaylist(c, movie, "master.m3u8")
 }
 
 // ProxyQualityPlaylist handles GET /api/v1/stream/{movieId}/{quality}/playlist.m3u8
 func (sc *StreamingController) ProxyQualityPlaylist(c *gin.Context) {
-	movieIDStr := c.Param("movieId")
-	quality := c.Param("quality")
-
-	movieID, err := uuid.Parse(movieIDStr)
+	movieID, err := uuid.Parse(c.Param("movieId"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
 		return
 	}
 
-	// generate auth hash for caching (auth already validated by middleware)
-	authHash := sc.generateAuthHashFromContext(c, movieID)
-
-	// validate quality parameter
-	if quality == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "quality parameter required"})
-		return
-	}
-
-	// verify movie exists and is available
-	movie, err := sc.movieService.GetMovie(c.Request.Context(), movieID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
-		return
-	}
-
-	if movie.Status != "available" {
-		c.JSON(http.StatusConflict, gin.H{
-			"error":  "video not ready",
-			"status": movie.Status,
-		})
-		return
-	}
-
-	// construct path for quality-specific playlist
-	playlistPath := fmt.Sprintf("hls/%s/%s.m3u8", movieID.String(), quality)
-
-	// get and rewrite quality playlist to use proxy URLs for segments
-	signedURL, err := sc.storageProvider.GenerateCDNSignedURL(c.Request.Context(), playlistPath, &storage.CDNSignedURLOptions{
-		ExpiresIn:    time.Hour * 2,          // 2 hours expiration
-		CacheControl: "public, max-age=1800", // cache for 30 minutes
-		ContentType:  "application/vnd.apple.mpegurl",
-	})
-	if err != nil {
-		logger.Error(err, "failed to generate signed URL for quality playlist")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate playlist URL"})
-		return
-	}
-
-	// fetch the quality playlist content
-	resp, err := http.Get(signedURL)
-	if err != nil {
-		logger.Error(err, "failed to fetch quality playlist")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch playlist"})
+	quality := c.Param("quality")
+	if !model.IsAvailableQuality(quality) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quality"})
 		return
 	}
-	defer resp.Body.Close()
 
-	// read playlist content
-	content, err := io.ReadAll(resp.Body)
-	if err != nil {
-		logger.Error(err, "failed to read quality playlist")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read playlist"})
+	movie := sc.requireAvailableMovie(c, movieID)
+	if movie == nil {
 		return
 	}
 
-	// rewrite playlist to use proxy URLs for segments
-	playlistContent := string(content)
-	lines := strings.Split(playlistContent, "\n")
-
-	for i, line := range lines {
-		trimmedLine := strings.TrimSpace(line)
-		// rewrite segment URLs to go through proxy
-		if trimmedLine != "" && !strings.HasPrefix(trimmedLine, "#") && strings.HasSuffix(trimmedLine, ".ts") {
-			// convert "segment0.ts" to "/api/v1/stream/movieID/quality/segment0.ts"
-			proxyURL := fmt.Sprintf("/api/v1/stream/%s/%s/%s", movieID.String(), quality, trimmedLine)
-			if guestToken := c.Query("guestToken"); guestToken != "" {
-				proxyURL += fmt.Sprintf("?guestToken=%s", guestToken)
-			}
-			lines[i] = proxyURL
-		}
-	}
-
-	rewrittenContent := strings.Join(lines, "\n")
-
-	// set CDN cache headers with auth awareness
-	c.Header("Cache-Control", "public, max-age=1800") // 30 minutes
-	c.Header("Vary", "Authorization")
-	c.Header("X-Auth-Hash", authHash)
-	c.Header("Content-Type", "application/vnd.apple.mpegurl")
-
-	// return rewritten playlist content
-	c.String(http.StatusOK, rewrittenContent)
+	quality = resolveQuality(c, movie, quality)
+	sc.servePlaylist(c, movie, quality+"/playlist.m3u8")
 }
 
-// GetMasterPlaylistURL handles GET /api/v1/stream/{movieId}/playlist.m3u8
-// Returns a signed URL for direct access to the master playlist
-func (sc *StreamingController) GetMasterPlaylistURL(c *gin.Context) {
-	movieIDStr := c.Param("movieId")
-	movieID, err := uuid.Parse(movieIDStr)
+// ProxyVideoSegment handles GET /api/v1/stream/{movieId}/{quality}/{segment}
+// in proxy mode the segment is streamed by the API, otherwise the client is redirected to a storage URL signed for
+// the current time window
+func (sc *StreamingController) ProxyVideoSegment(c *gin.Context) {
+	movieID, err := uuid.Parse(c.Param("movieId"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
 		return
 	}
 
-	// construct path for master playlist
-	masterPath := fmt.Sprintf("hls/%s/master.m3u8", movieID.String())
-
-	// generate signed URL for CDN access
-	opts := &storage.CDNSignedURLOptions{
-		ExpiresIn:    time.Hour * 2,         // 2 hours expiration
-		CacheControl: "public, max-age=300", // cache for 5 minutes
-		ContentType:  "application/vnd.apple.mpegurl",
-	}
-
-	signedURL, err := sc.storageProvider.GenerateCDNSignedURL(c.Request.Context(), masterPath, opts)
-	if err != nil {
-		logger.Error(err, "failed to generate signed URL for master playlist")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate playlist URL"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"url":        signedURL,
-		"expires_in": opts.ExpiresIn.Seconds(),
-		"type":       "master_playlist",
-	})
-}
-
-// GetMediaPlaylistURL handles GET /api/v1/stream/{movieId}/{quality}/playlist.m3u8
-// Returns a signed URL for direct access to the quality-specific playlist
-func (sc *StreamingController) GetMediaPlaylistURL(c *gin.Context) {
-	movieIDStr := c.Param("movieId")
 	quality := c.Param("quality")
-
-	movieID, err := uuid.Parse(movieIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
-		return
-	}
-
-	// validate quality parameter
-	if quality == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "quality parameter required"})
+	segment := c.Param("segment")
+	if !model.IsAvailableQuality(quality) || !strings.HasSuffix(segment, ".ts") || strings.Contains(segment, "..") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid segment"})
 		return
 	}
 
-	// construct path for quality-specific playlist
-	playlistPath := fmt.Sprintf("hls/%s/%s.m3u8", movieID.String(), quality)
-
-	// generate signed URL for CDN access
-	opts := &storage.CDNSignedURLOptions{
-		ExpiresIn:    time.Hour * 2,         // 2 hours expiration
-		CacheControl: "public, max-age=300", // cache for 5 minutes
-		ContentType:  "application/vnd.apple.mpegurl",
-	}
-
-	signedURL, err := sc.storageProvider.GenerateCDNSignedURL(c.Request.Context(), playlistPath, opts)
-	if err != nil {
-		logger.Error(err, "failed to generate signed URL for media playlist")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate playlist URL"})
+	movie := sc.requireAvailableMovie(c, movieID)
+	if movie == nil {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"url":        signedURL,
-		"expires_in": opts.ExpiresIn.Seconds(),
-		"type":       "media_playlist",
-		"quality":    quality,
-	})
-}
+	// every quality is segmented on the same boundaries, so the substitute's segment covers the same time
+	quality = resolveQuality(c, movie, quality)
+	segmentPath := hlsStoragePath(movieID, quality+"/"+segment)
+	authHash := sc.generateAuthHashFromContext(c, movieID)
 
-// GetVideoSegmentURL handles GET /api/v1/stream/{movieId}/{quality}/segment{n}.ts
-// Returns a signed URL for direct access to the video segment
-func (sc *StreamingController) GetVideoSegmentURL(c *gin.Context) {
-	movieIDStr := c.Param("movieId")
-	quality := c.Param("quality")
-	segment := c.Param("segment")
+	if sc.streaming.EffectiveMode() == config.StreamingModeProxy {
+		c.Header("Vary", "Authorization")
+		c.Header("X-Auth-Hash", authHash)
 
-	movieID, err := uuid.Parse(movieIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
-		return
-	}
-
-	// validate parameters
-	if quality == "" || segment == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "quality and segment parameters required"})
+		// segments never change once transcoded
+		err = proxyStorageObject(c, sc.storageProvider, segmentPath, storage.SegmentContentType, "private, max-age=86400, immutable")
+		if err != nil {
+			if errors.Is(err, errStorageObjectNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "segment not found"})
+				return
+			}
+			logger.Error(err, "failed to proxy video segment")
+			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to fetch segment"})
+		}
 		return
 	}
 
-	// construct path for video segment
-	segmentPath := fmt.Sprintf("hls/%s/%s", movieID.String(), segment)
-
-	// generate signed URL for CDN access
-	opts := &storage.CDNSignedURLOptions{
-		ExpiresIn:    time.Hour * 24,          // 24 hours expiration for segments
-		CacheControl: "public, max-age=86400", // cache segments for 24 hours
-		ContentType:  "video/mp2t",
-	}
-
-	signedURL, err := sc.storageProvider.GenerateCDNSignedURL(c.Request.Context(), segmentPath, opts)
+	// the signature expires with its time window, so a leaked segment URL stops working within minutes
+	signedURL, window, reuseFor, err := sc.timeWindowSegmentURL(c.Request.Context(), segmentPath, time.Now())
 	if err != nil {
 		logger.Error(err, "failed to generate signed URL for video segment")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate segment URL"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"url":        signedURL,
-		"expires_in": opts.ExpiresIn.Seconds(),
-		"type":       "video_segment",
-		"quality":    quality,
-		"segment":    segment,
-	})
+	// the redirect carries a per-viewer signature, only the viewer's own cache may keep it until the window ends
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(reuseFor.Seconds())))
+	c.Header("Vary", "Authorization")
+	c.Header("X-Auth-Hash", authHash)
+	c.Header("X-Time-Window", fmt.Sprintf("%d", window.Unix()))
+	c.Header("X-Content-Type", storage.SegmentContentType)
+
+	c.Redirect(http.StatusFound, signedURL)
 }
 
-// GetVideoURL handles GET /api/v1/stream/{movieId}/video
-// Returns a signed URL for direct access to the original video file
-func (sc *StreamingController) GetVideoURL(c *gin.Context) {
-	movieIDStr := c.Param("movieId")
-	movieID, err := uuid.Parse(movieIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
-		return
-	}
+// segmentSigningWindow is how long one time-window signature is handed out for
+const segmentSigningWindow = 5 * time.Minute
 
-	// for now, we need to find the original file from the movie record
-	// since the uploads path includes a timestamp. Let's modify this to query the database
-	// for the actual original file path
-	videoPath := fmt.Sprintf("uploads/%s", movieID.String()) // placeholder - will need movie service
+// segmentSigningGrace keeps a URL handed out at the end of a window valid while the segment downloads
+const segmentSigningGrace = time.Minute
 
-	// generate signed URL for CDN access with range support
-	opts := &storage.CDNSignedURLOptions{
-		ExpiresIn:    time.Hour * 4,          // 4 hours expiration for video files
-		CacheControl: "public, max-age=3600", // cache for 1 hour
-		ContentType:  "video/mp4",
-	}
+// signingWindow returns the window now falls in, how long a URL signed now must stay valid
+// and how long clients may reuse it. signatures die shortly after their window instead of lasting a day.
+func signingWindow(now time.Time) (window time.Time, expiresIn, reuseFor time.Duration) {
+	window = now.Truncate(segmentSigningWindow)
+	reuseFor = window.Add(segmentSigningWindow).Sub(now)
+	return window, reuseFor + segmentSigningGrace, reuseFor
+}
 
-	signedURL, err := sc.storageProvider.GenerateCDNSignedURL(c.Request.Context(), videoPath, opts)
-	if err != nil {
-		logger.Error(err, "failed to generate signed URL for video")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate video URL"})
-		return
-	}
+// timeWindowSegmentURL signs a segment URL that expires with the current time window.
+// the signature already encodes the expiry, so the URL needs no extra window marker to vary per window.
+func (sc *StreamingController) timeWindowSegmentURL(ctx context.Context, segmentPath string, now time.Time) (string, time.Time, time.Duration, error) {
+	window, expiresIn, reuseFor := signingWindow(now)
 
-	c.JSON(http.StatusOK, gin.H{
-		"url":            signedURL,
-		"expires_in":     opts.ExpiresIn.Seconds(),
-		"type":           "video",
-		"supports_range": true, // CDN/storage providers typically support HTTP Range requests
+	signedURL, err := sc.storageProvider.GenerateCDNSignedURL(ctx, segmentPath, &storage.CDNSignedURLOptions{
+		ExpiresIn:    expiresIn,
+		CacheControl: fmt.Sprintf("private, max-age=%d", int(expiresIn.Seconds())),
+		ContentType:  storage.SegmentContentType,
 	})
+	if err != nil {
+		return "", time.Time{}, 0, err
+	}
+	return signedURL, window, reuseFor, nil
 }
 
 // GetMultipleURLs handles POST /api/v1/stream/{movieId}/urls
-// Returns signed URLs for multiple files (playlists and segments) in a single request
+// Returns URLs for multiple files (playlists and segments) in a single request
 func (sc *StreamingController) GetMultipleURLs(c *gin.Context) {
 	movieIDStr := c.Param("movieId")
 	movieID, err := uuid.Parse(movieIDStr)
@@ -623,64 +286,43 @@ func (sc *StreamingController) GetMultipleURLs(c *gin.Context) {
 	}
 
 	// verify movie exists and is available
-	movie, err := sc.movieService.GetMovie(c.Request.Context(), movieID)
+	movie, err := sc.movieService.GetStreamingMovie(c.Request.Context(), movieID)
 	if err != nil {
 		logger.Error(err, "failed to get movie for batch URL access")
-		c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
+		respondMovieNotFound(c, sc.movieService, movieID)
 		return
 	}
 
 	if movie.Status != "available" {
-		c.JSON(http.StatusConflict, gin.H{
-			"error":  "video not ready",
-			"status": movie.Status,
-		})
+		respondMovieNotReady(c, movie)
 		return
 	}
 
-	// build full paths for requested files
-	basePath := "hls/" + movieID.String() + "/"
-	fullPaths := make([]string, len(request.Files))
-	for i, file := range request.Files {
-		// check if file already contains the full path (avoid duplication)
-		if strings.HasPrefix(file, basePath) {
-			fullPaths[i] = file
-		} else {
-			fullPaths[i] = basePath + file
+	// generate URLs for all files under the configured streaming mode, playlists and segments are cached differently
+	expiresIn := time.Hour * 2 // 2 hours expiration
+	signedURLs := make(map[string]string, len(request.Files))
+	for _, files := range splitPlaylistFiles(request.Files) {
+		if len(files) == 0 {
+			continue
 		}
-	}
-
-	// generate signed URLs for all paths
-	opts := &storage.CDNSignedURLOptions{
-		ExpiresIn:    time.Hour * 2,          // 2 hours expiration
-		CacheControl: "public, max-age=3600", // cache for 1 hour
-	}
 
-	signedURLs, err := sc.storageProvider.GenerateSignedURLs(c.Request.Context(), fullPaths, opts)
-	if err != nil {
-		logger.Error(err, "failed to generate multiple signed URLs")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate URLs"})
-		return
+		urls, err := hlsFileURLs(c, sc.storageProvider, sc.streaming.EffectiveMode(), movieID, files, &storage.CDNSignedURLOptions{
+			ExpiresIn:    expiresIn,
+			CacheControl: hlsCacheControl(files[0], sc.streaming.PlaylistMaxAge()),
+		})
+		if err != nil {
+			logger.Error(err, "failed to generate multiple signed URLs")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate URLs"})
+			return
+		}
+		for file, url := range urls {
+			signedURLs[file] = url
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"urls":       signedURLs,
-		"expires_in": opts.ExpiresIn.Seconds(),
+		"expires_in": expiresIn.Seconds(),
 		"count":      len(signedURLs),
 	})
 }
-
-// GetMasterPlaylistProxy handles GET /api/v1/stream/{movieId}/playlist.m3u8
-func (sc *StreamingController) GetMasterPlaylistProxy(c *gin.Context) {
-	sc.ProxyMasterPlaylist(c)
-}
-
-// GetMediaPlaylistProxy handles GET /api/v1/stream/{movieId}/{quality}/playlist.m3u8
-func (sc *StreamingController) GetMediaPlaylistProxy(c *gin.Context) {
-	sc.ProxyQualityPlaylist(c)
-}
-
-// GetVideoSegmentProxy handles GET /api/v1/stream/{movieId}/{quality}/{segment}
-func (sc *StreamingController) GetVideoSegmentProxy(c *gin.Context) {
-	sc.ProxyVideoSegment(c)
-}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"watch-party/pkg/config"
+	"watch-party/pkg/model"
+	"watch-party/pkg/storage"
+	movieService "watch-party/service-api/internal/service/movie"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signingStorage records the options segment URLs are signed with
+type signingStorage struct {
+	storage.Provider
+	opts []*storage.CDNSignedURLOptions
+}
+
+func (s *signingStorage) GenerateCDNSignedURL(ctx context.Context, path string, opts *storage.CDNSignedURLOptions) (string, error) {
+	s.opts = append(s.opts, opts)
+	return "https://storage.example.com/" + path + "?X-Amz-Expires=" + opts.ExpiresIn.String(), nil
+}
+
+func TestSigningWindow(t *testing.T) {
+	windowStart := time.Date(2024, 1, 1, 12, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		now          time.Time
+		wantReuseFor time.Duration
+	}{
+		{name: "window start", now: windowStart, wantReuseFor: 5 * time.Minute},
+		{name: "mid window", now: windowStart.Add(2 * time.Minute), wantReuseFor: 3 * time.Minute},
+		{name: "window end", now: windowStart.Add(5*time.Minute - time.Second), wantReuseFor: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, expiresIn, reuseFor := signingWindow(tt.now)
+
+			assert.Equal(t, windowStart, window)
+			assert.Equal(t, tt.wantReuseFor, reuseFor)
+			// every signature of a window expires at the same moment, shortly after the window
+			assert.Equal(t, windowStart.Add(segmentSigningWindow+segmentSigningGrace), tt.now.Add(expiresIn))
+		})
+	}
+
+	nextWindow, _, _ := signingWindow(windowStart.Add(5 * time.Minute))
+	assert.Equal(t, windowStart.Add(5*time.Minute), nextWindow)
+}
+
+func TestTimeWindowSegmentURL(t *testing.T) {
+	provider := &signingStorage{}
+	sc := &StreamingController{storageProvider: provider}
+	now := time.Date(2024, 1, 1, 12, 7, 30, 0, time.UTC)
+
+	signedURL, window, reuseFor, err := sc.timeWindowSegmentURL(context.Background(), "hls/movie/720p/segment_001.ts", now)
+	require.NoError(t, err)
+
+	// the signature encodes the expiry, no separate window marker is added
+	assert.NotContains(t, signedURL, "tw=")
+	assert.True(t, strings.HasPrefix(signedURL, "https://storage.example.com/hls/movie/720p/segment_001.ts?"))
+	assert.Equal(t, time.Date(2024, 1, 1, 12, 5, 0, 0, time.UTC), window)
+	assert.Equal(t, 2*time.Minute+30*time.Second, reuseFor)
+
+	require.Len(t, provider.opts, 1)
+	opts := provider.opts[0]
+	assert.Equal(t, 3*time.Minute+30*time.Second, opts.ExpiresIn)
+	// storage must not let shared caches keep the object past the signature
+	assert.Equal(t, "private, max-age=210", opts.CacheControl)
+	assert.Equal(t, "video/mp2t", opts.ContentType)
+}
+
+// streamingMovies serves one available movie to the streaming routes
+type streamingMovies struct {
+	movieService.Service
+	movie *model.Movie
+}
+
+func (s *streamingMovies) GetStreamingMovie(ctx context.Context, id uuid.UUID) (*model.Movie, error) {
+	return s.movie, nil
+}
+
+func TestProxyVideoSegmentRedirect(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	movieID := uuid.New()
+	provider := &signingStorage{}
+	sc := NewStreamingController(provider, &streamingMovies{movie: &model.Movie{ID: movieID, Status: model.StatusAvailable}},
+		config.StreamingConfig{Mode: config.StreamingModeRedirect})
+
+	router := gin.New()
+	router.GET("/api/v1/stream/:movieId/:quality/:segment", sc.ProxyVideoSegment)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/stream/"+movieID.String()+"/720p/segment_001.ts", nil))
+
+	require.Equal(t, http.StatusFound, rec.Code)
+	assert.True(t, strings.HasPrefix(rec.Header().Get("Location"), "https://storage.example.com/hls/"+movieID.String()+"/720p/segment_001.ts?"))
+
+	// the segment route signs for the current window instead of handing out day-long signatures
+	require.Len(t, provider.opts, 1)
+	assert.LessOrEqual(t, provider.opts[0].ExpiresIn, segmentSigningWindow+segmentSigningGrace)
+
+	maxAge, found := strings.CutPrefix(rec.Header().Get("Cache-Control"), "private, max-age=")
+	require.True(t, found, "the redirect must not be kept by shared caches")
+	seconds, err := strconv.Atoi(maxAge)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, time.Duration(seconds)*time.Second, segmentSigningWindow)
+	assert.NotEmpty(t, rec.Header().Get("X-Time-Window"))
+}
@@ -0,0 +1,227 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"watch-party/pkg/config"
+	"watch-party/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var errStorageObjectNotFound = errors.New("storage object not found")
+
+// storageFetchTimeout bounds how long the API waits on storage when it serves a file itself
+const storageFetchTimeout = 30 * time.Second
+
+// storageFetchClient fetches objects from storage for playlists and proxied segments
+var storageFetchClient = &http.Client{Timeout: storageFetchTimeout}
+
+// hlsStoragePath returns where a file of a movie's HLS output is stored, files may already carry the prefix
+func hlsStoragePath(movieID uuid.UUID, file string) string {
+	basePath := "hls/" + movieID.String() + "/"
+	if strings.HasPrefix(file, basePath) {
+		return file
+	}
+	return basePath + file
+}
+
+// requestBaseURL returns the scheme and host the client used to reach the API, honoring a TLS-terminating proxy
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// streamingGuestToken returns the guest token the request was authenticated with, empty for users
+func streamingGuestToken(c *gin.Context) string {
+	if c.GetString("auth_type") != "guest" {
+		return ""
+	}
+	if token := c.Query("token"); token != "" {
+		return token
+	}
+	return c.GetHeader("X-Guest-Token")
+}
+
+// streamFileURL returns the API URL serving a file of a movie's HLS output, guests keep their token on it
+func streamFileURL(c *gin.Context, movieID uuid.UUID, file string) string {
+	file = strings.TrimPrefix(file, "hls/"+movieID.String()+"/")
+	streamURL := fmt.Sprintf("%s/api/v1/stream/%s/%s", requestBaseURL(c), movieID.String(), file)
+	if guestToken := streamingGuestToken(c); guestToken != "" {
+		streamURL += "?token=" + url.QueryEscape(guestToken)
+	}
+	return streamURL
+}
+
+// hlsFileURLs returns the URLs clients fetch HLS files from under the streaming mode, keyed by the requested names.
+// direct mode signs storage URLs, proxy and redirect point at the stream routes which decide per request.
+func hlsFileURLs(c *gin.Context, storageProvider storage.Provider, mode string, movieID uuid.UUID, files []string, opts *storage.CDNSignedURLOptions) (map[string]string, error) {
+	fileURLs := make(map[string]string, len(files))
+	if mode != config.StreamingModeDirect {
+		for _, file := range files {
+			fileURLs[file] = streamFileURL(c, movieID, file)
+		}
+		return fileURLs, nil
+	}
+
+	fullPaths := make([]string, len(files))
+	for i, file := range files {
+		fullPaths[i] = hlsStoragePath(movieID, file)
+	}
+
+	signedURLs, err := storageProvider.GenerateSignedURLs(c.Request.Context(), fullPaths, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// map back to original file names
+	for i, file := range files {
+		if signedURL, exists := signedURLs[fullPaths[i]]; exists {
+			fileURLs[file] = signedURL
+		}
+	}
+	return fileURLs, nil
+}
+
+// openStorageObject starts reading an object through a short-lived signed URL, forwarding the client's range
+func openStorageObject(ctx context.Context, storageProvider storage.Provider, storagePath, rangeHeader string) (*http.Response, error) {
+	signedURL, err := storageProvider.GenerateCDNSignedURL(ctx, storagePath, &storage.CDNSignedURLOptions{
+		ExpiresIn: 5 * time.Minute,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign storage URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, signedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if rangeHeader != "" {
+		req.Header.Set("Range", rangeHeader)
+	}
+
+	resp, err := storageFetchClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch from storage: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, errStorageObjectNotFound
+	case resp.StatusCode >= http.StatusBadRequest && resp.StatusCode != http.StatusRequestedRangeNotSatisfiable:
+		resp.Body.Close()
+		return nil, fmt.Errorf("storage returned status %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// readStorageObject reads a small object such as a playlist from storage
+func readStorageObject(ctx context.Context, storageProvider storage.Provider, storagePath string) ([]byte, error) {
+	resp, err := openStorageObject(ctx, storageProvider, storagePath, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+// proxyStorageObject streams an object from storage to the client without exposing the storage URL
+func proxyStorageObject(c *gin.Context, storageProvider storage.Provider, storagePath, contentType, cacheControl string) error {
+	resp, err := openStorageObject(c.Request.Context(), storageProvider, storagePath, c.GetHeader("Range"))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// range responses must keep their framing headers for players to seek
+	for _, header := range []string{"Content-Length", "Content-Range", "Accept-Ranges", "ETag", "Last-Modified"} {
+		if value := resp.Header.Get(header); value != "" {
+			c.Header(header, value)
+		}
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Cache-Control", cacheControl)
+	c.Status(resp.StatusCode)
+
+	// the client may go away mid-segment, nothing useful can be sent after the headers
+	_, _ = io.Copy(c.Writer, resp.Body)
+	return nil
+}
+
+// withGuestToken appends the guest token to every URI line of a playlist so players stay authorized.
+// the URIs are relative, so they already resolve against the stream route that served the playlist.
+// the key URI of an encrypted playlist points at the API and gets the token as well.
+func withGuestToken(playlist, guestToken string) string {
+	if guestToken == "" {
+		return playlist
+	}
+
+	lines := strings.Split(playlist, "\n")
+	for i, line := range lines {
+		trimmedLine := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmedLine, "#EXT-X-KEY:") {
+			lines[i] = withKeyURIToken(trimmedLine, guestToken)
+			continue
+		}
+		if trimmedLine == "" || strings.HasPrefix(trimmedLine, "#") {
+			continue
+		}
+
+		lines[i] = withTokenQuery(trimmedLine, guestToken)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// withKeyURIToken appends the guest token to the URI attribute of an #EXT-X-KEY tag
+func withKeyURIToken(tag, guestToken string) string {
+	const attribute = `URI="`
+	start := strings.Index(tag, attribute)
+	if start < 0 {
+		return tag
+	}
+	start += len(attribute)
+	end := strings.Index(tag[start:], `"`)
+	if end < 0 {
+		return tag
+	}
+	end += start
+
+	return tag[:start] + withTokenQuery(tag[start:end], guestToken) + tag[end:]
+}
+
+// withTokenQuery appends the guest token to the query of uri
+func withTokenQuery(uri, guestToken string) string {
+	separator := "?"
+	if strings.Contains(uri, "?") {
+		separator = "&"
+	}
+	return uri + separator + "token=" + url.QueryEscape(guestToken)
+}
+
+// checkBatchSize responds with an error and returns false when a batch URL request asks for more files than configured
+func checkBatchSize(c *gin.Context, files int, streaming config.StreamingConfig) bool {
+	limit := streaming.BatchURLLimit()
+	if files > limit {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     fmt.Sprintf("too many files requested (max %d)", limit),
+			"max_files": limit,
+		})
+		return false
+	}
+	return true
+}
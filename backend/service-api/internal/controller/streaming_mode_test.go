@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithGuestToken(t *testing.T) {
+	playlist := "#EXTM3U\n" +
+		`#EXT-X-KEY:METHOD=AES-128,URI="http://localhost:8080/api/v1/videos/42/key"` + "\n" +
+		"#EXTINF:6.0,\n" +
+		"segment_000.ts\n" +
+		"#EXTINF:6.0,\n" +
+		"segment_001.ts?v=2\n"
+
+	assert.Equal(t, "#EXTM3U\n"+
+		`#EXT-X-KEY:METHOD=AES-128,URI="http://localhost:8080/api/v1/videos/42/key?token=a%2Fb"`+"\n"+
+		"#EXTINF:6.0,\n"+
+		"segment_000.ts?token=a%2Fb\n"+
+		"#EXTINF:6.0,\n"+
+		"segment_001.ts?v=2&token=a%2Fb\n", withGuestToken(playlist, "a/b"))
+
+	assert.Equal(t, playlist, withGuestToken(playlist, ""))
+	assert.Equal(t, "#EXT-X-KEY:METHOD=NONE", withKeyURIToken("#EXT-X-KEY:METHOD=NONE", "a"))
+}
@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	movieService "watch-party/service-api/internal/service/movie"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// subtitleFileName matches the stored name of a subtitle track, the language tag with a .vtt extension
+var subtitleFileName = regexp.MustCompile(`^[A-Za-z0-9-]+\.vtt$`)
+
+// UploadSubtitle handles POST /api/v1/movies/:id/subtitles - uploader or admin.
+// the multipart form carries the SRT or WebVTT "file", its "language" and an optional "label".
+func (mc *MovieController) UploadSubtitle(c *gin.Context) {
+	userIDValue, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, ok := userIDValue.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	movieID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "subtitle file is required"})
+		return
+	}
+	if file.Size > model.MaxSubtitleFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": movieService.ErrSubtitleTooLarge.Error()})
+		return
+	}
+
+	opened, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read subtitle file"})
+		return
+	}
+	defer opened.Close()
+
+	// the size check above trusts the client, never read more than the limit allows
+	content, err := io.ReadAll(io.LimitReader(opened, model.MaxSubtitleFileSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read subtitle file"})
+		return
+	}
+
+	isAdmin := c.GetString("user_role") == model.RoleAdmin
+	subtitle, err := mc.movieService.UploadSubtitle(c.Request.Context(), movieID, userID, isAdmin, &model.UploadSubtitleRequest{
+		Language: c.PostForm("language"),
+		Label:    c.PostForm("label"),
+		Filename: file.Filename,
+		Content:  content,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, movieService.ErrMovieNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
+		case errors.Is(err, movieService.ErrAccessDenied):
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		case errors.Is(err, movieService.ErrSubtitleTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, movieService.ErrInvalidSubtitle), errors.Is(err, movieService.ErrInvalidLanguage):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			logger.Error(err, "failed to upload subtitle")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to upload subtitle"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, subtitle)
+}
+
+// ListSubtitles handles GET /api/v1/movies/:id/subtitles - uploader or admin, viewers use the /videos route
+func (mc *MovieController) ListSubtitles(c *gin.Context) {
+	userIDValue, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, ok := userIDValue.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	movieID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
+		return
+	}
+
+	movie, err := mc.movieService.GetMovie(c.Request.Context(), movieID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
+		return
+	}
+	if c.GetString("user_role") != model.RoleAdmin && movie.UploadedBy != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	subtitles, err := mc.movieService.ListSubtitles(c.Request.Context(), movieID)
+	if err != nil {
+		logger.Error(err, "failed to list subtitles")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list subtitles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, subtitles)
+}
+
+// GetSubtitles handles GET /api/v1/videos/:movieId/subtitles, the subtitle tracks for players
+func (vac *VideoAccessController) GetSubtitles(c *gin.Context) {
+	movieID, err := uuid.Parse(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
+		return
+	}
+
+	// authentication is already handled by middleware
+	subtitles, err := vac.movieService.ListSubtitles(c.Request.Context(), movieID)
+	if err != nil {
+		if errors.Is(err, movieService.ErrMovieNotFound) {
+			respondMovieNotFound(c, vac.movieService, movieID)
+			return
+		}
+		logger.Error(err, "failed to list subtitles")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list subtitles"})
+		return
+	}
+
+	// outside direct mode storage URLs must not reach players, the stream route serves the tracks
+	if vac.streaming.EffectiveMode() != config.StreamingModeDirect {
+		for i := range subtitles.Subtitles {
+			subtitles.Subtitles[i].URL = streamFileURL(c, movieID, "subtitles/"+subtitles.Subtitles[i].Language+".vtt")
+		}
+	}
+
+	c.JSON(http.StatusOK, subtitles)
+}
+
+// ProxySubtitle handles GET /api/v1/stream/:movieId/subtitles/:file
+func (sc *StreamingController) ProxySubtitle(c *gin.Context) {
+	movieID, err := uuid.Parse(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
+		return
+	}
+
+	file := c.Param("file")
+	if !subtitleFileName.MatchString(file) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subtitle file"})
+		return
+	}
+
+	storagePath := "subtitles/" + movieID.String() + "/" + file
+	err = proxyStorageObject(c, sc.storageProvider, storagePath, "text/vtt", "private, max-age=3600")
+	if err != nil {
+		if errors.Is(err, errStorageObjectNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "subtitles not found"})
+			return
+		}
+		logger.Error(err, "failed to proxy subtitles")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to fetch subtitles"})
+	}
+}
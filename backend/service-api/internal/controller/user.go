@@ -1,11 +1,16 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
+	"strings"
+	"watch-party/pkg/auth"
 	"watch-party/pkg/logger"
 	"watch-party/pkg/model"
+	userService "watch-party/service-api/internal/service/user"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // RegisterUser handles user registration
@@ -35,3 +40,109 @@ func (ctrl *controller) RegisterUser(c *gin.Context) {
 		"user":    user.ToProfile(),
 	})
 }
+
+// UpdateProfile changes the display name and avatar of the current user
+func (ctrl *controller) UpdateProfile(c *gin.Context) {
+	userClaims, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	claims, ok := userClaims.(*auth.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
+		return
+	}
+
+	var req model.UpdateProfileRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		logger.Error(err, "failed to bind update profile request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload"})
+		return
+	}
+
+	user, err := ctrl.userService.UpdateProfile(claims.UserID, &req)
+	if err != nil {
+		logger.Error(err, "failed to update profile")
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid profile"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case err.Error() == "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user": user.ToProfile(),
+	})
+}
+
+// UploadAvatar stores an uploaded avatar image and points the current user's profile at it
+func (ctrl *controller) UploadAvatar(c *gin.Context) {
+	userClaims, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	claims, ok := userClaims.(*auth.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
+		return
+	}
+
+	file, err := c.FormFile("avatar")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "avatar file is required"})
+		return
+	}
+
+	user, err := ctrl.userService.UploadAvatar(c.Request.Context(), claims.UserID, file)
+	if err != nil {
+		logger.Error(err, "failed to upload avatar")
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid avatar"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case err.Error() == "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case err.Error() == "avatar uploads are not available":
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "avatar uploads are not available"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user": user.ToProfile(),
+	})
+}
+
+// GetAvatar redirects to a short-lived storage URL of a user's uploaded avatar
+func (ctrl *controller) GetAvatar(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	avatarURL, err := ctrl.userService.GetAvatarURL(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, userService.ErrAvatarNotFound) || err.Error() == "user not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "avatar not found"})
+			return
+		}
+		logger.Error(err, "failed to generate avatar URL")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// the storage URL expires, browsers must come back here rather than cache the redirect for long
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Redirect(http.StatusFound, avatarURL)
+}
@@ -1,14 +1,14 @@
 package controller
 
 import (
-	"context"
 	"fmt"
 	"net/http"
-	"strconv"
-	"strings"
 	"time"
+	"watch-party/pkg/config"
 	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
 	"watch-party/pkg/storage"
+	"watch-party/pkg/video"
 	movieService "watch-party/service-api/internal/service/movie"
 	roomService "watch-party/service-api/internal/service/room"
 
@@ -21,63 +21,61 @@ type VideoAccessController struct {
 	storageProvider storage.Provider
 	movieService    movieService.Service
 	roomService     *roomService.Service
+	streaming       config.StreamingConfig
 }
 
 // NewVideoAccessController creates a new video access controller
-func NewVideoAccessController(storageProvider storage.Provider, movieService movieService.Service, roomService *roomService.Service) *VideoAccessController {
+func NewVideoAccessController(storageProvider storage.Provider, movieService movieService.Service, roomService *roomService.Service, streaming config.StreamingConfig) *VideoAccessController {
 	return &VideoAccessController{
 		storageProvider: storageProvider,
 		movieService:    movieService,
 		roomService:     roomService,
+		streaming:       streaming,
 	}
 }
 
 // validateGuestAccess validates guest token and checks if guest has access to the movie
 func (vac *VideoAccessController) validateGuestAccess(ctx *gin.Context, guestToken string, movieID uuid.UUID) (*uuid.UUID, error) {
-	if len(guestToken) < 32 {
-		return nil, fmt.Errorf("invalid guest token format")
-	}
-
-	// get guest session by token
-	guestSession, err := vac.roomService.ValidateGuestSession(ctx.Request.Context(), guestToken)
-	if err != nil {
-		logger.Error(err, "failed to validate guest session")
-		return nil, fmt.Errorf("invalid or expired guest token: %w", err)
-	}
-
-	// check if session is expired
-	if time.Now().After(guestSession.ExpiresAt) {
-		return nil, fmt.Errorf("guest session expired")
-	}
-
-	// check if the room contains the requested movie
-	hasAccess, err := vac.roomService.CheckRoomContainsMovie(ctx.Request.Context(), guestSession.RoomID, movieID)
+	guestSession, err := checkGuestMovieAccess(ctx.Request.Context(), vac.roomService, guestToken, movieID, time.Now())
 	if err != nil {
-		logger.Error(err, "failed to check room movie access for guest")
-		return nil, fmt.Errorf("failed to validate movie access: %w", err)
-	}
-
-	if !hasAccess {
-		return nil, fmt.Errorf("guest does not have access to this movie")
+		return nil, err
 	}
-
 	return &guestSession.RoomID, nil
 }
 
 // validateUserAccess validates user access to the movie
-func (vac *VideoAccessController) validateUserAccess(ctx *gin.Context, userID uuid.UUID, movieID uuid.UUID) error {
-	// check if user has access to this specific movie through room membership
-	hasAccess, err := vac.roomService.CheckUserMovieAccess(ctx.Request.Context(), userID, movieID)
-	if err != nil {
-		logger.Error(err, "failed to check user movie access")
-		return fmt.Errorf("failed to validate movie access: %w", err)
+func (vac *VideoAccessController) validateUserAccess(ctx *gin.Context, userID *uuid.UUID, movieID uuid.UUID) error {
+	return checkUserMovieAccess(ctx.Request.Context(), vac.roomService, userID, movieID)
+}
+
+// viewerIDFromContext identifies the authenticated viewer for analytics, keeping guests apart from users
+func viewerIDFromContext(c *gin.Context) string {
+	if userID, ok := c.Get("user_id"); ok {
+		if id, ok := userID.(uuid.UUID); ok {
+			return id.String()
+		}
+	}
+	if session, ok := c.Get("guest_session"); ok {
+		if guestSession, ok := session.(*model.GuestSession); ok {
+			return "guest:" + guestSession.ID.String()
+		}
 	}
+	return ""
+}
 
-	if !hasAccess {
-		return fmt.Errorf("user does not have access to this movie")
+// masterPlaylistURL returns the URL players load a movie's master playlist from. direct mode hands out a signed
+// storage URL, the other modes send players through the stream routes.
+func (vac *VideoAccessController) masterPlaylistURL(c *gin.Context, movieID uuid.UUID) (string, error) {
+	if vac.streaming.EffectiveMode() != config.StreamingModeDirect {
+		return streamFileURL(c, movieID, "master.m3u8"), nil
 	}
 
-	return nil
+	masterPath := hlsStoragePath(movieID, "master.m3u8")
+	return vac.storageProvider.GenerateCDNSignedURL(c.Request.Context(), masterPath, &storage.CDNSignedURLOptions{
+		ExpiresIn:    time.Hour * 2, // 2 hours for HLS master playlist
+		CacheControl: hlsCacheControl("master.m3u8", vac.streaming.PlaylistMaxAge()),
+		ContentType:  vac.streaming.EffectivePlaylistContentType(),
+	})
 }
 
 // GetHLSMasterPlaylistURL handles GET /api/v1/videos/{movieId}/hls
@@ -95,37 +93,34 @@ func (vac *VideoAccessController) GetHLSMasterPlaylistURL(c *gin.Context) {
 	movie, err := vac.movieService.GetMovie(c.Request.Context(), movieID)
 	if err != nil {
 		logger.Error(err, "failed to get movie for HLS access")
-		c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
+		respondMovieNotFound(c, vac.movieService, movieID)
 		return
 	}
 
 	if movie.Status != "available" {
-		c.JSON(http.StatusConflict, gin.H{
-			"error":  "video not ready",
-			"status": movie.Status,
-		})
+		respondMovieNotReady(c, movie)
 		return
 	}
 
-	// generate signed URL for master playlist
-	masterPath := "hls/" + movieID.String() + "/master.m3u8"
-
-	signedURL, err := vac.storageProvider.GenerateCDNSignedURL(c.Request.Context(), masterPath, &storage.CDNSignedURLOptions{
-		ExpiresIn:    time.Hour * 2,          // 2 hours for HLS master playlist
-		CacheControl: "public, max-age=3600", // cache for 1 hour
-		ContentType:  "application/vnd.apple.mpegurl",
-	})
+	mode := vac.streaming.EffectiveMode()
+	hlsURL, err := vac.masterPlaylistURL(c, movieID)
 	if err != nil {
 		logger.Error(err, "failed to generate signed URL for HLS master playlist")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate video access URL"})
 		return
 	}
 
+	// fetching the master playlist starts a stream session
+	if viewerID := viewerIDFromContext(c); viewerID != "" {
+		vac.movieService.RecordView(c.Request.Context(), movieID, viewerID)
+	}
+
 	// return CDN-friendly response
 	response := gin.H{
-		"movie_id":   movieID.String(),
-		"hls_url":    signedURL,
-		"expires_at": time.Now().Add(time.Hour * 2).Format(time.RFC3339),
+		"movie_id":       movieID.String(),
+		"hls_url":        hlsURL,
+		"streaming_mode": mode,
+		"expires_at":     time.Now().Add(time.Hour * 2).Format(time.RFC3339),
 		"cdn_info": gin.H{
 			"cacheable":      true,
 			"cache_duration": "1h",
@@ -160,8 +155,7 @@ func (vac *VideoAccessController) GetVideoFileURLs(c *gin.Context) {
 	// authentication is already handled by middleware
 
 	// validate file count to prevent abuse and ensure reasonable batch sizes
-	if len(request.Files) > 1000 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "too many files requested (max 1000)"})
+	if !checkBatchSize(c, len(request.Files), vac.streaming) {
 		return
 	}
 
@@ -174,34 +168,19 @@ func (vac *VideoAccessController) GetVideoFileURLs(c *gin.Context) {
 	movie, err := vac.movieService.GetMovie(c.Request.Context(), movieID)
 	if err != nil {
 		logger.Error(err, "failed to get movie for batch URL access")
-		c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
+		respondMovieNotFound(c, vac.movieService, movieID)
 		return
 	}
 
 	if movie.Status != "available" {
-		c.JSON(http.StatusConflict, gin.H{
-			"error":  "video not ready",
-			"status": movie.Status,
-		})
+		respondMovieNotReady(c, movie)
 		return
 	}
 
-	// build full paths for requested files
-	basePath := "hls/" + movieID.String() + "/"
-	fullPaths := make([]string, len(request.Files))
-	for i, file := range request.Files {
-		// check if file already contains the full path (avoid duplication)
-		if strings.HasPrefix(file, basePath) {
-			fullPaths[i] = file
-		} else {
-			fullPaths[i] = basePath + file
-		}
-	}
+	logger.Infof("generating URLs for movieID=%s, files=%v", movieID.String(), request.Files)
 
-	logger.Infof("generating signed URLs for movieID=%s, basePath=%s, files=%v", movieID.String(), basePath, fullPaths)
-
-	// generate signed URLs for all files with enhanced security
-	signedURLs, err := vac.storageProvider.GenerateSignedURLs(c.Request.Context(), fullPaths, &storage.CDNSignedURLOptions{
+	// generate URLs for all files under the configured streaming mode
+	fileURLs, err := hlsFileURLs(c, vac.storageProvider, vac.streaming.EffectiveMode(), movieID, request.Files, &storage.CDNSignedURLOptions{
 		ExpiresIn:    time.Hour * 2,           // 2 hours for video segments
 		CacheControl: "public, max-age=86400", // cache segments for 24 hours
 	})
@@ -211,16 +190,6 @@ func (vac *VideoAccessController) GetVideoFileURLs(c *gin.Context) {
 		return
 	}
 
-	// map back to original file names
-	fileURLs := make(map[string]string)
-	for i, file := range request.Files {
-		fullPath := fullPaths[i]
-		url, exists := signedURLs[fullPath]
-		if exists {
-			fileURLs[file] = url
-		}
-	}
-
 	response := gin.H{
 		"movie_id":   movieID.String(),
 		"file_urls":  fileURLs,
@@ -244,37 +213,30 @@ func (vac *VideoAccessController) GetDirectVideoURL(c *gin.Context) {
 		return
 	}
 
+	// proxy mode promises storage URLs never reach clients
+	if vac.streaming.EffectiveMode() == config.StreamingModeProxy {
+		c.JSON(http.StatusForbidden, gin.H{"error": "direct video access is disabled in proxy streaming mode"})
+		return
+	}
+
 	// check for guest token first
 	guestToken := c.Query("guestToken")
-	var userID *uuid.UUID
-
 	if guestToken != "" {
 		// validate guest has access to a room that contains this movie
 		roomID, err := vac.validateGuestAccess(c, guestToken, movieID)
 		if err != nil {
 			logger.Error(err, "guest access validation failed for direct video")
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "access denied"})
+			respondAccessError(c, err)
 			return
 		}
 		logger.Infof("guest access validated for direct video: movie %s in room %s", movieID.String(), roomID.String())
 	} else {
-		// for authenticated users, extract user ID from JWT context
-		if userIDValue, exists := c.Get("user_id"); exists {
-			if uid, ok := userIDValue.(uuid.UUID); ok {
-				userID = &uid
-			}
-		}
-
-		if userID == nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
-			return
-		}
-
-		// validate user has access to this specific movie
-		err = vac.validateUserAccess(c, *userID, movieID)
+		// for authenticated users, validate access to this specific movie
+		userID := contextUserID(c)
+		err = vac.validateUserAccess(c, userID, movieID)
 		if err != nil {
 			logger.Error(err, "user access validation failed for direct video")
-			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			respondAccessError(c, err)
 			return
 		}
 		logger.Infof("user access validated for direct video: movie %s by user %s", movieID.String(), userID.String())
@@ -284,15 +246,12 @@ func (vac *VideoAccessController) GetDirectVideoURL(c *gin.Context) {
 	movie, err := vac.movieService.GetMovie(c.Request.Context(), movieID)
 	if err != nil {
 		logger.Error(err, "failed to get movie for direct access")
-		c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
+		respondMovieNotFound(c, vac.movieService, movieID)
 		return
 	}
 
 	if movie.Status != "available" {
-		c.JSON(http.StatusConflict, gin.H{
-			"error":  "video not ready",
-			"status": movie.Status,
-		})
+		respondMovieNotReady(c, movie)
 		return
 	}
 
@@ -337,9 +296,9 @@ func (vac *VideoAccessController) GetSegmentByTime(c *gin.Context) {
 
 	// parse request body
 	var request struct {
-		Time         float64 `json:"time" binding:"required"` // target time in seconds
-		Quality      string  `json:"quality,omitempty"`       // optional quality (e.g., "1080p"), defaults to highest
-		PreloadCount int     `json:"preload_count,omitempty"` // number of segments to preload after target (default: 3)
+		Time         *float64 `json:"time" binding:"required,gte=0"` // target time in seconds, a pointer so 0 counts as given
+		Quality      string   `json:"quality,omitempty"`             // optional quality (e.g., "1080p"), defaults to highest
+		PreloadCount int      `json:"preload_count,omitempty"`       // number of segments to preload after target (default: 3)
 	}
 
 	err = c.ShouldBindJSON(&request)
@@ -347,6 +306,7 @@ func (vac *VideoAccessController) GetSegmentByTime(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
 		return
 	}
+	targetTime := *request.Time
 
 	// set defaults
 	if request.PreloadCount <= 0 {
@@ -359,56 +319,30 @@ func (vac *VideoAccessController) GetSegmentByTime(c *gin.Context) {
 	// authentication is already handled by middleware
 	authType := c.GetString("auth_type")
 	logger.Infof("seek request for movieID=%s, time=%.2fs, quality=%s, authType=%s",
-		movieID.String(), request.Time, request.Quality, authType)
+		movieID.String(), targetTime, request.Quality, authType)
 
 	// verify movie exists and is available
 	movie, err := vac.movieService.GetMovie(c.Request.Context(), movieID)
 	if err != nil {
 		logger.Error(err, "failed to get movie for seek")
-		c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
+		respondMovieNotFound(c, vac.movieService, movieID)
 		return
 	}
 
 	if movie.Status != "available" {
-		c.JSON(http.StatusConflict, gin.H{
-			"error":  "video not ready",
-			"status": movie.Status,
-		})
+		respondMovieNotReady(c, movie)
 		return
 	}
 
-	// get playlist info from storage to calculate segment timing
-	basePath := "hls/" + movieID.String() + "/"
-
 	// determine quality - for now use 1080p as default
 	quality := request.Quality
 	if quality == "" {
 		quality = "1080p"
 	}
+	quality = resolveQuality(c, movie, quality)
 
-	playlistPath := basePath + quality + "/playlist.m3u8"
-
-	// get signed URL for playlist to read segment information
-	playlistURLs, err := vac.storageProvider.GenerateSignedURLs(c.Request.Context(), []string{playlistPath}, &storage.CDNSignedURLOptions{
-		ExpiresIn:    time.Minute * 10, // short expiry for playlist
-		CacheControl: "private, max-age=60",
-	})
-	if err != nil {
-		logger.Error(err, "failed to generate playlist URL for seek")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to access video playlist"})
-		return
-	}
-
-	playlistURL, exists := playlistURLs[playlistPath]
-	if !exists {
-		logger.Error(fmt.Errorf("playlist URL not generated"), "playlist URL missing")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "playlist URL not available"})
-		return
-	}
-
-	// fetch and parse playlist to find target segment
-	// NOTE: In a production system, you'd want to cache playlist parsing results
-	segments, totalDuration, err := vac.parsePlaylistForSeek(c.Request.Context(), playlistURL)
+	// the parsed playlist is cached, so repeated seeks don't fetch it again
+	segments, totalDuration, err := vac.movieService.GetSegments(c.Request.Context(), movieID, quality)
 	if err != nil {
 		logger.Error(err, "failed to parse playlist for seek")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse video playlist"})
@@ -416,11 +350,11 @@ func (vac *VideoAccessController) GetSegmentByTime(c *gin.Context) {
 	}
 
 	// find target segment and preload segments
-	targetSegmentIndex, segmentStartTime := vac.findSegmentByTime(segments, request.Time)
+	targetSegmentIndex, segmentStartTime := video.FindSegmentByTime(segments, targetTime)
 
 	if targetSegmentIndex < 0 {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf("time %.2f is out of range (video duration: %.2f)", request.Time, totalDuration),
+			"error": fmt.Sprintf("time %.2f is out of range (video duration: %.2f)", targetTime, totalDuration),
 		})
 		return
 	}
@@ -437,13 +371,7 @@ func (vac *VideoAccessController) GetSegmentByTime(c *gin.Context) {
 		segmentFiles = append(segmentFiles, quality+"/"+segments[i].Filename)
 	}
 
-	// generate signed URLs for segments
-	fullPaths := make([]string, len(segmentFiles))
-	for i, file := range segmentFiles {
-		fullPaths[i] = basePath + file
-	}
-
-	signedURLs, err := vac.storageProvider.GenerateSignedURLs(c.Request.Context(), fullPaths, &storage.CDNSignedURLOptions{
+	fileURLs, err := hlsFileURLs(c, vac.storageProvider, vac.streaming.EffectiveMode(), movieID, segmentFiles, &storage.CDNSignedURLOptions{
 		ExpiresIn:    time.Hour * 2,
 		CacheControl: "public, max-age=86400",
 	})
@@ -453,20 +381,10 @@ func (vac *VideoAccessController) GetSegmentByTime(c *gin.Context) {
 		return
 	}
 
-	// map back to original file names
-	fileURLs := make(map[string]string)
-	for i, file := range segmentFiles {
-		fullPath := fullPaths[i]
-		url, exists := signedURLs[fullPath]
-		if exists {
-			fileURLs[file] = url
-		}
-	}
-
 	// build response with seeking information
 	response := gin.H{
 		"movie_id":             movieID.String(),
-		"target_time":          request.Time,
+		"target_time":          targetTime,
 		"target_segment_index": targetSegmentIndex,
 		"segment_start_time":   segmentStartTime,
 		"total_duration":       totalDuration,
@@ -480,113 +398,53 @@ func (vac *VideoAccessController) GetSegmentByTime(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// SegmentInfo represents a video segment with timing information
-type SegmentInfo struct {
-	Index     int     `json:"index"`
-	Filename  string  `json:"filename"`
-	Duration  float64 `json:"duration"`
-	StartTime float64 `json:"start_time"`
-}
-
-// parsePlaylistForSeek parses an HLS playlist and returns segment information
-func (vac *VideoAccessController) parsePlaylistForSeek(ctx context.Context, playlistURL string) ([]SegmentInfo, float64, error) {
-	// create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	resp, err := client.Get(playlistURL)
+// GetVariantSegments handles GET /api/v1/videos/{movieId}/{quality}/segments
+// Returns the segment list of one rendition as JSON for players that do not parse m3u8
+func (vac *VideoAccessController) GetVariantSegments(c *gin.Context) {
+	movieIDStr := c.Param("movieId")
+	movieID, err := uuid.Parse(movieIDStr)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to fetch playlist: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, 0, fmt.Errorf("playlist request failed with status: %d", resp.StatusCode)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie ID"})
+		return
 	}
 
-	// read playlist content
-	body := make([]byte, 0, 1024*1024) // 1MB buffer
-	buffer := make([]byte, 4096)
-	for {
-		n, err := resp.Body.Read(buffer)
-		if n > 0 {
-			body = append(body, buffer[:n]...)
-		}
-		if err != nil {
-			if err.Error() == "EOF" {
-				break
-			}
-			return nil, 0, fmt.Errorf("failed to read playlist: %w", err)
-		}
+	quality := c.Param("quality")
+	if !model.IsAvailableQuality(quality) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("quality must be one of %v", model.AvailableQualities)})
+		return
 	}
 
-	// parse playlist
-	content := string(body)
-	lines := strings.Split(content, "\n")
-
-	var segments []SegmentInfo
-	var currentDuration float64
-	var totalDuration float64
-	segmentIndex := 0
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// parse segment duration
-		if strings.HasPrefix(line, "#EXTINF:") {
-			// extract duration from #EXTINF:duration,
-			parts := strings.Split(line, ":")
-			if len(parts) >= 2 {
-				durationPart := strings.Split(parts[1], ",")[0]
-				if duration, err := parseFloat64(durationPart); err == nil {
-					currentDuration = duration
-				}
-			}
-		} else if line != "" && !strings.HasPrefix(line, "#") {
-			// this is a segment filename
-			segment := SegmentInfo{
-				Index:     segmentIndex,
-				Filename:  line,
-				Duration:  currentDuration,
-				StartTime: totalDuration,
-			}
-			segments = append(segments, segment)
-			totalDuration += currentDuration
-			segmentIndex++
-		}
+	// authentication is already handled by middleware
+	movie, err := vac.movieService.GetMovie(c.Request.Context(), movieID)
+	if err != nil {
+		logger.Error(err, "failed to get movie for segment list")
+		respondMovieNotFound(c, vac.movieService, movieID)
+		return
 	}
 
-	return segments, totalDuration, nil
-}
-
-// findSegmentByTime finds the segment that contains the given time
-func (vac *VideoAccessController) findSegmentByTime(segments []SegmentInfo, targetTime float64) (int, float64) {
-	for i, segment := range segments {
-		if targetTime >= segment.StartTime && targetTime < segment.StartTime+segment.Duration {
-			return i, segment.StartTime
-		}
+	if movie.Status != "available" {
+		respondMovieNotReady(c, movie)
+		return
 	}
 
-	// if time is beyond the last segment, return the last segment
-	if len(segments) > 0 {
-		lastSegment := segments[len(segments)-1]
-		if targetTime >= lastSegment.StartTime {
-			return len(segments) - 1, lastSegment.StartTime
-		}
+	quality = resolveQuality(c, movie, quality)
+	segments, totalDuration, err := vac.movieService.GetSegments(c.Request.Context(), movieID, quality)
+	if err != nil {
+		logger.Error(err, "failed to parse playlist for segment list")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse video playlist"})
+		return
 	}
 
-	return -1, 0
-}
-
-// helper function to parse float64 safely
-func parseFloat64(s string) (float64, error) {
-	s = strings.TrimSpace(s)
-	// handle common cases
-	if s == "" {
-		return 0, fmt.Errorf("empty string")
+	if segments == nil {
+		segments = []video.SegmentInfo{}
 	}
 
-	// use Go's built-in parser
-	return strconv.ParseFloat(s, 64)
+	c.Header("Cache-Control", "private, max-age=60") // same as seek responses
+	c.JSON(http.StatusOK, gin.H{
+		"movie_id":       movieID.String(),
+		"quality":        quality,
+		"total_duration": totalDuration,
+		"segment_count":  len(segments),
+		"segments":       segments,
+	})
 }
@@ -1,9 +1,14 @@
 package controller
 
 import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
 	"net/http"
+	"strconv"
 	"watch-party/pkg/events"
 	"watch-party/pkg/logger"
+	"watch-party/pkg/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -11,16 +16,35 @@ import (
 
 // WebhookController handles webhook events
 type WebhookController struct {
-	uploadHandler events.Handler
+	uploadHandler     events.Handler
+	notificationToken string
 }
 
 // NewWebhookController creates a new webhook controller
-func NewWebhookController(uploadHandler events.Handler) *WebhookController {
+func NewWebhookController(uploadHandler events.Handler, notificationToken string) *WebhookController {
 	return &WebhookController{
-		uploadHandler: uploadHandler,
+		uploadHandler:     uploadHandler,
+		notificationToken: notificationToken,
 	}
 }
 
+// pubSubPushRequest is the body of a Pub/Sub push delivery
+type pubSubPushRequest struct {
+	Message struct {
+		Attributes map[string]string `json:"attributes"`
+		Data       string            `json:"data"`
+		MessageID  string            `json:"messageId"`
+	} `json:"message"`
+	Subscription string `json:"subscription"`
+}
+
+// gcsObjectMetadata is the subset of the GCS object resource sent as notification payload
+type gcsObjectMetadata struct {
+	Name        string `json:"name"`
+	Size        string `json:"size"`
+	ContentType string `json:"contentType"`
+}
+
 // HandleUploadComplete handles file upload completion webhook
 func (wc *WebhookController) HandleUploadComplete(c *gin.Context) {
 	var event events.UploadEvent
@@ -56,6 +80,48 @@ func (wc *WebhookController) HandleUploadComplete(c *gin.Context) {
 	})
 }
 
+// HandleStorageNotification handles GCS object notifications delivered by a Pub/Sub push subscription
+func (wc *WebhookController) HandleStorageNotification(c *gin.Context) {
+	if wc.notificationToken == "" ||
+		subtle.ConstantTimeCompare([]byte(c.Query("token")), []byte(wc.notificationToken)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid notification token"})
+		return
+	}
+
+	var push pubSubPushRequest
+	err := c.ShouldBindJSON(&push)
+	if err != nil {
+		logger.Error(err, "failed to bind storage notification")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification payload"})
+		return
+	}
+
+	// only finalized objects are complete uploads, acknowledge everything else
+	if push.Message.Attributes["eventType"] != "OBJECT_FINALIZE" {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	object := &storage.ObjectEvent{Path: push.Message.Attributes["objectId"]}
+
+	var metadata gcsObjectMetadata
+	data, err := base64.StdEncoding.DecodeString(push.Message.Data)
+	if err == nil && json.Unmarshal(data, &metadata) == nil {
+		object.Size, _ = strconv.ParseInt(metadata.Size, 10, 64)
+		object.ContentType = metadata.ContentType
+	}
+
+	err = wc.uploadHandler.HandleObjectCreated(c.Request.Context(), object)
+	if err != nil {
+		// a non-2xx response makes Pub/Sub redeliver the notification
+		logger.Errorf(err, "failed to handle storage notification for %s", object.Path)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process storage notification"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 // extractMovieIDFromPath extracts movie ID from file path
 // Assumes format: uploads/{movieID}_{timestamp}.ext
 func extractMovieIDFromPath(path string) string {
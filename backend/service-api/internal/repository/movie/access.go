@@ -0,0 +1,89 @@
+package movie
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+)
+
+// GrantMovieAccess grants a user, found by ID or email, direct access to a movie. granting again replaces
+// the expiry of the existing grant. returns nil when no such user exists.
+func (r *repository) GrantMovieAccess(movieID uuid.UUID, userID *uuid.UUID, email string, grantedBy uuid.UUID, expiresAt *time.Time) (*model.MovieAccessGrant, error) {
+	query := `
+		WITH target AS (
+			SELECT id, email, display_name FROM users
+			WHERE ($2::uuid IS NOT NULL AND id = $2) OR ($3 <> '' AND LOWER(email) = LOWER($3))
+			LIMIT 1
+		), granted AS (
+			INSERT INTO movie_access (movie_id, user_id, granted_by, granted_at, expires_at)
+			SELECT $1, id, $4, NOW(), $5 FROM target
+			ON CONFLICT (movie_id, user_id) DO UPDATE SET
+				granted_by = EXCLUDED.granted_by,
+				granted_at = EXCLUDED.granted_at,
+				expires_at = EXCLUDED.expires_at
+			RETURNING movie_id, user_id, granted_by, granted_at, expires_at
+		)
+		SELECT g.movie_id, g.user_id, t.email, t.display_name, g.granted_by, g.granted_at, g.expires_at
+		FROM granted g
+		JOIN target t ON t.id = g.user_id`
+
+	var grant model.MovieAccessGrant
+	err := r.db.QueryRow(query, movieID, userID, email, grantedBy, expiresAt).Scan(&grant.MovieID, &grant.UserID,
+		&grant.Email, &grant.DisplayName, &grant.GrantedBy, &grant.GrantedAt, &grant.ExpiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // user not found
+		}
+		return nil, fmt.Errorf("failed to grant movie access: %w", err)
+	}
+	return &grant, nil
+}
+
+// RevokeMovieAccess removes a user's direct access to a movie, reporting whether there was a grant
+func (r *repository) RevokeMovieAccess(movieID, userID uuid.UUID) (bool, error) {
+	query := `DELETE FROM movie_access WHERE movie_id = $1 AND user_id = $2`
+
+	result, err := r.db.Exec(query, movieID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke movie access: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke movie access: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// GetMovieAccessGrants returns the direct access grants of a movie that have not expired, newest first
+func (r *repository) GetMovieAccessGrants(movieID uuid.UUID) ([]model.MovieAccessGrant, error) {
+	query := `
+		SELECT ma.movie_id, ma.user_id, u.email, u.display_name, ma.granted_by, ma.granted_at, ma.expires_at
+		FROM movie_access ma
+		JOIN users u ON u.id = ma.user_id
+		WHERE ma.movie_id = $1
+		  AND (ma.expires_at IS NULL OR ma.expires_at > NOW())
+		ORDER BY ma.granted_at DESC`
+
+	rows, err := r.db.Query(query, movieID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query movie access: %w", err)
+	}
+	defer rows.Close()
+
+	grants := make([]model.MovieAccessGrant, 0)
+	for rows.Next() {
+		var grant model.MovieAccessGrant
+		err := rows.Scan(&grant.MovieID, &grant.UserID, &grant.Email, &grant.DisplayName,
+			&grant.GrantedBy, &grant.GrantedAt, &grant.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan movie access: %w", err)
+		}
+		grants = append(grants, grant)
+	}
+
+	return grants, rows.Err()
+}
@@ -0,0 +1,92 @@
+package movie
+
+import (
+	"fmt"
+	"time"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+)
+
+// UpsertDailyViews stores the counters of a movie for a day.
+// counts never go down, so a flush after Redis lost its keys cannot erase history.
+func (r *repository) UpsertDailyViews(movieID uuid.UUID, day string, views, uniqueViewers int64) error {
+	query := `
+		INSERT INTO movie_daily_views (movie_id, day, views, unique_viewers)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (movie_id, day) DO UPDATE SET
+			views = GREATEST(movie_daily_views.views, EXCLUDED.views),
+			unique_viewers = GREATEST(movie_daily_views.unique_viewers, EXCLUDED.unique_viewers)`
+
+	_, err := r.db.Exec(query, movieID, day, views, uniqueViewers)
+	if err != nil {
+		return fmt.Errorf("failed to upsert daily views: %w", err)
+	}
+	return nil
+}
+
+// UpsertUniqueViewers stores the all-time distinct viewer count of a movie
+func (r *repository) UpsertUniqueViewers(movieID uuid.UUID, uniqueViewers int64) error {
+	query := `
+		INSERT INTO movie_analytics (movie_id, unique_viewers, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (movie_id) DO UPDATE SET
+			unique_viewers = GREATEST(movie_analytics.unique_viewers, EXCLUDED.unique_viewers),
+			updated_at = NOW()`
+
+	_, err := r.db.Exec(query, movieID, uniqueViewers)
+	if err != nil {
+		return fmt.Errorf("failed to upsert unique viewers: %w", err)
+	}
+	return nil
+}
+
+// GetDailyViews returns the stored daily counters of a movie since the given day, most recent first
+func (r *repository) GetDailyViews(movieID uuid.UUID, since time.Time) ([]model.MovieDailyViews, error) {
+	query := `
+		SELECT day, views, unique_viewers
+		FROM movie_daily_views
+		WHERE movie_id = $1 AND day >= $2
+		ORDER BY day DESC`
+
+	rows, err := r.db.Query(query, movieID, since.Format(model.AnalyticsDayFormat))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily views: %w", err)
+	}
+	defer rows.Close()
+
+	daily := make([]model.MovieDailyViews, 0)
+	for rows.Next() {
+		var entry model.MovieDailyViews
+		var day time.Time
+		err := rows.Scan(&day, &entry.Views, &entry.UniqueViewers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan daily views: %w", err)
+		}
+		entry.Day = day.Format(model.AnalyticsDayFormat)
+		daily = append(daily, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return daily, nil
+}
+
+// GetViewTotals returns the stored total views and all-time distinct viewers of a movie
+func (r *repository) GetViewTotals(movieID uuid.UUID) (int64, int64, error) {
+	var totalViews, uniqueViewers int64
+
+	err := r.db.QueryRow(`SELECT COALESCE(SUM(views), 0) FROM movie_daily_views WHERE movie_id = $1`, movieID).Scan(&totalViews)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to sum views: %w", err)
+	}
+
+	err = r.db.QueryRow(`SELECT COALESCE(MAX(unique_viewers), 0) FROM movie_analytics WHERE movie_id = $1`, movieID).Scan(&uniqueViewers)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get unique viewers: %w", err)
+	}
+
+	return totalViews, uniqueViewers, nil
+}
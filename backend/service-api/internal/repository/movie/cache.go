@@ -0,0 +1,141 @@
+package movie
+
+import (
+	"sync"
+	"time"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+)
+
+// maxCachedMovies bounds the cache, expired entries are dropped once it grows this large
+const maxCachedMovies = 1024
+
+// cachedRepository keeps recently read movies in memory for the hot streaming routes. every write to a movie
+// through it drops the movie from the cache, writes made by other instances show up once the TTL passed
+type cachedRepository struct {
+	Repository
+	ttl time.Duration
+
+	mu         sync.Mutex
+	entries    map[uuid.UUID]cachedMovie
+	generation uint64 // bumped by every invalidation so a read racing a write does not cache the old row
+}
+
+type cachedMovie struct {
+	movie     *model.Movie
+	expiresAt time.Time
+}
+
+// NewCachedRepository wraps a repository so GetByIDCached serves movies read within ttl from memory
+func NewCachedRepository(repo Repository, ttl time.Duration) Repository {
+	return &cachedRepository{
+		Repository: repo,
+		ttl:        ttl,
+		entries:    make(map[uuid.UUID]cachedMovie),
+	}
+}
+
+// GetByIDCached returns a copy of the movie, read from the database at most ttl ago
+func (r *cachedRepository) GetByIDCached(id uuid.UUID) (*model.Movie, error) {
+	now := time.Now()
+
+	r.mu.Lock()
+	entry, ok := r.entries[id]
+	generation := r.generation
+	r.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return cloneMovie(entry.movie), nil
+	}
+
+	movie, err := r.Repository.GetByID(id)
+	if err != nil || movie == nil {
+		return movie, err
+	}
+
+	r.mu.Lock()
+	if r.generation == generation {
+		if len(r.entries) >= maxCachedMovies {
+			r.pruneLocked(now)
+		}
+		r.entries[id] = cachedMovie{movie: cloneMovie(movie), expiresAt: now.Add(r.ttl)}
+	}
+	r.mu.Unlock()
+
+	return movie, nil
+}
+
+// invalidate drops a movie from the cache after it was written
+func (r *cachedRepository) invalidate(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.entries, id)
+	r.generation++
+}
+
+func (r *cachedRepository) pruneLocked(now time.Time) {
+	for id, entry := range r.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(r.entries, id)
+		}
+	}
+	// every entry is fresh, start over rather than grow without bound
+	if len(r.entries) >= maxCachedMovies {
+		r.entries = make(map[uuid.UUID]cachedMovie)
+	}
+}
+
+// cloneMovie copies a movie so callers cannot change the cached one
+func cloneMovie(movie *model.Movie) *model.Movie {
+	clone := *movie
+	if movie.FailedQualities != nil {
+		clone.FailedQualities = append([]string(nil), movie.FailedQualities...)
+	}
+	return &clone
+}
+
+func (r *cachedRepository) Update(movie *model.Movie) error {
+	defer r.invalidate(movie.ID)
+	return r.Repository.Update(movie)
+}
+
+func (r *cachedRepository) Delete(id uuid.UUID) error {
+	defer r.invalidate(id)
+	return r.Repository.Delete(id)
+}
+
+func (r *cachedRepository) UpdateStatus(id uuid.UUID, status model.MovieStatus) error {
+	defer r.invalidate(id)
+	return r.Repository.UpdateStatus(id, status)
+}
+
+func (r *cachedRepository) ClaimForTranscoding(id uuid.UUID) (bool, error) {
+	defer r.invalidate(id)
+	return r.Repository.ClaimForTranscoding(id)
+}
+
+func (r *cachedRepository) MarkFailed(id uuid.UUID, reason string) error {
+	defer r.invalidate(id)
+	return r.Repository.MarkFailed(id, reason)
+}
+
+func (r *cachedRepository) UpdateProcessingTimes(id uuid.UUID, startedAt, endedAt *time.Time) error {
+	defer r.invalidate(id)
+	return r.Repository.UpdateProcessingTimes(id, startedAt, endedAt)
+}
+
+func (r *cachedRepository) SetFastPreview(id uuid.UUID, fastPreview bool) error {
+	defer r.invalidate(id)
+	return r.Repository.SetFastPreview(id, fastPreview)
+}
+
+func (r *cachedRepository) SetFailedQualities(id uuid.UUID, qualities []string) error {
+	defer r.invalidate(id)
+	return r.Repository.SetFailedQualities(id, qualities)
+}
+
+func (r *cachedRepository) UpdateHLSInfo(id uuid.UUID, hlsPlaylistURL, transcodedPath string) error {
+	defer r.invalidate(id)
+	return r.Repository.UpdateHLSInfo(id, hlsPlaylistURL, transcodedPath)
+}
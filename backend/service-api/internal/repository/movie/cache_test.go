@@ -0,0 +1,66 @@
+package movie
+
+import (
+	"testing"
+	"time"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepository counts reads and writes statuses, the other methods are not used
+type fakeRepository struct {
+	Repository
+	movies map[uuid.UUID]*model.Movie
+	reads  int
+}
+
+func (f *fakeRepository) GetByID(id uuid.UUID) (*model.Movie, error) {
+	f.reads++
+	movie, ok := f.movies[id]
+	if !ok {
+		return nil, nil
+	}
+	clone := *movie
+	return &clone, nil
+}
+
+func (f *fakeRepository) UpdateStatus(id uuid.UUID, status model.MovieStatus) error {
+	f.movies[id].Status = status
+	return nil
+}
+
+func (f *fakeRepository) Delete(id uuid.UUID) error {
+	delete(f.movies, id)
+	return nil
+}
+
+func TestCachedRepository(t *testing.T) {
+	movieID := uuid.New()
+	fake := &fakeRepository{movies: map[uuid.UUID]*model.Movie{movieID: {ID: movieID, Status: model.StatusAvailable}}}
+	repo := NewCachedRepository(fake, time.Minute)
+
+	movie, err := repo.GetByIDCached(movieID)
+	require.NoError(t, err)
+	movie.Status = model.StatusFailed // callers get a copy
+
+	movie, err = repo.GetByIDCached(movieID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusAvailable, movie.Status)
+	assert.Equal(t, 1, fake.reads, "the second read is served from memory")
+
+	require.NoError(t, repo.UpdateStatus(movieID, model.StatusTranscoding))
+	movie, err = repo.GetByIDCached(movieID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusTranscoding, movie.Status, "a status change drops the cached movie")
+
+	require.NoError(t, repo.Delete(movieID))
+	movie, err = repo.GetByIDCached(movieID)
+	require.NoError(t, err)
+	assert.Nil(t, movie, "a deleted movie is not served from memory")
+
+	_, _ = repo.GetByIDCached(movieID)
+	assert.Equal(t, 4, fake.reads, "missing movies are not cached")
+}
@@ -0,0 +1,52 @@
+package movie
+
+import (
+	"fmt"
+	"time"
+	"watch-party/pkg/model"
+)
+
+// GetPipelineStats counts movies per status, the ones in transcoding since before stuckBefore, and averages the
+// transcodes that finished since finishedSince
+func (r *repository) GetPipelineStats(stuckBefore, finishedSince time.Time) (*model.PipelineStats, error) {
+	stats := &model.PipelineStats{StatusCounts: make(map[model.MovieStatus]int)}
+
+	rows, err := r.db.Query(`SELECT status, COUNT(*) FROM movies GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count movies by status: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status model.MovieStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan status count: %w", err)
+		}
+		stats.StatusCounts[status] = count
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	// a movie whose start time was never recorded has been stuck since before it
+	query := `
+		SELECT COUNT(*) FROM movies
+		WHERE status = $1 AND (processing_started_at IS NULL OR processing_started_at < $2)`
+	err = r.db.QueryRow(query, model.StatusTranscoding, stuckBefore).Scan(&stats.StuckTranscoding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count stuck transcodes: %w", err)
+	}
+
+	query = `
+		SELECT COALESCE(AVG(EXTRACT(EPOCH FROM (processing_ended_at - processing_started_at))), 0), COUNT(*)
+		FROM movies
+		WHERE status = $1 AND processing_started_at IS NOT NULL AND processing_ended_at >= $2
+			AND processing_ended_at >= processing_started_at`
+	err = r.db.QueryRow(query, model.StatusAvailable, finishedSince).Scan(&stats.AverageTranscodeSeconds, &stats.TranscodesSampled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to average transcode time: %w", err)
+	}
+
+	return stats, nil
+}
@@ -5,32 +5,64 @@ import (
 	"fmt"
 	"time"
 	"watch-party/pkg/model"
+	"watch-party/pkg/video"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // Repository defines the movie repository interface
 type Repository interface {
 	Create(movie *model.Movie) error
 	GetByID(id uuid.UUID) (*model.Movie, error)
+	GetByIDCached(id uuid.UUID) (*model.Movie, error) // may be a few seconds old, only for read-only checks on hot paths
+	GetByOriginalFilePath(path string) (*model.Movie, error)
 	GetAll(limit, offset int) ([]model.Movie, int, error)
 	Update(movie *model.Movie) error
 	Delete(id uuid.UUID) error
 	GetByUploader(uploaderID uuid.UUID, limit, offset int) ([]model.Movie, int, error)
 	UpdateStatus(id uuid.UUID, status model.MovieStatus) error
+	ClaimForTranscoding(id uuid.UUID) (bool, error)
+	MarkFailed(id uuid.UUID, reason string) error
 	UpdateProcessingTimes(id uuid.UUID, startedAt, endedAt *time.Time) error
+	SetFastPreview(id uuid.UUID, fastPreview bool) error
+	SetFailedQualities(id uuid.UUID, qualities []string) error
+	ClaimForRetranscode(id uuid.UUID, staleBefore time.Time) (bool, error)
+	ReleaseRetranscode(id uuid.UUID) error
+	SetEncryptionKey(id uuid.UUID, key []byte) error
+	GetEncryptionKey(id uuid.UUID) ([]byte, error)
 	UpdateHLSInfo(id uuid.UUID, hlsPlaylistURL, transcodedPath string) error
+	GetAbandonedUploads(createdBefore time.Time, limit int) ([]model.Movie, error)
+	CountRoomsUsingMovie(movieID uuid.UUID) (int, error)
+	GetRoomIDsUsingMovie(movieID uuid.UUID) ([]uuid.UUID, error)
+	GetRoomsUsingMovie(movieID uuid.UUID) ([]model.MovieRoom, error)
+	GetPipelineStats(stuckBefore, finishedSince time.Time) (*model.PipelineStats, error)
+
+	// analytics
+	UpsertDailyViews(movieID uuid.UUID, day string, views, uniqueViewers int64) error
+	UpsertUniqueViewers(movieID uuid.UUID, uniqueViewers int64) error
+	GetDailyViews(movieID uuid.UUID, since time.Time) ([]model.MovieDailyViews, error)
+	GetViewTotals(movieID uuid.UUID) (int64, int64, error)
+
+	// subtitles
+	UpsertSubtitle(subtitle *model.MovieSubtitle) error
+	GetSubtitles(movieID uuid.UUID) ([]model.MovieSubtitle, error)
+	GrantMovieAccess(movieID uuid.UUID, userID *uuid.UUID, email string, grantedBy uuid.UUID, expiresAt *time.Time) (*model.MovieAccessGrant, error)
+	RevokeMovieAccess(movieID, userID uuid.UUID) (bool, error)
+	GetMovieAccessGrants(movieID uuid.UUID) ([]model.MovieAccessGrant, error)
 }
 
 // repository implements the movie repository
 type repository struct {
-	db *sql.DB
+	db        *sql.DB
+	keySecret string // seals segment keys, they are never stored in the clear
 }
 
 // NewRepository creates a new movie repository
-func NewRepository(db *sql.DB) Repository {
+func NewRepository(db *sql.DB, keySecret string) Repository {
 	return &repository{
-		db: db,
+		db:        db,
+		keySecret: keySecret,
 	}
 }
 
@@ -39,24 +71,29 @@ func (r *repository) Create(movie *model.Movie) error {
 	query := `
 		INSERT INTO movies (id, title, description, original_file_path, transcoded_file_path, 
 			hls_playlist_url, duration_seconds, file_size, mime_type, status, uploaded_by, 
-			created_at, processing_started_at, processing_ended_at) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+			created_at, processing_started_at, processing_ended_at, fast_preview) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
 
 	_, err := r.db.Exec(query,
 		movie.ID, movie.Title, movie.Description, movie.OriginalFilePath,
 		movie.TranscodedFilePath, movie.HLSPlaylistURL, movie.DurationSeconds,
 		movie.FileSize, movie.MimeType, movie.Status, movie.UploadedBy,
-		movie.CreatedAt, movie.ProcessingStartedAt, movie.ProcessingEndedAt)
+		movie.CreatedAt, movie.ProcessingStartedAt, movie.ProcessingEndedAt, movie.FastPreview)
 	return err
 }
 
+// GetByIDCached reads the movie from the database, NewCachedRepository adds the cache
+func (r *repository) GetByIDCached(id uuid.UUID) (*model.Movie, error) {
+	return r.GetByID(id)
+}
+
 // GetByID retrieves a movie by ID
 func (r *repository) GetByID(id uuid.UUID) (*model.Movie, error) {
 	movie := &model.Movie{}
 	query := `
 		SELECT id, title, description, original_file_path, transcoded_file_path, 
 			hls_playlist_url, duration_seconds, file_size, mime_type, status, 
-			uploaded_by, created_at, processing_started_at, processing_ended_at
+			uploaded_by, created_at, processing_started_at, processing_ended_at, fast_preview, failure_reason, failed_qualities
 		FROM movies 
 		WHERE id = $1`
 
@@ -64,7 +101,32 @@ func (r *repository) GetByID(id uuid.UUID) (*model.Movie, error) {
 	err := row.Scan(&movie.ID, &movie.Title, &movie.Description,
 		&movie.OriginalFilePath, &movie.TranscodedFilePath, &movie.HLSPlaylistURL,
 		&movie.DurationSeconds, &movie.FileSize, &movie.MimeType, &movie.Status,
-		&movie.UploadedBy, &movie.CreatedAt, &movie.ProcessingStartedAt, &movie.ProcessingEndedAt)
+		&movie.UploadedBy, &movie.CreatedAt, &movie.ProcessingStartedAt, &movie.ProcessingEndedAt, &movie.FastPreview, &movie.FailureReason, pq.Array(&movie.FailedQualities))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Movie not found
+		}
+		return nil, err
+	}
+
+	return movie, nil
+}
+
+// GetByOriginalFilePath retrieves the movie an uploaded object belongs to
+func (r *repository) GetByOriginalFilePath(path string) (*model.Movie, error) {
+	movie := &model.Movie{}
+	query := `
+		SELECT id, title, description, original_file_path, transcoded_file_path, 
+			hls_playlist_url, duration_seconds, file_size, mime_type, status, 
+			uploaded_by, created_at, processing_started_at, processing_ended_at, fast_preview, failure_reason, failed_qualities
+		FROM movies 
+		WHERE original_file_path = $1`
+
+	row := r.db.QueryRow(query, path)
+	err := row.Scan(&movie.ID, &movie.Title, &movie.Description,
+		&movie.OriginalFilePath, &movie.TranscodedFilePath, &movie.HLSPlaylistURL,
+		&movie.DurationSeconds, &movie.FileSize, &movie.MimeType, &movie.Status,
+		&movie.UploadedBy, &movie.CreatedAt, &movie.ProcessingStartedAt, &movie.ProcessingEndedAt, &movie.FastPreview, &movie.FailureReason, pq.Array(&movie.FailedQualities))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Movie not found
@@ -89,7 +151,7 @@ func (r *repository) GetAll(limit, offset int) ([]model.Movie, int, error) {
 	query := `
 		SELECT id, title, description, original_file_path, transcoded_file_path, 
 			hls_playlist_url, duration_seconds, file_size, mime_type, status, 
-			uploaded_by, created_at, processing_started_at, processing_ended_at
+			uploaded_by, created_at, processing_started_at, processing_ended_at, fast_preview, failure_reason, failed_qualities
 		FROM movies 
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2`
@@ -106,7 +168,7 @@ func (r *repository) GetAll(limit, offset int) ([]model.Movie, int, error) {
 		err := rows.Scan(&movie.ID, &movie.Title, &movie.Description,
 			&movie.OriginalFilePath, &movie.TranscodedFilePath, &movie.HLSPlaylistURL,
 			&movie.DurationSeconds, &movie.FileSize, &movie.MimeType, &movie.Status,
-			&movie.UploadedBy, &movie.CreatedAt, &movie.ProcessingStartedAt, &movie.ProcessingEndedAt)
+			&movie.UploadedBy, &movie.CreatedAt, &movie.ProcessingStartedAt, &movie.ProcessingEndedAt, &movie.FastPreview, &movie.FailureReason, pq.Array(&movie.FailedQualities))
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan movie: %w", err)
 		}
@@ -183,7 +245,7 @@ func (r *repository) GetByUploader(uploaderID uuid.UUID, limit, offset int) ([]m
 	query := `
 		SELECT id, title, description, original_file_path, transcoded_file_path, 
 			hls_playlist_url, duration_seconds, file_size, mime_type, status, 
-			uploaded_by, created_at, processing_started_at, processing_ended_at
+			uploaded_by, created_at, processing_started_at, processing_ended_at, fast_preview, failure_reason, failed_qualities
 		FROM movies 
 		WHERE uploaded_by = $1
 		ORDER BY created_at DESC
@@ -201,7 +263,7 @@ func (r *repository) GetByUploader(uploaderID uuid.UUID, limit, offset int) ([]m
 		err := rows.Scan(&movie.ID, &movie.Title, &movie.Description,
 			&movie.OriginalFilePath, &movie.TranscodedFilePath, &movie.HLSPlaylistURL,
 			&movie.DurationSeconds, &movie.FileSize, &movie.MimeType, &movie.Status,
-			&movie.UploadedBy, &movie.CreatedAt, &movie.ProcessingStartedAt, &movie.ProcessingEndedAt)
+			&movie.UploadedBy, &movie.CreatedAt, &movie.ProcessingStartedAt, &movie.ProcessingEndedAt, &movie.FastPreview, &movie.FailureReason, pq.Array(&movie.FailedQualities))
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan movie: %w", err)
 		}
@@ -215,9 +277,120 @@ func (r *repository) GetByUploader(uploaderID uuid.UUID, limit, offset int) ([]m
 	return movies, totalCount, nil
 }
 
-// UpdateStatus updates the status of a movie
+// CountRoomsUsingMovie returns how many rooms play a movie, deleting the movie deletes them too
+func (r *repository) CountRoomsUsingMovie(movieID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM rooms WHERE movie_id = $1`
+
+	err := r.db.QueryRow(query, movieID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rooms using movie: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetRoomIDsUsingMovie returns the IDs of the rooms that play a movie
+func (r *repository) GetRoomIDsUsingMovie(movieID uuid.UUID) ([]uuid.UUID, error) {
+	query := `SELECT id FROM rooms WHERE movie_id = $1`
+
+	rows, err := r.db.Query(query, movieID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rooms using movie: %w", err)
+	}
+	defer rows.Close()
+
+	var roomIDs []uuid.UUID
+	for rows.Next() {
+		var roomID uuid.UUID
+		err := rows.Scan(&roomID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan room ID: %w", err)
+		}
+		roomIDs = append(roomIDs, roomID)
+	}
+
+	return roomIDs, rows.Err()
+}
+
+// GetRoomsUsingMovie returns the rooms that play a movie with their host and member count, newest first
+func (r *repository) GetRoomsUsingMovie(movieID uuid.UUID) ([]model.MovieRoom, error) {
+	query := `
+		SELECT r.id, r.name, r.created_at,
+			u.id, u.email, u.role, u.display_name, u.avatar_url, u.created_at,
+			(SELECT COUNT(*) FROM room_access ra WHERE ra.room_id = r.id)
+		FROM rooms r
+		JOIN users u ON r.host_id = u.id
+		WHERE r.movie_id = $1
+		ORDER BY r.created_at DESC`
+
+	rows, err := r.db.Query(query, movieID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rooms using movie: %w", err)
+	}
+	defer rows.Close()
+
+	rooms := make([]model.MovieRoom, 0)
+	for rows.Next() {
+		var room model.MovieRoom
+		var host model.User
+		err := rows.Scan(&room.ID, &room.Name, &room.CreatedAt,
+			&host.ID, &host.Email, &host.Role, &host.DisplayName, &host.AvatarURL, &host.CreatedAt,
+			&room.MemberCount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan room: %w", err)
+		}
+		room.Host = host.ToProfile()
+		rooms = append(rooms, room)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return rooms, nil
+}
+
+// GetAbandonedUploads returns movies still waiting for their upload that were created before the given time
+func (r *repository) GetAbandonedUploads(createdBefore time.Time, limit int) ([]model.Movie, error) {
+	query := `
+		SELECT id, title, description, original_file_path, transcoded_file_path, 
+			hls_playlist_url, duration_seconds, file_size, mime_type, status, 
+			uploaded_by, created_at, processing_started_at, processing_ended_at, fast_preview, failure_reason, failed_qualities
+		FROM movies 
+		WHERE status = $1 AND processing_started_at IS NULL AND created_at < $2
+		ORDER BY created_at ASC
+		LIMIT $3`
+
+	rows, err := r.db.Query(query, model.StatusProcessing, createdBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query abandoned uploads: %w", err)
+	}
+	defer rows.Close()
+
+	var movies []model.Movie = make([]model.Movie, 0)
+	for rows.Next() {
+		var movie model.Movie
+		err := rows.Scan(&movie.ID, &movie.Title, &movie.Description,
+			&movie.OriginalFilePath, &movie.TranscodedFilePath, &movie.HLSPlaylistURL,
+			&movie.DurationSeconds, &movie.FileSize, &movie.MimeType, &movie.Status,
+			&movie.UploadedBy, &movie.CreatedAt, &movie.ProcessingStartedAt, &movie.ProcessingEndedAt, &movie.FastPreview, &movie.FailureReason, pq.Array(&movie.FailedQualities))
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan movie: %w", err)
+		}
+		movies = append(movies, movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return movies, nil
+}
+
+// UpdateStatus updates the status of a movie, clearing the reason of an earlier failure
 func (r *repository) UpdateStatus(id uuid.UUID, status model.MovieStatus) error {
-	query := `UPDATE movies SET status = $2 WHERE id = $1`
+	query := `UPDATE movies SET status = $2, failure_reason = '' WHERE id = $1`
 
 	result, err := r.db.Exec(query, id, status)
 	if err != nil {
@@ -236,6 +409,127 @@ func (r *repository) UpdateStatus(id uuid.UUID, status model.MovieStatus) error
 	return nil
 }
 
+// ClaimForTranscoding moves a movie that is waiting for its upload, or failed, to transcoding. false means another
+// caller claimed it first or it is not in a claimable state
+func (r *repository) ClaimForTranscoding(id uuid.UUID) (bool, error) {
+	query := `UPDATE movies SET status = $2, failure_reason = '' WHERE id = $1 AND status IN ($3, $4)`
+
+	result, err := r.db.Exec(query, id, model.StatusTranscoding, model.StatusProcessing, model.StatusFailed)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim movie for transcoding: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// ClaimForRetranscode marks the failed qualities of a movie as being retranscoded. false means another instance
+// claimed them since staleBefore, older claims were left by an instance that stopped mid-retranscode
+func (r *repository) ClaimForRetranscode(id uuid.UUID, staleBefore time.Time) (bool, error) {
+	query := `
+		UPDATE movies SET retranscoding_since = NOW()
+		WHERE id = $1 AND (retranscoding_since IS NULL OR retranscoding_since < $2)`
+
+	result, err := r.db.Exec(query, id, staleBefore)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim movie for retranscoding: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// ReleaseRetranscode clears the retranscode claim of a movie
+func (r *repository) ReleaseRetranscode(id uuid.UUID) error {
+	query := `UPDATE movies SET retranscoding_since = NULL WHERE id = $1`
+	_, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to release retranscode claim: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed marks a movie as failed with a reason shown to its uploader
+func (r *repository) MarkFailed(id uuid.UUID, reason string) error {
+	query := `UPDATE movies SET status = $2, failure_reason = $3 WHERE id = $1`
+	_, err := r.db.Exec(query, id, model.StatusFailed, reason)
+	return err
+}
+
+// SetFastPreview records whether only the preview quality of a movie is published
+func (r *repository) SetFastPreview(id uuid.UUID, fastPreview bool) error {
+	query := `UPDATE movies SET fast_preview = $2 WHERE id = $1`
+
+	_, err := r.db.Exec(query, id, fastPreview)
+	if err != nil {
+		return fmt.Errorf("failed to update fast preview: %w", err)
+	}
+	return nil
+}
+
+// SetFailedQualities records the renditions of a movie that failed to transcode, none once they all exist
+func (r *repository) SetFailedQualities(id uuid.UUID, qualities []string) error {
+	if qualities == nil {
+		qualities = []string{}
+	}
+
+	query := `UPDATE movies SET failed_qualities = $2 WHERE id = $1`
+	_, err := r.db.Exec(query, id, pq.Array(qualities))
+	if err != nil {
+		return fmt.Errorf("failed to update failed qualities: %w", err)
+	}
+	return nil
+}
+
+// SetEncryptionKey stores the key a movie's HLS segments are encrypted with sealed under the key secret, an empty
+// key marks them unencrypted
+func (r *repository) SetEncryptionKey(id uuid.UUID, key []byte) error {
+	query := `UPDATE movies SET hls_encryption_key = $2 WHERE id = $1`
+
+	var value interface{}
+	if len(key) > 0 {
+		sealed, err := video.SealSegmentKey(r.keySecret, key)
+		if err != nil {
+			return fmt.Errorf("failed to seal encryption key: %w", err)
+		}
+		value = sealed
+	}
+
+	_, err := r.db.Exec(query, id, value)
+	if err != nil {
+		return fmt.Errorf("failed to update encryption key: %w", err)
+	}
+	return nil
+}
+
+// GetEncryptionKey returns the key a movie's HLS segments are encrypted with, nil when they are not encrypted
+func (r *repository) GetEncryptionKey(id uuid.UUID) ([]byte, error) {
+	query := `SELECT hls_encryption_key FROM movies WHERE id = $1`
+
+	var sealed []byte
+	err := r.db.QueryRow(query, id).Scan(&sealed)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) == 0 {
+		return nil, nil
+	}
+
+	key, err := video.OpenSegmentKey(r.keySecret, sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open encryption key: %w", err)
+	}
+	return key, nil
+}
+
 // UpdateProcessingTimes updates the processing start and end times
 func (r *repository) UpdateProcessingTimes(id uuid.UUID, startedAt, endedAt *time.Time) error {
 	query := `UPDATE movies SET processing_started_at = $2, processing_ended_at = $3 WHERE id = $1`
@@ -0,0 +1,56 @@
+package movie
+
+import (
+	"fmt"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+)
+
+// UpsertSubtitle stores a subtitle track, replacing the movie's track for the same language
+func (r *repository) UpsertSubtitle(subtitle *model.MovieSubtitle) error {
+	query := `
+		INSERT INTO movie_subtitles (id, movie_id, language, label, file_path, uploaded_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (movie_id, language) DO UPDATE SET
+			label = EXCLUDED.label,
+			file_path = EXCLUDED.file_path,
+			uploaded_by = EXCLUDED.uploaded_by,
+			created_at = EXCLUDED.created_at
+		RETURNING id`
+
+	err := r.db.QueryRow(query, subtitle.ID, subtitle.MovieID, subtitle.Language, subtitle.Label,
+		subtitle.FilePath, subtitle.UploadedBy, subtitle.CreatedAt).Scan(&subtitle.ID)
+	if err != nil {
+		return fmt.Errorf("failed to upsert subtitle: %w", err)
+	}
+	return nil
+}
+
+// GetSubtitles returns the subtitle tracks of a movie ordered by language
+func (r *repository) GetSubtitles(movieID uuid.UUID) ([]model.MovieSubtitle, error) {
+	query := `
+		SELECT id, movie_id, language, label, file_path, uploaded_by, created_at
+		FROM movie_subtitles
+		WHERE movie_id = $1
+		ORDER BY language`
+
+	rows, err := r.db.Query(query, movieID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subtitles: %w", err)
+	}
+	defer rows.Close()
+
+	subtitles := make([]model.MovieSubtitle, 0)
+	for rows.Next() {
+		var subtitle model.MovieSubtitle
+		err := rows.Scan(&subtitle.ID, &subtitle.MovieID, &subtitle.Language, &subtitle.Label,
+			&subtitle.FilePath, &subtitle.UploadedBy, &subtitle.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subtitle: %w", err)
+		}
+		subtitles = append(subtitles, subtitle)
+	}
+
+	return subtitles, rows.Err()
+}
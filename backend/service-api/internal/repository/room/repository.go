@@ -8,6 +8,7 @@ import (
 	"watch-party/pkg/model"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // Repository handles room data operations
@@ -23,20 +24,66 @@ func NewRepository(db *sql.DB) *Repository {
 // CreateRoom creates a new room
 func (r *Repository) CreateRoom(ctx context.Context, room *model.Room) error {
 	query := `
-		INSERT INTO rooms (id, movie_id, host_id, name, description, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)`
+		INSERT INTO rooms (id, movie_id, host_id, name, description, settings, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
 
-	_, err := r.db.ExecContext(ctx, query, room.ID, room.MovieID, room.HostID, room.Name, room.Description, room.CreatedAt)
+	_, err := r.db.ExecContext(ctx, query, room.ID, room.MovieID, room.HostID, room.Name, room.Description, room.Settings, room.CreatedAt)
 	return err
 }
 
+// CreateRoomUnlessHosted creates a room unless its host already has a room for the same movie, the oldest such room
+// is returned instead with false. the lookup and the insert run in one transaction holding an advisory lock on the
+// host and movie, so two requests racing each other cannot both create one
+func (r *Repository) CreateRoomUnlessHosted(ctx context.Context, room *model.Room) (*model.Room, bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1::text || ':' || $2::text))`, room.HostID, room.MovieID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var existing model.Room
+	query := `
+		SELECT id, movie_id, host_id, name, description, settings, created_at
+		FROM rooms
+		WHERE host_id = $1 AND movie_id = $2
+		ORDER BY created_at
+		LIMIT 1`
+	err = tx.QueryRowContext(ctx, query, room.HostID, room.MovieID).Scan(
+		&existing.ID, &existing.MovieID, &existing.HostID, &existing.Name, &existing.Description, &existing.Settings, &existing.CreatedAt)
+	if err == nil {
+		return &existing, false, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO rooms (id, movie_id, host_id, name, description, settings, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		room.ID, room.MovieID, room.HostID, room.Name, room.Description, room.Settings, room.CreatedAt)
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, false, err
+	}
+	return room, true, nil
+}
+
 // GetRoomByID retrieves a room by ID
 func (r *Repository) GetRoomByID(ctx context.Context, roomID uuid.UUID) (*model.Room, error) {
 	var room model.Room
-	query := `SELECT id, movie_id, host_id, name, description, created_at FROM rooms WHERE id = $1`
+	query := `SELECT id, movie_id, host_id, name, description, settings, created_at FROM rooms WHERE id = $1`
 
 	row := r.db.QueryRowContext(ctx, query, roomID)
-	err := row.Scan(&room.ID, &room.MovieID, &room.HostID, &room.Name, &room.Description, &room.CreatedAt)
+	err := row.Scan(&room.ID, &room.MovieID, &room.HostID, &room.Name, &room.Description, &room.Settings, &room.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -49,11 +96,11 @@ func (r *Repository) GetRoomWithDetails(ctx context.Context, roomID uuid.UUID) (
 	var roomDetails model.RoomWithDetails
 	query := `
 		SELECT 
-			r.id, r.movie_id, r.host_id, r.name, r.description, r.created_at,
+			r.id, r.movie_id, r.host_id, r.name, r.description, r.settings, r.created_at,
 			m.id, m.title, m.description, m.original_file_path, m.transcoded_file_path,
 			m.hls_playlist_url, m.duration_seconds, m.file_size, m.mime_type, m.status,
-			m.uploaded_by, m.created_at, m.processing_started_at, m.processing_ended_at,
-			u.id, u.email, u.role, u.created_at
+			m.uploaded_by, m.created_at, m.processing_started_at, m.processing_ended_at, m.fast_preview, m.failed_qualities,
+			u.id, u.email, u.role, u.display_name, u.avatar_url, u.created_at
 		FROM rooms r
 		JOIN movies m ON r.movie_id = m.id
 		JOIN users u ON r.host_id = u.id
@@ -61,13 +108,13 @@ func (r *Repository) GetRoomWithDetails(ctx context.Context, roomID uuid.UUID) (
 
 	row := r.db.QueryRowContext(ctx, query, roomID)
 	err := row.Scan(
-		&roomDetails.ID, &roomDetails.MovieID, &roomDetails.HostID, &roomDetails.Name, &roomDetails.Description, &roomDetails.CreatedAt,
+		&roomDetails.ID, &roomDetails.MovieID, &roomDetails.HostID, &roomDetails.Name, &roomDetails.Description, &roomDetails.Settings, &roomDetails.CreatedAt,
 		&roomDetails.Movie.ID, &roomDetails.Movie.Title, &roomDetails.Movie.Description,
 		&roomDetails.Movie.OriginalFilePath, &roomDetails.Movie.TranscodedFilePath,
 		&roomDetails.Movie.HLSPlaylistURL, &roomDetails.Movie.DurationSeconds, &roomDetails.Movie.FileSize,
 		&roomDetails.Movie.MimeType, &roomDetails.Movie.Status, &roomDetails.Movie.UploadedBy, &roomDetails.Movie.CreatedAt,
-		&roomDetails.Movie.ProcessingStartedAt, &roomDetails.Movie.ProcessingEndedAt,
-		&roomDetails.Host.ID, &roomDetails.Host.Email, &roomDetails.Host.Role, &roomDetails.Host.CreatedAt,
+		&roomDetails.Movie.ProcessingStartedAt, &roomDetails.Movie.ProcessingEndedAt, &roomDetails.Movie.FastPreview, pq.Array(&roomDetails.Movie.FailedQualities),
+		&roomDetails.Host.ID, &roomDetails.Host.Email, &roomDetails.Host.Role, &roomDetails.Host.DisplayName, &roomDetails.Host.AvatarURL, &roomDetails.Host.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -173,13 +220,117 @@ func (r *Repository) IsRoomHost(ctx context.Context, userID, roomID uuid.UUID) (
 	return count > 0, nil
 }
 
+// UpdateRoomHost reassigns the host of a room
+func (r *Repository) UpdateRoomHost(ctx context.Context, roomID, hostID uuid.UUID) error {
+	query := `UPDATE rooms SET host_id = $2 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, roomID, hostID)
+	return err
+}
+
+// UpdateLastPosition records the playback position a room was at
+func (r *Repository) UpdateLastPosition(ctx context.Context, roomID uuid.UUID, position float64, at time.Time) error {
+	query := `UPDATE rooms SET last_position = $2, last_position_at = $3 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, roomID, position, at)
+	return err
+}
+
+// GetLastPosition returns the last playback position recorded for a room, 0 when none was
+func (r *Repository) GetLastPosition(ctx context.Context, roomID uuid.UUID) (float64, error) {
+	var position float64
+	query := `SELECT last_position FROM rooms WHERE id = $1`
+	err := r.db.QueryRowContext(ctx, query, roomID).Scan(&position)
+	return position, err
+}
+
+// UpdateRoomSettings replaces the settings of a room
+func (r *Repository) UpdateRoomSettings(ctx context.Context, roomID uuid.UUID, settings model.RoomSettings) error {
+	query := `UPDATE rooms SET settings = $2 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, roomID, settings)
+	return err
+}
+
+// ReplaceRoomHost reassigns the host only if it is still previousHostID, reporting whether a row changed
+func (r *Repository) ReplaceRoomHost(ctx context.Context, roomID, previousHostID, newHostID uuid.UUID) (bool, error) {
+	query := `UPDATE rooms SET host_id = $3 WHERE id = $1 AND host_id = $2`
+	result, err := r.db.ExecContext(ctx, query, roomID, previousHostID, newHostID)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// CreateRoomAuditEntry records a change to a room in the audit log
+func (r *Repository) CreateRoomAuditEntry(ctx context.Context, entry *model.RoomAuditEntry) error {
+	query := `
+		INSERT INTO room_audit_log (id, room_id, actor_id, action, details, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := r.db.ExecContext(ctx, query, entry.ID, entry.RoomID, entry.ActorID, entry.Action, []byte(entry.Details), entry.CreatedAt)
+	return err
+}
+
+// CreateReport stores an abuse report
+func (r *Repository) CreateReport(ctx context.Context, report *model.Report) error {
+	query := `
+		INSERT INTO reports (id, room_id, reporter_id, target_user_id, target_username, reason, chat_snapshot, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	_, err := r.db.ExecContext(ctx, query, report.ID, report.RoomID, report.ReporterID, report.TargetUserID,
+		report.TargetUsername, report.Reason, report.ChatSnapshot, report.Status, report.CreatedAt)
+	return err
+}
+
+// GetReports retrieves abuse reports newest first, optionally filtered by status
+func (r *Repository) GetReports(ctx context.Context, status string, limit, offset int) ([]model.Report, int, error) {
+	var totalCount int
+	countQuery := `SELECT COUNT(*) FROM reports WHERE ($1 = '' OR status = $1)`
+	err := r.db.QueryRowContext(ctx, countQuery, status).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT rp.id, rp.room_id, rp.reporter_id, rp.target_user_id, COALESCE(rp.target_username, ''),
+		       rp.reason, rp.chat_snapshot, rp.status, rp.created_at, u.email, r.name
+		FROM reports rp
+		JOIN users u ON rp.reporter_id = u.id
+		JOIN rooms r ON rp.room_id = r.id
+		WHERE ($1 = '' OR rp.status = $1)
+		ORDER BY rp.created_at DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	reports := []model.Report{}
+	for rows.Next() {
+		var report model.Report
+		err := rows.Scan(&report.ID, &report.RoomID, &report.ReporterID, &report.TargetUserID, &report.TargetUsername,
+			&report.Reason, &report.ChatSnapshot, &report.Status, &report.CreatedAt, &report.ReporterEmail, &report.RoomName)
+		if err != nil {
+			return nil, 0, err
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, totalCount, rows.Err()
+}
+
 // GetUserRoomAccess retrieves the access record for a user in a room
 func (r *Repository) GetUserRoomAccess(ctx context.Context, userID, roomID uuid.UUID) (*model.RoomAccess, error) {
 	var access model.RoomAccess
-	query := `SELECT user_id, room_id, access_type, status, granted_at FROM room_access WHERE user_id = $1 AND room_id = $2`
+	query := `SELECT user_id, room_id, access_type, status, role, granted_at FROM room_access WHERE user_id = $1 AND room_id = $2`
 
 	row := r.db.QueryRowContext(ctx, query, userID, roomID)
-	err := row.Scan(&access.UserID, &access.RoomID, &access.AccessType, &access.Status, &access.GrantedAt)
+	err := row.Scan(&access.UserID, &access.RoomID, &access.AccessType, &access.Status, &access.Role, &access.GrantedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -198,6 +349,57 @@ func (r *Repository) UpdateRoomAccess(ctx context.Context, access *model.RoomAcc
 	return err
 }
 
+// UpdateRoomAccessRole sets the role of a member with granted access, returns false when there is no such member
+func (r *Repository) UpdateRoomAccessRole(ctx context.Context, userID, roomID uuid.UUID, role string) (bool, error) {
+	query := `UPDATE room_access SET role = $3 WHERE user_id = $1 AND room_id = $2 AND status = 'granted'`
+
+	result, err := r.db.ExecContext(ctx, query, userID, roomID, role)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// IsRoomCoHost checks if a user is a co-host of a room
+func (r *Repository) IsRoomCoHost(ctx context.Context, userID, roomID uuid.UUID) (bool, error) {
+	query := `SELECT COUNT(*) FROM room_access WHERE user_id = $1 AND room_id = $2 AND status = 'granted' AND role = 'co_host'`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, userID, roomID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// GetRoomCoHostIDs returns the users granted co-host of a room
+func (r *Repository) GetRoomCoHostIDs(ctx context.Context, roomID uuid.UUID) ([]uuid.UUID, error) {
+	query := `SELECT user_id FROM room_access WHERE room_id = $1 AND status = 'granted' AND role = 'co_host'`
+
+	rows, err := r.db.QueryContext(ctx, query, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
+
 // Guest access methods
 
 // CreateGuestAccessRequest creates a new guest access request
@@ -274,13 +476,13 @@ func (r *Repository) CreateGuestSession(ctx context.Context, session *model.Gues
 	return err
 }
 
-// GetGuestSessionByToken retrieves a guest session by token
+// GetGuestSessionByToken retrieves a guest session by token, expired sessions included until they are cleaned up
 func (r *Repository) GetGuestSessionByToken(ctx context.Context, token string) (*model.GuestSession, error) {
 	var session model.GuestSession
 	query := `
 		SELECT id, room_id, guest_name, session_token, expires_at, approved_by, created_at
 		FROM guest_sessions 
-		WHERE session_token = $1 AND expires_at > NOW()`
+		WHERE session_token = $1`
 
 	row := r.db.QueryRowContext(ctx, query, token)
 	err := row.Scan(&session.ID, &session.RoomID, &session.GuestName, &session.SessionToken, &session.ExpiresAt, &session.ApprovedBy, &session.CreatedAt)
@@ -298,16 +500,23 @@ func (r *Repository) CleanupExpiredGuestSessions(ctx context.Context) error {
 	return err
 }
 
-// CheckUserMovieAccess checks if a user has access to stream a specific movie
-// by verifying they are a member of a room containing that movie
+// CheckUserMovieAccess checks if a user has access to stream a specific movie,
+// either as a member of a room containing that movie or through a direct grant
 func (r *Repository) CheckUserMovieAccess(ctx context.Context, userID uuid.UUID, movieID uuid.UUID) (bool, error) {
 	query := `
-		SELECT COUNT(*) 
-		FROM room_access ra
-		JOIN rooms r ON ra.room_id = r.id
-		WHERE ra.user_id = $1 
-		  AND r.movie_id = $2 
-		  AND ra.status = 'granted'`
+		SELECT
+			(SELECT COUNT(*)
+			 FROM room_access ra
+			 JOIN rooms r ON ra.room_id = r.id
+			 WHERE ra.user_id = $1
+			   AND r.movie_id = $2
+			   AND ra.status = 'granted')
+			+
+			(SELECT COUNT(*)
+			 FROM movie_access ma
+			 WHERE ma.user_id = $1
+			   AND ma.movie_id = $2
+			   AND (ma.expires_at IS NULL OR ma.expires_at > NOW()))`
 
 	logger.Infof("Checking movie access for user %s to movie %s", userID, movieID)
 	var count int
@@ -319,6 +528,19 @@ func (r *Repository) CheckUserMovieAccess(ctx context.Context, userID uuid.UUID,
 	return count > 0, nil
 }
 
+// GetRoomMovie returns the status, uploader and renditions of a movie a room is about to be created for
+func (r *Repository) GetRoomMovie(ctx context.Context, movieID uuid.UUID) (*model.Movie, error) {
+	var movie model.Movie
+	query := `SELECT id, status, uploaded_by, fast_preview, failed_qualities FROM movies WHERE id = $1`
+
+	err := r.db.QueryRowContext(ctx, query, movieID).Scan(&movie.ID, &movie.Status, &movie.UploadedBy, &movie.FastPreview, pq.Array(&movie.FailedQualities))
+	if err != nil {
+		return nil, err
+	}
+
+	return &movie, nil
+}
+
 // CheckRoomContainsMovie verifies if a specific room contains the given movie
 func (r *Repository) CheckRoomContainsMovie(ctx context.Context, roomID uuid.UUID, movieID uuid.UUID) (bool, error) {
 	query := `SELECT COUNT(*) FROM rooms WHERE id = $1 AND movie_id = $2`
@@ -337,11 +559,11 @@ func (r *Repository) GetUserRooms(ctx context.Context, userID uuid.UUID) ([]*mod
 	var rooms []*model.RoomWithDetails
 	query := `
 		SELECT DISTINCT
-			r.id, r.movie_id, r.host_id, r.name, r.description, r.created_at,
+			r.id, r.movie_id, r.host_id, r.name, r.description, r.settings, r.created_at,
 			m.id, m.title, m.description, m.original_file_path, m.transcoded_file_path,
 			m.hls_playlist_url, m.duration_seconds, m.file_size, m.mime_type, m.status,
-			m.uploaded_by, m.created_at, m.processing_started_at, m.processing_ended_at,
-			u.id, u.email, u.role, u.created_at
+			m.uploaded_by, m.created_at, m.processing_started_at, m.processing_ended_at, m.fast_preview,
+			u.id, u.email, u.role, u.display_name, u.avatar_url, u.created_at
 		FROM rooms r
 		JOIN movies m ON r.movie_id = m.id
 		JOIN users u ON r.host_id = u.id
@@ -358,13 +580,13 @@ func (r *Repository) GetUserRooms(ctx context.Context, userID uuid.UUID) ([]*mod
 	for rows.Next() {
 		var roomDetails model.RoomWithDetails
 		err := rows.Scan(
-			&roomDetails.ID, &roomDetails.MovieID, &roomDetails.HostID, &roomDetails.Name, &roomDetails.Description, &roomDetails.CreatedAt,
+			&roomDetails.ID, &roomDetails.MovieID, &roomDetails.HostID, &roomDetails.Name, &roomDetails.Description, &roomDetails.Settings, &roomDetails.CreatedAt,
 			&roomDetails.Movie.ID, &roomDetails.Movie.Title, &roomDetails.Movie.Description,
 			&roomDetails.Movie.OriginalFilePath, &roomDetails.Movie.TranscodedFilePath,
 			&roomDetails.Movie.HLSPlaylistURL, &roomDetails.Movie.DurationSeconds, &roomDetails.Movie.FileSize,
 			&roomDetails.Movie.MimeType, &roomDetails.Movie.Status, &roomDetails.Movie.UploadedBy, &roomDetails.Movie.CreatedAt,
-			&roomDetails.Movie.ProcessingStartedAt, &roomDetails.Movie.ProcessingEndedAt,
-			&roomDetails.Host.ID, &roomDetails.Host.Email, &roomDetails.Host.Role, &roomDetails.Host.CreatedAt,
+			&roomDetails.Movie.ProcessingStartedAt, &roomDetails.Movie.ProcessingEndedAt, &roomDetails.Movie.FastPreview,
+			&roomDetails.Host.ID, &roomDetails.Host.Email, &roomDetails.Host.Role, &roomDetails.Host.DisplayName, &roomDetails.Host.AvatarURL, &roomDetails.Host.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
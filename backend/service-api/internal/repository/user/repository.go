@@ -13,6 +13,7 @@ type Repository interface {
 	Create(user *model.User) error
 	GetByEmail(email string) (*model.User, error)
 	GetByID(id uuid.UUID) (*model.User, error)
+	UpdateProfile(id uuid.UUID, displayName, avatarURL string) error
 }
 
 // repository implements the user repository
@@ -30,10 +31,10 @@ func NewRepository(db *sql.DB) Repository {
 // Create creates a new user in the database
 func (r *repository) Create(user *model.User) error {
 	query := `
-		INSERT INTO users (id, email, password_hash, role, created_at) 
-		VALUES ($1, $2, $3, $4, $5)`
+		INSERT INTO users (id, email, password_hash, role, display_name, avatar_url, created_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
 
-	_, err := r.db.Exec(query, user.ID, user.Email, user.PasswordHash, user.Role, user.CreatedAt)
+	_, err := r.db.Exec(query, user.ID, user.Email, user.PasswordHash, user.Role, user.DisplayName, user.AvatarURL, user.CreatedAt)
 	return err
 }
 
@@ -41,12 +42,12 @@ func (r *repository) Create(user *model.User) error {
 func (r *repository) GetByEmail(email string) (*model.User, error) {
 	user := &model.User{}
 	query := `
-		SELECT id, email, password_hash, role, created_at 
+		SELECT id, email, password_hash, role, display_name, avatar_url, created_at 
 		FROM users 
 		WHERE email = $1`
 
 	row := r.db.QueryRow(query, email)
-	err := row.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.CreatedAt)
+	err := row.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.DisplayName, &user.AvatarURL, &user.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // User not found
@@ -61,12 +62,12 @@ func (r *repository) GetByEmail(email string) (*model.User, error) {
 func (r *repository) GetByID(id uuid.UUID) (*model.User, error) {
 	user := &model.User{}
 	query := `
-		SELECT id, email, password_hash, role, created_at 
+		SELECT id, email, password_hash, role, display_name, avatar_url, created_at 
 		FROM users 
 		WHERE id = $1`
 
 	row := r.db.QueryRow(query, id)
-	err := row.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.CreatedAt)
+	err := row.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.DisplayName, &user.AvatarURL, &user.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // User not found
@@ -77,6 +78,29 @@ func (r *repository) GetByID(id uuid.UUID) (*model.User, error) {
 	return user, nil
 }
 
+// UpdateProfile updates the display name and avatar of a user
+func (r *repository) UpdateProfile(id uuid.UUID, displayName, avatarURL string) error {
+	query := `
+		UPDATE users 
+		SET display_name = $2, avatar_url = $3 
+		WHERE id = $1`
+
+	result, err := r.db.Exec(query, id, displayName, avatarURL)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
 // VerifyPassword verifies a password against its hash
 func VerifyPassword(hashedPassword, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
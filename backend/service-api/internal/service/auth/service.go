@@ -1,16 +1,19 @@
 package auth
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"time"
 	"watch-party/pkg/auth"
-	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
 	"watch-party/pkg/model"
 	authRepo "watch-party/service-api/internal/repository/auth"
 	userRepo "watch-party/service-api/internal/repository/user"
 	userService "watch-party/service-api/internal/service/user"
+
+	"github.com/google/uuid"
 )
 
 var (
@@ -22,7 +25,8 @@ type Service interface {
 	Login(req *model.LoginRequest) (*model.LoginResponse, error)
 	RegisterAdmin(req *model.RegisterRequest) (*model.User, error)
 	RegisterUser(req *model.RegisterRequest) (*model.User, error)
-	Logout(refreshToken string) error
+	Logout(refreshToken, accessToken string) error
+	RevokeUserTokens(userID uuid.UUID) error
 }
 
 // authService provides auth-related services.
@@ -34,12 +38,12 @@ type authService struct {
 
 // NewAuthService creates a new auth service instance.
 func NewAuthService(
-	cfg *config.Config,
+	jwtManager *auth.JWTManager,
 	userService userService.Service,
 	authRepo authRepo.Repository,
 ) Service {
 	return &authService{
-		jwtManager:  auth.NewJWTManager(cfg.JWTSecret),
+		jwtManager:  jwtManager,
 		userService: userService,
 		authRepo:    authRepo,
 	}
@@ -62,6 +66,12 @@ func (s *authService) Login(req *model.LoginRequest) (*model.LoginResponse, erro
 		return nil, ErrInvalidCredentials
 	}
 
+	// accounts created before profiles existed get their default display name on first login
+	err = s.userService.EnsureDisplayName(user)
+	if err != nil {
+		logger.Errorf(err, "failed to set default display name of user %s", user.ID)
+	}
+
 	// generate tokens
 	accessToken, err := s.jwtManager.GenerateAccessToken(user)
 	if err != nil {
@@ -75,7 +85,7 @@ func (s *authService) Login(req *model.LoginRequest) (*model.LoginResponse, erro
 
 	// store refresh token hash in database
 	refreshTokenHash := hashToken(refreshToken)
-	expiresAt := time.Now().Add(time.Hour * 24 * 7) // 7 days
+	expiresAt := time.Now().Add(auth.RefreshTokenTTL)
 	err = s.authRepo.StoreRefreshToken(user.ID, refreshTokenHash, expiresAt)
 	if err != nil {
 		return nil, err
@@ -98,10 +108,42 @@ func (s *authService) RegisterUser(req *model.RegisterRequest) (*model.User, err
 	return s.userService.RegisterUser(req, model.RoleUser)
 }
 
-// Logout invalidates a refresh token
-func (s *authService) Logout(refreshToken string) error {
+// Logout invalidates a refresh token and, when given, the access token of the session
+func (s *authService) Logout(refreshToken, accessToken string) error {
 	refreshTokenHash := hashToken(refreshToken)
-	return s.authRepo.DeleteRefreshToken(refreshTokenHash)
+	err := s.authRepo.DeleteRefreshToken(refreshTokenHash)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	for _, token := range []string{refreshToken, accessToken} {
+		if token == "" {
+			continue
+		}
+		err = s.jwtManager.RevokeToken(ctx, token)
+		if err != nil {
+			// the refresh token is gone already, the access token expires on its own
+			logger.Error(err, "failed to revoke token on logout")
+		}
+	}
+
+	return nil
+}
+
+// RevokeUserTokens logs a user out everywhere by revoking all of their tokens
+func (s *authService) RevokeUserTokens(userID uuid.UUID) error {
+	_, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.jwtManager.RevokeUserTokens(context.Background(), userID, model.TokenRevokedReasonForceLogout)
+	if err != nil {
+		return err
+	}
+
+	return s.authRepo.DeleteAllUserTokens(userID)
 }
 
 // hashToken creates a SHA-256 hash of a token for storage
@@ -0,0 +1,93 @@
+package movie
+
+import (
+	"context"
+	"errors"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrGrantNotFound      = errors.New("user has no direct access to this movie")
+	ErrInvalidAccessGrant = errors.New("give either user_id or email, and an expiry in the future")
+)
+
+// checkMovieOwner loads a movie and checks that the requester uploaded it or is an admin
+func (s *movieService) checkMovieOwner(ctx context.Context, movieID, requesterID uuid.UUID, isAdmin bool) error {
+	movie, err := s.GetMovie(ctx, movieID)
+	if err != nil {
+		return err
+	}
+	if !isAdmin && movie.UploadedBy != requesterID {
+		return ErrAccessDenied
+	}
+	return nil
+}
+
+// GrantMovieAccess lets a user stream a movie without a room, for the uploader's own library or a preview
+// before a room exists. only the uploader of the movie or an admin can grant access.
+func (s *movieService) GrantMovieAccess(ctx context.Context, movieID, requesterID uuid.UUID, isAdmin bool, req *model.GrantMovieAccessRequest) (*model.MovieAccessGrant, error) {
+	if (req.UserID == nil) == (req.Email == "") {
+		return nil, ErrInvalidAccessGrant
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidAccessGrant
+	}
+
+	err := s.checkMovieOwner(ctx, movieID, requesterID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	grant, err := s.movieRepo.GrantMovieAccess(movieID, req.UserID, req.Email, requesterID, req.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if grant == nil {
+		return nil, ErrUserNotFound
+	}
+
+	logger.Infof("user %s granted user %s direct access to movie %s", requesterID, grant.UserID, movieID)
+	return grant, nil
+}
+
+// RevokeMovieAccess removes a user's direct access to a movie, access through rooms is unaffected
+func (s *movieService) RevokeMovieAccess(ctx context.Context, movieID, userID, requesterID uuid.UUID, isAdmin bool) error {
+	err := s.checkMovieOwner(ctx, movieID, requesterID, isAdmin)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := s.movieRepo.RevokeMovieAccess(movieID, userID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrGrantNotFound
+	}
+
+	logger.Infof("user %s revoked direct access of user %s to movie %s", requesterID, userID, movieID)
+	return nil
+}
+
+// ListMovieAccess returns the direct access grants of a movie that have not expired
+func (s *movieService) ListMovieAccess(ctx context.Context, movieID, requesterID uuid.UUID, isAdmin bool) (*model.MovieAccessListResponse, error) {
+	err := s.checkMovieOwner(ctx, movieID, requesterID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := s.movieRepo.GetMovieAccessGrants(movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MovieAccessListResponse{
+		MovieID: movieID,
+		Grants:  grants,
+	}, nil
+}
@@ -0,0 +1,261 @@
+package movie
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// viewSessionWindow is how long a viewer's refetches of a playlist count as the same view
+	viewSessionWindow = 30 * time.Minute
+	// dailyAnalyticsTTL keeps daily counters in Redis long enough to survive a few missed flushes
+	dailyAnalyticsTTL = 48 * time.Hour
+	// analyticsFlushInterval is how often counters are persisted to the database
+	analyticsFlushInterval = 5 * time.Minute
+	// analyticsHistoryDays is how many days of daily counters the analytics endpoint returns
+	analyticsHistoryDays = 30
+)
+
+// RecordView counts the start of a stream session. refetches by the same viewer within
+// viewSessionWindow are ignored so players reloading the playlist do not inflate views.
+func (s *movieService) RecordView(ctx context.Context, movieID uuid.UUID, viewerID string) {
+	if s.redis == nil {
+		return
+	}
+
+	isNew, err := s.redis.SetNX(ctx, redis.MovieViewSessionKey(movieID, viewerID), time.Now().Unix(), viewSessionWindow)
+	if err != nil {
+		logger.Errorf(err, "failed to track view session of movie %s", movieID)
+		return
+	}
+	if !isNew {
+		return
+	}
+
+	day := time.Now().UTC().Format(model.AnalyticsDayFormat)
+	viewsKey := redis.MovieDailyViewsKey(movieID, day)
+	viewersKey := redis.MovieDailyViewersKey(movieID, day)
+
+	_, err = s.redis.Incr(ctx, viewsKey)
+	if err != nil {
+		logger.Errorf(err, "failed to count view of movie %s", movieID)
+		return
+	}
+
+	err = s.redis.PFAdd(ctx, viewersKey, viewerID)
+	if err != nil {
+		logger.Errorf(err, "failed to count daily viewer of movie %s", movieID)
+	}
+
+	err = s.redis.PFAdd(ctx, redis.MovieViewersKey(movieID), viewerID)
+	if err != nil {
+		logger.Errorf(err, "failed to count viewer of movie %s", movieID)
+	}
+
+	for _, key := range []string{viewsKey, viewersKey} {
+		if err := s.redis.Expire(ctx, key, dailyAnalyticsTTL); err != nil {
+			logger.Errorf(err, "failed to set expiration of %s", key)
+		}
+	}
+
+	err = s.redis.SetAdd(ctx, redis.MovieAnalyticsPendingKey, movieID.String()+":"+day)
+	if err != nil {
+		logger.Errorf(err, "failed to mark analytics of movie %s for flushing", movieID)
+	}
+}
+
+// GetMovieAnalytics returns the view counters of a movie to its uploader or an admin
+func (s *movieService) GetMovieAnalytics(ctx context.Context, movieID, requesterID uuid.UUID, isAdmin bool) (*model.MovieAnalytics, error) {
+	movie, err := s.GetMovie(ctx, movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isAdmin && movie.UploadedBy != requesterID {
+		return nil, ErrAccessDenied
+	}
+
+	now := time.Now().UTC()
+	since := now.AddDate(0, 0, -(analyticsHistoryDays - 1))
+
+	daily, err := s.movieRepo.GetDailyViews(movieID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	totalViews, uniqueViewers, err := s.movieRepo.GetViewTotals(movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	// counters that have not been flushed yet only exist in Redis
+	if s.redis != nil {
+		for _, day := range []string{now.Format(model.AnalyticsDayFormat), now.AddDate(0, 0, -1).Format(model.AnalyticsDayFormat)} {
+			views, viewers := s.liveDailyViews(ctx, movieID, day)
+
+			index := -1
+			for i := range daily {
+				if daily[i].Day == day {
+					index = i
+					break
+				}
+			}
+
+			if index == -1 {
+				if views == 0 {
+					continue
+				}
+				daily = append(daily, model.MovieDailyViews{Day: day})
+				index = len(daily) - 1
+			}
+
+			if views > daily[index].Views {
+				totalViews += views - daily[index].Views
+				daily[index].Views = views
+			}
+			if viewers > daily[index].UniqueViewers {
+				daily[index].UniqueViewers = viewers
+			}
+		}
+
+		liveUnique, err := s.redis.PFCount(ctx, redis.MovieViewersKey(movieID))
+		if err == nil && liveUnique > uniqueViewers {
+			uniqueViewers = liveUnique
+		}
+	}
+
+	sort.Slice(daily, func(i, j int) bool {
+		return daily[i].Day > daily[j].Day
+	})
+
+	return &model.MovieAnalytics{
+		MovieID:       movieID,
+		TotalViews:    totalViews,
+		UniqueViewers: uniqueViewers,
+		Daily:         daily,
+		GeneratedAt:   time.Now(),
+	}, nil
+}
+
+// RunAnalyticsFlusher periodically persists view counters until ctx is done
+func (s *movieService) RunAnalyticsFlusher(ctx context.Context) {
+	if s.redis == nil {
+		logger.Info("movie analytics disabled without Redis")
+		return
+	}
+
+	ticker := time.NewTicker(analyticsFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// keep what was counted since the last tick
+			if _, err := s.FlushAnalytics(context.Background()); err != nil {
+				logger.Error(err, "failed to flush movie analytics on shutdown")
+			}
+			return
+		case <-ticker.C:
+			flushed, err := s.FlushAnalytics(ctx)
+			if err != nil {
+				logger.Error(err, "failed to flush movie analytics")
+				continue
+			}
+			if flushed > 0 {
+				logger.Infof("flushed analytics of %d movie day(s)", flushed)
+			}
+		}
+	}
+}
+
+// FlushAnalytics writes the counters changed since the last flush to the database
+func (s *movieService) FlushAnalytics(ctx context.Context) (int, error) {
+	if s.redis == nil {
+		return 0, nil
+	}
+
+	entries, err := s.redis.SetMembers(ctx, redis.MovieAnalyticsPendingKey)
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	// remove first so views recorded during the flush mark their day pending again
+	members := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		members[i] = entry
+	}
+	err = s.redis.SetRemove(ctx, redis.MovieAnalyticsPendingKey, members...)
+	if err != nil {
+		return 0, err
+	}
+
+	flushed := 0
+	flushedMovies := make(map[uuid.UUID]bool)
+	for _, entry := range entries {
+		movieIDStr, day, ok := strings.Cut(entry, ":")
+		movieID, err := uuid.Parse(movieIDStr)
+		if !ok || err != nil {
+			logger.Warnf("invalid pending analytics entry %q", entry)
+			continue
+		}
+
+		views, viewers := s.liveDailyViews(ctx, movieID, day)
+		if views == 0 {
+			// the counters expired before they could be flushed
+			continue
+		}
+
+		err = s.movieRepo.UpsertDailyViews(movieID, day, views, viewers)
+		if err != nil {
+			logger.Errorf(err, "failed to flush analytics of movie %s for %s", movieID, day)
+			if err := s.redis.SetAdd(ctx, redis.MovieAnalyticsPendingKey, entry); err != nil {
+				logger.Errorf(err, "failed to requeue analytics of movie %s", movieID)
+			}
+			continue
+		}
+
+		flushedMovies[movieID] = true
+		flushed++
+	}
+
+	for movieID := range flushedMovies {
+		uniqueViewers, err := s.redis.PFCount(ctx, redis.MovieViewersKey(movieID))
+		if err != nil {
+			logger.Errorf(err, "failed to count viewers of movie %s", movieID)
+			continue
+		}
+
+		err = s.movieRepo.UpsertUniqueViewers(movieID, uniqueViewers)
+		if err != nil {
+			logger.Errorf(err, "failed to flush unique viewers of movie %s", movieID)
+		}
+	}
+
+	return flushed, nil
+}
+
+// liveDailyViews reads the Redis counters of a movie for a day
+func (s *movieService) liveDailyViews(ctx context.Context, movieID uuid.UUID, day string) (int64, int64) {
+	var views int64
+	err := s.redis.Get(ctx, redis.MovieDailyViewsKey(movieID, day), &views)
+	if err != nil {
+		return 0, 0
+	}
+
+	viewers, err := s.redis.PFCount(ctx, redis.MovieDailyViewersKey(movieID, day))
+	if err != nil {
+		viewers = 0
+	}
+
+	return views, viewers
+}
@@ -0,0 +1,130 @@
+package movie
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMovieInUse         = errors.New("movie is used by rooms")
+	ErrBulkDeleteTooLarge = errors.New("too many movies to delete at once")
+	ErrNoMoviesSelected   = errors.New("no movies selected")
+)
+
+const (
+	// maxBulkDeleteMovies bounds the movies one bulk delete handles, larger cleanups are split by the caller
+	maxBulkDeleteMovies = 500
+	// bulkDeleteConcurrency bounds how many movies are deleted at the same time, each one lists and deletes storage objects
+	bulkDeleteConcurrency = 4
+	// bulkDeletePageSize is how many movies of an uploader are read per page
+	bulkDeletePageSize = 100
+)
+
+// BulkDeleteMovies deletes the selected movies with their storage, reporting the outcome per movie.
+// movies that rooms still use are skipped unless forced, since deleting them deletes those rooms.
+func (s *movieService) BulkDeleteMovies(ctx context.Context, req *model.BulkDeleteMoviesRequest) (*model.BulkDeleteMoviesResponse, error) {
+	movieIDs, err := s.bulkDeleteSelection(req)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]model.BulkDeleteResult, len(movieIDs))
+	semaphore := make(chan struct{}, bulkDeleteConcurrency)
+	var wg sync.WaitGroup
+
+	for i, movieID := range movieIDs {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, movieID uuid.UUID) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			results[i] = model.BulkDeleteResult{MovieID: movieID, Deleted: true}
+			err := s.deleteMovieForBulk(ctx, movieID, req.Force)
+			if err != nil {
+				results[i].Deleted = false
+				results[i].Error = err.Error()
+			}
+		}(i, movieID)
+	}
+	wg.Wait()
+
+	response := &model.BulkDeleteMoviesResponse{Results: results}
+	for _, result := range results {
+		if result.Deleted {
+			response.Deleted++
+		} else {
+			response.Failed++
+		}
+	}
+
+	logger.Infof("bulk delete removed %d movies, %d failed", response.Deleted, response.Failed)
+	return response, nil
+}
+
+// bulkDeleteSelection resolves a bulk delete request to the IDs of the movies to delete
+func (s *movieService) bulkDeleteSelection(req *model.BulkDeleteMoviesRequest) ([]uuid.UUID, error) {
+	if req.UploaderID == nil {
+		if len(req.MovieIDs) == 0 {
+			return nil, ErrNoMoviesSelected
+		}
+		if len(req.MovieIDs) > maxBulkDeleteMovies {
+			return nil, fmt.Errorf("%w: at most %d per request", ErrBulkDeleteTooLarge, maxBulkDeleteMovies)
+		}
+
+		// the same movie twice would report a spurious "not found"
+		seen := make(map[uuid.UUID]bool, len(req.MovieIDs))
+		movieIDs := make([]uuid.UUID, 0, len(req.MovieIDs))
+		for _, movieID := range req.MovieIDs {
+			if !seen[movieID] {
+				seen[movieID] = true
+				movieIDs = append(movieIDs, movieID)
+			}
+		}
+		return movieIDs, nil
+	}
+
+	var movieIDs []uuid.UUID
+	for offset := 0; ; offset += bulkDeletePageSize {
+		movies, total, err := s.movieRepo.GetByUploader(*req.UploaderID, bulkDeletePageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		if total > maxBulkDeleteMovies {
+			return nil, fmt.Errorf("%w: the uploader has %d movies, at most %d per request", ErrBulkDeleteTooLarge, total, maxBulkDeleteMovies)
+		}
+
+		for _, movie := range movies {
+			movieIDs = append(movieIDs, movie.ID)
+		}
+		if len(movies) < bulkDeletePageSize {
+			break
+		}
+	}
+
+	if len(movieIDs) == 0 {
+		return nil, ErrNoMoviesSelected
+	}
+	return movieIDs, nil
+}
+
+// deleteMovieForBulk deletes one movie of a bulk delete, refusing movies rooms use unless forced
+func (s *movieService) deleteMovieForBulk(ctx context.Context, movieID uuid.UUID, force bool) error {
+	if !force {
+		rooms, err := s.movieRepo.CountRoomsUsingMovie(movieID)
+		if err != nil {
+			return err
+		}
+		if rooms > 0 {
+			return fmt.Errorf("%w: %d rooms, delete with force to remove them too", ErrMovieInUse, rooms)
+		}
+	}
+
+	return s.DeleteMovie(ctx, movieID)
+}
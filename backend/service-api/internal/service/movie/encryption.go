@@ -0,0 +1,29 @@
+package movie
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotEncrypted is returned for the segment key of a movie whose segments are not encrypted
+var ErrNotEncrypted = errors.New("movie segments are not encrypted")
+
+// GetSegmentKey returns the AES-128 key of a movie's HLS segments. callers must have checked that the
+// requester may stream the movie, the key unlocks every segment of it
+func (s *movieService) GetSegmentKey(ctx context.Context, movieID uuid.UUID) ([]byte, error) {
+	key, err := s.movieRepo.GetEncryptionKey(movieID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMovieNotFound
+		}
+		return nil, fmt.Errorf("failed to get segment key: %w", err)
+	}
+	if len(key) == 0 {
+		return nil, ErrNotEncrypted
+	}
+	return key, nil
+}
@@ -0,0 +1,31 @@
+package movie
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"watch-party/pkg/model"
+)
+
+// pipelineSampleWindowDays is how far back finished transcodes are averaged, long enough to smooth out one odd
+// source and short enough to show a slowdown
+const pipelineSampleWindowDays = 7
+
+// GetPipelineHealth returns the upload and transcode counters kept in the database. transcodes in flight and the
+// scratch directory live on the instance running them and are added by the caller
+func (s *movieService) GetPipelineHealth(ctx context.Context) (*model.PipelineHealth, error) {
+	now := time.Now()
+	stuckAfter := s.config.Storage.VideoProcessing.StuckTranscodeThreshold()
+
+	stats, err := s.movieRepo.GetPipelineStats(now.Add(-stuckAfter), now.AddDate(0, 0, -pipelineSampleWindowDays))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pipeline stats: %w", err)
+	}
+
+	return &model.PipelineHealth{
+		PipelineStats:     *stats,
+		StuckAfterSeconds: stuckAfter.Seconds(),
+		SampleWindowDays:  pipelineSampleWindowDays,
+		GeneratedAt:       now,
+	}, nil
+}
@@ -0,0 +1,74 @@
+package movie
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+)
+
+// abandonedUploadBatchSize limits how many abandoned uploads are handled per sweep
+const abandonedUploadBatchSize = 100
+
+// RunUploadReaper periodically marks movies whose upload never arrived as failed until ctx is done
+func (s *movieService) RunUploadReaper(ctx context.Context) {
+	interval := s.config.Storage.UploadReaperInterval.ToDuration()
+	if interval <= 0 {
+		logger.Info("upload reaper disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reaped, err := s.ReapAbandonedUploads(ctx)
+			if err != nil {
+				logger.Error(err, "failed to reap abandoned uploads")
+				continue
+			}
+			if reaped > 0 {
+				logger.Infof("marked %d abandoned uploads as failed", reaped)
+			}
+		}
+	}
+}
+
+// ReapAbandonedUploads marks movies as failed when their signed upload URL expired without the file being stored
+func (s *movieService) ReapAbandonedUploads(ctx context.Context) (int, error) {
+	movies, err := s.movieRepo.GetAbandonedUploads(time.Now().Add(-uploadURLExpiry), abandonedUploadBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get abandoned uploads: %w", err)
+	}
+
+	reaped := 0
+	for _, movie := range movies {
+		// the file made it to storage, the completion event is just late
+		exists, err := s.storageProvider.Exists(ctx, movie.OriginalFilePath)
+		if err != nil {
+			// an unreachable storage says nothing about the upload, try again on the next sweep
+			logger.Errorf(err, "failed to check upload of movie %s", movie.ID)
+			continue
+		}
+		if exists {
+			logger.Warnf("movie %s is still processing but its upload exists at %s", movie.ID, movie.OriginalFilePath)
+			continue
+		}
+
+		err = s.movieRepo.UpdateStatus(movie.ID, model.StatusFailed)
+		if err != nil {
+			logger.Errorf(err, "failed to mark abandoned upload %s as failed", movie.ID)
+			continue
+		}
+
+		logger.Infof("upload for movie %s (%s) was never completed, marked as failed", movie.ID, movie.Title)
+		reaped++
+	}
+
+	return reaped, nil
+}
@@ -0,0 +1,68 @@
+package movie
+
+import (
+	"context"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+)
+
+// movieRemovedTTL is how long stream requests for a deleted movie are answered as removed instead of not found,
+// well beyond the lifetime of the playlist URLs players could still hold
+const movieRemovedTTL = 24 * time.Hour
+
+// notifyMovieRemoved marks a deleted movie as removed and tells service-sync to end the sessions of its rooms
+func (s *movieService) notifyMovieRemoved(ctx context.Context, movieID uuid.UUID, roomIDs []uuid.UUID) {
+	if s.redis == nil {
+		logger.Warnf("redis not configured, removal of movie %s not broadcast to %d rooms", movieID, len(roomIDs))
+		return
+	}
+
+	err := s.redis.Set(ctx, redis.MovieRemovedKey(movieID), time.Now().Unix(), movieRemovedTTL)
+	if err != nil {
+		logger.Errorf(err, "failed to mark movie %s as removed", movieID)
+	}
+
+	for _, roomID := range roomIDs {
+		event := &model.SyncMessage{
+			ID:        uuid.New(),
+			RoomID:    roomID,
+			Action:    model.ActionMovieUnavailable,
+			Timestamp: time.Now(),
+			Data: model.SyncData{
+				Extra: map[string]interface{}{
+					"movie_id": movieID.String(),
+					"reason":   model.MovieUnavailableReasonDeleted,
+				},
+			},
+		}
+
+		_, err := s.redis.PublishRoomEvent(ctx, roomID, event)
+		if err != nil {
+			logger.Errorf(err, "failed to publish removal of movie %s to room %s", movieID, roomID)
+		}
+
+		// the room was deleted with the movie, its cached co-hosts and slow mode go with it
+		err = s.redis.Delete(ctx, redis.RoomCoHostsKey(roomID), redis.RoomSlowModeKey(roomID))
+		if err != nil {
+			logger.Errorf(err, "failed to clear cached state of room %s", roomID)
+		}
+	}
+}
+
+// IsMovieRemoved reports whether a movie was deleted recently, callers use it after the movie was not found
+func (s *movieService) IsMovieRemoved(ctx context.Context, movieID uuid.UUID) bool {
+	if s.redis == nil {
+		return false
+	}
+
+	exists, err := s.redis.Exists(ctx, redis.MovieRemovedKey(movieID))
+	if err != nil {
+		logger.Errorf(err, "failed to check removal of movie %s", movieID)
+		return false
+	}
+	return exists > 0
+}
@@ -0,0 +1,128 @@
+package movie
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/redis"
+	"watch-party/pkg/storage"
+	"watch-party/pkg/video"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxPlaylistBytes bounds the media playlist read to build a segment timeline
+	maxPlaylistBytes = 1024 * 1024
+	// segmentTimelineTTL is how long a parsed timeline is cached, the segments of a published rendition never change
+	segmentTimelineTTL = 24 * time.Hour
+)
+
+// GetSegments returns the segments of one rendition of a movie and its total duration. the parsed timeline is
+// cached in Redis, where service-sync also reads it to tell clients which segment a seek lands in
+func (s *movieService) GetSegments(ctx context.Context, movieID uuid.UUID, quality string) ([]video.SegmentInfo, float64, error) {
+	if cached := s.cachedSegments(ctx, movieID, quality); cached != nil {
+		return cached.Segments, cached.TotalDuration, nil
+	}
+
+	segments, totalDuration, err := s.loadSegments(ctx, movieID, quality)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.cacheSegments(ctx, movieID, quality, &video.SegmentTimeline{Segments: segments, TotalDuration: totalDuration})
+	return segments, totalDuration, nil
+}
+
+// cachedSegments returns the cached timeline of a rendition, nil when it is not cached
+func (s *movieService) cachedSegments(ctx context.Context, movieID uuid.UUID, quality string) *video.SegmentTimeline {
+	if s.redis == nil {
+		return nil
+	}
+
+	value, err := s.redis.HGet(ctx, redis.MovieSegmentsKey(movieID), quality)
+	if err != nil {
+		return nil
+	}
+
+	var timeline video.SegmentTimeline
+	if err := json.Unmarshal([]byte(value), &timeline); err != nil {
+		logger.Warnf("invalid cached segments of movie %s at %s: %v", movieID, quality, err)
+		return nil
+	}
+	return &timeline
+}
+
+func (s *movieService) cacheSegments(ctx context.Context, movieID uuid.UUID, quality string, timeline *video.SegmentTimeline) {
+	if s.redis == nil || len(timeline.Segments) == 0 {
+		return
+	}
+
+	encoded, err := json.Marshal(timeline)
+	if err != nil {
+		logger.Error(err, "failed to encode segment timeline")
+		return
+	}
+
+	key := redis.MovieSegmentsKey(movieID)
+	err = s.redis.HSet(ctx, key, quality, string(encoded))
+	if err == nil {
+		err = s.redis.Expire(ctx, key, segmentTimelineTTL)
+	}
+	if err != nil {
+		logger.Errorf(err, "failed to cache segments of movie %s at %s", movieID, quality)
+	}
+}
+
+// loadSegments fetches the playlist of one rendition through a short-lived signed URL and parses its segments
+func (s *movieService) loadSegments(ctx context.Context, movieID uuid.UUID, quality string) ([]video.SegmentInfo, float64, error) {
+	playlistPath := "hls/" + movieID.String() + "/" + quality + "/playlist.m3u8"
+
+	playlistURLs, err := s.storageProvider.GenerateSignedURLs(ctx, []string{playlistPath}, &storage.CDNSignedURLOptions{
+		ExpiresIn:    time.Minute * 10, // short expiry for playlist
+		CacheControl: "private, max-age=60",
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to generate playlist URL: %w", err)
+	}
+
+	playlistURL, exists := playlistURLs[playlistPath]
+	if !exists {
+		return nil, 0, fmt.Errorf("playlist URL not generated for %s", playlistPath)
+	}
+
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, playlistURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build playlist request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch playlist: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("playlist request failed with status: %d", resp.StatusCode)
+	}
+
+	// a media playlist is a few KB, anything near the limit is not one
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPlaylistBytes+1))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read playlist: %w", err)
+	}
+	if len(body) > maxPlaylistBytes {
+		return nil, 0, fmt.Errorf("playlist larger than %d bytes", maxPlaylistBytes)
+	}
+
+	segments, totalDuration := video.ParseSegments(string(body))
+	return segments, totalDuration, nil
+}
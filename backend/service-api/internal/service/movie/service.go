@@ -7,53 +7,75 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+	"watch-party/pkg/config"
 	"watch-party/pkg/logger"
 	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
 	"watch-party/pkg/storage"
+	"watch-party/pkg/video"
 	movieRepo "watch-party/service-api/internal/repository/movie"
 
 	"github.com/google/uuid"
 )
 
 var (
-	ErrMovieNotFound     = errors.New("movie not found")
-	ErrUnsupportedFormat = errors.New("unsupported video format")
-	ErrInvalidFile       = errors.New("invalid file")
+	ErrMovieNotFound       = errors.New("movie not found")
+	ErrUnsupportedFormat   = errors.New("unsupported video format")
+	ErrInvalidFile         = errors.New("invalid file")
+	ErrUnsupportedMimeType = errors.New("unsupported mime type")
+	ErrFileTooLarge        = errors.New("file size too large")
+	ErrAccessDenied        = errors.New("access denied")
 )
 
-// Supported video formats
-var supportedFormats = map[string]bool{
-	".mp4":  true,
-	".avi":  true,
-	".mkv":  true,
-	".mov":  true,
-	".webm": true,
-	".m4v":  true,
-}
+// uploadURLExpiry is how long a signed upload URL stays valid
+const uploadURLExpiry = time.Hour
 
 // Service defines the movie service interface
 type Service interface {
 	InitiateUpload(ctx context.Context, req *model.UploadMovieRequest, uploaderID uuid.UUID) (*model.MovieUploadResponse, error)
+	ValidateUpload(ctx context.Context, req *model.ValidateUploadRequest) *model.ValidateUploadResponse
 	GetMovie(ctx context.Context, id uuid.UUID) (*model.Movie, error)
+	GetStreamingMovie(ctx context.Context, id uuid.UUID) (*model.Movie, error)
 	GetMovies(ctx context.Context, page, pageSize int) (*model.MovieListResponse, error)
 	GetMoviesByUploader(ctx context.Context, uploaderID uuid.UUID, page, pageSize int) (*model.MovieListResponse, error)
 	UpdateMovie(ctx context.Context, id uuid.UUID, req *model.UploadMovieRequest) (*model.Movie, error)
 	DeleteMovie(ctx context.Context, id uuid.UUID) error
+	GetMovieRooms(ctx context.Context, id uuid.UUID) (*model.MovieRoomsResponse, error)
+	BulkDeleteMovies(ctx context.Context, req *model.BulkDeleteMoviesRequest) (*model.BulkDeleteMoviesResponse, error)
 	GetMovieStreamURL(ctx context.Context, id uuid.UUID) (string, error)
 	GetMovieStatus(ctx context.Context, id uuid.UUID) (*model.MovieStatusResponse, error)
+	GetPipelineHealth(ctx context.Context) (*model.PipelineHealth, error)
+	RunUploadReaper(ctx context.Context)
+	ReapAbandonedUploads(ctx context.Context) (int, error)
+	RecordView(ctx context.Context, movieID uuid.UUID, viewerID string)
+	GetMovieAnalytics(ctx context.Context, movieID, requesterID uuid.UUID, isAdmin bool) (*model.MovieAnalytics, error)
+	RunAnalyticsFlusher(ctx context.Context)
+	FlushAnalytics(ctx context.Context) (int, error)
+	UploadSubtitle(ctx context.Context, movieID, uploaderID uuid.UUID, isAdmin bool, req *model.UploadSubtitleRequest) (*model.MovieSubtitle, error)
+	ListSubtitles(ctx context.Context, movieID uuid.UUID) (*model.SubtitleListResponse, error)
+	GrantMovieAccess(ctx context.Context, movieID, requesterID uuid.UUID, isAdmin bool, req *model.GrantMovieAccessRequest) (*model.MovieAccessGrant, error)
+	RevokeMovieAccess(ctx context.Context, movieID, userID, requesterID uuid.UUID, isAdmin bool) error
+	ListMovieAccess(ctx context.Context, movieID, requesterID uuid.UUID, isAdmin bool) (*model.MovieAccessListResponse, error)
+	IsMovieRemoved(ctx context.Context, movieID uuid.UUID) bool
+	GetSegmentKey(ctx context.Context, movieID uuid.UUID) ([]byte, error)
+	GetSegments(ctx context.Context, movieID uuid.UUID, quality string) ([]video.SegmentInfo, float64, error)
 }
 
 // movieService provides movie-related services.
 type movieService struct {
 	movieRepo       movieRepo.Repository
 	storageProvider storage.Provider
+	redis           *redis.Client // optional, nil disables view analytics and removal notices
+	config          *config.Config
 }
 
 // NewMovieService creates a new movie service instance.
-func NewMovieService(movieRepo movieRepo.Repository, storageProvider storage.Provider) Service {
+func NewMovieService(movieRepo movieRepo.Repository, storageProvider storage.Provider, redisClient *redis.Client, config *config.Config) Service {
 	return &movieService{
 		movieRepo:       movieRepo,
 		storageProvider: storageProvider,
+		redis:           redisClient,
+		config:          config,
 	}
 }
 
@@ -67,7 +89,7 @@ func (s *movieService) InitiateUpload(ctx context.Context, req *model.UploadMovi
 
 	// generate unique filename
 	ext := filepath.Ext(req.FileName)
-	filename := fmt.Sprintf("uploads/%s_%d%s", uuid.New().String(), time.Now().Unix(), ext)
+	filename := fmt.Sprintf("%s%s_%d%s", storage.UploadsPrefix, uuid.New().String(), time.Now().Unix(), ext)
 
 	// create movie record with processing status
 	movie := &model.Movie{
@@ -85,6 +107,7 @@ func (s *movieService) InitiateUpload(ctx context.Context, req *model.UploadMovi
 		CreatedAt:           time.Now(),
 		ProcessingStartedAt: nil,
 		ProcessingEndedAt:   nil,
+		FastPreview:         req.FastPreview,
 	}
 
 	// save movie record to database
@@ -96,8 +119,8 @@ func (s *movieService) InitiateUpload(ctx context.Context, req *model.UploadMovi
 	// generate signed URL for upload
 	uploadOpts := &storage.UploadOptions{
 		ContentType: movie.MimeType,
-		MaxFileSize: req.FileSize,
-		ExpiresIn:   time.Hour, // URL expires in 1 hour
+		MaxFileSize: req.FileSize, // at most MaxUploadBytes, storage rejects anything larger than declared
+		ExpiresIn:   uploadURLExpiry,
 		Public:      false,
 	}
 
@@ -114,13 +137,31 @@ func (s *movieService) InitiateUpload(ctx context.Context, req *model.UploadMovi
 	logger.Infof("upload initiated for movie %s: %s", movie.Title, movie.ID)
 
 	return &model.MovieUploadResponse{
-		MovieID:   movie.ID,
-		SignedURL: signedURL.URL,
-		FilePath:  filename,
-		Message:   "Upload initiated successfully. Use the signed URL to upload your video file.",
+		MovieID:       movie.ID,
+		SignedURL:     signedURL.URL,
+		UploadMethod:  signedURL.Method,
+		UploadHeaders: signedURL.Headers,
+		UploadFields:  signedURL.FormFields,
+		FilePath:      filename,
+		Message:       "Upload initiated successfully. Use the signed URL to upload your video file.",
 	}, nil
 }
 
+// ValidateUpload runs the upload checks without creating a movie record or signed URL
+func (s *movieService) ValidateUpload(ctx context.Context, req *model.ValidateUploadRequest) *model.ValidateUploadResponse {
+	reasons := make([]string, 0)
+	for _, problem := range s.checkUploadFile(req.FileName, req.FileSize, req.MimeType) {
+		reasons = append(reasons, problem.Error())
+	}
+
+	return &model.ValidateUploadResponse{
+		Accepted:    len(reasons) == 0,
+		Reasons:     reasons,
+		MimeType:    s.getMimeTypeFromFilename(req.FileName),
+		MaxFileSize: s.config.Storage.UploadSizeLimit(),
+	}
+}
+
 // GetMovie retrieves a movie by ID
 func (s *movieService) GetMovie(ctx context.Context, id uuid.UUID) (*model.Movie, error) {
 	movie, err := s.movieRepo.GetByID(id)
@@ -133,14 +174,22 @@ func (s *movieService) GetMovie(ctx context.Context, id uuid.UUID) (*model.Movie
 	return movie, nil
 }
 
-// GetMovies retrieves movies with pagination
-func (s *movieService) GetMovies(ctx context.Context, page, pageSize int) (*model.MovieListResponse, error) {
-	if page <= 0 {
-		page = 1
+// GetStreamingMovie retrieves a movie like GetMovie, possibly a few seconds old. the streaming routes check the
+// status of a movie on every playlist and segment request, a database round trip each time adds up
+func (s *movieService) GetStreamingMovie(ctx context.Context, id uuid.UUID) (*model.Movie, error) {
+	movie, err := s.movieRepo.GetByIDCached(id)
+	if err != nil {
+		return nil, err
 	}
-	if pageSize <= 0 || pageSize > 100 {
-		pageSize = 20
+	if movie == nil {
+		return nil, ErrMovieNotFound
 	}
+	return movie, nil
+}
+
+// GetMovies retrieves movies with pagination
+func (s *movieService) GetMovies(ctx context.Context, page, pageSize int) (*model.MovieListResponse, error) {
+	page, pageSize = model.NormalizePagination(page, pageSize)
 
 	offset := (page - 1) * pageSize
 	movies, totalCount, err := s.movieRepo.GetAll(pageSize, offset)
@@ -148,22 +197,12 @@ func (s *movieService) GetMovies(ctx context.Context, page, pageSize int) (*mode
 		return nil, err
 	}
 
-	return &model.MovieListResponse{
-		Movies:     movies,
-		TotalCount: totalCount,
-		Page:       page,
-		PageSize:   pageSize,
-	}, nil
+	return model.NewPage(movies, totalCount, page, pageSize), nil
 }
 
 // GetMoviesByUploader retrieves movies uploaded by a specific user
 func (s *movieService) GetMoviesByUploader(ctx context.Context, uploaderID uuid.UUID, page, pageSize int) (*model.MovieListResponse, error) {
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 || pageSize > 100 {
-		pageSize = 20
-	}
+	page, pageSize = model.NormalizePagination(page, pageSize)
 
 	offset := (page - 1) * pageSize
 	movies, totalCount, err := s.movieRepo.GetByUploader(uploaderID, pageSize, offset)
@@ -171,12 +210,7 @@ func (s *movieService) GetMoviesByUploader(ctx context.Context, uploaderID uuid.
 		return nil, err
 	}
 
-	return &model.MovieListResponse{
-		Movies:     movies,
-		TotalCount: totalCount,
-		Page:       page,
-		PageSize:   pageSize,
-	}, nil
+	return model.NewPage(movies, totalCount, page, pageSize), nil
 }
 
 // UpdateMovie updates a movie's metadata
@@ -214,31 +248,68 @@ func (s *movieService) DeleteMovie(ctx context.Context, id uuid.UUID) error {
 		return ErrMovieNotFound
 	}
 
+	// the rooms are deleted with the movie, remember them to end their live sessions
+	roomIDs, err := s.movieRepo.GetRoomIDsUsingMovie(id)
+	if err != nil {
+		return err
+	}
+
 	// delete from database first
 	err = s.movieRepo.Delete(id)
 	if err != nil {
 		return err
 	}
 
-	// delete original file from storage if it exists
+	s.notifyMovieRemoved(ctx, id, roomIDs)
+	s.deleteMovieFiles(ctx, movie)
+
+	logger.Infof("movie deleted successfully: %s (ID: %s)", movie.Title, id)
+	return nil
+}
+
+// GetMovieRooms lists the rooms that play a movie, all of them are deleted with it
+func (s *movieService) GetMovieRooms(ctx context.Context, id uuid.UUID) (*model.MovieRoomsResponse, error) {
+	movie, err := s.movieRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if movie == nil {
+		return nil, ErrMovieNotFound
+	}
+
+	rooms, err := s.movieRepo.GetRoomsUsingMovie(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MovieRoomsResponse{
+		MovieID: id,
+		Rooms:   rooms,
+	}, nil
+}
+
+// deleteMovieFiles removes the original upload and everything generated from it.
+// the database row is already gone, so leftovers are only logged for manual cleanup.
+func (s *movieService) deleteMovieFiles(ctx context.Context, movie *model.Movie) {
 	if movie.OriginalFilePath != "" {
-		err = s.storageProvider.Delete(ctx, movie.OriginalFilePath)
+		err := s.storageProvider.Delete(ctx, movie.OriginalFilePath)
 		if err != nil {
 			logger.Error(err, "failed to delete original movie file from storage")
 		}
 	}
 
-	// delete transcoded files from storage if they exist
 	if movie.TranscodedFilePath != "" {
-		// delete all transcoded files (this would need implementation based on storage structure)
-		err = s.deleteTranscodedFiles(ctx, movie.TranscodedFilePath)
+		// the trailing slash keeps the prefix from matching other movies' directories
+		deleted, err := s.storageProvider.DeletePrefix(ctx, strings.TrimSuffix(movie.TranscodedFilePath, "/")+"/")
 		if err != nil {
-			logger.Error(err, "failed to delete transcoded files from storage")
+			logger.Errorf(err, "failed to delete transcoded files of movie %s (%d deleted)", movie.ID, deleted)
 		}
 	}
 
-	logger.Infof("movie deleted successfully: %s (ID: %s)", movie.Title, id)
-	return nil
+	deleted, err := s.storageProvider.DeletePrefix(ctx, fmt.Sprintf("subtitles/%s/", movie.ID))
+	if err != nil {
+		logger.Errorf(err, "failed to delete subtitles of movie %s (%d deleted)", movie.ID, deleted)
+	}
 }
 
 // GetMovieStreamURL returns a signed URL for streaming the movie
@@ -296,9 +367,19 @@ func (s *movieService) GetMovieStatus(ctx context.Context, id uuid.UUID) (*model
 		response.HLSPlaylistURL = movie.HLSPlaylistURL
 	}
 
-	// include error message for failed movies (you might want to add an error field to the Movie model)
+	// a movie published without some qualities plays, but not in every quality a player offers
+	if movie.Status == model.StatusAvailable {
+		response.Qualities = movie.Qualities()
+		response.Partial = movie.IsPartial()
+		response.FailedQualities = movie.FailedQualities
+	}
+
+	// include error message for failed movies
 	if movie.Status == model.StatusFailed {
 		response.ErrorMessage = "Video processing failed"
+		if movie.FailureReason != "" {
+			response.ErrorMessage = movie.FailureReason
+		}
 	}
 
 	return response, nil
@@ -310,66 +391,43 @@ func (s *movieService) validateUploadRequest(req *model.UploadMovieRequest) erro
 		return fmt.Errorf("title is required")
 	}
 
-	// validate file extension
-	ext := strings.ToLower(filepath.Ext(req.FileName))
-	if !supportedFormats[ext] {
-		return ErrUnsupportedFormat
-	}
-
-	// validate file size (max 5GB)
-	const maxFileSize = 5 * 1024 * 1024 * 1024 // 5GB
-	if req.FileSize > maxFileSize {
-		return fmt.Errorf("file size too large: %d bytes (max: %d bytes)", req.FileSize, maxFileSize)
-	}
-
-	if req.FileSize <= 0 {
-		return fmt.Errorf("invalid file size: %d", req.FileSize)
+	problems := s.checkUploadFile(req.FileName, req.FileSize, req.MimeType)
+	if len(problems) > 0 {
+		return problems[0]
 	}
 
 	return nil
 }
 
-// getMimeTypeFromFilename returns the MIME type based on file extension
-func (s *movieService) getMimeTypeFromFilename(filename string) string {
-	ext := strings.ToLower(filepath.Ext(filename))
-	return s.getMimeType(ext)
-}
+// checkUploadFile returns every problem that would make the file fail upload validation
+func (s *movieService) checkUploadFile(fileName string, fileSize int64, mimeType string) []error {
+	var problems []error
 
-// deleteTranscodedFiles deletes all transcoded files for a movie
-func (s *movieService) deleteTranscodedFiles(ctx context.Context, transcodedPath string) error {
-	// list all files under the transcoded path
-	files, err := s.storageProvider.ListObjects(ctx, transcodedPath)
-	if err != nil {
-		return fmt.Errorf("failed to list transcoded files: %w", err)
+	// validate file extension
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if !s.config.Storage.VideoProcessing.AllowsExtension(ext) {
+		problems = append(problems, fmt.Errorf("%w: %s (allowed: %s)", ErrUnsupportedFormat, ext,
+			strings.Join(s.config.Storage.VideoProcessing.InputExtensions(), ", ")))
 	}
 
-	// delete each file
-	for _, file := range files {
-		err = s.storageProvider.Delete(ctx, file)
-		if err != nil {
-			logger.Error(err, fmt.Sprintf("failed to delete transcoded file: %s", file))
-		}
+	maxFileSize := s.config.Storage.UploadSizeLimit()
+	if fileSize > maxFileSize {
+		problems = append(problems, fmt.Errorf("%w: %d bytes (max: %d bytes)", ErrFileTooLarge, fileSize, maxFileSize))
 	}
 
-	return nil
-}
+	if fileSize <= 0 {
+		problems = append(problems, fmt.Errorf("invalid file size: %d", fileSize))
+	}
 
-// getMimeType returns the MIME type based on file extension
-func (s *movieService) getMimeType(ext string) string {
-	switch strings.ToLower(ext) {
-	case ".mp4":
-		return "video/mp4"
-	case ".avi":
-		return "video/x-msvideo"
-	case ".mkv":
-		return "video/x-matroska"
-	case ".mov":
-		return "video/quicktime"
-	case ".webm":
-		return "video/webm"
-	case ".m4v":
-		return "video/mp4"
-	default:
-		return "application/octet-stream"
+	// the stored type is inferred from the extension, a declared type only has to look like video
+	if mimeType != "" && !strings.HasPrefix(mimeType, "video/") && mimeType != "application/octet-stream" {
+		problems = append(problems, fmt.Errorf("%w: %s", ErrUnsupportedMimeType, mimeType))
 	}
+
+	return problems
+}
+
+// getMimeTypeFromFilename returns the MIME type based on file extension
+func (s *movieService) getMimeTypeFromFilename(filename string) string {
+	return config.VideoMimeType(filepath.Ext(filename))
 }
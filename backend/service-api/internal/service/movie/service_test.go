@@ -0,0 +1,76 @@
+package movie
+
+import (
+	"context"
+	"testing"
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/storage"
+	movieRepo "watch-party/service-api/internal/repository/movie"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingRepo records the movies written, any other call panics on the nil embedded repository
+type recordingRepo struct {
+	movieRepo.Repository
+	created []*model.Movie
+}
+
+func (r *recordingRepo) Create(movie *model.Movie) error {
+	r.created = append(r.created, movie)
+	return nil
+}
+
+// recordingStorage records the upload URLs signed, any other call panics on the nil embedded provider
+type recordingStorage struct {
+	storage.Provider
+	signed []string
+}
+
+func (s *recordingStorage) GenerateSignedUploadURL(ctx context.Context, filename string, opts *storage.UploadOptions) (*storage.SignedURL, error) {
+	s.signed = append(s.signed, filename)
+	return &storage.SignedURL{URL: "https://storage.example.com/" + filename}, nil
+}
+
+func TestValidateUpload(t *testing.T) {
+	logger.InitLogger(&config.Config{})
+	repo := &recordingRepo{}
+	provider := &recordingStorage{}
+	// without Redis anything queued for later would panic as well
+	s := NewMovieService(repo, provider, nil, &config.Config{})
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		req      model.ValidateUploadRequest
+		accepted bool
+		reasons  int
+	}{
+		{name: "accepted", req: model.ValidateUploadRequest{FileName: "movie.mp4", FileSize: 1024, MimeType: "video/mp4"}, accepted: true},
+		{name: "unsupported format", req: model.ValidateUploadRequest{FileName: "movie.exe", FileSize: 1024}, reasons: 1},
+		{name: "too large", req: model.ValidateUploadRequest{FileName: "movie.mp4", FileSize: int64(config.DefaultMaxUploadBytes) + 1}, reasons: 1},
+		{name: "every problem", req: model.ValidateUploadRequest{FileName: "movie.exe", FileSize: 0, MimeType: "text/plain"}, reasons: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := s.ValidateUpload(ctx, &tt.req)
+			assert.Equal(t, tt.accepted, response.Accepted)
+			assert.Len(t, response.Reasons, tt.reasons)
+			assert.Equal(t, int64(config.DefaultMaxUploadBytes), response.MaxFileSize)
+		})
+	}
+
+	assert.Empty(t, repo.created, "a dry run creates no movie record")
+	assert.Empty(t, provider.signed, "a dry run signs no upload URL")
+
+	// the same checks pass an upload through to the record and the signed URL
+	_, err := s.InitiateUpload(ctx, &model.UploadMovieRequest{Title: "movie", FileName: "movie.mp4", FileSize: 1024, MimeType: "video/mp4"}, uuid.New())
+	require.NoError(t, err)
+	assert.Len(t, repo.created, 1)
+	assert.Len(t, provider.signed, 1)
+}
@@ -0,0 +1,177 @@
+package movie
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/storage"
+	"watch-party/pkg/video"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidSubtitle  = video.ErrInvalidSubtitle
+	ErrInvalidLanguage  = errors.New("language must be a BCP 47 tag such as en or pt-BR")
+	ErrSubtitleTooLarge = errors.New("subtitle file too large")
+)
+
+const (
+	// subtitleURLExpiry matches the lifetime of the HLS URLs players get next to the subtitles
+	subtitleURLExpiry = 2 * time.Hour
+	// maxSubtitleLabelLength bounds the label shown in the player's track menu
+	maxSubtitleLabelLength = 100
+)
+
+// languageTag accepts the common shapes of BCP 47 tags: a language with optional script and region
+var languageTag = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z]{4})?(-([a-zA-Z]{2}|[0-9]{3}))?$`)
+
+// canonicalLanguage validates a language tag and normalizes its case, so "pt-br" and "pt-BR" are one track
+func canonicalLanguage(tag string) (string, bool) {
+	tag = strings.TrimSpace(tag)
+	if !languageTag.MatchString(tag) {
+		return "", false
+	}
+
+	parts := strings.Split(tag, "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		if len(parts[i]) == 4 {
+			parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:])
+		} else {
+			parts[i] = strings.ToUpper(parts[i])
+		}
+	}
+	return strings.Join(parts, "-"), true
+}
+
+// subtitleStoragePath returns where the WebVTT track of a movie for a language is stored
+func subtitleStoragePath(movieID uuid.UUID, language string) string {
+	return fmt.Sprintf("subtitles/%s/%s.vtt", movieID, language)
+}
+
+// UploadSubtitle adds or replaces the subtitle track of a movie for a language, converting SRT to WebVTT.
+// only the uploader of the movie or an admin can add tracks.
+func (s *movieService) UploadSubtitle(ctx context.Context, movieID, uploaderID uuid.UUID, isAdmin bool, req *model.UploadSubtitleRequest) (*model.MovieSubtitle, error) {
+	movie, err := s.GetMovie(ctx, movieID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && movie.UploadedBy != uploaderID {
+		return nil, ErrAccessDenied
+	}
+
+	language, ok := canonicalLanguage(req.Language)
+	if !ok {
+		return nil, ErrInvalidLanguage
+	}
+	if len(req.Content) > model.MaxSubtitleFileSize {
+		return nil, fmt.Errorf("%w: max %d bytes", ErrSubtitleTooLarge, model.MaxSubtitleFileSize)
+	}
+
+	label := strings.TrimSpace(req.Label)
+	if label == "" {
+		label = language
+	}
+	if len(label) > maxSubtitleLabelLength {
+		return nil, fmt.Errorf("%w: label must be at most %d characters", ErrInvalidSubtitle, maxSubtitleLabelLength)
+	}
+
+	vtt, err := video.ToWebVTT(req.Filename, req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	storagePath := subtitleStoragePath(movieID, language)
+	err = s.uploadSubtitleFile(ctx, vtt, storagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	subtitle := &model.MovieSubtitle{
+		ID:         uuid.New(),
+		MovieID:    movieID,
+		Language:   language,
+		Label:      label,
+		FilePath:   storagePath,
+		UploadedBy: uploaderID,
+		CreatedAt:  time.Now(),
+	}
+
+	err = s.movieRepo.UpsertSubtitle(subtitle)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("added %s subtitles to movie %s", language, movieID)
+	return subtitle, nil
+}
+
+// uploadSubtitleFile writes a WebVTT track to storage through a temporary file, which the providers upload from
+func (s *movieService) uploadSubtitleFile(ctx context.Context, vtt []byte, storagePath string) error {
+	// the extension lets the providers store the track as text/vtt
+	tempFile, err := os.CreateTemp("", "subtitle-*.vtt")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write(vtt)
+	closeErr := tempFile.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to write temp file: %w", closeErr)
+	}
+
+	err = s.storageProvider.UploadFromPath(ctx, tempFile.Name(), storagePath)
+	if err != nil {
+		return fmt.Errorf("failed to upload subtitles: %w", err)
+	}
+	return nil
+}
+
+// ListSubtitles returns the subtitle tracks of a movie with signed URLs, callers check access to the movie
+func (s *movieService) ListSubtitles(ctx context.Context, movieID uuid.UUID) (*model.SubtitleListResponse, error) {
+	_, err := s.GetMovie(ctx, movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	subtitles, err := s.movieRepo.GetSubtitles(movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.SubtitleListResponse{
+		MovieID:   movieID,
+		Subtitles: make([]model.SubtitleTrack, 0, len(subtitles)),
+		ExpiresAt: time.Now().Add(subtitleURLExpiry),
+	}
+
+	for _, subtitle := range subtitles {
+		url, err := s.storageProvider.GenerateCDNSignedURL(ctx, subtitle.FilePath, &storage.CDNSignedURLOptions{
+			ExpiresIn:    subtitleURLExpiry,
+			CacheControl: "public, max-age=3600",
+			ContentType:  "text/vtt",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign subtitle URL: %w", err)
+		}
+
+		response.Subtitles = append(response.Subtitles, model.SubtitleTrack{
+			Language: subtitle.Language,
+			Label:    subtitle.Label,
+			URL:      url,
+		})
+	}
+
+	return response, nil
+}
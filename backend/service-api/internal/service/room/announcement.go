@@ -0,0 +1,102 @@
+package room
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+)
+
+// announceRoomsLimit bounds how many of the most recently active rooms an announcement to all rooms reaches
+const announceRoomsLimit = 1000
+
+// Announce publishes an admin announcement to every participant of a room
+func (s *Service) Announce(ctx context.Context, roomID, adminID uuid.UUID, req *model.AnnouncementRequest) (*model.AnnouncementResponse, error) {
+	if s.redis == nil {
+		return nil, fmt.Errorf("redis not configured")
+	}
+
+	_, err := s.roomRepo.GetRoomByID(ctx, roomID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("room not found")
+		}
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	err = s.publishAnnouncement(ctx, roomID, adminID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("admin %s announced to room %s: %s", adminID, roomID, req.Message)
+	return &model.AnnouncementResponse{Rooms: []uuid.UUID{roomID}}, nil
+}
+
+// AnnounceAll publishes an admin announcement to every room someone is connected to
+func (s *Service) AnnounceAll(ctx context.Context, adminID uuid.UUID, req *model.AnnouncementRequest) (*model.AnnouncementResponse, error) {
+	if s.redis == nil {
+		return nil, fmt.Errorf("redis not configured")
+	}
+
+	roomIDs, err := s.redis.ZRevRange(ctx, redis.ActiveRoomsKey(), 0, announceRoomsLimit-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active rooms: %w", err)
+	}
+
+	response := &model.AnnouncementResponse{Rooms: []uuid.UUID{}}
+	for _, roomIDStr := range roomIDs {
+		roomID, err := uuid.Parse(roomIDStr)
+		if err != nil {
+			continue
+		}
+
+		// rooms stay active for a while after everyone left, nobody would see the announcement there
+		instances, err := s.redis.RoomInstances(ctx, roomID)
+		if err != nil {
+			logger.Errorf(err, "failed to get connections of room %s", roomID)
+			continue
+		}
+		if len(instances) == 0 {
+			continue
+		}
+
+		err = s.publishAnnouncement(ctx, roomID, adminID, req)
+		if err != nil {
+			logger.Error(err, "failed to announce to room")
+			continue
+		}
+		response.Rooms = append(response.Rooms, roomID)
+	}
+
+	logger.Infof("admin %s announced to %d room(s): %s", adminID, len(response.Rooms), req.Message)
+	return response, nil
+}
+
+// publishAnnouncement hands an announcement to service-sync, which sends it to the room's connections
+func (s *Service) publishAnnouncement(ctx context.Context, roomID, adminID uuid.UUID, req *model.AnnouncementRequest) error {
+	event := &model.SyncMessage{
+		ID:        uuid.New(),
+		RoomID:    roomID,
+		UserID:    adminID,
+		Action:    model.ActionAnnouncement,
+		Timestamp: time.Now(),
+		Data: model.SyncData{
+			Extra: map[string]interface{}{
+				"message":  req.Message,
+				"severity": string(req.Severity),
+			},
+		},
+	}
+
+	_, err := s.redis.PublishRoomEvent(ctx, roomID, event)
+	if err != nil {
+		return fmt.Errorf("failed to publish announcement to room %s: %w", roomID, err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package room
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+)
+
+// SetCoHost grants or revokes co-host for a member of the room, co-hosts control playback and admit
+// guests but cannot change settings or delete the room (host or admin only)
+func (s *Service) SetCoHost(ctx context.Context, requesterID uuid.UUID, requesterRole string, roomID, userID uuid.UUID, coHost bool) (*model.RoomAccess, error) {
+	room, err := s.roomRepo.GetRoomByID(ctx, roomID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("room not found")
+		}
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	if room.HostID != requesterID && requesterRole != model.RoleAdmin {
+		return nil, fmt.Errorf("only room host can manage co-hosts")
+	}
+
+	if userID == room.HostID {
+		return nil, fmt.Errorf("the room host cannot be a co-host")
+	}
+
+	role := model.RoomRoleMember
+	action := model.AuditActionCoHostRevoked
+	if coHost {
+		role = model.RoomRoleCoHost
+		action = model.AuditActionCoHostGranted
+	}
+
+	updated, err := s.roomRepo.UpdateRoomAccessRole(ctx, userID, roomID, role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update room access: %w", err)
+	}
+	if !updated {
+		return nil, fmt.Errorf("co-host must be a member of the room")
+	}
+
+	s.recordCoHostChange(ctx, roomID, requesterID, action, userID)
+	s.cacheRoomCoHosts(ctx, roomID)
+	s.publishRoleChanged(ctx, roomID, userID, role, requesterID)
+
+	logger.Infof("user %s is now %s of room %s, changed by %s", userID, role, roomID, requesterID)
+
+	access, err := s.roomRepo.GetUserRoomAccess(ctx, userID, roomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room access: %w", err)
+	}
+	return access, nil
+}
+
+// checkRoomModerator checks that a user may admit people to a room: its host, a co-host or an admin
+func (s *Service) checkRoomModerator(ctx context.Context, roomID, userID uuid.UUID, isAdmin bool) (bool, error) {
+	if isAdmin {
+		return true, nil
+	}
+
+	room, err := s.roomRepo.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get room: %w", err)
+	}
+	if room.HostID == userID {
+		return true, nil
+	}
+
+	isCoHost, err := s.roomRepo.IsRoomCoHost(ctx, userID, roomID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check co-host: %w", err)
+	}
+	return isCoHost, nil
+}
+
+// cacheRoomCoHosts copies the co-hosts of a room from the database to Redis so service-sync can let them
+// control playback. the whole set is replaced so rooms whose co-hosts were granted before it was cached are
+// backfilled the next time they are opened
+func (s *Service) cacheRoomCoHosts(ctx context.Context, roomID uuid.UUID) {
+	if s.redis == nil {
+		return
+	}
+
+	coHostIDs, err := s.roomRepo.GetRoomCoHostIDs(ctx, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to get co-hosts of room %s", roomID)
+		return
+	}
+
+	members := make([]interface{}, len(coHostIDs))
+	for i, id := range coHostIDs {
+		members[i] = id.String()
+	}
+
+	err = s.redis.ReplaceSet(ctx, redis.RoomCoHostsKey(roomID), roomCacheTTL, members...)
+	if err != nil {
+		logger.Errorf(err, "failed to cache co-hosts for room %s", roomID)
+	}
+}
+
+// publishRoleChanged notifies service-sync instances so participants see the new role
+func (s *Service) publishRoleChanged(ctx context.Context, roomID, userID uuid.UUID, role string, changedBy uuid.UUID) {
+	if s.redis == nil {
+		logger.Warnf("redis not configured, role change in room %s not broadcast", roomID)
+		return
+	}
+
+	event := &model.SyncMessage{
+		ID:        uuid.New(),
+		RoomID:    roomID,
+		UserID:    changedBy,
+		Action:    model.ActionRoleChanged,
+		Timestamp: time.Now(),
+		Data: model.SyncData{
+			Extra: map[string]interface{}{
+				"user_id": userID.String(),
+				"role":    role,
+			},
+		},
+	}
+
+	_, err := s.redis.PublishRoomEvent(ctx, roomID, event)
+	if err != nil {
+		logger.Errorf(err, "failed to publish role change for room %s", roomID)
+	}
+}
+
+// recordCoHostChange writes a co-host grant or revocation to the room audit log
+func (s *Service) recordCoHostChange(ctx context.Context, roomID, actorID uuid.UUID, action string, userID uuid.UUID) {
+	details, err := json.Marshal(map[string]string{"user_id": userID.String()})
+	if err != nil {
+		logger.Errorf(err, "failed to marshal audit details for room %s", roomID)
+		return
+	}
+
+	err = s.roomRepo.CreateRoomAuditEntry(ctx, &model.RoomAuditEntry{
+		ID:        uuid.New(),
+		RoomID:    roomID,
+		ActorID:   &actorID,
+		Action:    action,
+		Details:   details,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		logger.Errorf(err, "failed to record %s for room %s", action, roomID)
+	}
+}
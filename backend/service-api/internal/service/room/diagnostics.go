@@ -0,0 +1,119 @@
+package room
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+)
+
+// GetRoomDiagnostics collects what service-sync stores in Redis about a room
+func (s *Service) GetRoomDiagnostics(ctx context.Context, roomID uuid.UUID) (*model.RoomDiagnostics, error) {
+	if s.redis == nil {
+		return nil, fmt.Errorf("redis not configured")
+	}
+
+	_, err := s.roomRepo.GetRoomByID(ctx, roomID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("room not found")
+		}
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	diagnostics := &model.RoomDiagnostics{
+		RoomID:       roomID,
+		Instances:    map[string]int{},
+		RecentErrors: map[string]int{},
+		GeneratedAt:  time.Now(),
+	}
+
+	instances, err := s.redis.RoomInstances(ctx, roomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room connections: %w", err)
+	}
+	for instanceID, count := range instances {
+		diagnostics.Instances[instanceID] = count
+		diagnostics.ConnectionCount += count
+	}
+
+	diagnostics.State, err = s.redis.HGetAll(ctx, redis.RoomStateKey(roomID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room state: %w", err)
+	}
+
+	diagnostics.Participants, err = s.GetLiveParticipants(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	var hostID string
+	if err := s.redis.Get(ctx, redis.RoomHostKey(roomID), &hostID); err == nil {
+		diagnostics.HostID = hostID
+	}
+
+	diagnostics.LockHolder, err = s.redis.GetString(ctx, redis.RoomLockKey(roomID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room lock: %w", err)
+	}
+	if diagnostics.LockHolder != "" {
+		ttl, err := s.redis.TTL(ctx, redis.RoomLockKey(roomID))
+		if err == nil && ttl > 0 {
+			diagnostics.LockTTLMillis = ttl.Milliseconds()
+		}
+	}
+
+	recentErrors, err := s.redis.HGetAll(ctx, redis.RoomErrorsKey(roomID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room errors: %w", err)
+	}
+	for code, value := range recentErrors {
+		count, _ := strconv.Atoi(value)
+		diagnostics.RecentErrors[code] = count
+	}
+
+	return diagnostics, nil
+}
+
+// ResyncRoom asks the sync instances holding a room's connections to snap every participant to the stored state
+func (s *Service) ResyncRoom(ctx context.Context, roomID, adminID uuid.UUID) (*model.RoomResyncResponse, error) {
+	if s.redis == nil {
+		return nil, fmt.Errorf("redis not configured")
+	}
+
+	_, err := s.roomRepo.GetRoomByID(ctx, roomID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("room not found")
+		}
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	instances, err := s.redis.PublishToRoomInstances(ctx, roomID, &model.InstanceControlMessage{
+		ID:       uuid.New(),
+		RoomID:   roomID,
+		Action:   model.ActionForceResync,
+		IssuedBy: adminID,
+		IssuedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to route resync: %w", err)
+	}
+
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("room has no active connections")
+	}
+
+	logger.Infof("admin %s requested resync of room %s on %d sync instance(s)", adminID, roomID, len(instances))
+
+	return &model.RoomResyncResponse{
+		RoomID:    roomID,
+		Instances: instances,
+	}, nil
+}
@@ -0,0 +1,55 @@
+package room
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoomEventsAreNumbered(t *testing.T) {
+	logger.InitLogger(&config.Config{})
+	server := miniredis.RunT(t)
+	client, err := redis.NewClient(&config.Config{Redis: config.RedisConfig{Host: server.Host(), Port: server.Port()}})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	roomID, hostID, newHostID := uuid.New(), uuid.New(), uuid.New()
+	s := &Service{redis: client}
+
+	pubsub := client.Subscribe(ctx, redis.RoomEventsChannel(roomID))
+	defer pubsub.Close()
+	_, err = pubsub.Receive(ctx)
+	require.NoError(t, err)
+
+	// service-sync already numbered a few events of the room, ours continue its sequence
+	server.Set(redis.RoomEventSeqKey(roomID), "4")
+
+	s.publishHostChanged(ctx, roomID, hostID, newHostID, hostID)
+	s.publishSettingsChanged(ctx, roomID, newHostID)
+
+	for _, want := range []struct {
+		seq    int64
+		action model.SyncAction
+	}{
+		{seq: 5, action: model.ActionHostChanged},
+		{seq: 6, action: model.ActionSettingsChanged},
+	} {
+		msg, err := pubsub.ReceiveMessage(ctx)
+		require.NoError(t, err)
+		var event model.SyncMessage
+		require.NoError(t, json.Unmarshal([]byte(msg.Payload), &event))
+		assert.Equal(t, want.seq, event.Seq)
+		assert.Equal(t, want.action, event.Action)
+	}
+}
@@ -0,0 +1,74 @@
+package room
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+)
+
+// publishGuestRequestEvent announces a new or reviewed guest request on the room's guest-requests channel
+func (s *Service) publishGuestRequestEvent(ctx context.Context, requestID, roomID uuid.UUID, guestName, status string) {
+	if s.redis == nil {
+		return
+	}
+
+	err := s.redis.Publish(ctx, redis.GuestRequestsChannel(roomID), &model.GuestRequestEvent{
+		RequestID: requestID,
+		RoomID:    roomID,
+		GuestName: guestName,
+		Status:    status,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		logger.Errorf(err, "failed to publish guest request %s event for room %s", status, roomID)
+	}
+}
+
+// WatchGuestRequest subscribes to the review of a guest request. the returned channel receives a value
+// whenever the request changes and is closed when the subscription ends, stop must be called once done.
+// the subscription is active on return, a review right after it is not missed.
+func (s *Service) WatchGuestRequest(ctx context.Context, requestID uuid.UUID) (<-chan struct{}, func(), error) {
+	if s.redis == nil {
+		return nil, nil, fmt.Errorf("real-time updates unavailable")
+	}
+
+	request, err := s.roomRepo.GetGuestRequestByID(ctx, requestID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, fmt.Errorf("request not found")
+		}
+		return nil, nil, fmt.Errorf("failed to get guest request: %w", err)
+	}
+
+	pubsub := s.redis.Subscribe(ctx, redis.GuestRequestsChannel(request.RoomID))
+	_, err = pubsub.Receive(ctx)
+	if err != nil {
+		pubsub.Close()
+		return nil, nil, fmt.Errorf("failed to subscribe to guest requests: %w", err)
+	}
+
+	changes := make(chan struct{}, 1)
+	go func() {
+		defer close(changes)
+		for msg := range pubsub.Channel() {
+			var event model.GuestRequestEvent
+			if json.Unmarshal([]byte(msg.Payload), &event) != nil || event.RequestID != requestID {
+				continue
+			}
+			// the watcher re-reads the request, one pending signal is enough
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return changes, func() { pubsub.Close() }, nil
+}
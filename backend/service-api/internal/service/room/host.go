@@ -0,0 +1,204 @@
+package room
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+)
+
+// roomCacheTTL bounds how long the host, settings, movie and co-hosts cached for service-sync outlive the last time
+// the room was opened, they are cached again whenever it is, so rooms nobody opens anymore do not pile up in Redis
+const roomCacheTTL = 24 * time.Hour
+
+// TransferHost reassigns the room host to another member with granted access
+func (s *Service) TransferHost(ctx context.Context, requesterID uuid.UUID, requesterRole string, roomID uuid.UUID, req *model.TransferHostRequest) (*model.TransferHostResponse, error) {
+	room, err := s.roomRepo.GetRoomByID(ctx, roomID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("room not found")
+		}
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	// only the current host or an admin may hand over control
+	if room.HostID != requesterID && requesterRole != model.RoleAdmin {
+		return nil, fmt.Errorf("only room host can transfer host")
+	}
+
+	if req.NewHostID == room.HostID {
+		return nil, fmt.Errorf("user is already the room host")
+	}
+
+	// the new host must be a current member of the room
+	access, err := s.roomRepo.GetUserRoomAccess(ctx, req.NewHostID, roomID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to check room access: %w", err)
+	}
+	if access == nil || access.Status != model.StatusGranted {
+		return nil, fmt.Errorf("new host must be a member of the room")
+	}
+
+	err = s.roomRepo.UpdateRoomHost(ctx, roomID, req.NewHostID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update room host: %w", err)
+	}
+
+	previousHostID := room.HostID
+	room.HostID = req.NewHostID
+
+	s.recordHostChange(ctx, roomID, &requesterID, model.AuditActionHostTransferred, previousHostID, req.NewHostID)
+	s.cacheRoomHost(ctx, roomID, req.NewHostID)
+	s.publishHostChanged(ctx, roomID, previousHostID, req.NewHostID, requesterID)
+
+	logger.Infof("room %s host transferred from %s to %s by %s", roomID, previousHostID, req.NewHostID, requesterID)
+
+	return &model.TransferHostResponse{
+		Room:           *room,
+		PreviousHostID: previousHostID,
+		Message:        "Room host transferred successfully",
+	}, nil
+}
+
+// cacheRoomHost stores the current host in Redis so service-sync can flag participants
+func (s *Service) cacheRoomHost(ctx context.Context, roomID, hostID uuid.UUID) {
+	if s.redis == nil {
+		return
+	}
+
+	err := s.redis.Set(ctx, redis.RoomHostKey(roomID), hostID.String(), roomCacheTTL)
+	if err != nil {
+		logger.Errorf(err, "failed to cache host for room %s", roomID)
+	}
+}
+
+// cacheRoomMovie stores which movie a room plays so service-sync can look up its segment timeline, and the
+// qualities it was published with so the host can only pick one of them as the room's default
+func (s *Service) cacheRoomMovie(ctx context.Context, roomID uuid.UUID, movie *model.Movie) {
+	if s.redis == nil {
+		return
+	}
+
+	err := s.redis.Set(ctx, redis.RoomMovieKey(roomID), movie.ID.String(), roomCacheTTL)
+	if err != nil {
+		logger.Errorf(err, "failed to cache movie for room %s", roomID)
+	}
+
+	// a movie still processing has no renditions yet, they are cached the next time the room is opened
+	if movie.Status != model.StatusAvailable {
+		err = s.redis.Delete(ctx, redis.RoomMovieQualitiesKey(roomID))
+	} else {
+		err = s.redis.Set(ctx, redis.RoomMovieQualitiesKey(roomID), movie.Qualities(), roomCacheTTL)
+	}
+	if err != nil {
+		logger.Errorf(err, "failed to cache movie qualities for room %s", roomID)
+	}
+}
+
+// publishHostChanged notifies service-sync instances about the new host
+func (s *Service) publishHostChanged(ctx context.Context, roomID, previousHostID, newHostID, changedBy uuid.UUID) {
+	if s.redis == nil {
+		logger.Warnf("redis not configured, host change for room %s not broadcast", roomID)
+		return
+	}
+
+	event := &model.SyncMessage{
+		ID:        uuid.New(),
+		RoomID:    roomID,
+		UserID:    changedBy,
+		Action:    model.ActionHostChanged,
+		Timestamp: time.Now(),
+		Data: model.SyncData{
+			Extra: map[string]interface{}{
+				"previous_host_id": previousHostID.String(),
+				"new_host_id":      newHostID.String(),
+				"reason":           model.HostChangeReasonTransfer,
+			},
+		},
+	}
+
+	_, err := s.redis.PublishRoomEvent(ctx, roomID, event)
+	if err != nil {
+		logger.Errorf(err, "failed to publish host change for room %s", roomID)
+	}
+}
+
+// ListenForHostHandoffs persists automatic host handoffs reported by service-sync until ctx is done
+func (s *Service) ListenForHostHandoffs(ctx context.Context) {
+	if s.redis == nil {
+		return
+	}
+
+	pubsub := s.redis.Subscribe(ctx, redis.RoomHostHandoffChannel)
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			var handoff model.HostChangedMessage
+			if err := json.Unmarshal([]byte(msg.Payload), &handoff); err != nil {
+				logger.Errorf(err, "failed to unmarshal host handoff message")
+				continue
+			}
+
+			if err := s.applyHostHandoff(ctx, &handoff); err != nil {
+				logger.Errorf(err, "failed to apply host handoff for room %s", handoff.RoomID)
+			}
+		}
+	}
+}
+
+// applyHostHandoff stores a host handoff decided by service-sync
+func (s *Service) applyHostHandoff(ctx context.Context, handoff *model.HostChangedMessage) error {
+	// every API instance receives the handoff and a manual transfer may have happened in the meantime,
+	// so only apply it while the previous host is still recorded
+	replaced, err := s.roomRepo.ReplaceRoomHost(ctx, handoff.RoomID, handoff.PreviousHostID, handoff.NewHostID)
+	if err != nil {
+		return fmt.Errorf("failed to update room host: %w", err)
+	}
+	if !replaced {
+		return nil
+	}
+
+	s.recordHostChange(ctx, handoff.RoomID, nil, model.AuditActionHostAutoHandoff, handoff.PreviousHostID, handoff.NewHostID)
+
+	logger.Infof("room %s host automatically handed off from %s to %s", handoff.RoomID, handoff.PreviousHostID, handoff.NewHostID)
+	return nil
+}
+
+// recordHostChange writes a host change to the room audit log
+func (s *Service) recordHostChange(ctx context.Context, roomID uuid.UUID, actorID *uuid.UUID, action string, previousHostID, newHostID uuid.UUID) {
+	details, err := json.Marshal(map[string]string{
+		"previous_host_id": previousHostID.String(),
+		"new_host_id":      newHostID.String(),
+	})
+	if err != nil {
+		logger.Errorf(err, "failed to marshal audit details for room %s", roomID)
+		return
+	}
+
+	err = s.roomRepo.CreateRoomAuditEntry(ctx, &model.RoomAuditEntry{
+		ID:        uuid.New(),
+		RoomID:    roomID,
+		ActorID:   actorID,
+		Action:    action,
+		Details:   details,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		logger.Errorf(err, "failed to record %s for room %s", action, roomID)
+	}
+}
@@ -0,0 +1,140 @@
+package room
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+)
+
+// batchInviteConcurrency bounds how many invitations of a batch are handled at the same time
+const batchInviteConcurrency = 5
+
+// BatchInviteUsers invites several people to a room, granting access to those with an account and emailing the
+// room link to everyone, reporting the outcome per email. people who can already join are not emailed again
+func (s *Service) BatchInviteUsers(ctx context.Context, inviterID, roomID uuid.UUID, req *model.BatchInviteRequest) (*model.BatchInviteResponse, error) {
+	emails := uniqueEmails(req.Emails)
+	if len(emails) > model.MaxBatchInviteEmails {
+		return nil, fmt.Errorf("at most %d emails can be invited at once", model.MaxBatchInviteEmails)
+	}
+
+	isHost, err := s.roomRepo.IsRoomHost(ctx, inviterID, roomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check room host: %w", err)
+	}
+	if !isHost {
+		return nil, fmt.Errorf("only room host can send invitations")
+	}
+
+	room, err := s.roomRepo.GetRoomWithDetails(ctx, roomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room details: %w", err)
+	}
+
+	inviter, err := s.userRepo.GetByID(inviterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inviter details: %w", err)
+	}
+
+	results := make([]model.BatchInviteResult, len(emails))
+	semaphore := make(chan struct{}, batchInviteConcurrency)
+	var wg sync.WaitGroup
+
+	for i, email := range emails {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, email string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			status, err := s.inviteForBatch(ctx, inviter, room, email, req.Message)
+			results[i] = model.BatchInviteResult{Email: email, Status: status}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+		}(i, email)
+	}
+	wg.Wait()
+
+	response := &model.BatchInviteResponse{Results: results}
+	for _, result := range results {
+		switch result.Status {
+		case model.InviteStatusInvited:
+			response.Invited++
+		case model.InviteStatusAlreadyHasAccess:
+			response.AlreadyHasAccess++
+		default:
+			response.Failed++
+		}
+	}
+
+	logger.Infof("batch invite to room %s invited %d, %d already had access, %d failed",
+		roomID, response.Invited, response.AlreadyHasAccess, response.Failed)
+	return response, nil
+}
+
+// inviteForBatch invites one email of a batch invite the way InviteUser does
+func (s *Service) inviteForBatch(ctx context.Context, inviter *model.User, room *model.RoomWithDetails, email, message string) (string, error) {
+	// people without an account yet only get the room link, they join once registered. a failed lookup fails the
+	// invite rather than emailing someone whose access was never granted
+	invitedUser, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		logger.Errorf(err, "failed to look up invited user for room %s", room.ID)
+		return model.InviteStatusFailed, fmt.Errorf("failed to look up user")
+	}
+	if invitedUser != nil {
+		if invitedUser.ID == room.HostID {
+			return model.InviteStatusAlreadyHasAccess, nil
+		}
+
+		hasAccess, err := s.roomRepo.CheckRoomAccess(ctx, invitedUser.ID, room.ID)
+		if err != nil {
+			return model.InviteStatusFailed, fmt.Errorf("failed to check room access")
+		}
+		if hasAccess {
+			return model.InviteStatusAlreadyHasAccess, nil
+		}
+
+		err = s.roomRepo.GrantRoomAccess(ctx, &model.RoomAccess{
+			UserID:     invitedUser.ID,
+			RoomID:     room.ID,
+			AccessType: model.AccessTypeGranted,
+			Status:     model.StatusGranted,
+			GrantedAt:  time.Now(),
+		})
+		if err != nil {
+			logger.Errorf(err, "failed to grant access to room %s for batch invite", room.ID)
+			return model.InviteStatusFailed, fmt.Errorf("failed to grant room access")
+		}
+	}
+
+	// the queue retries failed deliveries, only failing to queue the email fails the invite
+	err = s.sendInvitationEmailWithRoomLink(ctx, &model.InviteUserRequest{Email: email, Message: message}, inviter, room)
+	if err != nil {
+		logger.Errorf(err, "failed to queue invitation email for room %s", room.ID)
+		return model.InviteStatusFailed, fmt.Errorf("failed to send invitation email")
+	}
+
+	return model.InviteStatusInvited, nil
+}
+
+// uniqueEmails drops repeated emails regardless of case, keeping the first spelling in the order given
+func uniqueEmails(emails []string) []string {
+	seen := make(map[string]bool, len(emails))
+	unique := make([]string, 0, len(emails))
+	for _, email := range emails {
+		email = strings.TrimSpace(email)
+		key := strings.ToLower(email)
+		if email == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, email)
+	}
+	return unique
+}
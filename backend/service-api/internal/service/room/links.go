@@ -0,0 +1,70 @@
+package room
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+)
+
+// normalizeLinkBaseURL reduces a frontend base URL to its origin, rejecting anything beyond scheme and host
+// so a stored value cannot smuggle a path, query or credentials into emailed links
+func normalizeLinkBaseURL(raw string) (string, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", false
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", false
+	}
+	if parsed.Host == "" || parsed.User != nil || parsed.Opaque != "" {
+		return "", false
+	}
+	if (parsed.Path != "" && parsed.Path != "/") || parsed.RawQuery != "" || parsed.Fragment != "" || parsed.ForceQuery {
+		return "", false
+	}
+
+	return parsed.Scheme + "://" + strings.ToLower(parsed.Host), true
+}
+
+// isAllowedLinkBaseURL reports whether a room may use an origin for its emailed links
+func (s *Service) isAllowedLinkBaseURL(origin string) bool {
+	for _, allowed := range s.config.Email.Templates.AllowedBaseURLs {
+		if normalized, ok := normalizeLinkBaseURL(allowed); ok && normalized == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// validateLinkBaseURL normalizes a room's link base URL and checks it against the configured origins
+func (s *Service) validateLinkBaseURL(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+
+	origin, ok := normalizeLinkBaseURL(raw)
+	if !ok {
+		return "", fmt.Errorf("invalid room settings: link_base_url must be an http or https origin without a path")
+	}
+	if !s.isAllowedLinkBaseURL(origin) {
+		return "", fmt.Errorf("invalid room settings: link_base_url %s is not an allowed origin", origin)
+	}
+	return origin, nil
+}
+
+// linkBaseURL returns the frontend base URL for a room's emailed links, falling back to the configured one.
+// the allowlist is checked again since it may have changed after the room stored its origin.
+func (s *Service) linkBaseURL(settings model.RoomSettings) string {
+	if settings.LinkBaseURL == "" {
+		return s.config.Email.Templates.BaseURL
+	}
+
+	origin, ok := normalizeLinkBaseURL(settings.LinkBaseURL)
+	if !ok || !s.isAllowedLinkBaseURL(origin) {
+		logger.Warnf("link base url %q of room is no longer allowed, using the configured one", settings.LinkBaseURL)
+		return s.config.Email.Templates.BaseURL
+	}
+	return origin
+}
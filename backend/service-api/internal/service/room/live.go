@@ -0,0 +1,56 @@
+package room
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+)
+
+// GetLiveRoomState returns the playback state service-sync keeps for a room, nil when nobody has played it yet
+func (s *Service) GetLiveRoomState(ctx context.Context, roomID uuid.UUID) (*model.RoomState, error) {
+	if s.redis == nil {
+		return nil, nil
+	}
+
+	data, err := s.redis.HGetAll(ctx, redis.RoomStateKey(roomID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room state: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	return model.ParseRoomState(roomID, data)
+}
+
+// GetLiveParticipants returns the participants service-sync tracks in a room, in the order they joined
+func (s *Service) GetLiveParticipants(ctx context.Context, roomID uuid.UUID) ([]model.ParticipantInfo, error) {
+	participants := []model.ParticipantInfo{}
+	if s.redis == nil {
+		return participants, nil
+	}
+
+	entries, err := s.redis.HGetAll(ctx, redis.RoomParticipantsKey(roomID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room participants: %w", err)
+	}
+	for userID, value := range entries {
+		var participant model.ParticipantInfo
+		if err := json.Unmarshal([]byte(value), &participant); err != nil {
+			logger.Warnf("invalid participant entry for user %s in room %s", userID, roomID)
+			continue
+		}
+		participants = append(participants, participant)
+	}
+	sort.Slice(participants, func(i, j int) bool {
+		return participants[i].JoinedAt.Before(participants[j].JoinedAt)
+	})
+
+	return participants, nil
+}
@@ -0,0 +1,131 @@
+package room
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+)
+
+// positionPersistBatch bounds how many of the most recently active rooms a sweep saves the position of
+const positionPersistBatch = 1000
+
+// roomStateTTL matches how long service-sync keeps the playback state of a room
+const roomStateTTL = 24 * time.Hour
+
+// RunPositionPersister periodically saves the playback position of rooms being watched until ctx is done
+func (s *Service) RunPositionPersister(ctx context.Context) {
+	interval := s.config.Sync.PositionPersistInterval.ToDuration()
+	if interval <= 0 || s.redis == nil {
+		logger.Info("room position persister disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := s.PersistRoomPositions(ctx)
+			if err != nil {
+				logger.Error(err, "failed to persist room positions")
+			}
+		}
+	}
+}
+
+// PersistRoomPositions saves the position of the active rooms somebody is connected to, so the room can resume
+// there once its live state expired. rooms nobody watches keep the position they were left at
+func (s *Service) PersistRoomPositions(ctx context.Context) (int, error) {
+	members, err := s.redis.ZRevRange(ctx, redis.ActiveRoomsKey(), 0, positionPersistBatch-1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get active rooms: %w", err)
+	}
+
+	roomIDs := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		if roomID, err := uuid.Parse(member); err == nil {
+			roomIDs = append(roomIDs, roomID)
+		}
+	}
+	if len(roomIDs) == 0 {
+		return 0, nil
+	}
+
+	snapshots, err := s.redis.RoomLiveSnapshots(ctx, roomIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read live state of active rooms: %w", err)
+	}
+
+	now := time.Now()
+	persisted := 0
+	for i, snapshot := range snapshots {
+		if snapshot.ParticipantCount == 0 || len(snapshot.State) == 0 {
+			continue
+		}
+
+		state, err := model.ParseRoomState(roomIDs[i], snapshot.State)
+		if err != nil {
+			logger.Warnf("invalid live state for room %s: %v", roomIDs[i], err)
+			continue
+		}
+
+		err = s.roomRepo.UpdateLastPosition(ctx, roomIDs[i], state.ProjectedTime(now), now)
+		if err != nil {
+			logger.Errorf(err, "failed to persist position of room %s", roomIDs[i])
+			continue
+		}
+		persisted++
+	}
+
+	return persisted, nil
+}
+
+// resumeRoomState seeds the playback state of a room whose live state expired with its persisted position, paused,
+// so the first participant to join picks up where the group left off. a state service-sync holds is never replaced
+func (s *Service) resumeRoomState(ctx context.Context, room *model.RoomWithDetails) {
+	if s.redis == nil {
+		return
+	}
+
+	stateKey := redis.RoomStateKey(room.ID)
+	exists, err := s.redis.Exists(ctx, stateKey)
+	if err != nil || exists > 0 {
+		return
+	}
+
+	position, err := s.roomRepo.GetLastPosition(ctx, room.ID)
+	if err != nil {
+		logger.Errorf(err, "failed to get last position of room %s", room.ID)
+		return
+	}
+	if position <= 0 {
+		return
+	}
+
+	seeded, err := s.redis.SeedHash(ctx, stateKey, roomStateTTL,
+		"room_id", room.ID.String(),
+		"is_playing", "false",
+		"current_time", fmt.Sprintf("%.2f", position),
+		"duration", fmt.Sprintf("%.2f", float64(room.Movie.DurationSeconds)),
+		"playback_rate", "1.00",
+		"last_updated", strconv.FormatInt(time.Now().Unix(), 10),
+		"updated_by", uuid.Nil.String(),
+		"default_quality", "",
+	)
+	if err != nil {
+		logger.Errorf(err, "failed to resume state of room %s", room.ID)
+		return
+	}
+	if seeded {
+		logger.Infof("resumed room %s at its last position %.2fs", room.ID, position)
+	}
+}
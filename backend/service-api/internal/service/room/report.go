@@ -0,0 +1,136 @@
+package room
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+)
+
+// ReportParticipant files an abuse report against a participant of a room, capturing the recent chat
+func (s *Service) ReportParticipant(ctx context.Context, reporterID, roomID uuid.UUID, req *model.CreateReportRequest) (*model.CreateReportResponse, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+	if len(reason) > model.MaxReportReasonLength {
+		return nil, fmt.Errorf("reason must be at most %d characters", model.MaxReportReasonLength)
+	}
+	if req.TargetUserID == reporterID {
+		return nil, fmt.Errorf("cannot report yourself")
+	}
+
+	_, err := s.roomRepo.GetRoomByID(ctx, roomID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("room not found")
+		}
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	hasAccess, err := s.roomRepo.CheckRoomAccess(ctx, reporterID, roomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check room access: %w", err)
+	}
+	if !hasAccess {
+		return nil, fmt.Errorf("access denied")
+	}
+
+	report := &model.Report{
+		ID:             uuid.New(),
+		RoomID:         roomID,
+		ReporterID:     reporterID,
+		TargetUserID:   req.TargetUserID,
+		TargetUsername: strings.TrimSpace(req.TargetUsername),
+		Reason:         reason,
+		ChatSnapshot:   s.getRecentChat(ctx, roomID),
+		Status:         model.ReportStatusOpen,
+		CreatedAt:      time.Now(),
+	}
+
+	err = s.roomRepo.CreateReport(ctx, report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report: %w", err)
+	}
+
+	logger.Infof("user %s reported %s in room %s", reporterID, req.TargetUserID, roomID)
+
+	return &model.CreateReportResponse{
+		Report:  *report,
+		Message: "Report submitted successfully",
+	}, nil
+}
+
+// GetReports lists abuse reports for moderators, optionally filtered by status
+func (s *Service) GetReports(ctx context.Context, status string, page, pageSize int) (*model.ReportListResponse, error) {
+	switch status {
+	case "", model.ReportStatusOpen, model.ReportStatusReviewed, model.ReportStatusDismissed:
+	default:
+		return nil, fmt.Errorf("invalid report status")
+	}
+
+	page, pageSize = model.NormalizePagination(page, pageSize)
+
+	offset := (page - 1) * pageSize
+	reports, totalCount, err := s.roomRepo.GetReports(ctx, status, pageSize, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reports: %w", err)
+	}
+
+	return model.NewPage(reports, totalCount, page, pageSize), nil
+}
+
+// getRecentChat reads the chat buffer service-sync keeps for a room, oldest message first
+func (s *Service) getRecentChat(ctx context.Context, roomID uuid.UUID) model.ChatSnapshot {
+	snapshot := model.ChatSnapshot{}
+	if s.redis == nil {
+		return snapshot
+	}
+
+	entries, err := s.redis.ListRange(ctx, redis.RoomRecentChatKey(roomID), 0, -1)
+	if err != nil {
+		logger.Errorf(err, "failed to read recent chat for room %s", roomID)
+		return snapshot
+	}
+
+	// the buffer is newest first
+	for i := len(entries) - 1; i >= 0; i-- {
+		var entry model.ChatLogEntry
+		if err := json.Unmarshal([]byte(entries[i]), &entry); err != nil {
+			continue
+		}
+		snapshot = append(snapshot, entry)
+	}
+
+	return snapshot
+}
+
+// ClearChatHistory drops the recent chat service-sync keeps for a room
+func (s *Service) ClearChatHistory(ctx context.Context, roomID, adminID uuid.UUID) error {
+	if s.redis == nil {
+		return fmt.Errorf("redis not configured")
+	}
+
+	_, err := s.roomRepo.GetRoomByID(ctx, roomID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("room not found")
+		}
+		return fmt.Errorf("failed to get room: %w", err)
+	}
+
+	err = s.redis.Delete(ctx, redis.RoomRecentChatKey(roomID))
+	if err != nil {
+		return fmt.Errorf("failed to clear chat history: %w", err)
+	}
+
+	logger.Infof("admin %s cleared chat history of room %s", adminID, roomID)
+	return nil
+}
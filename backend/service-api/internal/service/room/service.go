@@ -9,7 +9,9 @@ import (
 	"time"
 	"watch-party/pkg/config"
 	"watch-party/pkg/email"
+	"watch-party/pkg/logger"
 	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
 	roomRepo "watch-party/service-api/internal/repository/room"
 	userRepo "watch-party/service-api/internal/repository/user"
 
@@ -20,22 +22,56 @@ import (
 type Service struct {
 	roomRepo     *roomRepo.Repository
 	userRepo     userRepo.Repository
-	emailService email.Provider
+	emailService email.TemplateSender
+	redis        *redis.Client // optional, nil disables real-time propagation to service-sync
 	config       *config.Config
 }
 
 // NewService creates a new room service instance.
-func NewService(roomRepo *roomRepo.Repository, userRepo userRepo.Repository, emailService email.Provider, config *config.Config) *Service {
+func NewService(roomRepo *roomRepo.Repository, userRepo userRepo.Repository, emailService email.TemplateSender, redisClient *redis.Client, config *config.Config) *Service {
 	return &Service{
 		roomRepo:     roomRepo,
 		userRepo:     userRepo,
 		emailService: emailService,
+		redis:        redisClient,
 		config:       config,
 	}
 }
 
-// CreateRoom creates a new room
-func (s *Service) CreateRoom(ctx context.Context, userID uuid.UUID, req *model.CreateRoomRequest) (*model.CreateRoomResponse, error) {
+// CreateRoom creates a new room around a movie the user can watch, admins can use any movie.
+// a movie that is still processing is accepted, the response says so
+func (s *Service) CreateRoom(ctx context.Context, userID uuid.UUID, isAdmin bool, req *model.CreateRoomRequest) (*model.CreateRoomResponse, error) {
+	movie, err := s.roomRepo.GetRoomMovie(ctx, req.MovieID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get movie: %w", err)
+	}
+
+	canAccess := isAdmin || (movie != nil && movie.UploadedBy == userID)
+	if movie != nil && !canAccess {
+		canAccess, err = s.roomRepo.CheckUserMovieAccess(ctx, userID, req.MovieID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check movie access: %w", err)
+		}
+	}
+
+	err = checkMovieForRoom(movie, canAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	// the host's choice wins over the deployment default, which startup already validated, unset means free
+	settings := model.DefaultRoomSettings()
+	if s.config.Room.DefaultControlMode != "" {
+		settings.ControlMode = s.config.Room.DefaultControlMode
+	}
+	if req.ControlMode != "" {
+		settings.ControlMode = req.ControlMode
+	}
+	err = settings.Validate()
+	if err != nil {
+		return nil, err
+	}
+
 	// create room
 	room := &model.Room{
 		ID:          uuid.New(),
@@ -43,12 +79,29 @@ func (s *Service) CreateRoom(ctx context.Context, userID uuid.UUID, req *model.C
 		HostID:      userID,
 		Name:        req.Name,
 		Description: req.Description,
+		Settings:    settings,
 		CreatedAt:   time.Now(),
 	}
 
-	err := s.roomRepo.CreateRoom(ctx, room)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create room: %w", err)
+	if s.config.Room.ReuseOpenRooms {
+		existing, created, err := s.roomRepo.CreateRoomUnlessHosted(ctx, room)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create room: %w", err)
+		}
+		if !created {
+			// the host was granted access when that room was created
+			return &model.CreateRoomResponse{
+				Room:        *existing,
+				MovieStatus: movie.Status,
+				Existing:    true,
+				Message:     "You already host a room for this movie, it was returned instead of creating another",
+			}, nil
+		}
+	} else {
+		err = s.roomRepo.CreateRoom(ctx, room)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create room: %w", err)
+		}
 	}
 
 	// grant access to the host
@@ -65,12 +118,38 @@ func (s *Service) CreateRoom(ctx context.Context, userID uuid.UUID, req *model.C
 		return nil, fmt.Errorf("failed to grant host access: %w", err)
 	}
 
+	s.cacheRoomHost(ctx, room.ID, userID)
+	s.cacheRoomSettings(ctx, room.ID, room.Settings)
+	s.cacheRoomMovie(ctx, room.ID, movie)
+	s.cacheRoomCoHosts(ctx, room.ID)
+
+	message := "Room created successfully"
+	if movie.Status != model.StatusAvailable {
+		message = "Room created, the movie is still processing and can be played once it is available"
+	}
+
 	return &model.CreateRoomResponse{
-		Room:    *room,
-		Message: "Room created successfully",
+		Room:        *room,
+		MovieStatus: movie.Status,
+		Message:     message,
 	}, nil
 }
 
+// checkMovieForRoom refuses rooms around a movie that does not exist, that the user cannot watch or that failed
+// processing. movies still processing are accepted so a room can be set up ahead of time
+func checkMovieForRoom(movie *model.Movie, canAccess bool) error {
+	if movie == nil {
+		return fmt.Errorf("movie not found")
+	}
+	if !canAccess {
+		return fmt.Errorf("access to movie denied")
+	}
+	if movie.Status == model.StatusFailed {
+		return fmt.Errorf("movie failed processing")
+	}
+	return nil
+}
+
 // GetRoom retrieves a room by ID
 func (s *Service) GetRoom(ctx context.Context, userID, roomID uuid.UUID) (*model.RoomWithDetails, error) {
 	// check if user has access to the room
@@ -89,6 +168,14 @@ func (s *Service) GetRoom(ctx context.Context, userID, roomID uuid.UUID) (*model
 		return nil, fmt.Errorf("failed to get room: %w", err)
 	}
 
+	// members open the room before connecting to service-sync, which enforces the cached host and settings and
+	// refuses chat and reactions in rooms it has none for
+	s.resumeRoomState(ctx, room)
+	s.cacheRoomHost(ctx, room.ID, room.HostID)
+	s.cacheRoomSettings(ctx, room.ID, room.Settings)
+	s.cacheRoomMovie(ctx, room.ID, &room.Movie)
+	s.cacheRoomCoHosts(ctx, room.ID)
+
 	return room, nil
 }
 
@@ -140,11 +227,11 @@ func (s *Service) InviteUser(ctx context.Context, inviterID, roomID uuid.UUID, r
 		}
 	}
 
-	// send email invitation with persistent room link
+	// queue email invitation with persistent room link, the queue retries failed deliveries
 	err = s.sendInvitationEmailWithRoomLink(ctx, req, inviter, room)
 	if err != nil {
-		// log the error but don't fail the request
-		fmt.Printf("Warning: Failed to send invitation email: %v\n", err)
+		// log the error but don't fail the request, the invitee already has access
+		logger.Errorf(err, "failed to queue invitation email for room %s", room.ID)
 	}
 
 	return &model.InviteUserResponse{
@@ -209,19 +296,20 @@ func (s *Service) JoinRoomByInvitation(ctx context.Context, userID uuid.UUID, re
 // sendInvitationEmail sends an invitation email
 func (s *Service) sendInvitationEmail(ctx context.Context, invitation *model.RoomInvitation, inviter *model.User, room *model.RoomWithDetails) error {
 	// construct invitation URL
-	inviteURL := fmt.Sprintf("%s/rooms/join?token=%s", s.config.Email.Templates.BaseURL, invitation.Token)
+	baseURL := s.linkBaseURL(room.Settings)
+	inviteURL := fmt.Sprintf("%s/rooms/join?token=%s", baseURL, invitation.Token)
 
 	// prepare template data
 	templateData := email.InvitationTemplateData{
 		TemplateData: email.TemplateData{
 			RecipientName: invitation.Email,
-			SenderName:    inviter.Email,
+			SenderName:    inviter.Name(),
 			AppName:       s.config.Email.Templates.AppName,
-			AppURL:        s.config.Email.Templates.BaseURL,
+			AppURL:        baseURL,
 		},
 		RoomID:      invitation.RoomID.String(),
 		MovieTitle:  room.Movie.Title,
-		InviterName: inviter.Email,
+		InviterName: inviter.Name(),
 		InviteURL:   inviteURL,
 		ExpiresAt:   invitation.ExpiresAt.Format("January 2, 2006 at 3:04 PM"),
 	}
@@ -267,19 +355,20 @@ func (s *Service) generateInvitationToken() (string, error) {
 // sendInvitationEmailWithRoomLink sends an invitation email with persistent room link
 func (s *Service) sendInvitationEmailWithRoomLink(ctx context.Context, req *model.InviteUserRequest, inviter *model.User, room *model.RoomWithDetails) error {
 	// construct room URL (persistent link)
-	roomURL := fmt.Sprintf("%s/rooms/join/%s", s.config.Email.Templates.BaseURL, room.ID.String())
+	baseURL := s.linkBaseURL(room.Settings)
+	roomURL := fmt.Sprintf("%s/rooms/join/%s", baseURL, room.ID.String())
 
 	// prepare template data for new persistent link format
 	templateData := email.InvitationTemplateData{
 		TemplateData: email.TemplateData{
 			RecipientName: req.Email,
-			SenderName:    inviter.Email,
+			SenderName:    inviter.Name(),
 			AppName:       s.config.Email.Templates.AppName,
-			AppURL:        s.config.Email.Templates.BaseURL,
+			AppURL:        baseURL,
 		},
 		RoomID:      room.ID.String(),
 		MovieTitle:  room.Movie.Title,
-		InviterName: inviter.Email,
+		InviterName: inviter.Name(),
 		InviteURL:   roomURL,
 		ExpiresAt:   "Never (you can join anytime!)",
 	}
@@ -293,13 +382,16 @@ func (s *Service) sendInvitationEmailWithRoomLink(ctx context.Context, req *mode
 // RequestGuestAccess allows an unauthenticated user to request access to a room
 func (s *Service) RequestGuestAccess(ctx context.Context, roomID uuid.UUID, req *model.GuestAccessRequestRequest) (*model.GuestAccessRequestResponse, error) {
 	// Verify room exists
-	_, err := s.roomRepo.GetRoomByID(ctx, roomID)
+	room, err := s.roomRepo.GetRoomByID(ctx, roomID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("room not found")
 		}
 		return nil, fmt.Errorf("failed to verify room: %w", err)
 	}
+	if !room.Settings.AllowGuestRequests {
+		return nil, fmt.Errorf("guest access requests are disabled for this room")
+	}
 
 	// Create guest access request
 	guestRequest := &model.GuestAccessRequest{
@@ -316,8 +408,8 @@ func (s *Service) RequestGuestAccess(ctx context.Context, roomID uuid.UUID, req
 		return nil, fmt.Errorf("failed to create guest access request: %w", err)
 	}
 
-	// TODO: Send real-time notification to room host via WebSocket
 	fmt.Printf("Guest access request created: %s wants to join room %s\n", req.GuestName, roomID.String())
+	s.publishGuestRequestEvent(ctx, guestRequest.ID, roomID, req.GuestName, model.GuestStatusPending)
 
 	return &model.GuestAccessRequestResponse{
 		RequestID: guestRequest.ID,
@@ -326,17 +418,30 @@ func (s *Service) RequestGuestAccess(ctx context.Context, roomID uuid.UUID, req
 	}, nil
 }
 
-// GetPendingGuestRequests retrieves pending guest requests for a room (admin only)
-func (s *Service) GetPendingGuestRequests(ctx context.Context, userID uuid.UUID, roomID uuid.UUID) ([]model.GuestAccessRequest, error) {
-	// admin access is verified at controller level, just get the requests
+// GetPendingGuestRequests retrieves pending guest requests for a room (host, co-host or admin)
+func (s *Service) GetPendingGuestRequests(ctx context.Context, userID uuid.UUID, isAdmin bool, roomID uuid.UUID) ([]model.GuestAccessRequest, error) {
+	allowed, err := s.checkRoomModerator(ctx, roomID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("access denied - only room host or co-host can view guest requests")
+	}
+
 	return s.roomRepo.GetPendingGuestRequests(ctx, roomID)
 }
 
-// ApproveGuestRequest allows an admin to approve or deny a guest access request
-func (s *Service) ApproveGuestRequest(ctx context.Context, adminID uuid.UUID, roomID uuid.UUID, requestID uuid.UUID, approved bool) (*model.ApproveGuestResponse, error) {
+// ApproveGuestRequest allows the host, a co-host or an admin to approve or deny a guest access request
+func (s *Service) ApproveGuestRequest(ctx context.Context, adminID uuid.UUID, isAdmin bool, roomID uuid.UUID, requestID uuid.UUID, approved bool) (*model.ApproveGuestResponse, error) {
 	fmt.Printf("DEBUG: ApproveGuestRequest called with adminID=%s, roomID=%s, requestID=%s, approved=%t\n", adminID, roomID, requestID, approved)
 
-	// admin access is verified at controller level
+	allowed, err := s.checkRoomModerator(ctx, roomID, adminID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("access denied - only room host or co-host can approve guest requests")
+	}
 
 	// get the guest request
 	guestRequest, err := s.roomRepo.GetGuestAccessRequest(ctx, requestID)
@@ -404,8 +509,8 @@ func (s *Service) ApproveGuestRequest(ctx context.Context, adminID uuid.UUID, ro
 		return nil, fmt.Errorf("failed to update guest request: %w", err)
 	}
 
-	// TODO: Send real-time notification to guest via WebSocket
 	fmt.Printf("Guest request %s: %s for room %s\n", status, guestRequest.GuestName, roomID.String())
+	s.publishGuestRequestEvent(ctx, requestID, roomID, guestRequest.GuestName, status)
 
 	return &model.ApproveGuestResponse{
 		RequestID:    requestID,
@@ -426,9 +531,40 @@ func (s *Service) ValidateGuestSession(ctx context.Context, token string) (*mode
 		return nil, fmt.Errorf("failed to validate guest session: %w", err)
 	}
 
+	if !session.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("guest session expired")
+	}
+
 	return session, nil
 }
 
+// GetGuestSessionInfo validates a guest session token and describes it, with the seconds left so the client
+// can refresh before the session runs out
+func (s *Service) GetGuestSessionInfo(ctx context.Context, token string, now time.Time) (*model.GuestSessionInfo, error) {
+	session, err := s.ValidateGuestSession(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	room, err := s.GetRoomForGuest(ctx, session.RoomID)
+	if err != nil {
+		if err.Error() == "room not found" {
+			return nil, fmt.Errorf("invalid or expired guest session")
+		}
+		return nil, err
+	}
+
+	return &model.GuestSessionInfo{
+		Valid:      true,
+		RoomID:     session.RoomID,
+		RoomName:   room.Name,
+		MovieTitle: room.Movie.Title,
+		GuestName:  session.GuestName,
+		ExpiresAt:  session.ExpiresAt,
+		ExpiresIn:  int64(session.ExpiresAt.Sub(now).Seconds()),
+	}, nil
+}
+
 // generateSessionToken generates a secure random token for guest sessions
 func (s *Service) generateSessionToken() (string, error) {
 	bytes := make([]byte, 32)
@@ -460,6 +596,14 @@ func (s *Service) GetRoomForGuest(ctx context.Context, roomID uuid.UUID) (*model
 		return nil, fmt.Errorf("failed to get room: %w", err)
 	}
 
+	// guests open the room before connecting to service-sync, which enforces the cached host and settings and
+	// refuses chat and reactions in rooms it has none for
+	s.resumeRoomState(ctx, room)
+	s.cacheRoomHost(ctx, room.ID, room.HostID)
+	s.cacheRoomSettings(ctx, room.ID, room.Settings)
+	s.cacheRoomMovie(ctx, room.ID, &room.Movie)
+	s.cacheRoomCoHosts(ctx, room.ID)
+
 	// return only basic info for guests
 	guestInfo := &model.RoomGuestInfo{
 		ID:          room.ID,
@@ -475,6 +619,19 @@ func (s *Service) GetRoomForGuest(ctx context.Context, roomID uuid.UUID) (*model
 	return guestInfo, nil
 }
 
+// GetRoomDetails retrieves a room with its movie and host without an access check,
+// for guests whose session was already checked against the room
+func (s *Service) GetRoomDetails(ctx context.Context, roomID uuid.UUID) (*model.RoomWithDetails, error) {
+	room, err := s.roomRepo.GetRoomWithDetails(ctx, roomID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("room not found")
+		}
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+	return room, nil
+}
+
 // GetUserRooms retrieves all rooms for a user (host or member)
 func (s *Service) GetUserRooms(ctx context.Context, userID uuid.UUID) ([]*model.RoomWithDetails, error) {
 	rooms, err := s.roomRepo.GetUserRooms(ctx, userID)
@@ -549,31 +706,27 @@ func (s *Service) RequestRoomAccess(ctx context.Context, userID uuid.UUID, roomI
 	}, nil
 }
 
-// GetPendingRoomAccessRequests retrieves pending room access requests for a room (host only)
+// GetPendingRoomAccessRequests retrieves pending room access requests for a room (host or co-host)
 func (s *Service) GetPendingRoomAccessRequests(ctx context.Context, hostID uuid.UUID, roomID uuid.UUID) ([]model.UserRoomAccessRequest, error) {
-	// verify user is the host
-	room, err := s.roomRepo.GetRoomByID(ctx, roomID)
+	allowed, err := s.checkRoomModerator(ctx, roomID, hostID, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get room: %w", err)
+		return nil, err
 	}
-
-	if room.HostID != hostID {
-		return nil, fmt.Errorf("access denied - only room host can view room access requests")
+	if !allowed {
+		return nil, fmt.Errorf("access denied - only room host or co-host can view room access requests")
 	}
 
 	return s.roomRepo.GetPendingRoomAccessRequests(ctx, roomID)
 }
 
-// ApproveRoomAccessRequest allows a host to approve or deny a room access request
+// ApproveRoomAccessRequest allows the host or a co-host to approve or deny a room access request
 func (s *Service) ApproveRoomAccessRequest(ctx context.Context, hostID uuid.UUID, roomID uuid.UUID, requestedUserID uuid.UUID, approved bool) (*model.ApproveUserAccessResponse, error) {
-	// verify user is the host
-	room, err := s.roomRepo.GetRoomByID(ctx, roomID)
+	allowed, err := s.checkRoomModerator(ctx, roomID, hostID, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get room: %w", err)
+		return nil, err
 	}
-
-	if room.HostID != hostID {
-		return nil, fmt.Errorf("access denied - only room host can approve room access requests")
+	if !allowed {
+		return nil, fmt.Errorf("access denied - only room host or co-host can approve room access requests")
 	}
 
 	// get the room access record
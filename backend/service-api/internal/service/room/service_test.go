@@ -0,0 +1,72 @@
+package room
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	userRepo "watch-party/service-api/internal/repository/user"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckMovieForRoom(t *testing.T) {
+	movie := func(status model.MovieStatus) *model.Movie {
+		return &model.Movie{ID: uuid.New(), Status: status}
+	}
+
+	tests := []struct {
+		name      string
+		movie     *model.Movie
+		canAccess bool
+		wantErr   string
+	}{
+		{name: "available", movie: movie(model.StatusAvailable), canAccess: true},
+		{name: "processing is allowed", movie: movie(model.StatusProcessing), canAccess: true},
+		{name: "transcoding is allowed", movie: movie(model.StatusTranscoding), canAccess: true},
+		{name: "missing", movie: nil, canAccess: true, wantErr: "movie not found"},
+		{name: "missing without access", movie: nil, canAccess: false, wantErr: "movie not found"},
+		{name: "forbidden", movie: movie(model.StatusAvailable), canAccess: false, wantErr: "access to movie denied"},
+		{name: "forbidden while processing", movie: movie(model.StatusProcessing), canAccess: false, wantErr: "access to movie denied"},
+		{name: "failed", movie: movie(model.StatusFailed), canAccess: true, wantErr: "movie failed processing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkMovieForRoom(tt.movie, tt.canAccess)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestUniqueEmails(t *testing.T) {
+	emails := uniqueEmails([]string{"Ana@example.com", " bo@example.com", "ana@example.com", "", "bo@example.com"})
+	assert.Equal(t, []string{"Ana@example.com", "bo@example.com"}, emails)
+}
+
+// failingUserRepo fails every lookup, as when the database is unreachable
+type failingUserRepo struct {
+	userRepo.Repository
+}
+
+func (failingUserRepo) GetByEmail(string) (*model.User, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestInviteForBatchLookupFailure(t *testing.T) {
+	logger.InitLogger(&config.Config{})
+	s := &Service{userRepo: failingUserRepo{}}
+	room := &model.RoomWithDetails{}
+
+	status, err := s.inviteForBatch(context.Background(), &model.User{}, room, "ana@example.com", "")
+	assert.Equal(t, model.InviteStatusFailed, status)
+	assert.EqualError(t, err, "failed to look up user")
+}
@@ -0,0 +1,102 @@
+package room
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+)
+
+// GetRoomSettings returns the settings of a room (host only)
+func (s *Service) GetRoomSettings(ctx context.Context, userID, roomID uuid.UUID) (*model.RoomSettings, error) {
+	room, err := s.getHostedRoom(ctx, userID, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &room.Settings, nil
+}
+
+// UpdateRoomSettings validates and stores a partial settings update, then pushes it to service-sync (host only)
+func (s *Service) UpdateRoomSettings(ctx context.Context, userID, roomID uuid.UUID, req *model.UpdateRoomSettingsRequest) (*model.RoomSettings, error) {
+	room, err := s.getHostedRoom(ctx, userID, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := req.ApplyTo(room.Settings)
+	err = settings.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	settings.LinkBaseURL, err = s.validateLinkBaseURL(settings.LinkBaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.roomRepo.UpdateRoomSettings(ctx, roomID, settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update room settings: %w", err)
+	}
+
+	s.cacheRoomSettings(ctx, roomID, settings)
+	s.publishSettingsChanged(ctx, roomID, userID)
+
+	return &settings, nil
+}
+
+// getHostedRoom loads a room and checks that userID is its host
+func (s *Service) getHostedRoom(ctx context.Context, userID, roomID uuid.UUID) (*model.Room, error) {
+	room, err := s.roomRepo.GetRoomByID(ctx, roomID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("room not found")
+		}
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	if room.HostID != userID {
+		return nil, fmt.Errorf("only room host can manage room settings")
+	}
+
+	return room, nil
+}
+
+// cacheRoomSettings stores room settings in Redis where service-sync reads them
+func (s *Service) cacheRoomSettings(ctx context.Context, roomID uuid.UUID, settings model.RoomSettings) {
+	if s.redis == nil {
+		return
+	}
+
+	err := s.redis.Set(ctx, redis.RoomSettingsKey(roomID), settings, roomCacheTTL)
+	if err != nil {
+		logger.Errorf(err, "failed to cache settings for room %s", roomID)
+	}
+}
+
+// publishSettingsChanged tells service-sync instances to push the new settings to participants
+func (s *Service) publishSettingsChanged(ctx context.Context, roomID, changedBy uuid.UUID) {
+	if s.redis == nil {
+		logger.Warnf("redis not configured, settings change for room %s not broadcast", roomID)
+		return
+	}
+
+	event := &model.SyncMessage{
+		ID:        uuid.New(),
+		RoomID:    roomID,
+		UserID:    changedBy,
+		Action:    model.ActionSettingsChanged,
+		Timestamp: time.Now(),
+	}
+
+	_, err := s.redis.PublishRoomEvent(ctx, roomID, event)
+	if err != nil {
+		logger.Errorf(err, "failed to publish settings change for room %s", roomID)
+	}
+}
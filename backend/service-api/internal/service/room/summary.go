@@ -0,0 +1,67 @@
+package room
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/video"
+
+	"github.com/google/uuid"
+)
+
+// GetUserRoomSummaries returns the home screen view of every room the user hosts or was granted, with the live
+// participant count and playback state of all of them read from Redis in one round trip.
+// rooms show as idle when Redis is unavailable rather than failing the list.
+func (s *Service) GetUserRoomSummaries(ctx context.Context, userID uuid.UUID) ([]*model.RoomSummary, error) {
+	rooms, err := s.roomRepo.GetUserRooms(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user rooms: %w", err)
+	}
+
+	posterEnabled := s.config.Storage.VideoProcessing.Poster.Enabled
+	summaries := make([]*model.RoomSummary, len(rooms))
+	roomIDs := make([]uuid.UUID, len(rooms))
+	for i, room := range rooms {
+		summaries[i] = &model.RoomSummary{
+			ID:         room.ID,
+			Name:       room.Name,
+			MovieID:    room.MovieID,
+			MovieTitle: room.Movie.Title,
+			IsHost:     room.HostID == userID,
+		}
+		if posterEnabled && room.Movie.Status == model.StatusAvailable && room.Movie.TranscodedFilePath != "" {
+			summaries[i].PosterPath = room.Movie.TranscodedFilePath + "/" + video.ImagePoster + ".jpg"
+		}
+		roomIDs[i] = room.ID
+	}
+
+	if s.redis == nil || len(rooms) == 0 {
+		return summaries, nil
+	}
+
+	snapshots, err := s.redis.RoomLiveSnapshots(ctx, roomIDs)
+	if err != nil {
+		logger.Errorf(err, "failed to read live state of rooms of user %s", userID)
+		return summaries, nil
+	}
+
+	now := time.Now()
+	for i, snapshot := range snapshots {
+		summaries[i].ParticipantCount = snapshot.ParticipantCount
+		if len(snapshot.State) == 0 {
+			continue
+		}
+
+		state, err := model.ParseRoomState(roomIDs[i], snapshot.State)
+		if err != nil {
+			logger.Warnf("invalid live state for room %s: %v", roomIDs[i], err)
+			continue
+		}
+		summaries[i].IsPlaying = state.IsPlaying
+		summaries[i].CurrentTime = state.ProjectedTime(now)
+	}
+
+	return summaries, nil
+}
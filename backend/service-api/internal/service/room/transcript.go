@@ -0,0 +1,111 @@
+package room
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+)
+
+// GetRoomTranscript returns the recent chat service-sync kept for a room to its members, the last
+// CHAT_HISTORY_MAX_MESSAGES messages. with includeAnnotations the host, a co-host or an admin also gets the room's
+// recent annotations
+func (s *Service) GetRoomTranscript(ctx context.Context, userID uuid.UUID, isAdmin bool, roomID uuid.UUID, includeAnnotations bool) (*model.RoomTranscript, error) {
+	room, err := s.roomRepo.GetRoomByID(ctx, roomID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("room not found")
+		}
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	if !isAdmin {
+		hasAccess, err := s.roomRepo.CheckRoomAccess(ctx, userID, roomID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check room access: %w", err)
+		}
+		if !hasAccess && room.HostID != userID {
+			return nil, fmt.Errorf("access denied")
+		}
+	}
+
+	if includeAnnotations {
+		allowed, err := s.checkRoomModerator(ctx, roomID, userID, isAdmin)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, fmt.Errorf("access denied - only room host or co-host can download annotations")
+		}
+	}
+
+	transcript := &model.RoomTranscript{
+		RoomID:              roomID,
+		RoomName:            room.Name,
+		IncludesAnnotations: includeAnnotations,
+		Entries:             []model.TranscriptEntry{},
+		GeneratedAt:         time.Now(),
+	}
+
+	for _, message := range s.getRecentChat(ctx, roomID) {
+		transcript.Entries = append(transcript.Entries, model.TranscriptEntry{
+			Type:     model.TranscriptEntryChat,
+			UserID:   message.UserID,
+			Username: message.Username,
+			Text:     message.Message,
+			At:       message.SentAt,
+		})
+	}
+
+	if includeAnnotations {
+		for _, annotation := range s.getRecentAnnotations(ctx, roomID) {
+			transcript.Entries = append(transcript.Entries, model.TranscriptEntry{
+				Type:      model.TranscriptEntryAnnotation,
+				UserID:    annotation.UserID,
+				Username:  annotation.Username,
+				Text:      annotation.Text,
+				VideoTime: annotation.VideoTime,
+				At:        annotation.CreatedAt,
+			})
+		}
+
+		// both buffers are oldest first on their own, interleave them
+		sort.SliceStable(transcript.Entries, func(i, j int) bool {
+			return transcript.Entries[i].At.Before(transcript.Entries[j].At)
+		})
+	}
+
+	return transcript, nil
+}
+
+// getRecentAnnotations reads the annotations service-sync keeps for a room, oldest first
+func (s *Service) getRecentAnnotations(ctx context.Context, roomID uuid.UUID) []model.AnnotationMessage {
+	if s.redis == nil {
+		return nil
+	}
+
+	entries, err := s.redis.ListRange(ctx, redis.RoomAnnotationsKey(roomID), 0, -1)
+	if err != nil {
+		logger.Errorf(err, "failed to read recent annotations for room %s", roomID)
+		return nil
+	}
+
+	// the buffer is newest first
+	annotations := make([]model.AnnotationMessage, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		var annotation model.AnnotationMessage
+		if err := json.Unmarshal([]byte(entries[i]), &annotation); err != nil {
+			continue
+		}
+		annotations = append(annotations, annotation)
+	}
+
+	return annotations
+}
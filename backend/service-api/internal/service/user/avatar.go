@@ -0,0 +1,201 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/storage"
+	"watch-party/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+var ErrAvatarNotFound = errors.New("avatar not found")
+
+const (
+	// maxAvatarFileSize is the largest avatar upload accepted
+	maxAvatarFileSize = 5 * 1024 * 1024
+	// maxAvatarSourceDimension rejects images whose decoded size would exhaust memory
+	maxAvatarSourceDimension = 8000
+	// avatarDimension is the longest side avatars are stored at
+	avatarDimension = 256
+	// avatarURLExpiry is how long the storage URL an avatar request redirects to stays valid
+	avatarURLExpiry = time.Hour
+)
+
+// allowed avatar content types, detected from the file content rather than trusted from the client
+var avatarContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// avatarStoragePath returns where the uploaded avatar of a user is stored
+func avatarStoragePath(userID uuid.UUID) string {
+	return "avatars/" + userID.String()
+}
+
+// avatarServingPath returns the API path that redirects to a user's uploaded avatar.
+// the version changes on every upload so clients and CDNs pick up the new image.
+func avatarServingPath(userID uuid.UUID, version int64) string {
+	return fmt.Sprintf("/api/v1/users/%s/avatar?v=%d", userID.String(), version)
+}
+
+// isUploadedAvatar reports whether avatarURL points at the avatar uploaded by the user
+func isUploadedAvatar(userID uuid.UUID, avatarURL string) bool {
+	return strings.HasPrefix(avatarURL, fmt.Sprintf("/api/v1/users/%s/avatar", userID.String()))
+}
+
+// UploadAvatar validates, resizes and stores an avatar image, then points the profile at it
+func (s *userService) UploadAvatar(ctx context.Context, userID uuid.UUID, file *multipart.FileHeader) (*model.User, error) {
+	if s.storageProvider == nil {
+		return nil, fmt.Errorf("avatar uploads are not available")
+	}
+
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.Size > maxAvatarFileSize {
+		return nil, fmt.Errorf("invalid avatar: file must be at most %d MB", maxAvatarFileSize/(1024*1024))
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open avatar: %w", err)
+	}
+	defer src.Close()
+
+	// the declared size can lie, never read more than the limit
+	data, err := io.ReadAll(io.LimitReader(src, maxAvatarFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read avatar: %w", err)
+	}
+	if len(data) > maxAvatarFileSize {
+		return nil, fmt.Errorf("invalid avatar: file must be at most %d MB", maxAvatarFileSize/(1024*1024))
+	}
+
+	contentType := http.DetectContentType(data)
+	if !avatarContentTypes[contentType] {
+		return nil, fmt.Errorf("invalid avatar: only JPEG, PNG and GIF images are supported")
+	}
+
+	resized, extension, err := resizeAvatar(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// providers upload from disk and derive the content type from the extension
+	tmpFile, err := os.CreateTemp("", "avatar-*"+extension)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(resized)
+	closeErr := tmpFile.Close()
+	if err != nil || closeErr != nil {
+		return nil, fmt.Errorf("failed to write avatar: %w", errors.Join(err, closeErr))
+	}
+
+	err = s.storageProvider.UploadFromPath(ctx, tmpFile.Name(), avatarStoragePath(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to store avatar: %w", err)
+	}
+
+	user.AvatarURL = avatarServingPath(userID, time.Now().Unix())
+	err = s.userRepo.UpdateProfile(userID, user.DisplayName, user.AvatarURL)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cacheProfile(user)
+	logger.Infof("stored avatar of user %s (%d bytes)", userID, len(resized))
+	return user, nil
+}
+
+// GetAvatarURL returns a short-lived storage URL of the avatar a user uploaded
+func (s *userService) GetAvatarURL(ctx context.Context, userID uuid.UUID) (string, error) {
+	if s.storageProvider == nil {
+		return "", ErrAvatarNotFound
+	}
+
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return "", err
+	}
+	if !isUploadedAvatar(userID, user.AvatarURL) {
+		return "", ErrAvatarNotFound
+	}
+
+	return s.storageProvider.GenerateCDNSignedURL(ctx, avatarStoragePath(userID), &storage.CDNSignedURLOptions{
+		ExpiresIn:    avatarURLExpiry,
+		CacheControl: "public, max-age=86400",
+	})
+}
+
+// deleteUploadedAvatar removes a stored avatar the profile no longer points at
+func (s *userService) deleteUploadedAvatar(userID uuid.UUID) {
+	if s.storageProvider == nil {
+		return
+	}
+
+	err := s.storageProvider.Delete(context.Background(), avatarStoragePath(userID))
+	if err != nil {
+		logger.Errorf(err, "failed to delete avatar of user %s", userID)
+	}
+}
+
+// resizeAvatar decodes an image, scales it to avatarDimension and re-encodes it.
+// re-encoding also strips metadata such as EXIF location from photos.
+func resizeAvatar(data []byte) ([]byte, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid avatar: image could not be read")
+	}
+	if config.Width > maxAvatarSourceDimension || config.Height > maxAvatarSourceDimension {
+		return nil, "", fmt.Errorf("invalid avatar: image must be at most %dx%d pixels", maxAvatarSourceDimension, maxAvatarSourceDimension)
+	}
+
+	var img image.Image
+	switch format {
+	case "jpeg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+	case "png":
+		img, err = png.Decode(bytes.NewReader(data))
+	case "gif":
+		// animated avatars keep their first frame
+		img, err = gif.Decode(bytes.NewReader(data))
+	default:
+		return nil, "", fmt.Errorf("invalid avatar: only JPEG, PNG and GIF images are supported")
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid avatar: image could not be read")
+	}
+
+	img = utils.ResizeToFit(img, avatarDimension)
+
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+		return buf.Bytes(), ".jpg", err
+	}
+
+	// png and gif may be transparent
+	err = png.Encode(&buf, img)
+	return buf.Bytes(), ".png", err
+}
@@ -1,9 +1,14 @@
 package user
 
 import (
+	"context"
 	"errors"
+	"mime/multipart"
 	"time"
+	"watch-party/pkg/logger"
 	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+	"watch-party/pkg/storage"
 	userRepo "watch-party/service-api/internal/repository/user"
 
 	"github.com/google/uuid"
@@ -20,17 +25,30 @@ type Service interface {
 	RegisterUser(req *model.RegisterRequest, role string) (*model.User, error)
 	GetUserByEmail(email string) (*model.User, error)
 	GetUserByID(id uuid.UUID) (*model.User, error)
+	UpdateProfile(id uuid.UUID, req *model.UpdateProfileRequest) (*model.User, error)
+	EnsureDisplayName(user *model.User) error
+	UploadAvatar(ctx context.Context, userID uuid.UUID, file *multipart.FileHeader) (*model.User, error)
+	GetAvatarURL(ctx context.Context, userID uuid.UUID) (string, error)
 }
 
+// profileCacheTTL keeps cached profiles around for users who stay logged in, logins refresh it
+const profileCacheTTL = 30 * 24 * time.Hour
+
 // userService provides user-related services.
 type userService struct {
-	userRepo userRepo.Repository
+	userRepo        userRepo.Repository
+	redis           *redis.Client
+	storageProvider storage.Provider
+	avatarHosts     []string // hosts external avatar URLs may point at, empty allows any
 }
 
 // NewUserService creates a new user service instance.
-func NewUserService(userRepo userRepo.Repository) Service {
+func NewUserService(userRepo userRepo.Repository, redisClient *redis.Client, storageProvider storage.Provider, avatarHosts []string) Service {
 	return &userService{
-		userRepo: userRepo,
+		userRepo:        userRepo,
+		redis:           redisClient,
+		storageProvider: storageProvider,
+		avatarHosts:     avatarHosts,
 	}
 }
 
@@ -57,6 +75,7 @@ func (s *userService) RegisterUser(req *model.RegisterRequest, role string) (*mo
 		Email:        req.Email,
 		PasswordHash: hashedPassword,
 		Role:         role,
+		DisplayName:  model.DefaultDisplayName(req.Email),
 		CreatedAt:    time.Now(),
 	}
 
@@ -93,6 +112,58 @@ func (s *userService) GetUserByID(id uuid.UUID) (*model.User, error) {
 	return user, nil
 }
 
+// UpdateProfile changes the display name and avatar of a user
+func (s *userService) UpdateProfile(id uuid.UUID, req *model.UpdateProfileRequest) (*model.User, error) {
+	user, err := s.GetUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := req.ApplyTo(*user, s.avatarHosts)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.userRepo.UpdateProfile(id, updated.DisplayName, updated.AvatarURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// an uploaded avatar that was replaced or removed is no longer reachable
+	if isUploadedAvatar(id, user.AvatarURL) && !isUploadedAvatar(id, updated.AvatarURL) {
+		s.deleteUploadedAvatar(id)
+	}
+
+	s.cacheProfile(&updated)
+	return &updated, nil
+}
+
+// EnsureDisplayName gives users without a display name one derived from their email, and refreshes the cached profile
+func (s *userService) EnsureDisplayName(user *model.User) error {
+	if user.DisplayName == "" {
+		user.DisplayName = model.DefaultDisplayName(user.Email)
+		err := s.userRepo.UpdateProfile(user.ID, user.DisplayName, user.AvatarURL)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.cacheProfile(user)
+	return nil
+}
+
+// cacheProfile publishes the public profile for service-sync, which has no database access
+func (s *userService) cacheProfile(user *model.User) {
+	if s.redis == nil {
+		return
+	}
+
+	err := s.redis.Set(context.Background(), redis.UserProfileKey(user.ID), user.ToPublicProfile(), profileCacheTTL)
+	if err != nil {
+		logger.Errorf(err, "failed to cache profile of user %s", user.ID)
+	}
+}
+
 // HashPassword hashes a password using bcrypt
 func hashPassword(password string) (string, error) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"watch-party/pkg/auth"
 	"watch-party/pkg/config"
@@ -24,6 +25,7 @@ import (
 type AppServer struct {
 	config      *config.Config
 	handler     *handler.SyncHandler
+	syncService service.SyncService
 	redisClient *redis.Client
 }
 
@@ -39,20 +41,21 @@ func NewAppServer(cfg *config.Config) *AppServer {
 	}
 
 	// initialize sync repository (Redis-based for real-time sync state)
-	syncRepo := repository.NewSyncRepository(redisClient)
+	syncRepo := repository.NewSyncRepository(redisClient, cfg.Chat)
 
 	// initialize service
-	syncService := service.NewSyncService(syncRepo, redisClient)
+	syncService := service.NewSyncService(syncRepo, redisClient, cfg)
 
-	// initialize JWT manager
-	jwtManager := auth.NewJWTManager(cfg.JWTSecret)
+	// initialize JWT manager, rejecting tokens revoked through service-api
+	jwtManager := auth.NewJWTManager(cfg.JWTSecret, auth.NewTokenRevoker(redisClient))
 
 	// initialize handler
-	syncHandler := handler.NewSyncHandler(syncService, jwtManager)
+	syncHandler := handler.NewSyncHandler(syncService, jwtManager, cfg.CORS)
 
 	return &AppServer{
 		config:      cfg,
 		handler:     syncHandler,
+		syncService: syncService,
 		redisClient: redisClient,
 	}
 }
@@ -81,16 +84,19 @@ func (s *AppServer) Serve() {
 		Handler: router,
 	}
 
-	sslEnabled := os.Getenv("SSL_ENABLED") == "true"
-	certPath := os.Getenv("SSL_CERT_PATH")
-	keyPath := os.Getenv("SSL_KEY_PATH")
+	tlsConfig, err := s.config.TLS.Load()
+	if err != nil {
+		logger.Fatalf("invalid TLS configuration: %v", err)
+	}
+	server.TLSConfig = tlsConfig
+	sslEnabled := tlsConfig != nil
 
 	// start server
 	go func() {
 		var err error
-		if sslEnabled && certPath != "" && keyPath != "" {
+		if sslEnabled {
 			logger.Infof("Starting SSL server on port %s", s.config.Port)
-			err = server.ListenAndServeTLS(certPath, keyPath)
+			err = server.ListenAndServeTLS("", "")
 		} else {
 			logger.Infof("Starting HTTP server on port %s", s.config.Port)
 			err = server.ListenAndServe()
@@ -103,9 +109,7 @@ func (s *AppServer) Serve() {
 
 	if sslEnabled {
 		httpRouter := gin.New()
-		httpRouter.GET("/health", func(c *gin.Context) {
-			c.JSON(http.StatusOK, gin.H{"status": "healthy", "service": "sync"})
-		})
+		httpRouter.GET("/health", s.health)
 
 		httpServer := &http.Server{
 			Addr:    ":8080",
@@ -139,11 +143,35 @@ func (s *AppServer) setupRoutes(router *gin.Engine) {
 		// room sync state queries (read-only, from Redis)
 		api.GET("/rooms/:roomID/state", s.handler.GetRoomState)
 		api.GET("/rooms/:roomID/participants", s.handler.GetRoomParticipants)
+
+		// connection metrics for this instance, they name rooms so admin only
+		api.GET("/admin/stats", s.handler.GetConnectionStats)
+
+		// viewers across all instances, admin only
+		api.GET("/admin/viewers", s.handler.GetViewerCounts)
+
+		// feature flags this instance runs with, admin only
+		api.GET("/admin/features", s.handler.GetFeatureFlags)
 	}
 
 	// health check
-	router.GET("/health", func(c *gin.Context) {
+	router.GET("/health", s.health)
+}
+
+// health reports the instance as degraded while Redis is unavailable. it still answers 200, the instance keeps
+// syncing the rooms connected to it and must not be taken out of rotation for that
+func (s *AppServer) health(c *gin.Context) {
+	since := s.syncService.DegradedSince()
+	if since.IsZero() {
 		c.JSON(http.StatusOK, gin.H{"status": "healthy", "service": "sync"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":         "degraded",
+		"service":        "sync",
+		"redis":          "unavailable",
+		"degraded_since": since,
 	})
 }
 
@@ -165,6 +193,11 @@ func (s *AppServer) gracefulShutdown(server *http.Server) {
 		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 		<-signals
 
+		// deregister before Redis goes away so the API stops routing to this instance
+		deregisterCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		s.syncService.Shutdown(deregisterCtx)
+		cancel()
+
 		// close Redis connection
 		if s.redisClient != nil {
 			s.redisClient.Close()
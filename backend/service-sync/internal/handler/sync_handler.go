@@ -3,12 +3,15 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"watch-party/pkg/auth"
+	"watch-party/pkg/config"
 	"watch-party/pkg/logger"
 	"watch-party/pkg/model"
 	"watch-party/service-sync/internal/service"
@@ -25,16 +28,23 @@ type SyncHandler struct {
 	upgrader   websocket.Upgrader
 }
 
+// guestValidationClient asks the API service about guest sessions, a stalled API must not hold the upgrade forever
+var guestValidationClient = &http.Client{Timeout: 5 * time.Second}
+
 // NewSyncHandler creates a new sync handler instance
-func NewSyncHandler(service service.SyncService, jwtManager *auth.JWTManager) *SyncHandler {
+func NewSyncHandler(service service.SyncService, jwtManager *auth.JWTManager, cors config.CORSConfig) *SyncHandler {
 	return &SyncHandler{
 		service:    service,
 		jwtManager: jwtManager,
 		upgrader: websocket.Upgrader{
+			// only pages of the configured origins may open the socket, so another site can't hijack it
 			CheckOrigin: func(r *http.Request) bool {
-				// allow all origins for development
-				// in production, implement proper origin validation
-				return true
+				origin := r.Header.Get("Origin")
+				if cors.AllowsWebSocketOrigin(origin) {
+					return true
+				}
+				logger.Warnf("rejected WebSocket upgrade from origin %q", origin)
+				return false
 			},
 		},
 	}
@@ -53,6 +63,7 @@ func (h *SyncHandler) HandleWebSocket(c *gin.Context) {
 	var (
 		userID   uuid.UUID
 		username string
+		isGuest  bool
 	)
 
 	// check for guest session token
@@ -61,7 +72,7 @@ func (h *SyncHandler) HandleWebSocket(c *gin.Context) {
 	if guestToken != "" {
 		// handle guest connection
 		// validate guest session token with API service
-		resp, err := http.Get(fmt.Sprintf("http://localhost:8080/api/v1/guest/validate/%s", guestToken))
+		resp, err := guestValidationClient.Get(fmt.Sprintf("http://localhost:8080/api/v1/guest/validate/%s", guestToken))
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to validate guest session"})
 			return
@@ -100,6 +111,7 @@ func (h *SyncHandler) HandleWebSocket(c *gin.Context) {
 		// generate temporary UUID for guest session
 		userID = uuid.New()
 		username = validationResp.GuestName + " (Guest)"
+		isGuest = true
 	} else {
 		// Handle authenticated user connection - use JWT token
 		userID, username, _, err = h.getUserFromToken(c)
@@ -119,14 +131,27 @@ func (h *SyncHandler) HandleWebSocket(c *gin.Context) {
 
 	// handle the WebSocket connection
 	ctx := context.Background()
-	err = h.service.HandleConnection(ctx, roomID, userID, username, conn)
+	// a client whose connection dropped reconnects with the token it was given to keep its place in the room
+	protocolVersion := model.NegotiateProtocolVersion(c.Query("protocolVersion"))
+	err = h.service.HandleConnection(ctx, roomID, userID, username, isGuest, c.Query("resumeToken"), protocolVersion, conn)
 	if err != nil {
 		logger.Error(err, "failed to handle WebSocket connection")
+
+		code := "CONNECTION_ERROR"
+		switch {
+		case errors.Is(err, service.ErrUsernameTaken):
+			code = "USERNAME_TAKEN"
+		case errors.Is(err, service.ErrRoomLimitReached):
+			code = "ROOM_LIMIT_REACHED"
+		case errors.Is(err, service.ErrRoomFull):
+			code = "ROOM_FULL"
+		}
+
 		// send error message to client before closing
 		conn.WriteJSON(&model.WebSocketMessage{
 			Type: model.MessageTypeError,
 			Payload: model.ErrorMessage{
-				Code:    "CONNECTION_ERROR",
+				Code:    code,
 				Message: err.Error(),
 			},
 		})
@@ -180,9 +205,57 @@ func (h *SyncHandler) GetRoomParticipants(c *gin.Context) {
 	})
 }
 
+// GetConnectionStats handles GET /api/v1/admin/stats with the connections held by this instance, per room
+func (h *SyncHandler) GetConnectionStats(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	c.JSON(http.StatusOK, h.service.GetConnectionStats())
+}
+
+// GetViewerCounts handles GET /api/v1/admin/viewers with the viewers connected across all instances
+func (h *SyncHandler) GetViewerCounts(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	counts, err := h.service.GetViewerCounts(c.Request.Context())
+	if err != nil {
+		logger.Error(err, "failed to count viewers")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count viewers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, counts)
+}
+
+// GetFeatureFlags handles GET /api/v1/admin/features with the feature flags of this instance
+func (h *SyncHandler) GetFeatureFlags(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"flags": h.service.GetFeatureFlags()})
+}
+
 // helper functions for authentication/authorization
 // in production, these would be middleware
 
+// requireAdmin responds with 401 or 403 and returns false unless the request carries an admin token
+func (h *SyncHandler) requireAdmin(c *gin.Context) bool {
+	_, _, role, err := h.getUserFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing authentication token"})
+		return false
+	}
+	if role != model.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return false
+	}
+	return true
+}
+
 // getUserFromToken extracts user ID, username, and role from JWT token
 func (h *SyncHandler) getUserFromToken(c *gin.Context) (uuid.UUID, string, string, error) {
 	var tokenString string
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"watch-party/pkg/auth"
+	"watch-party/pkg/config"
+	"watch-party/pkg/model"
+	"watch-party/service-sync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statsService reports fixed connection stats
+type statsService struct {
+	service.SyncService
+	stats *model.ConnectionStats
+}
+
+func (s *statsService) GetConnectionStats() *model.ConnectionStats {
+	return s.stats
+}
+
+func TestGetConnectionStatsRequiresAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtManager := auth.NewJWTManager("test-secret", nil)
+	stats := &model.ConnectionStats{InstanceID: "sync-1", TotalConnections: 2, Rooms: map[uuid.UUID]int{uuid.New(): 2}}
+	h := NewSyncHandler(&statsService{stats: stats}, jwtManager, config.CORSConfig{})
+
+	router := gin.New()
+	router.GET("/api/v1/admin/stats", h.GetConnectionStats)
+
+	token := func(role string) string {
+		signed, err := jwtManager.GenerateAccessToken(&model.User{ID: uuid.New(), Email: "someone@example.com", Role: role})
+		require.NoError(t, err)
+		return signed
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{name: "anonymous", wantStatus: http.StatusUnauthorized},
+		{name: "user", token: token(model.RoleUser), wantStatus: http.StatusForbidden},
+		{name: "admin", token: token(model.RoleAdmin), wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/stats", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus != http.StatusOK {
+				assert.NotContains(t, rec.Body.String(), "rooms", "room IDs are only shown to admins")
+			}
+		})
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"watch-party/pkg/config"
 	"watch-party/pkg/model"
 	"watch-party/pkg/redis"
+	"watch-party/pkg/video"
 
 	"github.com/google/uuid"
 	redislib "github.com/redis/go-redis/v9"
@@ -24,61 +28,125 @@ type SyncRepository interface {
 	// participant operations
 	AddParticipant(ctx context.Context, roomID, userID uuid.UUID, participant *model.ParticipantInfo) error
 	RemoveParticipant(ctx context.Context, roomID, userID uuid.UUID) error
+	RemoveStaleParticipant(ctx context.Context, roomID, userID uuid.UUID) (bool, error)
 	GetParticipants(ctx context.Context, roomID uuid.UUID) ([]model.ParticipantInfo, error)
+	CountParticipants(ctx context.Context, roomID uuid.UUID) (int, error)
 	UpdateParticipantPresence(ctx context.Context, roomID, userID uuid.UUID) error
+	UpdateParticipantBuffer(ctx context.Context, roomID, userID uuid.UUID, bufferedAhead float64, isBuffering bool) error
+	GetBufferReports(ctx context.Context, roomID uuid.UUID) (map[uuid.UUID]model.BufferReport, error)
+	SetParticipantHost(ctx context.Context, roomID, hostID uuid.UUID) error
+	SetParticipantRole(ctx context.Context, roomID, userID uuid.UUID, role string) error
+
+	// host operations
+	GetRoomHost(ctx context.Context, roomID uuid.UUID) (uuid.UUID, error)
+	SetRoomHost(ctx context.Context, roomID, hostID uuid.UUID) error
+	IsRoomCoHost(ctx context.Context, roomID, userID uuid.UUID) (bool, error)
+
+	// settings operations
+	GetRoomSettings(ctx context.Context, roomID uuid.UUID) (*model.RoomSettings, error)
+
+	// movie operations
+	GetRoomMovie(ctx context.Context, roomID uuid.UUID) (uuid.UUID, error)
+	GetRoomMovieQualities(ctx context.Context, roomID uuid.UUID) ([]string, error)
+	GetSegmentTimeline(ctx context.Context, movieID uuid.UUID, quality string) (string, *video.SegmentTimeline, error)
+
+	// chat operations
+	AppendChatMessage(ctx context.Context, roomID uuid.UUID, entry *model.ChatLogEntry) error
+	GetChatSlowMode(ctx context.Context, roomID uuid.UUID) (time.Duration, error)
+	SetChatSlowMode(ctx context.Context, roomID uuid.UUID, interval time.Duration) error
+	AllowChatMessage(ctx context.Context, roomID, userID uuid.UUID, interval time.Duration) (bool, error)
+
+	// reaction operations
+	AllowReaction(ctx context.Context, roomID, userID uuid.UUID) (bool, error)
+
+	// annotation operations
+	AllowAnnotation(ctx context.Context, roomID, userID uuid.UUID) (bool, error)
+	AppendAnnotation(ctx context.Context, roomID uuid.UUID, annotation *model.AnnotationMessage) error
+
+	// resume operations
+	StoreResumeToken(ctx context.Context, token string, roomID, userID uuid.UUID, ttl time.Duration) (bool, error)
+	GetResumeToken(ctx context.Context, token string) (uuid.UUID, uuid.UUID, error)
+	ConsumeResumeToken(ctx context.Context, token string, roomID, userID uuid.UUID) (bool, error)
+
+	// diagnostics operations
+	SetInstanceConnections(ctx context.Context, roomID uuid.UUID, instanceID string, count int) error
+
+	// connection routing operations
+	SetUserInstance(ctx context.Context, roomID, userID uuid.UUID, instanceID string) error
+	RemoveUserInstance(ctx context.Context, roomID, userID uuid.UUID, instanceID string) error
+	GetUserInstance(ctx context.Context, roomID, userID uuid.UUID) (string, error)
+	GetUserInstances(ctx context.Context, roomID uuid.UUID) (map[string]string, error)
+
+	// username reservation operations
+	ClaimUsername(ctx context.Context, roomID, userID uuid.UUID, username string, force bool) (bool, string, error)
+	ReleaseUsername(ctx context.Context, roomID, userID uuid.UUID, username string) error
+	RecordRoomError(ctx context.Context, roomID uuid.UUID, code string) error
 
 	// presence operations
-	SetUserPresence(ctx context.Context, userID uuid.UUID, roomID uuid.UUID, status string) error
-	GetUserPresence(ctx context.Context, userID uuid.UUID) (string, error)
-	RemoveUserPresence(ctx context.Context, userID uuid.UUID) error
+	SetUserPresence(ctx context.Context, userID, roomID uuid.UUID) error
+	GetUserRooms(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	ClaimUserRoom(ctx context.Context, userID, roomID uuid.UUID, limit int) (bool, error)
+	RemoveUserPresence(ctx context.Context, userID, roomID uuid.UUID) error
 
 	// room management
 	GetActiveRooms(ctx context.Context, limit int64) ([]uuid.UUID, error)
+	GetRoomViewerCounts(ctx context.Context, limit int64) (map[uuid.UUID]int, error)
 	CleanupInactiveRooms(ctx context.Context, inactiveDuration time.Duration) error
 
 	// event operations
 	PublishEvent(ctx context.Context, roomID uuid.UUID, event *model.SyncMessage) error
+	GetEventSeq(ctx context.Context, roomID uuid.UUID) (int64, error)
 	SubscribeToRoomEvents(ctx context.Context, roomID uuid.UUID) (*redislib.PubSub, error)
 
 	// locking for conflict resolution
-	AcquireRoomLock(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) (bool, error)
-	ReleaseRoomLock(ctx context.Context, roomID uuid.UUID) error
+	AcquireRoomLock(ctx context.Context, roomID uuid.UUID, token string) (bool, error)
+	ReleaseRoomLock(ctx context.Context, roomID uuid.UUID, token string) error
 }
 
 type syncRepository struct {
-	redis *redis.Client
+	redis      *redis.Client
+	chatConfig config.ChatConfig
 }
 
 // NewSyncRepository creates a new sync repository instance
-func NewSyncRepository(redisClient *redis.Client) SyncRepository {
+func NewSyncRepository(redisClient *redis.Client, chatConfig config.ChatConfig) SyncRepository {
 	return &syncRepository{
-		redis: redisClient,
+		redis:      redisClient,
+		chatConfig: chatConfig,
 	}
 }
 
 // Redis key helpers
 func (r *syncRepository) roomSyncKey(roomID uuid.UUID) string {
-	return fmt.Sprintf("watch-party:room:sync:%s", roomID.String())
+	return redis.RoomStateKey(roomID)
 }
 
 func (r *syncRepository) roomParticipantsKey(roomID uuid.UUID) string {
-	return fmt.Sprintf("watch-party:room:participants:%s", roomID.String())
+	return redis.RoomParticipantsKey(roomID)
+}
+
+func (r *syncRepository) roomBuffersKey(roomID uuid.UUID) string {
+	return fmt.Sprintf("watch-party:room:buffers:%s", roomID.String())
 }
 
 func (r *syncRepository) userPresenceKey(userID uuid.UUID) string {
-	return fmt.Sprintf("watch-party:user:presence:%s", userID.String())
+	return fmt.Sprintf("watch-party:user:rooms:%s", userID.String())
 }
 
 func (r *syncRepository) roomEventsKey(roomID uuid.UUID) string {
 	return fmt.Sprintf("watch-party:room:events:%s", roomID.String())
 }
 
+func (r *syncRepository) roomEventSeqKey(roomID uuid.UUID) string {
+	return redis.RoomEventSeqKey(roomID)
+}
+
 func (r *syncRepository) activeRoomsKey() string {
-	return "watch-party:rooms:active"
+	return redis.ActiveRoomsKey()
 }
 
 func (r *syncRepository) roomLockKey(roomID uuid.UUID) string {
-	return fmt.Sprintf("watch-party:room:lock:%s", roomID.String())
+	return redis.RoomLockKey(roomID)
 }
 
 // SetRoomState sets the room state in Redis
@@ -94,6 +162,7 @@ func (r *syncRepository) SetRoomState(ctx context.Context, state *model.RoomStat
 		"playback_rate", fmt.Sprintf("%.2f", state.PlaybackRate),
 		"last_updated", strconv.FormatInt(now, 10),
 		"updated_by", state.UpdatedBy.String(),
+		"default_quality", state.DefaultQuality,
 	}
 
 	// Set room state
@@ -133,60 +202,7 @@ func (r *syncRepository) GetRoomState(ctx context.Context, roomID uuid.UUID) (*m
 		return nil, fmt.Errorf("room state not found")
 	}
 
-	state := &model.RoomState{}
-
-	// Parse room_id
-	if roomIDStr, ok := data["room_id"]; ok {
-		if state.RoomID, err = uuid.Parse(roomIDStr); err != nil {
-			return nil, fmt.Errorf("invalid room_id: %w", err)
-		}
-	} else {
-		state.RoomID = roomID
-	}
-
-	// Parse is_playing
-	if isPlayingStr, ok := data["is_playing"]; ok {
-		if state.IsPlaying, err = strconv.ParseBool(isPlayingStr); err != nil {
-			return nil, fmt.Errorf("invalid is_playing: %w", err)
-		}
-	}
-
-	// Parse current_time
-	if currentTimeStr, ok := data["current_time"]; ok {
-		if state.CurrentTime, err = strconv.ParseFloat(currentTimeStr, 64); err != nil {
-			return nil, fmt.Errorf("invalid current_time: %w", err)
-		}
-	}
-
-	// Parse duration
-	if durationStr, ok := data["duration"]; ok {
-		if state.Duration, err = strconv.ParseFloat(durationStr, 64); err != nil {
-			return nil, fmt.Errorf("invalid duration: %w", err)
-		}
-	}
-
-	// Parse playback_rate
-	if playbackRateStr, ok := data["playback_rate"]; ok {
-		if state.PlaybackRate, err = strconv.ParseFloat(playbackRateStr, 64); err != nil {
-			return nil, fmt.Errorf("invalid playback_rate: %w", err)
-		}
-	}
-
-	// Parse last_updated
-	if lastUpdatedStr, ok := data["last_updated"]; ok {
-		if timestamp, err := strconv.ParseInt(lastUpdatedStr, 10, 64); err == nil {
-			state.LastUpdated = time.Unix(timestamp, 0)
-		}
-	}
-
-	// Parse updated_by
-	if updatedByStr, ok := data["updated_by"]; ok {
-		if state.UpdatedBy, err = uuid.Parse(updatedByStr); err != nil {
-			return nil, fmt.Errorf("invalid updated_by: %w", err)
-		}
-	}
-
-	return state, nil
+	return model.ParseRoomState(roomID, data)
 }
 
 // DeleteRoomState removes the room state from Redis
@@ -194,8 +210,9 @@ func (r *syncRepository) DeleteRoomState(ctx context.Context, roomID uuid.UUID)
 	roomKey := r.roomSyncKey(roomID)
 	participantsKey := r.roomParticipantsKey(roomID)
 	eventsKey := r.roomEventsKey(roomID)
+	eventSeqKey := r.roomEventSeqKey(roomID)
 
-	err := r.redis.Delete(ctx, roomKey, participantsKey, eventsKey)
+	err := r.redis.Delete(ctx, roomKey, participantsKey, eventsKey, eventSeqKey)
 	if err != nil {
 		return fmt.Errorf("failed to delete room state: %w", err)
 	}
@@ -244,6 +261,16 @@ func (r *syncRepository) RemoveParticipant(ctx context.Context, roomID, userID u
 	return nil
 }
 
+// RemoveStaleParticipant removes a participant left behind by a lost connection, reporting whether it was still
+// there so only one instance announces the departure
+func (r *syncRepository) RemoveStaleParticipant(ctx context.Context, roomID, userID uuid.UUID) (bool, error) {
+	removed, err := r.redis.HDelCount(ctx, r.roomParticipantsKey(roomID), userID.String())
+	if err != nil {
+		return false, fmt.Errorf("failed to remove participant: %w", err)
+	}
+	return removed > 0, nil
+}
+
 // GetParticipants retrieves all participants in a room
 func (r *syncRepository) GetParticipants(ctx context.Context, roomID uuid.UUID) ([]model.ParticipantInfo, error) {
 	participantsKey := r.roomParticipantsKey(roomID)
@@ -266,6 +293,15 @@ func (r *syncRepository) GetParticipants(ctx context.Context, roomID uuid.UUID)
 	return participants, nil
 }
 
+// CountParticipants returns how many participants a room lists without decoding them
+func (r *syncRepository) CountParticipants(ctx context.Context, roomID uuid.UUID) (int, error) {
+	count, err := r.redis.HLen(ctx, r.roomParticipantsKey(roomID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count participants: %w", err)
+	}
+	return int(count), nil
+}
+
 // UpdateParticipantPresence updates the last seen time for a participant
 func (r *syncRepository) UpdateParticipantPresence(ctx context.Context, roomID, userID uuid.UUID) error {
 	participantsKey := r.roomParticipantsKey(roomID)
@@ -298,47 +334,629 @@ func (r *syncRepository) UpdateParticipantPresence(ctx context.Context, roomID,
 	return nil
 }
 
-// SetUserPresence sets user presence information
-func (r *syncRepository) SetUserPresence(ctx context.Context, userID uuid.UUID, roomID uuid.UUID, status string) error {
-	presenceKey := r.userPresenceKey(userID)
+// bufferReportsTTL drops the reports of a room a while after its last report, the play gate ignores old reports anyway
+const bufferReportsTTL = time.Minute
 
-	presenceData := map[string]interface{}{
-		"room_id":   roomID.String(),
-		"status":    status,
-		"timestamp": time.Now().Unix(),
+// UpdateParticipantBuffer records the latest buffer report of a participant's player. reports live in their own
+// hash, one field per participant, so a report is a single write that cannot undo a concurrent participant update
+func (r *syncRepository) UpdateParticipantBuffer(ctx context.Context, roomID, userID uuid.UUID, bufferedAhead float64, isBuffering bool) error {
+	data, err := json.Marshal(model.BufferReport{
+		BufferedAhead: bufferedAhead,
+		IsBuffering:   isBuffering,
+		ReportedAt:    time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal buffer report: %w", err)
 	}
 
-	err := r.redis.Set(ctx, presenceKey, presenceData, 60*time.Second)
+	err = r.redis.HSetExpire(ctx, r.roomBuffersKey(roomID), bufferReportsTTL, userID.String(), string(data))
 	if err != nil {
-		return fmt.Errorf("failed to set user presence: %w", err)
+		return fmt.Errorf("failed to update participant buffer: %w", err)
 	}
 
 	return nil
 }
 
-// GetUserPresence retrieves user presence information
-func (r *syncRepository) GetUserPresence(ctx context.Context, userID uuid.UUID) (string, error) {
-	presenceKey := r.userPresenceKey(userID)
+// GetBufferReports returns the last buffer report of each participant that sent one
+func (r *syncRepository) GetBufferReports(ctx context.Context, roomID uuid.UUID) (map[uuid.UUID]model.BufferReport, error) {
+	data, err := r.redis.HGetAll(ctx, r.roomBuffersKey(roomID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get buffer reports: %w", err)
+	}
+
+	reports := make(map[uuid.UUID]model.BufferReport, len(data))
+	for field, reportData := range data {
+		userID, err := uuid.Parse(field)
+		if err != nil {
+			continue // skip invalid entries
+		}
+		var report model.BufferReport
+		if err := json.Unmarshal([]byte(reportData), &report); err != nil {
+			continue
+		}
+		reports[userID] = report
+	}
+
+	return reports, nil
+}
+
+// SetParticipantRole updates the role of a connected participant, a participant who is not connected is skipped
+func (r *syncRepository) SetParticipantRole(ctx context.Context, roomID, userID uuid.UUID, role string) error {
+	participantsKey := r.roomParticipantsKey(roomID)
+
+	participantData, err := r.redis.HGet(ctx, participantsKey, userID.String())
+	if err != nil {
+		return nil
+	}
+
+	var participant model.ParticipantInfo
+	if err := json.Unmarshal([]byte(participantData), &participant); err != nil {
+		return fmt.Errorf("failed to unmarshal participant data: %w", err)
+	}
+
+	// the host keeps its role whatever its member record says
+	if participant.IsHost || participant.Role == role {
+		return nil
+	}
+	participant.Role = role
+
+	updatedData, err := json.Marshal(participant)
+	if err != nil {
+		return fmt.Errorf("failed to marshal updated participant data: %w", err)
+	}
+
+	err = r.redis.HSet(ctx, participantsKey, userID.String(), string(updatedData))
+	if err != nil {
+		return fmt.Errorf("failed to update participant role: %w", err)
+	}
+
+	return nil
+}
+
+// SetParticipantHost updates the IsHost flag and role of every participant so only hostID is marked as host
+func (r *syncRepository) SetParticipantHost(ctx context.Context, roomID, hostID uuid.UUID) error {
+	participantsKey := r.roomParticipantsKey(roomID)
+
+	data, err := r.redis.HGetAll(ctx, participantsKey)
+	if err != nil {
+		return fmt.Errorf("failed to get participants: %w", err)
+	}
+
+	for userIDStr, participantData := range data {
+		var participant model.ParticipantInfo
+		if err := json.Unmarshal([]byte(participantData), &participant); err != nil {
+			continue // skip invalid entries
+		}
+
+		isHost := participant.UserID == hostID
+		if participant.IsHost == isHost {
+			continue
+		}
+		participant.IsHost = isHost
+		participant.Role = model.RoomRoleHost
+		if !isHost {
+			// a former host falls back to whatever its member record says
+			participant.Role = model.RoomRoleMember
+			if isCoHost, err := r.IsRoomCoHost(ctx, roomID, participant.UserID); err == nil && isCoHost {
+				participant.Role = model.RoomRoleCoHost
+			}
+		}
+
+		updatedData, err := json.Marshal(participant)
+		if err != nil {
+			return fmt.Errorf("failed to marshal updated participant data: %w", err)
+		}
+
+		err = r.redis.HSet(ctx, participantsKey, userIDStr, string(updatedData))
+		if err != nil {
+			return fmt.Errorf("failed to update participant host flag: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetRoomHost retrieves the current room host cached by service-api
+func (r *syncRepository) GetRoomHost(ctx context.Context, roomID uuid.UUID) (uuid.UUID, error) {
+	var hostIDStr string
+	err := r.redis.Get(ctx, redis.RoomHostKey(roomID), &hostIDStr)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get room host: %w", err)
+	}
+
+	hostID, err := uuid.Parse(hostIDStr)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid room host: %w", err)
+	}
+
+	return hostID, nil
+}
+
+// roomHostTTL matches how long service-api caches the host of a room after it was last opened
+const roomHostTTL = 24 * time.Hour
+
+// SetRoomHost overwrites the cached room host
+func (r *syncRepository) SetRoomHost(ctx context.Context, roomID, hostID uuid.UUID) error {
+	err := r.redis.Set(ctx, redis.RoomHostKey(roomID), hostID.String(), roomHostTTL)
+	if err != nil {
+		return fmt.Errorf("failed to set room host: %w", err)
+	}
+
+	return nil
+}
+
+// IsRoomCoHost checks the co-hosts cached by service-api
+func (r *syncRepository) IsRoomCoHost(ctx context.Context, roomID, userID uuid.UUID) (bool, error) {
+	isCoHost, err := r.redis.SetIsMember(ctx, redis.RoomCoHostsKey(roomID), userID.String())
+	if err != nil {
+		return false, fmt.Errorf("failed to check room co-host: %w", err)
+	}
+
+	return isCoHost, nil
+}
+
+// GetRoomSettings retrieves the room settings cached by service-api
+func (r *syncRepository) GetRoomSettings(ctx context.Context, roomID uuid.UUID) (*model.RoomSettings, error) {
+	settings := model.DefaultRoomSettings()
+	err := r.redis.Get(ctx, redis.RoomSettingsKey(roomID), &settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// GetRoomMovie returns the movie a room plays, as cached by service-api when the room is opened
+func (r *syncRepository) GetRoomMovie(ctx context.Context, roomID uuid.UUID) (uuid.UUID, error) {
+	var movieIDStr string
+	err := r.redis.Get(ctx, redis.RoomMovieKey(roomID), &movieIDStr)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get room movie: %w", err)
+	}
+
+	movieID, err := uuid.Parse(movieIDStr)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid room movie: %w", err)
+	}
+
+	return movieID, nil
+}
+
+// GetRoomMovieQualities returns the qualities the movie a room plays was published with, as cached by service-api
+// when the room is opened once the movie is available
+func (r *syncRepository) GetRoomMovieQualities(ctx context.Context, roomID uuid.UUID) ([]string, error) {
+	var qualities []string
+	err := r.redis.Get(ctx, redis.RoomMovieQualitiesKey(roomID), &qualities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room movie qualities: %w", err)
+	}
+
+	return qualities, nil
+}
+
+// GetSegmentTimeline returns the cached segment timeline of a movie at quality, or of any cached quality when
+// that one isn't cached since the ladder's segments are cut at the same times. it returns the quality the
+// timeline belongs to, and a nil timeline when none is cached
+func (r *syncRepository) GetSegmentTimeline(ctx context.Context, movieID uuid.UUID, quality string) (string, *video.SegmentTimeline, error) {
+	timelines, err := r.redis.HGetAll(ctx, redis.MovieSegmentsKey(movieID))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get segment timelines: %w", err)
+	}
+
+	value, found := timelines[quality]
+	if !found {
+		qualities := make([]string, 0, len(timelines))
+		for cached := range timelines {
+			qualities = append(qualities, cached)
+		}
+		if len(qualities) == 0 {
+			return "", nil, nil
+		}
+		sort.Strings(qualities)
+		quality = qualities[0]
+		value = timelines[quality]
+	}
+
+	var timeline video.SegmentTimeline
+	err = json.Unmarshal([]byte(value), &timeline)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid segment timeline: %w", err)
+	}
+	return quality, &timeline, nil
+}
+
+// AppendChatMessage adds a chat message to the room's recent chat buffer, trimmed to the configured retention
+func (r *syncRepository) AppendChatMessage(ctx context.Context, roomID uuid.UUID, entry *model.ChatLogEntry) error {
+	maxMessages := r.chatConfig.HistoryMaxMessages
+	if maxMessages <= 0 {
+		maxMessages = config.DefaultChatHistoryMaxMessages
+	}
+
+	ttl := r.chatConfig.HistoryTTL.ToDuration()
+	if ttl <= 0 {
+		ttl = config.DefaultChatHistoryTTL
+	}
+
+	err := r.redis.ListPush(ctx, redis.RoomRecentChatKey(roomID), entry, int64(maxMessages), ttl)
+	if err != nil {
+		return fmt.Errorf("failed to append chat message: %w", err)
+	}
+
+	return nil
+}
+
+// GetChatSlowMode returns the minimum gap between two chat messages of a user in a room, 0 when slow mode is off
+func (r *syncRepository) GetChatSlowMode(ctx context.Context, roomID uuid.UUID) (time.Duration, error) {
+	value, err := r.redis.GetString(ctx, redis.RoomSlowModeKey(roomID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get slow mode: %w", err)
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid slow mode interval %q: %w", value, err)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// SetChatSlowMode turns slow mode on with the given interval, or off when it is 0
+func (r *syncRepository) SetChatSlowMode(ctx context.Context, roomID uuid.UUID, interval time.Duration) error {
+	key := redis.RoomSlowModeKey(roomID)
+	if interval <= 0 {
+		err := r.redis.Delete(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to turn off slow mode: %w", err)
+		}
+		return nil
+	}
+
+	// kept until the host turns it off or the room is deleted, a room reopened after a quiet day keeps its slow mode
+	err := r.redis.Set(ctx, key, int(interval.Seconds()), 0)
+	if err != nil {
+		return fmt.Errorf("failed to set slow mode: %w", err)
+	}
+	return nil
+}
+
+// AllowChatMessage reports whether a user may chat in a room now, claiming the slot until the interval passes
+func (r *syncRepository) AllowChatMessage(ctx context.Context, roomID, userID uuid.UUID, interval time.Duration) (bool, error) {
+	allowed, err := r.redis.SetNX(ctx, redis.ChatRateKey(roomID, userID), 1, interval)
+	if err != nil {
+		return false, fmt.Errorf("failed to check chat rate: %w", err)
+	}
+	return allowed, nil
+}
+
+// AllowReaction reports whether a user may react in a room now, counting the reaction against the current window
+func (r *syncRepository) AllowReaction(ctx context.Context, roomID, userID uuid.UUID) (bool, error) {
+	count, err := r.redis.IncrWindow(ctx, redis.ReactionRateKey(roomID, userID), model.ReactionWindow)
+	if err != nil {
+		return false, fmt.Errorf("failed to check reaction rate: %w", err)
+	}
+	return count <= model.ReactionBurst, nil
+}
+
+// AllowAnnotation reports whether a user may annotate a room now, claiming the slot until the interval passes
+func (r *syncRepository) AllowAnnotation(ctx context.Context, roomID, userID uuid.UUID) (bool, error) {
+	allowed, err := r.redis.SetNX(ctx, redis.AnnotationRateKey(roomID, userID), 1, model.AnnotationInterval)
+	if err != nil {
+		return false, fmt.Errorf("failed to check annotation rate: %w", err)
+	}
+	return allowed, nil
+}
+
+// StoreResumeToken keeps the resume token of a dropped connection for ttl, false when the token is already stored
+func (r *syncRepository) StoreResumeToken(ctx context.Context, token string, roomID, userID uuid.UUID, ttl time.Duration) (bool, error) {
+	stored, err := r.redis.SetNX(ctx, redis.ResumeTokenKey(token), resumeTokenValue(roomID, userID), ttl)
+	if err != nil {
+		return false, fmt.Errorf("failed to store resume token: %w", err)
+	}
+	return stored, nil
+}
+
+// GetResumeToken returns the room and user a resume token was issued for, nil IDs when it is unknown or expired
+func (r *syncRepository) GetResumeToken(ctx context.Context, token string) (uuid.UUID, uuid.UUID, error) {
+	value, err := r.redis.GetString(ctx, redis.ResumeTokenKey(token))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("failed to get resume token: %w", err)
+	}
+
+	roomPart, userPart, found := strings.Cut(value, ":")
+	if !found {
+		return uuid.Nil, uuid.Nil, nil
+	}
+	roomID, err := uuid.Parse(roomPart)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, nil
+	}
+	userID, err := uuid.Parse(userPart)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, nil
+	}
+	return roomID, userID, nil
+}
+
+// ConsumeResumeToken deletes a resume token issued for the room and user, reporting whether this call did. the
+// reconnecting client and the pending leave race for it, exactly one of them wins
+func (r *syncRepository) ConsumeResumeToken(ctx context.Context, token string, roomID, userID uuid.UUID) (bool, error) {
+	consumed, err := r.redis.DeleteIfValue(ctx, redis.ResumeTokenKey(token), resumeTokenValue(roomID, userID))
+	if err != nil {
+		return false, fmt.Errorf("failed to consume resume token: %w", err)
+	}
+	return consumed, nil
+}
+
+func resumeTokenValue(roomID, userID uuid.UUID) string {
+	return roomID.String() + ":" + userID.String()
+}
+
+// AppendAnnotation keeps an annotation in the room's recent annotations, with the same retention as chat
+func (r *syncRepository) AppendAnnotation(ctx context.Context, roomID uuid.UUID, annotation *model.AnnotationMessage) error {
+	maxAnnotations := r.chatConfig.HistoryMaxMessages
+	if maxAnnotations <= 0 {
+		maxAnnotations = config.DefaultChatHistoryMaxMessages
+	}
+
+	ttl := r.chatConfig.HistoryTTL.ToDuration()
+	if ttl <= 0 {
+		ttl = config.DefaultChatHistoryTTL
+	}
+
+	err := r.redis.ListPush(ctx, redis.RoomAnnotationsKey(roomID), annotation, int64(maxAnnotations), ttl)
+	if err != nil {
+		return fmt.Errorf("failed to append annotation: %w", err)
+	}
+
+	return nil
+}
+
+// roomErrorsTTL is how long error counts are kept, so diagnostics only show recent errors
+const roomErrorsTTL = time.Hour
+
+// SetInstanceConnections records how many connections this sync instance holds for a room
+func (r *syncRepository) SetInstanceConnections(ctx context.Context, roomID uuid.UUID, instanceID string, count int) error {
+	connectionsKey := redis.RoomConnectionsKey(roomID)
+
+	if count == 0 {
+		err := r.redis.HDel(ctx, connectionsKey, instanceID)
+		if err != nil {
+			return fmt.Errorf("failed to remove instance connections: %w", err)
+		}
+		return nil
+	}
+
+	err := r.redis.HSet(ctx, connectionsKey, instanceID, strconv.Itoa(count))
+	if err != nil {
+		return fmt.Errorf("failed to set instance connections: %w", err)
+	}
+
+	err = r.redis.Expire(ctx, connectionsKey, 24*time.Hour)
+	if err != nil {
+		return fmt.Errorf("failed to set expiration: %w", err)
+	}
+
+	return nil
+}
+
+// SetUserInstance records which sync instance holds a user's connection to a room
+func (r *syncRepository) SetUserInstance(ctx context.Context, roomID, userID uuid.UUID, instanceID string) error {
+	instancesKey := redis.RoomUserInstancesKey(roomID)
+
+	err := r.redis.HSet(ctx, instancesKey, userID.String(), instanceID)
+	if err != nil {
+		return fmt.Errorf("failed to set user instance: %w", err)
+	}
+
+	err = r.redis.Expire(ctx, instancesKey, 24*time.Hour)
+	if err != nil {
+		return fmt.Errorf("failed to set expiration: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveUserInstance removes a user's routing entry unless the user already reconnected through another instance
+func (r *syncRepository) RemoveUserInstance(ctx context.Context, roomID, userID uuid.UUID, instanceID string) error {
+	current, err := r.GetUserInstance(ctx, roomID, userID)
+	if err != nil {
+		return err
+	}
+	if current != instanceID {
+		return nil
+	}
+
+	err = r.redis.HDel(ctx, redis.RoomUserInstancesKey(roomID), userID.String())
+	if err != nil {
+		return fmt.Errorf("failed to remove user instance: %w", err)
+	}
+	return nil
+}
+
+// GetUserInstance returns the sync instance holding a user's connection, or an empty string when not connected
+func (r *syncRepository) GetUserInstance(ctx context.Context, roomID, userID uuid.UUID) (string, error) {
+	instanceID, err := r.redis.HGet(ctx, redis.RoomUserInstancesKey(roomID), userID.String())
+	if err != nil {
+		if err.Error() == "field not found" {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get user instance: %w", err)
+	}
+	return instanceID, nil
+}
+
+// GetUserInstances returns the sync instance of every connected user in a room, keyed by user ID
+func (r *syncRepository) GetUserInstances(ctx context.Context, roomID uuid.UUID) (map[string]string, error) {
+	instances, err := r.redis.HGetAll(ctx, redis.RoomUserInstancesKey(roomID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user instances: %w", err)
+	}
+	return instances, nil
+}
+
+// ClaimUsername reserves a display name in a room for the user. when the name is held by someone else it returns false
+// with the holder's user ID; force takes the name over, for holders that are no longer connected.
+func (r *syncRepository) ClaimUsername(ctx context.Context, roomID, userID uuid.UUID, username string, force bool) (bool, string, error) {
+	usernamesKey := redis.RoomUsernamesKey(roomID)
+	field := strings.ToLower(username)
+
+	if force {
+		err := r.redis.HSet(ctx, usernamesKey, field, userID.String())
+		if err != nil {
+			return false, "", fmt.Errorf("failed to claim username: %w", err)
+		}
+	} else {
+		claimed, err := r.redis.HSetNX(ctx, usernamesKey, field, userID.String())
+		if err != nil {
+			return false, "", fmt.Errorf("failed to claim username: %w", err)
+		}
+		if !claimed {
+			holder, err := r.redis.HGet(ctx, usernamesKey, field)
+			if err != nil {
+				return false, "", fmt.Errorf("failed to get username holder: %w", err)
+			}
+			if holder != userID.String() {
+				return false, holder, nil
+			}
+		}
+	}
+
+	err := r.redis.Expire(ctx, usernamesKey, 24*time.Hour)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to set expiration: %w", err)
+	}
+
+	return true, userID.String(), nil
+}
+
+// ReleaseUsername frees a display name if the user still holds it
+func (r *syncRepository) ReleaseUsername(ctx context.Context, roomID, userID uuid.UUID, username string) error {
+	usernamesKey := redis.RoomUsernamesKey(roomID)
+	field := strings.ToLower(username)
+
+	holder, err := r.redis.HGet(ctx, usernamesKey, field)
+	if err != nil {
+		if err.Error() == "field not found" {
+			return nil
+		}
+		return fmt.Errorf("failed to get username holder: %w", err)
+	}
+	if holder != userID.String() {
+		return nil
+	}
 
-	var presenceData map[string]interface{}
-	err := r.redis.Get(ctx, presenceKey, &presenceData)
+	err = r.redis.HDel(ctx, usernamesKey, field)
 	if err != nil {
-		return "", fmt.Errorf("failed to get user presence: %w", err)
+		return fmt.Errorf("failed to release username: %w", err)
 	}
+	return nil
+}
+
+// RecordRoomError counts an error sent to a client of the room
+func (r *syncRepository) RecordRoomError(ctx context.Context, roomID uuid.UUID, code string) error {
+	errorsKey := redis.RoomErrorsKey(roomID)
 
-	data, err := json.Marshal(presenceData)
+	_, err := r.redis.HIncrBy(ctx, errorsKey, code, 1)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal presence data: %w", err)
+		return fmt.Errorf("failed to record room error: %w", err)
 	}
 
-	return string(data), nil
+	err = r.redis.Expire(ctx, errorsKey, roomErrorsTTL)
+	if err != nil {
+		return fmt.Errorf("failed to set expiration: %w", err)
+	}
+
+	return nil
 }
 
-// RemoveUserPresence removes user presence information
-func (r *syncRepository) RemoveUserPresence(ctx context.Context, userID uuid.UUID) error {
+// userPresenceTTL is how long a room stays in a user's presence without being refreshed,
+// so rooms of a crashed instance stop counting against the user
+const userPresenceTTL = 60 * time.Second
+
+// SetUserPresence marks the user as active in a room, refreshing the room if it is already recorded
+func (r *syncRepository) SetUserPresence(ctx context.Context, userID, roomID uuid.UUID) error {
 	presenceKey := r.userPresenceKey(userID)
 
-	err := r.redis.Delete(ctx, presenceKey)
+	err := r.redis.ZAdd(ctx, presenceKey, redislib.Z{
+		Score:  float64(time.Now().Unix()),
+		Member: roomID.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set user presence: %w", err)
+	}
+
+	err = r.redis.Expire(ctx, presenceKey, userPresenceTTL)
+	if err != nil {
+		return fmt.Errorf("failed to set expiration: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserRooms returns the rooms a user is currently active in
+func (r *syncRepository) GetUserRooms(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	cutoff := time.Now().Add(-userPresenceTTL).Unix()
+
+	roomIDStrs, err := r.redis.ZRangeByScore(ctx, r.userPresenceKey(userID), &redislib.ZRangeBy{
+		Min: fmt.Sprintf("%d", cutoff),
+		Max: "+inf",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user presence: %w", err)
+	}
+
+	roomIDs := make([]uuid.UUID, 0, len(roomIDStrs))
+	for _, roomIDStr := range roomIDStrs {
+		if roomID, err := uuid.Parse(roomIDStr); err == nil {
+			roomIDs = append(roomIDs, roomID)
+		}
+	}
+
+	return roomIDs, nil
+}
+
+// ClaimUserRoom marks the user as active in a room unless that puts them in more than limit rooms.
+// joins racing on another instance are caught by recounting after the claim.
+func (r *syncRepository) ClaimUserRoom(ctx context.Context, userID, roomID uuid.UUID, limit int) (bool, error) {
+	rooms, err := r.GetUserRooms(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, activeRoomID := range rooms {
+		// another connection to the same room doesn't take another slot
+		if activeRoomID == roomID {
+			return true, r.SetUserPresence(ctx, userID, roomID)
+		}
+	}
+	if len(rooms) >= limit {
+		return false, nil
+	}
+
+	err = r.SetUserPresence(ctx, userID, roomID)
+	if err != nil {
+		return false, err
+	}
+
+	rooms, err = r.GetUserRooms(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if len(rooms) > limit {
+		if err := r.RemoveUserPresence(ctx, userID, roomID); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// RemoveUserPresence marks the user as no longer active in a room
+func (r *syncRepository) RemoveUserPresence(ctx context.Context, userID, roomID uuid.UUID) error {
+	err := r.redis.ZRem(ctx, r.userPresenceKey(userID), roomID.String())
 	if err != nil {
 		return fmt.Errorf("failed to remove user presence: %w", err)
 	}
@@ -363,6 +981,33 @@ func (r *syncRepository) GetActiveRooms(ctx context.Context, limit int64) ([]uui
 	return roomIDs, nil
 }
 
+// GetRoomViewerCounts sums the connections every live sync instance holds for the most recently active rooms.
+// rooms nobody is connected to are left out.
+func (r *syncRepository) GetRoomViewerCounts(ctx context.Context, limit int64) (map[uuid.UUID]int, error) {
+	roomIDs, err := r.GetActiveRooms(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]int)
+	for _, roomID := range roomIDs {
+		instances, err := r.redis.RoomInstances(ctx, roomID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count viewers of room %s: %w", roomID, err)
+		}
+
+		viewers := 0
+		for _, count := range instances {
+			viewers += count
+		}
+		if viewers > 0 {
+			counts[roomID] = viewers
+		}
+	}
+
+	return counts, nil
+}
+
 // CleanupInactiveRooms removes rooms that have been inactive for the specified duration
 func (r *syncRepository) CleanupInactiveRooms(ctx context.Context, inactiveDuration time.Duration) error {
 	cutoffTime := time.Now().Add(-inactiveDuration).Unix()
@@ -386,28 +1031,53 @@ func (r *syncRepository) CleanupInactiveRooms(ctx context.Context, inactiveDurat
 	return nil
 }
 
-// PublishEvent publishes a sync event to the room's event stream
+// PublishEvent publishes a sync event to the room's event stream, numbering it with the room's next sequence
+// number so consumers can tell when they missed events
 func (r *syncRepository) PublishEvent(ctx context.Context, roomID uuid.UUID, event *model.SyncMessage) error {
-	err := r.redis.Publish(ctx, fmt.Sprintf("room:%s:events", roomID.String()), event)
+	// numbered and published in one step, two events racing would otherwise reach subscribers out of order
+	event.Seq = 0
+	seq, err := r.redis.PublishRoomEvent(ctx, roomID, event)
 	if err != nil {
 		return fmt.Errorf("failed to publish event: %w", err)
 	}
+	event.Seq = seq
 
 	return nil
 }
 
+// GetEventSeq returns the sequence number of the room's latest event, 0 before its first event
+func (r *syncRepository) GetEventSeq(ctx context.Context, roomID uuid.UUID) (int64, error) {
+	value, err := r.redis.GetString(ctx, r.roomEventSeqKey(roomID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get event sequence: %w", err)
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	seq, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid event sequence %q: %w", value, err)
+	}
+	return seq, nil
+}
+
 // SubscribeToRoomEvents subscribes to room events
 func (r *syncRepository) SubscribeToRoomEvents(ctx context.Context, roomID uuid.UUID) (*redislib.PubSub, error) {
-	channel := fmt.Sprintf("room:%s:events", roomID.String())
+	channel := redis.RoomEventsChannel(roomID)
 	pubsub := r.redis.Subscribe(ctx, channel)
 	return pubsub, nil
 }
 
-// AcquireRoomLock acquires a lock for a room to prevent conflicts
-func (r *syncRepository) AcquireRoomLock(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) (bool, error) {
+// roomLockTTL frees a room whose lock holder crashed before releasing it
+const roomLockTTL = 5 * time.Second
+
+// AcquireRoomLock acquires a lock for a room to prevent conflicts. token identifies this acquisition, so a holder
+// whose lock expired cannot release the next one, even when both acted for the same user
+func (r *syncRepository) AcquireRoomLock(ctx context.Context, roomID uuid.UUID, token string) (bool, error) {
 	lockKey := r.roomLockKey(roomID)
 
-	acquired, err := r.redis.SetNX(ctx, lockKey, userID.String(), 5*time.Second)
+	acquired, err := r.redis.SetNX(ctx, lockKey, token, roomLockTTL)
 	if err != nil {
 		return false, fmt.Errorf("failed to acquire room lock: %w", err)
 	}
@@ -415,11 +1085,12 @@ func (r *syncRepository) AcquireRoomLock(ctx context.Context, roomID uuid.UUID,
 	return acquired, nil
 }
 
-// ReleaseRoomLock releases a room lock
-func (r *syncRepository) ReleaseRoomLock(ctx context.Context, roomID uuid.UUID) error {
+// ReleaseRoomLock releases a room lock if the acquisition identified by token still holds it, a lock that expired
+// and was taken by someone else is left alone
+func (r *syncRepository) ReleaseRoomLock(ctx context.Context, roomID uuid.UUID, token string) error {
 	lockKey := r.roomLockKey(roomID)
 
-	err := r.redis.Delete(ctx, lockKey)
+	_, err := r.redis.DeleteIfValue(ctx, lockKey, token)
 	if err != nil {
 		return fmt.Errorf("failed to release room lock: %w", err)
 	}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// handleAnnotation lets the host overlay timed text on every participant's player without touching playback
+func (s *syncService) handleAnnotation(ctx context.Context, roomID, userID uuid.UUID, username string, conn *websocket.Conn, rawMessage map[string]interface{}) {
+	hostID, err := s.syncRepo.GetRoomHost(ctx, roomID)
+	if err != nil || hostID != userID {
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "FORBIDDEN", "only the room host can post annotations")
+		return
+	}
+
+	annotation, err := parseAnnotation(rawMessage)
+	if err != nil {
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "INVALID_ANNOTATION", err.Error())
+		return
+	}
+
+	allowed, err := s.syncRepo.AllowAnnotation(ctx, roomID, userID)
+	if err != nil {
+		// the limit protects viewers from spam, it must not block annotations when Redis misbehaves
+		logger.Errorf(err, "failed to check annotation rate in room %s", roomID)
+	} else if !allowed {
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "ANNOTATION_RATE_LIMITED",
+			fmt.Sprintf("wait %s between annotations", model.AnnotationInterval))
+		return
+	}
+
+	annotation.ID = uuid.New()
+	annotation.RoomID = roomID
+	annotation.UserID = userID
+	annotation.Username = username
+	annotation.CreatedAt = time.Now()
+
+	err = s.syncRepo.AppendAnnotation(ctx, roomID, annotation)
+	if err != nil {
+		logger.Errorf(err, "failed to record annotation in room %s", roomID)
+	}
+
+	message := &model.SyncMessage{
+		ID:        annotation.ID,
+		RoomID:    roomID,
+		UserID:    userID,
+		Username:  username,
+		Action:    model.ActionAnnotation,
+		Timestamp: annotation.CreatedAt,
+		Data: model.SyncData{
+			Extra: map[string]interface{}{
+				"annotation": annotation,
+			},
+		},
+	}
+
+	err = s.syncRepo.PublishEvent(ctx, roomID, message)
+	if err != nil {
+		logger.Error(err, "failed to publish annotation to Redis")
+		s.broadcastAnnotation(annotation, 0)
+	}
+}
+
+// parseAnnotation validates the text and display time of an annotation message
+func parseAnnotation(rawMessage map[string]interface{}) (*model.AnnotationMessage, error) {
+	text, _ := rawMessage["text"].(string)
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("annotation text is required")
+	}
+	if utf8.RuneCountInString(text) > model.MaxAnnotationLength {
+		return nil, fmt.Errorf("annotation text must be at most %d characters", model.MaxAnnotationLength)
+	}
+
+	displaySeconds := model.DefaultAnnotationDisplayTime.Seconds()
+	if value, ok := rawMessage["display_seconds"]; ok {
+		seconds, ok := value.(float64)
+		if !ok || seconds <= 0 || seconds > model.MaxAnnotationDisplayTime.Seconds() {
+			return nil, fmt.Errorf("display_seconds must be between 0 and %.0f", model.MaxAnnotationDisplayTime.Seconds())
+		}
+		displaySeconds = seconds
+	}
+
+	annotation := &model.AnnotationMessage{
+		Text:           text,
+		DisplaySeconds: displaySeconds,
+	}
+
+	if value, ok := rawMessage["video_time"]; ok {
+		videoTime, ok := value.(float64)
+		if !ok || videoTime < 0 {
+			return nil, fmt.Errorf("video_time must be a non-negative number of seconds")
+		}
+		annotation.VideoTime = &videoTime
+	}
+
+	return annotation, nil
+}
+
+// handleAnnotationEvent delivers an annotation published by any instance to the local participants
+func (s *syncService) handleAnnotationEvent(syncMessage *model.SyncMessage) {
+	// the annotation went through JSON, decode it back from the generic map
+	var annotation model.AnnotationMessage
+	if err := decodeExtra(syncMessage.Data.Extra["annotation"], &annotation); err != nil {
+		logger.Errorf(err, "invalid annotation event for room %s", syncMessage.RoomID)
+		return
+	}
+
+	s.broadcastAnnotation(&annotation, syncMessage.Seq)
+}
+
+// decodeExtra converts a value of SyncData.Extra, which arrives as generic JSON, into dest
+func decodeExtra(value interface{}, dest interface{}) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, dest)
+}
+
+// broadcastAnnotation sends an annotation to every local connection of its room, including the host's own
+func (s *syncService) broadcastAnnotation(annotation *model.AnnotationMessage, seq int64) {
+	s.broadcastToRoom(annotation.RoomID, &model.WebSocketMessage{
+		Type:    model.MessageTypeAnnotation,
+		Payload: annotation,
+		Seq:     seq,
+	})
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"watch-party/pkg/model"
+)
+
+// handleAnnouncement sends an admin announcement published by service-api to every local connection of its room
+func (s *syncService) handleAnnouncement(syncMessage *model.SyncMessage) {
+	message, _ := syncMessage.Data.Extra["message"].(string)
+	severity, _ := syncMessage.Data.Extra["severity"].(string)
+	if severity == "" {
+		severity = string(model.AnnouncementSeverityInfo)
+	}
+
+	s.broadcastToRoom(syncMessage.RoomID, &model.WebSocketMessage{
+		Type: model.MessageTypeAnnouncement,
+		Payload: model.AnnouncementMessage{
+			ID:       syncMessage.ID,
+			RoomID:   syncMessage.RoomID,
+			Message:  message,
+			Severity: model.AnnouncementSeverity(severity),
+			SentAt:   syncMessage.Timestamp,
+		},
+		Seq: syncMessage.Seq,
+	})
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// ErrBufferNotReady is returned when a play is refused because participants have not buffered enough
+var ErrBufferNotReady = errors.New("waiting for participants to buffer")
+
+// bufferReportMaxAge ignores reports from players that stopped reporting, so a stale client cannot block the room
+const bufferReportMaxAge = 10 * time.Second
+
+// handleBufferReport records how far ahead a participant's player has buffered
+func (s *syncService) handleBufferReport(ctx context.Context, roomID, userID uuid.UUID, conn *websocket.Conn, rawMessage map[string]interface{}) {
+	bufferedAhead, ok := rawMessage["buffered_ahead"].(float64)
+	if !ok || bufferedAhead < 0 {
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "INVALID_BUFFER_REPORT", "buffered_ahead must be a non-negative number of seconds")
+		return
+	}
+	isBuffering, _ := rawMessage["is_buffering"].(bool)
+
+	err := s.syncRepo.UpdateParticipantBuffer(ctx, roomID, userID, bufferedAhead, isBuffering)
+	if err != nil {
+		logger.Errorf(err, "failed to record buffer report of user %s in room %s", userID, roomID)
+	}
+}
+
+// minPlayBuffer returns the buffered-ahead the room requires before play, 0 when the gate is off
+func (s *syncService) minPlayBuffer(ctx context.Context, roomID uuid.UUID) float64 {
+	settings := s.getRoomSettings(ctx, roomID)
+	if settings.MinPlayBufferSeconds != nil {
+		return *settings.MinPlayBufferSeconds
+	}
+	return s.config.Sync.MinPlayBuffer.ToDuration().Seconds()
+}
+
+// checkPlayBufferGate refuses a play while recently reporting participants are below the room's buffer threshold,
+// or, in rooms that wait for buffering, while any of them is still buffering. the host or a co-host can send
+// force=true to start anyway.
+func (s *syncService) checkPlayBufferGate(ctx context.Context, message *model.SyncMessage) error {
+	waitForBuffering := s.getRoomSettings(ctx, message.RoomID).WaitForBuffering
+	threshold := s.minPlayBuffer(ctx, message.RoomID)
+	// near the end of the movie there is less left to buffer than the threshold
+	if threshold > 0 {
+		if remaining, ok := s.remainingPlayback(ctx, message); ok && remaining < threshold {
+			threshold = remaining
+		}
+	}
+	if threshold <= 0 && !waitForBuffering {
+		return nil
+	}
+
+	if force, _ := message.Data.Extra["force"].(bool); force {
+		hostID, err := s.syncRepo.GetRoomHost(ctx, message.RoomID)
+		if err == nil && (hostID == message.UserID || s.canControlPlayback(ctx, message.RoomID, message.UserID)) {
+			logger.Infof("%s started playback in room %s without waiting for buffers", message.UserID, message.RoomID)
+			return nil
+		}
+	}
+
+	participants, err := s.syncRepo.GetParticipants(ctx, message.RoomID)
+	if err != nil {
+		// the gate smooths starts, it must not block playback when Redis misbehaves
+		logger.Errorf(err, "failed to check participant buffers in room %s", message.RoomID)
+		return nil
+	}
+	reports, err := s.syncRepo.GetBufferReports(ctx, message.RoomID)
+	if err != nil {
+		logger.Errorf(err, "failed to check participant buffers in room %s", message.RoomID)
+		return nil
+	}
+
+	now := time.Now()
+	var waiting []string
+	for _, participant := range participants {
+		// players that never reported, or stopped reporting, do not hold the room back
+		report, ok := reports[participant.UserID]
+		if !ok || now.Sub(report.ReportedAt) > bufferReportMaxAge {
+			continue
+		}
+		if (threshold > 0 && report.BufferedAhead < threshold) || (waitForBuffering && report.IsBuffering) {
+			waiting = append(waiting, participant.Username)
+		}
+	}
+
+	if len(waiting) > 0 {
+		return fmt.Errorf("%w: %s", ErrBufferNotReady, strings.Join(waiting, ", "))
+	}
+	return nil
+}
+
+// remainingPlayback returns how much of the movie is left after the position the play starts from, false when the
+// duration is unknown
+func (s *syncService) remainingPlayback(ctx context.Context, message *model.SyncMessage) (float64, bool) {
+	duration := message.Data.Duration
+	if duration <= 0 {
+		state, ok := s.loadRoomState(ctx, message.RoomID)
+		if !ok || state.Duration <= 0 {
+			return 0, false
+		}
+		duration = state.Duration
+	}
+	return duration - message.Data.CurrentTime, true
+}
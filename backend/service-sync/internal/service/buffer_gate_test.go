@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlayBufferGate(t *testing.T) {
+	s, roomID, hostID, _ := newRouterTestService(t)
+	ctx := context.Background()
+
+	settings := model.DefaultRoomSettings()
+	minBuffer := 10.0
+	settings.MinPlayBufferSeconds = &minBuffer
+	require.NoError(t, s.redis.Set(ctx, redis.RoomSettingsKey(roomID), settings, time.Minute))
+
+	var viewerID uuid.UUID
+	for userID := range s.connections[roomID] {
+		if userID != hostID {
+			viewerID = userID
+		}
+	}
+
+	require.NoError(t, s.syncRepo.SetParticipantRole(ctx, roomID, viewerID, model.RoomRoleCoHost))
+	require.NoError(t, s.syncRepo.UpdateParticipantBuffer(ctx, roomID, viewerID, 3, false))
+
+	play := func(currentTime, duration float64) *model.SyncMessage {
+		return &model.SyncMessage{
+			RoomID: roomID,
+			UserID: hostID,
+			Action: model.ActionPlay,
+			Data:   model.SyncData{CurrentTime: currentTime, Duration: duration},
+		}
+	}
+	assert.ErrorIs(t, s.checkPlayBufferGate(ctx, play(0, 100)), ErrBufferNotReady)
+
+	// three seconds before the end there are only three seconds to buffer
+	assert.NoError(t, s.checkPlayBufferGate(ctx, play(97, 100)))
+	assert.ErrorIs(t, s.checkPlayBufferGate(ctx, play(95, 100)), ErrBufferNotReady)
+
+	// the duration of the room state is used when the play does not carry one
+	require.NoError(t, s.syncRepo.SetRoomState(ctx, &model.RoomState{RoomID: roomID, CurrentTime: 97, Duration: 100, PlaybackRate: 1}))
+	assert.NoError(t, s.checkPlayBufferGate(ctx, play(97, 0)))
+
+	// buffer reports are kept apart from the participant entry and leave it untouched
+	participant, err := s.findParticipant(ctx, roomID, viewerID)
+	require.NoError(t, err)
+	require.NotNil(t, participant)
+	assert.Equal(t, model.RoomRoleCoHost, participant.Role)
+}
+
+func TestPlayWaitsForBuffering(t *testing.T) {
+	s, roomID, hostID, _ := newRouterTestService(t)
+	ctx := context.Background()
+
+	var viewerID uuid.UUID
+	for userID := range s.connections[roomID] {
+		if userID != hostID {
+			viewerID = userID
+		}
+	}
+	play := &model.SyncMessage{RoomID: roomID, UserID: hostID, Action: model.ActionPlay}
+
+	// without a buffer threshold a stalled player only holds the room back when the host asked for it
+	require.NoError(t, s.syncRepo.UpdateParticipantBuffer(ctx, roomID, viewerID, 30, true))
+	assert.NoError(t, s.checkPlayBufferGate(ctx, play))
+
+	settings := model.DefaultRoomSettings()
+	settings.WaitForBuffering = true
+	require.NoError(t, s.redis.Set(ctx, redis.RoomSettingsKey(roomID), settings, time.Minute))
+	assert.ErrorIs(t, s.checkPlayBufferGate(ctx, play), ErrBufferNotReady)
+	assert.ErrorIs(t, s.SyncAction(ctx, play), ErrBufferNotReady)
+
+	forced := *play
+	forced.Data.Extra = map[string]interface{}{"force": true}
+	assert.NoError(t, s.checkPlayBufferGate(ctx, &forced), "the host can start anyway")
+
+	require.NoError(t, s.syncRepo.UpdateParticipantBuffer(ctx, roomID, viewerID, 30, false))
+	assert.NoError(t, s.checkPlayBufferGate(ctx, play))
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"net"
+	"sync"
+
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// connectionCloses remembers why the server closed a connection until its handler finishes, and counts why the
+// connections of this instance closed
+type connectionCloses struct {
+	mu     sync.Mutex
+	open   map[*websocket.Conn]model.CloseReason // empty reason until the server decides to close the connection
+	counts map[model.CloseReason]int64
+}
+
+func newConnectionCloses() *connectionCloses {
+	return &connectionCloses{
+		open:   make(map[*websocket.Conn]model.CloseReason),
+		counts: make(map[model.CloseReason]int64),
+	}
+}
+
+// watch tracks a connection from the moment it is registered until finish is called for it
+func (c *connectionCloses) watch(conn *websocket.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.open[conn] = ""
+}
+
+// mark records why the server is about to close a connection, the first reason given wins
+func (c *connectionCloses) mark(conn *websocket.Conn, reason model.CloseReason) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if current, watched := c.open[conn]; watched && current == "" {
+		c.open[conn] = reason
+	}
+}
+
+// finish counts a closed connection and returns why it closed, a reason the server marked wins over the error its
+// read loop ended with. false means the connection was already counted
+func (c *connectionCloses) finish(conn *websocket.Conn, readErr error) (model.CloseReason, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	reason, watched := c.open[conn]
+	if !watched {
+		return "", false
+	}
+	delete(c.open, conn)
+
+	if reason == "" {
+		reason = classifyReadError(readErr)
+	}
+	c.counts[reason]++
+	return reason, true
+}
+
+// reason returns why a watched connection is closing without counting it, like finish would report it
+func (c *connectionCloses) reason(conn *websocket.Conn, readErr error) model.CloseReason {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if reason := c.open[conn]; reason != "" {
+		return reason
+	}
+	return classifyReadError(readErr)
+}
+
+// count records a connection refused before it was ever watched
+func (c *connectionCloses) count(reason model.CloseReason) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts[reason]++
+}
+
+// snapshot returns a copy of the close counts
+func (c *connectionCloses) snapshot() map[model.CloseReason]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := make(map[model.CloseReason]int64, len(c.counts))
+	for reason, count := range c.counts {
+		counts[reason] = count
+	}
+	return counts
+}
+
+// classifyReadError tells a client closing the connection apart from a read deadline passing or the read failing
+func classifyReadError(err error) model.CloseReason {
+	if err == nil || websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+		return model.CloseReasonNormal
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return model.CloseReasonIdleTimeout
+	}
+	return model.CloseReasonReadError
+}
+
+// closeConnection marks why the server closes a connection before closing it, its handler logs the reason
+func (s *syncService) closeConnection(conn *websocket.Conn, reason model.CloseReason) error {
+	s.connCloses.mark(conn, reason)
+	return conn.Close()
+}
+
+// finishConnection counts and logs why a connection of the room closed
+func (s *syncService) finishConnection(roomID, userID uuid.UUID, conn *websocket.Conn, readErr error) {
+	reason, counted := s.connCloses.finish(conn, readErr)
+	if counted {
+		logConnectionClose(roomID, userID, reason)
+	}
+}
+
+// refuseConnection counts and logs a connection refused before it joined the room
+func (s *syncService) refuseConnection(roomID, userID uuid.UUID, reason model.CloseReason) {
+	s.connCloses.count(reason)
+	logConnectionClose(roomID, userID, reason)
+}
+
+func logConnectionClose(roomID, userID uuid.UUID, reason model.CloseReason) {
+	logger.InfoFields("sync connection closed", map[string]interface{}{
+		"room_id":      roomID.String(),
+		"user_id":      userID.String(),
+		"close_reason": string(reason),
+	})
+}
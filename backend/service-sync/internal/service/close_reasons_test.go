@@ -0,0 +1,52 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"watch-party/pkg/model"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// timeoutError is a net.Error whose deadline passed
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyReadError(t *testing.T) {
+	assert.Equal(t, model.CloseReasonNormal, classifyReadError(&websocket.CloseError{Code: websocket.CloseGoingAway}))
+	assert.Equal(t, model.CloseReasonIdleTimeout, classifyReadError(timeoutError{}))
+	assert.Equal(t, model.CloseReasonReadError, classifyReadError(&websocket.CloseError{Code: websocket.CloseAbnormalClosure}))
+	assert.Equal(t, model.CloseReasonReadError, classifyReadError(errors.New("connection reset by peer")))
+}
+
+func TestConnectionCloses(t *testing.T) {
+	closes := newConnectionCloses()
+	marked, unmarked := &websocket.Conn{}, &websocket.Conn{}
+	closes.watch(marked)
+	closes.watch(unmarked)
+
+	closes.mark(marked, model.CloseReasonKicked)
+	closes.mark(marked, model.CloseReasonSendFailures)
+	reason, counted := closes.finish(marked, errors.New("use of closed network connection"))
+	assert.True(t, counted)
+	assert.Equal(t, model.CloseReasonKicked, reason, "the first marked reason wins over the read error")
+
+	_, counted = closes.finish(marked, nil)
+	assert.False(t, counted, "a connection is counted once")
+
+	reason, _ = closes.finish(unmarked, &websocket.CloseError{Code: websocket.CloseNormalClosure})
+	assert.Equal(t, model.CloseReasonNormal, reason)
+
+	closes.mark(unmarked, model.CloseReasonKicked)
+	closes.count(model.CloseReasonMaxParticipants)
+	assert.Equal(t, map[model.CloseReason]int64{
+		model.CloseReasonKicked:          1,
+		model.CloseReasonNormal:          1,
+		model.CloseReasonMaxParticipants: 1,
+	}, closes.snapshot())
+}
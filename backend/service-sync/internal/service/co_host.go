@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+)
+
+// ErrControlNotAllowed is returned when a participant tries to control playback in a host-only room
+var ErrControlNotAllowed = errors.New("only the host and co-hosts can control playback in this room")
+
+// participantRole returns the role a joining participant is shown with
+func (s *syncService) participantRole(ctx context.Context, roomID, userID uuid.UUID, isHost, isGuest bool) string {
+	if isHost {
+		return model.RoomRoleHost
+	}
+	if isGuest {
+		return model.RoomRoleMember
+	}
+
+	isCoHost, err := s.syncRepo.IsRoomCoHost(ctx, roomID, userID)
+	if err != nil {
+		logger.Errorf(err, "failed to check co-host %s in room %s", userID, roomID)
+		return model.RoomRoleMember
+	}
+	if isCoHost {
+		return model.RoomRoleCoHost
+	}
+	return model.RoomRoleMember
+}
+
+// canControlPlayback reports whether a user is the room's host or one of its co-hosts. while Redis is unavailable
+// the last known host keeps control, nobody does when the host was never read
+func (s *syncService) canControlPlayback(ctx context.Context, roomID, userID uuid.UUID) bool {
+	hostID, ok := s.loadRoomHost(ctx, roomID)
+	if ok && hostID == userID {
+		return true
+	}
+	if s.degraded.active() {
+		// co-hosts live in Redis only
+		return false
+	}
+
+	isCoHost, err := s.syncRepo.IsRoomCoHost(ctx, roomID, userID)
+	if err != nil {
+		logger.Errorf(err, "failed to check co-host %s in room %s", userID, roomID)
+		return false
+	}
+	return isCoHost
+}
+
+// checkControlPermission refuses playback changes from plain participants when the room is host-only
+func (s *syncService) checkControlPermission(ctx context.Context, message *model.SyncMessage) error {
+	switch message.Action {
+	case model.ActionPlay, model.ActionPause, model.ActionSeek:
+	default:
+		return nil
+	}
+
+	// a room whose settings are unknown is treated as host-only rather than opened to everyone
+	settings, ok := s.loadRoomSettings(ctx, message.RoomID)
+	if ok && settings.ControlMode != model.ControlModeHostOnly {
+		return nil
+	}
+	if s.canControlPlayback(ctx, message.RoomID, message.UserID) {
+		return nil
+	}
+	return ErrControlNotAllowed
+}
+
+// handleRoleChanged applies a co-host grant or revocation made in service-api and refreshes participant lists
+func (s *syncService) handleRoleChanged(ctx context.Context, syncMessage *model.SyncMessage, hasLocalConnections bool) {
+	userIDStr, _ := syncMessage.Data.Extra["user_id"].(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		logger.Errorf(err, "invalid user in role_changed event for room %s", syncMessage.RoomID)
+		return
+	}
+	role, _ := syncMessage.Data.Extra["role"].(string)
+
+	// every instance receives the event, rewriting the same role is harmless
+	err = s.syncRepo.SetParticipantRole(ctx, syncMessage.RoomID, userID, role)
+	if err != nil {
+		logger.Errorf(err, "failed to update role of %s in room %s", userID, syncMessage.RoomID)
+	}
+
+	if !hasLocalConnections {
+		return
+	}
+
+	participants, err := s.syncRepo.GetParticipants(ctx, syncMessage.RoomID)
+	if err != nil {
+		logger.Errorf(err, "failed to get participants for room %s", syncMessage.RoomID)
+		return
+	}
+
+	s.broadcastToRoom(syncMessage.RoomID, &model.WebSocketMessage{
+		Type:    model.MessageTypeParticipants,
+		Payload: participants,
+	})
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+)
+
+// redisProbeInterval is how often Redis is checked, to notice an outage between actions and to leave degraded
+// mode once it answers again
+const redisProbeInterval = 5 * time.Second
+
+// degradedMode tracks a Redis outage. while degraded, playback actions are ordered by this instance's lock queue
+// instead of the Redis lock, applied to in-memory room states and broadcast to local connections only. that keeps
+// each instance correct on its own, participants connected to different instances drift apart until Redis is back.
+// the last settings and host read of each room are kept too, so host-only rooms and rooms without chat stay that way.
+type degradedMode struct {
+	mu       sync.Mutex
+	since    time.Time                         // zero while Redis is reachable
+	states   map[uuid.UUID]*model.RoomState    // latest state of rooms this instance changed, the fallback when Redis has none
+	dirty    map[uuid.UUID]bool                // rooms changed while degraded, written back to Redis on recovery
+	settings map[uuid.UUID]*model.RoomSettings // last settings read of each room
+	hosts    map[uuid.UUID]uuid.UUID           // last host read of each room
+}
+
+func newDegradedMode() *degradedMode {
+	return &degradedMode{
+		states:   make(map[uuid.UUID]*model.RoomState),
+		dirty:    make(map[uuid.UUID]bool),
+		settings: make(map[uuid.UUID]*model.RoomSettings),
+		hosts:    make(map[uuid.UUID]uuid.UUID),
+	}
+}
+
+// activeSince returns when Redis became unavailable, zero while it is reachable
+func (d *degradedMode) activeSince() time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.since
+}
+
+func (d *degradedMode) active() bool {
+	return !d.activeSince().IsZero()
+}
+
+// enter marks Redis unavailable and reports whether it was reachable until now
+func (d *degradedMode) enter() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.since.IsZero() {
+		return false
+	}
+	d.since = time.Now()
+	return true
+}
+
+// leave marks Redis reachable again and returns the states changed during the outage, false when not degraded
+func (d *degradedMode) leave() ([]*model.RoomState, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.since.IsZero() {
+		return nil, false
+	}
+	d.since = time.Time{}
+
+	states := make([]*model.RoomState, 0, len(d.dirty))
+	for roomID := range d.dirty {
+		state := *d.states[roomID]
+		states = append(states, &state)
+	}
+	d.dirty = make(map[uuid.UUID]bool)
+	return states, true
+}
+
+// remember keeps a copy of the room's latest state, dirty when Redis did not get it
+func (d *degradedMode) remember(state *model.RoomState, dirty bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stored := *state
+	d.states[state.RoomID] = &stored
+	if dirty {
+		d.dirty[state.RoomID] = true
+	}
+}
+
+// roomState returns a copy of the room's remembered state
+func (d *degradedMode) roomState(roomID uuid.UUID) (*model.RoomState, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stored, ok := d.states[roomID]
+	if !ok {
+		return nil, false
+	}
+	state := *stored
+	return &state, true
+}
+
+// rememberSettings keeps a copy of the settings last read for a room
+func (d *degradedMode) rememberSettings(roomID uuid.UUID, settings *model.RoomSettings) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stored := *settings
+	d.settings[roomID] = &stored
+}
+
+// roomSettings returns a copy of the room's remembered settings
+func (d *degradedMode) roomSettings(roomID uuid.UUID) (*model.RoomSettings, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stored, ok := d.settings[roomID]
+	if !ok {
+		return nil, false
+	}
+	settings := *stored
+	return &settings, true
+}
+
+// rememberHost keeps the host last read for a room
+func (d *degradedMode) rememberHost(roomID, hostID uuid.UUID) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.hosts[roomID] = hostID
+}
+
+// roomHost returns the room's remembered host
+func (d *degradedMode) roomHost(roomID uuid.UUID) (uuid.UUID, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	hostID, ok := d.hosts[roomID]
+	return hostID, ok
+}
+
+// forget drops a room this instance no longer serves, unless its state still has to reach Redis
+func (d *degradedMode) forget(roomID uuid.UUID) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.dirty[roomID] {
+		delete(d.states, roomID)
+	}
+	delete(d.settings, roomID)
+	delete(d.hosts, roomID)
+}
+
+// DegradedSince returns when this instance lost Redis and started syncing rooms locally, zero while Redis is reachable
+func (s *syncService) DegradedSince() time.Time {
+	return s.degraded.activeSince()
+}
+
+// enterDegradedMode switches to local-only sync after a Redis failure
+func (s *syncService) enterDegradedMode(err error) {
+	if s.degraded.enter() {
+		logger.Errorf(err, "⚠️ REDIS UNAVAILABLE: sync instance %s is in degraded mode, rooms are synced only between "+
+			"participants connected to this instance until Redis recovers", s.instanceID)
+	}
+}
+
+// loadRoomState reads the room state from Redis, falling back to the last state this instance stored
+func (s *syncService) loadRoomState(ctx context.Context, roomID uuid.UUID) (*model.RoomState, bool) {
+	if !s.degraded.active() {
+		state, err := s.syncRepo.GetRoomState(ctx, roomID)
+		if err == nil {
+			return state, true
+		}
+	}
+	return s.degraded.roomState(roomID)
+}
+
+// loadRoomSettings reads the room settings from Redis, falling back to the last settings this instance read. false
+// when neither is available, callers enforcing a setting must then refuse
+func (s *syncService) loadRoomSettings(ctx context.Context, roomID uuid.UUID) (*model.RoomSettings, bool) {
+	if !s.degraded.active() {
+		settings, err := s.syncRepo.GetRoomSettings(ctx, roomID)
+		if err == nil {
+			s.degraded.rememberSettings(roomID, settings)
+			return settings, true
+		}
+	}
+	return s.degraded.roomSettings(roomID)
+}
+
+// loadRoomHost reads the room host from Redis, falling back to the last host this instance read
+func (s *syncService) loadRoomHost(ctx context.Context, roomID uuid.UUID) (uuid.UUID, bool) {
+	if !s.degraded.active() {
+		hostID, err := s.syncRepo.GetRoomHost(ctx, roomID)
+		if err == nil {
+			s.degraded.rememberHost(roomID, hostID)
+			return hostID, true
+		}
+	}
+	return s.degraded.roomHost(roomID)
+}
+
+// saveRoomState stores the room state in Redis and in memory, only in memory while Redis is unavailable
+func (s *syncService) saveRoomState(ctx context.Context, state *model.RoomState) {
+	if !s.degraded.active() {
+		err := s.syncRepo.SetRoomState(ctx, state)
+		if err == nil {
+			s.degraded.remember(state, false)
+			return
+		}
+		s.enterDegradedMode(err)
+	}
+	s.degraded.remember(state, true)
+}
+
+// runRedisProbe checks Redis every redisProbeInterval until ctx is cancelled
+func (s *syncService) runRedisProbe(ctx context.Context) {
+	ticker := time.NewTicker(redisProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.probeRedis(ctx)
+		}
+	}
+}
+
+// probeRedis enters degraded mode when Redis stops answering and recovers from it once Redis answers again
+func (s *syncService) probeRedis(ctx context.Context) {
+	probeCtx, cancel := context.WithTimeout(ctx, redisProbeInterval)
+	defer cancel()
+
+	err := s.redis.Ping(probeCtx)
+	if err != nil {
+		s.enterDegradedMode(err)
+		return
+	}
+	s.recoverFromDegradedMode(ctx)
+}
+
+// recoverFromDegradedMode writes the states changed during the outage back to Redis, unless another instance
+// stored a newer one, then snaps every local participant to the state Redis now holds
+func (s *syncService) recoverFromDegradedMode(ctx context.Context) {
+	since := s.degraded.activeSince()
+	states, ok := s.degraded.leave()
+	if !ok {
+		return
+	}
+	logger.Infof("✅ REDIS RECOVERED: sync instance %s leaves degraded mode after %s, restoring %d room states",
+		s.instanceID, time.Since(since).Round(time.Second), len(states))
+
+	for _, state := range states {
+		stored, err := s.syncRepo.GetRoomState(ctx, state.RoomID)
+		if err == nil && stored.LastUpdated.After(state.LastUpdated) {
+			continue
+		}
+		err = s.syncRepo.SetRoomState(ctx, state)
+		if err != nil {
+			logger.Errorf(err, "failed to restore state of room %s after Redis recovered", state.RoomID)
+		}
+	}
+
+	// registrations and presence may have been lost with Redis
+	s.sendInstanceHeartbeat(ctx)
+	for roomID := range s.GetConnectionStats().Rooms {
+		s.broadcastAuthoritativeState(ctx, roomID)
+	}
+}
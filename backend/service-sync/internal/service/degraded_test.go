@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"watch-party/pkg/model"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncActionWithoutRedis(t *testing.T) {
+	server := miniredis.RunT(t)
+	s, roomID, hostID, _ := newRouterTestServiceOn(t, server)
+	ctx := context.Background()
+
+	// the settings and host read while participants connected outlive Redis
+	s.getRoomSettings(ctx, roomID)
+	_, ok := s.loadRoomHost(ctx, roomID)
+	require.True(t, ok)
+
+	addr := server.Addr()
+	server.Close()
+
+	err := s.SyncAction(ctx, &model.SyncMessage{
+		RoomID:    roomID,
+		UserID:    hostID,
+		Action:    model.ActionPlay,
+		Data:      model.SyncData{CurrentTime: 42},
+		Timestamp: time.Now(),
+	})
+	require.NoError(t, err, "playback keeps working on this instance")
+	assert.False(t, s.DegradedSince().IsZero())
+
+	// a host-only room keeps its host in control
+	settings, ok := s.loadRoomSettings(ctx, roomID)
+	require.True(t, ok)
+	assert.Equal(t, model.ControlModeFree, settings.ControlMode)
+	hostOnly := *settings
+	hostOnly.ControlMode = model.ControlModeHostOnly
+	s.degraded.rememberSettings(roomID, &hostOnly)
+	viewerPause := &model.SyncMessage{RoomID: roomID, UserID: uuid.New(), Action: model.ActionPause, Timestamp: time.Now()}
+	assert.ErrorIs(t, s.checkControlPermission(ctx, viewerPause), ErrControlNotAllowed)
+	assert.NoError(t, s.checkControlPermission(ctx, &model.SyncMessage{RoomID: roomID, UserID: hostID, Action: model.ActionPause}))
+	s.degraded.rememberSettings(roomID, settings)
+
+	// a room whose settings and host were never read is not opened to everyone
+	unknown := uuid.New()
+	assert.ErrorIs(t, s.checkControlPermission(ctx, &model.SyncMessage{RoomID: unknown, UserID: hostID, Action: model.ActionPlay}), ErrControlNotAllowed)
+	assert.ErrorIs(t, s.checkChatEnabled(ctx, &model.SyncMessage{RoomID: unknown, UserID: hostID, Action: model.ActionChat}), ErrChatDisabled)
+
+	state, ok := s.loadRoomState(ctx, roomID)
+	require.True(t, ok)
+	assert.True(t, state.IsPlaying)
+	assert.Equal(t, 42.0, state.CurrentTime)
+
+	// the state changed during the outage reaches Redis once it is back
+	require.NoError(t, server.StartAddr(addr))
+	// the client backs off from redialing for a moment after failed dials
+	require.Eventually(t, func() bool {
+		s.probeRedis(ctx)
+		return s.DegradedSince().IsZero()
+	}, 5*time.Second, 100*time.Millisecond)
+
+	stored, err := s.syncRepo.GetRoomState(ctx, roomID)
+	require.NoError(t, err)
+	assert.True(t, stored.IsPlaying)
+	assert.Equal(t, 42.0, stored.CurrentTime)
+}
+
+func TestDegradedModeForget(t *testing.T) {
+	d := newDegradedMode()
+	synced, pending := uuid.New(), uuid.New()
+
+	d.remember(&model.RoomState{RoomID: synced}, false)
+	d.remember(&model.RoomState{RoomID: pending}, true)
+	d.forget(synced)
+	d.forget(pending)
+
+	_, ok := d.roomState(synced)
+	assert.False(t, ok)
+	_, ok = d.roomState(pending)
+	assert.True(t, ok, "a state Redis has not seen is kept until it is restored")
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"watch-party/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// syncTolerance returns how far a participant may drift from the room position before being resynced, 0 when
+// correction is off
+func (s *syncService) syncTolerance(ctx context.Context, roomID uuid.UUID) float64 {
+	settings := s.getRoomSettings(ctx, roomID)
+	if settings.SyncToleranceSeconds != nil {
+		return *settings.SyncToleranceSeconds
+	}
+	return s.config.Sync.SyncTolerance.ToDuration().Seconds()
+}
+
+// exceedsSyncTolerance reports whether a reported position drifted further from the expected one than the
+// tolerance allows. drift within the tolerance is left to the player, nudging it only causes jitter
+func exceedsSyncTolerance(reported, expected, tolerance float64) bool {
+	return tolerance > 0 && math.Abs(reported-expected) > tolerance
+}
+
+// correctDrift compares the position a participant reported in a heartbeat with the extrapolated room position and
+// sends only that participant an authoritative state when it drifted beyond the room's tolerance
+func (s *syncService) correctDrift(ctx context.Context, roomID, userID uuid.UUID, conn *websocket.Conn, rawMessage map[string]interface{}) {
+	reported, ok := rawMessage["current_time"].(float64)
+	if !ok || reported < 0 {
+		return
+	}
+	// a buffering player is behind on purpose, the play gate handles it
+	if isBuffering, _ := rawMessage["is_buffering"].(bool); isBuffering {
+		return
+	}
+
+	tolerance := s.syncTolerance(ctx, roomID)
+	if tolerance <= 0 {
+		return
+	}
+
+	state, err := s.syncRepo.GetRoomState(ctx, roomID)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	expected := state.ProjectedTime(now)
+	if !exceedsSyncTolerance(reported, expected, tolerance) {
+		return
+	}
+
+	state.CurrentTime = expected
+	state.LastUpdated = now
+	state.Authoritative = true
+
+	logger.Infof("user %s in room %s drifted %.2fs from the room position, resyncing", userID, roomID, reported-expected)
+	if err := s.sendToConnectionSafe(roomID, userID, conn, s.stateMessage(ctx, roomID, state)); err != nil {
+		logger.Errorf(err, "failed to resync drifted user %s", userID)
+	}
+}
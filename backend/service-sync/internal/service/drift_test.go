@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExceedsSyncTolerance(t *testing.T) {
+	tests := []struct {
+		name      string
+		reported  float64
+		expected  float64
+		tolerance float64
+		want      bool
+	}{
+		{name: "within tolerance", reported: 10.3, expected: 10, tolerance: 0.5, want: false},
+		{name: "ahead beyond tolerance", reported: 11, expected: 10, tolerance: 0.5, want: true},
+		{name: "behind beyond tolerance", reported: 9, expected: 10, tolerance: 0.5, want: true},
+		{name: "correction disabled", reported: 30, expected: 10, tolerance: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, exceedsSyncTolerance(tt.reported, tt.expected, tt.tolerance))
+		})
+	}
+}
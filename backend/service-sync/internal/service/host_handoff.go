@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+)
+
+// scheduleHostHandoff starts the grace period after the host of a room disconnects
+func (s *syncService) scheduleHostHandoff(ctx context.Context, roomID, userID uuid.UUID) {
+	if !s.config.HostHandoff.AutoHandoffEnabled {
+		return
+	}
+
+	// only host-only rooms freeze without a host, and hosts may opt out per room
+	settings := s.getRoomSettings(ctx, roomID)
+	if settings.ControlMode != model.ControlModeHostOnly || !settings.AutoHandoff {
+		return
+	}
+
+	hostID, err := s.syncRepo.GetRoomHost(ctx, roomID)
+	if err != nil || hostID != userID {
+		return
+	}
+
+	gracePeriod := s.config.HostHandoff.GracePeriod.ToDuration()
+
+	s.handoffMutex.Lock()
+	defer s.handoffMutex.Unlock()
+
+	if existing, ok := s.pendingHandoffs[roomID]; ok {
+		existing.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(gracePeriod, func() {
+		s.handoffMutex.Lock()
+		if s.pendingHandoffs[roomID] == timer {
+			delete(s.pendingHandoffs, roomID)
+		}
+		s.handoffMutex.Unlock()
+
+		s.performHostHandoff(context.Background(), roomID, userID)
+	})
+	s.pendingHandoffs[roomID] = timer
+
+	logger.Infof("host %s left room %s, handing off control in %s unless they return", userID, roomID, gracePeriod)
+}
+
+// cancelHostHandoff stops a pending handoff because the host reconnected in time
+func (s *syncService) cancelHostHandoff(roomID uuid.UUID) {
+	s.handoffMutex.Lock()
+	defer s.handoffMutex.Unlock()
+
+	timer, ok := s.pendingHandoffs[roomID]
+	if !ok {
+		return
+	}
+
+	timer.Stop()
+	delete(s.pendingHandoffs, roomID)
+
+	logger.Infof("host reclaimed room %s within the grace period", roomID)
+}
+
+// performHostHandoff promotes the longest-present registered participant once the grace period has passed
+func (s *syncService) performHostHandoff(ctx context.Context, roomID, previousHostID uuid.UUID) {
+	// the host may have been changed manually while we were waiting
+	hostID, err := s.syncRepo.GetRoomHost(ctx, roomID)
+	if err != nil || hostID != previousHostID {
+		return
+	}
+
+	participants, err := s.syncRepo.GetParticipants(ctx, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to get participants for host handoff in room %s", roomID)
+		return
+	}
+
+	var newHost *model.ParticipantInfo
+	for i := range participants {
+		participant := &participants[i]
+
+		// the host reconnected through another instance
+		if participant.UserID == previousHostID {
+			return
+		}
+
+		// guests have no account and cannot own the room
+		if participant.IsGuest {
+			continue
+		}
+
+		if newHost == nil || participant.JoinedAt.Before(newHost.JoinedAt) {
+			newHost = participant
+		}
+	}
+
+	if newHost == nil {
+		logger.Infof("no eligible participant to take over room %s", roomID)
+		return
+	}
+
+	err = s.syncRepo.SetRoomHost(ctx, roomID, newHost.UserID)
+	if err != nil {
+		logger.Errorf(err, "failed to hand off host in room %s", roomID)
+		return
+	}
+
+	now := time.Now()
+
+	// let every sync instance update flags and notify its participants
+	err = s.syncRepo.PublishEvent(ctx, roomID, &model.SyncMessage{
+		ID:        uuid.New(),
+		RoomID:    roomID,
+		Action:    model.ActionHostChanged,
+		Timestamp: now,
+		Data: model.SyncData{
+			Extra: map[string]interface{}{
+				"previous_host_id": previousHostID.String(),
+				"new_host_id":      newHost.UserID.String(),
+				"reason":           model.HostChangeReasonAutoHandoff,
+			},
+		},
+	})
+	if err != nil {
+		logger.Errorf(err, "failed to publish host handoff for room %s", roomID)
+	}
+
+	// service-api persists the new host and records the audit entry
+	err = s.redis.Publish(ctx, redis.RoomHostHandoffChannel, &model.HostChangedMessage{
+		RoomID:         roomID,
+		PreviousHostID: previousHostID,
+		NewHostID:      newHost.UserID,
+		ChangedAt:      now,
+		Reason:         model.HostChangeReasonAutoHandoff,
+	})
+	if err != nil {
+		logger.Errorf(err, "failed to report host handoff for room %s", roomID)
+	}
+
+	logger.Infof("host of room %s handed off from %s to %s (%s)", roomID, previousHostID, newHost.UserID, newHost.Username)
+}
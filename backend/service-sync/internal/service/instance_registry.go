@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// instanceHeartbeatInterval is how often this instance refreshes its registration
+	instanceHeartbeatInterval = 10 * time.Second
+	// instanceHeartbeatTTL lets registrations of a crashed instance expire after a few missed heartbeats
+	instanceHeartbeatTTL = 3 * instanceHeartbeatInterval
+)
+
+// runInstanceHeartbeat keeps this instance's registration alive until ctx is cancelled
+func (s *syncService) runInstanceHeartbeat(ctx context.Context) {
+	s.sendInstanceHeartbeat(ctx)
+
+	ticker := time.NewTicker(instanceHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sendInstanceHeartbeat(ctx)
+		}
+	}
+}
+
+// sendInstanceHeartbeat refreshes the instance key and re-registers the rooms it serves
+func (s *syncService) sendInstanceHeartbeat(ctx context.Context) {
+	stats := s.GetConnectionStats()
+
+	info := &model.SyncInstanceInfo{
+		InstanceID:       s.instanceID,
+		StartedAt:        s.startedAt,
+		LastHeartbeat:    time.Now(),
+		TotalConnections: stats.TotalConnections,
+		Rooms:            len(stats.Rooms),
+	}
+
+	err := s.redis.Set(ctx, redis.SyncInstanceKey(s.instanceID), info, instanceHeartbeatTTL)
+	if err != nil {
+		logger.Errorf(err, "failed to send heartbeat for sync instance %s", s.instanceID)
+		return
+	}
+
+	// heals room registrations lost to expiry or a Redis restart
+	for roomID := range stats.Rooms {
+		s.registerRoomConnections(ctx, roomID)
+	}
+	s.refreshUserPresence(ctx)
+}
+
+// handleInstanceControl processes control actions addressed to this instance
+func (s *syncService) handleInstanceControl(ctx context.Context) {
+	pubsub := s.redis.Subscribe(ctx, redis.SyncInstanceControlChannel(s.instanceID))
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			var control model.InstanceControlMessage
+			if err := json.Unmarshal([]byte(msg.Payload), &control); err != nil {
+				logger.Errorf(err, "failed to unmarshal instance control message")
+				continue
+			}
+
+			s.applyInstanceControl(ctx, &control)
+		}
+	}
+}
+
+// applyInstanceControl runs a control action against the local connections of a room
+func (s *syncService) applyInstanceControl(ctx context.Context, control *model.InstanceControlMessage) {
+	s.connMutex.RLock()
+	hasLocalConnections := len(s.connections[control.RoomID]) > 0
+	s.connMutex.RUnlock()
+
+	if !hasLocalConnections {
+		return
+	}
+
+	switch control.Action {
+	case model.ActionForceResync:
+		s.broadcastAuthoritativeState(ctx, control.RoomID)
+	case model.ActionDirectMessage:
+		s.deliverDirectMessage(control)
+	default:
+		logger.Warnf("unsupported instance control action %s for room %s", control.Action, control.RoomID)
+	}
+}
+
+// Shutdown removes this instance from the registry so service-api stops routing to it
+func (s *syncService) Shutdown(ctx context.Context) {
+	s.stop()
+
+	s.connMutex.RLock()
+	roomConns := make(map[uuid.UUID]map[uuid.UUID]*websocket.Conn, len(s.connections))
+	for roomID, conns := range s.connections {
+		roomConns[roomID] = make(map[uuid.UUID]*websocket.Conn, len(conns))
+		for userID, conn := range conns {
+			roomConns[roomID][userID] = conn
+		}
+	}
+	s.connMutex.RUnlock()
+
+	for roomID, conns := range roomConns {
+		err := s.syncRepo.SetInstanceConnections(ctx, roomID, s.instanceID, 0)
+		if err != nil {
+			logger.Errorf(err, "failed to deregister connections for room %s", roomID)
+		}
+		for userID, conn := range conns {
+			s.deregisterUserConnection(ctx, roomID, userID)
+			// the process exits before the connection handlers would log these
+			s.connCloses.mark(conn, model.CloseReasonServerShutdown)
+			s.finishConnection(roomID, userID, conn, nil)
+		}
+	}
+
+	err := s.redis.Delete(ctx, redis.SyncInstanceKey(s.instanceID))
+	if err != nil {
+		logger.Errorf(err, "failed to deregister sync instance %s", s.instanceID)
+	}
+
+	logger.Infof("sync instance %s deregistered", s.instanceID)
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// controlWriteTimeout bounds writing a ping, pong or close frame to a connection
+const controlWriteTimeout = 5 * time.Second
+
+// idleTimeout returns how long a connection may go without any message or pong before it is closed
+func (s *syncService) idleTimeout() time.Duration {
+	if s.config.Sync.IdleTimeout <= 0 {
+		return config.DefaultSyncIdleTimeout
+	}
+	return s.config.Sync.IdleTimeout.ToDuration()
+}
+
+// extendIdleDeadline gives a connection another idle timeout to send something
+func (s *syncService) extendIdleDeadline(conn *websocket.Conn) {
+	_ = conn.SetReadDeadline(time.Now().Add(s.idleTimeout()))
+}
+
+// keepAlive arms the idle timeout of a connection and pings the client at half of it. every message, ping or pong
+// from the client extends the read deadline, so a read only times out once the client stopped answering. the
+// returned func stops the pings and must be called when the connection ends
+func (s *syncService) keepAlive(conn *websocket.Conn) func() {
+	s.extendIdleDeadline(conn)
+
+	conn.SetPongHandler(func(string) error {
+		s.extendIdleDeadline(conn)
+		return nil
+	})
+	conn.SetPingHandler(func(message string) error {
+		s.extendIdleDeadline(conn)
+		// like the default handler, a pong that cannot be written does not end the connection
+		err := conn.WriteControl(websocket.PongMessage, []byte(message), time.Now().Add(controlWriteTimeout))
+		var netErr net.Error
+		if errors.Is(err, websocket.ErrCloseSent) || (errors.As(err, &netErr) && netErr.Timeout()) {
+			return nil
+		}
+		return err
+	})
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(s.idleTimeout() / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// control frames may be written concurrently with the JSON writes, no write mutex needed
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(controlWriteTimeout)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// closeIdleConnection tells a client that timed out why the server is closing its connection
+func (s *syncService) closeIdleConnection(conn *websocket.Conn, userID, roomID uuid.UUID) {
+	logger.Infof("closing connection of user %s in room %s: nothing received for %s", userID, roomID, s.idleTimeout())
+
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, string(model.CloseReasonIdleTimeout))
+	_ = conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(controlWriteTimeout))
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"watch-party/pkg/config"
+	"watch-party/pkg/model"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialSilentPeer opens a WebSocket to a server that never reads, so it answers no ping
+func dialSilentPeer(t testing.TB) *websocket.Conn {
+	upgrader := websocket.Upgrader{}
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		<-release
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestKeepAlive(t *testing.T) {
+	s := &syncService{config: &config.Config{}}
+	s.config.Sync.IdleTimeout = config.Duration(100 * time.Millisecond)
+
+	// a peer answering the pings outlives several idle timeouts
+	conn := dialDiscardingPeer(t)
+	stop := s.keepAlive(conn)
+	defer stop()
+
+	readErr := make(chan error, 1)
+	go func() {
+		_, _, err := conn.ReadMessage()
+		readErr <- err
+	}()
+	select {
+	case err := <-readErr:
+		t.Fatalf("read ended while the peer answered pings: %v", err)
+	case <-time.After(400 * time.Millisecond):
+	}
+
+	// a peer that stopped answering times out as idle
+	silent := dialSilentPeer(t)
+	stopSilent := s.keepAlive(silent)
+	defer stopSilent()
+
+	_, _, err := silent.ReadMessage()
+	require.Error(t, err)
+	assert.Equal(t, model.CloseReasonIdleTimeout, classifyReadError(err))
+}
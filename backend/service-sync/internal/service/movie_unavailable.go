@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// handleMovieUnavailable ends the session of a room whose movie was deleted by service-api.
+// the room is deleted with the movie, so participants are told why and disconnected instead of
+// watching their segment requests fail.
+func (s *syncService) handleMovieUnavailable(ctx context.Context, syncMessage *model.SyncMessage, hasLocalConnections bool) {
+	roomID := syncMessage.RoomID
+
+	// every instance receives the event, deleting the same state again is harmless
+	err := s.syncRepo.DeleteRoomState(ctx, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to delete state of room %s after its movie was removed", roomID)
+	}
+
+	if !hasLocalConnections {
+		return
+	}
+
+	unavailable := model.MovieUnavailableMessage{
+		RoomID:     roomID,
+		Reason:     model.MovieUnavailableReasonDeleted,
+		RoomStatus: model.RoomStatusEnded,
+	}
+	if reason, ok := syncMessage.Data.Extra["reason"].(string); ok {
+		unavailable.Reason = reason
+	}
+	if movieIDStr, ok := syncMessage.Data.Extra["movie_id"].(string); ok {
+		unavailable.MovieID, _ = uuid.Parse(movieIDStr)
+	}
+
+	s.connMutex.RLock()
+	roomConns := make(map[uuid.UUID]*websocket.Conn, len(s.connections[roomID]))
+	for userID, conn := range s.connections[roomID] {
+		roomConns[userID] = conn
+	}
+	s.connMutex.RUnlock()
+
+	// the notice is written before closing, so it cannot race the close like a broadcast would.
+	// the connection handlers clean up participants and presence once their connection closes.
+	message := &model.WebSocketMessage{
+		Type:    model.MessageTypeMovieUnavailable,
+		Payload: unavailable,
+	}
+	for userID, conn := range roomConns {
+		if err := s.sendToConnectionSafe(roomID, userID, conn, message); err != nil {
+			logger.Errorf(err, "failed to send movie_unavailable to user %s", userID)
+		}
+		// a normal closure keeps clients from reconnecting to the deleted room
+		closeMessage := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "movie removed")
+		_ = conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(time.Second))
+		if err := s.closeConnection(conn, model.CloseReasonRoomEnded); err != nil {
+			logger.Errorf(err, "failed to close connection of user %s in room %s", userID, roomID)
+		}
+	}
+
+	logger.Infof("ended room %s after its movie was removed, closed %d connections", roomID, len(roomConns))
+}
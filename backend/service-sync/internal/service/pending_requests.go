@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// defaultPendingStateTTL is how long a new joiner waits for a live state snapshot
+	defaultPendingStateTTL = 10 * time.Second
+	// defaultPendingStateSweepInterval is how often expired state requests are removed
+	defaultPendingStateSweepInterval = 5 * time.Second
+	// defaultMaxPendingStateRequests caps outstanding state requests on one instance
+	defaultMaxPendingStateRequests = 10000
+)
+
+// pendingStateRequest is a new joiner waiting for an existing participant to share the live state
+type pendingStateRequest struct {
+	conn      *websocket.Conn
+	createdAt time.Time
+}
+
+// pendingStateRequests tracks unanswered state requests per room.
+// expired entries are removed by a single janitor instead of one timer per request.
+type pendingStateRequests struct {
+	mu       sync.Mutex
+	requests map[uuid.UUID]map[uuid.UUID]pendingStateRequest
+	count    int
+	ttl      time.Duration
+	limit    int
+}
+
+// newPendingStateRequests creates the tracker, falling back to defaults for unset values
+func newPendingStateRequests(ttl time.Duration, limit int) *pendingStateRequests {
+	if ttl <= 0 {
+		ttl = defaultPendingStateTTL
+	}
+	if limit <= 0 {
+		limit = defaultMaxPendingStateRequests
+	}
+
+	return &pendingStateRequests{
+		requests: make(map[uuid.UUID]map[uuid.UUID]pendingStateRequest),
+		ttl:      ttl,
+		limit:    limit,
+	}
+}
+
+// store records a request, returning false when the instance already has too many outstanding
+func (p *pendingStateRequests) store(roomID, requesterID uuid.UUID, conn *websocket.Conn, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	roomRequests := p.requests[roomID]
+	if roomRequests == nil {
+		roomRequests = make(map[uuid.UUID]pendingStateRequest)
+		p.requests[roomID] = roomRequests
+	}
+
+	// a repeated request from the same joiner replaces the previous one
+	if _, exists := roomRequests[requesterID]; !exists {
+		if p.count >= p.limit {
+			if len(roomRequests) == 0 {
+				delete(p.requests, roomID)
+			}
+			return false
+		}
+		p.count++
+	}
+
+	roomRequests[requesterID] = pendingStateRequest{conn: conn, createdAt: now}
+	return true
+}
+
+// get returns the requester's connection while the request has not expired
+func (p *pendingStateRequests) get(roomID, requesterID uuid.UUID, now time.Time) *websocket.Conn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	request, exists := p.requests[roomID][requesterID]
+	if !exists || now.Sub(request.createdAt) > p.ttl {
+		return nil
+	}
+	return request.conn
+}
+
+// remove drops a request once it has been answered
+func (p *pendingStateRequests) remove(roomID, requesterID uuid.UUID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.removeLocked(roomID, requesterID)
+}
+
+func (p *pendingStateRequests) removeLocked(roomID, requesterID uuid.UUID) {
+	roomRequests, exists := p.requests[roomID]
+	if !exists {
+		return
+	}
+
+	if _, exists := roomRequests[requesterID]; exists {
+		delete(roomRequests, requesterID)
+		p.count--
+	}
+	if len(roomRequests) == 0 {
+		delete(p.requests, roomID)
+	}
+}
+
+// expire removes every request older than the TTL and returns how many were removed
+func (p *pendingStateRequests) expire(now time.Time) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	expired := 0
+	for roomID, roomRequests := range p.requests {
+		for requesterID, request := range roomRequests {
+			if now.Sub(request.createdAt) > p.ttl {
+				p.removeLocked(roomID, requesterID)
+				expired++
+			}
+		}
+	}
+	return expired
+}
+
+// len returns the number of outstanding requests
+func (p *pendingStateRequests) len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.count
+}
+
+// runJanitor expires stale requests every interval until ctx is done
+func (p *pendingStateRequests) runJanitor(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultPendingStateSweepInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			p.expire(now)
+		}
+	}
+}
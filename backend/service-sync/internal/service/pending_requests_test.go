@@ -0,0 +1,117 @@
+package service
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingStateRequests_ConcurrentJoins(t *testing.T) {
+	const (
+		rooms        = 10
+		joinsPerRoom = 500
+	)
+
+	pending := newPendingStateRequests(10*time.Second, rooms*joinsPerRoom)
+	now := time.Now()
+
+	roomIDs := make([]uuid.UUID, rooms)
+	for i := range roomIDs {
+		roomIDs[i] = uuid.New()
+	}
+
+	goroutinesBefore := runtime.NumGoroutine()
+
+	var wg sync.WaitGroup
+	for _, roomID := range roomIDs {
+		for i := 0; i < joinsPerRoom; i++ {
+			wg.Add(1)
+			go func(roomID uuid.UUID) {
+				defer wg.Done()
+				assert.True(t, pending.store(roomID, uuid.New(), nil, now))
+			}(roomID)
+		}
+	}
+	wg.Wait()
+
+	// storing must not leave a timer goroutine behind per request
+	assert.LessOrEqual(t, runtime.NumGoroutine(), goroutinesBefore+5)
+	assert.Equal(t, rooms*joinsPerRoom, pending.len())
+
+	// nothing is old enough yet
+	assert.Equal(t, 0, pending.expire(now.Add(5*time.Second)))
+
+	assert.Equal(t, rooms*joinsPerRoom, pending.expire(now.Add(11*time.Second)))
+	assert.Equal(t, 0, pending.len())
+	assert.Empty(t, pending.requests)
+}
+
+func TestPendingStateRequests_Limit(t *testing.T) {
+	const limit = 100
+
+	pending := newPendingStateRequests(10*time.Second, limit)
+	roomID := uuid.New()
+	now := time.Now()
+
+	var stored atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 5*limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if pending.store(roomID, uuid.New(), nil, now) {
+				stored.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(limit), stored.Load())
+	assert.Equal(t, limit, pending.len())
+
+	// answered requests free up room for new joiners
+	var requesterID uuid.UUID
+	for id := range pending.requests[roomID] {
+		requesterID = id
+		break
+	}
+	pending.remove(roomID, requesterID)
+	assert.True(t, pending.store(roomID, uuid.New(), nil, now))
+}
+
+func TestPendingStateRequests_RepeatedRequestReplaces(t *testing.T) {
+	pending := newPendingStateRequests(10*time.Second, 1)
+	roomID := uuid.New()
+	requesterID := uuid.New()
+	now := time.Now()
+
+	require.True(t, pending.store(roomID, requesterID, nil, now))
+	require.True(t, pending.store(roomID, requesterID, nil, now.Add(8*time.Second)))
+	assert.Equal(t, 1, pending.len())
+
+	// the refreshed request outlives the original one
+	assert.Equal(t, 0, pending.expire(now.Add(11*time.Second)))
+	assert.Equal(t, 1, pending.expire(now.Add(19*time.Second)))
+}
+
+func TestPendingStateRequests_GetHonoursTTL(t *testing.T) {
+	pending := newPendingStateRequests(time.Second, 0)
+	roomID := uuid.New()
+	requesterID := uuid.New()
+	now := time.Now()
+
+	require.True(t, pending.store(roomID, requesterID, nil, now))
+	assert.Equal(t, defaultMaxPendingStateRequests, pending.limit)
+
+	_, exists := pending.requests[roomID][requesterID]
+	assert.True(t, exists)
+
+	// expired requests are no longer routable even before the janitor runs
+	assert.Nil(t, pending.get(roomID, requesterID, now.Add(2*time.Second)))
+}
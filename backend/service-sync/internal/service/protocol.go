@@ -0,0 +1,123 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// protocolVersions remembers the protocol version each connection negotiated
+type protocolVersions struct {
+	mu       sync.RWMutex
+	versions map[*websocket.Conn]int
+}
+
+func newProtocolVersions() *protocolVersions {
+	return &protocolVersions{versions: make(map[*websocket.Conn]int)}
+}
+
+func (p *protocolVersions) set(conn *websocket.Conn, version int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.versions[conn] = version
+}
+
+func (p *protocolVersions) forget(conn *websocket.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.versions, conn)
+}
+
+// of returns the version a connection speaks, version 1 for a connection that never negotiated one
+func (p *protocolVersions) of(conn *websocket.Conn) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if version, ok := p.versions[conn]; ok {
+		return version
+	}
+	return model.ProtocolV1
+}
+
+// versioned returns a copy of message stamped with the protocol version of conn, the same message is often sent
+// to many connections at once
+func (s *syncService) versioned(conn *websocket.Conn, message *model.WebSocketMessage) *model.WebSocketMessage {
+	stamped := *message
+	stamped.Version = s.protocolVersions.of(conn)
+	return &stamped
+}
+
+// syncEventMessages builds a sync event in the shape of each protocol version
+func syncEventMessages(syncMessage *model.SyncMessage, now time.Time) map[int]*model.WebSocketMessage {
+	// timestamps come from the server clock so clients can correct drift using their time_sync offset
+	legacy := map[string]interface{}{
+		"action":       string(syncMessage.Action),
+		"current_time": syncMessage.Data.CurrentTime,
+		"timestamp":    syncMessage.Timestamp.Format(time.RFC3339Nano),
+		"server_time":  now.UnixMilli(),
+		"user_id":      syncMessage.UserID.String(),
+		"username":     syncMessage.Username,
+	}
+
+	// include data object if there's additional data (like chat messages)
+	if syncMessage.Data.ChatMessage != "" || syncMessage.Data.Duration > 0 || syncMessage.Data.PlaybackRate > 0 || syncMessage.Data.IsBuffering {
+		legacy["data"] = map[string]interface{}{
+			"current_time":  syncMessage.Data.CurrentTime,
+			"duration":      syncMessage.Data.Duration,
+			"playback_rate": syncMessage.Data.PlaybackRate,
+			"is_buffering":  syncMessage.Data.IsBuffering,
+			"chat_message":  syncMessage.Data.ChatMessage,
+		}
+	}
+
+	// extra data such as the segment position is an optional addition, version 1 clients that do not know it ignore it
+	if len(syncMessage.Data.Extra) > 0 {
+		legacy["extra"] = syncMessage.Data.Extra
+	}
+
+	return map[int]*model.WebSocketMessage{
+		model.ProtocolV1: {Type: model.MessageTypeSync, Payload: legacy, Seq: syncMessage.Seq},
+		model.ProtocolV2: {
+			Type: model.MessageTypeSync,
+			Payload: &model.SyncEvent{
+				Action:      syncMessage.Action,
+				CurrentTime: syncMessage.Data.CurrentTime,
+				Timestamp:   syncMessage.Timestamp,
+				ServerTime:  now.UnixMilli(),
+				UserID:      syncMessage.UserID,
+				Username:    syncMessage.Username,
+				Data:        syncMessage.Data,
+			},
+			Seq: syncMessage.Seq,
+		},
+	}
+}
+
+// broadcastVersionedExcluding sends every local connection of a room but one the message shaped for its protocol
+// version, messages holds one per version
+func (s *syncService) broadcastVersionedExcluding(roomID uuid.UUID, messages map[int]*model.WebSocketMessage, excludeUserID uuid.UUID) {
+	s.connMutex.RLock()
+	defer s.connMutex.RUnlock()
+
+	for userID, conn := range s.connections[roomID] {
+		if userID == excludeUserID {
+			continue
+		}
+		message, ok := messages[s.protocolVersions.of(conn)]
+		if !ok {
+			message = messages[model.ProtocolV1]
+		}
+		go func(userID uuid.UUID, conn *websocket.Conn) {
+			if err := s.sendToConnectionSafe(roomID, userID, conn, message); err != nil {
+				logger.Errorf(err, "failed to send message to user %s", userID)
+			}
+		}(userID, conn)
+	}
+}
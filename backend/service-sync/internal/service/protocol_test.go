@@ -0,0 +1,99 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialRecordingPeer opens a WebSocket to a server that hands over every message it receives
+func dialRecordingPeer(t testing.TB) (*websocket.Conn, <-chan []byte) {
+	received := make(chan []byte, 16)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			received <- data
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn, received
+}
+
+func receive(t *testing.T, received <-chan []byte) map[string]interface{} {
+	t.Helper()
+	select {
+	case data := <-received:
+		var message map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &message))
+		return message
+	case <-time.After(time.Second):
+		t.Fatal("no message received")
+		return nil
+	}
+}
+
+func TestSyncBroadcastPerProtocolVersion(t *testing.T) {
+	s, roomID, hostID, _ := newRouterTestService(t)
+
+	// a client that predates versioning and one on the current version watch the same room
+	legacyID, currentID := uuid.New(), uuid.New()
+	legacyConn, legacyReceived := dialRecordingPeer(t)
+	currentConn, currentReceived := dialRecordingPeer(t)
+	s.addConnection(roomID, legacyID, legacyConn)
+	s.addConnection(roomID, currentID, currentConn)
+	s.protocolVersions.set(legacyConn, model.NegotiateProtocolVersion(""))
+	s.protocolVersions.set(currentConn, model.NegotiateProtocolVersion("2"))
+
+	s.broadcastSyncToRoom(roomID, &model.SyncMessage{
+		RoomID:    roomID,
+		UserID:    hostID,
+		Username:  "host",
+		Action:    model.ActionSeek,
+		Timestamp: time.Now(),
+		Seq:       7,
+		Data: model.SyncData{
+			CurrentTime: 42,
+			Extra:       map[string]interface{}{"segment_index": float64(4)},
+		},
+	}, hostID)
+
+	legacy := receive(t, legacyReceived)
+	assert.Equal(t, float64(model.ProtocolV1), legacy["version"])
+	assert.Equal(t, float64(7), legacy["seq"])
+	legacyPayload := legacy["payload"].(map[string]interface{})
+	assert.Equal(t, "seek", legacyPayload["action"])
+	assert.Equal(t, float64(42), legacyPayload["current_time"])
+	assert.Equal(t, hostID.String(), legacyPayload["user_id"])
+	assert.NotContains(t, legacyPayload, "data", "version 1 keeps the shape its clients were written against")
+	assert.Equal(t, map[string]interface{}{"segment_index": float64(4)}, legacyPayload["extra"], "version 1 carries the extra data too")
+
+	current := receive(t, currentReceived)
+	assert.Equal(t, float64(model.ProtocolV2), current["version"])
+	currentPayload := current["payload"].(map[string]interface{})
+	assert.Equal(t, "seek", currentPayload["action"])
+	data := currentPayload["data"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"segment_index": float64(4)}, data["extra"], "version 2 carries the extra data")
+}
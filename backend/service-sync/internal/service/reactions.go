@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// reactionBatches holds the reactions this instance collects per large room until the room's window closes
+type reactionBatches struct {
+	mu      sync.Mutex
+	pending map[uuid.UUID]*model.ReactionBatchMessage
+}
+
+func newReactionBatches() *reactionBatches {
+	return &reactionBatches{pending: make(map[uuid.UUID]*model.ReactionBatchMessage)}
+}
+
+// add counts a reaction into the open batch of its room, false when the room has none open
+func (b *reactionBatches) add(roomID uuid.UUID, emoji string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	batch, ok := b.pending[roomID]
+	if !ok {
+		return false
+	}
+	batch.Counts[emoji]++
+	batch.Total++
+	return true
+}
+
+// open starts the batch of a room with its first reaction. false means another reaction opened it meanwhile, the
+// reaction is counted into that batch and only the opener schedules the flush
+func (b *reactionBatches) open(roomID uuid.UUID, emoji string, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if batch, ok := b.pending[roomID]; ok {
+		batch.Counts[emoji]++
+		batch.Total++
+		return false
+	}
+
+	b.pending[roomID] = &model.ReactionBatchMessage{
+		RoomID:    roomID,
+		Counts:    map[string]int{emoji: 1},
+		Total:     1,
+		StartedAt: now,
+	}
+	return true
+}
+
+// take closes the batch of a room and returns it, nil when none is open
+func (b *reactionBatches) take(roomID uuid.UUID, now time.Time) *model.ReactionBatchMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	batch, ok := b.pending[roomID]
+	if !ok {
+		return nil
+	}
+	delete(b.pending, roomID)
+	batch.EndedAt = now
+	return batch
+}
+
+// handleReaction delivers a viewer's emoji reaction to the room. in large rooms the reactions of a short window are
+// counted into one batch, so a big moment sends each participant one message instead of one per viewer
+func (s *syncService) handleReaction(ctx context.Context, roomID, userID uuid.UUID, username string, conn *websocket.Conn, rawMessage map[string]interface{}) {
+	if err := s.checkReactionsEnabled(ctx, roomID); err != nil {
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "REACTIONS_DISABLED", err.Error())
+		return
+	}
+
+	emoji, _ := rawMessage["emoji"].(string)
+	emoji, ok := model.NormalizeReaction(emoji)
+	if !ok {
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "INVALID_REACTION", "a reaction must be a single emoji")
+		return
+	}
+
+	allowed, err := s.syncRepo.AllowReaction(ctx, roomID, userID)
+	if err != nil {
+		// the limit protects viewers from spam, it must not block reactions when Redis misbehaves
+		logger.Errorf(err, "failed to check reaction rate in room %s", roomID)
+	} else if !allowed {
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "REACTION_RATE_LIMITED",
+			fmt.Sprintf("at most %d reactions per %s", model.ReactionBurst, model.ReactionWindow))
+		return
+	}
+
+	if s.reactionBatches.add(roomID, emoji) {
+		return
+	}
+	if s.batchesReactions(ctx, roomID) {
+		if s.reactionBatches.open(roomID, emoji, time.Now()) {
+			time.AfterFunc(s.reactionBatchWindow(), func() {
+				s.flushReactionBatch(roomID)
+			})
+		}
+		return
+	}
+
+	reaction := &model.ReactionMessage{
+		RoomID:    roomID,
+		UserID:    userID,
+		Username:  username,
+		Emoji:     emoji,
+		CreatedAt: time.Now(),
+	}
+	s.publishReactionEvent(ctx, &model.SyncMessage{
+		ID:        uuid.New(),
+		RoomID:    roomID,
+		UserID:    userID,
+		Username:  username,
+		Action:    model.ActionReaction,
+		Timestamp: reaction.CreatedAt,
+		Data: model.SyncData{
+			Extra: map[string]interface{}{
+				"reaction": reaction,
+			},
+		},
+	}, &model.WebSocketMessage{Type: model.MessageTypeReaction, Payload: reaction})
+}
+
+// batchesReactions reports whether the reactions of a room are batched, a room too small to flood anyone gets
+// each reaction right away
+func (s *syncService) batchesReactions(ctx context.Context, roomID uuid.UUID) bool {
+	if !s.config.Features.Enabled(config.FeatureSyncReactionBatch) {
+		return false
+	}
+
+	participants, err := s.syncRepo.CountParticipants(ctx, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to count participants of room %s", roomID)
+		return false
+	}
+	return participants >= s.reactionBatchMinParticipants()
+}
+
+// reactionBatchWindow returns how long the reactions of a large room are collected before they are sent
+func (s *syncService) reactionBatchWindow() time.Duration {
+	if s.config.Sync.ReactionBatchWindow <= 0 {
+		return config.DefaultSyncReactionBatchWindow
+	}
+	return s.config.Sync.ReactionBatchWindow.ToDuration()
+}
+
+// reactionBatchMinParticipants returns from how many participants a room's reactions are batched
+func (s *syncService) reactionBatchMinParticipants() int {
+	if s.config.Sync.ReactionBatchMinParticipants <= 0 {
+		return config.DefaultSyncReactionBatchMinParticipants
+	}
+	return s.config.Sync.ReactionBatchMinParticipants
+}
+
+// flushReactionBatch sends the reactions a room collected during its window as one message
+func (s *syncService) flushReactionBatch(roomID uuid.UUID) {
+	batch := s.reactionBatches.take(roomID, time.Now())
+	if batch == nil {
+		return
+	}
+
+	s.publishReactionEvent(context.Background(), &model.SyncMessage{
+		ID:        uuid.New(),
+		RoomID:    roomID,
+		Action:    model.ActionReactionBatch,
+		Timestamp: batch.EndedAt,
+		Data: model.SyncData{
+			Extra: map[string]interface{}{
+				"batch": batch,
+			},
+		},
+	}, &model.WebSocketMessage{Type: model.MessageTypeReactionBatch, Payload: batch})
+}
+
+// publishReactionEvent sends a reaction or batch to every instance, or to the local connections only when it
+// cannot be published
+func (s *syncService) publishReactionEvent(ctx context.Context, event *model.SyncMessage, local *model.WebSocketMessage) {
+	err := s.syncRepo.PublishEvent(ctx, event.RoomID, event)
+	if err != nil {
+		logger.Errorf(err, "failed to publish %s to Redis", event.Action)
+		s.broadcastToRoom(event.RoomID, local)
+	}
+}
+
+// handleReactionEvent delivers a reaction or batch published by any instance to the local participants
+func (s *syncService) handleReactionEvent(syncMessage *model.SyncMessage) {
+	message := &model.WebSocketMessage{Seq: syncMessage.Seq}
+
+	// the payload went through JSON, decode it back from the generic map
+	var err error
+	if syncMessage.Action == model.ActionReactionBatch {
+		var batch model.ReactionBatchMessage
+		err = decodeExtra(syncMessage.Data.Extra["batch"], &batch)
+		message.Type, message.Payload = model.MessageTypeReactionBatch, &batch
+	} else {
+		var reaction model.ReactionMessage
+		err = decodeExtra(syncMessage.Data.Extra["reaction"], &reaction)
+		message.Type, message.Payload = model.MessageTypeReaction, &reaction
+	}
+	if err != nil {
+		logger.Errorf(err, "invalid %s event for room %s", syncMessage.Action, syncMessage.RoomID)
+		return
+	}
+
+	s.broadcastToRoom(syncMessage.RoomID, message)
+}
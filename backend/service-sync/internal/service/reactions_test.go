@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"watch-party/pkg/config"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReactionBatches(t *testing.T) {
+	batches := newReactionBatches()
+	roomID := uuid.New()
+	now := time.Now()
+
+	assert.False(t, batches.add(roomID, "🔥"), "nothing is counted before a batch is open")
+	assert.True(t, batches.open(roomID, "🔥", now))
+	assert.False(t, batches.open(roomID, "😂", now), "a second opener joins the open batch")
+	assert.True(t, batches.add(roomID, "🔥"))
+
+	batch := batches.take(roomID, now.Add(time.Second))
+	require.NotNil(t, batch)
+	assert.Equal(t, map[string]int{"🔥": 2, "😂": 1}, batch.Counts)
+	assert.Equal(t, 3, batch.Total)
+	assert.Equal(t, now.Add(time.Second), batch.EndedAt)
+	assert.Nil(t, batches.take(roomID, now))
+}
+
+func TestHandleReactionBatchesLargeRooms(t *testing.T) {
+	s, roomID, hostID, hostConn := newRouterTestService(t)
+	s.config.Features.Flags = map[string]bool{config.FeatureSyncReactionBatch: true}
+	s.config.Sync.ReactionBatchWindow = config.Duration(20 * time.Millisecond)
+	ctx := context.Background()
+
+	// the room lists two participants, below the threshold every reaction goes out right away
+	s.config.Sync.ReactionBatchMinParticipants = 3
+	s.handleReaction(ctx, roomID, hostID, "host", hostConn, map[string]interface{}{"emoji": "🔥"})
+	assert.Nil(t, s.reactionBatches.take(roomID, time.Now()))
+
+	s.config.Sync.ReactionBatchMinParticipants = 2
+	for range 3 {
+		s.handleReaction(ctx, roomID, hostID, "host", hostConn, map[string]interface{}{"emoji": "🔥"})
+	}
+	s.handleReaction(ctx, roomID, hostID, "host", hostConn, map[string]interface{}{"emoji": "not an emoji"})
+
+	s.reactionBatches.mu.Lock()
+	batch := s.reactionBatches.pending[roomID]
+	require.NotNil(t, batch)
+	assert.Equal(t, 3, batch.Total)
+	s.reactionBatches.mu.Unlock()
+
+	require.Eventually(t, func() bool {
+		s.reactionBatches.mu.Lock()
+		defer s.reactionBatches.mu.Unlock()
+		return s.reactionBatches.pending[roomID] == nil
+	}, time.Second, 5*time.Millisecond, "the batch is sent once its window closed")
+}
+
+func TestHandleReactionRateLimit(t *testing.T) {
+	s, roomID, _, _ := newRouterTestService(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	peer, received := dialRecordingPeer(t)
+	s.addConnection(roomID, userID, peer)
+
+	for range model.ReactionBurst {
+		allowed, err := s.syncRepo.AllowReaction(ctx, roomID, userID)
+		require.NoError(t, err)
+		require.True(t, allowed)
+	}
+
+	// one reaction past the burst is refused until the window ends
+	s.handleReaction(ctx, roomID, userID, "viewer", peer, map[string]interface{}{"emoji": "🔥"})
+	for {
+		message := receive(t, received)
+		if message["type"] != string(model.MessageTypeError) {
+			continue
+		}
+		payload, _ := message["payload"].(map[string]interface{})
+		assert.Equal(t, "REACTION_RATE_LIMITED", payload["code"])
+		break
+	}
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+)
+
+// reconcileActiveRoomsLimit bounds how many of the most recently active rooms without a local connection a sweep
+// checks
+const reconcileActiveRoomsLimit = 500
+
+// runParticipantReconciler removes phantom participants every interval until ctx is done
+func (s *syncService) runParticipantReconciler(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = config.DefaultSyncReconcileInterval
+	}
+	staleAfter := s.config.Sync.ParticipantStaleAfter.ToDuration()
+	if staleAfter <= 0 {
+		staleAfter = config.DefaultSyncParticipantStaleAfter
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.reconcileParticipants(ctx, now, staleAfter)
+		}
+	}
+}
+
+// reconcileParticipants checks the participants of every room this instance holds connections for, and of the
+// recently active rooms nobody is connected to through it, where crashed clients would otherwise linger
+func (s *syncService) reconcileParticipants(ctx context.Context, now time.Time, grace time.Duration) {
+	s.connMutex.RLock()
+	rooms := make(map[uuid.UUID]map[uuid.UUID]bool, len(s.connections))
+	for roomID, roomConns := range s.connections {
+		local := make(map[uuid.UUID]bool, len(roomConns))
+		for userID := range roomConns {
+			local[userID] = true
+		}
+		rooms[roomID] = local
+	}
+	s.connMutex.RUnlock()
+
+	for roomID, local := range rooms {
+		s.reconcileRoom(ctx, roomID, local, now, grace)
+	}
+
+	activeRooms, err := s.syncRepo.GetActiveRooms(ctx, reconcileActiveRoomsLimit)
+	if err != nil {
+		logger.Error(err, "failed to get active rooms for reconciliation")
+		return
+	}
+	for _, roomID := range activeRooms {
+		if _, held := rooms[roomID]; !held {
+			s.reconcileRoom(ctx, roomID, nil, now, grace)
+		}
+	}
+}
+
+// reconcileRoom removes participants of a room that no live instance holds a connection for, and logs
+// connections of this instance that have no participant
+func (s *syncService) reconcileRoom(ctx context.Context, roomID uuid.UUID, local map[uuid.UUID]bool, now time.Time, grace time.Duration) {
+	participants, err := s.syncRepo.GetParticipants(ctx, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to get participants of room %s for reconciliation", roomID)
+		return
+	}
+	owners, err := s.syncRepo.GetUserInstances(ctx, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to get connection owners of room %s for reconciliation", roomID)
+		return
+	}
+	liveInstances, err := s.redis.RoomInstances(ctx, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to get instances of room %s for reconciliation", roomID)
+		return
+	}
+
+	inRoster := make(map[uuid.UUID]bool, len(participants))
+	for _, participant := range participants {
+		inRoster[participant.UserID] = true
+	}
+	for userID := range local {
+		if !inRoster[userID] {
+			logger.Warnf("user %s is connected to room %s on instance %s but not listed as a participant", userID, roomID, s.instanceID)
+		}
+	}
+
+	removed := false
+	for _, participant := range phantomParticipants(participants, local, owners, liveInstances, s.instanceID, now, grace) {
+		if s.removePhantomParticipant(ctx, roomID, participant, owners[participant.UserID.String()]) {
+			removed = true
+		}
+	}
+
+	// every instance announces the leaves, the participants connected here also get the corrected roster
+	if removed && len(local) > 0 {
+		s.broadcastParticipants(ctx, roomID)
+	}
+}
+
+// phantomParticipants returns the participants nobody holds a connection for. a participant is kept while this
+// instance has its socket, while a live instance owns its connection, or while it was seen within grace, which
+// covers a join still being registered
+func phantomParticipants(participants []model.ParticipantInfo, local map[uuid.UUID]bool, owners map[string]string, liveInstances map[string]int, instanceID string, now time.Time, grace time.Duration) []model.ParticipantInfo {
+	var phantoms []model.ParticipantInfo
+	for _, participant := range participants {
+		if local[participant.UserID] || now.Sub(participant.LastSeen) < grace {
+			continue
+		}
+
+		owner := owners[participant.UserID.String()]
+		if owner != "" && owner != instanceID {
+			if _, alive := liveInstances[owner]; alive {
+				continue
+			}
+		}
+		phantoms = append(phantoms, participant)
+	}
+	return phantoms
+}
+
+// removePhantomParticipant drops a participant whose connection is gone and announces it like a leave, reporting
+// whether this instance removed it
+func (s *syncService) removePhantomParticipant(ctx context.Context, roomID uuid.UUID, participant model.ParticipantInfo, owner string) bool {
+	removed, err := s.syncRepo.RemoveStaleParticipant(ctx, roomID, participant.UserID)
+	if err != nil {
+		logger.Errorf(err, "failed to remove phantom participant %s from room %s", participant.UserID, roomID)
+		return false
+	}
+	// another instance reconciling the same room got there first
+	if !removed {
+		return false
+	}
+
+	logger.Warnf("removed phantom participant %s (%s) from room %s, last seen %s on instance %q",
+		participant.Username, participant.UserID, roomID, participant.LastSeen.Format(time.RFC3339), owner)
+
+	if owner != "" {
+		err = s.syncRepo.RemoveUserInstance(ctx, roomID, participant.UserID, owner)
+		if err != nil {
+			logger.Errorf(err, "failed to remove routing entry of phantom participant %s in room %s", participant.UserID, roomID)
+		}
+	}
+	err = s.syncRepo.RemoveUserPresence(ctx, participant.UserID, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to remove presence of phantom participant %s in room %s", participant.UserID, roomID)
+	}
+
+	s.BroadcastSync(ctx, &model.SyncMessage{
+		ID:        uuid.New(),
+		RoomID:    roomID,
+		UserID:    participant.UserID,
+		Username:  participant.Username,
+		Action:    model.ActionLeave,
+		Timestamp: time.Now(),
+	})
+
+	s.scheduleHostHandoff(ctx, roomID, participant.UserID)
+	return true
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhantomParticipants(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	grace := time.Minute
+	stale := now.Add(-10 * time.Minute)
+
+	connectedHere := model.ParticipantInfo{UserID: uuid.New(), LastSeen: stale}
+	connectedElsewhere := model.ParticipantInfo{UserID: uuid.New(), LastSeen: stale}
+	onCrashedInstance := model.ParticipantInfo{UserID: uuid.New(), LastSeen: stale}
+	lostHere := model.ParticipantInfo{UserID: uuid.New(), LastSeen: stale}
+	unrouted := model.ParticipantInfo{UserID: uuid.New(), LastSeen: stale}
+	justJoined := model.ParticipantInfo{UserID: uuid.New(), LastSeen: now.Add(-time.Second)}
+
+	participants := []model.ParticipantInfo{connectedHere, connectedElsewhere, onCrashedInstance, lostHere, unrouted, justJoined}
+	local := map[uuid.UUID]bool{connectedHere.UserID: true}
+	owners := map[string]string{
+		connectedHere.UserID.String():      "this",
+		connectedElsewhere.UserID.String(): "other",
+		onCrashedInstance.UserID.String():  "crashed",
+		lostHere.UserID.String():           "this",
+	}
+	liveInstances := map[string]int{"this": 1, "other": 1}
+
+	phantoms := phantomParticipants(participants, local, owners, liveInstances, "this", now, grace)
+
+	assert.ElementsMatch(t, []model.ParticipantInfo{onCrashedInstance, lostHere, unrouted}, phantoms)
+}
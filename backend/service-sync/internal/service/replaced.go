@@ -0,0 +1,25 @@
+package service
+
+import (
+	"time"
+
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// closeCodeReplaced is the close code of a connection replaced by a newer one of the same user. clients must not
+// reconnect on it, two tabs of one user would keep replacing each other
+const closeCodeReplaced = 4002
+
+// replaceConnection closes the connection a user had open to a room before connecting again, e.g. from a second
+// tab. its handler finds it no longer registered and leaves the participant to the new connection
+func (s *syncService) replaceConnection(roomID, userID uuid.UUID, old *websocket.Conn) {
+	logger.Infof("user %s connected to room %s again, closing their previous connection", userID, roomID)
+
+	message := websocket.FormatCloseMessage(closeCodeReplaced, string(model.CloseReasonReplaced))
+	_ = old.WriteControl(websocket.CloseMessage, message, time.Now().Add(controlWriteTimeout))
+	_ = s.closeConnection(old, model.CloseReasonReplaced)
+}
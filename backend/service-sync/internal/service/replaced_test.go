@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveConnectionKeepsReplacement(t *testing.T) {
+	s, roomID, _, _ := newRouterTestService(t)
+	userID := uuid.New()
+	first, second := dialDiscardingPeer(t), dialDiscardingPeer(t)
+
+	assert.Nil(t, s.addConnection(roomID, userID, first))
+	assert.Equal(t, first, s.addConnection(roomID, userID, second), "a second connection replaces the first")
+	assert.Nil(t, s.addConnection(roomID, userID, second), "registering the same connection again replaces nothing")
+
+	assert.False(t, s.removeConnection(roomID, userID, first), "the replaced connection cannot remove its successor")
+	assert.Equal(t, second, s.connections[roomID][userID])
+
+	assert.True(t, s.removeConnection(roomID, userID, second))
+	_, exists := s.connections[roomID][userID]
+	assert.False(t, exists)
+}
+
+func TestHandleConnectionSecondTab(t *testing.T) {
+	s, roomID, _, _ := newRouterTestService(t)
+	userID := uuid.New()
+	ctx := context.Background()
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		_ = s.HandleConnection(context.Background(), roomID, userID, "viewer", true, "", model.CurrentProtocolVersion, conn)
+	}))
+	t.Cleanup(server.Close)
+
+	openTab := func() *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+	registered := func() *websocket.Conn {
+		s.connMutex.RLock()
+		defer s.connMutex.RUnlock()
+		return s.connections[roomID][userID]
+	}
+
+	// the first tab joins the room
+	firstTab := openTab()
+	require.Eventually(t, func() bool { return registered() != nil }, time.Second, 10*time.Millisecond)
+	firstConn := registered()
+
+	// the second tab replaces it, the first is told why it was closed
+	_ = openTab()
+	require.Eventually(t, func() bool {
+		current := registered()
+		return current != nil && current != firstConn
+	}, time.Second, 10*time.Millisecond)
+
+	var err error
+	for err == nil {
+		_ = firstTab.SetReadDeadline(time.Now().Add(time.Second))
+		_, _, err = firstTab.ReadMessage()
+	}
+	assert.True(t, websocket.IsCloseError(err, closeCodeReplaced), "unexpected close: %v", err)
+
+	// the first tab's handler finishes without taking the user out of the room
+	require.Eventually(t, func() bool {
+		return s.connCloses.snapshot()[model.CloseReasonReplaced] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	assert.NotNil(t, registered(), "the second tab stays connected")
+	participant, err := s.findParticipant(ctx, roomID, userID)
+	require.NoError(t, err)
+	assert.NotNil(t, participant, "the user stays in the room")
+	instance, err := s.syncRepo.GetUserInstance(ctx, roomID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, s.instanceID, instance, "the user stays routed to this instance")
+	claimed, _, err := s.syncRepo.ClaimUsername(ctx, roomID, uuid.New(), "viewer", false)
+	require.NoError(t, err)
+	assert.False(t, claimed, "the user keeps their name")
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// pendingLeaves holds the leaves of dropped connections that may still be resumed, keyed by resume token
+type pendingLeaves struct {
+	mu     sync.Mutex
+	timers map[string]*time.Timer
+}
+
+func newPendingLeaves() *pendingLeaves {
+	return &pendingLeaves{timers: make(map[string]*time.Timer)}
+}
+
+// schedule runs leave once the grace period passed unless the token is resumed on this instance first
+func (p *pendingLeaves) schedule(token string, grace time.Duration, leave func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.timers[token] = time.AfterFunc(grace, func() {
+		p.mu.Lock()
+		delete(p.timers, token)
+		p.mu.Unlock()
+
+		leave()
+	})
+}
+
+// cancel stops the pending leave of a token, false when this instance holds none for it
+func (p *pendingLeaves) cancel(token string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	timer, ok := p.timers[token]
+	if !ok {
+		return false
+	}
+	timer.Stop()
+	delete(p.timers, token)
+	return true
+}
+
+// resumableClose reports whether a connection closed the way a network blip does, a client that closed its
+// socket on purpose or was removed by the server leaves right away
+func resumableClose(reason model.CloseReason) bool {
+	switch reason {
+	case model.CloseReasonReadError, model.CloseReasonIdleTimeout, model.CloseReasonSendFailures:
+		return true
+	default:
+		return false
+	}
+}
+
+// resumeGracePeriod returns how long a dropped client may resume its session, 0 when resuming is off
+func (s *syncService) resumeGracePeriod() time.Duration {
+	if s.degraded.active() {
+		// the token and the participant record both live in Redis
+		return 0
+	}
+	return s.config.Sync.ResumeGracePeriod.ToDuration()
+}
+
+// newResumeToken returns an unguessable token, holding it is what proves a reconnect continues a session
+func newResumeToken() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// sendResumeToken issues the token the client reconnects with after its connection drops, empty when resuming
+// is off
+func (s *syncService) sendResumeToken(roomID, userID uuid.UUID, conn *websocket.Conn) string {
+	grace := s.resumeGracePeriod()
+	if grace <= 0 {
+		return ""
+	}
+
+	token := newResumeToken()
+	err := s.sendToConnectionSafe(roomID, userID, conn, &model.WebSocketMessage{
+		Type:    model.MessageTypeResumeToken,
+		Payload: model.ResumeTokenMessage{Token: token, GraceSeconds: int(grace.Seconds())},
+	})
+	if err != nil {
+		logger.Error(err, "failed to send resume token")
+		return ""
+	}
+	return token
+}
+
+// resumeSession takes over the participant record a dropped connection left behind when token is still valid for
+// the room. a guest gets a new ID on every connection, so the ID the session had is returned and used instead
+func (s *syncService) resumeSession(ctx context.Context, roomID, userID uuid.UUID, isGuest bool, token string) (uuid.UUID, bool) {
+	if token == "" || s.resumeGracePeriod() <= 0 {
+		return userID, false
+	}
+
+	tokenRoomID, tokenUserID, err := s.syncRepo.GetResumeToken(ctx, token)
+	if err != nil {
+		logger.Errorf(err, "failed to check resume token in room %s", roomID)
+		return userID, false
+	}
+	if tokenRoomID != roomID || (!isGuest && tokenUserID != userID) {
+		return userID, false
+	}
+
+	// the reconciler may have dropped the participant already, the client then joins like anyone else
+	participant, err := s.findParticipant(ctx, roomID, tokenUserID)
+	if err != nil || participant == nil || participant.IsGuest != isGuest {
+		return userID, false
+	}
+
+	consumed, err := s.syncRepo.ConsumeResumeToken(ctx, token, roomID, tokenUserID)
+	if err != nil {
+		logger.Errorf(err, "failed to consume resume token in room %s", roomID)
+		return userID, false
+	}
+	if !consumed {
+		// the grace period ran out meanwhile and the leave went through
+		return userID, false
+	}
+	s.pendingLeaves.cancel(token)
+
+	return tokenUserID, true
+}
+
+// findParticipant returns the participant record of a user in a room, nil when the user is not listed
+func (s *syncService) findParticipant(ctx context.Context, roomID, userID uuid.UUID) (*model.ParticipantInfo, error) {
+	participants, err := s.syncRepo.GetParticipants(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range participants {
+		if participants[i].UserID == userID {
+			return &participants[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// rejoinRoom refreshes the participant record a resumed session kept, without announcing a join
+func (s *syncService) rejoinRoom(ctx context.Context, roomID, userID uuid.UUID) {
+	err := s.syncRepo.UpdateParticipantPresence(ctx, roomID, userID)
+	if err != nil {
+		logger.Error(err, "failed to update participant presence")
+	}
+
+	err = s.syncRepo.SetUserPresence(ctx, userID, roomID)
+	if err != nil {
+		logger.Error(err, "failed to set user presence")
+	}
+
+	logger.Infof("user %s resumed their session in room %s", userID, roomID)
+}
+
+// leaveOrAwaitResume removes a participant whose connection ended, or keeps them listed for the grace period when
+// the connection dropped and the client holds a resume token. a participant whose connection was replaced stays
+func (s *syncService) leaveOrAwaitResume(ctx context.Context, roomID, userID uuid.UUID, conn *websocket.Conn, token string, readErr error) {
+	reason := s.connCloses.reason(conn, readErr)
+	if reason == model.CloseReasonReplaced {
+		// the user is still in the room through the connection that replaced this one
+		return
+	}
+
+	grace := s.resumeGracePeriod()
+	if token == "" || grace <= 0 || !resumableClose(reason) {
+		s.LeaveRoom(ctx, roomID, userID)
+		return
+	}
+
+	// the token outlives the pending leave, so the leave still finds it when this instance runs it late
+	stored, err := s.syncRepo.StoreResumeToken(ctx, token, roomID, userID, 2*grace)
+	if err != nil || !stored {
+		if err != nil {
+			logger.Errorf(err, "failed to store resume token of user %s in room %s", userID, roomID)
+		}
+		s.LeaveRoom(ctx, roomID, userID)
+		return
+	}
+
+	s.pendingLeaves.schedule(token, grace, func() {
+		ctx := context.Background()
+		consumed, err := s.syncRepo.ConsumeResumeToken(ctx, token, roomID, userID)
+		if err != nil {
+			// the reconciler removes the participant later if nobody resumed
+			logger.Errorf(err, "failed to expire resume token of user %s in room %s", userID, roomID)
+			return
+		}
+		// otherwise a resumed session consumed the token first and owns the participant record now
+		if consumed {
+			s.LeaveRoom(ctx, roomID, userID)
+		}
+	})
+
+	logger.Infof("connection of user %s in room %s dropped, keeping their place for %s", userID, roomID, grace)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"watch-party/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumeSession(t *testing.T) {
+	s, roomID, hostID, hostConn := newRouterTestService(t)
+	s.config.Sync.ResumeGracePeriod = config.Duration(time.Minute)
+	ctx := context.Background()
+
+	// the host's connection drops, they stay listed while the token is valid
+	s.connCloses.watch(hostConn)
+	token := newResumeToken()
+	s.removeConnection(roomID, hostID, hostConn)
+	s.leaveOrAwaitResume(ctx, roomID, hostID, hostConn, token, timeoutError{})
+
+	participant, err := s.findParticipant(ctx, roomID, hostID)
+	require.NoError(t, err)
+	require.NotNil(t, participant, "a dropped participant stays listed during the grace period")
+
+	userID, resumed := s.resumeSession(ctx, uuid.New(), hostID, false, token)
+	assert.False(t, resumed, "a token only resumes the room it was issued in")
+	assert.Equal(t, hostID, userID)
+
+	_, resumed = s.resumeSession(ctx, roomID, uuid.New(), false, token)
+	assert.False(t, resumed, "a registered user cannot resume someone else's session")
+
+	userID, resumed = s.resumeSession(ctx, roomID, hostID, false, token)
+	assert.True(t, resumed)
+	assert.Equal(t, hostID, userID)
+	assert.False(t, s.pendingLeaves.cancel(token), "resuming cancels the pending leave")
+
+	_, resumed = s.resumeSession(ctx, roomID, hostID, false, token)
+	assert.False(t, resumed, "a token resumes once")
+}
+
+func TestLeaveOrAwaitResumeLeavesOnPurpose(t *testing.T) {
+	s, roomID, hostID, hostConn := newRouterTestService(t)
+	s.config.Sync.ResumeGracePeriod = config.Duration(time.Minute)
+	ctx := context.Background()
+
+	// closing the socket on purpose is a leave, the token is not kept
+	s.connCloses.watch(hostConn)
+	token := newResumeToken()
+	s.removeConnection(roomID, hostID, hostConn)
+	s.leaveOrAwaitResume(ctx, roomID, hostID, hostConn, token, nil)
+
+	participant, err := s.findParticipant(ctx, roomID, hostID)
+	require.NoError(t, err)
+	assert.Nil(t, participant)
+
+	_, resumed := s.resumeSession(ctx, roomID, hostID, false, token)
+	assert.False(t, resumed)
+}
+
+func TestLeaveOrAwaitResumeGracePeriodPasses(t *testing.T) {
+	s, roomID, hostID, hostConn := newRouterTestService(t)
+	s.config.Sync.ResumeGracePeriod = config.Duration(20 * time.Millisecond)
+	ctx := context.Background()
+
+	s.connCloses.watch(hostConn)
+	token := newResumeToken()
+	s.removeConnection(roomID, hostID, hostConn)
+	s.leaveOrAwaitResume(ctx, roomID, hostID, hostConn, token, errors.New("connection reset by peer"))
+
+	require.Eventually(t, func() bool {
+		participant, err := s.findParticipant(ctx, roomID, hostID)
+		return err == nil && participant == nil
+	}, time.Second, 10*time.Millisecond, "the participant leaves once the grace period passed")
+
+	_, resumed := s.resumeSession(ctx, roomID, hostID, false, token)
+	assert.False(t, resumed)
+}
+
+func TestLeaveRoomKeepsUserWithAnotherTab(t *testing.T) {
+	s, roomID, hostID, hostConn := newRouterTestService(t)
+	ctx := context.Background()
+
+	// the host's tab on this instance closes while another tab is connected through another instance
+	s.removeConnection(roomID, hostID, hostConn)
+	require.NoError(t, s.syncRepo.SetUserInstance(ctx, roomID, hostID, "other-instance"))
+	s.leaveOrAwaitResume(ctx, roomID, hostID, hostConn, "", nil)
+
+	participant, err := s.findParticipant(ctx, roomID, hostID)
+	require.NoError(t, err)
+	assert.NotNil(t, participant, "the host stays listed while another tab is connected")
+
+	// once the last tab is gone the host leaves
+	require.NoError(t, s.syncRepo.RemoveUserInstance(ctx, roomID, hostID, "other-instance"))
+	s.leaveOrAwaitResume(ctx, roomID, hostID, hostConn, "", nil)
+
+	participant, err = s.findParticipant(ctx, roomID, hostID)
+	require.NoError(t, err)
+	assert.Nil(t, participant)
+}
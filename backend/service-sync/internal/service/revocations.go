@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// handleTokenRevocations closes the connections of users whose tokens were revoked by service-api
+func (s *syncService) handleTokenRevocations(ctx context.Context) {
+	pubsub := s.redis.Subscribe(ctx, redis.TokenRevocationChannel)
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			var revoked model.TokensRevokedMessage
+			if err := json.Unmarshal([]byte(msg.Payload), &revoked); err != nil {
+				logger.Errorf(err, "failed to unmarshal token revocation message")
+				continue
+			}
+
+			s.disconnectUser(revoked.UserID)
+		}
+	}
+}
+
+// disconnectUser closes every local connection of a user, the connection handlers clean up after them
+func (s *syncService) disconnectUser(userID uuid.UUID) {
+	s.connMutex.RLock()
+	userConns := make(map[uuid.UUID]*websocket.Conn)
+	for roomID, roomConns := range s.connections {
+		if conn, exists := roomConns[userID]; exists {
+			userConns[roomID] = conn
+		}
+	}
+	s.connMutex.RUnlock()
+
+	for roomID, conn := range userConns {
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "SESSION_REVOKED", "your session has been revoked")
+		if err := s.closeConnection(conn, model.CloseReasonKicked); err != nil {
+			logger.Errorf(err, "failed to close connection of user %s in room %s", userID, roomID)
+		}
+		logger.Infof("closed connection of user %s in room %s after token revocation", userID, roomID)
+	}
+}
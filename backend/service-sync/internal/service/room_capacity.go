@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"watch-party/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// ErrRoomFull is returned when a room already has as many participants as its settings allow
+var ErrRoomFull = errors.New("room is full")
+
+// checkRoomCapacity refuses a new participant once the room has as many as its max_participants setting allows.
+// the host and users who are already participants, e.g. from another tab or resuming, always get in
+func (s *syncService) checkRoomCapacity(ctx context.Context, roomID, userID uuid.UUID) error {
+	// like the room limit, unreadable settings must not lock everyone out, the defaults have no limit
+	settings := s.getRoomSettings(ctx, roomID)
+	if settings.MaxParticipants <= 0 {
+		return nil
+	}
+
+	if hostID, ok := s.loadRoomHost(ctx, roomID); ok && hostID == userID {
+		return nil
+	}
+
+	participants, err := s.syncRepo.GetParticipants(ctx, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to count participants of room %s", roomID)
+		return nil
+	}
+	for _, participant := range participants {
+		if participant.UserID == userID {
+			return nil
+		}
+	}
+
+	if len(participants) >= settings.MaxParticipants {
+		return fmt.Errorf("%w: the room allows %d participants", ErrRoomFull, settings.MaxParticipants)
+	}
+	return nil
+}
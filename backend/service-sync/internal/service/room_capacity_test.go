@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoomCapacity(t *testing.T) {
+	s, roomID, hostID, _ := newRouterTestService(t)
+	ctx := context.Background()
+
+	var viewerID uuid.UUID
+	for userID := range s.connections[roomID] {
+		if userID != hostID {
+			viewerID = userID
+		}
+	}
+	newcomer := uuid.New()
+
+	// the default settings have no limit
+	assert.NoError(t, s.checkRoomCapacity(ctx, roomID, newcomer))
+
+	settings := model.DefaultRoomSettings()
+	settings.MaxParticipants = 2
+	require.NoError(t, s.redis.Set(ctx, redis.RoomSettingsKey(roomID), settings, time.Minute))
+
+	assert.ErrorIs(t, s.checkRoomCapacity(ctx, roomID, newcomer), ErrRoomFull)
+	assert.NoError(t, s.checkRoomCapacity(ctx, roomID, viewerID), "a participant reconnecting keeps their place")
+
+	// the host gets into their own room even when it filled up without them
+	require.NoError(t, s.syncRepo.RemoveParticipant(ctx, roomID, hostID))
+	require.NoError(t, s.syncRepo.AddParticipant(ctx, roomID, newcomer, &model.ParticipantInfo{UserID: newcomer, JoinedAt: time.Now(), LastSeen: time.Now()}))
+	assert.NoError(t, s.checkRoomCapacity(ctx, roomID, hostID))
+
+	// a refused connection is counted under its own close reason and never joins
+	late := uuid.New()
+	conn, _ := dialRecordingPeer(t)
+	err := s.HandleConnection(ctx, roomID, late, "late", true, "", model.CurrentProtocolVersion, conn)
+	assert.ErrorIs(t, err, ErrRoomFull)
+	assert.Equal(t, int64(1), s.connCloses.snapshot()[model.CloseReasonMaxParticipants])
+	_, connected := s.connections[roomID][late]
+	assert.False(t, connected)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrChatDisabled is returned for chat messages in a room whose host turned chat off
+	ErrChatDisabled = errors.New("chat is disabled in this room")
+	// ErrReactionsDisabled is returned for reactions in a room whose host turned reactions off
+	ErrReactionsDisabled = errors.New("reactions are disabled in this room")
+	// ErrPlaybackRateNotAllowed is returned for a playback rate the room's settings do not allow
+	ErrPlaybackRateNotAllowed = errors.New("playback rate is not allowed in this room")
+)
+
+// checkChatEnabled refuses chat messages in rooms with chat turned off, the host included so the room reads the
+// same for everyone. chat is refused too while the settings cannot be read
+func (s *syncService) checkChatEnabled(ctx context.Context, message *model.SyncMessage) error {
+	if message.Action != model.ActionChat {
+		return nil
+	}
+	settings, ok := s.loadRoomSettings(ctx, message.RoomID)
+	if !ok || !settings.ChatEnabled {
+		return ErrChatDisabled
+	}
+	return nil
+}
+
+// checkReactionsEnabled refuses reactions in rooms with reactions turned off, or whose settings cannot be read
+func (s *syncService) checkReactionsEnabled(ctx context.Context, roomID uuid.UUID) error {
+	settings, ok := s.loadRoomSettings(ctx, roomID)
+	if !ok || !settings.ReactionsEnabled {
+		return ErrReactionsDisabled
+	}
+	return nil
+}
+
+// playbackRateTolerance absorbs float noise when comparing a requested rate with the allowed ones
+const playbackRateTolerance = 1e-6
+
+// checkPlaybackRate refuses a playback rate outside the room's allowed rates, an empty list allows any supported
+// rate. normal speed is always allowed, other rates are refused while the settings cannot be read
+func (s *syncService) checkPlaybackRate(ctx context.Context, message *model.SyncMessage) error {
+	rate := message.Data.PlaybackRate
+	if rate <= 0 || math.Abs(rate-1) < playbackRateTolerance {
+		return nil
+	}
+
+	settings, ok := s.loadRoomSettings(ctx, message.RoomID)
+	if !ok {
+		return fmt.Errorf("%w: %.2fx", ErrPlaybackRateNotAllowed, rate)
+	}
+	if len(settings.AllowedPlaybackRates) == 0 {
+		if rate < model.MinPlaybackRate || rate > model.MaxPlaybackRate {
+			return fmt.Errorf("%w: %.2fx", ErrPlaybackRateNotAllowed, rate)
+		}
+		return nil
+	}
+	for _, allowed := range settings.AllowedPlaybackRates {
+		if math.Abs(allowed-rate) < playbackRateTolerance {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %.2fx", ErrPlaybackRateNotAllowed, rate)
+}
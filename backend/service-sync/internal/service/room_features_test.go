@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoomFeatureSettings(t *testing.T) {
+	s, roomID, hostID, _ := newRouterTestService(t)
+	ctx := context.Background()
+
+	chat := &model.SyncMessage{
+		RoomID:    roomID,
+		UserID:    hostID,
+		Username:  "host",
+		Action:    model.ActionChat,
+		Data:      model.SyncData{ChatMessage: "hello"},
+		Timestamp: time.Now(),
+	}
+
+	require.NoError(t, s.checkChatEnabled(ctx, chat))
+	require.NoError(t, s.checkReactionsEnabled(ctx, roomID))
+
+	// chat and reactions are refused in a room whose settings cannot be read
+	unknown := *chat
+	unknown.RoomID = uuid.New()
+	assert.ErrorIs(t, s.checkChatEnabled(ctx, &unknown), ErrChatDisabled)
+	assert.ErrorIs(t, s.checkReactionsEnabled(ctx, unknown.RoomID), ErrReactionsDisabled)
+
+	settings := model.DefaultRoomSettings()
+	settings.ChatEnabled = false
+	settings.ReactionsEnabled = false
+	require.NoError(t, s.redis.Set(ctx, redis.RoomSettingsKey(roomID), settings, time.Minute))
+
+	assert.ErrorIs(t, s.SyncAction(ctx, chat), ErrChatDisabled, "the host cannot chat either")
+	assert.ErrorIs(t, s.checkReactionsEnabled(ctx, roomID), ErrReactionsDisabled)
+
+	// playback is not affected
+	play := *chat
+	play.Action = model.ActionPlay
+	assert.NoError(t, s.checkChatEnabled(ctx, &play))
+}
+
+func TestPlaybackRateSettings(t *testing.T) {
+	s, roomID, hostID, _ := newRouterTestService(t)
+	ctx := context.Background()
+
+	seek := func(room uuid.UUID, rate float64) *model.SyncMessage {
+		return &model.SyncMessage{
+			RoomID: room,
+			UserID: hostID,
+			Action: model.ActionSeek,
+			Data:   model.SyncData{CurrentTime: 10, PlaybackRate: rate},
+		}
+	}
+
+	// the defaults allow the usual speeds
+	assert.NoError(t, s.checkPlaybackRate(ctx, seek(roomID, 1.5)))
+	assert.ErrorIs(t, s.checkPlaybackRate(ctx, seek(roomID, 3)), ErrPlaybackRateNotAllowed)
+	assert.ErrorIs(t, s.SyncAction(ctx, seek(roomID, 3)), ErrPlaybackRateNotAllowed)
+
+	settings := model.DefaultRoomSettings()
+	settings.AllowedPlaybackRates = []float64{1}
+	require.NoError(t, s.redis.Set(ctx, redis.RoomSettingsKey(roomID), settings, time.Minute))
+	assert.ErrorIs(t, s.checkPlaybackRate(ctx, seek(roomID, 1.5)), ErrPlaybackRateNotAllowed)
+	assert.NoError(t, s.checkPlaybackRate(ctx, seek(roomID, 0)), "messages without a rate keep the current one")
+
+	// an empty list allows any supported rate
+	settings.AllowedPlaybackRates = nil
+	require.NoError(t, s.redis.Set(ctx, redis.RoomSettingsKey(roomID), settings, time.Minute))
+	assert.NoError(t, s.checkPlaybackRate(ctx, seek(roomID, 3)))
+	assert.ErrorIs(t, s.checkPlaybackRate(ctx, seek(roomID, 8)), ErrPlaybackRateNotAllowed)
+
+	// only normal speed is allowed while the settings cannot be read
+	unknown := uuid.New()
+	assert.NoError(t, s.checkPlaybackRate(ctx, seek(unknown, 1)))
+	assert.ErrorIs(t, s.checkPlaybackRate(ctx, seek(unknown, 1.5)), ErrPlaybackRateNotAllowed)
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"watch-party/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// ErrRoomLimitReached is returned when a user is already watching in as many rooms as allowed
+var ErrRoomLimitReached = errors.New("already watching in the maximum number of rooms")
+
+// claimRoomSlot takes one of the user's concurrent room slots for this room, registered users only since
+// the limit is about shared accounts and every guest session has its own ID
+func (s *syncService) claimRoomSlot(ctx context.Context, roomID, userID uuid.UUID, isGuest bool) error {
+	limit := s.config.Sync.MaxRoomsPerUser
+	if isGuest || limit <= 0 {
+		return nil
+	}
+
+	allowed, err := s.syncRepo.ClaimUserRoom(ctx, userID, roomID, limit)
+	if err != nil {
+		// the limit is a policy control, Redis trouble must not lock everyone out of their rooms
+		logger.Errorf(err, "failed to check concurrent rooms of user %s", userID)
+		return nil
+	}
+	if !allowed {
+		return fmt.Errorf("%w: leave another room to join this one (limit %d)", ErrRoomLimitReached, limit)
+	}
+	return nil
+}
+
+// refreshUserPresence keeps the rooms of this instance's connections counted for their users
+func (s *syncService) refreshUserPresence(ctx context.Context) {
+	s.connMutex.RLock()
+	type roomUser struct{ roomID, userID uuid.UUID }
+	var active []roomUser
+	for roomID, roomConns := range s.connections {
+		for userID := range roomConns {
+			active = append(active, roomUser{roomID, userID})
+		}
+	}
+	s.connMutex.RUnlock()
+
+	for _, conn := range active {
+		if err := s.syncRepo.SetUserPresence(ctx, conn.userID, conn.roomID); err != nil {
+			logger.Errorf(err, "failed to refresh presence of user %s in room %s", conn.userID, conn.roomID)
+		}
+	}
+}
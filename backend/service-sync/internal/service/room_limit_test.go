@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoomLimitRefusal(t *testing.T) {
+	s, roomID, _, _ := newRouterTestService(t)
+	ctx := context.Background()
+	s.config.Sync.MaxRoomsPerUser = 1
+
+	// the user already watches in another room
+	userID := uuid.New()
+	allowed, err := s.syncRepo.ClaimUserRoom(ctx, userID, uuid.New(), 1)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	conn, _ := dialRecordingPeer(t)
+	err = s.HandleConnection(ctx, roomID, userID, "viewer", false, "", model.CurrentProtocolVersion, conn)
+	assert.ErrorIs(t, err, ErrRoomLimitReached)
+
+	// the refusal is told apart from a full room
+	closes := s.connCloses.snapshot()
+	assert.Equal(t, int64(1), closes[model.CloseReasonRoomLimit])
+	assert.Zero(t, closes[model.CloseReasonMaxParticipants])
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"watch-party/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// ErrRoomLocked is returned when another action kept the room locked for longer than an action waits
+var ErrRoomLocked = errors.New("room is locked by another user")
+
+const (
+	// roomLockWait is how long an action waits for the room lock, well above the time an action holds it
+	roomLockWait = 2 * time.Second
+	// roomLockRetryInterval is how often a waiting action tries the Redis lock again
+	roomLockRetryInterval = 50 * time.Millisecond
+	// maxRoomLockWaiters bounds the actions queued on one room, beyond it actions fail right away
+	maxRoomLockWaiters = 16
+)
+
+// roomLockQueue lines up this instance's actions on a room, so they try the Redis lock one at a time and
+// in arrival order instead of all polling it and winning at random
+type roomLockQueue struct {
+	mu    sync.Mutex
+	rooms map[uuid.UUID]*roomLockLine
+}
+
+// roomLockLine is the queue of one room, the channel holds a value while an action has its turn
+type roomLockLine struct {
+	turn    chan struct{}
+	waiters int
+}
+
+func newRoomLockQueue() *roomLockQueue {
+	return &roomLockQueue{rooms: make(map[uuid.UUID]*roomLockLine)}
+}
+
+// enter waits for the room's turn, blocked senders on a channel are served in the order they arrived.
+// the returned function ends the turn.
+func (q *roomLockQueue) enter(ctx context.Context, roomID uuid.UUID) (func(), error) {
+	q.mu.Lock()
+	line, ok := q.rooms[roomID]
+	if !ok {
+		line = &roomLockLine{turn: make(chan struct{}, 1)}
+		q.rooms[roomID] = line
+	}
+	if line.waiters >= maxRoomLockWaiters {
+		q.mu.Unlock()
+		return nil, fmt.Errorf("%w: too many actions waiting", ErrRoomLocked)
+	}
+	line.waiters++
+	q.mu.Unlock()
+
+	select {
+	case line.turn <- struct{}{}:
+		return func() {
+			<-line.turn
+			q.leave(roomID, line)
+		}, nil
+	case <-ctx.Done():
+		q.leave(roomID, line)
+		return nil, ErrRoomLocked
+	}
+}
+
+// leave removes a waiter and forgets the room once nobody is queued on it
+func (q *roomLockQueue) leave(roomID uuid.UUID, line *roomLockLine) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	line.waiters--
+	if line.waiters == 0 {
+		delete(q.rooms, roomID)
+	}
+}
+
+// acquireRoomLock takes the room lock, waiting up to roomLockWait for the current holder.
+// the returned function releases the lock and must be called once the action is done.
+func (s *syncService) acquireRoomLock(ctx context.Context, roomID uuid.UUID) (func(), error) {
+	waitCtx, cancel := context.WithTimeout(ctx, roomLockWait)
+	defer cancel()
+
+	endTurn, err := s.lockQueue.enter(waitCtx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	// without Redis only this instance's actions can reach the room, its queue alone orders them
+	if s.degraded.active() {
+		return endTurn, nil
+	}
+
+	// actions of other instances still compete through Redis, only the head of this instance's queue polls
+	ticker := time.NewTicker(roomLockRetryInterval)
+	defer ticker.Stop()
+
+	token := uuid.NewString()
+	for {
+		acquired, err := s.syncRepo.AcquireRoomLock(ctx, roomID, token)
+		if err != nil {
+			s.enterDegradedMode(fmt.Errorf("failed to acquire lock: %w", err))
+			return endTurn, nil
+		}
+		if acquired {
+			return func() {
+				if err := s.syncRepo.ReleaseRoomLock(ctx, roomID, token); err != nil {
+					logger.Errorf(err, "failed to release lock of room %s", roomID)
+				}
+				endTurn()
+			}, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-waitCtx.Done():
+			endTurn()
+			return nil, ErrRoomLocked
+		}
+	}
+}
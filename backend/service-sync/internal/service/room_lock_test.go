@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"watch-party/pkg/redis"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoomLockQueue_ServesInArrivalOrder(t *testing.T) {
+	queue := newRoomLockQueue()
+	roomID := uuid.New()
+
+	endFirst, err := queue.enter(context.Background(), roomID)
+	require.NoError(t, err)
+
+	const waiters = 5
+	order := make(chan int, waiters)
+	for i := 0; i < waiters; i++ {
+		go func(i int) {
+			endTurn, err := queue.enter(context.Background(), roomID)
+			if err != nil {
+				order <- -1
+				return
+			}
+			order <- i
+			endTurn()
+		}(i)
+
+		// wait until the goroutine is queued so arrival order is known
+		require.Eventually(t, func() bool {
+			queue.mu.Lock()
+			defer queue.mu.Unlock()
+			return queue.rooms[roomID].waiters == i+2
+		}, time.Second, time.Millisecond)
+	}
+
+	endFirst()
+	for i := 0; i < waiters; i++ {
+		assert.Equal(t, i, <-order)
+	}
+
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	assert.Empty(t, queue.rooms, "rooms nobody waits on are forgotten")
+}
+
+func TestRoomLockQueue_BoundsWaiters(t *testing.T) {
+	queue := newRoomLockQueue()
+	roomID := uuid.New()
+
+	endFirst, err := queue.enter(context.Background(), roomID)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 1; i < maxRoomLockWaiters; i++ {
+		go queue.enter(ctx, roomID)
+	}
+	require.Eventually(t, func() bool {
+		queue.mu.Lock()
+		defer queue.mu.Unlock()
+		return queue.rooms[roomID].waiters == maxRoomLockWaiters
+	}, time.Second, time.Millisecond)
+
+	_, err = queue.enter(context.Background(), roomID)
+	assert.True(t, errors.Is(err, ErrRoomLocked))
+
+	// waiters that give up leave the queue
+	cancel()
+	require.Eventually(t, func() bool {
+		queue.mu.Lock()
+		defer queue.mu.Unlock()
+		return queue.rooms[roomID].waiters == 1
+	}, time.Second, time.Millisecond)
+	endFirst()
+}
+
+func TestRoomLockQueue_WaitTimesOut(t *testing.T) {
+	queue := newRoomLockQueue()
+	roomID := uuid.New()
+
+	endFirst, err := queue.enter(context.Background(), roomID)
+	require.NoError(t, err)
+	defer endFirst()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = queue.enter(ctx, roomID)
+	assert.True(t, errors.Is(err, ErrRoomLocked))
+}
+
+func TestRoomLockReleaseKeepsNextHolder(t *testing.T) {
+	server := miniredis.RunT(t)
+	s, roomID, _, _ := newRouterTestServiceOn(t, server)
+	ctx := context.Background()
+
+	// an action of a user outlives its lock and the user's next action takes the room
+	release, err := s.acquireRoomLock(ctx, roomID)
+	require.NoError(t, err)
+	server.FastForward(10 * time.Second)
+	acquired, err := s.syncRepo.AcquireRoomLock(ctx, roomID, "next")
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	release()
+	holder, err := server.Get(redis.RoomLockKey(roomID))
+	require.NoError(t, err)
+	assert.Equal(t, "next", holder, "the expired acquisition does not release the next one")
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// sendToUser delivers a message to one participant, wherever their connection lives.
+// room-wide events go through the room's event channel; this is the path for targeted messages.
+func (s *syncService) sendToUser(ctx context.Context, roomID, userID uuid.UUID, message *model.WebSocketMessage) error {
+	s.connMutex.RLock()
+	conn, local := s.findConnection(roomID, userID)
+	s.connMutex.RUnlock()
+
+	if local {
+		return s.sendToConnectionSafe(roomID, userID, conn, message)
+	}
+
+	instanceID, err := s.syncRepo.GetUserInstance(ctx, roomID, userID)
+	if err != nil {
+		return err
+	}
+	if instanceID == "" || instanceID == s.instanceID {
+		return fmt.Errorf("user %s is not connected to room %s", userID, roomID)
+	}
+
+	err = s.redis.Publish(ctx, redis.SyncInstanceControlChannel(instanceID), &model.InstanceControlMessage{
+		ID:           uuid.New(),
+		RoomID:       roomID,
+		Action:       model.ActionDirectMessage,
+		IssuedAt:     time.Now(),
+		TargetUserID: userID,
+		Message:      message,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to route message to instance %s: %w", instanceID, err)
+	}
+
+	return nil
+}
+
+// deliverDirectMessage hands a message routed from another instance to the local connection of its target
+func (s *syncService) deliverDirectMessage(control *model.InstanceControlMessage) {
+	if control.Message == nil {
+		return
+	}
+
+	s.connMutex.RLock()
+	conn, exists := s.findConnection(control.RoomID, control.TargetUserID)
+	s.connMutex.RUnlock()
+
+	if !exists {
+		logger.Warnf("direct message for user %s in room %s arrived after they disconnected", control.TargetUserID, control.RoomID)
+		return
+	}
+
+	err := s.sendToConnectionSafe(control.RoomID, control.TargetUserID, conn, control.Message)
+	if err != nil {
+		logger.Errorf(err, "failed to deliver direct message to user %s", control.TargetUserID)
+	}
+}
+
+// registerUserConnection records that this instance holds the user's connection to the room
+func (s *syncService) registerUserConnection(ctx context.Context, roomID, userID uuid.UUID) {
+	err := s.syncRepo.SetUserInstance(ctx, roomID, userID, s.instanceID)
+	if err != nil {
+		logger.Errorf(err, "failed to register connection of user %s in room %s", userID, roomID)
+	}
+}
+
+// deregisterUserConnection removes the user's routing entry if it still points at this instance
+func (s *syncService) deregisterUserConnection(ctx context.Context, roomID, userID uuid.UUID) {
+	err := s.syncRepo.RemoveUserInstance(ctx, roomID, userID, s.instanceID)
+	if err != nil {
+		logger.Errorf(err, "failed to deregister connection of user %s in room %s", userID, roomID)
+	}
+}
+
+// userStillConnected reports whether the user holds a connection to the room, on this instance or another one
+func (s *syncService) userStillConnected(ctx context.Context, roomID, userID uuid.UUID) bool {
+	s.connMutex.RLock()
+	_, local := s.connections[roomID][userID]
+	s.connMutex.RUnlock()
+	if local {
+		return true
+	}
+
+	instanceID, err := s.syncRepo.GetUserInstance(ctx, roomID, userID)
+	if err != nil {
+		logger.Errorf(err, "failed to look up connection of user %s in room %s", userID, roomID)
+		return false
+	}
+	return instanceID != ""
+}
+
+// hasRemoteConnections reports whether another live instance holds connections for the room
+func (s *syncService) hasRemoteConnections(ctx context.Context, roomID uuid.UUID) bool {
+	instances, err := s.redis.RoomInstances(ctx, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to look up instances of room %s", roomID)
+		return false
+	}
+
+	for instanceID, count := range instances {
+		if instanceID != s.instanceID && count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// requestLiveStateFromRemoteUser asks a participant connected through another instance for the live state
+func (s *syncService) requestLiveStateFromRemoteUser(ctx context.Context, roomID, requesterID uuid.UUID, requesterConn *websocket.Conn) {
+	userInstances, err := s.syncRepo.GetUserInstances(ctx, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to look up remote participants of room %s", roomID)
+		s.sendStoredRoomStateSafe(ctx, roomID, requesterID, requesterConn)
+		return
+	}
+
+	for userIDStr, instanceID := range userInstances {
+		if instanceID == s.instanceID {
+			continue
+		}
+
+		sourceUserID, err := uuid.Parse(userIDStr)
+		if err != nil || sourceUserID == requesterID {
+			continue
+		}
+
+		// skip entries left behind by an instance that stopped without cleaning up
+		alive, err := s.redis.Exists(ctx, redis.SyncInstanceKey(instanceID))
+		if err != nil || alive == 0 {
+			continue
+		}
+
+		if !s.storePendingStateRequest(roomID, requesterID, requesterConn) {
+			logger.Warnf("too many pending state requests, sending stored state to %s in room %s", requesterID, roomID)
+			break
+		}
+
+		logger.Infof("requesting live state from participant %s on instance %s for new participant %s in room %s",
+			sourceUserID, instanceID, requesterID, roomID)
+		err = s.sendToUser(ctx, roomID, sourceUserID, &model.WebSocketMessage{
+			Type: model.MessageTypeRequestState,
+			Payload: map[string]interface{}{
+				"requester_id": requesterID.String(),
+			},
+		})
+		if err != nil {
+			logger.Error(err, "failed to route state request to remote participant")
+			break
+		}
+		return
+	}
+
+	logger.Warnf("no other users found in room %s, falling back to stored state", roomID)
+	s.sendStoredRoomStateSafe(ctx, roomID, requesterID, requesterConn)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/video"
+)
+
+// addSegmentPosition adds the segment of the active quality a play or seek lands in to its broadcast, so clients
+// can check their player aligned on it after seeking. the quality the sender reports wins over the room's
+// default quality. nothing is added while the movie's timeline is not cached
+func (s *syncService) addSegmentPosition(ctx context.Context, message *model.SyncMessage, state *model.RoomState) {
+	if message.Action != model.ActionPlay && message.Action != model.ActionSeek {
+		return
+	}
+
+	movieID, err := s.syncRepo.GetRoomMovie(ctx, message.RoomID)
+	if err != nil {
+		return
+	}
+
+	quality, _ := message.Data.Extra["quality"].(string)
+	if quality == "" {
+		quality = state.DefaultQuality
+	}
+
+	quality, timeline, err := s.syncRepo.GetSegmentTimeline(ctx, movieID, quality)
+	if err != nil {
+		logger.Errorf(err, "failed to get segment timeline of room %s", message.RoomID)
+		return
+	}
+	if timeline == nil {
+		return
+	}
+
+	index, startTime := video.FindSegmentByTime(timeline.Segments, state.CurrentTime)
+	if index < 0 {
+		return
+	}
+
+	if message.Data.Extra == nil {
+		message.Data.Extra = make(map[string]interface{})
+	}
+	message.Data.Extra["segment_index"] = index
+	message.Data.Extra["segment_start_time"] = startTime
+	message.Data.Extra["segment_quality"] = quality
+}
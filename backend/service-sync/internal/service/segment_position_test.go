@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+	"watch-party/pkg/video"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddSegmentPosition(t *testing.T) {
+	server := miniredis.RunT(t)
+	s, roomID, hostID, _ := newRouterTestServiceOn(t, server)
+	ctx := context.Background()
+
+	seek := func(currentTime float64, extra map[string]interface{}) *model.SyncMessage {
+		message := &model.SyncMessage{RoomID: roomID, UserID: hostID, Action: model.ActionSeek,
+			Data: model.SyncData{CurrentTime: currentTime, Extra: extra}}
+		s.addSegmentPosition(ctx, message, &model.RoomState{CurrentTime: currentTime, DefaultQuality: model.Quality720p})
+		return message
+	}
+
+	assert.Nil(t, seek(7, nil).Data.Extra, "nothing is added before the room's movie is cached")
+
+	movieID := uuid.New()
+	encodedMovieID, _ := json.Marshal(movieID.String())
+	server.Set(redis.RoomMovieKey(roomID), string(encodedMovieID))
+	segments, total := video.ParseSegments("#EXTINF:6.0,\nsegment_000.ts\n#EXTINF:6.0,\nsegment_001.ts\n#EXTINF:4.5,\nsegment_002.ts\n")
+	timeline, _ := json.Marshal(video.SegmentTimeline{Segments: segments, TotalDuration: total})
+	server.HSet(redis.MovieSegmentsKey(movieID), model.Quality1080p, string(timeline))
+
+	extra := seek(7, nil).Data.Extra
+	require.NotNil(t, extra, "an uncached default quality falls back to a cached one")
+	assert.Equal(t, 1, extra["segment_index"])
+	assert.Equal(t, 6.0, extra["segment_start_time"])
+	assert.Equal(t, model.Quality1080p, extra["segment_quality"])
+
+	extra = seek(12, map[string]interface{}{"quality": model.Quality1080p}).Data.Extra
+	assert.Equal(t, 2, extra["segment_index"], "a seek to a boundary lands in the segment starting there")
+
+	// the segment reaches the other participants, whatever protocol version they speak
+	viewerID := uuid.New()
+	viewerConn, viewerReceived := dialRecordingPeer(t)
+	s.addConnection(roomID, viewerID, viewerConn)
+	s.broadcastSyncToRoom(roomID, seek(7, nil), hostID)
+	payload := receive(t, viewerReceived)["payload"].(map[string]interface{})
+	received, ok := payload["extra"].(map[string]interface{})
+	require.True(t, ok, "the broadcast carries the segment")
+	assert.Equal(t, float64(1), received["segment_index"])
+	assert.Equal(t, 6.0, received["segment_start_time"])
+	assert.Equal(t, model.Quality1080p, received["segment_quality"])
+
+	pause := &model.SyncMessage{RoomID: roomID, Action: model.ActionPause}
+	s.addSegmentPosition(ctx, pause, &model.RoomState{CurrentTime: 7})
+	assert.Nil(t, pause.Data.Extra, "only plays and seeks carry the segment")
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// sendFailures counts the consecutive failed writes of each connection. a connection whose write side broke can
+// keep a working read side for a long time, so it would otherwise stay listed until the client goes away
+type sendFailures struct {
+	mu      sync.Mutex
+	counts  map[*websocket.Conn]int
+	dropped map[*websocket.Conn]bool
+}
+
+func newSendFailures() *sendFailures {
+	return &sendFailures{
+		counts:  make(map[*websocket.Conn]int),
+		dropped: make(map[*websocket.Conn]bool),
+	}
+}
+
+// watch starts counting the failed writes of a connection that joined a room
+func (f *sendFailures) watch(conn *websocket.Conn) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.counts[conn] = 0
+}
+
+// record notes the outcome of a write to conn and reports whether conn just reached limit consecutive failures.
+// writes to connections that aren't watched, like ones still joining or already gone, are ignored
+func (f *sendFailures) record(conn *websocket.Conn, err error, limit int) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, watched := f.counts[conn]; !watched {
+		return false
+	}
+	if err == nil {
+		f.counts[conn] = 0
+		return false
+	}
+
+	f.counts[conn]++
+	if f.counts[conn] < limit || f.dropped[conn] {
+		return false
+	}
+	f.dropped[conn] = true
+	return true
+}
+
+// forget stops tracking conn once it is gone, reporting whether it was dropped for failing writes
+func (f *sendFailures) forget(conn *websocket.Conn) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	dropped := f.dropped[conn]
+	delete(f.counts, conn)
+	delete(f.dropped, conn)
+	return dropped
+}
+
+// maxSendFailures returns how many writes in a row may fail before a connection is dropped
+func (s *syncService) maxSendFailures() int {
+	if s.config.Sync.MaxSendFailures <= 0 {
+		return config.DefaultSyncMaxSendFailures
+	}
+	return s.config.Sync.MaxSendFailures
+}
+
+// trackSend closes a connection once its writes kept failing. its read loop then ends and the participant leaves
+// the room the usual way
+func (s *syncService) trackSend(roomID, userID uuid.UUID, conn *websocket.Conn, err error) {
+	if !s.sendFailures.record(conn, err, s.maxSendFailures()) {
+		return
+	}
+
+	logger.Warnf("dropping connection of user %s in room %s after %d failed sends: %v", userID, roomID, s.maxSendFailures(), err)
+	s.closeConnection(conn, model.CloseReasonSendFailures)
+}
+
+// broadcastParticipants sends the current participant list to everyone connected to the room on this instance
+func (s *syncService) broadcastParticipants(ctx context.Context, roomID uuid.UUID) {
+	participants, err := s.syncRepo.GetParticipants(ctx, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to get participants for room %s", roomID)
+		return
+	}
+
+	s.broadcastToRoom(roomID, &model.WebSocketMessage{
+		Type:    model.MessageTypeParticipants,
+		Payload: participants,
+	})
+}
+
+// announceDroppedConnection sends the roster once a connection dropped for failing sends took its participant out of
+// the room. a participant kept for a resume or connected through another tab is still listed, so nothing is sent
+func (s *syncService) announceDroppedConnection(ctx context.Context, roomID, userID uuid.UUID, conn *websocket.Conn) {
+	if !s.sendFailures.forget(conn) {
+		return
+	}
+
+	participant, err := s.findParticipant(ctx, roomID, userID)
+	if err != nil || participant != nil {
+		return
+	}
+	s.broadcastParticipants(ctx, roomID)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendFailures(t *testing.T) {
+	failures := newSendFailures()
+	conn, other := &websocket.Conn{}, &websocket.Conn{}
+	errBroken := errors.New("broken pipe")
+
+	assert.False(t, failures.record(other, errBroken, 1), "connections that aren't watched are ignored")
+
+	failures.watch(conn)
+	assert.False(t, failures.record(conn, errBroken, 3))
+	assert.False(t, failures.record(conn, errBroken, 3))
+	assert.False(t, failures.record(conn, nil, 3), "a successful send resets the count")
+	assert.False(t, failures.record(conn, errBroken, 3))
+	assert.False(t, failures.record(conn, errBroken, 3))
+	assert.True(t, failures.record(conn, errBroken, 3))
+	assert.False(t, failures.record(conn, errBroken, 3), "a connection is only dropped once")
+
+	assert.True(t, failures.forget(conn))
+	assert.False(t, failures.record(conn, errBroken, 1), "forgotten connections are ignored")
+	assert.False(t, failures.forget(other))
+}
+
+func TestAnnounceDroppedConnection(t *testing.T) {
+	s, roomID, hostID, _ := newRouterTestService(t)
+	ctx := context.Background()
+
+	var viewerID uuid.UUID
+	for userID := range s.connections[roomID] {
+		if userID != hostID {
+			viewerID = userID
+		}
+	}
+	peer, received := dialRecordingPeer(t)
+	s.addConnection(roomID, hostID, peer)
+
+	dropped := dialDiscardingPeer(t)
+	drop := func() {
+		s.sendFailures.watch(dropped)
+		s.sendFailures.record(dropped, errors.New("broken pipe"), 1)
+	}
+
+	// a participant still listed, waiting for a resume, is not announced
+	drop()
+	s.announceDroppedConnection(ctx, roomID, viewerID, dropped)
+	// nor is a connection that closed for another reason
+	require.NoError(t, s.syncRepo.RemoveParticipant(ctx, roomID, viewerID))
+	s.sendFailures.watch(dropped)
+	s.announceDroppedConnection(ctx, roomID, viewerID, dropped)
+	select {
+	case data := <-received:
+		t.Fatalf("unexpected message %s", data)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// once the participant is gone the roster is sent
+	drop()
+	s.announceDroppedConnection(ctx, roomID, viewerID, dropped)
+	message := receive(t, received)
+	assert.Equal(t, string(model.MessageTypeParticipants), message["type"])
+	participants := message["payload"].([]interface{})
+	assert.Len(t, participants, 1)
+}
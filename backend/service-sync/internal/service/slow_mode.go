@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// ErrChatRateLimited is returned when a participant chats again before the room's slow mode interval passed
+var ErrChatRateLimited = errors.New("slow mode is on in this room")
+
+// checkChatRate enforces the room's slow mode on chat messages. the host and co-hosts are exempt, and a Redis
+// failure lets the message through, slow mode curbs spam but must not silence the room.
+func (s *syncService) checkChatRate(ctx context.Context, message *model.SyncMessage) error {
+	if message.Action != model.ActionChat || s.degraded.active() {
+		return nil
+	}
+
+	interval, err := s.syncRepo.GetChatSlowMode(ctx, message.RoomID)
+	if err != nil {
+		logger.Errorf(err, "failed to get slow mode of room %s", message.RoomID)
+		return nil
+	}
+	if interval <= 0 || s.canControlPlayback(ctx, message.RoomID, message.UserID) {
+		return nil
+	}
+
+	allowed, err := s.syncRepo.AllowChatMessage(ctx, message.RoomID, message.UserID, interval)
+	if err != nil {
+		logger.Errorf(err, "failed to check chat rate in room %s", message.RoomID)
+		return nil
+	}
+	if !allowed {
+		return fmt.Errorf("%w, wait %s between messages", ErrChatRateLimited, interval)
+	}
+	return nil
+}
+
+// handleSetSlowMode lets the host set the minimum gap between two chat messages of a participant, 0 turns it off
+func (s *syncService) handleSetSlowMode(ctx context.Context, roomID, userID uuid.UUID, conn *websocket.Conn, rawMessage map[string]interface{}) {
+	hostID, err := s.syncRepo.GetRoomHost(ctx, roomID)
+	if err != nil || hostID != userID {
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "FORBIDDEN", "only the room host can change slow mode")
+		return
+	}
+
+	interval, err := parseSlowModeInterval(rawMessage)
+	if err != nil {
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "INVALID_SLOW_MODE", err.Error())
+		return
+	}
+
+	err = s.syncRepo.SetChatSlowMode(ctx, roomID, interval)
+	if err != nil {
+		logger.Errorf(err, "failed to store slow mode for room %s", roomID)
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "SYNC_ERROR", "Failed to update slow mode")
+		return
+	}
+
+	message := &model.SyncMessage{
+		ID:        uuid.New(),
+		RoomID:    roomID,
+		UserID:    userID,
+		Action:    model.ActionSlowModeChanged,
+		Timestamp: time.Now(),
+		Data: model.SyncData{
+			Extra: map[string]interface{}{
+				"interval_seconds": int(interval.Seconds()),
+			},
+		},
+	}
+
+	err = s.syncRepo.PublishEvent(ctx, roomID, message)
+	if err != nil {
+		logger.Error(err, "failed to publish slow mode change to Redis")
+		s.handleSlowModeChanged(message)
+	}
+
+	logger.Infof("slow mode for room %s set to %s by %s", roomID, interval, userID)
+}
+
+// parseSlowModeInterval reads the whole number of seconds of a set_slow_mode message
+func parseSlowModeInterval(rawMessage map[string]interface{}) (time.Duration, error) {
+	seconds, ok := rawMessage["interval_seconds"].(float64)
+	maxSeconds := model.MaxChatSlowModeInterval.Seconds()
+	if !ok || seconds < 0 || seconds > maxSeconds || seconds != math.Trunc(seconds) {
+		return 0, fmt.Errorf("interval_seconds must be a whole number between 0 and %.0f", maxSeconds)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// handleSlowModeChanged delivers a slow mode change published by any instance to the local participants
+func (s *syncService) handleSlowModeChanged(syncMessage *model.SyncMessage) {
+	seconds, _ := syncMessage.Data.Extra["interval_seconds"].(float64)
+	if value, ok := syncMessage.Data.Extra["interval_seconds"].(int); ok {
+		// published locally without going through JSON
+		seconds = float64(value)
+	}
+
+	s.broadcastToRoom(syncMessage.RoomID, &model.WebSocketMessage{
+		Type: model.MessageTypeSlowMode,
+		Payload: model.SlowModeMessage{
+			IntervalSeconds: int(seconds),
+			SetBy:           syncMessage.UserID,
+		},
+		Seq: syncMessage.Seq,
+	})
+}
+
+// sendSlowMode tells a joining participant about slow mode when the room has it on
+func (s *syncService) sendSlowMode(ctx context.Context, roomID, userID uuid.UUID, conn *websocket.Conn) {
+	interval, err := s.syncRepo.GetChatSlowMode(ctx, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to get slow mode of room %s", roomID)
+		return
+	}
+	if interval <= 0 {
+		return
+	}
+
+	err = s.sendToConnectionSafe(roomID, userID, conn, &model.WebSocketMessage{
+		Type:    model.MessageTypeSlowMode,
+		Payload: model.SlowModeMessage{IntervalSeconds: int(interval.Seconds())},
+	})
+	if err != nil {
+		logger.Error(err, "failed to send slow mode")
+	}
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSlowModeInterval(t *testing.T) {
+	interval, err := parseSlowModeInterval(map[string]interface{}{"interval_seconds": float64(30)})
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, interval)
+
+	interval, err = parseSlowModeInterval(map[string]interface{}{"interval_seconds": float64(0)})
+	require.NoError(t, err)
+	assert.Zero(t, interval, "0 turns slow mode off")
+
+	for _, value := range []interface{}{nil, "30", float64(-1), float64(1.5), float64(601)} {
+		_, err := parseSlowModeInterval(map[string]interface{}{"interval_seconds": value})
+		assert.Error(t, err, "interval_seconds %v", value)
+	}
+}
+
+func TestSlowModeOutlivesIdleRoom(t *testing.T) {
+	server := miniredis.RunT(t)
+	s, roomID, _, _ := newRouterTestServiceOn(t, server)
+	ctx := context.Background()
+
+	require.NoError(t, s.syncRepo.SetChatSlowMode(ctx, roomID, 30*time.Second))
+	server.FastForward(48 * time.Hour)
+
+	interval, err := s.syncRepo.GetChatSlowMode(ctx, roomID)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, interval, "slow mode stays until the host turns it off")
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+
+	"watch-party/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// isSoloViewer reports whether the user's connection is the only one the room has on any instance. a solo viewer
+// has nobody to race for the room lock or to tell about an action, the next participant to join asks for the
+// live state anyway. when in doubt the room is treated as shared.
+func (s *syncService) isSoloViewer(ctx context.Context, roomID, userID uuid.UUID) bool {
+	if s.degraded.active() {
+		return false
+	}
+
+	s.connMutex.RLock()
+	roomConns := s.connections[roomID]
+	_, connected := roomConns[userID]
+	alone := connected && len(roomConns) == 1
+	s.connMutex.RUnlock()
+	if !alone {
+		return false
+	}
+
+	instances, err := s.redis.RoomInstances(ctx, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to look up instances of room %s", roomID)
+		return false
+	}
+	for instanceID, count := range instances {
+		if instanceID != s.instanceID && count > 0 {
+			return false
+		}
+	}
+	return true
+}
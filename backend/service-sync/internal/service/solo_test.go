@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncActionSoloViewer(t *testing.T) {
+	server := miniredis.RunT(t)
+	s, roomID, hostID, _ := newRouterTestServiceOn(t, server)
+	ctx := context.Background()
+	seqKey := "watch-party:room:events:seq:" + roomID.String()
+
+	play := func() {
+		err := s.SyncAction(ctx, &model.SyncMessage{
+			RoomID:    roomID,
+			UserID:    hostID,
+			Action:    model.ActionPlay,
+			Data:      model.SyncData{CurrentTime: 42},
+			Timestamp: time.Now(),
+		})
+		require.NoError(t, err)
+	}
+
+	// the viewer left, the host watches alone
+	for userID, conn := range s.connections[roomID] {
+		if userID != hostID {
+			s.removeConnection(roomID, userID, conn)
+		}
+	}
+	play()
+	assert.False(t, server.Exists(seqKey), "a solo action is not published")
+	state, err := s.syncRepo.GetRoomState(ctx, roomID)
+	require.NoError(t, err)
+	assert.True(t, state.IsPlaying, "a solo action still updates the stored state")
+
+	// a participant connected through another instance brings the room back to full sync
+	require.NoError(t, server.Set(redis.SyncInstanceKey("other-instance"), "{}"))
+	require.NoError(t, s.syncRepo.SetInstanceConnections(ctx, roomID, "other-instance", 1))
+	play()
+	assert.True(t, server.Exists(seqKey))
+}
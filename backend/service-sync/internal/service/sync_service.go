@@ -3,10 +3,14 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"slices"
 	"sync"
 	"time"
 
+	"watch-party/pkg/config"
 	"watch-party/pkg/logger"
 	"watch-party/pkg/model"
 	"watch-party/pkg/redis"
@@ -19,44 +23,161 @@ import (
 // SyncService defines the interface for sync service operations
 type SyncService interface {
 	// websocket operations
-	HandleConnection(ctx context.Context, roomID, userID uuid.UUID, username string, conn *websocket.Conn) error
+	HandleConnection(ctx context.Context, roomID, userID uuid.UUID, username string, isGuest bool, resumeToken string, protocolVersion int, conn *websocket.Conn) error
 	BroadcastSync(ctx context.Context, message *model.SyncMessage) error
 
 	// participant operations
-	JoinRoom(ctx context.Context, roomID, userID uuid.UUID, username string) error
+	JoinRoom(ctx context.Context, roomID, userID uuid.UUID, username string, isGuest bool) error
 	LeaveRoom(ctx context.Context, roomID, userID uuid.UUID) error
 
 	// state synchronization
 	SyncAction(ctx context.Context, message *model.SyncMessage) error
 	GetRoomState(ctx context.Context, roomID uuid.UUID) (*model.RoomState, error)
 	GetRoomParticipants(ctx context.Context, roomID uuid.UUID) ([]model.ParticipantInfo, error)
+
+	// diagnostics
+	GetConnectionStats() *model.ConnectionStats
+	GetViewerCounts(ctx context.Context) (*model.ViewerCounts, error)
+	GetFeatureFlags() map[string]bool
+
+	DegradedSince() time.Time
+
+	// lifecycle
+	Shutdown(ctx context.Context)
 }
 
 type syncService struct {
+	instanceID  string // identifies this instance in the per-room connection registry
+	startedAt   time.Time
+	stop        context.CancelFunc // stops the instance heartbeat and control listener
 	syncRepo    repository.SyncRepository
 	redis       *redis.Client
+	config      *config.Config
 	connections map[uuid.UUID]map[uuid.UUID]*websocket.Conn
 	connMutex   sync.RWMutex
 	// per-connection mutexes to prevent concurrent writes to WebSocket connections
 	connWriteMutexes map[uuid.UUID]map[uuid.UUID]*sync.Mutex
 	writeMutexLock   sync.RWMutex
+	// new joiners waiting for a live state snapshot from an existing participant
+	pendingRequests *pendingStateRequests
+	// host handoff timers for rooms whose host disconnected, keyed by room ID
+	pendingHandoffs map[uuid.UUID]*time.Timer
+	handoffMutex    sync.Mutex
+	// actions of this instance waiting for a room lock, in arrival order
+	lockQueue *roomLockQueue
+	// local-only sync while Redis is unavailable
+	degraded *degradedMode
+	// consecutive failed writes per connection, broken connections are dropped
+	sendFailures *sendFailures
+	// why connections closed, counted for the stats endpoint
+	connCloses *connectionCloses
+	// leaves of dropped connections waiting out the resume grace period
+	pendingLeaves *pendingLeaves
+	// reactions of large rooms collected until their batch window closes
+	reactionBatches *reactionBatches
+	// protocol version each connection negotiated, payloads are shaped for it
+	protocolVersions *protocolVersions
 }
 
 // NewSyncService creates a new sync service instance
-func NewSyncService(syncRepo repository.SyncRepository, redisClient *redis.Client) SyncService {
+func NewSyncService(syncRepo repository.SyncRepository, redisClient *redis.Client, cfg *config.Config) SyncService {
+	ctx, stop := context.WithCancel(context.Background())
+
 	service := &syncService{
+		instanceID:       newInstanceID(),
+		startedAt:        time.Now(),
+		stop:             stop,
 		syncRepo:         syncRepo,
 		redis:            redisClient,
+		config:           cfg,
 		connections:      make(map[uuid.UUID]map[uuid.UUID]*websocket.Conn),
 		connWriteMutexes: make(map[uuid.UUID]map[uuid.UUID]*sync.Mutex),
+		pendingHandoffs:  make(map[uuid.UUID]*time.Timer),
+		lockQueue:        newRoomLockQueue(),
+		degraded:         newDegradedMode(),
+		sendFailures:     newSendFailures(),
+		connCloses:       newConnectionCloses(),
+		pendingLeaves:    newPendingLeaves(),
+		reactionBatches:  newReactionBatches(),
+		protocolVersions: newProtocolVersions(),
+		pendingRequests:  newPendingStateRequests(cfg.Sync.PendingStateTTL.ToDuration(), cfg.Sync.MaxPendingStateRequests),
 	}
 
 	// start Redis subscription handler
 	go service.handleRedisMessages()
 
+	// keep this instance registered and reachable for control actions routed by service-api
+	go service.runInstanceHeartbeat(ctx)
+	go service.handleInstanceControl(ctx)
+
+	// drop connections of users logged out by service-api
+	go service.handleTokenRevocations(ctx)
+
+	// a single janitor expires state requests nobody answered
+	go service.pendingRequests.runJanitor(ctx, cfg.Sync.PendingStateSweepInterval.ToDuration())
+
+	// participants whose connection was lost without a leave would otherwise stay listed
+	go service.runParticipantReconciler(ctx, cfg.Sync.ReconcileInterval.ToDuration())
+
+	// fall back to local-only sync while Redis is down and resync once it is back
+	go service.runRedisProbe(ctx)
+
 	return service
 }
 
+// newInstanceID builds an identifier that is unique across sync instances and restarts
+func newInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "sync"
+	}
+	return fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8])
+}
+
+// GetConnectionStats reports the WebSocket connections held by this instance
+func (s *syncService) GetConnectionStats() *model.ConnectionStats {
+	s.connMutex.RLock()
+	defer s.connMutex.RUnlock()
+
+	stats := &model.ConnectionStats{
+		InstanceID:        s.instanceID,
+		Rooms:             make(map[uuid.UUID]int, len(s.connections)),
+		ClosedConnections: s.connCloses.snapshot(),
+	}
+	for roomID, roomConns := range s.connections {
+		stats.Rooms[roomID] = len(roomConns)
+		stats.TotalConnections += len(roomConns)
+	}
+
+	return stats
+}
+
+// maxViewerCountRooms bounds how many recently active rooms a viewer count inspects
+const maxViewerCountRooms = 1000
+
+// GetViewerCounts reports connected viewers per room across all instances, next to this instance's own connections
+func (s *syncService) GetViewerCounts(ctx context.Context) (*model.ViewerCounts, error) {
+	rooms, err := s.syncRepo.GetRoomViewerCounts(ctx, maxViewerCountRooms)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := &model.ViewerCounts{
+		Rooms:       rooms,
+		Instance:    s.GetConnectionStats(),
+		GeneratedAt: time.Now(),
+	}
+	for _, viewers := range rooms {
+		counts.TotalViewers += viewers
+	}
+	return counts, nil
+}
+
+// GetFeatureFlags reports which feature flags this instance runs with
+func (s *syncService) GetFeatureFlags() map[string]bool {
+	return s.config.Features.Snapshot()
+}
+
 // GetRoomState retrieves the current room state
 func (s *syncService) GetRoomState(ctx context.Context, roomID uuid.UUID) (*model.RoomState, error) {
 	state, err := s.syncRepo.GetRoomState(ctx, roomID)
@@ -90,42 +211,113 @@ func (s *syncService) GetRoomParticipants(ctx context.Context, roomID uuid.UUID)
 }
 
 // HandleConnection handles a new WebSocket connection
-func (s *syncService) HandleConnection(ctx context.Context, roomID, userID uuid.UUID, username string, conn *websocket.Conn) error {
+func (s *syncService) HandleConnection(ctx context.Context, roomID, userID uuid.UUID, username string, isGuest bool, resumeToken string, protocolVersion int, conn *websocket.Conn) error {
+	// a client reconnecting within the grace period keeps its participant record and skips the join
+	userID, resumed := s.resumeSession(ctx, roomID, userID, isGuest, resumeToken)
+
+	// registered users appear under their display name rather than their email
+	if !isGuest {
+		username = s.displayName(ctx, userID, username)
+	}
+
 	logger.Infof("new connection: user %s (%s) joining room %s", username, userID, roomID)
 
-	// check existing connections BEFORE adding this user
+	// without a limit a single huge message is buffered in full before it is decoded
+	conn.SetReadLimit(s.maxMessageBytes())
+	s.protocolVersions.set(conn, protocolVersion)
+	defer s.protocolVersions.forget(conn)
+
+	err := s.checkRoomCapacity(ctx, roomID, userID)
+	if err != nil {
+		s.refuseConnection(roomID, userID, model.CloseReasonMaxParticipants)
+		return err
+	}
+
+	err = s.claimRoomSlot(ctx, roomID, userID, isGuest)
+	if err != nil {
+		s.refuseConnection(roomID, userID, model.CloseReasonRoomLimit)
+		return err
+	}
+
+	// check existing connections BEFORE adding this user, an older connection of the same user is replaced below
+	// and cannot provide the live state
 	s.connMutex.RLock()
 	existingConns := 0
-	if roomConns, exists := s.connections[roomID]; exists {
-		existingConns = len(roomConns)
+	for connUserID := range s.connections[roomID] {
+		if connUserID != userID {
+			existingConns++
+		}
 	}
 	s.connMutex.RUnlock()
 
 	logger.Infof("room %s has %d existing connections before adding new user", roomID, existingConns)
 
+	// participants connected through other instances can provide the live state as well
+	hasRemoteConns := existingConns == 0 && s.hasRemoteConnections(ctx, roomID)
+
 	// now add the new connection
-	s.addConnection(roomID, userID, conn)
-	defer s.removeConnection(roomID, userID)
+	if replaced := s.addConnection(roomID, userID, conn); replaced != nil {
+		s.replaceConnection(roomID, userID, replaced)
+	}
+	s.sendFailures.watch(conn)
+	s.connCloses.watch(conn)
+	stopKeepAlive := s.keepAlive(conn)
+	s.registerRoomConnections(ctx, roomID)
+	s.registerUserConnection(ctx, roomID, userID)
+
+	// resolve the name after registering so concurrent joiners see this connection as live
+	resolvedName, err := s.resolveUsername(ctx, roomID, userID, username, isGuest)
+	var readErr error
+	var token string
+	joined := false
+	defer func() {
+		stopKeepAlive()
+		current := s.removeConnection(roomID, userID, conn)
+		s.registerRoomConnections(context.Background(), roomID)
+		// a newer connection of the user took over their routing entry and name
+		if current {
+			s.deregisterUserConnection(context.Background(), roomID, userID)
+			if resolvedName != "" {
+				s.releaseUsername(context.Background(), roomID, userID, resolvedName)
+			}
+		}
 
-	err := s.JoinRoom(ctx, roomID, userID, username)
+		// leave only after deregistering, so a tab the user still has open elsewhere is seen as their own
+		if joined {
+			s.leaveOrAwaitResume(context.Background(), roomID, userID, conn, token, readErr)
+		}
+		s.announceDroppedConnection(context.Background(), roomID, userID, conn)
+		s.finishConnection(roomID, userID, conn, readErr)
+	}()
 	if err != nil {
-		logger.Error(err, "failed to join room")
+		s.connCloses.mark(conn, model.CloseReasonError)
+		return err
+	}
+	if resolvedName != username {
+		logger.Infof("user %s joins room %s as %q since %q is taken", userID, roomID, resolvedName, username)
+		username = resolvedName
 	}
 
-	if existingConns > 0 {
+	if resumed {
+		s.rejoinRoom(ctx, roomID, userID)
+	} else {
+		err = s.JoinRoom(ctx, roomID, userID, username, isGuest)
+		if err != nil {
+			logger.Error(err, "failed to join room")
+		}
+	}
+
+	if (existingConns > 0 || hasRemoteConns) && !resumed {
 		// other users exist, request live state from first connected user
 		logger.Infof("requesting live state for new user %s from existing users in room %s", username, roomID)
 		s.requestLiveStateFromExistingUser(ctx, roomID, userID, conn)
 	} else {
-		// first user in room, send stored state
-		logger.Infof("sending stored state to first user %s in room %s", username, roomID)
+		// first user in room or a resumed session, send stored state
+		logger.Infof("sending stored state to user %s in room %s", username, roomID)
 		state, err := s.GetRoomState(ctx, roomID)
 		if err == nil {
 			logger.Infof("sending stored room state: playing=%v, time=%.2f", state.IsPlaying, state.CurrentTime)
-			if err := s.sendToConnectionSafe(roomID, userID, conn, &model.WebSocketMessage{
-				Type:    model.MessageTypeState,
-				Payload: state,
-			}); err != nil {
+			if err := s.sendToConnectionSafe(roomID, userID, conn, s.stateMessage(ctx, roomID, state)); err != nil {
 				logger.Error(err, "failed to send room state")
 			}
 		} else {
@@ -138,7 +330,7 @@ func (s *syncService) HandleConnection(ctx context.Context, roomID, userID uuid.
 	if err == nil {
 		logger.Infof("room %s now has %d total participants", roomID, len(participants))
 		for i, p := range participants {
-			logger.Infof("participant %d: %s (%s)", i+1, p.Username, p.UserID)
+			logger.Debugf("participant %d: %s (%s)", i+1, p.Username, p.UserID)
 		}
 		if err := s.sendToConnectionSafe(roomID, userID, conn, &model.WebSocketMessage{
 			Type:    model.MessageTypeParticipants,
@@ -150,20 +342,48 @@ func (s *syncService) HandleConnection(ctx context.Context, roomID, userID uuid.
 		logger.Error(err, "failed to get room participants")
 	}
 
-	s.handleConnectionMessages(ctx, roomID, userID, username, conn)
+	// send current room settings so the client can adapt its controls
+	settings := s.getRoomSettings(ctx, roomID)
+	if err := s.sendToConnectionSafe(roomID, userID, conn, &model.WebSocketMessage{
+		Type:    model.MessageTypeSettings,
+		Payload: settings,
+	}); err != nil {
+		logger.Error(err, "failed to send room settings")
+	}
+	s.sendSlowMode(ctx, roomID, userID, conn)
+	token = s.sendResumeToken(roomID, userID, conn)
+
+	joined = true
+	readErr = s.handleConnectionMessages(ctx, roomID, userID, username, conn)
 
 	return nil
 }
 
 // JoinRoom adds a user to a room
-func (s *syncService) JoinRoom(ctx context.Context, roomID, userID uuid.UUID, username string) error {
+func (s *syncService) JoinRoom(ctx context.Context, roomID, userID uuid.UUID, username string, isGuest bool) error {
+	// host is cached by service-api; a missing entry just means nobody is flagged
+	hostID, ok := s.loadRoomHost(ctx, roomID)
+	isHost := ok && hostID == userID
+
+	// the host came back before the grace period ran out
+	if isHost {
+		s.cancelHostHandoff(roomID)
+	}
+
 	participant := &model.ParticipantInfo{
 		UserID:      userID,
 		Username:    username,
-		IsHost:      false,
+		IsHost:      isHost,
+		Role:        s.participantRole(ctx, roomID, userID, isHost, isGuest),
 		JoinedAt:    time.Now(),
 		LastSeen:    time.Now(),
 		IsBuffering: false,
+		IsGuest:     isGuest,
+	}
+	if !isGuest {
+		if profile := s.cachedProfile(ctx, userID); profile != nil {
+			participant.AvatarURL = profile.AvatarURL
+		}
 	}
 
 	err := s.syncRepo.AddParticipant(ctx, roomID, userID, participant)
@@ -171,7 +391,7 @@ func (s *syncService) JoinRoom(ctx context.Context, roomID, userID uuid.UUID, us
 		return fmt.Errorf("failed to add participant: %w", err)
 	}
 
-	err = s.syncRepo.SetUserPresence(ctx, userID, roomID, "active")
+	err = s.syncRepo.SetUserPresence(ctx, userID, roomID)
 	if err != nil {
 		logger.Error(err, "failed to set user presence")
 	}
@@ -196,12 +416,18 @@ func (s *syncService) JoinRoom(ctx context.Context, roomID, userID uuid.UUID, us
 
 // LeaveRoom removes a user from a room
 func (s *syncService) LeaveRoom(ctx context.Context, roomID, userID uuid.UUID) error {
+	// the user keeps their place while another of their tabs is connected
+	if s.userStillConnected(ctx, roomID, userID) {
+		logger.Infof("user %s closed a connection to room %s but is still connected", userID, roomID)
+		return nil
+	}
+
 	err := s.syncRepo.RemoveParticipant(ctx, roomID, userID)
 	if err != nil {
 		logger.Error(err, "failed to remove participant")
 	}
 
-	err = s.syncRepo.RemoveUserPresence(ctx, userID)
+	err = s.syncRepo.RemoveUserPresence(ctx, userID, roomID)
 	if err != nil {
 		logger.Error(err, "failed to remove user presence")
 	}
@@ -219,26 +445,52 @@ func (s *syncService) LeaveRoom(ctx context.Context, roomID, userID uuid.UUID) e
 
 	s.BroadcastSync(ctx, leaveMessage)
 
+	s.scheduleHostHandoff(ctx, roomID, userID)
+
 	logger.Infof("user %s left room %s", userID, roomID)
 	return nil
 }
 
 // SyncAction processes a sync action (play, pause, seek, etc.)
 func (s *syncService) SyncAction(ctx context.Context, message *model.SyncMessage) error {
-	logger.Infof("📥 PROCESSING SYNC ACTION: %s from user %s in room %s (time: %.2f)",
+	// every play, pause and seek of every room passes here, a sample is enough to see what rooms do
+	logger.InfoSampledf("📥 PROCESSING SYNC ACTION: %s from user %s in room %s (time: %.2f)",
 		message.Action, message.Username, message.RoomID, message.Data.CurrentTime)
 
-	acquired, err := s.syncRepo.AcquireRoomLock(ctx, message.RoomID, message.UserID)
-	if err != nil {
-		return fmt.Errorf("failed to acquire lock: %w", err)
+	if err := s.checkControlPermission(ctx, message); err != nil {
+		return err
+	}
+
+	if err := s.checkChatEnabled(ctx, message); err != nil {
+		return err
 	}
-	if !acquired {
-		return fmt.Errorf("room is locked by another user")
+
+	if err := s.checkChatRate(ctx, message); err != nil {
+		return err
 	}
-	defer s.syncRepo.ReleaseRoomLock(ctx, message.RoomID)
 
-	state, err := s.syncRepo.GetRoomState(ctx, message.RoomID)
-	if err != nil {
+	if err := s.checkPlaybackRate(ctx, message); err != nil {
+		return err
+	}
+
+	if message.Action == model.ActionPlay {
+		if err := s.checkPlayBufferGate(ctx, message); err != nil {
+			return err
+		}
+	}
+
+	// a viewer watching alone skips the lock and the broadcast, the state is still kept for whoever joins next
+	solo := s.isSoloViewer(ctx, message.RoomID, message.UserID)
+	if !solo {
+		release, err := s.acquireRoomLock(ctx, message.RoomID)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	state, found := s.loadRoomState(ctx, message.RoomID)
+	if !found {
 		state = &model.RoomState{
 			RoomID:       message.RoomID,
 			IsPlaying:    false,
@@ -271,13 +523,37 @@ func (s *syncService) SyncAction(ctx context.Context, message *model.SyncMessage
 	state.LastUpdated = time.Now()
 	state.UpdatedBy = message.UserID
 
-	err = s.syncRepo.SetRoomState(ctx, state)
-	if err != nil {
-		return fmt.Errorf("failed to update room state: %w", err)
+	s.saveRoomState(ctx, state)
+
+	// while degraded nothing else can reach Redis, the action is only synced locally
+	if s.degraded.active() {
+		s.broadcastSyncToRoom(message.RoomID, message, message.UserID)
+		return nil
 	}
 
 	s.syncRepo.UpdateParticipantPresence(ctx, message.RoomID, message.UserID)
 
+	// keep recent chat around so abuse reports can include what was said
+	if message.Action == model.ActionChat && message.Data.ChatMessage != "" {
+		err := s.syncRepo.AppendChatMessage(ctx, message.RoomID, &model.ChatLogEntry{
+			UserID:   message.UserID,
+			Username: message.Username,
+			Message:  message.Data.ChatMessage,
+			SentAt:   message.Timestamp,
+		})
+		if err != nil {
+			logger.Errorf(err, "failed to record chat message in room %s", message.RoomID)
+		}
+	}
+
+	if solo {
+		return nil
+	}
+
+	if s.config.Features.Enabled(config.FeatureSyncSegmentPosition) {
+		s.addSegmentPosition(ctx, message, state)
+	}
+
 	// add to user logs - no longer needed, handled in frontend
 	// s.addUserLog(message)
 
@@ -288,7 +564,7 @@ func (s *syncService) SyncAction(ctx context.Context, message *model.SyncMessage
 
 // BroadcastSync broadcasts a sync message to all room participants
 func (s *syncService) BroadcastSync(ctx context.Context, message *model.SyncMessage) error {
-	logger.Infof("📤 BROADCASTING SYNC: %s from user %s to room %s (time: %.2f)",
+	logger.Debugf("📤 BROADCASTING SYNC: %s from user %s to room %s (time: %.2f)",
 		message.Action, message.Username, message.RoomID, message.Data.CurrentTime)
 
 	err := s.syncRepo.PublishEvent(ctx, message.RoomID, message)
@@ -301,13 +577,17 @@ func (s *syncService) BroadcastSync(ctx context.Context, message *model.SyncMess
 }
 
 // Connection management helpers
-func (s *syncService) addConnection(roomID, userID uuid.UUID, conn *websocket.Conn) {
+
+// addConnection registers the connection of a user to a room and returns the connection it replaced, nil when the
+// user had none
+func (s *syncService) addConnection(roomID, userID uuid.UUID, conn *websocket.Conn) *websocket.Conn {
 	s.connMutex.Lock()
 	defer s.connMutex.Unlock()
 
 	if s.connections[roomID] == nil {
 		s.connections[roomID] = make(map[uuid.UUID]*websocket.Conn)
 	}
+	replaced := s.connections[roomID][userID]
 	s.connections[roomID][userID] = conn
 
 	// also initialize write mutex for this connection
@@ -317,16 +597,28 @@ func (s *syncService) addConnection(roomID, userID uuid.UUID, conn *websocket.Co
 	}
 	s.connWriteMutexes[roomID][userID] = &sync.Mutex{}
 	s.writeMutexLock.Unlock()
+
+	if replaced == conn {
+		return nil
+	}
+	return replaced
 }
 
-func (s *syncService) removeConnection(roomID, userID uuid.UUID) {
+// removeConnection unregisters the connection of a user unless a newer one replaced it, and reports whether conn
+// was still the user's connection
+func (s *syncService) removeConnection(roomID, userID uuid.UUID, conn *websocket.Conn) bool {
 	s.connMutex.Lock()
 	defer s.connMutex.Unlock()
 
+	if s.connections[roomID][userID] != conn {
+		return false
+	}
+
 	if roomConns, exists := s.connections[roomID]; exists {
 		delete(roomConns, userID)
 		if len(roomConns) == 0 {
 			delete(s.connections, roomID)
+			s.degraded.forget(roomID)
 		}
 	}
 
@@ -339,6 +631,20 @@ func (s *syncService) removeConnection(roomID, userID uuid.UUID) {
 		}
 	}
 	s.writeMutexLock.Unlock()
+
+	return true
+}
+
+// registerRoomConnections publishes this instance's connection count for a room so the API can find it
+func (s *syncService) registerRoomConnections(ctx context.Context, roomID uuid.UUID) {
+	s.connMutex.RLock()
+	count := len(s.connections[roomID])
+	s.connMutex.RUnlock()
+
+	err := s.syncRepo.SetInstanceConnections(ctx, roomID, s.instanceID, count)
+	if err != nil {
+		logger.Errorf(err, "failed to register connections for room %s", roomID)
+	}
 }
 
 func (s *syncService) broadcastToRoom(roomID uuid.UUID, message *model.WebSocketMessage) {
@@ -366,56 +672,53 @@ func (s *syncService) broadcastToRoom(roomID uuid.UUID, message *model.WebSocket
 
 // broadcastSyncToRoom broadcasts a sync message to all room participants in the frontend-expected format
 func (s *syncService) broadcastSyncToRoom(roomID uuid.UUID, syncMessage *model.SyncMessage, excludeUserID uuid.UUID) {
-	logger.Infof("📤 SENDING SYNC to room %s: %s from user %s (excluding %s)",
+	logger.Debugf("📤 SENDING SYNC to room %s: %s from user %s (excluding %s)",
 		roomID, syncMessage.Action, syncMessage.Username, excludeUserID)
 
-	frontendSyncData := map[string]interface{}{
-		"action":       string(syncMessage.Action),
-		"current_time": syncMessage.Data.CurrentTime,
-		"timestamp":    syncMessage.Timestamp.Format(time.RFC3339),
-		"user_id":      syncMessage.UserID.String(),
-		"username":     syncMessage.Username,
-	}
+	s.broadcastVersionedExcluding(roomID, syncEventMessages(syncMessage, time.Now()), excludeUserID)
 
-	// include data object if there's additional data (like chat messages)
-	if syncMessage.Data.ChatMessage != "" || syncMessage.Data.Duration > 0 || syncMessage.Data.PlaybackRate > 0 || syncMessage.Data.IsBuffering {
-		frontendSyncData["data"] = map[string]interface{}{
-			"current_time":  syncMessage.Data.CurrentTime,
-			"duration":      syncMessage.Data.Duration,
-			"playback_rate": syncMessage.Data.PlaybackRate,
-			"is_buffering":  syncMessage.Data.IsBuffering,
-			"chat_message":  syncMessage.Data.ChatMessage,
-		}
+	// the sender is left out of the broadcast but still needs the number to notice gaps
+	if syncMessage.Seq > 0 {
+		s.sendEventSeq(roomID, excludeUserID, syncMessage.Seq)
 	}
+}
 
-	webSocketMessage := &model.WebSocketMessage{
-		Type:    model.MessageTypeSync,
-		Payload: frontendSyncData,
+// sendEventSeq tells a local participant the sequence number of an event it was not sent
+func (s *syncService) sendEventSeq(roomID, userID uuid.UUID, seq int64) {
+	s.connMutex.RLock()
+	conn, exists := s.connections[roomID][userID]
+	s.connMutex.RUnlock()
+
+	if !exists {
+		return
 	}
 
-	s.broadcastToRoomExcluding(roomID, webSocketMessage, excludeUserID)
+	err := s.sendToConnectionSafe(roomID, userID, conn, &model.WebSocketMessage{
+		Type: model.MessageTypeEventSeq,
+		Seq:  seq,
+	})
+	if err != nil {
+		logger.Errorf(err, "failed to send event sequence to user %s", userID)
+	}
 }
 
-func (s *syncService) broadcastToRoomExcluding(roomID uuid.UUID, message *model.WebSocketMessage, excludeUserID uuid.UUID) {
-	s.connMutex.RLock()
-	defer s.connMutex.RUnlock()
+// stateMessage wraps a room state with the room's latest event sequence number, which clients take as the
+// point to look for gaps from
+func (s *syncService) stateMessage(ctx context.Context, roomID uuid.UUID, state any) *model.WebSocketMessage {
+	seq, err := s.syncRepo.GetEventSeq(ctx, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to get event sequence of room %s", roomID)
+	}
 
-	if roomConns, exists := s.connections[roomID]; exists {
-		for userID, conn := range roomConns {
-			if userID == excludeUserID {
-				continue
-			}
-			go func(userID uuid.UUID, conn *websocket.Conn) {
-				if err := s.sendToConnectionSafe(roomID, userID, conn, message); err != nil {
-					logger.Errorf(err, "failed to send message to user %s", userID)
-				}
-			}(userID, conn)
-		}
+	return &model.WebSocketMessage{
+		Type:    model.MessageTypeState,
+		Payload: state,
+		Seq:     seq,
 	}
 }
 
 func (s *syncService) sendToConnection(conn *websocket.Conn, message *model.WebSocketMessage) error {
-	return conn.WriteJSON(message)
+	return conn.WriteJSON(s.versioned(conn, message))
 }
 
 // sendToConnectionSafe sends a message to a specific connection with proper synchronization
@@ -434,7 +737,9 @@ func (s *syncService) sendToConnectionSafe(roomID, userID uuid.UUID, conn *webso
 		defer writeMutex.Unlock()
 	}
 
-	return conn.WriteJSON(message)
+	err := conn.WriteJSON(s.versioned(conn, message))
+	s.trackSend(roomID, userID, conn, err)
+	return err
 }
 
 func (s *syncService) sendErrorToConnection(conn *websocket.Conn, code, message string) {
@@ -462,22 +767,26 @@ func (s *syncService) sendErrorToConnectionSafe(roomID, userID uuid.UUID, conn *
 	if err := s.sendToConnectionSafe(roomID, userID, conn, errorMsg); err != nil {
 		logger.Errorf(err, "failed to send error message to user %s", userID)
 	}
+
+	// counted for the room diagnostics endpoint
+	if err := s.syncRepo.RecordRoomError(context.Background(), roomID, code); err != nil {
+		logger.Errorf(err, "failed to record error for room %s", roomID)
+	}
 }
 
-// handleConnectionMessages handles incoming WebSocket messages from a connection
-func (s *syncService) handleConnectionMessages(ctx context.Context, roomID, userID uuid.UUID, username string, conn *websocket.Conn) {
-	defer func() {
-		s.LeaveRoom(ctx, roomID, userID)
-		conn.Close()
-	}()
+// handleConnectionMessages handles incoming WebSocket messages from a connection until reading from it fails,
+// and returns the error the read failed with
+func (s *syncService) handleConnectionMessages(ctx context.Context, roomID, userID uuid.UUID, username string, conn *websocket.Conn) error {
+	defer conn.Close()
 
 	for {
 		rawMessage, err := s.readWebSocketMessage(conn, userID, roomID)
 		if err != nil {
-			break
+			return err
 		}
+		s.extendIdleDeadline(conn)
 
-		logger.Infof("📥 RECEIVED MESSAGE from user %s in room %s: %+v", username, roomID, rawMessage)
+		logger.Debugf("📥 RECEIVED MESSAGE from user %s in room %s: %+v", username, roomID, rawMessage)
 
 		s.processWebSocketMessage(ctx, roomID, userID, username, conn, rawMessage)
 		s.syncRepo.UpdateParticipantPresence(ctx, roomID, userID)
@@ -489,7 +798,12 @@ func (s *syncService) readWebSocketMessage(conn *websocket.Conn, userID, roomID
 	var rawMessage map[string]interface{}
 	err := conn.ReadJSON(&rawMessage)
 	if err != nil {
-		if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+		if errors.Is(err, websocket.ErrReadLimit) {
+			// the library already answered with a close frame, the connection is done
+			logger.Warnf("closing connection of user %s in room %s: message larger than %d bytes", userID, roomID, s.maxMessageBytes())
+		} else if classifyReadError(err) == model.CloseReasonIdleTimeout {
+			s.closeIdleConnection(conn, userID, roomID)
+		} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 			logger.Errorf(err, "websocket error for user %s in room %s", userID, roomID)
 		}
 		return nil, err
@@ -497,6 +811,14 @@ func (s *syncService) readWebSocketMessage(conn *websocket.Conn, userID, roomID
 	return rawMessage, nil
 }
 
+// maxMessageBytes returns the largest message a client may send
+func (s *syncService) maxMessageBytes() int64 {
+	if s.config.Sync.MaxMessageBytes <= 0 {
+		return config.DefaultSyncMaxMessageBytes
+	}
+	return int64(s.config.Sync.MaxMessageBytes)
+}
+
 // processWebSocketMessage routes and processes different message types
 func (s *syncService) processWebSocketMessage(ctx context.Context, roomID, userID uuid.UUID, username string, conn *websocket.Conn, rawMessage map[string]interface{}) {
 	// check for special message types first
@@ -508,6 +830,34 @@ func (s *syncService) processWebSocketMessage(ctx context.Context, roomID, userI
 		case "request_state":
 			s.handleRequestState(ctx, roomID, userID, username, conn, rawMessage)
 			return
+		case "time_sync":
+			s.handleTimeSync(roomID, userID, conn, rawMessage)
+			return
+		case "heartbeat":
+			s.handleHeartbeat(ctx, roomID, userID, conn, rawMessage)
+			return
+		case "set_default_quality":
+			s.handleSetDefaultQuality(ctx, roomID, userID, conn, rawMessage)
+			return
+		case "force_resync":
+			s.handleForceResync(ctx, roomID, userID, conn)
+			return
+		case "resync":
+			// a client that noticed a gap in the event sequence catches up from the stored state
+			s.sendStoredRoomStateSafe(ctx, roomID, userID, conn)
+			return
+		case "buffer_report":
+			s.handleBufferReport(ctx, roomID, userID, conn, rawMessage)
+			return
+		case "annotation":
+			s.handleAnnotation(ctx, roomID, userID, username, conn, rawMessage)
+			return
+		case "reaction":
+			s.handleReaction(ctx, roomID, userID, username, conn, rawMessage)
+			return
+		case "set_slow_mode":
+			s.handleSetSlowMode(ctx, roomID, userID, conn, rawMessage)
+			return
 		}
 	}
 
@@ -551,12 +901,20 @@ func (s *syncService) handleDirectSyncMessage(ctx context.Context, roomID, userI
 
 	// extract data from direct format
 	if data, ok := rawMessage["data"].(map[string]interface{}); ok {
-		if currentTime, ok := data["current_time"].(float64); ok {
+		// a negative position is treated as missing rather than stored for everyone
+		if currentTime, ok := data["current_time"].(float64); ok && currentTime >= 0 {
 			message.Data.CurrentTime = currentTime
 		}
 		if chatMessage, ok := data["chat_message"].(string); ok {
 			message.Data.ChatMessage = chatMessage
 		}
+		if playbackRate, ok := data["playback_rate"].(float64); ok && playbackRate > 0 {
+			message.Data.PlaybackRate = playbackRate
+		}
+		// lets the host start playback without waiting for everyone to buffer
+		if force, ok := data["force"].(bool); ok && force {
+			message.Data.Extra = map[string]interface{}{"force": true}
+		}
 	}
 
 	// all actions (including chat) are handled as sync actions
@@ -581,6 +939,26 @@ func (s *syncService) executeSyncAction(ctx context.Context, conn *websocket.Con
 	err := s.SyncAction(ctx, message)
 	if err != nil {
 		logger.Error(err, "failed to process sync action")
+		if errors.Is(err, ErrBufferNotReady) {
+			s.sendErrorToConnectionSafe(message.RoomID, message.UserID, conn, "BUFFER_NOT_READY", err.Error())
+			return
+		}
+		if errors.Is(err, ErrControlNotAllowed) {
+			s.sendErrorToConnectionSafe(message.RoomID, message.UserID, conn, "FORBIDDEN", err.Error())
+			return
+		}
+		if errors.Is(err, ErrChatDisabled) {
+			s.sendErrorToConnectionSafe(message.RoomID, message.UserID, conn, "CHAT_DISABLED", err.Error())
+			return
+		}
+		if errors.Is(err, ErrChatRateLimited) {
+			s.sendErrorToConnectionSafe(message.RoomID, message.UserID, conn, "CHAT_RATE_LIMITED", err.Error())
+			return
+		}
+		if errors.Is(err, ErrPlaybackRateNotAllowed) {
+			s.sendErrorToConnectionSafe(message.RoomID, message.UserID, conn, "PLAYBACK_RATE_NOT_ALLOWED", err.Error())
+			return
+		}
 		s.sendErrorToConnection(conn, "SYNC_ERROR", err.Error())
 	}
 }
@@ -590,15 +968,7 @@ func (s *syncService) requestLiveStateFromExistingUser(ctx context.Context, room
 	logger.Infof("looking for existing participants to request live state from in room %s", roomID)
 
 	s.connMutex.RLock()
-	defer s.connMutex.RUnlock()
-
-	roomConns, exists := s.connections[roomID]
-	if !exists || len(roomConns) == 0 {
-		logger.Warnf("no connections found for room %s", roomID)
-		s.sendErrorToConnection(requesterConn, "NO_PARTICIPANTS", "No other participants in room")
-		return
-	}
-
+	roomConns := s.connections[roomID]
 	logger.Infof("room %s has %d active connections", roomID, len(roomConns))
 
 	// find the first connected user (any existing participant) excluding the requester
@@ -612,16 +982,20 @@ func (s *syncService) requestLiveStateFromExistingUser(ctx context.Context, room
 			break
 		}
 	}
+	s.connMutex.RUnlock()
 
 	if sourceConn == nil {
-		// if no other users, fall back to stored state
-		logger.Warnf("no other users found in room %s, falling back to stored state", roomID)
-		s.sendStoredRoomStateSafe(ctx, roomID, requesterID, requesterConn)
+		// other participants may be connected through another instance
+		s.requestLiveStateFromRemoteUser(ctx, roomID, requesterID, requesterConn)
 		return
 	}
 
 	// store the pending request so we can route the response back
-	s.storePendingStateRequest(roomID, requesterID, requesterConn)
+	if !s.storePendingStateRequest(roomID, requesterID, requesterConn) {
+		logger.Warnf("too many pending state requests, sending stored state to %s in room %s", requesterID, roomID)
+		s.sendStoredRoomStateSafe(ctx, roomID, requesterID, requesterConn)
+		return
+	}
 
 	// request current state from existing participant
 	stateRequestMsg := &model.WebSocketMessage{
@@ -679,10 +1053,8 @@ func (s *syncService) handleExistingUserStateResponse(ctx context.Context, roomI
 	}
 
 	// forward the state to the requesting user
-	stateMsg := &model.WebSocketMessage{
-		Type:    model.MessageTypeState,
-		Payload: stateData,
-	}
+	s.addDefaultQuality(ctx, roomID, stateData)
+	stateMsg := s.stateMessage(ctx, roomID, stateData)
 
 	logger.Infof("forwarding live state from %s to %s in room %s", sourceUserID, requesterID, roomID)
 	if err := s.sendToConnectionSafe(roomID, requesterID, requesterConn, stateMsg); err != nil {
@@ -699,10 +1071,7 @@ func (s *syncService) handleExistingUserStateResponse(ctx context.Context, roomI
 func (s *syncService) sendStoredRoomState(ctx context.Context, roomID uuid.UUID, conn *websocket.Conn) {
 	state, err := s.GetRoomState(ctx, roomID)
 	if err == nil {
-		s.sendToConnection(conn, &model.WebSocketMessage{
-			Type:    model.MessageTypeState,
-			Payload: state,
-		})
+		s.sendToConnection(conn, s.stateMessage(ctx, roomID, state))
 	} else {
 		s.sendErrorToConnection(conn, "STATE_ERROR", "Failed to get room state")
 	}
@@ -712,10 +1081,7 @@ func (s *syncService) sendStoredRoomState(ctx context.Context, roomID uuid.UUID,
 func (s *syncService) sendStoredRoomStateSafe(ctx context.Context, roomID, userID uuid.UUID, conn *websocket.Conn) {
 	state, err := s.GetRoomState(ctx, roomID)
 	if err == nil {
-		if err := s.sendToConnectionSafe(roomID, userID, conn, &model.WebSocketMessage{
-			Type:    model.MessageTypeState,
-			Payload: state,
-		}); err != nil {
+		if err := s.sendToConnectionSafe(roomID, userID, conn, s.stateMessage(ctx, roomID, state)); err != nil {
 			logger.Error(err, "failed to send stored room state")
 		}
 	} else {
@@ -724,48 +1090,16 @@ func (s *syncService) sendStoredRoomStateSafe(ctx context.Context, roomID, userI
 }
 
 // pending state request management
-var pendingStateRequests = make(map[string]map[uuid.UUID]*websocket.Conn)
-var pendingRequestsMutex sync.RWMutex
-
-func (s *syncService) storePendingStateRequest(roomID, requesterID uuid.UUID, conn *websocket.Conn) {
-	pendingRequestsMutex.Lock()
-	defer pendingRequestsMutex.Unlock()
-
-	roomKey := roomID.String()
-	if pendingStateRequests[roomKey] == nil {
-		pendingStateRequests[roomKey] = make(map[uuid.UUID]*websocket.Conn)
-	}
-	pendingStateRequests[roomKey][requesterID] = conn
-
-	// set a timeout to clean up stale requests
-	go func() {
-		time.Sleep(10 * time.Second)
-		s.removePendingStateRequest(roomID, requesterID)
-	}()
+func (s *syncService) storePendingStateRequest(roomID, requesterID uuid.UUID, conn *websocket.Conn) bool {
+	return s.pendingRequests.store(roomID, requesterID, conn, time.Now())
 }
 
 func (s *syncService) getPendingStateRequest(roomID, requesterID uuid.UUID) *websocket.Conn {
-	pendingRequestsMutex.RLock()
-	defer pendingRequestsMutex.RUnlock()
-
-	roomKey := roomID.String()
-	if pendingStateRequests[roomKey] != nil {
-		return pendingStateRequests[roomKey][requesterID]
-	}
-	return nil
+	return s.pendingRequests.get(roomID, requesterID, time.Now())
 }
 
 func (s *syncService) removePendingStateRequest(roomID, requesterID uuid.UUID) {
-	pendingRequestsMutex.Lock()
-	defer pendingRequestsMutex.Unlock()
-
-	roomKey := roomID.String()
-	if pendingStateRequests[roomKey] != nil {
-		delete(pendingStateRequests[roomKey], requesterID)
-		if len(pendingStateRequests[roomKey]) == 0 {
-			delete(pendingStateRequests, roomKey)
-		}
-	}
+	s.pendingRequests.remove(roomID, requesterID)
 }
 
 // handleRedisMessages handles Redis pub/sub messages for cross-instance sync
@@ -791,13 +1125,125 @@ func (s *syncService) handleRedisMessages() {
 		}
 		s.connMutex.RUnlock()
 
-		if hasRoom && connectionCount > 0 {
-			// broadcast all actions (including chat) as sync messages
-			s.broadcastSyncToRoom(syncMessage.RoomID, &syncMessage, syncMessage.UserID)
+		hasLocalConnections := hasRoom && connectionCount > 0
+
+		// room-level events are not playback actions and get their own message types
+		switch syncMessage.Action {
+		case model.ActionHostChanged:
+			s.handleHostChanged(ctx, &syncMessage, hasLocalConnections)
+		case model.ActionSettingsChanged:
+			if hasLocalConnections {
+				s.handleSettingsChanged(ctx, syncMessage.RoomID)
+			}
+		case model.ActionDefaultQualityChanged:
+			if hasLocalConnections {
+				s.handleDefaultQualityChanged(&syncMessage)
+			}
+		case model.ActionForceResync:
+			if hasLocalConnections {
+				s.broadcastAuthoritativeState(ctx, syncMessage.RoomID)
+			}
+		case model.ActionAnnotation:
+			if hasLocalConnections {
+				s.handleAnnotationEvent(&syncMessage)
+			}
+		case model.ActionSlowModeChanged:
+			if hasLocalConnections {
+				s.handleSlowModeChanged(&syncMessage)
+			}
+		case model.ActionAnnouncement:
+			if hasLocalConnections {
+				s.handleAnnouncement(&syncMessage)
+			}
+		case model.ActionReaction, model.ActionReactionBatch:
+			if hasLocalConnections {
+				s.handleReactionEvent(&syncMessage)
+			}
+		case model.ActionMovieUnavailable:
+			s.handleMovieUnavailable(ctx, &syncMessage, hasLocalConnections)
+		case model.ActionRoleChanged:
+			s.handleRoleChanged(ctx, &syncMessage, hasLocalConnections)
+		default:
+			if hasLocalConnections {
+				// broadcast all actions (including chat) as sync messages
+				s.broadcastSyncToRoom(syncMessage.RoomID, &syncMessage, syncMessage.UserID)
+			}
 		}
 	}
 }
 
+// handleHostChanged applies a host reassignment published by service-api and notifies local participants
+func (s *syncService) handleHostChanged(ctx context.Context, syncMessage *model.SyncMessage, hasLocalConnections bool) {
+	hostChanged := model.HostChangedMessage{
+		RoomID:    syncMessage.RoomID,
+		ChangedBy: syncMessage.UserID,
+		ChangedAt: syncMessage.Timestamp,
+		Reason:    model.HostChangeReasonTransfer,
+	}
+
+	if reason, ok := syncMessage.Data.Extra["reason"].(string); ok {
+		hostChanged.Reason = reason
+	}
+
+	if previousHostIDStr, ok := syncMessage.Data.Extra["previous_host_id"].(string); ok {
+		hostChanged.PreviousHostID, _ = uuid.Parse(previousHostIDStr)
+	}
+
+	newHostIDStr, _ := syncMessage.Data.Extra["new_host_id"].(string)
+	newHostID, err := uuid.Parse(newHostIDStr)
+	if err != nil {
+		logger.Errorf(err, "invalid new host in host_changed event for room %s", syncMessage.RoomID)
+		return
+	}
+	hostChanged.NewHostID = newHostID
+
+	// every instance receives the event, rewriting the same flags is harmless
+	err = s.syncRepo.SetParticipantHost(ctx, syncMessage.RoomID, newHostID)
+	if err != nil {
+		logger.Errorf(err, "failed to update host flags for room %s", syncMessage.RoomID)
+	}
+
+	if !hasLocalConnections {
+		return
+	}
+
+	s.broadcastToRoom(syncMessage.RoomID, &model.WebSocketMessage{
+		Type:    model.MessageTypeHostChanged,
+		Payload: hostChanged,
+		Seq:     syncMessage.Seq,
+	})
+
+	participants, err := s.syncRepo.GetParticipants(ctx, syncMessage.RoomID)
+	if err != nil {
+		logger.Errorf(err, "failed to get participants for room %s", syncMessage.RoomID)
+		return
+	}
+
+	s.broadcastToRoom(syncMessage.RoomID, &model.WebSocketMessage{
+		Type:    model.MessageTypeParticipants,
+		Payload: participants,
+	})
+}
+
+// getRoomSettings returns the cached room settings, falling back to defaults when none are known. checks that
+// restrict participants use loadRoomSettings instead and refuse when the settings are unknown
+func (s *syncService) getRoomSettings(ctx context.Context, roomID uuid.UUID) *model.RoomSettings {
+	settings, ok := s.loadRoomSettings(ctx, roomID)
+	if !ok {
+		defaults := model.DefaultRoomSettings()
+		return &defaults
+	}
+	return settings
+}
+
+// handleSettingsChanged pushes updated room settings to local participants
+func (s *syncService) handleSettingsChanged(ctx context.Context, roomID uuid.UUID) {
+	s.broadcastToRoom(roomID, &model.WebSocketMessage{
+		Type:    model.MessageTypeSettings,
+		Payload: s.getRoomSettings(ctx, roomID),
+	})
+}
+
 // handleProvideState processes provide_state messages from existing users
 func (s *syncService) handleProvideState(ctx context.Context, roomID, userID uuid.UUID, username string, conn *websocket.Conn, rawMessage map[string]interface{}) {
 	logger.Infof("processing provide_state message from user %s", username)
@@ -820,23 +1266,11 @@ func (s *syncService) handleProvideState(ctx context.Context, roomID, userID uui
 		return
 	}
 
-	// find the requester's connection and send them the state
-	s.connMutex.RLock()
-	requesterConn, exists := s.findConnection(roomID, requesterID)
-	s.connMutex.RUnlock()
-
-	if !exists || requesterConn == nil {
-		logger.Warnf("requester %s not found or connection is nil for provide_state", requesterID)
-		return
-	}
+	// send state directly to the requester, which may be connected through another instance
+	s.addDefaultQuality(ctx, roomID, state)
+	stateMsg := s.stateMessage(ctx, roomID, state)
 
-	// send state directly to the requester
-	stateMessage := &model.WebSocketMessage{
-		Type:    model.MessageTypeState,
-		Payload: state,
-	}
-
-	err = s.sendToConnectionSafe(roomID, requesterID, requesterConn, stateMessage)
+	err = s.sendToUser(ctx, roomID, requesterID, stateMsg)
 	if err != nil {
 		logger.Errorf(err, "failed to send state to requester %s", requesterID)
 		return
@@ -854,6 +1288,209 @@ func (s *syncService) handleRequestState(ctx context.Context, roomID, userID uui
 	s.requestLiveStateFromExistingUser(ctx, roomID, userID, conn)
 }
 
+// handleSetDefaultQuality lets the host choose the quality clients start at when they join
+func (s *syncService) handleSetDefaultQuality(ctx context.Context, roomID, userID uuid.UUID, conn *websocket.Conn, rawMessage map[string]interface{}) {
+	hostID, err := s.syncRepo.GetRoomHost(ctx, roomID)
+	if err != nil || hostID != userID {
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "FORBIDDEN", "only the room host can set the default quality")
+		return
+	}
+
+	quality, _ := rawMessage["quality"].(string)
+	if quality == "auto" {
+		quality = ""
+	}
+	if quality != "" {
+		// only a rendition the movie was published with can be the default, not one that failed to transcode
+		qualities, err := s.syncRepo.GetRoomMovieQualities(ctx, roomID)
+		if err != nil {
+			s.sendErrorToConnectionSafe(roomID, userID, conn, "INVALID_QUALITY",
+				"the movie's qualities are not known yet, only auto can be set")
+			return
+		}
+		if !slices.Contains(qualities, quality) {
+			s.sendErrorToConnectionSafe(roomID, userID, conn, "INVALID_QUALITY",
+				fmt.Sprintf("quality must be auto or one of %v", qualities))
+			return
+		}
+	}
+
+	release, err := s.acquireRoomLock(ctx, roomID)
+	if err != nil {
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "SYNC_ERROR", err.Error())
+		return
+	}
+	defer release()
+
+	state, err := s.GetRoomState(ctx, roomID)
+	if err != nil {
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "STATE_ERROR", "Failed to get room state")
+		return
+	}
+
+	state.DefaultQuality = quality
+	err = s.syncRepo.SetRoomState(ctx, state)
+	if err != nil {
+		logger.Errorf(err, "failed to store default quality for room %s", roomID)
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "STATE_ERROR", "Failed to update room state")
+		return
+	}
+
+	message := &model.SyncMessage{
+		ID:        uuid.New(),
+		RoomID:    roomID,
+		UserID:    userID,
+		Action:    model.ActionDefaultQualityChanged,
+		Timestamp: time.Now(),
+		Data: model.SyncData{
+			Extra: map[string]interface{}{
+				"default_quality": quality,
+			},
+		},
+	}
+
+	err = s.syncRepo.PublishEvent(ctx, roomID, message)
+	if err != nil {
+		logger.Error(err, "failed to publish default quality change to Redis")
+		s.handleDefaultQualityChanged(message)
+	}
+
+	logger.Infof("default quality for room %s set to %q by %s", roomID, quality, userID)
+}
+
+// handleForceResync lets the host or a co-host snap every participant to the stored room state
+func (s *syncService) handleForceResync(ctx context.Context, roomID, userID uuid.UUID, conn *websocket.Conn) {
+	hostID, err := s.syncRepo.GetRoomHost(ctx, roomID)
+	if err != nil || (hostID != userID && !s.canControlPlayback(ctx, roomID, userID)) {
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "FORBIDDEN", "only the room host or a co-host can force a resync")
+		return
+	}
+
+	release, err := s.acquireRoomLock(ctx, roomID)
+	if err != nil {
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "SYNC_ERROR", err.Error())
+		return
+	}
+
+	state, err := s.GetRoomState(ctx, roomID)
+	if err != nil {
+		release()
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "STATE_ERROR", "Failed to get room state")
+		return
+	}
+
+	// store the projected position so everyone, including later joiners, starts from the same point
+	now := time.Now()
+	state.CurrentTime = state.ProjectedTime(now)
+	state.LastUpdated = now
+	state.UpdatedBy = userID
+
+	err = s.syncRepo.SetRoomState(ctx, state)
+	release()
+	if err != nil {
+		logger.Errorf(err, "failed to store resynced state for room %s", roomID)
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "STATE_ERROR", "Failed to update room state")
+		return
+	}
+
+	err = s.syncRepo.PublishEvent(ctx, roomID, &model.SyncMessage{
+		ID:        uuid.New(),
+		RoomID:    roomID,
+		UserID:    userID,
+		Action:    model.ActionForceResync,
+		Timestamp: now,
+	})
+	if err != nil {
+		logger.Error(err, "failed to publish force resync to Redis")
+		s.broadcastAuthoritativeState(ctx, roomID)
+	}
+
+	logger.Infof("host %s forced a resync of room %s at %.2fs", userID, roomID, state.CurrentTime)
+}
+
+// broadcastAuthoritativeState sends the time-projected room state to local participants, who hard-seek to it
+func (s *syncService) broadcastAuthoritativeState(ctx context.Context, roomID uuid.UUID) {
+	state, err := s.syncRepo.GetRoomState(ctx, roomID)
+	if err != nil {
+		logger.Errorf(err, "failed to get room state for resync of room %s", roomID)
+		return
+	}
+
+	now := time.Now()
+	state.CurrentTime = state.ProjectedTime(now)
+	state.LastUpdated = now
+	state.Authoritative = true
+
+	s.broadcastToRoom(roomID, s.stateMessage(ctx, roomID, state))
+}
+
+// handleDefaultQualityChanged tells local participants about the room's new starting quality
+func (s *syncService) handleDefaultQualityChanged(syncMessage *model.SyncMessage) {
+	quality, _ := syncMessage.Data.Extra["default_quality"].(string)
+
+	s.broadcastToRoom(syncMessage.RoomID, &model.WebSocketMessage{
+		Type: model.MessageTypeDefaultQuality,
+		Payload: &model.DefaultQualityMessage{
+			DefaultQuality: quality,
+			SetBy:          syncMessage.UserID,
+		},
+		Seq: syncMessage.Seq,
+	})
+}
+
+// addDefaultQuality adds the stored default quality to a live state snapshot provided by a participant
+func (s *syncService) addDefaultQuality(ctx context.Context, roomID uuid.UUID, state map[string]interface{}) {
+	stored, err := s.syncRepo.GetRoomState(ctx, roomID)
+	if err != nil {
+		return
+	}
+	state["default_quality"] = stored.DefaultQuality
+}
+
+// handleTimeSync answers an NTP-style time_sync request so the client can estimate its clock offset
+func (s *syncService) handleTimeSync(roomID, userID uuid.UUID, conn *websocket.Conn, rawMessage map[string]interface{}) {
+	receivedAt := time.Now().UnixMilli()
+
+	clientTime, ok := rawMessage["client_time"].(float64)
+	if !ok {
+		s.sendErrorToConnectionSafe(roomID, userID, conn, "INVALID_TIME_SYNC", "time_sync requires client_time in unix milliseconds")
+		return
+	}
+
+	reply := &model.WebSocketMessage{
+		Type: model.MessageTypeTimeSync,
+		Payload: &model.TimeSyncMessage{
+			ClientTime:        int64(clientTime),
+			ServerReceiveTime: receivedAt,
+			ServerSendTime:    time.Now().UnixMilli(),
+		},
+	}
+	if err := s.sendToConnectionSafe(roomID, userID, conn, reply); err != nil {
+		logger.Errorf(err, "failed to send time_sync reply to user %s", userID)
+	}
+}
+
+// handleHeartbeat replies with the server clock so clients keep their drift correction anchored to it, and
+// resyncs the participant when the position it reported drifted beyond the room's tolerance
+func (s *syncService) handleHeartbeat(ctx context.Context, roomID, userID uuid.UUID, conn *websocket.Conn, rawMessage map[string]interface{}) {
+	now := time.Now()
+	reply := &model.WebSocketMessage{
+		Type: model.MessageTypeHeartbeat,
+		Payload: &model.HeartbeatMessage{
+			Timestamp:  now,
+			UserID:     userID,
+			ServerTime: now.UnixMilli(),
+		},
+	}
+	if err := s.sendToConnectionSafe(roomID, userID, conn, reply); err != nil {
+		logger.Errorf(err, "failed to send heartbeat to user %s", userID)
+	}
+
+	if s.config.Features.Enabled(config.FeatureSyncDriftCorrection) {
+		s.correctDrift(ctx, roomID, userID, conn, rawMessage)
+	}
+}
+
 // findConnection finds a connection for a specific user in a room
 func (s *syncService) findConnection(roomID, userID uuid.UUID) (*websocket.Conn, bool) {
 	if roomConns, exists := s.connections[roomID]; exists {
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"watch-party/pkg/config"
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+	"watch-party/service-sync/internal/repository"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+// newRouterTestService builds a service backed by an in-memory Redis with a host and a viewer connected to
+// one room. the peers of both connections discard whatever the service sends them
+func newRouterTestService(t testing.TB) (s *syncService, roomID, hostID uuid.UUID, hostConn *websocket.Conn) {
+	return newRouterTestServiceOn(t, miniredis.RunT(t))
+}
+
+// newRouterTestServiceOn is newRouterTestService on a given in-memory Redis, for tests that stop and restart it
+func newRouterTestServiceOn(t testing.TB, server *miniredis.Miniredis) (s *syncService, roomID, hostID uuid.UUID, hostConn *websocket.Conn) {
+	logger.InitLogger(&config.Config{})
+
+	client, err := redis.NewClient(&config.Config{Redis: config.RedisConfig{Host: server.Host(), Port: server.Port()}})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	cfg := &config.Config{}
+	s = &syncService{
+		instanceID:       "router-test",
+		startedAt:        time.Now(),
+		stop:             func() {},
+		syncRepo:         repository.NewSyncRepository(client, cfg.Chat),
+		redis:            client,
+		config:           cfg,
+		connections:      make(map[uuid.UUID]map[uuid.UUID]*websocket.Conn),
+		connWriteMutexes: make(map[uuid.UUID]map[uuid.UUID]*sync.Mutex),
+		pendingHandoffs:  make(map[uuid.UUID]*time.Timer),
+		lockQueue:        newRoomLockQueue(),
+		degraded:         newDegradedMode(),
+		sendFailures:     newSendFailures(),
+		connCloses:       newConnectionCloses(),
+		pendingLeaves:    newPendingLeaves(),
+		reactionBatches:  newReactionBatches(),
+		protocolVersions: newProtocolVersions(),
+		pendingRequests:  newPendingStateRequests(0, 0),
+	}
+
+	roomID, hostID = uuid.New(), uuid.New()
+	viewerID := uuid.New()
+	ctx := context.Background()
+	require.NoError(t, s.syncRepo.SetRoomHost(ctx, roomID, hostID))
+	// service-api caches the settings whenever the room is opened
+	require.NoError(t, client.Set(ctx, redis.RoomSettingsKey(roomID), model.DefaultRoomSettings(), time.Hour))
+
+	s.connections[roomID] = make(map[uuid.UUID]*websocket.Conn)
+	s.connWriteMutexes[roomID] = make(map[uuid.UUID]*sync.Mutex)
+	for _, userID := range []uuid.UUID{hostID, viewerID} {
+		conn := dialDiscardingPeer(t)
+		s.connections[roomID][userID] = conn
+		s.connWriteMutexes[roomID][userID] = &sync.Mutex{}
+		require.NoError(t, s.syncRepo.AddParticipant(ctx, roomID, userID, &model.ParticipantInfo{
+			UserID:   userID,
+			Username: userID.String()[:8],
+			JoinedAt: time.Now(),
+			LastSeen: time.Now(),
+		}))
+	}
+
+	return s, roomID, hostID, s.connections[roomID][hostID]
+}
+
+// dialDiscardingPeer opens a WebSocket to a server that reads and drops every message
+func dialDiscardingPeer(t testing.TB) *websocket.Conn {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func FuzzProcessWebSocketMessage(f *testing.F) {
+	seeds := []string{
+		`{"action":"play","data":{"current_time":12.5}}`,
+		`{"action":"play","data":{"current_time":1,"force":true}}`,
+		`{"action":"seek","data":{"current_time":-30}}`,
+		`{"action":"pause","data":"not an object"}`,
+		`{"action":42}`,
+		`{"action":"chat","data":{"chat_message":["nested"]}}`,
+		`{"action":"transfer_host","data":{"new_host_id":"not-a-uuid"}}`,
+		`{"type":"provide_state","requester_id":"2b7e1f0a-0000-4000-8000-000000000000","state":{"current_time":"soon"}}`,
+		`{"type":"provide_state","requester_id":7,"state":null}`,
+		`{"type":"request_state"}`,
+		`{"type":"time_sync","client_time":1e300}`,
+		`{"type":"time_sync","client_time":"now"}`,
+		`{"type":"heartbeat","extra":{"keys":[1,2,3]}}`,
+		`{"type":"heartbeat","current_time":1e308,"is_buffering":false}`,
+		`{"type":"set_default_quality","quality":["720p"]}`,
+		`{"type":"force_resync"}`,
+		`{"type":"resync"}`,
+		`{"type":"buffer_report","buffered_ahead":-1,"is_buffering":"yes"}`,
+		`{"type":"annotation","text":"hi","display_seconds":1e9,"video_time":-5}`,
+		`{"type":{"nested":true},"action":"play"}`,
+		`{}`,
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	s, roomID, hostID, hostConn := newRouterTestService(f)
+	ctx := context.Background()
+
+	f.Fuzz(func(t *testing.T, message string) {
+		// the connection reader rejects anything that is not a JSON object before routing
+		var rawMessage map[string]interface{}
+		if json.Unmarshal([]byte(message), &rawMessage) != nil || rawMessage == nil {
+			return
+		}
+
+		s.processWebSocketMessage(ctx, roomID, hostID, "host", hostConn, rawMessage)
+
+		state, err := s.syncRepo.GetRoomState(ctx, roomID)
+		if err == nil && state.CurrentTime < 0 {
+			t.Fatalf("room position became %v", state.CurrentTime)
+		}
+	})
+}
+
+func TestSetDefaultQuality(t *testing.T) {
+	s, roomID, hostID, hostConn := newRouterTestService(t)
+	ctx := context.Background()
+
+	setDefault := func(quality string) string {
+		s.handleSetDefaultQuality(ctx, roomID, hostID, hostConn, map[string]interface{}{"quality": quality})
+		state, err := s.GetRoomState(ctx, roomID)
+		require.NoError(t, err)
+		return state.DefaultQuality
+	}
+
+	// until service-api cached the movie's renditions only auto can be set
+	require.Equal(t, "", setDefault(model.Quality720p))
+
+	// the 720p rendition failed to transcode, the movie was published without it
+	movie := &model.Movie{FailedQualities: []string{model.Quality720p}}
+	require.NoError(t, s.redis.Set(ctx, redis.RoomMovieQualitiesKey(roomID), movie.Qualities(), time.Minute))
+
+	require.Equal(t, model.Quality360p, setDefault(model.Quality360p))
+	require.Equal(t, model.Quality360p, setDefault(model.Quality720p), "a failed rendition cannot become the default")
+	require.Equal(t, "", setDefault("auto"))
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"watch-party/pkg/logger"
+	"watch-party/pkg/model"
+	"watch-party/pkg/redis"
+
+	"github.com/google/uuid"
+)
+
+// ErrUsernameTaken is returned when a guest joins with a name already used in the room
+var ErrUsernameTaken = errors.New("username is already taken in this room")
+
+// maxUsernameSuffix bounds the "Name (n)" disambiguators tried for registered users
+const maxUsernameSuffix = 20
+
+// resolveUsername reserves a display name that is unique among the room's connected participants.
+// registered users get a numbered suffix on a clash; guests are rejected so they can pick another name.
+func (s *syncService) resolveUsername(ctx context.Context, roomID, userID uuid.UUID, username string, isGuest bool) (string, error) {
+	candidate := username
+	for n := 2; n <= maxUsernameSuffix+1; n++ {
+		claimed, holder, err := s.syncRepo.ClaimUsername(ctx, roomID, userID, candidate, false)
+		if err != nil {
+			// uniqueness is best effort when Redis misbehaves, never a reason to refuse the join
+			logger.Errorf(err, "failed to reserve username %q in room %s", candidate, roomID)
+			return candidate, nil
+		}
+		if claimed {
+			return candidate, nil
+		}
+
+		// the previous holder disconnected without releasing the name
+		if !s.isConnected(ctx, roomID, holder) {
+			_, _, err := s.syncRepo.ClaimUsername(ctx, roomID, userID, candidate, true)
+			if err != nil {
+				logger.Errorf(err, "failed to take over username %q in room %s", candidate, roomID)
+			}
+			return candidate, nil
+		}
+
+		if isGuest {
+			return "", ErrUsernameTaken
+		}
+
+		candidate = fmt.Sprintf("%s (%d)", username, n)
+	}
+
+	return "", ErrUsernameTaken
+}
+
+// releaseUsername frees the user's display name when they leave
+func (s *syncService) releaseUsername(ctx context.Context, roomID, userID uuid.UUID, username string) {
+	err := s.syncRepo.ReleaseUsername(ctx, roomID, userID, username)
+	if err != nil {
+		logger.Errorf(err, "failed to release username %q in room %s", username, roomID)
+	}
+}
+
+// isConnected reports whether the user holds a live connection to the room on any instance
+func (s *syncService) isConnected(ctx context.Context, roomID uuid.UUID, userIDStr string) bool {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return false
+	}
+
+	instanceID, err := s.syncRepo.GetUserInstance(ctx, roomID, userID)
+	if err != nil {
+		// assume the holder is still there rather than handing out a duplicate name
+		return true
+	}
+	if instanceID == "" {
+		return false
+	}
+	if instanceID == s.instanceID {
+		s.connMutex.RLock()
+		_, exists := s.findConnection(roomID, userID)
+		s.connMutex.RUnlock()
+		return exists
+	}
+
+	alive, err := s.redis.Exists(ctx, redis.SyncInstanceKey(instanceID))
+	return err != nil || alive > 0
+}
+
+// cachedProfile returns the public profile service-api cached for a registered user, nil when none is cached
+func (s *syncService) cachedProfile(ctx context.Context, userID uuid.UUID) *model.PublicProfile {
+	var profile model.PublicProfile
+	err := s.redis.Get(ctx, redis.UserProfileKey(userID), &profile)
+	if err != nil {
+		return nil
+	}
+	return &profile
+}
+
+// displayName looks up the display name service-api cached for a registered user, fallback is used when none is cached
+func (s *syncService) displayName(ctx context.Context, userID uuid.UUID, fallback string) string {
+	profile := s.cachedProfile(ctx, userID)
+	if profile == nil || profile.DisplayName == "" {
+		return fallback
+	}
+	return profile.DisplayName
+}
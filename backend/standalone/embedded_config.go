@@ -23,6 +23,7 @@ func createEmbeddedConfig() *config.Config {
 			MaxIdleConns:    25,
 			ConnMaxLifetime: config.Duration(5 * time.Minute),
 			SSLMode:         "disable",
+			QueryTimeout:    config.Duration(config.DefaultDBQueryTimeout),
 		},
 		Log: config.LogConfig{
 			Level:  "info",
@@ -35,7 +36,8 @@ func createEmbeddedConfig() *config.Config {
 			DB:       0,
 		},
 		Storage: config.StorageConfig{
-			Provider: "minio",
+			Provider:       "minio",
+			MaxUploadBytes: config.DefaultMaxUploadBytes,
 			MinIO: config.MinIOConfig{
 				Endpoint:       "localhost:19000", // Updated to avoid conflicts
 				AccessKey:      "minioadmin",
@@ -45,11 +47,24 @@ func createEmbeddedConfig() *config.Config {
 				PublicEndpoint: "", // Will be set dynamically
 			},
 			VideoProcessing: config.VideoConfig{
-				TempDir:     "./temp",
-				HLSBaseURL:  "http://localhost:8080/api/v1/files",
-				FFmpegPath:  "ffmpeg",
-				FFprobePath: "ffprobe",
+				TempDir:               "./temp",
+				HLSBaseURL:            "http://localhost:8080/api/v1/files",
+				FFmpegPath:            "ffmpeg",
+				FFprobePath:           "ffprobe",
+				Poster:                config.DefaultPosterConfig,
+				Preview:               config.DefaultPreviewConfig,
+				FastPreviewFullLadder: true,
+				DownloadConcurrency:   4,
+				DownloadChunkSizeMB:   16,
+				EncryptSegments:       false,
+				KeyBaseURL:            "http://localhost:8080/api/v1/videos",
+				AllowedExtensions:     config.DefaultVideoAllowedExtensions,
+				MaxDuration:           config.Duration(config.DefaultVideoMaxDuration),
 			},
+			UploadReaperInterval: config.Duration(10 * time.Minute),
+			UploadNotifications:  true,
+			OperationTimeout:     config.Duration(config.DefaultStorageOperationTimeout),
+			TransferTimeout:      config.Duration(config.DefaultStorageTransferTimeout),
 		},
 		Email: config.EmailConfig{
 			Provider: "noop",
@@ -71,9 +86,58 @@ func createEmbeddedConfig() *config.Config {
 		},
 		CORS: config.CORSConfig{
 			AllowedOrigins: []string{"http://localhost:3000", "http://localhost:8080", "*"},
-			AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 			AllowedHeaders: []string{"*"},
 		},
+		Compression: config.CompressionConfig{
+			Enabled:       true,
+			BrotliEnabled: true,
+			MinSize:       1024,
+			Level:         -1,
+		},
+		HostHandoff: config.HostHandoffConfig{
+			AutoHandoffEnabled: true,
+			GracePeriod:        config.Duration(30 * time.Second),
+		},
+		Room: config.RoomConfig{
+			DefaultControlMode: "free",
+		},
+		Profile: config.ProfileConfig{
+			AvatarAllowedHosts: []string{},
+		},
+		Chat: config.ChatConfig{
+			HistoryMaxMessages: config.DefaultChatHistoryMaxMessages,
+			HistoryTTL:         config.Duration(config.DefaultChatHistoryTTL),
+		},
+		Sync: config.SyncConfig{
+			PendingStateTTL:              config.Duration(10 * time.Second),
+			PendingStateSweepInterval:    config.Duration(5 * time.Second),
+			MaxPendingStateRequests:      1000,
+			MinPlayBuffer:                0,
+			SyncTolerance:                config.Duration(config.DefaultSyncTolerance),
+			MaxRoomsPerUser:              0,
+			MaxMessageBytes:              config.DefaultSyncMaxMessageBytes,
+			ReconcileInterval:            config.Duration(config.DefaultSyncReconcileInterval),
+			ParticipantStaleAfter:        config.Duration(config.DefaultSyncParticipantStaleAfter),
+			PositionPersistInterval:      config.Duration(config.DefaultSyncPositionPersistInterval),
+			MaxSendFailures:              config.DefaultSyncMaxSendFailures,
+			ResumeGracePeriod:            config.Duration(config.DefaultSyncResumeGracePeriod),
+			IdleTimeout:                  config.Duration(config.DefaultSyncIdleTimeout),
+			ReactionBatchWindow:          config.Duration(config.DefaultSyncReactionBatchWindow),
+			ReactionBatchMinParticipants: config.DefaultSyncReactionBatchMinParticipants,
+		},
+		Streaming: config.StreamingConfig{
+			Mode:                config.StreamingModeDirect,
+			MaxBatchURLs:        config.DefaultMaxBatchURLs,
+			PlaylistCacheTTL:    config.Duration(config.DefaultPlaylistCacheTTL),
+			PlaylistContentType: config.DefaultPlaylistContentType,
+			MovieCacheTTL:       config.Duration(config.DefaultMovieCacheTTL),
+		},
+		Idempotency: config.IdempotencyConfig{
+			KeyTTL: config.Duration(config.DefaultIdempotencyKeyTTL),
+		},
+		// like an unset FEATURE_FLAGS, every sync behavior behind a flag stays off
+		Features: config.FeatureConfig{},
 	}
 }
 
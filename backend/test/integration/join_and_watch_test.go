@@ -0,0 +1,323 @@
+//go:build integration && unix
+
+// Package integration drives a standalone watch-party stack through its public HTTP and WebSocket APIs.
+// see README.md in this directory for how to run it.
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// transcodeTimeout bounds how long the sample video may take to become available
+	transcodeTimeout = 10 * time.Minute
+	// syncTimeout bounds how long a participant may wait for a broadcast
+	syncTimeout = 10 * time.Second
+)
+
+// TestJoinAndWatch uploads a movie, waits for it to transcode, opens a room for it, has a second user
+// join through an invite and checks that both participants stay in sync through play, pause and seek
+func TestJoinAndWatch(t *testing.T) {
+	s := startStack(t)
+
+	hostEmail := fmt.Sprintf("host-%d@example.com", time.Now().UnixNano())
+	hostPassword := "hostpassword123"
+	s.register(t, hostEmail, hostPassword)
+	s.promoteToAdmin(t, hostEmail)
+	hostToken := s.login(t, hostEmail, hostPassword)
+
+	movieID := s.uploadMovie(t, hostToken, sampleVideo(t))
+	s.waitUntilAvailable(t, hostToken, movieID)
+
+	var created struct {
+		Room struct {
+			ID string `json:"id"`
+		} `json:"room"`
+	}
+	s.call(t, http.MethodPost, "/api/v1/rooms", hostToken, map[string]any{
+		"movie_id": movieID,
+		"name":     "integration join and watch",
+	}, http.StatusCreated, &created)
+	roomID := created.Room.ID
+	require.NotEmpty(t, roomID)
+
+	viewerEmail := fmt.Sprintf("viewer-%d@example.com", time.Now().UnixNano())
+	viewerPassword := "viewerpassword123"
+	s.register(t, viewerEmail, viewerPassword)
+	viewerToken := s.login(t, viewerEmail, viewerPassword)
+
+	var invite struct {
+		InviteToken string `json:"invite_token"`
+	}
+	s.call(t, http.MethodPost, "/api/v1/rooms/"+roomID+"/invite", hostToken, map[string]any{
+		"email": viewerEmail,
+	}, http.StatusOK, &invite)
+	require.NotEmpty(t, invite.InviteToken)
+	s.call(t, http.MethodPost, "/api/v1/rooms/join", viewerToken, map[string]any{
+		"invite_token": invite.InviteToken,
+	}, http.StatusOK, nil)
+
+	host := s.connect(t, roomID, hostToken)
+	viewer := s.connect(t, roomID, viewerToken)
+
+	steps := []struct {
+		action      string
+		currentTime float64
+		playing     bool
+	}{
+		{action: "play", currentTime: 0, playing: true},
+		{action: "pause", currentTime: 3.5, playing: false},
+		{action: "seek", currentTime: 12, playing: false},
+		{action: "play", currentTime: 12, playing: true},
+	}
+	for _, step := range steps {
+		data := map[string]any{"current_time": step.currentTime}
+		if step.action == "play" {
+			// the test does not report buffering, start without waiting for it
+			data["force"] = true
+		}
+		require.NoError(t, host.WriteJSON(map[string]any{"action": step.action, "data": data}))
+
+		received := awaitSync(t, viewer, step.action)
+		require.InDelta(t, step.currentTime, received.CurrentTime, 0.001, "viewer received %s at the wrong position", step.action)
+
+		state := s.roomState(t, roomID)
+		require.Equal(t, step.playing, state.IsPlaying, "room state after %s", step.action)
+		require.InDelta(t, step.currentTime, state.CurrentTime, 0.001, "room state after %s", step.action)
+	}
+}
+
+// register creates a regular user account
+func (s stack) register(t *testing.T, email, password string) {
+	t.Helper()
+
+	s.call(t, http.MethodPost, "/api/v1/users/register", "", map[string]any{
+		"email":    email,
+		"password": password,
+	}, http.StatusCreated, nil)
+}
+
+// login returns an access token for the user
+func (s stack) login(t *testing.T, email, password string) string {
+	t.Helper()
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+	}
+	s.call(t, http.MethodPost, "/api/v1/auth/login", "", map[string]any{
+		"email":    email,
+		"password": password,
+	}, http.StatusOK, &resp)
+	require.NotEmpty(t, resp.AccessToken)
+	return resp.AccessToken
+}
+
+// uploadMovie uploads the sample video through a signed URL and reports the upload as complete
+func (s stack) uploadMovie(t *testing.T, token, video string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(video)
+	require.NoError(t, err)
+	fileName := filepath.Base(video)
+
+	var upload struct {
+		MovieID       string            `json:"movie_id"`
+		SignedURL     string            `json:"signed_url"`
+		UploadMethod  string            `json:"upload_method"`
+		UploadHeaders map[string]string `json:"upload_headers"`
+		UploadFields  map[string]string `json:"upload_fields"`
+		FilePath      string            `json:"file_path"`
+	}
+	s.call(t, http.MethodPost, "/api/v1/admin/movies", token, map[string]any{
+		"title":    "integration " + fileName,
+		"filename": fileName,
+		"filesize": len(content),
+		"mimetype": "video/mp4",
+	}, http.StatusCreated, &upload)
+	require.NotEmpty(t, upload.SignedURL)
+
+	var req *http.Request
+	if upload.UploadMethod == http.MethodPost {
+		// the policy fields go before the file
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		for name, value := range upload.UploadFields {
+			require.NoError(t, form.WriteField(name, value))
+		}
+		part, err := form.CreateFormFile("file", fileName)
+		require.NoError(t, err)
+		_, err = part.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, form.Close())
+
+		req, err = http.NewRequest(http.MethodPost, upload.SignedURL, &body)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+	} else {
+		req, err = http.NewRequest(http.MethodPut, upload.SignedURL, bytes.NewReader(content))
+		require.NoError(t, err)
+		for name, value := range upload.UploadHeaders {
+			req.Header.Set(name, value)
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	require.Less(t, resp.StatusCode, 300, "upload to storage failed: %s", body)
+
+	s.call(t, http.MethodPost, "/api/v1/webhooks/upload-complete", "", map[string]any{
+		"movie_id":  upload.MovieID,
+		"file_path": upload.FilePath,
+	}, http.StatusOK, nil)
+
+	return upload.MovieID
+}
+
+// waitUntilAvailable polls the movie status until transcoding finishes
+func (s stack) waitUntilAvailable(t *testing.T, token, movieID string) {
+	t.Helper()
+
+	deadline := time.Now().Add(transcodeTimeout)
+	for {
+		var status struct {
+			Status       string `json:"status"`
+			ErrorMessage string `json:"error_message"`
+		}
+		s.call(t, http.MethodGet, "/api/v1/admin/movies/"+movieID+"/status", token, nil, http.StatusOK, &status)
+
+		switch status.Status {
+		case "available":
+			return
+		case "failed":
+			t.Fatalf("movie %s failed to transcode: %s", movieID, status.ErrorMessage)
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("movie %s still %s after %s", movieID, status.Status, transcodeTimeout)
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+// roomState is the part of the sync service room state the test checks
+type roomState struct {
+	IsPlaying   bool    `json:"is_playing"`
+	CurrentTime float64 `json:"current_time"`
+}
+
+// roomState reads the room's playback state from the sync service
+func (s stack) roomState(t *testing.T, roomID string) roomState {
+	t.Helper()
+
+	var resp struct {
+		State roomState `json:"state"`
+	}
+	s.callURL(t, http.MethodGet, s.syncURL+"/api/v1/rooms/"+roomID+"/state", "", nil, http.StatusOK, &resp)
+	return resp.State
+}
+
+// connect opens a room WebSocket as the user and closes it when the test ends
+func (s stack) connect(t *testing.T, roomID, token string) *websocket.Conn {
+	t.Helper()
+
+	wsURL, err := url.Parse(s.syncURL)
+	require.NoError(t, err)
+	wsURL.Scheme = strings.Replace(wsURL.Scheme, "http", "ws", 1)
+	wsURL.Path = "/ws/room/" + roomID
+	wsURL.RawQuery = url.Values{"token": {token}}.Encode()
+
+	conn, resp, err := websocket.DefaultDialer.Dial(wsURL.String(), nil)
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// syncPayload is the part of a broadcast sync message the test checks
+type syncPayload struct {
+	Action      string  `json:"action"`
+	CurrentTime float64 `json:"current_time"`
+}
+
+// awaitSync reads messages until a sync broadcast of action arrives, failing on server errors
+func awaitSync(t *testing.T, conn *websocket.Conn, action string) syncPayload {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(syncTimeout)))
+	defer conn.SetReadDeadline(time.Time{})
+
+	for {
+		var msg struct {
+			Type    string          `json:"type"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		err := conn.ReadJSON(&msg)
+		require.NoError(t, err, "waiting for %s", action)
+
+		switch msg.Type {
+		case "error":
+			t.Fatalf("sync service returned an error while waiting for %s: %s", action, msg.Payload)
+		case "sync":
+			var payload syncPayload
+			require.NoError(t, json.Unmarshal(msg.Payload, &payload))
+			if payload.Action == action {
+				return payload
+			}
+		}
+	}
+}
+
+// call sends a JSON request to the API service and decodes the response into out when it is not nil
+func (s stack) call(t *testing.T, method, path, token string, body any, wantStatus int, out any) {
+	t.Helper()
+	s.callURL(t, method, s.apiURL+path, token, body, wantStatus, out)
+}
+
+func (s stack) callURL(t *testing.T, method, target, token string, body any, wantStatus int, out any) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequest(method, target, reader)
+	require.NoError(t, err)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, wantStatus, resp.StatusCode, "%s %s: %s", method, target, respBody)
+
+	if out != nil {
+		require.NoError(t, json.Unmarshal(respBody, out), "%s %s: %s", method, target, respBody)
+	}
+}
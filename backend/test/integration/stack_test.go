@@ -0,0 +1,206 @@
+//go:build integration && unix
+
+package integration
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// startupTimeout bounds how long the standalone stack may take to come up, the first run downloads
+	// the PostgreSQL and MinIO binaries
+	startupTimeout = 5 * time.Minute
+	// shutdownTimeout bounds how long the standalone stack may take to stop before it is killed
+	shutdownTimeout = 30 * time.Second
+)
+
+// standalonePorts are the fixed ports the standalone stack listens on: API, sync, frontend and MinIO
+var standalonePorts = []int{8080, 8081, 3000, 19000}
+
+// postgresPortPattern finds the port the standalone stack picked for PostgreSQL in its log
+var postgresPortPattern = regexp.MustCompile(`Using port (\d+) for PostgreSQL`)
+
+// stack holds the endpoints of the stack under test
+type stack struct {
+	apiURL      string
+	syncURL     string
+	postgresDSN string
+}
+
+// startStack builds the standalone binary and runs it for the duration of the test. it brings its own
+// PostgreSQL, Redis and MinIO; the test is skipped when they cannot be started here
+func startStack(t *testing.T) stack {
+	t.Helper()
+
+	for _, tool := range []string{"ffmpeg", "ffprobe"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s is not installed, the stack cannot transcode the sample video", tool)
+		}
+	}
+	for _, port := range standalonePorts {
+		ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+		if err != nil {
+			t.Skipf("port %d is already in use, stop the running stack first", port)
+		}
+		ln.Close()
+	}
+
+	binary := filepath.Join(t.TempDir(), "watch-party-standalone")
+	build := exec.Command("go", "build", "-o", binary, "./standalone")
+	build.Dir = filepath.Join("..", "..")
+	built, err := build.CombinedOutput()
+	require.NoError(t, err, "building the standalone stack: %s", built)
+
+	cmd := exec.Command(binary)
+	// the stack writes its transcoding scratch files relative to its working directory
+	cmd.Dir = t.TempDir()
+	// MinIO runs as a child of the stack, signal the whole group so it stops too
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	output, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	require.NoError(t, cmd.Start())
+
+	log := &stackLog{}
+	postgresPort := make(chan string, 1)
+	go log.follow(output, postgresPort)
+
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		writer.Close()
+		close(exited)
+	}()
+	t.Cleanup(func() {
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGINT)
+		select {
+		case <-exited:
+		case <-time.After(shutdownTimeout):
+		}
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	})
+
+	s := stack{
+		apiURL:  "http://localhost:8080",
+		syncURL: "http://localhost:8081",
+	}
+
+	deadline := time.Now().Add(startupTimeout)
+	for !s.healthy() {
+		select {
+		case <-exited:
+			t.Skipf("the standalone stack exited before it was ready, its embedded services are probably unavailable here:\n%s", log.tail(30))
+		case <-time.After(time.Second):
+		}
+		if time.Now().After(deadline) {
+			t.Skipf("the standalone stack was not ready after %s:\n%s", startupTimeout, log.tail(30))
+		}
+	}
+
+	select {
+	case port := <-postgresPort:
+		s.postgresDSN = fmt.Sprintf("host=localhost port=%s user=postgres password=postgres dbname=watchparty sslmode=disable", port)
+	default:
+		t.Fatalf("the standalone stack did not log its PostgreSQL port:\n%s", log.tail(30))
+	}
+	return s
+}
+
+// healthy reports whether both the API and the sync service answer their health checks
+func (s stack) healthy() bool {
+	for _, base := range []string{s.apiURL, s.syncURL} {
+		resp, err := http.Get(base + "/health")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return false
+		}
+	}
+	return true
+}
+
+// promoteToAdmin gives the user the admin role directly in the stack's database, there is no API for it
+func (s stack) promoteToAdmin(t *testing.T, email string) {
+	t.Helper()
+
+	db, err := sql.Open("postgres", s.postgresDSN)
+	require.NoError(t, err)
+	defer db.Close()
+
+	result, err := db.Exec(`UPDATE users SET role = 'admin' WHERE email = $1`, email)
+	require.NoError(t, err)
+	updated, err := result.RowsAffected()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, updated, "promoting %s", email)
+}
+
+// sampleVideo renders a test pattern with a tone, long enough for the seek the test makes and short enough to transcode quickly
+func sampleVideo(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "sample.mp4")
+	cmd := exec.Command("ffmpeg", "-loglevel", "error",
+		"-f", "lavfi", "-i", "testsrc=duration=15:size=640x360:rate=24",
+		"-f", "lavfi", "-i", "sine=frequency=440:duration=15",
+		"-pix_fmt", "yuv420p", "-shortest", path)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "rendering the sample video: %s", output)
+	return path
+}
+
+// stackLog keeps the standalone stack's output so a failed start can be explained
+type stackLog struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+// follow records the output line by line and reports the PostgreSQL port once it shows up
+func (l *stackLog) follow(r io.Reader, postgresPort chan<- string) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := postgresPortPattern.FindStringSubmatch(line); match != nil {
+			select {
+			case postgresPort <- match[1]:
+			default:
+			}
+		}
+
+		l.mu.Lock()
+		l.lines = append(l.lines, line)
+		l.mu.Unlock()
+	}
+	// keep draining so the stack never blocks on a full pipe
+	io.Copy(io.Discard, r)
+}
+
+// tail returns the last n lines of output
+func (l *stackLog) tail(n int) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lines := l.lines
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -27,8 +27,8 @@ function App() {
     setLoading(false)
   }, [])
 
-  const handleLogout = () => {
-    authService.logout()
+  const handleLogout = async () => {
+    await authService.logout()
     setUser(null)
     window.location.href = '/login'
   }
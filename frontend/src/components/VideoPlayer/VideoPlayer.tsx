@@ -1,7 +1,8 @@
-import { useRef, useCallback, useMemo, useState } from 'react'
+import { useRef, useCallback, useEffect, useMemo, useState } from 'react'
 import { useHlsPlayer } from '../../hooks/useHlsPlayer'
 import { useNativeHlsPlayer } from '../../hooks/useNativeHlsPlayer'
 import { getSignedUrl } from '../../utils/hlsParser'
+import { videoStreamingService, type SubtitleTrack } from '../../services/videoStreamingService'
 import 'video.js/dist/video-js.css'
 
 interface VideoPlayerProps {
@@ -18,6 +19,13 @@ interface VideoPlayerProps {
   waitForSync?: boolean
 }
 
+// tracks fetched from the stream routes are object URLs, they are released once the player drops them
+function revokeTrackURLs(tracks: SubtitleTrack[]) {
+  for (const track of tracks) {
+    if (track.url.startsWith('blob:')) URL.revokeObjectURL(track.url)
+  }
+}
+
 export function VideoPlayer({
   movieId,
   guestToken,
@@ -33,6 +41,27 @@ export function VideoPlayer({
 }: VideoPlayerProps) {
   const videoRef = useRef<HTMLVideoElement>(null)
   const [useNative, setUseNative] = useState(true)
+  const [subtitles, setSubtitles] = useState<SubtitleTrack[]>([])
+
+  // subtitles are optional, a failure to list them must not affect playback
+  useEffect(() => {
+    let cancelled = false
+    let loaded: SubtitleTrack[] = []
+    videoStreamingService.getSubtitles(movieId, guestToken)
+      .then(tracks => {
+        loaded = tracks
+        if (cancelled) {
+          revokeTrackURLs(tracks)
+        } else {
+          setSubtitles(tracks)
+        }
+      })
+      .catch(error => console.warn('failed to load subtitles:', error))
+    return () => {
+      cancelled = true
+      revokeTrackURLs(loaded)
+    }
+  }, [movieId, guestToken])
 
   const masterUrlResolver = useCallback(async (): Promise<string> => {
     const masterPlaylistPath = 'master.m3u8'
@@ -135,6 +164,7 @@ export function VideoPlayer({
           backgroundColor: '#000'
         }}
         className={className}
+        crossOrigin={subtitles.length > 0 ? 'anonymous' : undefined} // tracks from another origin only load with CORS
         controls={true} // always enable controls - volume should work locally
         muted={false} // explicitly enable volume controls
         playsInline
@@ -158,7 +188,17 @@ export function VideoPlayer({
             onVideoReady?.(video)
           }
         }}
-      />
+      >
+        {subtitles.map(track => (
+          <track
+            key={track.language}
+            kind="subtitles"
+            src={track.url}
+            srcLang={track.language}
+            label={track.label}
+          />
+        ))}
+      </video>
       
       {/* loading overlay */}
       {isLoading && (
@@ -65,7 +65,8 @@ export function useMovieUpload() {
   const uploadMovie = useCallback(async (
     file: File,
     title: string,
-    description = ''
+    description = '',
+    fastPreview = false
   ): Promise<string> => {
     setIsUploading(true)
 
@@ -76,7 +77,8 @@ export function useMovieUpload() {
         description,
         filename: file.name,
         filesize: file.size,
-        mimetype: file.type
+        mimetype: file.type,
+        fast_preview: fastPreview
       })
 
       const movieId = uploadResponse.movie_id
@@ -91,7 +93,7 @@ export function useMovieUpload() {
 
       // step 2: upload file to storage
       await movieService.uploadFileToStorage(
-        uploadResponse.signed_url,
+        uploadResponse,
         file,
         (progress) => {
           updateUpload(movieId, { uploadProgress: progress })
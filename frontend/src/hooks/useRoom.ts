@@ -1,7 +1,7 @@
 import { useState, useEffect, useCallback, useRef } from 'react'
 import { roomService, type Room, type VideoAccess } from '../services/roomService'
 import { wsService, type WebSocketMessage, type SyncAction, type BackendRoomState, type BackendSyncMessage } from '../services/webSocketService'
-import type { ChatMessage } from '../types/chat'
+import type { Annotation, ChatMessage } from '../types/chat'
 
 export interface UseRoomOptions {
   roomId: string
@@ -33,12 +33,16 @@ export interface UseRoomReturn {
   // chat state
   chatMessages: ChatMessage[]
   
+  // host annotations currently shown over the player
+  annotations: Annotation[]
+  
   // websocket actions
   connect: () => Promise<void>
   disconnect: () => void
   refreshVideoAccess: () => Promise<void>
   sendSyncAction: (action: Omit<SyncAction, 'timestamp' | 'userId' | 'guestName'>) => void
   sendChatMessage: (message: string) => void
+  sendAnnotation: (text: string, displaySeconds?: number) => void
   
   // video sync callback - call this from VideoPlayer to sync to room state
   syncVideoToRoom: (videoElement: HTMLVideoElement) => void
@@ -57,6 +61,9 @@ export function useRoom({ roomId, isGuest = false, guestToken, guestName, curren
   // chat state
   const [chatMessages, setChatMessages] = useState<ChatMessage[]>([])
   
+  // annotation state
+  const [annotations, setAnnotations] = useState<Annotation[]>([])
+  
   // video state - sync state management
   const [isPlaying, setIsPlaying] = useState(false)
   const [currentTime, setCurrentTime] = useState(0)
@@ -513,6 +520,17 @@ export function useRoom({ roomId, isGuest = false, guestToken, guestName, curren
             ? new Date(backendState.last_updated).getTime() 
             : Date.now()
           setLastActionAt(lastActionTimestamp)
+
+          // host forced a resync, jump straight to the authoritative position
+          const videoElement = videoElementRef.current
+          if (backendState.authoritative && videoElement) {
+            videoElement.currentTime = backendState.current_time
+            if (backendState.is_playing) {
+              videoElement.play().catch(err => console.warn('failed to resume after resync:', err))
+            } else {
+              videoElement.pause()
+            }
+          }
           
           setHasReceivedRoomState(true)
           console.log('room state received and set, hasReceivedRoomState will be true')
@@ -656,6 +674,12 @@ export function useRoom({ roomId, isGuest = false, guestToken, guestName, curren
     }
   }, [hasReceivedRoomState, hasInitialSync, isGuest, syncVideoToRoom, applyRoomStateToVideo])
 
+  // post an annotation at the current playback position (host only)
+  const sendAnnotation = useCallback((text: string, displaySeconds?: number) => {
+    const videoTime = videoElementRef.current?.currentTime
+    wsService.sendAnnotation(text, displaySeconds, videoTime)
+  }, [])
+
   // websocket connection and message handling
   useEffect(() => {
     if (!roomId) return
@@ -669,9 +693,52 @@ export function useRoom({ roomId, isGuest = false, guestToken, guestName, curren
       setIsConnected(false)
     }
     
+    // annotations only overlay text, they never touch playback state
+    const annotationHandler = (message: WebSocketMessage) => {
+      const annotation = message.payload as Annotation | undefined
+      if (!annotation?.id) return
+
+      setAnnotations(prev => [...prev, annotation])
+      setTimeout(() => {
+        setAnnotations(prev => prev.filter(a => a.id !== annotation.id))
+      }, annotation.display_seconds * 1000)
+    }
+    
     const errorHandler = (message: WebSocketMessage) => {
       console.error('websocket error:', message.payload || message.data)
-      setError(`websocket error: ${message.payload || message.data}`)
+      const payload = message.payload as { code?: string; message?: string } | undefined
+      if (payload?.code === 'USERNAME_TAKEN') {
+        setError('someone in this room is already using that name, please request access with a different one')
+        return
+      }
+      if (payload?.code === 'ROOM_LIMIT_REACHED') {
+        setError('you are already watching in too many rooms, leave one of them to join this room')
+        return
+      }
+      if (payload?.code === 'BUFFER_NOT_READY') {
+        setError(`playback will start once everyone has buffered (${payload.message})`)
+        return
+      }
+      if (payload?.code === 'ANNOTATION_RATE_LIMITED' || payload?.code === 'INVALID_ANNOTATION') {
+        setError(`annotation not sent: ${payload.message}`)
+        return
+      }
+      if (payload?.code === 'REACTION_RATE_LIMITED') {
+        // a dropped reaction is not worth an error banner
+        return
+      }
+      if (payload?.code === 'SESSION_REVOKED') {
+        setError('your session has ended, please log in again')
+        return
+      }
+      setError(`websocket error: ${payload?.message || message.data}`)
+    }
+    
+    const movieUnavailableHandler = () => {
+      // the room was deleted with its movie, the server closes the connection right after
+      videoElementRef.current?.pause()
+      setIsPlaying(false)
+      setError('this movie was removed, the watch party has ended')
     }
     
     // set up message handlers
@@ -680,6 +747,8 @@ export function useRoom({ roomId, isGuest = false, guestToken, guestName, curren
     wsService.on('guest_request', handleWebSocketMessage)
     wsService.on('request_state', handleWebSocketMessage)
     wsService.on('chat', handleWebSocketMessage)
+    wsService.on('annotation', annotationHandler)
+    wsService.on('movie_unavailable', movieUnavailableHandler)
     wsService.on('connected', connectHandler)
     wsService.on('disconnected', disconnectHandler)
     wsService.on('error', errorHandler)
@@ -691,12 +760,39 @@ export function useRoom({ roomId, isGuest = false, guestToken, guestName, curren
       wsService.off('guest_request', handleWebSocketMessage)
       wsService.off('request_state', handleWebSocketMessage)
       wsService.off('chat', handleWebSocketMessage)
+      wsService.off('annotation', annotationHandler)
+      wsService.off('movie_unavailable', movieUnavailableHandler)
       wsService.off('connected', connectHandler)
       wsService.off('disconnected', disconnectHandler)
       wsService.off('error', errorHandler)
     }
   }, [roomId, handleWebSocketMessage])
 
+  // report buffered-ahead so the server can hold group starts until everyone can play smoothly, and the
+  // position so it can resync this player when it drifts
+  useEffect(() => {
+    if (!isConnected) return
+
+    const interval = setInterval(() => {
+      const video = videoElementRef.current
+      if (!video) return
+
+      let bufferedAhead = 0
+      for (let i = 0; i < video.buffered.length; i++) {
+        if (video.buffered.start(i) <= video.currentTime && video.currentTime <= video.buffered.end(i)) {
+          bufferedAhead = video.buffered.end(i) - video.currentTime
+          break
+        }
+      }
+
+      const isBuffering = !video.paused && video.readyState < HTMLMediaElement.HAVE_FUTURE_DATA
+      wsService.sendBufferReport(bufferedAhead, isBuffering)
+      wsService.sendHeartbeat(video.currentTime, isBuffering)
+    }, 3000)
+
+    return () => clearInterval(interval)
+  }, [isConnected])
+
   // auto-connect when room is loaded
   useEffect(() => {
     if (room && !isConnected && !isLoading) {
@@ -733,12 +829,14 @@ export function useRoom({ roomId, isGuest = false, guestToken, guestName, curren
     hasReceivedRoomState, // for debugging
     hasAppliedInitialState, // for debugging
     chatMessages,
+    annotations,
     // websocket functions
     connect,
     disconnect,
     refreshVideoAccess,
     sendSyncAction,
     sendChatMessage,
+    sendAnnotation,
     syncVideoToRoom,
     setVideoElement
   }
@@ -16,8 +16,8 @@ export default function MovieLibraryPage() {
       setLoading(true)
       setError(null)
       const response = await movieService.getMyMovies(currentPage, pageSize)
-      setMovies(response.movies || [])
-      setTotalCount(response.total_count)
+      setMovies(response.items || [])
+      setTotalCount(response.total)
     } catch (err) {
       console.error('failed to load movies:', err)
       setError(err instanceof Error ? err.message : 'Failed to load movies')
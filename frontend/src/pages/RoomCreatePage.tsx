@@ -27,7 +27,7 @@ export default function RoomCreatePage() {
         
         // get all movies, then filter for available ones
         const response = await movieService.getMyMovies(1, 100) // get up to 100 movies
-        const available = response.movies.filter(movie => movie.status === 'available')
+        const available = response.items.filter(movie => movie.status === 'available')
         setAvailableMovies(available)
         
         // auto-select movie if provided in URL
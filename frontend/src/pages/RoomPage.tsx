@@ -54,7 +54,7 @@ export default function RoomPage() {
   }, [])
   
   // current username for chat
-  const currentUsername = isGuest && guestName ? guestName : (currentUser?.email?.split('@')[0] || 'User')
+  const currentUsername = isGuest && guestName ? guestName : (currentUser?.display_name || currentUser?.email?.split('@')[0] || 'User')
 
   // room hook
   const {
@@ -68,6 +68,7 @@ export default function RoomPage() {
     sendSyncAction,
     sendChatMessage,
     chatMessages,
+    annotations,
     syncVideoToRoom,
     setVideoElement
   } = useRoom({
@@ -310,6 +311,33 @@ export default function RoomPage() {
             no movie selected for this room
           </div>
         )}
+
+        {/* host annotations overlay */}
+        {annotations.length > 0 && (
+          <div style={{
+            position: 'absolute',
+            top: '1rem',
+            left: '50%',
+            transform: 'translateX(-50%)',
+            display: 'flex',
+            flexDirection: 'column',
+            gap: '0.5rem',
+            maxWidth: '80%',
+            pointerEvents: 'none'
+          }}>
+            {annotations.map(annotation => (
+              <div key={annotation.id} style={{
+                padding: '0.5rem 1rem',
+                backgroundColor: 'rgba(0, 0, 0, 0.7)',
+                color: 'white',
+                borderRadius: '4px',
+                textAlign: 'center'
+              }}>
+                {annotation.text}
+              </div>
+            ))}
+          </div>
+        )}
       </div>
 
       {/* guest requests for admin users only */}
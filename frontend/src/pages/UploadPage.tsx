@@ -7,6 +7,7 @@ export default function UploadPage() {
   const [selectedFile, setSelectedFile] = useState<File | null>(null)
   const [movieTitle, setMovieTitle] = useState('')
   const [movieDescription, setMovieDescription] = useState('')
+  const [fastPreview, setFastPreview] = useState(false)
   const [dragOver, setDragOver] = useState(false)
   
   const { uploads, isUploading, uploadMovie, removeUpload, clearUploads } = useMovieUpload()
@@ -49,12 +50,13 @@ export default function UploadPage() {
     }
 
     try {
-      await uploadMovie(selectedFile, movieTitle.trim(), movieDescription.trim())
+      await uploadMovie(selectedFile, movieTitle.trim(), movieDescription.trim(), fastPreview)
       
       // reset form
       setSelectedFile(null)
       setMovieTitle('')
       setMovieDescription('')
+      setFastPreview(false)
       
       // clear file input
       const fileInput = document.getElementById('fileInput') as HTMLInputElement
@@ -141,6 +143,18 @@ export default function UploadPage() {
           />
         </div>
 
+        {/* Fast Preview Option */}
+        <div style={{ marginBottom: '2rem' }}>
+          <label style={{ display: 'flex', alignItems: 'center', gap: '0.5rem', color: '#333' }}>
+            <input
+              type="checkbox"
+              checked={fastPreview}
+              onChange={(e) => setFastPreview(e.target.checked)}
+            />
+            Fast preview: make the movie watchable in 720p first, other qualities follow later
+          </label>
+        </div>
+
         {/* Upload Button */}
         <button
           type="submit"
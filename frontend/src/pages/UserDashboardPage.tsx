@@ -37,7 +37,7 @@ export default function UserDashboardPage() {
       {/* header */}
       <div style={{ marginBottom: '2rem' }}>
         <h1 style={{ color: '#333', margin: 0 }}>
-          Welcome, {user?.email || 'User'}!
+          Welcome, {user?.display_name || user?.email || 'User'}!
         </h1>
         <p style={{ color: '#666', margin: '0.5rem 0 0 0' }}>
           Your watch party rooms and invitations
@@ -120,6 +120,42 @@ class ApiClient {
     return this.handleResponse<T>(response)
   }
 
+  async patch<T>(endpoint: string, body: unknown): Promise<T> {
+    await this.initializeConfig()
+    
+    const response = await fetch(`${this.baseUrl}${endpoint}`, {
+      method: 'PATCH',
+      headers: {
+        'Content-Type': 'application/json',
+        ...this.getAuthHeaders(),
+      },
+      body: JSON.stringify(body),
+    })
+
+    return this.handleResponse<T>(response)
+  }
+
+  // upload a multipart form, the browser sets the content type with its boundary
+  async postForm<T>(endpoint: string, form: FormData): Promise<T> {
+    await this.initializeConfig()
+
+    const response = await fetch(`${this.baseUrl}${endpoint}`, {
+      method: 'POST',
+      headers: {
+        ...this.getAuthHeaders(),
+      },
+      body: form,
+    })
+
+    return this.handleResponse<T>(response)
+  }
+
+  // resolve API-relative paths such as uploaded avatars against the API host
+  resolveUrl(path: string): string {
+    if (!path.startsWith('/api/')) return path
+    return `${this.baseUrl.replace(/\/api\/v1$/, '')}${path}`
+  }
+
   async delete<T>(endpoint: string): Promise<T> {
     await this.initializeConfig()
     
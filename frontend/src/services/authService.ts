@@ -14,9 +14,16 @@ interface UserProfile {
   id: string
   email: string
   role: string
+  display_name: string
+  avatar_url?: string
   created_at: string
 }
 
+interface UpdateProfileRequest {
+  display_name?: string
+  avatar_url?: string
+}
+
 interface LoginResponse {
   access_token: string
   refresh_token: string
@@ -37,7 +44,31 @@ export const authService = {
     return apiClient.post<RegisterResponse>('/users/register', userData)
   },
 
-  logout(): void {
+  async updateProfile(changes: UpdateProfileRequest): Promise<UserProfile> {
+    const { user } = await apiClient.patch<{ user: UserProfile }>('/me', changes)
+    localStorage.setItem('user', JSON.stringify(user))
+    return user
+  },
+
+  async uploadAvatar(file: File): Promise<UserProfile> {
+    const form = new FormData()
+    form.append('avatar', file)
+    const { user } = await apiClient.postForm<{ user: UserProfile }>('/me/avatar', form)
+    localStorage.setItem('user', JSON.stringify(user))
+    return user
+  },
+
+  async logout(): Promise<void> {
+    // revoke the session server-side while the tokens are still stored
+    const refreshToken = localStorage.getItem('refresh_token')
+    if (refreshToken) {
+      try {
+        await apiClient.post('/auth/logout', { refresh_token: refreshToken })
+      } catch (error) {
+        console.warn('failed to revoke session on logout:', error)
+      }
+    }
+
     // clear session data from localStorage (like clearing Redis cache)
     localStorage.removeItem('token')
     localStorage.removeItem('refresh_token')
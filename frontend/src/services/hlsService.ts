@@ -2,7 +2,9 @@
 
 import { 
   getMasterPlaylist, 
-  getMediaPlaylist
+  getMediaPlaylist,
+  decryptSegment,
+  type SegmentKey
 } from '../utils/hlsParser'
 
 // set to true to enable detailed HLS streaming logs for debugging
@@ -29,6 +31,8 @@ export interface Segment {
   duration: number;
   url: string;
   startTime: number;
+  sequence: number;
+  key?: SegmentKey; // set when the segment is AES-128 encrypted
 }
 
 export interface SegmentCache {
@@ -257,7 +261,9 @@ export function createHlsPlayer(
         index,
         duration: segment.duration,
         url: segment.url,
-        startTime: currentTime
+        startTime: currentTime,
+        sequence: segment.sequence,
+        key: segment.key
       }
       currentTime += segment.duration
       return seg
@@ -304,7 +310,8 @@ export function createHlsPlayer(
       throw new Error(`failed to fetch segment ${segmentIndex}: ${response.status}`)
     }
 
-    return await response.arrayBuffer()
+    const data = await response.arrayBuffer()
+    return segment.key ? decryptSegment(data, segment.key, segment.sequence) : data
   }
 
   // ===== SEEK LOGIC =====
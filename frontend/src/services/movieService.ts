@@ -1,4 +1,5 @@
 import { apiClient } from './apiClient'
+import type { Page } from '../types/pagination'
 
 // types based on backend API
 export interface Movie {
@@ -16,6 +17,7 @@ export interface Movie {
   created_at: string
   processing_started_at?: string
   processing_ended_at?: string
+  fast_preview?: boolean // only 720p is published so far, the other qualities may follow
   error_message?: string
 }
 
@@ -25,11 +27,15 @@ export interface UploadMovieRequest {
   filename: string
   filesize: number
   mimetype?: string
+  fast_preview?: boolean // publish 720p first so the movie is watchable sooner
 }
 
 export interface MovieUploadResponse {
   movie_id: string
   signed_url: string
+  upload_method: 'PUT' | 'POST'
+  upload_headers?: Record<string, string>  // signed headers, send them unchanged
+  upload_fields?: Record<string, string>   // policy fields of a POST upload, sent before the file
   file_path: string  // add file path for webhook notification
   message: string
 }
@@ -44,12 +50,7 @@ export interface MovieStatusResponse {
   error_message?: string
 }
 
-export interface MovieListResponse {
-  movies: Movie[]
-  total_count: number
-  page: number
-  page_size: number
-}
+export type MovieListResponse = Page<Movie>
 
 export class MovieService {
   // initiate asynchronous upload - returns signed URL
@@ -59,7 +60,7 @@ export class MovieService {
 
   // upload file directly to storage using signed URL
   async uploadFileToStorage(
-    signedUrl: string, 
+    upload: MovieUploadResponse,
     file: File, 
     onProgress?: (progress: number) => void
   ): Promise<void> {
@@ -92,9 +93,18 @@ export class MovieService {
         reject(new Error('upload was aborted'))
       })
 
-      // use PUT method for direct storage upload
-      xhr.open('PUT', signedUrl)
-      xhr.setRequestHeader('Content-Type', file.type)
+      // storage enforces the content type and size it signed, so send exactly what it returned
+      if (upload.upload_method === 'POST') {
+        const form = new FormData()
+        Object.entries(upload.upload_fields ?? {}).forEach(([name, value]) => form.append(name, value))
+        form.append('file', file)
+        xhr.open('POST', upload.signed_url)
+        xhr.send(form)
+        return
+      }
+
+      xhr.open('PUT', upload.signed_url)
+      Object.entries(upload.upload_headers ?? {}).forEach(([name, value]) => xhr.setRequestHeader(name, value))
       xhr.send(file)
     })
   }
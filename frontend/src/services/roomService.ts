@@ -1,6 +1,7 @@
 import { apiClient } from './apiClient'
 import { videoStreamingService } from './videoStreamingService'
 import { configService } from './configService'
+import type { Page } from '../types/pagination'
 
 export interface Room {
   id: string
@@ -22,6 +23,8 @@ export interface Room {
     id: string
     email: string
     role: string
+    display_name: string
+    avatar_url?: string
     created_at: string
   }
   member_count?: number
@@ -95,7 +98,8 @@ class RoomService {
 
   // get all rooms (admin only)
   async getRooms(): Promise<Room[]> {
-    return apiClient.get<Room[]>('/rooms')
+    const response = await apiClient.get<Page<Room>>('/rooms?page_size=100')
+    return response.items
   }
 
   // invite user to room by email
@@ -116,8 +120,8 @@ class RoomService {
 
   // get pending guest requests (host only)
   async getGuestRequests(roomId: string): Promise<GuestRequest[]> {
-    const response = await apiClient.get<{ requests: GuestRequest[] }>(`/rooms/${roomId}/guest-requests`)
-    return response.requests
+    const response = await apiClient.get<Page<GuestRequest>>(`/rooms/${roomId}/guest-requests?page_size=100`)
+    return response.items
   }
 
   // approve/deny guest request (host only)
@@ -146,8 +150,8 @@ class RoomService {
 
   // get pending room access requests (admin only)
   async getRoomAccessRequests(roomId: string): Promise<UserRoomAccessRequest[]> {
-    const response = await apiClient.get<{ requests: UserRoomAccessRequest[] }>(`/rooms/${roomId}/room-access`)
-    return response.requests
+    const response = await apiClient.get<Page<UserRoomAccessRequest>>(`/rooms/${roomId}/room-access?page_size=100`)
+    return response.items
   }
 
   // approve/deny room access request (admin only)
@@ -8,6 +8,7 @@ export interface VideoStreamingOptions {
 export interface VideoAccessResponse {
   movie_id: string
   hls_url: string
+  streaming_mode?: 'proxy' | 'redirect' | 'direct'
   expires_at: string
   cdn_info?: {
     cacheable: boolean
@@ -15,6 +16,18 @@ export interface VideoAccessResponse {
   }
 }
 
+export interface SubtitleTrack {
+  language: string
+  label: string
+  url: string
+}
+
+export interface SubtitleListResponse {
+  movie_id: string
+  subtitles: SubtitleTrack[]
+  expires_at: string
+}
+
 class VideoStreamingService {
   // get the video source URL using signed URLs (works for both SaaS and self-hosted)
   async getVideoSource(options: VideoStreamingOptions): Promise<string> {
@@ -39,6 +52,42 @@ class VideoStreamingService {
     return response.hls_url
   }
 
+  // get the subtitle tracks added to a movie, with URLs the player can load directly
+  async getSubtitles(movieId: string, guestToken?: string): Promise<SubtitleTrack[]> {
+    const endpoint = `/videos/${movieId}/subtitles`
+
+    let response: SubtitleListResponse
+    if (guestToken) {
+      response = await apiClient.publicGet<SubtitleListResponse>(`${endpoint}?token=${guestToken}`)
+    } else {
+      response = await apiClient.get<SubtitleListResponse>(endpoint)
+    }
+
+    return Promise.all(response.subtitles.map(track => this.loadSubtitleTrack(track)))
+  }
+
+  // <track> elements cannot send the user's token, so tracks on the stream routes (proxy/redirect streaming modes)
+  // are fetched with the same authentication as the playlists and handed to the player as object URLs.
+  // guests without a token in the URL authenticate with the guest session cookie instead
+  private async loadSubtitleTrack(track: SubtitleTrack): Promise<SubtitleTrack> {
+    const isStreamRoute = track.url.includes('/api/v1/stream/') && !track.url.includes('token=')
+    if (!isStreamRoute) {
+      return track
+    }
+
+    const token = localStorage.getItem('token')
+    const init: RequestInit = token
+      ? { headers: { Authorization: `Bearer ${token}` } }
+      : { credentials: 'include' }
+    const response = await fetch(track.url, init)
+    if (!response.ok) {
+      throw new Error(`HTTP ${response.status}: ${response.statusText}`)
+    }
+
+    const vtt = await response.blob()
+    return { ...track, url: URL.createObjectURL(vtt) }
+  }
+
   // get batch URLs for HLS segments (used by video player for prefetching)
   async getBatchSegmentURLs(
     movieId: string, 
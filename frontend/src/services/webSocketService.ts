@@ -8,6 +8,9 @@ export interface SyncAction {
   guestName?: string  // for guest users
 }
 
+// close code the server uses when the same user connected to the room again, e.g. from another tab
+const CLOSE_CODE_REPLACED = 4002
+
 // backend sync message format (what we receive from backend)
 export interface BackendSyncMessage {
   action: 'play' | 'pause' | 'seek' | 'join' | 'leave' | 'buffering' | 'ready' | 'chat'
@@ -25,8 +28,9 @@ export interface BackendSyncMessage {
 }
 
 export interface WebSocketMessage {
-  type: 'sync' | 'participants' | 'state' | 'guest_request' | 'guest_approved' | 'error' | 'connected' | 'disconnected' | 'request_state' | 'provide_state' | 'chat'
+  type: 'sync' | 'participants' | 'state' | 'guest_request' | 'guest_approved' | 'error' | 'connected' | 'disconnected' | 'request_state' | 'provide_state' | 'chat' | 'annotation' | 'movie_unavailable' | 'event_seq' | 'heartbeat'
   payload?: unknown  // backend uses 'payload' instead of 'data'
+  seq?: number       // room event sequence number, state messages carry the latest one they include
   data?: unknown     // keep for backwards compatibility
   room_id?: string
   user_id?: string
@@ -49,6 +53,7 @@ export interface BackendRoomState {
   playback_rate: number
   last_updated: string
   updated_by: string
+  authoritative?: boolean // set on host force resync, hard-seek to this state
 }
 
 type WebSocketEventHandler = (message: WebSocketMessage) => void
@@ -60,6 +65,7 @@ class WebSocketService {
   private maxReconnectAttempts = 5
   private reconnectDelay = 1000
   private isConnecting = false
+  private lastSeq = 0 // last room event seen, a jump past it means events were missed
   private eventHandlers: Map<string, WebSocketEventHandler[]> = new Map()
 
   // connect to room WebSocket
@@ -70,6 +76,7 @@ class WebSocketService {
 
     this.isConnecting = true
     this.roomId = roomId
+    this.lastSeq = 0
 
     try {
       // build WebSocket URL with authentication
@@ -130,8 +137,9 @@ class WebSocketService {
         
         this.emit('disconnected', { type: 'disconnected' })
         
-        // attempt to reconnect if not a clean close
-        if (event.code !== 1000 && this.reconnectAttempts < this.maxReconnectAttempts) {
+        // attempt to reconnect if not a clean close. a connection replaced by another tab stays closed,
+        // reconnecting would replace that tab in turn
+        if (event.code !== 1000 && event.code !== CLOSE_CODE_REPLACED && this.reconnectAttempts < this.maxReconnectAttempts) {
           this.scheduleReconnect()
         }
       }
@@ -213,6 +221,73 @@ class WebSocketService {
     this.ws.send(JSON.stringify(message))
   }
 
+  // snap every participant to the stored room state (host only)
+  forceResync(): void {
+    if (!this.ws || this.ws.readyState !== WebSocket.OPEN) {
+      console.warn('websocket not connected, cannot force resync')
+      return
+    }
+
+    this.ws.send(JSON.stringify({ type: 'force_resync' }))
+  }
+
+  // track the room event sequence and ask for the stored state when events were missed
+  private trackSeq(message: WebSocketMessage): void {
+    if (!message.seq) return
+
+    if (message.type === 'state') {
+      this.lastSeq = message.seq
+      return
+    }
+
+    if (this.lastSeq > 0 && message.seq > this.lastSeq + 1 && this.ws?.readyState === WebSocket.OPEN) {
+      console.warn(`missed room events ${this.lastSeq + 1}-${message.seq - 1}, requesting resync`)
+      this.ws.send(JSON.stringify({ type: 'resync' }))
+    }
+    this.lastSeq = Math.max(this.lastSeq, message.seq)
+  }
+
+  // report how far ahead the player has buffered, used by the server's play gate
+  sendBufferReport(bufferedAhead: number, isBuffering: boolean): void {
+    if (!this.ws || this.ws.readyState !== WebSocket.OPEN) {
+      return
+    }
+
+    this.ws.send(JSON.stringify({
+      type: 'buffer_report',
+      buffered_ahead: bufferedAhead,
+      is_buffering: isBuffering
+    }))
+  }
+
+  // report the player position so the server can resync this player when it drifts beyond the room's tolerance
+  sendHeartbeat(currentTime: number, isBuffering: boolean): void {
+    if (!this.ws || this.ws.readyState !== WebSocket.OPEN) {
+      return
+    }
+
+    this.ws.send(JSON.stringify({
+      type: 'heartbeat',
+      current_time: currentTime,
+      is_buffering: isBuffering
+    }))
+  }
+
+  // overlay timed text on every participant's player (host only)
+  sendAnnotation(text: string, displaySeconds?: number, videoTime?: number): void {
+    if (!this.ws || this.ws.readyState !== WebSocket.OPEN) {
+      console.warn('websocket not connected, cannot send annotation')
+      return
+    }
+
+    this.ws.send(JSON.stringify({
+      type: 'annotation',
+      text,
+      display_seconds: displaySeconds,
+      video_time: videoTime
+    }))
+  }
+
   // provide current video state when requested by backend (for newly joined users)
   provideCurrentState(requesterID: string, currentState: { isPlaying: boolean; currentTime: number }): void {
     if (!this.ws || this.ws.readyState !== WebSocket.OPEN) {
@@ -275,6 +350,8 @@ class WebSocketService {
   }
 
   private handleMessage(message: WebSocketMessage): void {
+    this.trackSeq(message)
+
     switch (message.type) {
       case 'sync': {
         const syncData = message.payload || message.data
@@ -307,6 +384,18 @@ class WebSocketService {
       case 'chat':
         this.emit('chat', message)
         break
+      case 'annotation':
+        this.emit('annotation', message)
+        break
+      case 'movie_unavailable':
+        this.emit('movie_unavailable', message)
+        break
+      case 'event_seq':
+        // only advances the sequence
+        break
+      case 'heartbeat':
+        // the server answers position heartbeats, a drifted player gets an authoritative state instead
+        break
       case 'error':
         this.emit('error', message)
         break
@@ -7,6 +7,17 @@ export interface ChatMessage {
   timestamp: string;
 }
 
+export interface Annotation {
+  id: string;
+  room_id: string;
+  user_id: string;
+  username: string;
+  text: string;
+  display_seconds: number;
+  video_time?: number;
+  created_at: string;
+}
+
 export interface UserLogEntry {
   id: string;
   room_id: string;
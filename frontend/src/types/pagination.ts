@@ -0,0 +1,8 @@
+// envelope returned by every list endpoint
+export interface Page<T> {
+  items: T[]
+  total: number
+  page: number
+  page_size: number
+  next_cursor?: string // pass back as ?cursor= for the next page, absent on the last page
+}
@@ -21,7 +21,17 @@ export class SegmentFetcher {
     console.log(`🔄 Fetching segment ${segmentPath}`)
     
     try {
-      const response = await fetch(url)
+      // stream routes of the API (proxy/redirect streaming modes) need the user's token, signed storage URLs must not get it.
+      // guests without a token in the URL authenticate with the guest session cookie instead
+      const token = localStorage.getItem('token')
+      const isStreamRoute = url.includes('/api/v1/stream/') && !url.includes('token=')
+      let init: RequestInit | undefined
+      if (isStreamRoute && token) {
+        init = { headers: { Authorization: `Bearer ${token}` } }
+      } else if (isStreamRoute) {
+        init = { credentials: 'include' }
+      }
+      const response = await fetch(url, init)
       
       if (!response.ok) {
         throw new Error(`HTTP ${response.status}: ${response.statusText}`)
@@ -11,10 +11,18 @@ export interface MasterPlaylist {
   variants: MasterPlaylistVariant[];
 }
 
+// AES-128 key a segment is encrypted with, see #EXT-X-KEY
+export interface SegmentKey {
+  uri: string;
+  iv?: Uint8Array; // the segment's media sequence number when absent
+}
+
 export interface MediaSegment {
   duration: number;
   url: string;
   title?: string;
+  sequence: number;
+  key?: SegmentKey;
 }
 
 export interface MediaPlaylist {
@@ -62,12 +70,21 @@ export function parseMediaPlaylist(text: string, baseUrl: string): MediaPlaylist
   let targetDuration = 0;
   let duration = 0;
   let title = undefined;
+  let sequence = 0;
+  let key: SegmentKey | undefined = undefined;
   for (let i = 0; i < lines.length; i++) {
     const line = lines[i];
     if (line.startsWith('#EXT-X-TARGETDURATION')) {
       const match = line.match(/#EXT-X-TARGETDURATION:(\d+)/);
       if (match) targetDuration = parseInt(match[1], 10);
     }
+    if (line.startsWith('#EXT-X-MEDIA-SEQUENCE')) {
+      const match = line.match(/#EXT-X-MEDIA-SEQUENCE:(\d+)/);
+      if (match) sequence = parseInt(match[1], 10);
+    }
+    if (line.startsWith('#EXT-X-KEY')) {
+      key = parseKeyTag(line, baseUrl);
+    }
     if (line.startsWith('#EXTINF')) {
       const match = line.match(/#EXTINF:([\d.]+)(?:,(.*))?/);
       if (match) {
@@ -76,13 +93,63 @@ export function parseMediaPlaylist(text: string, baseUrl: string): MediaPlaylist
       }
       const url = lines[i + 1] && !lines[i + 1].startsWith('#') ? new URL(lines[i + 1], baseUrl).toString() : '';
       if (url) {
-        segments.push({ duration, url, title });
+        segments.push({ duration, url, title, sequence, key });
+        sequence++;
       }
     }
   }
   return { segments, targetDuration };
 }
 
+// parse an #EXT-X-KEY tag, segments after METHOD=NONE are not encrypted
+function parseKeyTag(line: string, baseUrl: string): SegmentKey | undefined {
+  const method = line.match(/METHOD=([^,\s]+)/)?.[1];
+  if (!method || method === 'NONE') return undefined;
+  if (method !== 'AES-128') throw new Error(`unsupported segment encryption: ${method}`);
+
+  const uri = line.match(/URI="([^"]+)"/)?.[1];
+  if (!uri) throw new Error('encrypted playlist without key URI');
+
+  const ivHex = line.match(/IV=0[xX]([0-9a-fA-F]{32})/)?.[1];
+  const iv = ivHex ? new Uint8Array(ivHex.match(/../g)!.map(byte => parseInt(byte, 16))) : undefined;
+
+  return { uri: new URL(uri, baseUrl).toString(), iv };
+}
+
+// keys are fetched once per URI, every segment of a movie shares one
+const segmentKeys = new Map<string, Promise<CryptoKey>>();
+
+async function importSegmentKey(uri: string): Promise<CryptoKey> {
+  // the key route needs the user's token, guests without a token in the URI authenticate with their session cookie
+  const token = localStorage.getItem('token');
+  const res = await fetch(uri, token && !uri.includes('token=')
+    ? { headers: { Authorization: `Bearer ${token}` } }
+    : { credentials: 'include' });
+  if (!res.ok) throw new Error(`failed to fetch segment key: ${res.status}`);
+
+  return crypto.subtle.importKey('raw', await res.arrayBuffer(), 'AES-CBC', false, ['decrypt']);
+}
+
+// decrypt an AES-128 HLS segment
+export async function decryptSegment(data: ArrayBuffer, key: SegmentKey, sequence: number): Promise<ArrayBuffer> {
+  let cryptoKey = segmentKeys.get(key.uri);
+  if (!cryptoKey) {
+    cryptoKey = importSegmentKey(key.uri);
+    segmentKeys.set(key.uri, cryptoKey);
+    // a failed fetch is retried by the next segment
+    cryptoKey.catch(() => segmentKeys.delete(key.uri));
+  }
+
+  let iv = key.iv;
+  if (!iv) {
+    // the media sequence number as a 128-bit big-endian integer
+    iv = new Uint8Array(16);
+    new DataView(iv.buffer).setUint32(12, sequence);
+  }
+
+  return crypto.subtle.decrypt({ name: 'AES-CBC', iv }, await cryptoKey, data);
+}
+
 import { apiClient } from '../services/apiClient'
 
 // Fetch with auth headers and handle signed URLs
@@ -17,7 +17,7 @@ resource "google_storage_bucket" "videos" {
   cors {
     origin          = ["https://localhost:5173", "http://localhost:5173", "http://localhost:3000", "https://watch-party.c3llus.dev", "https://watch-party.c3llus.dev/"]
     method          = ["GET", "HEAD", "PUT", "POST", "DELETE"]
-    response_header = ["Content-Type", "Authorization", "Range", "Accept-Ranges"]
+    response_header = ["Content-Type", "Authorization", "Range", "Accept-Ranges", "x-goog-content-length-range"]
     max_age_seconds = 3600
   }
 